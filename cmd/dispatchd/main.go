@@ -0,0 +1,244 @@
+// dispatchd 以 REST API、gRPC 与 WebSocket 对外提供司空2与机场2调度能力
+//
+//	go run ./cmd/dispatchd -addr :8080
+//
+// 监听地址优先取 -addr，其次为配置文件 Server.addr，再次为环境变量或 .env 中的 PORT，默认 :8080。
+// 指定 -grpc-addr 或配置文件 Server.grpc_addr 时同时提供 gRPC 接口（含 OSD、HMS告警、任务状态推送）。
+// 浏览器看板通过 WebSocket（默认 /v1/ws，见配置文件 Websocket 段）订阅 OSD、HMS告警、任务进度与DRC链路状态。
+// 调用方通过 X-Tenant-Id、X-User-Token、X-Project-Uuid 请求头声明租户身份，权限按配置文件 Server 段授予。
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/geofence"
+	"gitee.com/jamespi/drone_dispatch/pkg/grpcapi"
+	"gitee.com/jamespi/drone_dispatch/pkg/hms"
+	"gitee.com/jamespi/drone_dispatch/pkg/livestream"
+	"gitee.com/jamespi/drone_dispatch/pkg/media"
+	"gitee.com/jamespi/drone_dispatch/pkg/preflight"
+	"gitee.com/jamespi/drone_dispatch/pkg/reconstruct"
+	"gitee.com/jamespi/drone_dispatch/pkg/restapi"
+	"gitee.com/jamespi/drone_dispatch/pkg/telemetry"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/pkg/weather"
+	"gitee.com/jamespi/drone_dispatch/pkg/wshub"
+	"gitee.com/jamespi/drone_dispatch/plugin"
+	"gitee.com/jamespi/drone_dispatch/plugin/grpcplugin"
+	_ "gitee.com/jamespi/drone_dispatch/plugin/plugins" // 自动注册插件
+	"google.golang.org/grpc"
+)
+
+func main() {
+	addr := flag.String("addr", "", "监听地址，为空时按配置文件、PORT 环境变量依次确定")
+	grpcAddr := flag.String("grpc-addr", "", "gRPC 监听地址，为空时按配置文件确定，均未配置时不启用")
+	envFile := flag.String("env", ".env", "环境变量文件，已存在的环境变量不会被覆盖")
+	flag.Parse()
+
+	if err := loadEnvFile(*envFile); err != nil {
+		log.Printf("读取环境变量文件失败: %v", err)
+	}
+	if err := config.InitDefaultConfig(); err != nil {
+		log.Fatalf("配置初始化失败: %v", err)
+	}
+	// 内置告警码目录只收录常见告警，调度服务必须配置完整目录
+	if cfg := config.HmsSettings(); cfg == nil || cfg.CatalogFile == "" {
+		log.Fatalf("配置初始化失败: %v", hms.ErrCatalogRequired)
+	}
+	applyConfig(&config.Config{
+		Server:      config.ServerSettings(),
+		Plugins:     config.PluginsSettings(),
+		Geofences:   config.GeofencesSettings(),
+		Preflight:   config.PreflightSettings(),
+		Hms:         config.HmsSettings(),
+		Weather:     config.WeatherSettings(),
+		Media:       config.MediaSettings(),
+		Reconstruct: config.ReconstructSettings(),
+		LiveStream:  config.LiveStreamSettings(),
+	})
+
+	server := restapi.NewServer(restapi.OptionsFromConfig(config.ServerSettings()))
+	rpcServer := grpcapi.NewServer(grpcapi.OptionsFromConfig(config.ServerSettings()))
+	hub := wshub.NewHub(wshub.OptionsFromConfig(config.ServerSettings(), config.WebsocketSettings()))
+	config.OnReload(func(cfg *config.Config) {
+		applyConfig(cfg)
+		server.SetOptions(restapi.OptionsFromConfig(cfg.Server))
+		rpcServer.SetOptions(grpcapi.OptionsFromConfig(cfg.Server))
+		hub.SetOptions(wshub.OptionsFromConfig(cfg.Server, cfg.Websocket))
+	})
+	config.WatchConfig()
+
+	var grpcServer *grpc.Server
+	if addr := grpcListenAddr(*grpcAddr); addr != "" {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.Fatalf("gRPC 监听失败: %v", err)
+		}
+		grpcServer = rpcServer.NewGRPCServer()
+		go func() {
+			log.Printf("gRPC 服务已启动: %s", addr)
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatalf("gRPC 服务退出: %v", err)
+			}
+		}()
+	}
+
+	// WebSocket 需要接管连接，不经过 REST 服务的访问日志包装
+	mux := http.NewServeMux()
+	mux.Handle(wsPath(), hub)
+	mux.Handle("/", server)
+	httpServer := &http.Server{
+		Addr:              listenAddr(*addr),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		log.Printf("调度服务已启动: %s (API %s, WebSocket %s)", httpServer.Addr, restapi.APIVersion, wsPath())
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("调度服务退出: %v", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	log.Println("正在关闭调度服务...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	// 已升级的 WebSocket 连接不受 Shutdown 管理，先行断开
+	hub.Close()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("关闭HTTP服务失败: %v", err)
+	}
+	if grpcServer != nil {
+		// 推送流不会自行结束，等待超时后强制关闭
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			grpcServer.Stop()
+		}
+	}
+	plugin.Shutdown()
+}
+
+// applyConfig 按配置启用插件并应用电子围栏、飞前检查、HMS告警、天气门限、任务跟踪凭据、媒体归档、模型重建与直播会话，启动与配置重新加载时调用
+func applyConfig(cfg *config.Config) {
+	if err := grpcplugin.ApplyConfig(context.Background(), cfg.Plugins); err != nil {
+		log.Printf("插件启用存在错误: %v", err)
+	}
+	if err := geofence.ApplyConfig(cfg.Geofences); err != nil {
+		log.Printf("电子围栏导入存在错误: %v", err)
+	}
+	preflight.ApplyConfig(cfg.Preflight)
+	if err := hms.ApplyConfig(cfg.Hms); err != nil {
+		log.Printf("HMS告警配置存在错误: %v", err)
+	}
+	weather.ApplyConfig(cfg.Weather)
+	telemetry.DefaultTaskWatcher().SetOptions(telemetry.TaskWatchOptions{Credentials: serviceTokens(cfg.Server)})
+	if err := media.ApplyConfig(cfg.Media); err != nil {
+		log.Printf("媒体归档配置存在错误: %v", err)
+	}
+	if err := reconstruct.ApplyConfig(cfg.Reconstruct, cfg.Server); err != nil {
+		log.Printf("模型重建配置存在错误: %v", err)
+	}
+	livestream.ApplyConfig(cfg.LiveStream, cfg.Server)
+}
+
+// serviceTokens 配置文件 Server 段登记的租户服务凭据
+func serviceTokens(server *config.Server) tenant.ServiceTokens {
+	tokens := make(tenant.ServiceTokens)
+	if server == nil {
+		return tokens
+	}
+	for _, grant := range server.Tenants {
+		if grant.ServiceToken != "" {
+			tokens[grant.TenantID] = grant.ServiceToken
+		}
+	}
+	return tokens
+}
+
+// listenAddr 确定监听地址
+func listenAddr(flagAddr string) string {
+	if flagAddr != "" {
+		return flagAddr
+	}
+	if config.ServerSettings() != nil && config.ServerSettings().Addr != "" {
+		return config.ServerSettings().Addr
+	}
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
+	}
+	return ":8080"
+}
+
+// grpcListenAddr 确定 gRPC 监听地址，为空表示不启用
+func grpcListenAddr(flagAddr string) string {
+	if flagAddr != "" {
+		return flagAddr
+	}
+	if config.ServerSettings() != nil {
+		return config.ServerSettings().GrpcAddr
+	}
+	return ""
+}
+
+// wsPath 确定 WebSocket 推送路径：配置文件 Websocket.path，其次为 Drone.Dji.DjiWebsocket 地址中的路径，默认 /v1/ws
+func wsPath() string {
+	if config.WebsocketSettings() != nil && config.WebsocketSettings().Path != "" {
+		return "/" + strings.TrimPrefix(config.WebsocketSettings().Path, "/")
+	}
+	if u, err := url.Parse(config.DjiSettings()["DjiWebsocket"]); err == nil && u.Path != "" && u.Path != "/" {
+		return u.Path
+	}
+	return wshub.DefaultPath
+}
+
+// loadEnvFile 读取 KEY=VALUE 形式的环境变量文件，文件不存在时忽略
+func loadEnvFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if _, exists := os.LookupEnv(key); exists {
+			continue
+		}
+		os.Setenv(key, strings.Trim(strings.TrimSpace(value), `"'`))
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,90 @@
+// dock2sim 机场2设备模拟器命令行
+//
+//	go run ./cmd/dock2sim -embedded 127.0.0.1:1883
+//	go run ./cmd/dock2sim -broker tcp://127.0.0.1:1883 -dock-sn 7CTXN4A00B0001H
+//
+// 未指定 -broker 时使用 config.yaml 中的 Mqtt 配置。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/dock2sim"
+)
+
+func main() {
+	defaults := dock2sim.DefaultOptions()
+	embedded := flag.String("embedded", "", "启动内嵌MQTT代理的监听地址，例如 127.0.0.1:1883")
+	broker := flag.String("broker", "", "MQTT代理地址，例如 tcp://127.0.0.1:1883")
+	username := flag.String("username", "", "MQTT用户名")
+	password := flag.String("password", "", "MQTT密码")
+	configPath := flag.String("config", config.DefaultConfigPath, "配置文件路径（未指定 -broker 时读取其中的 Mqtt 配置）")
+	dockSN := flag.String("dock-sn", defaults.DockSN, "机场序列号")
+	droneSN := flag.String("drone-sn", defaults.DroneSN, "飞行器序列号")
+	lat := flag.Float64("lat", defaults.Latitude, "机场纬度")
+	lng := flag.Float64("lng", defaults.Longitude, "机场经度")
+	osdInterval := flag.Duration("osd-interval", defaults.OsdInterval, "OSD推送间隔")
+	wind := flag.Float64("wind", defaults.WindSpeed, "环境风速(m/s)")
+	rain := flag.Int("rain", 0, "降雨等级 0~3")
+	battery := flag.Float64("battery", defaults.InitialBattery, "初始电量")
+	flag.Parse()
+
+	brokerURL := *broker
+	if *embedded != "" {
+		b, err := dock2sim.StartBroker(*embedded)
+		if err != nil {
+			log.Fatalf("启动内嵌MQTT代理失败: %v", err)
+		}
+		defer b.Close()
+		brokerURL = b.URL()
+		log.Printf("内嵌MQTT代理已启动: %s", brokerURL)
+	}
+	if brokerURL == "" {
+		if err := config.InitConfig(*configPath); err != nil {
+			log.Fatalf("配置初始化失败: %v", err)
+		}
+		brokerURL = fmt.Sprintf("tcp://%s:%s", config.MqttSettings()["host"], config.MqttSettings()["port"])
+		if *username == "" {
+			*username, *password = config.MqttSettings()["username"], config.MqttSettings()["password"]
+		}
+	}
+
+	sim := dock2sim.New(dock2sim.Options{
+		DockSN:         *dockSN,
+		DroneSN:        *droneSN,
+		Latitude:       *lat,
+		Longitude:      *lng,
+		OsdInterval:    *osdInterval,
+		WindSpeed:      *wind,
+		Rainfall:       *rain,
+		InitialBattery: *battery,
+		Logger:         log.New(os.Stderr, "", log.LstdFlags),
+	})
+	if err := sim.Connect(brokerURL, *username, *password); err != nil {
+		log.Fatalf("模拟器连接失败: %v", err)
+	}
+	sim.Start()
+	defer sim.Stop()
+	log.Printf("机场2模拟器已启动: 机场 %s 飞行器 %s", *dockSN, *droneSN)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-signals:
+			log.Println("模拟器退出")
+			return
+		case <-ticker.C:
+			log.Println(sim)
+		}
+	}
+}
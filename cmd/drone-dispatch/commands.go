@@ -0,0 +1,943 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/geofence"
+	"gitee.com/jamespi/drone_dispatch/pkg/hms"
+	"gitee.com/jamespi/drone_dispatch/pkg/media"
+	"gitee.com/jamespi/drone_dispatch/pkg/reconstruct"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/pkg/wayline"
+	"gitee.com/jamespi/drone_dispatch/plugin"
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// commands 全部子命令
+var commands = []command{
+	// 配置档
+	{name: "profile list", summary: "列出配置档", setup: noFlags(profileList)},
+	{name: "profile show", args: "[名称]", summary: "查看配置档，令牌脱敏显示", setup: noFlags(profileShow)},
+	{name: "profile use", args: "名称", summary: "切换当前配置档", setup: noFlags(profileUse)},
+	{name: "profile set", args: "名称", summary: "新增或修改配置档，只更新指定的字段", setup: profileSet},
+	{name: "profile delete", args: "名称", summary: "删除配置档", setup: noFlags(profileDelete)},
+
+	// 项目与设备
+	{name: "project list", summary: "组织下的项目列表", setup: projectList},
+	{name: "project sts-token", summary: "项目的存储上传凭证", setup: noFlags(projectStsToken)},
+	{name: "device list", summary: "项目下的设备列表", setup: noFlags(deviceList)},
+	{name: "device state", args: "序列号", summary: "设备遥测状态", setup: noFlags(deviceState)},
+	{name: "device hms", args: "序列号...", summary: "设备当前的HMS告警（已解码）", setup: deviceHms},
+	{name: "device command", args: "序列号 指令", summary: "实时控制：return_home、return_home_cancel、pause、resume、return_specific_home", setup: noFlags(deviceCommand)},
+
+	// 飞行任务
+	{name: "task create", summary: "创建飞行任务，下发前执行电子围栏、飞前检查与天气门限", setup: taskCreate},
+	{name: "task list", summary: "飞行任务列表", setup: taskList},
+	{name: "task get", args: "任务UUID", summary: "飞行任务详情", setup: noFlags(taskGet)},
+	{name: "task suspend", args: "任务UUID", summary: "挂起待执行的任务", setup: noFlags(taskStatus("suspended"))},
+	{name: "task resume", args: "任务UUID", summary: "恢复已挂起的任务", setup: noFlags(taskStatus("restored"))},
+	{name: "task media", args: "任务UUID", summary: "任务产生的媒体资源", setup: noFlags(taskMedia)},
+	{name: "task track", args: "任务UUID", summary: "任务轨迹", setup: noFlags(taskTrack)},
+
+	// 航线
+	{name: "wayline list", summary: "项目下的航线列表", setup: noFlags(waylineList)},
+	{name: "wayline get", args: "航线UUID", summary: "航线详情", setup: noFlags(waylineGet)},
+	{name: "wayline upload", summary: "上传并登记航线文件", setup: waylineUpload},
+
+	// 媒体归档
+	{name: "media archive", args: "任务UUID", summary: "下载任务媒体并归档到配置的存储，已归档的文件跳过，中断后重新执行可续传", setup: noFlags(mediaArchive)},
+	{name: "media search", summary: "按任务、设备、拍摄时间与位置检索已归档的媒体", setup: mediaSearch},
+
+	// 模型重建
+	{name: "model reconstruct", summary: "选取已结束飞行任务的照片提交模型重建", setup: modelReconstruct},
+	{name: "model get", args: "模型ID", summary: "模型详情与重建进度", setup: noFlags(modelGet)},
+	{name: "model jobs", summary: "重建登记表：模型与源飞行任务、照片的对应关系", setup: modelJobs},
+
+	// 直播
+	{name: "live start", args: "序列号", summary: "开启直播", setup: liveStart},
+
+	// 插件
+	{name: "plugin list", summary: "插件列表：配置了 dispatchd 地址时查询服务端状态，否则列出本工具内置的插件", setup: noFlags(pluginList)},
+	{name: "plugin enable", args: "插件类型", summary: "通过 dispatchd 启用插件", setup: noFlags(pluginAction("enable"))},
+	{name: "plugin disable", args: "插件类型", summary: "通过 dispatchd 禁用插件", setup: noFlags(pluginAction("disable"))},
+}
+
+// noFlags 没有专属参数的命令
+func noFlags(fn func(a *app, args []string) error) func(fs *flag.FlagSet) func(a *app, args []string) error {
+	return func(*flag.FlagSet) func(a *app, args []string) error { return fn }
+}
+
+// want 校验位置参数个数
+func want(args []string, n int) error {
+	if len(args) != n {
+		return errUsage
+	}
+	return nil
+}
+
+/**  配置档  **/
+
+func profileList(a *app, args []string) error {
+	if err := want(args, 0); err != nil {
+		return err
+	}
+	list := make([]map[string]interface{}, 0, len(a.profiles.Profiles))
+	for _, name := range a.profiles.names() {
+		p := a.profiles.Profiles[name]
+		list = append(list, map[string]interface{}{
+			"name":         name,
+			"current":      name == a.profiles.Current,
+			"tenant_id":    p.TenantID,
+			"project_uuid": p.ProjectUUID,
+			"host":         p.Host,
+			"server":       p.Server,
+		})
+	}
+	return a.printer.print(map[string]interface{}{"list": list}, cols("name", "current", "tenant=tenant_id", "project=project_uuid", "host", "server"))
+}
+
+func profileShow(a *app, args []string) error {
+	if len(args) > 1 {
+		return errUsage
+	}
+	name, profile := a.profileName, a.profile
+	if len(args) == 1 {
+		var ok bool
+		if profile, ok = a.profiles.Profiles[args[0]]; !ok {
+			return fmt.Errorf("配置档 %s 不存在", args[0])
+		}
+		name = args[0]
+	}
+	profile.Token = maskToken(profile.Token)
+	return a.printer.print(map[string]interface{}{"name": name, "profile": profile}, nil)
+}
+
+func profileUse(a *app, args []string) error {
+	if err := want(args, 1); err != nil {
+		return err
+	}
+	if _, ok := a.profiles.Profiles[args[0]]; !ok {
+		return fmt.Errorf("配置档 %s 不存在", args[0])
+	}
+	a.profiles.Current = args[0]
+	if err := a.profiles.save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.errOut, "当前配置档: %s\n", args[0])
+	return nil
+}
+
+func profileSet(fs *flag.FlagSet) func(a *app, args []string) error {
+	tenantID := fs.Int64("tenant", 0, "租户ID")
+	token := fs.String("token", "", "司空2 X-User-Token")
+	project := fs.String("project", "", "项目UUID")
+	org := fs.String("org", "", "组织ID")
+	host := fs.String("host", "", "司空2 OpenAPI 地址")
+	server := fs.String("server", "", "dispatchd 地址，例如 http://127.0.0.1:8080")
+	return func(a *app, args []string) error {
+		if err := want(args, 1); err != nil {
+			return err
+		}
+		name := args[0]
+		profile := a.profiles.Profiles[name]
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "tenant":
+				profile.TenantID = *tenantID
+			case "token":
+				profile.Token = *token
+			case "project":
+				profile.ProjectUUID = *project
+			case "org":
+				profile.OrgID = *org
+			case "host":
+				profile.Host = strings.TrimRight(*host, "/")
+			case "server":
+				profile.Server = strings.TrimRight(*server, "/")
+			}
+		})
+		a.profiles.Profiles[name] = profile
+		if a.profiles.Current == "" {
+			a.profiles.Current = name
+		}
+		if err := a.profiles.save(); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.errOut, "已保存配置档 %s 到 %s\n", name, a.profiles.path)
+		return nil
+	}
+}
+
+func profileDelete(a *app, args []string) error {
+	if err := want(args, 1); err != nil {
+		return err
+	}
+	if _, ok := a.profiles.Profiles[args[0]]; !ok {
+		return fmt.Errorf("配置档 %s 不存在", args[0])
+	}
+	delete(a.profiles.Profiles, args[0])
+	if a.profiles.Current == args[0] {
+		a.profiles.Current = ""
+	}
+	return a.profiles.save()
+}
+
+/**  项目与设备  **/
+
+func projectList(fs *flag.FlagSet) func(a *app, args []string) error {
+	q := fs.String("q", "", "项目名称关键字，默认使用配置文件 FH.q")
+	return func(a *app, args []string) error {
+		if err := want(args, 0); err != nil {
+			return err
+		}
+		fh2, ctx, err := a.adapter()
+		if err != nil {
+			return err
+		}
+		if *q != "" {
+			config.SetFH2Settings(map[string]string{"q": *q})
+		}
+		resp, err := fh2.GetprojectList(ctx)
+		if err != nil {
+			return err
+		}
+		return a.printer.print(resp, cols("uuid", "name", "introduction", "org=org_uuid"))
+	}
+}
+
+func projectStsToken(a *app, args []string) error {
+	if err := want(args, 0); err != nil {
+		return err
+	}
+	fh2, ctx, err := a.adapter()
+	if err != nil {
+		return err
+	}
+	resp, err := fh2.GetProjectStsToken(ctx)
+	if err != nil {
+		return err
+	}
+	return a.printer.print(resp, nil)
+}
+
+func deviceList(a *app, args []string) error {
+	if err := want(args, 0); err != nil {
+		return err
+	}
+	fh2, ctx, err := a.adapter()
+	if err != nil {
+		return err
+	}
+	resp, err := fh2.GetDeviceList(ctx)
+	if err != nil {
+		return err
+	}
+	return a.printer.print(resp, cols(
+		"dock=gateway.sn", "dock_name=gateway.callsign", "dock_model=gateway.device_model.name", "dock_online=gateway.device_online_status",
+		"drone=drone.sn", "drone_name=drone.callsign", "drone_model=drone.device_model.name", "drone_online=drone.device_online_status",
+	))
+}
+
+func deviceState(a *app, args []string) error {
+	if err := want(args, 1); err != nil {
+		return err
+	}
+	fh2, ctx, err := a.adapter()
+	if err != nil {
+		return err
+	}
+	source, ok := fh2.(service.TelemetrySource)
+	if !ok {
+		return fmt.Errorf("适配器不支持获取设备状态")
+	}
+	resp, err := source.GetDeviceState(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return a.printer.print(resp, nil)
+}
+
+func deviceHms(fs *flag.FlagSet) func(a *app, args []string) error {
+	lang := fs.String("lang", "", "告警文案语言：zh、en，默认使用配置文件 Hms.language")
+	return func(a *app, args []string) error {
+		if len(args) == 0 {
+			return errUsage
+		}
+		var language hms.Lang
+		if *lang != "" {
+			parsed, err := hms.ParseLang(*lang)
+			if err != nil {
+				return err
+			}
+			language = parsed
+		}
+		fh2, ctx, err := a.adapter()
+		if err != nil {
+			return err
+		}
+		resp, err := fh2.GetDeviceHms(ctx, strings.Join(splitList(args), ","))
+		if err != nil {
+			return err
+		}
+		alarms, err := hms.ParseList([]byte(resp))
+		if err != nil {
+			return err
+		}
+		list := make([]hms.Decoded, 0, len(alarms))
+		for _, alarm := range alarms {
+			if language != "" {
+				list = append(list, hms.DefaultCatalog().Decode(alarm, language))
+			} else {
+				list = append(list, hms.Decode(alarm))
+			}
+		}
+		return a.printer.print(map[string]interface{}{"list": list}, cols("sn=device_sn", "level=level_text", "module=module_text", "code", "message", "action"))
+	}
+}
+
+// commandAliases 控制指令别名
+var commandAliases = map[string]string{
+	"rth":        "return_home",
+	"cancel_rth": "return_home_cancel",
+	"pause":      "flighttask_pause",
+	"resume":     "flighttask_recovery",
+}
+
+func deviceCommand(a *app, args []string) error {
+	if err := want(args, 2); err != nil {
+		return err
+	}
+	action := args[1]
+	if full, ok := commandAliases[action]; ok {
+		action = full
+	}
+	body, err := json.Marshal(map[string]string{"device_command": action})
+	if err != nil {
+		return err
+	}
+	fh2, ctx, err := a.adapter()
+	if err != nil {
+		return err
+	}
+	resp, err := fh2.UpdateDeviceCommand(ctx, args[0], bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	return a.printResult(resp)
+}
+
+/**  飞行任务  **/
+
+func taskCreate(fs *flag.FlagSet) func(a *app, args []string) error {
+	file := fs.String("file", "", "任务JSON文件，指定后忽略其余任务参数")
+	sn := fs.String("sn", "", "机场序列号")
+	wayline := fs.String("wayline", "", "航线UUID")
+	name := fs.String("name", "", "任务名称")
+	taskType := fs.String("type", "immediate", "任务类型：immediate、timed、recurring、continuous")
+	rthAltitude := fs.Int("rth-altitude", 100, "返航高度（米）")
+	rthMode := fs.String("rth-mode", "", "返航模式：optimal、preset")
+	precision := fs.String("precision", "", "航线精度：gps、rtk")
+	lostAction := fs.String("lost-action", "", "失联动作：return_home、continue_task")
+	resumable := fs.String("resumable", "", "断点续飞：auto、manual")
+	begin := fs.String("begin", "", "开始时间：RFC3339、2006-01-02 15:04 或毫秒时间戳")
+	end := fs.String("end", "", "结束时间，格式同 -begin")
+	timeZone := fs.String("time-zone", "Asia/Shanghai", "时区")
+	repeatType := fs.String("repeat-type", "", "重复类型：nonrepeating、daily、weekly、absolute_monthly、relative_monthly")
+	repeatOption := fs.String("repeat-option", "", "重复规则（JSON）")
+	minBattery := fs.Int("min-battery", 0, "最低起飞电量（%）")
+	override := fs.String("override-reason", "", "航线经过限飞区时的确认理由")
+	return func(a *app, args []string) error {
+		if err := want(args, 0); err != nil {
+			return err
+		}
+		var body []byte
+		if *file != "" {
+			raw, err := os.ReadFile(*file)
+			if err != nil {
+				return fmt.Errorf("读取任务文件失败: %w", err)
+			}
+			if !json.Valid(raw) {
+				return fmt.Errorf("任务文件不是合法的JSON: %s", *file)
+			}
+			body = raw
+		} else {
+			task := map[string]interface{}{
+				"name":         *name,
+				"wayline_uuid": *wayline,
+				"sn":           *sn,
+				"rth_altitude": *rthAltitude,
+				"task_type":    *taskType,
+				"time_zone":    *timeZone,
+			}
+			for key, value := range map[string]string{
+				"rth_mode":                        *rthMode,
+				"wayline_precision_type":          *precision,
+				"out_of_control_action_in_flight": *lostAction,
+				"resumable_status":                *resumable,
+				"repeat_type":                     *repeatType,
+			} {
+				if value != "" {
+					task[key] = value
+				}
+			}
+			for key, value := range map[string]string{"begin_at": *begin, "end_at": *end} {
+				if value == "" {
+					continue
+				}
+				at, err := parseTime(value)
+				if err != nil {
+					return fmt.Errorf("-%s: %w", strings.TrimSuffix(key, "_at"), err)
+				}
+				task[key] = at.UnixMilli()
+			}
+			if *repeatOption != "" {
+				if !json.Valid([]byte(*repeatOption)) {
+					return fmt.Errorf("-repeat-option 不是合法的JSON")
+				}
+				task["repeat_option"] = json.RawMessage(*repeatOption)
+			}
+			if *minBattery > 0 {
+				task["min_battery_capacity"] = *minBattery
+			}
+			encoded, err := json.Marshal(task)
+			if err != nil {
+				return err
+			}
+			body = encoded
+		}
+		fh2, ctx, err := a.adapter()
+		if err != nil {
+			return err
+		}
+		if *override != "" {
+			// 命令行直接使用配置档中的司空2令牌，由本机用户确认，不经过服务端的 geofence:override 授权
+			ctx = geofence.WithOverride(ctx, "命令行 "+a.profile.TenantInfo().Operator(), *override)
+		}
+		resp, err := fh2.CreateFlightTask(ctx, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		return a.printResult(resp)
+	}
+}
+
+func taskList(fs *flag.FlagSet) func(a *app, args []string) error {
+	sn := fs.String("sn", "", "机场序列号（必填）")
+	name := fs.String("name", "", "任务名称关键字（必填）")
+	begin := fs.String("begin", "", "开始时间，默认24小时前")
+	end := fs.String("end", "", "结束时间，默认当前")
+	taskType := fs.String("type", "", "任务类型")
+	status := fs.String("status", "", "任务状态")
+	return func(a *app, args []string) error {
+		if err := want(args, 0); err != nil {
+			return err
+		}
+		if *sn == "" || *name == "" {
+			return errUsage
+		}
+		endAt, beginAt := time.Now(), time.Now().Add(-24*time.Hour)
+		if *end != "" {
+			at, err := parseTime(*end)
+			if err != nil {
+				return fmt.Errorf("-end: %w", err)
+			}
+			endAt = at
+		}
+		if *begin != "" {
+			at, err := parseTime(*begin)
+			if err != nil {
+				return fmt.Errorf("-begin: %w", err)
+			}
+			beginAt = at
+		}
+		fh2, ctx, err := a.adapter()
+		if err != nil {
+			return err
+		}
+		resp, err := fh2.GetFlightTask(ctx, *sn, url.QueryEscape(*name), int(beginAt.UnixMilli()), int(endAt.UnixMilli()), *taskType, *status)
+		if err != nil {
+			return err
+		}
+		return a.printer.print(resp, cols("uuid", "name", "sn", "type=task_type", "status", "progress", "begin_at"))
+	}
+}
+
+func taskGet(a *app, args []string) error {
+	if err := want(args, 1); err != nil {
+		return err
+	}
+	fh2, ctx, err := a.adapter()
+	if err != nil {
+		return err
+	}
+	resp, err := fh2.GetFlightTaskInfo(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return a.printer.print(resp, nil)
+}
+
+// taskStatus 挂起（suspended）或恢复（restored）任务
+func taskStatus(status string) func(a *app, args []string) error {
+	return func(a *app, args []string) error {
+		if err := want(args, 1); err != nil {
+			return err
+		}
+		fh2, ctx, err := a.adapter()
+		if err != nil {
+			return err
+		}
+		body, err := json.Marshal(map[string]string{"status": status})
+		if err != nil {
+			return err
+		}
+		resp, err := fh2.UpdateFlightTaskStatus(ctx, args[0], bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		return a.printResult(resp)
+	}
+}
+
+func taskMedia(a *app, args []string) error {
+	if err := want(args, 1); err != nil {
+		return err
+	}
+	fh2, ctx, err := a.adapter()
+	if err != nil {
+		return err
+	}
+	resp, err := fh2.GetFlightTaskMedia(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return a.printer.print(resp, cols("uuid", "name", "type=file_type", "size", "created_at", "url"))
+}
+
+func taskTrack(a *app, args []string) error {
+	if err := want(args, 1); err != nil {
+		return err
+	}
+	fh2, ctx, err := a.adapter()
+	if err != nil {
+		return err
+	}
+	resp, err := fh2.GetFlightTaskTrack(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return a.printer.print(resp, nil)
+}
+
+/**  航线  **/
+
+func waylineList(a *app, args []string) error {
+	if err := want(args, 0); err != nil {
+		return err
+	}
+	fh2, ctx, err := a.adapter()
+	if err != nil {
+		return err
+	}
+	resp, err := fh2.GetWayLine(ctx)
+	if err != nil {
+		return err
+	}
+	return a.printer.print(resp, cols("uuid=id", "name", "drone=drone_model_key", "updated=update_time"))
+}
+
+func waylineGet(a *app, args []string) error {
+	if err := want(args, 1); err != nil {
+		return err
+	}
+	fh2, ctx, err := a.adapter()
+	if err != nil {
+		return err
+	}
+	resp, err := fh2.GetWayLineInfo(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return a.printer.print(resp, nil)
+}
+
+func waylineUpload(fs *flag.FlagSet) func(a *app, args []string) error {
+	file := fs.String("file", "", "本地航线文件（KMZ），获取项目存储凭证上传后登记，大文件分片上传，中断后重新执行可续传")
+	objectKey := fs.String("object-key", "", "已上传到项目存储的航线文件对象键，只登记不上传")
+	name := fs.String("name", "", "航线名称，默认取文件名")
+	return func(a *app, args []string) error {
+		if err := want(args, 0); err != nil {
+			return err
+		}
+		if (*file == "") == (*objectKey == "") {
+			return errUsage
+		}
+		source := *objectKey
+		if *file != "" {
+			source = *file
+		}
+		waylineName := *name
+		if waylineName == "" {
+			waylineName = strings.TrimSuffix(filepath.Base(source), ".kmz")
+		}
+		var data []byte
+		if *file != "" {
+			var err error
+			if data, err = os.ReadFile(*file); err != nil {
+				return fmt.Errorf("读取航线文件失败: %w", err)
+			}
+			if _, err := wayline.Parse(data); err != nil {
+				return fmt.Errorf("航线文件无效: %w", err)
+			}
+		}
+		fh2, ctx, err := a.adapter()
+		if err != nil {
+			return err
+		}
+		var resp string
+		if *file != "" {
+			resp, err = fh2.UploadWayline(ctx, waylineName, bytes.NewReader(data))
+		} else {
+			resp, err = fh2.SetFinishUpload(ctx, *objectKey, waylineName)
+		}
+		if err != nil {
+			return err
+		}
+		return a.printResult(resp)
+	}
+}
+
+/**  媒体归档  **/
+
+func mediaArchive(a *app, args []string) error {
+	if err := want(args, 1); err != nil {
+		return err
+	}
+	fh2, ctx, err := a.adapter()
+	if err != nil {
+		return err
+	}
+	pipeline, err := media.NewPipelineFromConfig(config.MediaSettings())
+	if err != nil {
+		return err
+	}
+	report, err := pipeline.Archive(ctx, fh2, media.Task{UUID: args[0]})
+	if report != nil {
+		if printErr := a.printer.print(report, nil); printErr != nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+func mediaSearch(fs *flag.FlagSet) func(a *app, args []string) error {
+	task := fs.String("task", "", "任务UUID")
+	sn := fs.String("sn", "", "设备序列号")
+	fileType := fs.String("type", "", "文件类型，例如 image、video")
+	from := fs.String("from", "", "拍摄时间下限")
+	to := fs.String("to", "", "拍摄时间上限")
+	near := fs.String("near", "", "中心点 纬度,经度（WGS84），与 -radius 配合")
+	radius := fs.Float64("radius", 100, "与 -near 的最大距离（米）")
+	bbox := fs.String("bbox", "", "经纬度范围 南,西,北,东（WGS84）")
+	limit := fs.Int("limit", 100, "返回数量上限，0 表示不限制")
+	return func(a *app, args []string) error {
+		if err := want(args, 0); err != nil {
+			return err
+		}
+		q := media.Query{TenantID: a.profile.TenantID, TaskUUID: *task, DeviceSN: *sn, FileType: *fileType, Radius: *radius, Limit: *limit}
+		for _, bound := range []struct {
+			value  string
+			target *time.Time
+			name   string
+		}{{*from, &q.From, "-from"}, {*to, &q.To, "-to"}} {
+			if bound.value == "" {
+				continue
+			}
+			at, err := parseTime(bound.value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", bound.name, err)
+			}
+			*bound.target = at
+		}
+		if *near != "" {
+			point, err := media.ParsePoint(*near)
+			if err != nil {
+				return fmt.Errorf("-near: %w", err)
+			}
+			q.Near = &point
+		}
+		if *bbox != "" {
+			bounds, err := media.ParseBounds(*bbox)
+			if err != nil {
+				return fmt.Errorf("-bbox: %w", err)
+			}
+			q.Bounds = &bounds
+		}
+		if err := a.loadConfig(); err != nil {
+			return err
+		}
+		pipeline, err := media.NewPipelineFromConfig(config.MediaSettings())
+		if err != nil {
+			return err
+		}
+		records := pipeline.Index().Search(q)
+		return a.printer.print(map[string]interface{}{"list": records},
+			cols("captured=captured_at", "task=task_uuid", "sn", "name", "size", "lat=location.latitude", "lng=location.longitude", "rel_alt=rel_altitude", "pitch=gimbal_pitch", "key"))
+	}
+}
+
+/**  模型重建  **/
+
+func modelReconstruct(fs *flag.FlagSet) func(a *app, args []string) error {
+	name := fs.String("name", "", "模型名称（必填）")
+	tasks := fs.String("task", "", "源飞行任务UUID，多个以逗号分隔（必填）")
+	wait := fs.Bool("wait", false, "等待重建结束")
+	return func(a *app, args []string) error {
+		if err := want(args, 0); err != nil {
+			return err
+		}
+		if *name == "" || *tasks == "" {
+			return errUsage
+		}
+		fh2, ctx, err := a.adapter()
+		if err != nil {
+			return err
+		}
+		// 命令行在前台等待结果，以配置档的令牌跟踪进度
+		orchestrator, err := reconstruct.NewOrchestratorFromConfig(config.ReconstructSettings(), tenant.ServiceTokens{a.profile.TenantID: a.profile.Token})
+		if err != nil {
+			return err
+		}
+		defer orchestrator.Stop()
+		job, err := orchestrator.Submit(ctx, fh2, reconstruct.Request{Name: *name, TaskUUIDs: splitList([]string{*tasks})})
+		if err != nil {
+			return err
+		}
+		if *wait {
+			fmt.Fprintf(a.errOut, "模型 %d 已提交，等待重建结束...\n", job.ModelID)
+			finished, err := orchestrator.Wait(ctx, job.ModelID)
+			if err != nil {
+				return err
+			}
+			job = &finished
+		}
+		return a.printer.print(job, nil)
+	}
+}
+
+func modelGet(a *app, args []string) error {
+	if err := want(args, 1); err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		return fmt.Errorf("模型ID无效: %s", args[0])
+	}
+	fh2, ctx, err := a.adapter()
+	if err != nil {
+		return err
+	}
+	resp, err := fh2.GetModelInfo(ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.printer.print(resp, nil)
+}
+
+func modelJobs(fs *flag.FlagSet) func(a *app, args []string) error {
+	task := fs.String("task", "", "只列出使用了该飞行任务照片的模型")
+	status := fs.String("status", "", "重建状态：processing、success、failed")
+	return func(a *app, args []string) error {
+		if err := want(args, 0); err != nil {
+			return err
+		}
+		if err := a.loadConfig(); err != nil {
+			return err
+		}
+		orchestrator, err := reconstruct.NewOrchestratorFromConfig(config.ReconstructSettings(), nil)
+		if err != nil {
+			return err
+		}
+		list := orchestrator.Registry().List(reconstruct.Filter{TenantID: a.profile.TenantID, TaskUUID: *task, Status: *status})
+		return a.printer.print(map[string]interface{}{"list": list},
+			cols("model=model_id", "name", "status", "progress", "images=media_count", "submitted=submitted_at", "finished=finished_at"))
+	}
+}
+
+/**  直播  **/
+
+func liveStart(fs *flag.FlagSet) func(a *app, args []string) error {
+	camera := fs.String("camera", "", "相机索引（必填），见 device list -o json 中的 camera_list")
+	quality := fs.String("quality", "", "清晰度：adaptive、smooth、ultra_high_definition")
+	expire := fs.Int("expire", 0, "直播地址有效期（秒）")
+	return func(a *app, args []string) error {
+		if err := want(args, 1); err != nil {
+			return err
+		}
+		if *camera == "" {
+			return errUsage
+		}
+		req := map[string]interface{}{"sn": args[0], "camera_index": *camera}
+		if *quality != "" {
+			req["quality_type"] = *quality
+		}
+		if *expire > 0 {
+			req["video_expire"] = *expire
+		}
+		body, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		fh2, ctx, err := a.adapter()
+		if err != nil {
+			return err
+		}
+		resp, err := fh2.LiveStreamStart(ctx, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		return a.printResult(resp)
+	}
+}
+
+/**  插件  **/
+
+var pluginColumns = cols("type", "status", "instances", "version", "vendor", "configured", "capabilities", "error")
+
+func pluginList(a *app, args []string) error {
+	if err := want(args, 0); err != nil {
+		return err
+	}
+	if a.profile.Server != "" {
+		resp, err := a.callServer(http.MethodGet, "/v1/plugins")
+		if err != nil {
+			return err
+		}
+		return a.printer.print(resp, pluginColumns)
+	}
+	if err := a.loadConfig(); err != nil {
+		return err
+	}
+	configured := make(map[string]string)
+	for _, decl := range config.PluginsSettings() {
+		state := "enabled"
+		if !decl.IsEnabled() {
+			state = "disabled"
+		}
+		configured[decl.Type] = state
+	}
+	list := make([]map[string]interface{}, 0)
+	for _, info := range plugin.PluginsList() {
+		list = append(list, map[string]interface{}{
+			"type":         string(info.PluginType),
+			"status":       string(info.Status),
+			"instances":    info.Instances,
+			"version":      info.Meta.Version,
+			"vendor":       info.Meta.Vendor,
+			"configured":   configured[string(info.PluginType)],
+			"capabilities": info.Meta.Capabilities,
+		})
+	}
+	return a.printer.print(map[string]interface{}{"list": list}, pluginColumns)
+}
+
+// pluginAction 通过 dispatchd 启用或禁用插件，插件运行在服务进程内
+func pluginAction(action string) func(a *app, args []string) error {
+	return func(a *app, args []string) error {
+		if err := want(args, 1); err != nil {
+			return err
+		}
+		if a.profile.Server == "" {
+			return fmt.Errorf("配置档未设置 dispatchd 地址，请执行 drone-dispatch profile set <名称> -server http://host:8080")
+		}
+		resp, err := a.callServer(http.MethodPost, "/v1/plugins/"+url.PathEscape(args[0])+"/"+action)
+		if err != nil {
+			return err
+		}
+		return a.printResult(string(resp))
+	}
+}
+
+// callServer 调用 dispatchd REST 接口，返回响应中的 data
+func (a *app) callServer(method, path string) (json.RawMessage, error) {
+	if a.profile.TenantID <= 0 || a.profile.Token == "" {
+		return nil, fmt.Errorf("配置档缺少租户ID或令牌，请先执行 drone-dispatch profile set")
+	}
+	resp, err := a.httpClient().DoRequest(a.ctx, method, a.profile.Server+path, nil, a.profile.Headers())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	var envelope struct {
+		Data  json.RawMessage `json:"data"`
+		Error *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, fmt.Errorf("解析 dispatchd 响应失败: %w（HTTP %d）", err, resp.StatusCode)
+	}
+	if envelope.Error != nil {
+		return nil, fmt.Errorf("dispatchd 返回错误 %s: %s", envelope.Error.Code, envelope.Error.Message)
+	}
+	return envelope.Data, nil
+}
+
+/**  辅助函数  **/
+
+// printResult 打印写操作的结果，演练模式下请求已打印，不再输出模拟响应
+func (a *app) printResult(resp string) error {
+	if a.dryRun {
+		return nil
+	}
+	return a.printer.print(resp, nil)
+}
+
+// splitList 展开逗号分隔的参数
+func splitList(args []string) []string {
+	var list []string
+	for _, arg := range args {
+		for _, item := range strings.Split(arg, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// parseTime 解析时间：RFC3339、本地时间 2006-01-02 15:04[:05] 或时间戳（秒或毫秒）
+func parseTime(value string) (time.Time, error) {
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(n), nil
+		}
+		return time.Unix(n, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间 %s", value)
+}
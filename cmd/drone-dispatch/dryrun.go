@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+)
+
+// dryRunResponse 未发送的写请求返回的响应，与司空2成功响应格式一致
+const dryRunResponse = `{"code":0,"message":"dry-run","data":{}}`
+
+// dryRunTransport 演练模式传输层：写请求打印后不发送；只读请求照常发送，
+// 以便创建任务前的电子围栏、飞前检查与天气门限按真实数据执行
+type dryRunTransport struct {
+	out  io.Writer
+	log  io.Writer
+	real http.RoundTripper
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		fmt.Fprintf(t.log, "# 已发送只读请求 %s %s\n", req.Method, req.URL)
+		return t.real.RoundTrip(req)
+	}
+	var body []byte
+	if req.Body != nil {
+		raw, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取请求体失败: %w", err)
+		}
+		body = raw
+	}
+	fmt.Fprintln(t.out, "# dry-run：以下请求未发送")
+	fmt.Fprintf(t.out, "%s %s\n", req.Method, req.URL)
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := req.Header.Get(key)
+		if strings.EqualFold(key, tenant.HeaderUserToken) || strings.EqualFold(key, "Authorization") ||
+			strings.EqualFold(key, "X-Amz-Security-Token") {
+			value = maskToken(value)
+		}
+		fmt.Fprintf(t.out, "%s: %s\n", key, value)
+	}
+	if len(body) > 0 {
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, body, "", "  ") == nil {
+			body = pretty.Bytes()
+		} else if !utf8.Valid(body) {
+			body = []byte(fmt.Sprintf("<二进制内容 %d 字节>", len(body)))
+		}
+		fmt.Fprintf(t.out, "\n%s\n", body)
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(dryRunResponse)),
+		Request:    req,
+	}, nil
+}
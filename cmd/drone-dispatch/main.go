@@ -0,0 +1,269 @@
+// drone-dispatch 运维命令行工具，直接使用司空2适配器完成日常操作
+//
+//	drone-dispatch profile set prod -tenant 1 -token xxx -project c33595a4-... -host https://es-flight-api-cn.djigate.com
+//	drone-dispatch device list -o table
+//	drone-dispatch device command 1581F6Q8D242100CPWEK return_home -dry-run
+//	drone-dispatch task create -sn 7CTXN4A00B096H -wayline 6d88fbe5-... -name 巡检 -type immediate
+//
+// 租户身份取自配置档（-profile、环境变量 DRONE_DISPATCH_PROFILE 或当前配置档），令牌可通过环境变量 DRONE_DISPATCH_TOKEN 传入。
+// 电子围栏、飞前检查、HMS、天气门限与媒体归档沿用配置文件（-config，默认 ./config.yaml，不存在时使用默认值）。
+// -dry-run 只打印写请求而不发送，只读请求照常发送以便按真实数据执行下发前检查。
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/geofence"
+	"gitee.com/jamespi/drone_dispatch/pkg/hms"
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+	"gitee.com/jamespi/drone_dispatch/pkg/preflight"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/pkg/weather"
+	"gitee.com/jamespi/drone_dispatch/plugin/plugins"
+	"gitee.com/jamespi/drone_dispatch/service"
+	"github.com/google/uuid"
+)
+
+// errUsage 参数错误，已打印用法
+var errUsage = errors.New("参数错误")
+
+// command 子命令，setup 注册命令自己的参数并返回执行函数
+type command struct {
+	name    string
+	args    string
+	summary string
+	setup   func(fs *flag.FlagSet) func(a *app, args []string) error
+}
+
+// app 一次命令执行的上下文
+type app struct {
+	ctx     context.Context
+	out     io.Writer
+	errOut  io.Writer
+	printer *printer
+
+	configPath   string
+	profilesPath string
+	profileName  string
+	output       string
+	dryRun       bool
+
+	profiles *profileStore
+	profile  Profile
+
+	client *httpclient.SecureHTTPClient
+	fh2    service.FH2DroneAdapter
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run 解析参数并执行命令，返回进程退出码
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	a := &app{
+		ctx:          ctx,
+		out:          stdout,
+		errOut:       stderr,
+		configPath:   config.DefaultConfigPath,
+		profilesPath: defaultProfilesPath(),
+		output:       outputTable,
+	}
+	global := flag.NewFlagSet("drone-dispatch", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	a.bindCommon(global)
+	global.Usage = func() { usage(stderr) }
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+	rest := global.Args()
+	if len(rest) == 0 || rest[0] == "help" {
+		usage(stderr)
+		return 2
+	}
+
+	cmd, consumed := findCommand(rest)
+	if cmd == nil {
+		fmt.Fprintf(stderr, "未知命令: %s\n\n", strings.Join(rest, " "))
+		usage(stderr)
+		return 2
+	}
+	fs := flag.NewFlagSet("drone-dispatch "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	a.bindCommon(fs)
+	exec := cmd.setup(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "用法: drone-dispatch %s [参数] %s\n  %s\n\n", cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, rest[consumed:])
+	if err != nil {
+		return 2
+	}
+	if a.output != outputJSON && a.output != outputTable {
+		fmt.Fprintf(stderr, "不支持的输出格式 %s，可选 json、table\n", a.output)
+		return 2
+	}
+	a.printer = &printer{out: stdout, format: a.output}
+
+	if err := a.loadProfile(); err != nil {
+		fmt.Fprintf(stderr, "错误: %v\n", err)
+		return 1
+	}
+	if err := exec(a, positional); err != nil {
+		if errors.Is(err, errUsage) {
+			fs.Usage()
+			return 2
+		}
+		fmt.Fprintf(stderr, "错误: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// bindCommon 注册全局参数，全局参数可写在命令前或命令后；以当前值作为默认值，命令前已解析的值不被覆盖
+func (a *app) bindCommon(fs *flag.FlagSet) {
+	fs.StringVar(&a.configPath, "config", a.configPath, "配置文件，不存在时使用默认的检查规则")
+	fs.StringVar(&a.profilesPath, "profiles", a.profilesPath, "配置档文件")
+	fs.StringVar(&a.profileName, "profile", a.profileName, "使用的配置档，默认为当前配置档")
+	fs.StringVar(&a.output, "o", a.output, "输出格式：table、json")
+	fs.BoolVar(&a.dryRun, "dry-run", a.dryRun, "只打印写请求，不发送")
+}
+
+// parseInterspersed 解析参数，允许参数出现在位置参数之后
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// findCommand 按 "分组 动作" 查找命令，返回已消耗的参数个数
+func findCommand(args []string) (*command, int) {
+	if len(args) >= 2 {
+		name := args[0] + " " + args[1]
+		for i := range commands {
+			if commands[i].name == name {
+				return &commands[i], 2
+			}
+		}
+	}
+	return nil, 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "用法: drone-dispatch [全局参数] <分组> <动作> [参数]")
+	fmt.Fprintln(w, "\n全局参数: -config 配置文件  -profile 配置档  -o table|json  -dry-run")
+	fmt.Fprintln(w, "\n命令:")
+	names := make([]string, len(commands))
+	byName := make(map[string]command, len(commands))
+	for i, c := range commands {
+		names[i] = c.name
+		byName[c.name] = c
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c := byName[name]
+		fmt.Fprintf(w, "  %-22s %-26s %s\n", c.name, c.args, c.summary)
+	}
+}
+
+// loadProfile 读取配置档文件并确定使用的配置档
+func (a *app) loadProfile() error {
+	store, err := loadProfiles(a.profilesPath)
+	if err != nil {
+		return err
+	}
+	a.profiles = store
+	name, profile, err := store.resolve(a.profileName)
+	if err != nil {
+		return err
+	}
+	a.profileName, a.profile = name, profile
+	return nil
+}
+
+// httpClient 请求客户端，演练模式下拦截写请求
+func (a *app) httpClient() *httpclient.SecureHTTPClient {
+	if a.client == nil {
+		transport := httpclient.NewSecureTransport()
+		if a.dryRun {
+			a.client = httpclient.NewSecureHTTPClientWithTransport(&dryRunTransport{out: a.out, log: a.errOut, real: transport})
+		} else {
+			a.client = httpclient.NewSecureHTTPClientWithTransport(transport)
+		}
+	}
+	return a.client
+}
+
+// tenantContext 携带配置档租户身份的上下文
+func (a *app) tenantContext() (context.Context, error) {
+	if a.profile.TenantID <= 0 || a.profile.Token == "" {
+		return nil, fmt.Errorf("配置档缺少租户ID或令牌，请先执行 drone-dispatch profile set")
+	}
+	ctx := tenant.WithTenant(a.ctx, a.profile.TenantInfo())
+	return tenant.WithRequestID(ctx, uuid.New().String()), nil
+}
+
+// adapter 创建司空2适配器：读取配置文件中的检查规则，配置档中的地址覆盖 FH.host
+func (a *app) adapter() (service.FH2DroneAdapter, context.Context, error) {
+	ctx, err := a.tenantContext()
+	if err != nil {
+		return nil, nil, err
+	}
+	if a.fh2 == nil {
+		if err := a.loadConfig(); err != nil {
+			return nil, nil, err
+		}
+		if a.profile.Host != "" {
+			config.SetFH2Settings(map[string]string{"host": strings.TrimRight(a.profile.Host, "/")})
+		}
+		if config.FH2Settings()["host"] == "" {
+			return nil, nil, fmt.Errorf("未配置司空2地址，请在配置档中设置 -host 或在配置文件中设置 FH.host")
+		}
+		a.fh2 = plugins.NewFH2AdapterWithClient(a.httpClient())
+	}
+	return a.fh2, ctx, nil
+}
+
+// loadConfig 读取配置文件并应用电子围栏、飞前检查、HMS与天气门限，文件不存在时使用默认值
+func (a *app) loadConfig() error {
+	if _, err := os.Stat(a.configPath); err == nil {
+		if err := config.InitConfig(a.configPath); err != nil {
+			return err
+		}
+		if err := geofence.ApplyConfig(config.GeofencesSettings()); err != nil {
+			fmt.Fprintf(a.errOut, "电子围栏导入存在错误: %v\n", err)
+		}
+		preflight.ApplyConfig(config.PreflightSettings())
+		if err := hms.ApplyConfig(config.HmsSettings()); err != nil {
+			fmt.Fprintf(a.errOut, "HMS告警配置存在错误: %v\n", err)
+		}
+		weather.ApplyConfig(config.WeatherSettings())
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+)
+
+// runCLI 执行命令行，返回退出码、标准输出与标准错误
+func runCLI(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestFlagParsing 全局参数可写在命令前或命令后，参数与位置参数可交错，参数错误返回退出码2
+func TestFlagParsing(t *testing.T) {
+	t.Setenv("DRONE_DISPATCH_PROFILE", "")
+	t.Setenv("DRONE_DISPATCH_TOKEN", "")
+	profiles := filepath.Join(t.TempDir(), "profiles.json")
+
+	if code, _, stderr := runCLI(t, "-profiles", profiles, "profile", "set", "dev", "-tenant", "1", "-token", "dev-token-123456", "-host", "https://fh2.example.com/"); code != 0 {
+		t.Fatalf("profile set 退出码 %d: %s", code, stderr)
+	}
+	// 全局参数写在位置参数之后
+	code, stdout, stderr := runCLI(t, "profile", "show", "dev", "-o", "json", "-profiles", profiles)
+	if code != 0 {
+		t.Fatalf("profile show 退出码 %d: %s", code, stderr)
+	}
+	var shown struct {
+		Name    string  `json:"name"`
+		Profile Profile `json:"profile"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &shown); err != nil {
+		t.Fatalf("-o json 应输出 JSON: %v\n%s", err, stdout)
+	}
+	if shown.Name != "dev" || shown.Profile.TenantID != 1 || shown.Profile.Host != "https://fh2.example.com" || shown.Profile.Token != "dev-****3456" {
+		t.Errorf("配置档 %+v，地址应去掉末尾斜杠、令牌应脱敏", shown)
+	}
+
+	// 只更新指定的字段，第一个配置档成为当前配置档
+	runCLI(t, "-profiles", profiles, "profile", "set", "dev", "-project", "p1")
+	store, err := loadProfiles(profiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := store.Profiles["dev"]; store.Current != "dev" || p.ProjectUUID != "p1" || p.Token != "dev-token-123456" {
+		t.Errorf("配置档文件 %+v", store)
+	}
+
+	t.Setenv("DRONE_DISPATCH_TOKEN", "env-token-abcdef")
+	if _, stdout, _ := runCLI(t, "-profiles", profiles, "-o", "json", "profile", "show"); !strings.Contains(stdout, "env-****cdef") {
+		t.Errorf("环境变量 DRONE_DISPATCH_TOKEN 应覆盖配置档中的令牌: %s", stdout)
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"缺少命令", []string{}, 2, "用法"},
+		{"未知命令", []string{"device", "reboot"}, 2, "未知命令: device reboot"},
+		{"不支持的输出格式", []string{"-o", "yaml", "profile", "list"}, 2, "不支持的输出格式 yaml"},
+		{"未定义的参数", []string{"profile", "list", "-unknown"}, 2, "flag provided but not defined"},
+		{"位置参数个数错误", []string{"profile", "use"}, 2, "用法: drone-dispatch profile use"},
+		{"配置档不存在", []string{"-profile", "prod", "profile", "list"}, 1, "配置档 prod 不存在"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := runCLI(t, append([]string{"-profiles", profiles}, tt.args...)...)
+			if code != tt.code || !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("退出码 %d，应为 %d；标准错误: %s", code, tt.code, stderr)
+			}
+		})
+	}
+}
+
+// fh2Recorder 记录收到的项目列表请求
+type fh2Recorder struct {
+	mu       sync.Mutex
+	requests []string // 查询关键字 q|令牌
+}
+
+func (r *fh2Recorder) server(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		r.requests = append(r.requests, req.URL.Query().Get("q")+"|"+req.Header.Get("X-User-Token"))
+		r.mu.Unlock()
+		fmt.Fprint(w, `{"code":0,"message":"OK","data":{"list":[{"uuid":"p1","name":"测试项目"}]}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (r *fh2Recorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.requests...)
+}
+
+// TestProfileHostOverridesConfig 配置档中的地址通过 SetFH2Settings 覆盖配置文件 FH.host，FH 段的其他配置保留；未设置地址时使用配置文件
+func TestProfileHostOverridesConfig(t *testing.T) {
+	t.Setenv("DRONE_DISPATCH_PROFILE", "")
+	t.Setenv("DRONE_DISPATCH_TOKEN", "")
+	dir := t.TempDir()
+	fromConfig, fromProfile := &fh2Recorder{}, &fh2Recorder{}
+	configServer, profileServer := fromConfig.server(t), fromProfile.server(t)
+
+	configPath := filepath.Join(dir, "config.yaml")
+	data := fmt.Sprintf("Drone:\n  Dji:\n    appId: \"123\"\nMqtt:\n  host: \"127.0.0.1\"\nFH:\n  host: %q\n  q: \"cfgproject\"\n", configServer.URL)
+	if err := os.WriteFile(configPath, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// 配置文件在进程内只加载一次，重复执行测试时按本次的配置文件内容重置 FH 段
+	config.SetFH2Settings(map[string]string{"host": configServer.URL, "q": "cfgproject"})
+	store := &profileStore{path: filepath.Join(dir, "profiles.json"), Profiles: map[string]Profile{
+		"config":   {TenantID: 1, Token: "config-token", ProjectUUID: "p1"},
+		"override": {TenantID: 1, Token: "override-token", ProjectUUID: "p1", Host: profileServer.URL + "/"},
+	}}
+	if err := store.save(); err != nil {
+		t.Fatal(err)
+	}
+	common := []string{"-config", configPath, "-profiles", store.path, "-o", "json"}
+
+	if code, _, stderr := runCLI(t, append(common, "-profile", "config", "project", "list")...); code != 0 {
+		t.Fatalf("project list 退出码 %d: %s", code, stderr)
+	}
+	if got := fromConfig.list(); len(got) != 1 || got[0] != "cfgproject|config-token" {
+		t.Errorf("未设置地址的配置档应请求配置文件中的 FH.host: %v", got)
+	}
+
+	code, stdout, stderr := runCLI(t, append(common, "-profile", "override", "project", "list")...)
+	if code != 0 {
+		t.Fatalf("project list 退出码 %d: %s", code, stderr)
+	}
+	if got := fromProfile.list(); len(got) != 1 || got[0] != "cfgproject|override-token" || len(fromConfig.list()) != 1 {
+		t.Errorf("配置档中的地址应覆盖 FH.host 并保留 FH.q: %v", got)
+	}
+	if !strings.Contains(stdout, "测试项目") {
+		t.Errorf("输出 %s", stdout)
+	}
+	if host := config.FH2Settings()["host"]; host != profileServer.URL {
+		t.Errorf("FH.host 为 %s，应为去掉末尾斜杠的配置档地址 %s", host, profileServer.URL)
+	}
+
+	runCLI(t, append(common, "-profile", "override", "project", "list", "-q", "cliproject")...)
+	if got := fromProfile.list(); len(got) != 2 || got[1] != "cliproject|override-token" {
+		t.Errorf("-q 应覆盖 FH.q: %v", got)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// 输出格式
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+)
+
+// column 表格列，path 为点分隔的字段路径，例如 gateway.sn
+type column struct {
+	title string
+	path  string
+}
+
+func cols(specs ...string) []column {
+	list := make([]column, 0, len(specs))
+	for _, spec := range specs {
+		title, path, ok := strings.Cut(spec, "=")
+		if !ok {
+			path = title
+		}
+		list = append(list, column{title: strings.ToUpper(title), path: path})
+	}
+	return list
+}
+
+// printer 按输出格式打印结果
+type printer struct {
+	out    io.Writer
+	format string
+}
+
+// print 打印结果：JSON 格式原样缩进输出；表格格式下列表按列输出，对象按 键/值 两列输出
+// 适配器返回的司空2响应 {"code":0,"data":...} 只输出 data
+func (p *printer) print(v interface{}, columns []column) error {
+	data, err := normalize(v)
+	if err != nil {
+		return err
+	}
+	if p.format != outputTable {
+		out, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.out, "%s\n", out)
+		return err
+	}
+	w := tabwriter.NewWriter(p.out, 0, 4, 2, ' ', 0)
+	if list, ok := listOf(data); ok && len(columns) > 0 {
+		titles := make([]string, len(columns))
+		for i, c := range columns {
+			titles[i] = c.title
+		}
+		fmt.Fprintln(w, strings.Join(titles, "\t"))
+		for _, item := range list {
+			cells := make([]string, len(columns))
+			for i, c := range columns {
+				cells[i] = cell(lookup(item, c.path))
+			}
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+		return w.Flush()
+	}
+	rows := make(map[string]string)
+	flatten("", data, rows)
+	keys := make([]string, 0, len(rows))
+	for key := range rows {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Fprintln(w, "KEY\tVALUE")
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s\t%s\n", key, rows[key])
+	}
+	return w.Flush()
+}
+
+// normalize 转换为通用的JSON值，字符串按JSON解析并取出司空2响应中的 data
+func normalize(v interface{}) (interface{}, error) {
+	var raw []byte
+	switch value := v.(type) {
+	case string:
+		raw = []byte(value)
+	case []byte:
+		raw = value
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		raw = encoded
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if obj, ok := data.(map[string]interface{}); ok {
+		if _, hasCode := obj["code"]; hasCode {
+			if inner, hasData := obj["data"]; hasData {
+				return inner, nil
+			}
+		}
+	}
+	return data, nil
+}
+
+// listOf 取出列表：数组本身，或对象中的 list 字段（null 视为空列表）
+func listOf(data interface{}) ([]interface{}, bool) {
+	switch value := data.(type) {
+	case []interface{}:
+		return value, true
+	case map[string]interface{}:
+		switch list := value["list"].(type) {
+		case []interface{}:
+			return list, true
+		case nil:
+			_, ok := value["list"]
+			return nil, ok
+		}
+	}
+	return nil, false
+}
+
+// lookup 按点分隔路径取值
+func lookup(v interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = obj[key]
+	}
+	return v
+}
+
+// cell 表格单元格文本
+func cell(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "-"
+	case string:
+		if value == "" {
+			return "-"
+		}
+		return value
+	case []interface{}:
+		parts := make([]string, len(value))
+		for i, item := range value {
+			parts[i] = cell(item)
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	}
+	return fmt.Sprint(v)
+}
+
+// flatten 将对象展开为 点分隔路径 -> 值
+func flatten(prefix string, v interface{}, rows map[string]string) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		if prefix == "" {
+			prefix = "value"
+		}
+		rows[prefix] = cell(v)
+		return
+	}
+	for key, value := range obj {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		flatten(path, value, rows)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+)
+
+// Profile 租户配置档，保存调用司空2与 dispatchd 所需的身份
+type Profile struct {
+	TenantID    int64  `json:"tenant_id"`
+	Token       string `json:"token,omitempty"`        // 司空2 X-User-Token，可改用环境变量 DRONE_DISPATCH_TOKEN 传入
+	ProjectUUID string `json:"project_uuid,omitempty"` // 项目UUID
+	OrgID       string `json:"org_id,omitempty"`
+	Host        string `json:"host,omitempty"`   // 司空2 OpenAPI 地址，覆盖配置文件 FH.host
+	Server      string `json:"server,omitempty"` // dispatchd 地址，插件启用与禁用通过它完成
+}
+
+// TenantInfo 转换为租户信息
+func (p Profile) TenantInfo() *tenant.TenantInfo {
+	info := tenant.NewTenantInfo(p.TenantID, p.Token, p.ProjectUUID)
+	info.OrgID = p.OrgID
+	return info
+}
+
+// Headers dispatchd REST 请求头
+func (p Profile) Headers() map[string]string {
+	headers := map[string]string{
+		tenant.HeaderTenantID:  strconv.FormatInt(p.TenantID, 10),
+		tenant.HeaderUserToken: p.Token,
+	}
+	if p.ProjectUUID != "" {
+		headers[tenant.HeaderProjectUUID] = p.ProjectUUID
+	}
+	if p.OrgID != "" {
+		headers[tenant.HeaderOrgID] = p.OrgID
+	}
+	return headers
+}
+
+// profileStore 配置档文件
+type profileStore struct {
+	path     string
+	Current  string             `json:"current,omitempty"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// defaultProfilesPath 默认配置档文件：环境变量 DRONE_DISPATCH_PROFILES，其次为用户配置目录下的 drone-dispatch/profiles.json
+func defaultProfilesPath() string {
+	if path := os.Getenv("DRONE_DISPATCH_PROFILES"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "profiles.json"
+	}
+	return filepath.Join(dir, "drone-dispatch", "profiles.json")
+}
+
+// loadProfiles 读取配置档文件，文件不存在时返回空集合
+func loadProfiles(path string) (*profileStore, error) {
+	store := &profileStore{path: path, Profiles: make(map[string]Profile)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("读取配置档文件失败: %w", err)
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("解析配置档文件 %s 失败: %w", path, err)
+	}
+	if store.Profiles == nil {
+		store.Profiles = make(map[string]Profile)
+	}
+	return store, nil
+}
+
+// save 写回配置档文件，文件含令牌，仅当前用户可读写
+func (s *profileStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("创建配置档目录失败: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("写入配置档文件失败: %w", err)
+	}
+	return nil
+}
+
+// names 按名称排序的配置档
+func (s *profileStore) names() []string {
+	names := make([]string, 0, len(s.Profiles))
+	for name := range s.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve 确定使用的配置档：-profile，其次为环境变量 DRONE_DISPATCH_PROFILE，再次为当前配置档
+// 环境变量 DRONE_DISPATCH_TOKEN 覆盖配置档中的令牌；没有任何配置档时返回空配置档
+func (s *profileStore) resolve(name string) (string, Profile, error) {
+	if name == "" {
+		name = os.Getenv("DRONE_DISPATCH_PROFILE")
+	}
+	if name == "" {
+		name = s.Current
+	}
+	var profile Profile
+	if name != "" {
+		p, ok := s.Profiles[name]
+		if !ok {
+			return "", Profile{}, fmt.Errorf("配置档 %s 不存在", name)
+		}
+		profile = p
+	}
+	if token := os.Getenv("DRONE_DISPATCH_TOKEN"); token != "" {
+		profile.Token = token
+	}
+	return name, profile, nil
+}
+
+// maskToken 令牌脱敏显示
+func maskToken(token string) string {
+	if len(token) <= 8 {
+		if token == "" {
+			return ""
+		}
+		return "****"
+	}
+	return token[:4] + "****" + token[len(token)-4:]
+}
@@ -0,0 +1,66 @@
+// fh2mock 独立运行的司空2 OpenAPI 模拟服务
+//
+//	go run ./cmd/fh2mock -addr :8090 -token dev-token -faults faults.json
+//
+// 将 config.yaml 中 FH.host 指向 http://127.0.0.1:8090 即可联调。
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/fh2mock"
+)
+
+func main() {
+	defaults := fh2mock.DefaultOptions()
+	addr := flag.String("addr", ":8090", "监听地址")
+	token := flag.String("token", "", "期望的 X-User-Token，为空时接受任意非空令牌")
+	projectUUID := flag.String("project", defaults.ProjectUUID, "预置项目UUID")
+	projectName := flag.String("project-name", defaults.ProjectName, "预置项目名称")
+	devices := flag.Int("devices", defaults.DeviceCount, "预置机场数量")
+	startDelay := flag.Duration("task-start-delay", defaults.TaskStartDelay, "任务创建到开始执行的时长")
+	taskDuration := flag.Duration("task-duration", defaults.TaskDuration, "任务执行时长")
+	flap := flag.Duration("flap", 0, "机场上下线切换间隔，0表示保持在线")
+	faultsFile := flag.String("faults", "", "故障注入规则文件（JSON数组）")
+	flag.Parse()
+
+	server := fh2mock.NewServer(fh2mock.Options{
+		UserToken:          *token,
+		ProjectUUID:        *projectUUID,
+		ProjectName:        *projectName,
+		DeviceCount:        *devices,
+		TaskStartDelay:     *startDelay,
+		TaskDuration:       *taskDuration,
+		DeviceFlapInterval: *flap,
+	})
+
+	if *faultsFile != "" {
+		data, err := os.ReadFile(*faultsFile)
+		if err != nil {
+			log.Fatalf("读取故障规则文件失败: %v", err)
+		}
+		var faults []fh2mock.Fault
+		if err := json.Unmarshal(data, &faults); err != nil {
+			log.Fatalf("解析故障规则文件失败: %v", err)
+		}
+		for _, fault := range faults {
+			server.AddFault(fault)
+		}
+		log.Printf("已加载 %d 条故障规则", len(faults))
+	}
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("司空2模拟服务已启动: %s (项目 %s)", *addr, *projectUUID)
+	if err := httpServer.ListenAndServe(); err != nil {
+		log.Fatalf("模拟服务退出: %v", err)
+	}
+}
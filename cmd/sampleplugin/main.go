@@ -0,0 +1,82 @@
+// sampleplugin 进程外插件示例：演示厂商如何在不修改本仓库的情况下发布适配器
+// 由宿主进程按配置文件 Plugins 段的 path 通过 grpcplugin.ApplyConfig 启动，不能直接运行
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/plugin/grpcplugin"
+)
+
+// sampleAdapter 示例适配器，提供设备遥测与设备定位能力
+type sampleAdapter struct {
+	mu      sync.RWMutex
+	devices map[string]bool
+}
+
+// Init 读取配置中的设备列表，例如 devices=SN1,SN2
+func (a *sampleAdapter) Init(ctx context.Context, cfg map[string]string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.devices = make(map[string]bool)
+	for _, sn := range strings.Split(cfg["devices"], ",") {
+		if sn = strings.TrimSpace(sn); sn != "" {
+			a.devices[sn] = true
+		}
+	}
+	if len(a.devices) == 0 {
+		return errors.New("配置项 devices 不能为空")
+	}
+	return nil
+}
+
+// HealthCheck 示例插件始终健康
+func (a *sampleAdapter) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// HasDevice 设备是否在配置的设备列表中
+func (a *sampleAdapter) HasDevice(ctx context.Context, deviceSn string) (bool, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.devices[deviceSn], nil
+}
+
+// GetDeviceState 返回模拟的设备状态
+func (a *sampleAdapter) GetDeviceState(ctx context.Context, deviceSn string) (string, error) {
+	if has, _ := a.HasDevice(ctx, deviceSn); !has {
+		return "", fmt.Errorf("设备 %s 不存在", deviceSn)
+	}
+	var tenantId int64
+	if tenantInfo, err := tenant.GetTenantFromContext(ctx); err == nil {
+		tenantId = tenantInfo.TenantId
+	}
+	state, err := json.Marshal(map[string]interface{}{
+		"sn":        deviceSn,
+		"tenant_id": tenantId,
+		"online":    true,
+		"battery":   87,
+		"timestamp": time.Now().UnixMilli(),
+	})
+	return string(state), err
+}
+
+func main() {
+	meta := grpcplugin.Meta{
+		PluginType:  "sample",
+		Version:     "0.1.0",
+		Vendor:      "Example",
+		Description: "进程外插件示例",
+	}
+	if err := grpcplugin.Serve(meta, &sampleAdapter{}); err != nil {
+		log.Fatalf("插件退出: %v", err)
+	}
+}
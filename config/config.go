@@ -2,21 +2,34 @@ package config
 
 import (
 	"fmt"
+	"log"
 	"strings"
 	"sync"
+	"sync/atomic"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
 // Config 结构体保持不变
 type Config struct {
-	Drone          *Drone      `mapstructure:"Drone"`
-	Mqtt           *MqttConfig `mapstructure:"Mqtt"`
-	Database       *Database   `mapstructure:"Database"`
-	RtmpURL        string      `mapstructure:"RtmpURL"`
-	AmapKey        string      `mapstructure:"AmapKey"`
-	TokenExpiresIn int         `mapstructure:"TokenExpiresIn"`
-	FH2            *FH2        `mapstructure:"FH"`
+	Drone          *Drone         `mapstructure:"Drone"`
+	Mqtt           *MqttConfig    `mapstructure:"Mqtt"`
+	Database       *Database      `mapstructure:"Database"`
+	RtmpURL        string         `mapstructure:"RtmpURL"`
+	AmapKey        string         `mapstructure:"AmapKey"`
+	TokenExpiresIn int            `mapstructure:"TokenExpiresIn"`
+	FH2            *FH2           `mapstructure:"FH"`
+	Plugins        []PluginConfig `mapstructure:"Plugins"`
+	Geofences      []GeofenceFile `mapstructure:"Geofences"`
+	Preflight      *Preflight     `mapstructure:"Preflight"`
+	Hms            *Hms           `mapstructure:"Hms"`
+	Weather        *Weather       `mapstructure:"Weather"`
+	Server         *Server        `mapstructure:"Server"`
+	Websocket      *Websocket     `mapstructure:"Websocket"`
+	Media          *Media         `mapstructure:"Media"`
+	Reconstruct    *Reconstruct   `mapstructure:"Reconstruct"`
+	LiveStream     *LiveStream    `mapstructure:"LiveStream"`
 }
 
 type Drone struct {
@@ -59,27 +72,180 @@ type FH2 struct {
 	XUserToken string `mapstructure:"xUserToken"`
 }
 
+// PluginConfig 插件声明，列在 Plugins 段中的插件在启动与配置重新加载时启用
+type PluginConfig struct {
+	Name     string            `mapstructure:"name"`     // 实例名称，同一插件类型可声明多个实例（例如多台机场），为空时同 type
+	Type     string            `mapstructure:"type"`     // 插件类型，例如 fh2、dji_dock2
+	Enabled  *bool             `mapstructure:"enabled"`  // 是否启用，默认启用
+	Settings map[string]string `mapstructure:"settings"` // 插件配置，启用时通过 Init 传入
+	Path     string            `mapstructure:"path"`     // 进程外插件可执行文件路径，配置后插件类型取自握手结果，type 可省略
+	Args     []string          `mapstructure:"args"`     // 进程外插件启动参数
+	Env      []string          `mapstructure:"env"`      // 进程外插件额外环境变量，格式 KEY=VALUE
+}
+
+// InstanceName 插件实例名称
+func (p PluginConfig) InstanceName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.Type
+}
+
+// IsEnabled 是否启用
+func (p PluginConfig) IsEnabled() bool {
+	return p.Enabled == nil || *p.Enabled
+}
+
+// Preflight 飞前检查配置，阈值为0时使用默认值；weather 规则使用 Weather 段的门限
+type Preflight struct {
+	Enabled       *bool    `mapstructure:"enabled"`        // 是否启用，默认启用
+	MinBattery    int      `mapstructure:"min_battery"`    // 电量低于该值不通过（%）
+	WarnBattery   int      `mapstructure:"warn_battery"`   // 电量低于该值警告（%）
+	RequireRTK    bool     `mapstructure:"require_rtk"`    // RTK未收敛时不通过
+	HmsFailLevel  int      `mapstructure:"hms_fail_level"` // HMS告警达到该等级不通过：1提醒 2警告
+	DisabledRules []string `mapstructure:"disabled_rules"` // 停用的规则，例如 rtk、weather
+	AuditFile     string   `mapstructure:"audit_file"`     // 检查报告存档文件（JSON Lines）
+	AuditLimit    int      `mapstructure:"audit_limit"`    // 内存中保留的最近报告数量，默认1000
+}
+
+// Hms 设备健康告警配置
+type Hms struct {
+	Language    string `mapstructure:"language"`     // 告警文案语言：zh、en，默认 zh
+	CatalogFile string `mapstructure:"catalog_file"` // 必填，完整告警码目录（大疆发布的 hms.json），与内置目录合并
+}
+
+// Weather 下发前天气门限配置，门限为0时使用默认值
+type Weather struct {
+	Enabled        *bool          `mapstructure:"enabled"`          // 是否启用，默认启用
+	Provider       string         `mapstructure:"provider"`         // 天气预报来源：amap（使用 AmapKey）、static、none，默认 amap
+	AmapURL        string         `mapstructure:"amap_url"`         // 高德Web服务地址，默认 https://restapi.amap.com
+	UseDock        *bool          `mapstructure:"use_dock"`         // 近期时段优先使用机场上报的环境数据，默认启用
+	DockHorizon    int            `mapstructure:"dock_horizon"`     // 机场环境数据适用的时段（分钟），默认60
+	MissionMinutes int            `mapstructure:"mission_minutes"`  // 未指定结束时间的任务按该时长查询天气（分钟），默认60
+	BlockOnUnknown bool           `mapstructure:"block_on_unknown"` // 无法获取天气时阻止下发，默认仅记录日志
+	MaxWindSpeed   float64        `mapstructure:"max_wind_speed"`   // 风速超过该值阻止下发（米/秒）
+	WarnWindSpeed  float64        `mapstructure:"warn_wind_speed"`  // 风速超过该值警告（米/秒）
+	MaxRainfall    int            `mapstructure:"max_rainfall"`     // 降雨量达到该等级阻止下发：1小雨 2中雨 3大雨
+	MinTemperature *float64       `mapstructure:"min_temperature"`  // 气温下限（摄氏度）
+	MaxTemperature *float64       `mapstructure:"max_temperature"`  // 气温上限（摄氏度）
+	Static         *WeatherStatic `mapstructure:"static"`           // provider 为 static 时返回的固定天气
+}
+
+// WeatherStatic 固定天气，用于测试与无外部天气服务的环境
+type WeatherStatic struct {
+	WindSpeed   float64 `mapstructure:"wind_speed"`  // 风速（米/秒）
+	Rainfall    int     `mapstructure:"rainfall"`    // 降雨量：0无雨 1小雨 2中雨 3大雨
+	Temperature float64 `mapstructure:"temperature"` // 气温（摄氏度）
+}
+
+// Server dispatchd 服务配置
+type Server struct {
+	Addr               string        `mapstructure:"addr"`                // 监听地址，默认使用环境变量 PORT，均未配置时为 :8080
+	GrpcAddr           string        `mapstructure:"grpc_addr"`           // gRPC 监听地址，为空时不启用 gRPC 接口
+	DefaultPermissions []string      `mapstructure:"default_permissions"` // 未单独配置的租户具备的权限
+	Tenants            []ServerGrant `mapstructure:"tenants"`             // 租户登记的令牌与授予的权限
+}
+
+// ServerGrant 租户身份与权限，权限格式为 平台:操作，例如 fh2:read、dock2:write、plugin:admin，支持 fh2:* 与 *
+type ServerGrant struct {
+	TenantID     int64    `mapstructure:"tenant_id"`
+	Permissions  []string `mapstructure:"permissions"`   // 为空时使用 default_permissions
+	TokenSHA256  []string `mapstructure:"token_sha256"`  // 租户用户令牌（X-User-Token）的 SHA-256 摘要，只有登记的令牌可访问服务
+	ServiceToken string   `mapstructure:"service_token"` // 租户的服务凭据（司空2组织密钥），推送轮询、任务状态与重建进度跟踪、直播刷新等后台调用使用，不沿用用户令牌
+}
+
+// Websocket 浏览器看板的 WebSocket 推送配置，数值为0时使用默认值
+type Websocket struct {
+	Path              string   `mapstructure:"path"`               // 推送路径，默认取 Drone.Dji.DjiWebsocket 地址中的路径，均未配置时为 /v1/ws
+	AllowedOrigins    []string `mapstructure:"allowed_origins"`    // 允许跨域连接的来源，* 表示全部，为空时只允许同源
+	HeartbeatInterval int      `mapstructure:"heartbeat_interval"` // 心跳间隔（秒），默认25，连续两个间隔无响应时断开
+	SendBuffer        int      `mapstructure:"send_buffer"`        // 每个连接待发送的消息数上限，超出视为消费过慢并断开，默认256
+	ReplayBuffer      int      `mapstructure:"replay_buffer"`      // 保留最近的事件数，用于断线重连后按序号续传，默认4096
+	OsdInterval       int      `mapstructure:"osd_interval"`       // 轮询型插件（如司空2）的OSD查询间隔（秒），默认2
+	HmsInterval       int      `mapstructure:"hms_interval"`       // 轮询型插件的HMS告警查询间隔（秒），默认30
+}
+
+// Media 飞行任务媒体归档配置，未配置时不自动归档
+type Media struct {
+	Enabled     *bool    `mapstructure:"enabled"`     // 是否在任务结束后自动归档，默认启用
+	Storage     string   `mapstructure:"storage"`     // 存储：local、s3，默认 local
+	Dir         string   `mapstructure:"dir"`         // 本地存储目录，默认 ./media
+	WorkDir     string   `mapstructure:"work_dir"`    // 下载工作目录（续传中的 .part 文件），默认 <dir>/.download
+	IndexFile   string   `mapstructure:"index_file"`  // 检索索引文件（JSON Lines），默认 <dir>/index.jsonl
+	Concurrency int      `mapstructure:"concurrency"` // 并发下载数，默认4
+	Delay       int      `mapstructure:"delay"`       // 任务结束后等待机场上传媒体的时长（秒），默认0
+	S3          *MediaS3 `mapstructure:"s3"`          // storage 为 s3 时的存储配置
+}
+
+// MediaS3 媒体归档的 S3 兼容存储（阿里云OSS、AWS S3、MinIO）
+type MediaS3 struct {
+	Endpoint        string `mapstructure:"endpoint"`          // 存储端点，例如 https://oss-cn-shenzhen.aliyuncs.com
+	Bucket          string `mapstructure:"bucket"`            // 存储桶
+	Region          string `mapstructure:"region"`            // 区域
+	Provider        string `mapstructure:"provider"`          // ali、aws、minio，决定区域写法与寻址方式
+	AccessKeyID     string `mapstructure:"access_key_id"`     // 访问密钥ID
+	AccessKeySecret string `mapstructure:"access_key_secret"` // 访问密钥
+	Prefix          string `mapstructure:"prefix"`            // 对象键前缀
+}
+
+// Reconstruct 模型重建编排配置，数值为0时使用默认值
+type Reconstruct struct {
+	RegistryFile    string `mapstructure:"registry_file"`     // 重建登记表（JSON Lines），记录模型与源飞行任务的对应关系，默认 ./reconstruct.jsonl
+	PollInterval    int    `mapstructure:"poll_interval"`     // 首次查询重建进度的间隔（秒），默认15
+	MaxPollInterval int    `mapstructure:"max_poll_interval"` // 进度无变化时查询间隔加倍的上限（秒），默认300
+	Timeout         int    `mapstructure:"timeout"`           // 等待重建结果的时长（秒），超时记为失败，默认86400
+	MinImages       int    `mapstructure:"min_images"`        // 提交所需的最少照片数，默认3
+	NotifyURL       string `mapstructure:"notify_url"`        // 重建结束时 POST 通知的回调地址，为空时不通知
+}
+
+// LiveStream 直播会话管理配置，数值为0时使用默认值
+type LiveStream struct {
+	VideoExpire   int    `mapstructure:"video_expire"`   // 开启直播时申请的推流Token有效期（秒），默认7200
+	RefreshBefore int    `mapstructure:"refresh_before"` // 仍有观众时提前刷新Token的时长（秒），默认300
+	ViewerTimeout int    `mapstructure:"viewer_timeout"` // 观众超过该时长未发送心跳视为离开（秒），默认60
+	IdleTimeout   int    `mapstructure:"idle_timeout"`   // 最后一位观众离开后保留会话的时长（秒），默认300，与司空2停止推流的时长一致
+	HlsURL        string `mapstructure:"hls_url"`        // HLS 播放地址模板，{host}、{app}、{stream} 替换为RTMP地址中的主机、应用名与流名，为空时不提供
+	WebRTCURL     string `mapstructure:"webrtc_url"`     // WebRTC 播放地址模板，规则同 hls_url
+}
+
+// GeofenceFile 电子围栏 GeoJSON 文件，启动与配置重新加载时导入
+type GeofenceFile struct {
+	File        string `mapstructure:"file"`         // GeoJSON 文件路径
+	TenantID    int64  `mapstructure:"tenant_id"`    // 适用租户，0 表示全部租户
+	ProjectUUID string `mapstructure:"project_uuid"` // 适用项目，为空表示租户下全部项目
+}
+
+// DefaultPlugins 配置文件没有 Plugins 段时启用的插件，与未支持插件声明的版本保持一致
+var DefaultPlugins = []PluginConfig{{Type: "fh2"}, {Type: "dji_dock2"}}
+
+// snapshot 全局设置快照，加载与重新加载配置时整体替换，读取方不得修改其中的 map
+type snapshot struct {
+	config   Config
+	dji      map[string]string
+	mmc      map[string]string
+	xag      map[string]string
+	mqtt     map[string]string
+	database map[string]string
+	ck       map[string]string
+	redis    map[string]string
+	fh2      map[string]string
+}
+
 // 全局变量和同步控制
 var (
-	cfg           Config
-	configOnce    sync.Once
-	configErr     error
-	isInitialized bool
-	autoInitOnce  sync.Once
-	autoInitErr   error
-
-	// 全局设置变量
-	DjiSettings            map[string]string
-	MMCSettings            map[string]string
-	XAGSettings            map[string]string
-	MqttSettings           map[string]string
-	DatabaseSettings       map[string]string
-	CKSettings             map[string]string
-	RedisSettings          map[string]string
-	RtmpURLSettings        string
-	AmapKeySettings        string
-	TokenExpiresInSettings int
-	FH2Settings            map[string]string
+	configOnce sync.Once
+	configErr  error
+
+	// 当前设置，配置重新加载时原子替换，读取方无需加锁
+	current atomic.Pointer[snapshot]
+	// 串行化设置的修改（加载、重新加载、运行时覆盖）
+	storeMu sync.Mutex
+	// 配置未初始化时读取到的空设置
+	emptySnapshot = newSnapshot(Config{})
+
+	// 配置重新加载回调
+	reloadMu       sync.Mutex
+	reloadHandlers []func(*Config)
 )
 
 // 默认配置路径
@@ -94,9 +260,6 @@ func InitConfig(configPath string) error {
 			configPath = DefaultConfigPath
 		}
 		configErr = loadConfig(configPath)
-		if configErr == nil {
-			isInitialized = true
-		}
 	})
 	return configErr
 }
@@ -108,30 +271,33 @@ func InitDefaultConfig() error {
 
 // IsConfigInitialized 检查配置是否已成功初始化
 func IsConfigInitialized() bool {
-	return isInitialized
+	return current.Load() != nil
 }
 
-// GetConfig 安全获取配置实例
+// GetConfig 安全获取配置实例，返回的配置为当前快照，不随重新加载变化
 func GetConfig() (*Config, error) {
-	if !isInitialized {
+	s := current.Load()
+	if s == nil {
+		if configErr != nil {
+			return nil, fmt.Errorf("配置初始化失败: %w", configErr)
+		}
 		return nil, fmt.Errorf("配置未初始化，请先调用 InitConfig 或 InitDefaultConfig")
 	}
-	if configErr != nil {
-		return nil, fmt.Errorf("配置初始化失败: %w", configErr)
-	}
+	cfg := s.config
 	return &cfg, nil
 }
 
 // GetSetting 提供统一的方法获取特定配置项
 func GetSetting(section, key string) (string, error) {
-	if !isInitialized {
+	s := current.Load()
+	if s == nil {
 		return "", fmt.Errorf("配置未初始化")
 	}
 
 	// 这里可以根据需要实现更精细的配置项获取逻辑
 	switch section {
 	case "Drone":
-		if value, exists := DjiSettings[key]; exists {
+		if value, exists := s.dji[key]; exists {
 			return value, nil
 		}
 		// 可以扩展其他section
@@ -140,6 +306,92 @@ func GetSetting(section, key string) (string, error) {
 	return "", fmt.Errorf("配置项不存在: %s.%s", section, key)
 }
 
+// settings 当前设置，未初始化时返回空设置
+func settings() *snapshot {
+	if s := current.Load(); s != nil {
+		return s
+	}
+	return emptySnapshot
+}
+
+// DjiSettings 大疆配置（Drone.Dji）
+func DjiSettings() map[string]string { return settings().dji }
+
+// MMCSettings MMC配置（Drone.MMC）
+func MMCSettings() map[string]string { return settings().mmc }
+
+// XAGSettings 极飞配置（Drone.XAG）
+func XAGSettings() map[string]string { return settings().xag }
+
+// MqttSettings MQTT配置
+func MqttSettings() map[string]string { return settings().mqtt }
+
+// DatabaseSettings 默认数据库配置
+func DatabaseSettings() map[string]string { return settings().database }
+
+// CKSettings ClickHouse 配置
+func CKSettings() map[string]string { return settings().ck }
+
+// RedisSettings Redis 配置
+func RedisSettings() map[string]string { return settings().redis }
+
+// RtmpURLSettings 直播推流地址
+func RtmpURLSettings() string { return settings().config.RtmpURL }
+
+// AmapKeySettings 高德Web服务Key
+func AmapKeySettings() string { return settings().config.AmapKey }
+
+// TokenExpiresInSettings Token有效期（秒）
+func TokenExpiresInSettings() int { return settings().config.TokenExpiresIn }
+
+// FH2Settings 司空2配置（FH 段）
+func FH2Settings() map[string]string { return settings().fh2 }
+
+// PluginsSettings 插件声明，配置文件没有 Plugins 段时为 DefaultPlugins
+func PluginsSettings() []PluginConfig { return settings().config.Plugins }
+
+// GeofencesSettings 电子围栏文件
+func GeofencesSettings() []GeofenceFile { return settings().config.Geofences }
+
+// PreflightSettings 飞前检查配置
+func PreflightSettings() *Preflight { return settings().config.Preflight }
+
+// HmsSettings HMS告警配置
+func HmsSettings() *Hms { return settings().config.Hms }
+
+// WeatherSettings 天气门限配置
+func WeatherSettings() *Weather { return settings().config.Weather }
+
+// ServerSettings dispatchd 服务配置
+func ServerSettings() *Server { return settings().config.Server }
+
+// WebsocketSettings WebSocket 推送配置
+func WebsocketSettings() *Websocket { return settings().config.Websocket }
+
+// MediaSettings 媒体归档配置
+func MediaSettings() *Media { return settings().config.Media }
+
+// ReconstructSettings 模型重建配置
+func ReconstructSettings() *Reconstruct { return settings().config.Reconstruct }
+
+// LiveStreamSettings 直播会话配置
+func LiveStreamSettings() *LiveStream { return settings().config.LiveStream }
+
+// SetFH2Settings 运行时覆盖司空2配置（例如命令行参数），values 中的项替换当前值，重新加载配置后失效
+func SetFH2Settings(values map[string]string) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	next := *settings()
+	next.fh2 = make(map[string]string, len(next.fh2)+len(values))
+	for k, v := range settings().fh2 {
+		next.fh2[k] = v
+	}
+	for k, v := range values {
+		next.fh2[k] = v
+	}
+	current.Store(&next)
+}
+
 // loadConfig 加载和解析配置文件
 func loadConfig(configPath string) error {
 	viper.SetConfigFile(configPath)
@@ -156,24 +408,83 @@ func loadConfig(configPath string) error {
 		return fmt.Errorf("配置文件读取失败: %w", err)
 	}
 
-	err = viper.Unmarshal(&cfg)
+	next, err := parseConfig()
 	if err != nil {
-		return fmt.Errorf("配置解析失败: %w", err)
+		return err
+	}
+
+	// 初始化全局设置
+	storeMu.Lock()
+	current.Store(newSnapshot(next))
+	storeMu.Unlock()
+
+	return nil
+}
+
+// parseConfig 解析已读取的配置并验证必要项
+func parseConfig() (Config, error) {
+	var next Config
+	if err := viper.Unmarshal(&next); err != nil {
+		return Config{}, fmt.Errorf("配置解析失败: %w", err)
 	}
 
 	// 验证必要配置
-	if err := validateConfig(); err != nil {
-		return fmt.Errorf("配置验证失败: %w", err)
+	if err := validateConfig(&next); err != nil {
+		return Config{}, fmt.Errorf("配置验证失败: %w", err)
 	}
 
-	// 初始化全局设置
-	initGlobalSettings()
+	// 没有 Plugins 段时沿用默认插件；显式配置为空列表时不启用插件
+	if !viper.IsSet("Plugins") {
+		next.Plugins = append([]PluginConfig(nil), DefaultPlugins...)
+	}
+	return next, nil
+}
+
+// OnReload 注册配置重新加载成功后的回调，回调参数为新配置
+func OnReload(handler func(*Config)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadHandlers = append(reloadHandlers, handler)
+}
+
+// Reload 重新读取配置文件，校验通过后原子替换全局设置并通知 OnReload 回调；失败时保留原配置
+func Reload() error {
+	if !IsConfigInitialized() {
+		return fmt.Errorf("配置未初始化，请先调用 InitConfig 或 InitDefaultConfig")
+	}
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
 
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("配置文件读取失败: %w", err)
+	}
+	next, err := parseConfig()
+	if err != nil {
+		return err
+	}
+	storeMu.Lock()
+	current.Store(newSnapshot(next))
+	storeMu.Unlock()
+
+	for _, handler := range reloadHandlers {
+		cfg := next
+		handler(&cfg)
+	}
 	return nil
 }
 
+// WatchConfig 监听配置文件变化，变化时自动 Reload
+func WatchConfig() {
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		if err := Reload(); err != nil {
+			log.Printf("重新加载配置失败: %v", err)
+		}
+	})
+	viper.WatchConfig()
+}
+
 // validateConfig 验证配置的必要项
-func validateConfig() error {
+func validateConfig(cfg *Config) error {
 	var missingFields []string
 
 	if cfg.Drone == nil {
@@ -196,134 +507,74 @@ func validateConfig() error {
 	return nil
 }
 
-// initGlobalSettings 初始化全局设置变量
-func initGlobalSettings() {
+// newSnapshot 由配置生成全局设置
+func newSnapshot(cfg Config) *snapshot {
+	s := &snapshot{config: cfg}
+
 	// 初始化无人机配置
-	initDroneSettings()
+	s.dji, s.mmc, s.xag = uavSettings(nil), uavSettings(nil), uavSettings(nil)
+	if cfg.Drone != nil {
+		s.dji = uavSettings(cfg.Drone.Dji)
+		s.mmc = uavSettings(cfg.Drone.MMC)
+		s.xag = uavSettings(cfg.Drone.XAG)
+	}
 
 	// 初始化MQTT配置
 	if cfg.Mqtt != nil {
-		MqttSettings = map[string]string{
+		s.mqtt = map[string]string{
 			"host":     cfg.Mqtt.Host,
 			"port":     cfg.Mqtt.Port,
 			"username": cfg.Mqtt.Username,
 			"password": cfg.Mqtt.Password,
 		}
 	} else {
-		MqttSettings = make(map[string]string)
+		s.mqtt = make(map[string]string)
 	}
 
 	// 初始化数据库配置
-	initDatabaseSettings()
-
-	// 初始化其他配置
-	if cfg.RtmpURL != "" {
-		RtmpURLSettings = cfg.RtmpURL
-	}
-
-	if cfg.AmapKey != "" {
-		AmapKeySettings = cfg.AmapKey
-	}
-
-	if cfg.TokenExpiresIn > 0 {
-		TokenExpiresInSettings = cfg.TokenExpiresIn
+	s.database, s.ck, s.redis = linkSettings(nil), linkSettings(nil), linkSettings(nil)
+	if cfg.Database != nil {
+		s.database = linkSettings(cfg.Database.Def)
+		s.ck = linkSettings(cfg.Database.CK)
+		s.redis = linkSettings(cfg.Database.Redis)
 	}
 
 	// 初始化FH2配置
 	if cfg.FH2 != nil {
-		FH2Settings = map[string]string{
+		s.fh2 = map[string]string{
 			"host":       cfg.FH2.Host,
 			"q":          cfg.FH2.Q,
 			"xUserToken": cfg.FH2.XUserToken,
 		}
 	} else {
-		FH2Settings = make(map[string]string)
+		s.fh2 = make(map[string]string)
 	}
+	return s
 }
 
-// initDroneSettings 初始化无人机相关设置
-func initDroneSettings() {
-	if cfg.Drone != nil {
-		if cfg.Drone.Dji != nil {
-			DjiSettings = map[string]string{
-				"appId":        cfg.Drone.Dji.AppId,
-				"appKey":       cfg.Drone.Dji.AppKey,
-				"appLicense":   cfg.Drone.Dji.AppLicense,
-				"url":          cfg.Drone.Dji.Url,
-				"GatewaySn":    cfg.Drone.Dji.GatewaySn,
-				"DjiWebsocket": cfg.Drone.Dji.DjiWebsocket,
-				"DockSn":       cfg.Drone.Dji.DockSn,
-				"ClientId":     cfg.Drone.Dji.ClientId,
-			}
-		} else {
-			DjiSettings = make(map[string]string)
-		}
-
-		if cfg.Drone.MMC != nil {
-			MMCSettings = map[string]string{
-				"appId":        cfg.Drone.MMC.AppId,
-				"appKey":       cfg.Drone.MMC.AppKey,
-				"appLicense":   cfg.Drone.MMC.AppLicense,
-				"url":          cfg.Drone.MMC.Url,
-				"GatewaySn":    cfg.Drone.MMC.GatewaySn,
-				"DjiWebsocket": cfg.Drone.MMC.DjiWebsocket,
-				"DockSn":       cfg.Drone.MMC.DockSn,
-				"ClientId":     cfg.Drone.MMC.ClientId,
-			}
-		} else {
-			MMCSettings = make(map[string]string)
-		}
-
-		if cfg.Drone.XAG != nil {
-			XAGSettings = map[string]string{
-				"appId":        cfg.Drone.XAG.AppId,
-				"appKey":       cfg.Drone.XAG.AppKey,
-				"appLicense":   cfg.Drone.XAG.AppLicense,
-				"url":          cfg.Drone.XAG.Url,
-				"GatewaySn":    cfg.Drone.XAG.GatewaySn,
-				"DjiWebsocket": cfg.Drone.XAG.DjiWebsocket,
-				"DockSn":       cfg.Drone.XAG.DockSn,
-				"ClientId":     cfg.Drone.XAG.ClientId,
-			}
-		} else {
-			XAGSettings = make(map[string]string)
-		}
-	} else {
-		DjiSettings = make(map[string]string)
-		MMCSettings = make(map[string]string)
-		XAGSettings = make(map[string]string)
+// uavSettings 无人机厂商配置转换为键值对，未配置时为空
+func uavSettings(uav *UAV) map[string]string {
+	if uav == nil {
+		return make(map[string]string)
+	}
+	return map[string]string{
+		"appId":        uav.AppId,
+		"appKey":       uav.AppKey,
+		"appLicense":   uav.AppLicense,
+		"url":          uav.Url,
+		"GatewaySn":    uav.GatewaySn,
+		"DjiWebsocket": uav.DjiWebsocket,
+		"DockSn":       uav.DockSn,
+		"ClientId":     uav.ClientId,
 	}
 }
 
-// initDatabaseSettings 初始化数据库相关设置
-func initDatabaseSettings() {
-	if cfg.Database != nil {
-		if cfg.Database.Def != nil {
-			DatabaseSettings = map[string]string{
-				"link": cfg.Database.Def.Link,
-			}
-		} else {
-			DatabaseSettings = make(map[string]string)
-		}
-
-		if cfg.Database.CK != nil {
-			CKSettings = map[string]string{
-				"link": cfg.Database.CK.Link,
-			}
-		} else {
-			CKSettings = make(map[string]string)
-		}
-
-		if cfg.Database.Redis != nil {
-			RedisSettings = map[string]string{
-				"link": cfg.Database.Redis.Link,
-			}
-		} else {
-			RedisSettings = make(map[string]string)
-		}
-	} else {
-		DatabaseSettings = make(map[string]string)
-		CKSettings = make(map[string]string)
-		RedisSettings = make(map[string]string)
+// linkSettings 数据库连接配置转换为键值对，未配置时为空
+func linkSettings(def *Def) map[string]string {
+	if def == nil {
+		return make(map[string]string)
+	}
+	return map[string]string{
+		"link": def.Link,
 	}
 }
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const baseConfig = `
+Drone:
+  Dji:
+    appId: "app"
+Mqtt:
+  host: "127.0.0.1"
+FH:
+  host: "http://fh2.test"
+`
+
+// useConfig 写入临时配置文件并加载
+func useConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	if err := loadConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		viper.Reset()
+		current.Store(nil)
+	})
+	return path
+}
+
+func TestDefaultPlugins(t *testing.T) {
+	useConfig(t, baseConfig)
+	if got := PluginsSettings(); len(got) != len(DefaultPlugins) || got[0].Type != "fh2" || got[1].Type != "dji_dock2" {
+		t.Fatalf("没有 Plugins 段时应启用默认插件，实际 %+v", got)
+	}
+
+	useConfig(t, baseConfig+"Plugins: []\n")
+	if got := PluginsSettings(); len(got) != 0 {
+		t.Fatalf("Plugins 为空列表时不应启用插件，实际 %+v", got)
+	}
+}
+
+// TestReloadConcurrentRead 重新加载与读取并发进行（配合 -race 运行）
+func TestReloadConcurrentRead(t *testing.T) {
+	path := useConfig(t, baseConfig)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if host := FH2Settings()["host"]; host == "" {
+				t.Error("读取到空的 FH.host")
+				return
+			}
+			_ = MqttSettings()["host"]
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		if err := os.WriteFile(path, []byte(baseConfig), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	SetFH2Settings(map[string]string{"q": "项目"})
+	if got := FH2Settings(); got["q"] != "项目" || got["host"] != "http://fh2.test" {
+		t.Fatalf("运行时覆盖应只替换指定项，实际 %v", got)
+	}
+}
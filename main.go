@@ -9,7 +9,15 @@ import (
 	"time"
 
 	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/geofence"
+	"gitee.com/jamespi/drone_dispatch/pkg/hms"
+	"gitee.com/jamespi/drone_dispatch/pkg/livestream"
+	"gitee.com/jamespi/drone_dispatch/pkg/media"
+	"gitee.com/jamespi/drone_dispatch/pkg/preflight"
+	"gitee.com/jamespi/drone_dispatch/pkg/reconstruct"
+	"gitee.com/jamespi/drone_dispatch/pkg/weather"
 	"gitee.com/jamespi/drone_dispatch/plugin"
+	"gitee.com/jamespi/drone_dispatch/plugin/grpcplugin"
 	_ "gitee.com/jamespi/drone_dispatch/plugin/plugins" // 自动注册插件
 	"gitee.com/jamespi/drone_dispatch/service"
 )
@@ -19,9 +27,53 @@ func main() {
 	if err := config.InitDefaultConfig(); err != nil {
 		log.Fatalf("配置初始化失败: %v", err)
 	}
-	// 司空2调用
-	// 启用指定插件
-	plugin.LoadEnableList([]string{"fh2", "dji_dock2"})
+	// 按配置文件 Plugins 段加载进程外插件并启用插件，配置文件变化时重新应用
+	if err := grpcplugin.ApplyConfig(context.Background(), config.PluginsSettings()); err != nil {
+		log.Printf("插件启用存在错误: %v", err)
+	}
+	// 导入配置文件 Geofences 段声明的电子围栏
+	if err := geofence.ApplyConfig(config.GeofencesSettings()); err != nil {
+		log.Printf("电子围栏导入存在错误: %v", err)
+	}
+	// 飞前检查阈值与停用规则
+	preflight.ApplyConfig(config.PreflightSettings())
+	// HMS告警文案语言与完整告警码目录
+	if err := hms.ApplyConfig(config.HmsSettings()); err != nil {
+		log.Printf("HMS告警配置存在错误: %v", err)
+	}
+	// 下发前天气门限与天气来源
+	weather.ApplyConfig(config.WeatherSettings())
+	// 飞行任务结束后的媒体归档
+	if err := media.ApplyConfig(config.MediaSettings()); err != nil {
+		log.Printf("媒体归档配置存在错误: %v", err)
+	}
+	// 模型重建编排与结果通知
+	if err := reconstruct.ApplyConfig(config.ReconstructSettings(), config.ServerSettings()); err != nil {
+		log.Printf("模型重建配置存在错误: %v", err)
+	}
+	// 直播会话共用、Token刷新与空闲停止
+	livestream.ApplyConfig(config.LiveStreamSettings(), config.ServerSettings())
+	config.OnReload(func(cfg *config.Config) {
+		if err := grpcplugin.ApplyConfig(context.Background(), cfg.Plugins); err != nil {
+			log.Printf("重新应用插件配置存在错误: %v", err)
+		}
+		if err := geofence.ApplyConfig(cfg.Geofences); err != nil {
+			log.Printf("重新导入电子围栏存在错误: %v", err)
+		}
+		preflight.ApplyConfig(cfg.Preflight)
+		if err := hms.ApplyConfig(cfg.Hms); err != nil {
+			log.Printf("重新应用HMS告警配置存在错误: %v", err)
+		}
+		weather.ApplyConfig(cfg.Weather)
+		if err := media.ApplyConfig(cfg.Media); err != nil {
+			log.Printf("重新应用媒体归档配置存在错误: %v", err)
+		}
+		if err := reconstruct.ApplyConfig(cfg.Reconstruct, cfg.Server); err != nil {
+			log.Printf("重新应用模型重建配置存在错误: %v", err)
+		}
+		livestream.ApplyConfig(cfg.LiveStream, cfg.Server)
+	})
+	config.WatchConfig()
 	// 多租户使用
 	demonstrateMultiTenant()
 
@@ -50,7 +102,7 @@ func demonstrateMultiTenant() {
 		if projectList, err := fh2.GetprojectList(ctx); err != nil {
 			log.Println("获取项目列表失败:", err)
 		} else {
-			log.Println("获取项目列表成功, 租户 %s 的项目列表: %s\n", tenantInfo.TenantId, projectList)
+			log.Printf("获取项目列表成功, 租户 %d 的项目列表: %s\n", tenantInfo.TenantId, projectList)
 		}
 	} else {
 		fmt.Println("插件未启用或不存在")
@@ -164,7 +216,7 @@ func demonstrateBackwardCompatible() {
 	//if dock2, ok := plugin.Get[service.DJIDock2DroneAdapter](plugin.DJIDock2Plugin); ok {
 	//	// 调用适配器方法
 	//	// 一键起飞
-	//	projectList, err := dock2.TakeOff()
+	//	projectList, err := dock2.TakeOff(context.Background())
 	//	if err != nil {
 	//		fmt.Println("获取设备物模型列表失败:", err)
 	//		return
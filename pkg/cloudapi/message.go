@@ -0,0 +1,287 @@
+// Package cloudapi DJI 上云API(Cloud API) MQTT 协议定义
+// 包含主题规则、消息信封与常用方法名，供 Dock2 适配器与设备模拟器共用。
+package cloudapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+	"github.com/google/uuid"
+)
+
+// 主题规则
+const (
+	topicOsd           = "thing/product/%s/osd"
+	topicState         = "thing/product/%s/state"
+	topicServices      = "thing/product/%s/services"
+	topicServicesReply = "thing/product/%s/services_reply"
+	topicEvents        = "thing/product/%s/events"
+	topicEventsReply   = "thing/product/%s/events_reply"
+	topicStatus        = "sys/product/%s/status"
+	topicStatusReply   = "sys/product/%s/status_reply"
+	topicDrcUp         = "thing/product/%s/drc/up"
+	topicDrcDown       = "thing/product/%s/drc/down"
+)
+
+// OsdTopic 设备定频推送属性主题
+func OsdTopic(sn string) string { return fmt.Sprintf(topicOsd, sn) }
+
+// StateTopic 设备状态变化推送主题
+func StateTopic(sn string) string { return fmt.Sprintf(topicState, sn) }
+
+// ServicesTopic 云端下发服务主题
+func ServicesTopic(gatewaySn string) string { return fmt.Sprintf(topicServices, gatewaySn) }
+
+// ServicesReplyTopic 设备服务回复主题
+func ServicesReplyTopic(gatewaySn string) string { return fmt.Sprintf(topicServicesReply, gatewaySn) }
+
+// EventsTopic 设备事件上报主题
+func EventsTopic(gatewaySn string) string { return fmt.Sprintf(topicEvents, gatewaySn) }
+
+// EventsReplyTopic 云端事件回复主题
+func EventsReplyTopic(gatewaySn string) string { return fmt.Sprintf(topicEventsReply, gatewaySn) }
+
+// StatusTopic 设备拓扑上线主题
+func StatusTopic(gatewaySn string) string { return fmt.Sprintf(topicStatus, gatewaySn) }
+
+// StatusReplyTopic 云端拓扑回复主题
+func StatusReplyTopic(gatewaySn string) string { return fmt.Sprintf(topicStatusReply, gatewaySn) }
+
+// DrcUpTopic DRC上行主题
+func DrcUpTopic(gatewaySn string) string { return fmt.Sprintf(topicDrcUp, gatewaySn) }
+
+// DrcDownTopic DRC下行主题
+func DrcDownTopic(gatewaySn string) string { return fmt.Sprintf(topicDrcDown, gatewaySn) }
+
+// 服务方法
+const (
+	MethodTakeoffToPoint         = "takeoff_to_point"
+	MethodFlyToPoint             = "fly_to_point"
+	MethodFlyToPointStop         = "fly_to_point_stop"
+	MethodReturnHome             = "return_home"
+	MethodReturnHomeCancel       = "return_home_cancel"
+	MethodFlightAuthorityGrab    = "flight_authority_grab"
+	MethodFlightAuthorityRelease = "flight_authority_release"
+	MethodPayloadAuthorityGrab   = "payload_authority_grab"
+	MethodDrcModeEnter           = "drc_mode_enter"
+	MethodDrcModeExit            = "drc_mode_exit"
+	MethodCameraModeSwitch       = "camera_mode_switch"
+	MethodCameraPhotoTake        = "camera_photo_take"
+	MethodCameraPhotoStop        = "camera_photo_stop"
+	MethodCameraRecordingStart   = "camera_recording_start"
+	MethodCameraRecordingStop    = "camera_recording_stop"
+	MethodCameraFrameZoom        = "camera_frame_zoom"
+	MethodCameraFocalLengthSet   = "camera_focal_length_set"
+	MethodCameraScreenDrag       = "camera_screen_drag"
+	MethodPhotoStorageSet        = "photo_storage_set"
+	MethodVideoStorageSet        = "video_storage_set"
+	MethodCoverOpen              = "cover_open"
+	MethodCoverClose             = "cover_close"
+	MethodLiveStartPush          = "live_start_push"
+	MethodLiveStopPush           = "live_stop_push"
+)
+
+// 事件方法
+const (
+	EventTakeoffToPointProgress = "takeoff_to_point_progress"
+	EventFlyToPointProgress     = "fly_to_point_progress"
+	EventReturnHomeInfo         = "return_home_info"
+	EventDrcStatusNotify        = "drc_status_notify"
+	EventJoystickInvalidNotify  = "joystick_invalid_notify"
+	EventHms                    = "hms"
+)
+
+// DRC 方法
+const (
+	DrcMethodStickControl  = "stick_control"
+	DrcMethodEmergencyStop = "drone_emergency_stop"
+	DrcMethodHeartBeat     = "heart_beat"
+	DrcMethodOsdInfoPush   = "osd_info_push"
+)
+
+// StatusMethodUpdateTopo 拓扑更新方法
+const StatusMethodUpdateTopo = "update_topo"
+
+// 服务回复结果码
+const (
+	ResultSuccess               = 0
+	ResultUnsupportedMethod     = 314000 // 设备不支持该方法
+	ResultInvalidState          = 314001 // 当前状态不允许执行
+	ResultInvalidParameter      = 314002 // 参数错误
+	ResultAuthorityNotAvailable = 314003 // 未获取控制权
+)
+
+// Message 上云API通用消息信封
+type Message struct {
+	Tid       string          `json:"tid"`
+	Bid       string          `json:"bid"`
+	Timestamp int64           `json:"timestamp"`
+	Gateway   string          `json:"gateway,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	NeedReply int             `json:"need_reply,omitempty"`
+	Seq       int64           `json:"seq,omitempty"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewMessage 创建消息，bid 为空时生成新的业务ID
+func NewMessage(gatewaySn, method, bid string, data interface{}) (*Message, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("序列化消息数据失败: %w", err)
+	}
+	if bid == "" {
+		bid = uuid.New().String()
+	}
+	return &Message{
+		Tid:       uuid.New().String(),
+		Bid:       bid,
+		Timestamp: time.Now().UnixMilli(),
+		Gateway:   gatewaySn,
+		Method:    method,
+		Data:      raw,
+	}, nil
+}
+
+// Reply 根据请求消息构造回复消息（保持 tid/bid 一致）
+func (m *Message) Reply(result int, output interface{}) (*Message, error) {
+	data := map[string]interface{}{"result": result}
+	if output != nil {
+		data["output"] = output
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("序列化回复数据失败: %w", err)
+	}
+	return &Message{
+		Tid:       m.Tid,
+		Bid:       m.Bid,
+		Timestamp: time.Now().UnixMilli(),
+		Gateway:   m.Gateway,
+		Method:    m.Method,
+		Data:      raw,
+	}, nil
+}
+
+// Bytes 序列化消息
+func (m *Message) Bytes() []byte {
+	data, _ := json.Marshal(m)
+	return data
+}
+
+// ParseMessage 解析消息
+func ParseMessage(payload []byte) (*Message, error) {
+	var msg Message
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return nil, fmt.Errorf("解析上云API消息失败: %w", err)
+	}
+	return &msg, nil
+}
+
+// ServiceReply services_reply 数据体
+type ServiceReply struct {
+	Result int             `json:"result"`
+	Output json.RawMessage `json:"output,omitempty"`
+}
+
+// ProgressEvent 任务类事件进度数据体（takeoff_to_point_progress、fly_to_point_progress）
+type ProgressEvent struct {
+	FlightID          string  `json:"flight_id,omitempty"`
+	FlyToID           string  `json:"fly_to_id,omitempty"`
+	Status            string  `json:"status"`
+	Result            int     `json:"result"`
+	RemainingDistance float64 `json:"remaining_distance"`
+	RemainingTime     float64 `json:"remaining_time"`
+}
+
+// 进度事件状态
+const (
+	ProgressTaskReady       = "task_ready"
+	ProgressWaylineProgress = "wayline_progress"
+	ProgressTaskFinish      = "task_finish"
+	ProgressTaskFailed      = "task_failed"
+)
+
+// HmsEvent hms 事件数据体，每次上报设备当前的全部告警
+type HmsEvent struct {
+	List []HmsItem `json:"list"`
+}
+
+// HmsItem 单条HMS告警
+type HmsItem struct {
+	Level      int     `json:"level"`       // 0通知 1提醒 2警告
+	Module     int     `json:"module"`      // 0飞行任务 1设备管理 2媒体 3HMS
+	InTheSky   int     `json:"in_the_sky"`  // 1表示飞行中产生
+	Code       string  `json:"code"`        // 告警码，例如 0x16100083
+	DeviceType string  `json:"device_type"` // 产生告警的设备类型，例如 0-91-0（Matrice 3D）、3-2-0（机场2）
+	Imminent   int     `json:"imminent"`
+	Args       HmsArgs `json:"args"`
+}
+
+// HmsArgs 告警参数，用于填充告警文案中的占位符
+type HmsArgs struct {
+	ComponentIndex int `json:"component_index"`
+	SensorIndex    int `json:"sensor_index"`
+}
+
+// Point 经纬度点位
+type Point struct {
+	Latitude  float64 `json:"latitude" validate:"lat"`
+	Longitude float64 `json:"longitude" validate:"lng"`
+	Height    float64 `json:"height"`
+}
+
+// TakeoffToPointRequest 一键起飞参数
+type TakeoffToPointRequest struct {
+	FlightID          string  `json:"flight_id" validate:"omitempty,uuid"`
+	TargetLatitude    float64 `json:"target_latitude" validate:"lat"`
+	TargetLongitude   float64 `json:"target_longitude" validate:"lng"`
+	TargetHeight      float64 `json:"target_height" validate:"min=2,max=10000"`
+	SecurityTakeoff   float64 `json:"security_takeoff_height" validate:"omitempty,min=20,max=1500"`
+	RthAltitude       float64 `json:"rth_altitude" validate:"omitempty,min=20,max=1500"`
+	MaxSpeed          float64 `json:"max_speed" validate:"omitempty,min=1,max=15"`
+	RcLostAction      int     `json:"rc_lost_action" validate:"oneof=0 1 2"` // 0悬停 1着陆 2返航
+	CommanderFlightHt float64 `json:"commander_flight_height" validate:"omitempty,min=2,max=3000"`
+}
+
+// Validate 校验参数
+func (r *TakeoffToPointRequest) Validate() error {
+	return validator.Validate(r)
+}
+
+// FlyToPointRequest 指点飞行参数
+type FlyToPointRequest struct {
+	FlyToID  string  `json:"fly_to_id" validate:"omitempty,uuid"`
+	MaxSpeed float64 `json:"max_speed" validate:"omitempty,min=1,max=15"`
+	Points   []Point `json:"points" validate:"required,max=1"` // 当前仅支持单个目标点
+}
+
+// Validate 校验参数
+func (r *FlyToPointRequest) Validate() error {
+	return validator.Validate(r)
+}
+
+// StickControl DRC杆量控制数据体，杆量范围 364~1684，中位 1024
+type StickControl struct {
+	Roll     float64 `json:"roll" validate:"min=364,max=1684"`
+	Pitch    float64 `json:"pitch" validate:"min=364,max=1684"`
+	Throttle float64 `json:"throttle" validate:"min=364,max=1684"`
+	Yaw      float64 `json:"yaw" validate:"min=364,max=1684"`
+	Seq      int64   `json:"seq"`
+}
+
+// Validate 校验参数
+func (r *StickControl) Validate() error {
+	return validator.Validate(r)
+}
+
+// StickNeutral 杆量中位值
+const StickNeutral = 1024
+
+// DrcStatus DRC链路状态
+const (
+	DrcStateDisconnected = 0
+	DrcStateConnecting   = 1
+	DrcStateConnected    = 2
+)
@@ -0,0 +1,94 @@
+package cloudapi
+
+// 机场模式 mode_code
+const (
+	DockModeIdle        = 0 // 空闲中
+	DockModeDebug       = 1 // 现场调试
+	DockModeRemoteDebug = 2 // 远程调试
+	DockModeUpgrading   = 3 // 固件升级中
+	DockModeWorking     = 4 // 作业中
+)
+
+// 飞行器模式 mode_code
+const (
+	DroneModeStandby       = 0  // 待机
+	DroneModeTakeoffPrep   = 1  // 起飞准备
+	DroneModeTakeoffReady  = 2  // 起飞准备完毕
+	DroneModeManual        = 3  // 手动飞行
+	DroneModeAutoTakeoff   = 4  // 自动起飞
+	DroneModeWayline       = 5  // 航线飞行
+	DroneModeReturnHome    = 9  // 自动返航
+	DroneModeAutoLanding   = 10 // 自动降落
+	DroneModeForcedLanding = 11 // 强制降落
+	DroneModeDisconnected  = 14 // 未连接
+	DroneModeVirtualStick  = 16 // 虚拟摇杆状态
+	DroneModeFlyTo         = 17 // 指令飞行
+)
+
+// 舱盖状态 cover_state
+const (
+	CoverClosed = 0
+	CoverOpen   = 1
+	CoverHalf   = 2
+)
+
+// DockOsd 机场OSD
+type DockOsd struct {
+	ModeCode               int              `json:"mode_code"`
+	CoverState             int              `json:"cover_state"`
+	DroneInDock            int              `json:"drone_in_dock"`
+	Latitude               float64          `json:"latitude"`
+	Longitude              float64          `json:"longitude"`
+	Height                 float64          `json:"height"`
+	EnvironmentTemperature float64          `json:"environment_temperature"`
+	Temperature            float64          `json:"temperature"`
+	Humidity               float64          `json:"humidity"`
+	WindSpeed              float64          `json:"wind_speed"`
+	Rainfall               int              `json:"rainfall"` // 0无雨 1小雨 2中雨 3大雨
+	DroneChargeState       DroneChargeState `json:"drone_charge_state"`
+}
+
+// DroneChargeState 飞行器充电状态
+type DroneChargeState struct {
+	State           int `json:"state"` // 0空闲 1充电中
+	CapacityPercent int `json:"capacity_percent"`
+}
+
+// AircraftOsd 飞行器OSD
+type AircraftOsd struct {
+	ModeCode         int          `json:"mode_code"`
+	Latitude         float64      `json:"latitude"`
+	Longitude        float64      `json:"longitude"`
+	Height           float64      `json:"height"`    // 椭球高
+	Elevation        float64      `json:"elevation"` // 相对起飞点高度
+	HorizontalSpeed  float64      `json:"horizontal_speed"`
+	VerticalSpeed    float64      `json:"vertical_speed"`
+	AttitudeHead     float64      `json:"attitude_head"`
+	HomeDistance     float64      `json:"home_distance"`
+	TotalFlightTime  float64      `json:"total_flight_time"`
+	Battery          BatteryInfo  `json:"battery"`
+	PositionState    PositionInfo `json:"position_state"`
+	WindSpeed        float64      `json:"wind_speed"`
+	WindDirection    int          `json:"wind_direction"`
+	GimbalPitch      float64      `json:"gimbal_pitch"`
+	CameraMode       int          `json:"camera_mode"`
+	Recording        bool         `json:"recording"`
+	ZoomFactor       float64      `json:"zoom_factor"`
+	StorageRemaining int          `json:"storage_remaining"`
+}
+
+// BatteryInfo 飞行器电池信息
+type BatteryInfo struct {
+	CapacityPercent  int `json:"capacity_percent"`
+	RemainFlightTime int `json:"remain_flight_time"`
+	ReturnHomePower  int `json:"return_home_power"`
+	LandingPower     int `json:"landing_power"`
+}
+
+// PositionInfo 定位信息
+type PositionInfo struct {
+	IsFixed   int `json:"is_fixed"` // 0未开始 1收敛中 2收敛成功 3收敛失败
+	Quality   int `json:"quality"`
+	GpsNumber int `json:"gps_number"`
+	RtkNumber int `json:"rtk_number"`
+}
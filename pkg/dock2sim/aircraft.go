@@ -0,0 +1,155 @@
+package dock2sim
+
+import (
+	"math"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/cloudapi"
+)
+
+// 地球近似常量：每纬度对应的米数
+const metersPerDegree = 111320.0
+
+// flightPhase 飞行阶段
+type flightPhase int
+
+const (
+	phaseDocked    flightPhase = iota // 停在机场内
+	phaseCoverOpen                    // 舱盖开启中
+	phaseTakeoff                      // 垂直爬升至安全起飞高度
+	phaseCruise                       // 飞向目标点
+	phaseHover                        // 悬停
+	phaseReturn                       // 返航（先爬升至返航高度再飞回机场）
+	phaseLanding                      // 降落
+	phaseForced                       // 低电量强制降落
+	phaseLanded                       // 降落在野外
+)
+
+// aircraft 飞行器运动学状态
+type aircraft struct {
+	phase        flightPhase
+	lat, lng     float64
+	height       float64 // 相对起飞点高度(m)
+	hSpeed       float64 // 水平速度(m/s)
+	vSpeed       float64 // 垂直速度(m/s)
+	heading      float64 // 航向(度)，正北为0
+	battery      float64 // 电量百分比
+	flightTime   float64 // 本次飞行累计时长(s)
+	phaseElapsed float64 // 当前阶段已持续时长(s)
+
+	targets      []cloudapi.Point // 待飞航点
+	maxSpeed     float64
+	rthAltitude  float64
+	takeoffAlt   float64
+	flightID     string
+	flyToID      string
+	initialRange float64 // 当前飞行段初始距离，用于计算进度
+
+	stick    cloudapi.StickControl // 最近一次杆量
+	stickAge float64               // 距最近一次杆量的时长(s)
+}
+
+// inAir 是否在空中
+func (a *aircraft) inAir() bool {
+	switch a.phase {
+	case phaseTakeoff, phaseCruise, phaseHover, phaseReturn, phaseLanding, phaseForced:
+		return true
+	}
+	return false
+}
+
+// modeCode 根据飞行阶段返回 Cloud API 飞行器模式
+func (a *aircraft) modeCode(drc bool) int {
+	switch a.phase {
+	case phaseCoverOpen:
+		return cloudapi.DroneModeTakeoffPrep
+	case phaseTakeoff:
+		return cloudapi.DroneModeAutoTakeoff
+	case phaseCruise:
+		if a.flyToID != "" {
+			return cloudapi.DroneModeFlyTo
+		}
+		return cloudapi.DroneModeAutoTakeoff
+	case phaseHover:
+		if drc {
+			return cloudapi.DroneModeVirtualStick
+		}
+		return cloudapi.DroneModeManual
+	case phaseReturn:
+		return cloudapi.DroneModeReturnHome
+	case phaseLanding:
+		return cloudapi.DroneModeAutoLanding
+	case phaseForced:
+		return cloudapi.DroneModeForcedLanding
+	}
+	return cloudapi.DroneModeStandby
+}
+
+// distanceTo 计算到目标点的水平距离(m)
+func (a *aircraft) distanceTo(lat, lng float64) float64 {
+	north, east := offsetMeters(a.lat, a.lng, lat, lng)
+	return math.Hypot(north, east)
+}
+
+// moveTowards 以指定速度向目标水平移动 dt 秒，返回是否到达
+func (a *aircraft) moveTowards(lat, lng, speed, dt float64) bool {
+	north, east := offsetMeters(a.lat, a.lng, lat, lng)
+	distance := math.Hypot(north, east)
+	step := speed * dt
+	if distance <= step || distance < 0.5 {
+		a.lat, a.lng = lat, lng
+		a.hSpeed = 0
+		return true
+	}
+	a.heading = math.Mod(math.Atan2(east, north)*180/math.Pi+360, 360)
+	ratio := step / distance
+	a.lat += north * ratio / metersPerDegree
+	a.lng += east * ratio / (metersPerDegree * math.Cos(a.lat*math.Pi/180))
+	a.hSpeed = speed
+	return false
+}
+
+// climbTowards 以指定速率调整高度 dt 秒，返回是否到达
+func (a *aircraft) climbTowards(height, rate, dt float64) bool {
+	diff := height - a.height
+	step := rate * dt
+	if math.Abs(diff) <= step {
+		a.height = height
+		a.vSpeed = 0
+		return true
+	}
+	if diff > 0 {
+		a.height += step
+		a.vSpeed = rate
+	} else {
+		a.height -= step
+		a.vSpeed = -rate
+	}
+	return false
+}
+
+// applyStick 按杆量计算虚拟摇杆飞行，杆量偏离中位越大速度越快
+func (a *aircraft) applyStick(maxSpeed, climbRate, dt float64) {
+	const stickRange = 660.0 // 1684 - 1024
+	pitch := (a.stick.Pitch - cloudapi.StickNeutral) / stickRange
+	roll := (a.stick.Roll - cloudapi.StickNeutral) / stickRange
+	throttle := (a.stick.Throttle - cloudapi.StickNeutral) / stickRange
+	yaw := (a.stick.Yaw - cloudapi.StickNeutral) / stickRange
+
+	a.heading = math.Mod(a.heading+yaw*90*dt+360, 360)
+	rad := a.heading * math.Pi / 180
+	forward, right := pitch*maxSpeed, roll*maxSpeed
+	north := forward*math.Cos(rad) - right*math.Sin(rad)
+	east := forward*math.Sin(rad) + right*math.Cos(rad)
+	a.lat += north * dt / metersPerDegree
+	a.lng += east * dt / (metersPerDegree * math.Cos(a.lat*math.Pi/180))
+	a.hSpeed = math.Hypot(north, east)
+	a.vSpeed = throttle * climbRate
+	a.height = math.Max(0, a.height+a.vSpeed*dt)
+}
+
+// offsetMeters 计算两点间的北向/东向偏移(m)
+func offsetMeters(fromLat, fromLng, toLat, toLng float64) (float64, float64) {
+	north := (toLat - fromLat) * metersPerDegree
+	east := (toLng - fromLng) * metersPerDegree * math.Cos(fromLat*math.Pi/180)
+	return north, east
+}
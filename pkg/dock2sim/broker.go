@@ -0,0 +1,46 @@
+package dock2sim
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+)
+
+// Broker 内嵌MQTT代理，供测试与本地开发使用（不做鉴权）
+type Broker struct {
+	server   *mochi.Server
+	listener *listeners.TCP
+}
+
+// StartBroker 启动内嵌MQTT代理，addr 为监听地址，例如 "127.0.0.1:0" 表示随机端口
+func StartBroker(addr string) (*Broker, error) {
+	server := mochi.New(&mochi.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err := server.AddHook(new(auth.AllowHook), nil); err != nil {
+		return nil, fmt.Errorf("添加MQTT鉴权钩子失败: %w", err)
+	}
+	listener := listeners.NewTCP(listeners.Config{ID: "dock2sim-tcp", Address: addr})
+	if err := server.AddListener(listener); err != nil {
+		return nil, fmt.Errorf("MQTT代理监听失败: %w", err)
+	}
+	if err := server.Serve(); err != nil {
+		return nil, fmt.Errorf("启动MQTT代理失败: %w", err)
+	}
+	return &Broker{server: server, listener: listener}, nil
+}
+
+// URL 返回代理地址，例如 tcp://127.0.0.1:1883
+func (b *Broker) URL() string {
+	return "tcp://" + b.listener.Address()
+}
+
+// Close 关闭代理
+func (b *Broker) Close() error {
+	return b.server.Close()
+}
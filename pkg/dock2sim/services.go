@@ -0,0 +1,328 @@
+package dock2sim
+
+import (
+	"fmt"
+	"math"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/cloudapi"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+)
+
+// onServices 处理 services 主题消息并回复 services_reply
+func (s *Simulator) onServices(_ mqtt.Client, message mqtt.Message) {
+	msg, err := cloudapi.ParseMessage(message.Payload())
+	if err != nil {
+		s.logf("%v", err)
+		return
+	}
+	reply := s.HandleService(msg)
+	s.mu.Lock()
+	s.outbox = append(s.outbox, outMessage{topic: cloudapi.ServicesReplyTopic(s.opts.DockSN), payload: reply.Bytes()})
+	s.mu.Unlock()
+	s.flush()
+}
+
+// onDrcDown 处理 drc/down 主题消息
+func (s *Simulator) onDrcDown(_ mqtt.Client, message mqtt.Message) {
+	msg, err := cloudapi.ParseMessage(message.Payload())
+	if err != nil {
+		s.logf("%v", err)
+		return
+	}
+	s.HandleDrc(msg)
+	s.flush()
+}
+
+// HandleService 处理一条 services 请求并返回 services_reply 消息
+// 不经过MQTT时可直接调用，产生的事件会在下一次 Step 或 flush 时发布
+func (s *Simulator) HandleService(msg *cloudapi.Message) *cloudapi.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, output := s.handleServiceLocked(msg)
+	if result != cloudapi.ResultSuccess {
+		s.logf("服务 %s 执行失败: %d", msg.Method, result)
+	}
+	reply, err := msg.Reply(result, output)
+	if err != nil {
+		reply, _ = msg.Reply(cloudapi.ResultInvalidParameter, nil)
+	}
+	return reply
+}
+
+// handleServiceLocked 按方法分发服务请求，调用方需持有锁
+func (s *Simulator) handleServiceLocked(msg *cloudapi.Message) (int, interface{}) {
+	a := &s.air
+	switch msg.Method {
+	case cloudapi.MethodFlightAuthorityGrab:
+		s.flightAuthority = true
+	case cloudapi.MethodFlightAuthorityRelease:
+		s.flightAuthority = false
+		s.setDrc(cloudapi.DrcStateDisconnected)
+	case cloudapi.MethodPayloadAuthorityGrab:
+		s.payloadAuthority = true
+
+	case cloudapi.MethodTakeoffToPoint:
+		var req cloudapi.TakeoffToPointRequest
+		if err := decodeData(msg, &req); err != nil {
+			return cloudapi.ResultInvalidParameter, nil
+		}
+		if a.phase != phaseDocked {
+			return cloudapi.ResultInvalidState, nil
+		}
+		if a.battery <= float64(s.opts.ReturnHomePct) {
+			return cloudapi.ResultInvalidState, map[string]string{"reason": "battery_low"}
+		}
+		if err := req.Validate(); err != nil {
+			return cloudapi.ResultInvalidParameter, map[string]string{"reason": err.Error()}
+		}
+		a.flightID = req.FlightID
+		if a.flightID == "" {
+			a.flightID = uuid.New().String()
+		}
+		a.flyToID = ""
+		a.takeoffAlt = math.Max(req.SecurityTakeoff, 20)
+		a.targets = []cloudapi.Point{{Latitude: req.TargetLatitude, Longitude: req.TargetLongitude, Height: req.TargetHeight}}
+		a.maxSpeed = speedOrDefault(req.MaxSpeed, s.opts.MaxSpeed)
+		if req.RthAltitude > 0 {
+			a.rthAltitude = req.RthAltitude
+		}
+		s.flightAuthority = true
+		s.setDockMode(cloudapi.DockModeWorking)
+		s.setPhase(phaseCoverOpen)
+		s.queueProgress(cloudapi.ProgressTaskReady, cloudapi.ResultSuccess)
+		return cloudapi.ResultSuccess, map[string]string{"flight_id": a.flightID}
+
+	case cloudapi.MethodFlyToPoint:
+		var req cloudapi.FlyToPointRequest
+		if err := decodeData(msg, &req); err != nil {
+			return cloudapi.ResultInvalidParameter, nil
+		}
+		if err := req.Validate(); err != nil {
+			return cloudapi.ResultInvalidParameter, map[string]string{"reason": err.Error()}
+		}
+		if !s.flightAuthority {
+			return cloudapi.ResultAuthorityNotAvailable, nil
+		}
+		if a.phase != phaseHover && a.phase != phaseCruise {
+			return cloudapi.ResultInvalidState, nil
+		}
+		a.flyToID = req.FlyToID
+		if a.flyToID == "" {
+			a.flyToID = uuid.New().String()
+		}
+		a.targets = req.Points
+		a.maxSpeed = speedOrDefault(req.MaxSpeed, s.opts.MaxSpeed)
+		s.setPhase(phaseCruise)
+		s.queueProgress(cloudapi.ProgressTaskReady, cloudapi.ResultSuccess)
+
+	case cloudapi.MethodFlyToPointStop:
+		if a.phase != phaseCruise {
+			return cloudapi.ResultInvalidState, nil
+		}
+		s.queueProgress(cloudapi.ProgressTaskFailed, cloudapi.ResultSuccess)
+		a.targets, a.flyToID = nil, ""
+		s.setPhase(phaseHover)
+
+	case cloudapi.MethodReturnHome:
+		if !a.inAir() || a.phase == phaseLanding || a.phase == phaseForced {
+			return cloudapi.ResultInvalidState, nil
+		}
+		s.startReturnHome()
+
+	case cloudapi.MethodReturnHomeCancel:
+		if a.phase != phaseReturn {
+			return cloudapi.ResultInvalidState, nil
+		}
+		s.setPhase(phaseHover)
+
+	case cloudapi.MethodDrcModeEnter:
+		if !s.flightAuthority {
+			return cloudapi.ResultAuthorityNotAvailable, nil
+		}
+		s.setDrc(cloudapi.DrcStateConnected)
+	case cloudapi.MethodDrcModeExit:
+		s.setDrc(cloudapi.DrcStateDisconnected)
+
+	case cloudapi.MethodCameraModeSwitch:
+		var req struct {
+			CameraMode int `json:"camera_mode"`
+		}
+		if err := decodeData(msg, &req); err != nil {
+			return cloudapi.ResultInvalidParameter, nil
+		}
+		s.cameraMode = req.CameraMode
+	case cloudapi.MethodCameraPhotoTake:
+		if !a.inAir() {
+			return cloudapi.ResultInvalidState, nil
+		}
+		s.photoCount++
+		return cloudapi.ResultSuccess, map[string]interface{}{"photo_count": s.photoCount}
+	case cloudapi.MethodCameraPhotoStop:
+	case cloudapi.MethodCameraRecordingStart:
+		if !a.inAir() || s.recording {
+			return cloudapi.ResultInvalidState, nil
+		}
+		s.recording = true
+	case cloudapi.MethodCameraRecordingStop:
+		if !s.recording {
+			return cloudapi.ResultInvalidState, nil
+		}
+		s.recording = false
+	case cloudapi.MethodCameraFrameZoom, cloudapi.MethodCameraFocalLengthSet:
+		var req struct {
+			ZoomFactor float64 `json:"zoom_factor"`
+		}
+		if err := decodeData(msg, &req); err != nil {
+			return cloudapi.ResultInvalidParameter, nil
+		}
+		if req.ZoomFactor > 0 {
+			s.zoomFactor = math.Min(req.ZoomFactor, 56)
+		}
+	case cloudapi.MethodCameraScreenDrag:
+		var req struct {
+			PitchSpeed float64 `json:"pitch_speed"`
+		}
+		if err := decodeData(msg, &req); err != nil {
+			return cloudapi.ResultInvalidParameter, nil
+		}
+		s.gimbalPitch = math.Max(-90, math.Min(35, s.gimbalPitch+req.PitchSpeed))
+	case cloudapi.MethodPhotoStorageSet, cloudapi.MethodVideoStorageSet:
+
+	case cloudapi.MethodCoverOpen:
+		if a.phase != phaseDocked {
+			return cloudapi.ResultInvalidState, nil
+		}
+		s.setCover(cloudapi.CoverOpen)
+	case cloudapi.MethodCoverClose:
+		if a.phase != phaseDocked {
+			return cloudapi.ResultInvalidState, nil
+		}
+		s.setCover(cloudapi.CoverClosed)
+
+	case cloudapi.MethodLiveStartPush:
+		var req struct {
+			URL     string `json:"url"`
+			VideoID string `json:"video_id"`
+		}
+		if err := decodeData(msg, &req); err != nil || req.VideoID == "" {
+			return cloudapi.ResultInvalidParameter, nil
+		}
+		s.liveStreams[req.VideoID] = req.URL
+		s.queueLiveStatus()
+	case cloudapi.MethodLiveStopPush:
+		var req struct {
+			VideoID string `json:"video_id"`
+		}
+		if err := decodeData(msg, &req); err != nil {
+			return cloudapi.ResultInvalidParameter, nil
+		}
+		delete(s.liveStreams, req.VideoID)
+		s.queueLiveStatus()
+
+	default:
+		return cloudapi.ResultUnsupportedMethod, nil
+	}
+	return cloudapi.ResultSuccess, nil
+}
+
+// HandleDrc 处理一条 drc/down 消息：杆量控制、急停与心跳
+func (s *Simulator) HandleDrc(msg *cloudapi.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := &s.air
+
+	switch msg.Method {
+	case cloudapi.DrcMethodStickControl:
+		var stick cloudapi.StickControl
+		if err := decodeData(msg, &stick); err != nil {
+			return
+		}
+		if reason := s.joystickInvalidReason(); reason != "" {
+			s.queueEvent(cloudapi.EventJoystickInvalidNotify, map[string]interface{}{"reason": reason})
+			return
+		}
+		if a.phase == phaseCruise {
+			// 杆量打断自动飞行，转为虚拟摇杆悬停
+			s.queueProgress(cloudapi.ProgressTaskFailed, cloudapi.ResultSuccess)
+			a.targets, a.flyToID, a.flightID = nil, "", ""
+			s.setPhase(phaseHover)
+		}
+		a.stick = stick
+		a.stickAge = 0
+	case cloudapi.DrcMethodEmergencyStop:
+		if a.inAir() && a.phase != phaseForced {
+			a.targets, a.flyToID, a.flightID = nil, "", ""
+			a.stick = neutralStick()
+			s.setPhase(phaseHover)
+		}
+		s.queueMessage(cloudapi.DrcUpTopic(s.opts.DockSN), cloudapi.DrcMethodEmergencyStop, map[string]int{"result": cloudapi.ResultSuccess}, 0)
+	case cloudapi.DrcMethodHeartBeat:
+		var beat map[string]interface{}
+		decodeData(msg, &beat)
+		s.queueMessage(cloudapi.DrcUpTopic(s.opts.DockSN), cloudapi.DrcMethodHeartBeat, beat, 0)
+	}
+}
+
+// joystickInvalidReason 返回杆量无效原因，为空表示有效
+func (s *Simulator) joystickInvalidReason() string {
+	switch {
+	case s.drcState != cloudapi.DrcStateConnected:
+		return "drc_not_connected"
+	case !s.flightAuthority:
+		return "authority_lost"
+	case !s.air.inAir():
+		return "drone_not_in_air"
+	case s.air.phase == phaseReturn || s.air.phase == phaseLanding || s.air.phase == phaseForced:
+		return "low_battery_or_return_home"
+	}
+	return ""
+}
+
+// setDrc 设置DRC链路状态并上报 drc_status_notify
+func (s *Simulator) setDrc(state int) {
+	if s.drcState == state {
+		return
+	}
+	s.drcState = state
+	s.air.stick = neutralStick()
+	s.queueEvent(cloudapi.EventDrcStatusNotify, map[string]int{"result": cloudapi.ResultSuccess, "drc_state": state})
+}
+
+// queueLiveStatus 上报直播状态
+func (s *Simulator) queueLiveStatus() {
+	status := make([]map[string]interface{}, 0, len(s.liveStreams))
+	for videoID, url := range s.liveStreams {
+		status = append(status, map[string]interface{}{"video_id": videoID, "url": url, "status": 1})
+	}
+	s.queueMessage(cloudapi.StateTopic(s.opts.DockSN), "", map[string]interface{}{
+		"live_status": status,
+	}, 0)
+}
+
+// neutralStick 返回中位杆量
+func neutralStick() cloudapi.StickControl {
+	return cloudapi.StickControl{
+		Roll:     cloudapi.StickNeutral,
+		Pitch:    cloudapi.StickNeutral,
+		Throttle: cloudapi.StickNeutral,
+		Yaw:      cloudapi.StickNeutral,
+	}
+}
+
+// speedOrDefault 校验速度参数
+func speedOrDefault(speed, fallback float64) float64 {
+	if speed <= 0 || speed > 15 {
+		return fallback
+	}
+	return speed
+}
+
+// String 输出模拟器状态摘要，便于调试
+func (s *Simulator) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf("dock=%s mode=%d cover=%d drone=%s phase=%d lat=%.6f lng=%.6f h=%.1f battery=%.1f%%",
+		s.opts.DockSN, s.dockMode, s.coverState, s.opts.DroneSN, s.air.phase, s.air.lat, s.air.lng, s.air.height, s.air.battery)
+}
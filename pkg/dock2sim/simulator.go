@@ -0,0 +1,635 @@
+// Package dock2sim DJI 机场2 设备模拟器
+// 通过 MQTT 按上云API(Cloud API)协议模拟机场与飞行器：定频推送 osd/state，响应 services 并回复 services_reply，
+// 以 events 上报起飞、指点飞行、返航进度，同时模拟飞行运动、电量消耗与低电量返航。
+// 可在 Go 测试中直接使用（配合 StartBroker 内嵌代理，或调用 Step 手动推进），也可通过 cmd/dock2sim 独立运行。
+package dock2sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/cloudapi"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// maxOutbox 未连接时待发布队列保留的最大消息数
+const maxOutbox = 1000
+
+// Options 模拟器配置
+type Options struct {
+	DockSN         string        // 机场序列号
+	DroneSN        string        // 飞行器序列号
+	Latitude       float64       // 机场纬度
+	Longitude      float64       // 机场经度
+	OsdInterval    time.Duration // OSD推送间隔
+	TickInterval   time.Duration // 物理仿真步长
+	MaxSpeed       float64       // 默认最大水平速度(m/s)
+	ClimbRate      float64       // 爬升速率(m/s)
+	DescendRate    float64       // 下降速率(m/s)
+	RthAltitude    float64       // 默认返航高度(m)
+	CoverOpenTime  time.Duration // 舱盖开启耗时
+	DrainPerSecond float64       // 飞行中每秒耗电百分比
+	ChargePerSec   float64       // 机场内每秒充电百分比
+	ReturnHomePct  int           // 低电量自动返航阈值
+	LandingPct     int           // 严重低电量强制降落阈值
+	InitialBattery float64       // 初始电量
+	WindSpeed      float64       // 环境风速(m/s)
+	Rainfall       int           // 降雨等级 0~3
+	Temperature    float64       // 环境温度(℃)
+	Logger         *log.Logger   // 日志，为nil时不输出
+}
+
+// DefaultOptions 默认配置
+func DefaultOptions() Options {
+	return Options{
+		DockSN:         "7CTXN4A00B0001H",
+		DroneSN:        "1581F6Q8D2421001P",
+		Latitude:       22.5431,
+		Longitude:      113.9344,
+		OsdInterval:    time.Second,
+		TickInterval:   100 * time.Millisecond,
+		MaxSpeed:       12,
+		ClimbRate:      5,
+		DescendRate:    3,
+		RthAltitude:    100,
+		CoverOpenTime:  3 * time.Second,
+		DrainPerSecond: 0.05,
+		ChargePerSec:   0.2,
+		ReturnHomePct:  25,
+		LandingPct:     10,
+		InitialBattery: 100,
+		WindSpeed:      3,
+		Temperature:    25,
+	}
+}
+
+// Simulator 机场2+飞行器模拟器
+type Simulator struct {
+	opts   Options
+	client mqtt.Client
+
+	mu               sync.Mutex
+	dockMode         int
+	coverState       int
+	air              aircraft
+	flightAuthority  bool
+	payloadAuthority bool
+	drcState         int
+	cameraMode       int
+	recording        bool
+	photoCount       int
+	zoomFactor       float64
+	gimbalPitch      float64
+	liveStreams      map[string]string // 直播中的相机 key: video_id
+	outbox           []outMessage      // 待发布的消息，在锁外发布
+	osdElapsed       time.Duration
+
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+	running bool
+}
+
+// outMessage 待发布消息
+type outMessage struct {
+	topic   string
+	payload []byte
+}
+
+// New 创建模拟器
+func New(opts Options) *Simulator {
+	defaults := DefaultOptions()
+	if opts.DockSN == "" {
+		opts.DockSN = defaults.DockSN
+	}
+	if opts.DroneSN == "" {
+		opts.DroneSN = defaults.DroneSN
+	}
+	if opts.Latitude == 0 && opts.Longitude == 0 {
+		opts.Latitude, opts.Longitude = defaults.Latitude, defaults.Longitude
+	}
+	if opts.OsdInterval <= 0 {
+		opts.OsdInterval = defaults.OsdInterval
+	}
+	if opts.TickInterval <= 0 {
+		opts.TickInterval = defaults.TickInterval
+	}
+	if opts.MaxSpeed <= 0 {
+		opts.MaxSpeed = defaults.MaxSpeed
+	}
+	if opts.ClimbRate <= 0 {
+		opts.ClimbRate = defaults.ClimbRate
+	}
+	if opts.DescendRate <= 0 {
+		opts.DescendRate = defaults.DescendRate
+	}
+	if opts.RthAltitude <= 0 {
+		opts.RthAltitude = defaults.RthAltitude
+	}
+	if opts.CoverOpenTime <= 0 {
+		opts.CoverOpenTime = defaults.CoverOpenTime
+	}
+	if opts.DrainPerSecond <= 0 {
+		opts.DrainPerSecond = defaults.DrainPerSecond
+	}
+	if opts.ChargePerSec <= 0 {
+		opts.ChargePerSec = defaults.ChargePerSec
+	}
+	if opts.ReturnHomePct <= 0 {
+		opts.ReturnHomePct = defaults.ReturnHomePct
+	}
+	if opts.LandingPct <= 0 {
+		opts.LandingPct = defaults.LandingPct
+	}
+	if opts.InitialBattery <= 0 {
+		opts.InitialBattery = defaults.InitialBattery
+	}
+
+	return &Simulator{
+		opts:        opts,
+		dockMode:    cloudapi.DockModeIdle,
+		coverState:  cloudapi.CoverClosed,
+		zoomFactor:  1,
+		liveStreams: make(map[string]string),
+		air: aircraft{
+			phase:       phaseDocked,
+			lat:         opts.Latitude,
+			lng:         opts.Longitude,
+			battery:     opts.InitialBattery,
+			maxSpeed:    opts.MaxSpeed,
+			rthAltitude: opts.RthAltitude,
+		},
+	}
+}
+
+// Options 返回生效的配置
+func (s *Simulator) Options() Options {
+	return s.opts
+}
+
+// Connect 连接MQTT代理并订阅 services 与 drc/down 主题，随后上报设备拓扑
+func (s *Simulator) Connect(brokerURL, username, password string) error {
+	opts := mqtt.NewClientOptions().
+		AddBroker(brokerURL).
+		SetClientID("dock2sim-" + s.opts.DockSN).
+		SetUsername(username).
+		SetPassword(password).
+		SetAutoReconnect(true).
+		SetConnectTimeout(10 * time.Second)
+	opts.SetOnConnectHandler(func(client mqtt.Client) {
+		client.Subscribe(cloudapi.ServicesTopic(s.opts.DockSN), 1, s.onServices)
+		client.Subscribe(cloudapi.DrcDownTopic(s.opts.DockSN), 0, s.onDrcDown)
+		s.publishTopo()
+	})
+
+	client := mqtt.NewClient(opts)
+	token := client.Connect()
+	if !token.WaitTimeout(10 * time.Second) {
+		return fmt.Errorf("连接MQTT代理超时: %s", brokerURL)
+	}
+	if err := token.Error(); err != nil {
+		return fmt.Errorf("连接MQTT代理失败: %w", err)
+	}
+	s.mu.Lock()
+	s.client = client
+	s.mu.Unlock()
+	// 连接回调先于 s.client 赋值执行，其中排队的拓扑与状态消息在此发布
+	s.flush()
+	return nil
+}
+
+// Start 启动仿真循环，按 TickInterval 推进物理状态并按 OsdInterval 推送OSD
+func (s *Simulator) Start() {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	s.stopCh = make(chan struct{})
+	s.doneCh = make(chan struct{})
+	s.mu.Unlock()
+
+	go func() {
+		defer close(s.doneCh)
+		ticker := time.NewTicker(s.opts.TickInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stopCh:
+				return
+			case <-ticker.C:
+				s.Step(s.opts.TickInterval)
+			}
+		}
+	}()
+}
+
+// Stop 停止仿真循环并断开MQTT连接
+func (s *Simulator) Stop() {
+	s.mu.Lock()
+	running := s.running
+	s.running = false
+	client := s.client
+	s.client = nil
+	s.mu.Unlock()
+
+	if running {
+		close(s.stopCh)
+		<-s.doneCh
+	}
+	if client != nil && client.IsConnected() {
+		client.Disconnect(250)
+	}
+}
+
+// Step 推进仿真 dt 时长，产生的事件与到期的OSD会立即发布
+// 测试中可不调用 Start，直接通过 Step 确定性地推进状态
+func (s *Simulator) Step(dt time.Duration) {
+	s.mu.Lock()
+	s.stepLocked(dt.Seconds())
+	s.osdElapsed += dt
+	if s.osdElapsed >= s.opts.OsdInterval {
+		s.osdElapsed = 0
+		s.queueOsd()
+		// 飞行进度与OSD同频上报
+		if s.air.phase == phaseCruise && len(s.air.targets) > 0 {
+			s.queueProgress(cloudapi.ProgressWaylineProgress, cloudapi.ResultSuccess)
+		}
+	}
+	s.mu.Unlock()
+	s.flush()
+}
+
+// DockOsd 返回当前机场OSD快照
+func (s *Simulator) DockOsd() cloudapi.DockOsd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dockOsd()
+}
+
+// AircraftOsd 返回当前飞行器OSD快照
+func (s *Simulator) AircraftOsd() cloudapi.AircraftOsd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.aircraftOsd()
+}
+
+// SetWeather 设置环境天气（风速m/s、降雨等级）
+func (s *Simulator) SetWeather(windSpeed float64, rainfall int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opts.WindSpeed = windSpeed
+	s.opts.Rainfall = rainfall
+}
+
+// ReportHms 上报 hms 事件（设备当前全部告警），传入空列表表示告警全部消除
+func (s *Simulator) ReportHms(items []cloudapi.HmsItem) {
+	if items == nil {
+		items = []cloudapi.HmsItem{}
+	}
+	s.mu.Lock()
+	s.queueMessage(cloudapi.EventsTopic(s.opts.DockSN), cloudapi.EventHms, cloudapi.HmsEvent{List: items}, 0)
+	s.mu.Unlock()
+	s.flush()
+}
+
+// SetBattery 设置飞行器电量，用于构造低电量场景
+func (s *Simulator) SetBattery(percent float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.air.battery = math.Max(0, math.Min(100, percent))
+}
+
+// stepLocked 物理仿真一步，调用方需持有锁
+func (s *Simulator) stepLocked(dt float64) {
+	a := &s.air
+	a.phaseElapsed += dt
+	if a.inAir() {
+		a.flightTime += dt
+		// 速度越快、风越大耗电越快
+		drain := s.opts.DrainPerSecond * (1 + a.hSpeed/s.opts.MaxSpeed*0.5 + s.opts.WindSpeed/20)
+		a.battery = math.Max(0, a.battery-drain*dt)
+		s.checkBattery()
+	}
+
+	switch a.phase {
+	case phaseDocked:
+		a.hSpeed, a.vSpeed = 0, 0
+		if a.battery < 100 {
+			a.battery = math.Min(100, a.battery+s.opts.ChargePerSec*dt)
+		}
+	case phaseCoverOpen:
+		if a.phaseElapsed >= s.opts.CoverOpenTime.Seconds() {
+			s.setCover(cloudapi.CoverOpen)
+			s.setPhase(phaseTakeoff)
+		} else if s.coverState != cloudapi.CoverHalf {
+			s.setCover(cloudapi.CoverHalf)
+		}
+	case phaseTakeoff:
+		if a.climbTowards(a.takeoffAlt, s.opts.ClimbRate, dt) {
+			s.setPhase(phaseCruise)
+			s.setCover(cloudapi.CoverClosed)
+		}
+	case phaseCruise:
+		s.stepCruise(dt)
+	case phaseHover:
+		if s.drcState == cloudapi.DrcStateConnected && a.stickAge < 1 {
+			a.applyStick(a.maxSpeed, s.opts.ClimbRate, dt)
+			a.stickAge += dt
+		} else {
+			a.hSpeed, a.vSpeed = 0, 0
+		}
+	case phaseReturn:
+		if a.height < a.rthAltitude-0.1 && a.distanceTo(s.opts.Latitude, s.opts.Longitude) > 1 {
+			a.climbTowards(a.rthAltitude, s.opts.ClimbRate, dt)
+			a.hSpeed = 0
+		} else if a.moveTowards(s.opts.Latitude, s.opts.Longitude, a.maxSpeed, dt) {
+			s.setCover(cloudapi.CoverOpen)
+			s.setPhase(phaseLanding)
+		}
+	case phaseLanding:
+		if a.climbTowards(0, s.opts.DescendRate, dt) {
+			a.flightTime = 0
+			s.setCover(cloudapi.CoverClosed)
+			s.setPhase(phaseDocked)
+			s.flightAuthority, s.drcState = false, cloudapi.DrcStateDisconnected
+			s.setDockMode(cloudapi.DockModeIdle)
+		}
+	case phaseForced:
+		if a.climbTowards(0, s.opts.DescendRate, dt) {
+			s.setPhase(phaseLanded)
+		}
+	}
+}
+
+// stepCruise 飞向航点，到达后上报完成事件并悬停
+func (s *Simulator) stepCruise(dt float64) {
+	a := &s.air
+	if len(a.targets) == 0 {
+		s.setPhase(phaseHover)
+		return
+	}
+	target := a.targets[0]
+	a.climbTowards(target.Height, s.opts.ClimbRate, dt)
+	reached := a.moveTowards(target.Latitude, target.Longitude, a.maxSpeed, dt)
+	if reached && math.Abs(a.height-target.Height) < 0.1 {
+		a.targets = a.targets[1:]
+	}
+	if len(a.targets) > 0 {
+		return
+	}
+	s.queueProgress(cloudapi.ProgressTaskFinish, cloudapi.ResultSuccess)
+	a.flightID, a.flyToID = "", ""
+	s.setPhase(phaseHover)
+}
+
+// checkBattery 低电量自动返航，严重低电量强制降落
+func (s *Simulator) checkBattery() {
+	a := &s.air
+	switch {
+	case a.battery <= float64(s.opts.LandingPct) && a.phase != phaseForced && a.phase != phaseLanding:
+		s.logf("电量 %.1f%% 低于强制降落阈值，原地降落", a.battery)
+		s.setPhase(phaseForced)
+	case a.battery <= float64(s.opts.ReturnHomePct) && (a.phase == phaseCruise || a.phase == phaseHover):
+		s.logf("电量 %.1f%% 低于返航阈值，自动返航", a.battery)
+		if a.phase == phaseCruise {
+			s.queueProgress(cloudapi.ProgressTaskFailed, cloudapi.ResultInvalidState)
+		}
+		s.startReturnHome()
+	}
+}
+
+// startReturnHome 进入返航阶段并上报返航路径
+func (s *Simulator) startReturnHome() {
+	a := &s.air
+	flightID := a.flightID
+	a.targets = nil
+	a.flightID, a.flyToID = "", ""
+	s.setPhase(phaseReturn)
+	path := []cloudapi.Point{
+		{Latitude: a.lat, Longitude: a.lng, Height: math.Max(a.height, a.rthAltitude)},
+		{Latitude: s.opts.Latitude, Longitude: s.opts.Longitude, Height: math.Max(a.height, a.rthAltitude)},
+		{Latitude: s.opts.Latitude, Longitude: s.opts.Longitude, Height: 0},
+	}
+	s.queueEvent(cloudapi.EventReturnHomeInfo, map[string]interface{}{
+		"planned_path_points": path,
+		"last_point_type":     0,
+		"flight_id":           flightID,
+	})
+}
+
+// setPhase 切换飞行阶段
+func (s *Simulator) setPhase(phase flightPhase) {
+	if s.air.phase == phase {
+		return
+	}
+	s.air.phase = phase
+	s.air.phaseElapsed = 0
+	s.queueState()
+}
+
+// setCover 设置舱盖状态
+func (s *Simulator) setCover(state int) {
+	if s.coverState == state {
+		return
+	}
+	s.coverState = state
+	s.queueState()
+}
+
+// setDockMode 设置机场模式
+func (s *Simulator) setDockMode(mode int) {
+	if s.dockMode == mode {
+		return
+	}
+	s.dockMode = mode
+	s.queueState()
+}
+
+// dockOsd 构造机场OSD
+func (s *Simulator) dockOsd() cloudapi.DockOsd {
+	inDock := 0
+	chargeState := 0
+	if s.air.phase == phaseDocked || s.air.phase == phaseCoverOpen {
+		inDock = 1
+		if s.air.battery < 100 {
+			chargeState = 1
+		}
+	}
+	return cloudapi.DockOsd{
+		ModeCode:               s.dockMode,
+		CoverState:             s.coverState,
+		DroneInDock:            inDock,
+		Latitude:               s.opts.Latitude,
+		Longitude:              s.opts.Longitude,
+		EnvironmentTemperature: s.opts.Temperature,
+		Temperature:            s.opts.Temperature + 3,
+		Humidity:               60,
+		WindSpeed:              s.opts.WindSpeed,
+		Rainfall:               s.opts.Rainfall,
+		DroneChargeState: cloudapi.DroneChargeState{
+			State:           chargeState,
+			CapacityPercent: int(s.air.battery),
+		},
+	}
+}
+
+// aircraftOsd 构造飞行器OSD
+func (s *Simulator) aircraftOsd() cloudapi.AircraftOsd {
+	a := &s.air
+	remain := 0
+	if drain := s.opts.DrainPerSecond; drain > 0 {
+		remain = int((a.battery - float64(s.opts.LandingPct)) / drain)
+	}
+	return cloudapi.AircraftOsd{
+		ModeCode:        a.modeCode(s.drcState == cloudapi.DrcStateConnected),
+		Latitude:        a.lat,
+		Longitude:       a.lng,
+		Height:          a.height,
+		Elevation:       a.height,
+		HorizontalSpeed: a.hSpeed,
+		VerticalSpeed:   a.vSpeed,
+		AttitudeHead:    a.heading,
+		HomeDistance:    a.distanceTo(s.opts.Latitude, s.opts.Longitude),
+		TotalFlightTime: a.flightTime,
+		Battery: cloudapi.BatteryInfo{
+			CapacityPercent:  int(a.battery),
+			RemainFlightTime: remain,
+			ReturnHomePower:  s.opts.ReturnHomePct,
+			LandingPower:     s.opts.LandingPct,
+		},
+		PositionState:    cloudapi.PositionInfo{IsFixed: 2, Quality: 5, GpsNumber: 20, RtkNumber: 30},
+		WindSpeed:        s.opts.WindSpeed,
+		GimbalPitch:      s.gimbalPitch,
+		CameraMode:       s.cameraMode,
+		Recording:        s.recording,
+		ZoomFactor:       s.zoomFactor,
+		StorageRemaining: 64*1024 - s.photoCount*8,
+	}
+}
+
+// queueOsd 排队发布机场与飞行器OSD，DRC模式下同时推送 osd_info_push
+func (s *Simulator) queueOsd() {
+	s.queueMessage(cloudapi.OsdTopic(s.opts.DockSN), "", s.dockOsd(), 0)
+	aircraftOsd := s.aircraftOsd()
+	s.queueMessage(cloudapi.OsdTopic(s.opts.DroneSN), "", aircraftOsd, 0)
+	if s.drcState == cloudapi.DrcStateConnected {
+		s.queueMessage(cloudapi.DrcUpTopic(s.opts.DockSN), cloudapi.DrcMethodOsdInfoPush, aircraftOsd, 0)
+	}
+}
+
+// queueState 排队发布状态变化
+func (s *Simulator) queueState() {
+	osd := s.dockOsd()
+	s.queueMessage(cloudapi.StateTopic(s.opts.DockSN), "", map[string]interface{}{
+		"mode_code":     osd.ModeCode,
+		"cover_state":   osd.CoverState,
+		"drone_in_dock": osd.DroneInDock,
+	}, 0)
+	s.queueMessage(cloudapi.StateTopic(s.opts.DroneSN), "", map[string]interface{}{
+		"mode_code": s.air.modeCode(s.drcState == cloudapi.DrcStateConnected),
+	}, 0)
+}
+
+// queueProgress 排队发布起飞/指点飞行进度事件
+func (s *Simulator) queueProgress(status string, result int) {
+	a := &s.air
+	method := cloudapi.EventTakeoffToPointProgress
+	if a.flyToID != "" {
+		method = cloudapi.EventFlyToPointProgress
+	}
+	remaining := 0.0
+	for i, target := range a.targets {
+		if i == 0 {
+			remaining += a.distanceTo(target.Latitude, target.Longitude)
+			continue
+		}
+		north, east := offsetMeters(a.targets[i-1].Latitude, a.targets[i-1].Longitude, target.Latitude, target.Longitude)
+		remaining += math.Hypot(north, east)
+	}
+	event := cloudapi.ProgressEvent{
+		FlightID:          a.flightID,
+		FlyToID:           a.flyToID,
+		Status:            status,
+		Result:            result,
+		RemainingDistance: math.Round(remaining*10) / 10,
+		RemainingTime:     math.Round(remaining / a.maxSpeed),
+	}
+	s.queueEvent(method, event)
+}
+
+// queueEvent 排队发布事件
+func (s *Simulator) queueEvent(method string, data interface{}) {
+	s.queueMessage(cloudapi.EventsTopic(s.opts.DockSN), method, data, 1)
+}
+
+// queueMessage 构造上云API消息并加入待发布队列
+func (s *Simulator) queueMessage(topic, method string, data interface{}, needReply int) {
+	msg, err := cloudapi.NewMessage(s.opts.DockSN, method, "", data)
+	if err != nil {
+		s.logf("构造消息失败: %v", err)
+		return
+	}
+	msg.NeedReply = needReply
+	s.outbox = append(s.outbox, outMessage{topic: topic, payload: msg.Bytes()})
+}
+
+// flush 在锁外发布待发布队列中的消息；未连接时保留队列，连接后再发布
+func (s *Simulator) flush() {
+	s.mu.Lock()
+	client := s.client
+	if client == nil || !client.IsConnected() {
+		// 断线期间只保留最近的消息，避免OSD无限堆积
+		if len(s.outbox) > maxOutbox {
+			s.outbox = append([]outMessage(nil), s.outbox[len(s.outbox)-maxOutbox:]...)
+		}
+		s.mu.Unlock()
+		return
+	}
+	pending := s.outbox
+	s.outbox = nil
+	s.mu.Unlock()
+
+	for _, out := range pending {
+		client.Publish(out.topic, 0, false, out.payload)
+	}
+}
+
+// publishTopo 上报设备拓扑
+func (s *Simulator) publishTopo() {
+	s.mu.Lock()
+	s.queueMessage(cloudapi.StatusTopic(s.opts.DockSN), cloudapi.StatusMethodUpdateTopo, map[string]interface{}{
+		"domain":   "3",
+		"type":     2,
+		"sub_type": 0,
+		"version":  1,
+		"sub_devices": []map[string]interface{}{
+			{"sn": s.opts.DroneSN, "domain": "0", "type": 91, "sub_type": 0, "index": "A", "version": 1},
+		},
+	}, 1)
+	s.queueState()
+	s.mu.Unlock()
+	s.flush()
+}
+
+// logf 输出日志
+func (s *Simulator) logf(format string, args ...interface{}) {
+	if s.opts.Logger != nil {
+		s.opts.Logger.Printf("[dock2sim %s] "+format, append([]interface{}{s.opts.DockSN}, args...)...)
+	}
+}
+
+// decodeData 解析消息数据体
+func decodeData(msg *cloudapi.Message, target interface{}) error {
+	if len(msg.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(msg.Data, target); err != nil {
+		return fmt.Errorf("解析 %s 参数失败: %w", msg.Method, err)
+	}
+	return nil
+}
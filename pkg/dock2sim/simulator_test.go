@@ -0,0 +1,248 @@
+package dock2sim
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/cloudapi"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// serviceResult 调用服务并解析 services_reply 的 result
+func serviceResult(t *testing.T, sim *Simulator, method string, data interface{}) int {
+	t.Helper()
+	msg, err := cloudapi.NewMessage(sim.Options().DockSN, method, "", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply := sim.HandleService(msg)
+	var body struct {
+		Result int `json:"result"`
+	}
+	if err := json.Unmarshal(reply.Data, &body); err != nil {
+		t.Fatalf("解析 %s 回复失败: %v", method, err)
+	}
+	if reply.Bid != msg.Bid || reply.Tid != msg.Tid {
+		t.Errorf("%s 回复的 tid/bid 应与请求一致", method)
+	}
+	return body.Result
+}
+
+// stepUntil 以 100ms 步长推进仿真，直到 done 返回 true 或超过 limit
+func stepUntil(t *testing.T, sim *Simulator, limit time.Duration, done func() bool) {
+	t.Helper()
+	for elapsed := time.Duration(0); elapsed < limit; elapsed += 100 * time.Millisecond {
+		if done() {
+			return
+		}
+		sim.Step(100 * time.Millisecond)
+	}
+	if !done() {
+		t.Fatalf("仿真 %s 内未到达预期状态，飞行器模式 %d", limit, sim.AircraftOsd().ModeCode)
+	}
+}
+
+// takeoff 起飞到机场正北约100米、高60米处
+func takeoff(t *testing.T, sim *Simulator) {
+	t.Helper()
+	opts := sim.Options()
+	result := serviceResult(t, sim, cloudapi.MethodTakeoffToPoint, cloudapi.TakeoffToPointRequest{
+		TargetLatitude:  opts.Latitude + 0.001,
+		TargetLongitude: opts.Longitude,
+		TargetHeight:    60,
+		SecurityTakeoff: 30,
+	})
+	if result != cloudapi.ResultSuccess {
+		t.Fatalf("起飞返回 %d", result)
+	}
+}
+
+// TestOsd 机场内充电，起飞后爬升并飞向目标点，OSD 反映位置、速度与耗电
+func TestOsd(t *testing.T) {
+	sim := New(Options{InitialBattery: 80, CoverOpenTime: time.Second})
+	dock := sim.DockOsd()
+	if dock.DroneInDock != 1 || dock.ModeCode != cloudapi.DockModeIdle || dock.CoverState != cloudapi.CoverClosed {
+		t.Fatalf("初始机场OSD = %+v", dock)
+	}
+	sim.Step(10 * time.Second)
+	if charged := sim.DockOsd().DroneChargeState; charged.CapacityPercent != 82 || charged.State != 1 {
+		t.Errorf("机场内10秒后充电状态 %+v，应为充电中、电量82%%", charged)
+	}
+
+	takeoff(t, sim)
+	if mode := sim.DockOsd().ModeCode; mode != cloudapi.DockModeWorking {
+		t.Errorf("起飞后机场模式 %d，应为作业中", mode)
+	}
+	stepUntil(t, sim, time.Minute, func() bool { return sim.AircraftOsd().Height >= 30 })
+	stepUntil(t, sim, 2*time.Minute, func() bool { return sim.AircraftOsd().ModeCode == cloudapi.DroneModeManual })
+	osd := sim.AircraftOsd()
+	if osd.Height < 59.9 || osd.HomeDistance < 100 || osd.HomeDistance > 125 {
+		t.Errorf("到达目标点后高度 %.1f、离家距离 %.1f", osd.Height, osd.HomeDistance)
+	}
+	if osd.Battery.CapacityPercent >= 82 || osd.TotalFlightTime == 0 {
+		t.Errorf("飞行中应耗电并累计飞行时长: %+v", osd.Battery)
+	}
+	if sim.DockOsd().DroneInDock != 0 {
+		t.Error("飞行器起飞后不应在舱内")
+	}
+}
+
+// TestServices 服务按飞行阶段与控制权校验，返回对应的 result
+func TestServices(t *testing.T) {
+	sim := New(Options{CoverOpenTime: time.Second})
+	point := cloudapi.FlyToPointRequest{Points: []cloudapi.Point{{Latitude: 22.544, Longitude: 113.934, Height: 80}}}
+
+	tests := []struct {
+		name   string
+		method string
+		data   interface{}
+		want   int
+	}{
+		{"未起飞时返航", cloudapi.MethodReturnHome, nil, cloudapi.ResultInvalidState},
+		{"未起飞时拍照", cloudapi.MethodCameraPhotoTake, nil, cloudapi.ResultInvalidState},
+		{"未获取控制权时指点飞行", cloudapi.MethodFlyToPoint, point, cloudapi.ResultAuthorityNotAvailable},
+		{"未获取控制权时进入DRC", cloudapi.MethodDrcModeEnter, nil, cloudapi.ResultAuthorityNotAvailable},
+		{"起飞参数无效", cloudapi.MethodTakeoffToPoint, cloudapi.TakeoffToPointRequest{TargetLatitude: 91, TargetHeight: 60}, cloudapi.ResultInvalidParameter},
+		{"舱内开舱盖", cloudapi.MethodCoverOpen, nil, cloudapi.ResultSuccess},
+		{"舱内关舱盖", cloudapi.MethodCoverClose, nil, cloudapi.ResultSuccess},
+		{"不支持的方法", "unknown_method", nil, cloudapi.ResultUnsupportedMethod},
+	}
+	for _, tt := range tests {
+		if got := serviceResult(t, sim, tt.method, tt.data); got != tt.want {
+			t.Errorf("%s: result %d，应为 %d", tt.name, got, tt.want)
+		}
+	}
+
+	takeoff(t, sim)
+	if got := serviceResult(t, sim, cloudapi.MethodCoverOpen, nil); got != cloudapi.ResultInvalidState {
+		t.Errorf("起飞后开舱盖 result %d，应为 %d", got, cloudapi.ResultInvalidState)
+	}
+	stepUntil(t, sim, 2*time.Minute, func() bool { return sim.AircraftOsd().ModeCode == cloudapi.DroneModeManual })
+
+	if got := serviceResult(t, sim, cloudapi.MethodCameraRecordingStart, nil); got != cloudapi.ResultSuccess || !sim.AircraftOsd().Recording {
+		t.Errorf("开始录像 result %d", got)
+	}
+	if got := serviceResult(t, sim, cloudapi.MethodCameraRecordingStart, nil); got != cloudapi.ResultInvalidState {
+		t.Errorf("重复开始录像 result %d，应为 %d", got, cloudapi.ResultInvalidState)
+	}
+	serviceResult(t, sim, cloudapi.MethodCameraFrameZoom, map[string]float64{"zoom_factor": 100})
+	if zoom := sim.AircraftOsd().ZoomFactor; zoom != 56 {
+		t.Errorf("变焦倍数 %.0f，应限制为 56", zoom)
+	}
+	if got := serviceResult(t, sim, cloudapi.MethodFlyToPoint, point); got != cloudapi.ResultSuccess || sim.AircraftOsd().ModeCode != cloudapi.DroneModeFlyTo {
+		t.Errorf("指点飞行 result %d，模式 %d", got, sim.AircraftOsd().ModeCode)
+	}
+	if got := serviceResult(t, sim, cloudapi.MethodFlyToPointStop, nil); got != cloudapi.ResultSuccess {
+		t.Errorf("停止指点飞行 result %d", got)
+	}
+
+	if got := serviceResult(t, sim, cloudapi.MethodReturnHome, nil); got != cloudapi.ResultSuccess || sim.AircraftOsd().ModeCode != cloudapi.DroneModeReturnHome {
+		t.Fatalf("返航 result %d，模式 %d", got, sim.AircraftOsd().ModeCode)
+	}
+	stepUntil(t, sim, 5*time.Minute, func() bool { return sim.DockOsd().DroneInDock == 1 })
+	if dock := sim.DockOsd(); dock.ModeCode != cloudapi.DockModeIdle || dock.CoverState != cloudapi.CoverClosed {
+		t.Errorf("返航降落后机场OSD = %+v", dock)
+	}
+}
+
+// TestBatteryFaults 注入低电量：低于返航阈值自动返航，低于强制降落阈值原地降落，电量不足时拒绝起飞
+func TestBatteryFaults(t *testing.T) {
+	sim := New(Options{CoverOpenTime: time.Second})
+	sim.SetBattery(20)
+	if got := serviceResult(t, sim, cloudapi.MethodTakeoffToPoint, cloudapi.TakeoffToPointRequest{TargetLatitude: 22.544, TargetLongitude: 113.934, TargetHeight: 60}); got != cloudapi.ResultInvalidState {
+		t.Errorf("低电量起飞 result %d，应为 %d", got, cloudapi.ResultInvalidState)
+	}
+
+	sim.SetBattery(100)
+	takeoff(t, sim)
+	stepUntil(t, sim, 2*time.Minute, func() bool { return sim.AircraftOsd().ModeCode == cloudapi.DroneModeManual })
+	sim.SetBattery(20)
+	sim.Step(100 * time.Millisecond)
+	if mode := sim.AircraftOsd().ModeCode; mode != cloudapi.DroneModeReturnHome {
+		t.Errorf("电量低于返航阈值后模式 %d，应为返航", mode)
+	}
+	sim.SetBattery(5)
+	sim.Step(100 * time.Millisecond)
+	if mode := sim.AircraftOsd().ModeCode; mode != cloudapi.DroneModeForcedLanding {
+		t.Errorf("电量低于强制降落阈值后模式 %d，应为强制降落", mode)
+	}
+	stepUntil(t, sim, 2*time.Minute, func() bool { return sim.AircraftOsd().Height == 0 })
+	if sim.DockOsd().DroneInDock != 0 {
+		t.Error("强制降落应落在野外而不是机场内")
+	}
+}
+
+// TestMqtt 经内嵌代理收发：services 请求得到 services_reply，OSD 与注入的 HMS 告警按主题发布
+func TestMqtt(t *testing.T) {
+	broker, err := StartBroker("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { broker.Close() })
+	sim := New(Options{OsdInterval: 100 * time.Millisecond, TickInterval: 20 * time.Millisecond})
+	if err := sim.Connect(broker.URL(), "", ""); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(sim.Stop)
+	opts := sim.Options()
+
+	client := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(broker.URL()).SetClientID("dock2sim-test"))
+	if token := client.Connect(); !token.WaitTimeout(5*time.Second) || token.Error() != nil {
+		t.Fatalf("测试客户端连接失败: %v", token.Error())
+	}
+	t.Cleanup(func() { client.Disconnect(100) })
+	received := make(chan *cloudapi.Message, 64)
+	topics := map[string]byte{
+		cloudapi.OsdTopic(opts.DroneSN):          0,
+		cloudapi.ServicesReplyTopic(opts.DockSN): 0,
+		cloudapi.EventsTopic(opts.DockSN):        0,
+	}
+	if token := client.SubscribeMultiple(topics, func(_ mqtt.Client, m mqtt.Message) {
+		if msg, err := cloudapi.ParseMessage(m.Payload()); err == nil {
+			select {
+			case received <- msg:
+			default:
+			}
+		}
+	}); !token.WaitTimeout(5*time.Second) || token.Error() != nil {
+		t.Fatalf("订阅失败: %v", token.Error())
+	}
+	wait := func(name string, match func(*cloudapi.Message) bool) *cloudapi.Message {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case msg := <-received:
+				if match(msg) {
+					return msg
+				}
+			case <-timeout:
+				t.Fatalf("未收到%s", name)
+				return nil
+			}
+		}
+	}
+
+	sim.Start()
+	wait("飞行器OSD", func(msg *cloudapi.Message) bool {
+		var osd cloudapi.AircraftOsd
+		return msg.Method == "" && json.Unmarshal(msg.Data, &osd) == nil && osd.Battery.CapacityPercent == 100
+	})
+
+	request, _ := cloudapi.NewMessage(opts.DockSN, cloudapi.MethodFlightAuthorityGrab, "", map[string]interface{}{})
+	if token := client.Publish(cloudapi.ServicesTopic(opts.DockSN), 1, false, request.Bytes()); !token.WaitTimeout(5 * time.Second) {
+		t.Fatal("发布 services 请求超时")
+	}
+	reply := wait("services_reply", func(msg *cloudapi.Message) bool { return msg.Bid == request.Bid })
+	if reply.Method != cloudapi.MethodFlightAuthorityGrab || string(reply.Data) != `{"result":0}` {
+		t.Errorf("services_reply = %s %s", reply.Method, reply.Data)
+	}
+
+	sim.ReportHms([]cloudapi.HmsItem{{Code: "0x16100083", Level: 2}})
+	hms := wait("HMS事件", func(msg *cloudapi.Message) bool { return msg.Method == cloudapi.EventHms })
+	var event cloudapi.HmsEvent
+	if err := json.Unmarshal(hms.Data, &event); err != nil || len(event.List) != 1 || event.List[0].Code != "0x16100083" {
+		t.Errorf("HMS事件 = %s", hms.Data)
+	}
+}
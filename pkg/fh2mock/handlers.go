@@ -0,0 +1,863 @@
+package fh2mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 支持的实时控制指令
+var deviceCommands = map[string]bool{
+	"return_home":          true,
+	"return_specific_home": true,
+	"return_home_cancel":   true,
+	"flighttask_pause":     true,
+	"flighttask_recovery":  true,
+}
+
+// routes 注册 OpenAPI 路由与模拟服务管理路由
+func (s *Server) routes() {
+	const prefix = "/openapi/v0.1"
+	// 项目
+	s.mux.HandleFunc("GET "+prefix+"/project", s.handleProjectList)
+	s.mux.HandleFunc("GET "+prefix+"/project/device", s.handleDeviceList)
+	s.mux.HandleFunc("GET "+prefix+"/project/sts-token", s.handleStsToken)
+	// 设备
+	s.mux.HandleFunc("GET "+prefix+"/device/{sn}/state", s.handleDeviceState)
+	s.mux.HandleFunc("GET "+prefix+"/device/hms", s.handleDeviceHms)
+	s.mux.HandleFunc("POST "+prefix+"/device/{sn}/command", s.handleDeviceCommand)
+	s.mux.HandleFunc("POST "+prefix+"/device/change-camera", s.handleDeviceAck)
+	s.mux.HandleFunc("POST "+prefix+"/device/change-lens", s.handleDeviceAck)
+	s.mux.HandleFunc("POST "+prefix+"/device/control", s.handleDeviceControl)
+	s.mux.HandleFunc("DELETE "+prefix+"/device/control", s.handleDeviceControl)
+	s.mux.HandleFunc("PUT "+prefix+"/device/stream/quality", s.handleDeviceAck)
+	s.mux.HandleFunc("POST "+prefix+"/device/{sn}/rtk", s.handleDeviceRTK)
+	s.mux.HandleFunc("POST "+prefix+"/live-stream/start", s.handleLiveStreamStart)
+	// 飞行任务
+	s.mux.HandleFunc("POST "+prefix+"/flight-task", s.handleCreateFlightTask)
+	s.mux.HandleFunc("GET "+prefix+"/flight-task/list", s.handleFlightTaskList)
+	s.mux.HandleFunc("GET "+prefix+"/flight-task/{uuid}", s.handleFlightTaskInfo)
+	s.mux.HandleFunc("PUT "+prefix+"/flight-task/{uuid}/status", s.handleFlightTaskStatus)
+	s.mux.HandleFunc("GET "+prefix+"/flight-task/{uuid}/media", s.handleFlightTaskMedia)
+	s.mux.HandleFunc("GET "+prefix+"/flight-task/{uuid}/track", s.handleFlightTaskTrack)
+	// 航线
+	s.mux.HandleFunc("POST "+prefix+"/wayline/finish-upload", s.handleFinishUpload)
+	s.mux.HandleFunc("GET "+prefix+"/wayline", s.handleWaylineList)
+	s.mux.HandleFunc("GET "+prefix+"/wayline/{uuid}", s.handleWaylineInfo)
+	// 模型重建
+	s.mux.HandleFunc("POST "+prefix+"/model/create", s.handleCreateModel)
+	s.mux.HandleFunc("GET "+prefix+"/model", s.handleModelList)
+	s.mux.HandleFunc("GET "+prefix+"/model/{id}", s.handleModelInfo)
+	// 对象存储（S3 兼容）
+	s.mux.HandleFunc(s3Prefix+"/{bucket}/{key...}", s.handleS3)
+
+	// 模拟服务管理接口（不校验鉴权）
+	s.mux.HandleFunc("GET /__mock/faults", s.handleMockFaults)
+	s.mux.HandleFunc("POST /__mock/faults", s.handleMockFaults)
+	s.mux.HandleFunc("DELETE /__mock/faults", s.handleMockFaults)
+	s.mux.HandleFunc("POST /__mock/devices/{sn}/online", s.handleMockDeviceOnline)
+	s.mux.HandleFunc("POST /__mock/devices/{sn}/offline", s.handleMockDeviceOnline)
+	s.mux.HandleFunc("POST /__mock/hms", s.handleMockHms)
+	s.mux.HandleFunc("POST /__mock/reset", s.handleMockReset)
+	s.mux.HandleFunc("GET /__mock/media/{task}/{name}", s.handleMockMedia)
+	s.mux.HandleFunc("GET /__mock/objects/{key...}", s.handleMockObject)
+	s.mux.HandleFunc("PUT /__mock/objects/{key...}", s.handleMockObject)
+}
+
+// refresh 根据当前时间推进设备与任务状态，调用方需持有写锁
+func (s *Server) refresh(now time.Time) {
+	for _, pair := range s.store.devices {
+		gateway := pair.Gateway
+		if s.opts.DeviceFlapInterval > 0 && now.Sub(gateway.lastFlipTime) >= s.opts.DeviceFlapInterval {
+			gateway.Online = !gateway.Online
+			gateway.lastFlipTime = now
+		}
+	}
+	for _, task := range s.store.tasks {
+		advanceTask(task, now, s.opts.TaskStartDelay, s.opts.TaskDuration)
+	}
+	for _, pair := range s.store.devices {
+		if pair.Drone == nil {
+			continue
+		}
+		task := s.activeTask(pair.Gateway.SN)
+		pair.Drone.Online = pair.Gateway.Online && task != nil
+		if task != nil {
+			pair.Drone.Battery = 100 - task.Progress*60/100
+		} else if pair.Drone.Battery < 100 {
+			pair.Drone.Battery++
+		}
+	}
+	for _, model := range s.store.models {
+		elapsed := now.Sub(model.createdAt)
+		if elapsed >= s.opts.ModelDuration {
+			model.Status, model.Progress = "success", 100
+		} else {
+			model.Status, model.Progress = "processing", int(float64(elapsed)/float64(s.opts.ModelDuration)*100)
+		}
+	}
+}
+
+// activeTask 返回机场当前执行中的任务
+func (s *Server) activeTask(dockSN string) *FlightTask {
+	for _, task := range s.store.tasks {
+		if task.SN == dockSN && (task.Status == TaskStatusExecuting || task.Status == TaskStatusPaused) {
+			return task
+		}
+	}
+	return nil
+}
+
+// handleProjectList 获取组织下的项目列表
+func (s *Server) handleProjectList(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.authorize(w, r, false); !ok {
+		return
+	}
+	q := r.URL.Query().Get("q")
+	page, pageSize := pagination(r)
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+	var matched []*Project
+	for _, project := range s.store.projects {
+		if q == "" || strings.Contains(project.Name, q) {
+			matched = append(matched, project)
+		}
+	}
+	writeJSON(w, http.StatusOK, 0, "", pageOf(matched, page, pageSize))
+}
+
+// handleDeviceList 获取项目下的设备列表
+func (s *Server) handleDeviceList(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.refresh(s.opts.Clock())
+	list := make([]*DevicePair, 0, len(s.store.devices))
+	for _, pair := range s.store.devices {
+		if pair.Gateway.ProjectUUID == projectUUID {
+			list = append(list, pair)
+		}
+	}
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{"list": list})
+}
+
+// handleStsToken 获取项目的存储上传凭证
+func (s *Server) handleStsToken(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	now := s.opts.Clock()
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{
+		"endpoint":          "http://" + r.Host + s3Prefix,
+		"bucket":            s3Bucket,
+		"provider":          "minio",
+		"region":            s3Region,
+		"object_key_prefix": "wayline/" + projectUUID,
+		"credentials": map[string]interface{}{
+			"access_key_id":     s3Credentials.AccessKeyID,
+			"access_key_secret": s3Credentials.AccessKeySecret,
+			"security_token":    s3Credentials.SecurityToken,
+			"expire":            now.Add(time.Hour).Unix(),
+		},
+	})
+}
+
+// handleDeviceState 获取设备物模型信息
+func (s *Server) handleDeviceState(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.authorize(w, r, true); !ok {
+		return
+	}
+	sn := r.PathValue("sn")
+	now := s.opts.Clock()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.refresh(now)
+	pair, device := s.store.findDevice(sn)
+	if device == nil {
+		writeJSON(w, http.StatusOK, 200404, fmt.Sprintf("设备不存在: %s", sn), nil)
+		return
+	}
+
+	state := map[string]interface{}{
+		"sn":        device.SN,
+		"online":    device.Online,
+		"timestamp": now.UnixMilli(),
+	}
+	if device == pair.Gateway {
+		state["mode_code"] = 0 // 空闲中
+		if s.activeTask(pair.Gateway.SN) != nil {
+			state["mode_code"] = 4 // 作业中
+		}
+		state["cover_state"] = 0
+		state["drone_in_dock"] = s.activeTask(pair.Gateway.SN) == nil
+		state["latitude"] = device.Latitude
+		state["longitude"] = device.Longitude
+		state["environment_temperature"] = 26.5
+		state["wind_speed"] = 3.2
+		state["rainfall"] = 0
+	} else {
+		state["mode_code"] = 0 // 待机
+		state["latitude"], state["longitude"], state["height"] = pair.Gateway.Latitude, pair.Gateway.Longitude, 0.0
+		if task := s.activeTask(pair.Gateway.SN); task != nil {
+			state["mode_code"] = 5 // 航线飞行
+			lat, lng, height := circlePosition(pair.Gateway, float64(task.Progress)/100)
+			state["latitude"], state["longitude"], state["height"] = lat, lng, height
+			state["horizontal_speed"] = 10.0
+		}
+		state["battery"] = map[string]interface{}{"capacity_percent": device.Battery}
+		state["position_state"] = map[string]interface{}{"is_fixed": 2, "rtk_number": 30, "gps_number": 20}
+	}
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{"device_state": state})
+}
+
+// handleDeviceHms 获取设备HMS信息
+func (s *Server) handleDeviceHms(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.authorize(w, r, true); !ok {
+		return
+	}
+	snList := strings.Split(r.URL.Query().Get("device_sn_list"), ",")
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+	list := make([]*HmsAlarm, 0)
+	for _, sn := range snList {
+		list = append(list, s.store.hms[strings.TrimSpace(sn)]...)
+	}
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{"list": list})
+}
+
+// handleDeviceCommand 实时控制指令下发
+func (s *Server) handleDeviceCommand(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.authorize(w, r, true); !ok {
+		return
+	}
+	var body struct {
+		DeviceCommand string `json:"device_command"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, 200400, "请求体格式错误", nil)
+		return
+	}
+	if !deviceCommands[body.DeviceCommand] {
+		writeJSON(w, http.StatusOK, 200400, fmt.Sprintf("不支持的指令: %s", body.DeviceCommand), nil)
+		return
+	}
+
+	now := s.opts.Clock()
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.refresh(now)
+	pair, _ := s.store.findDevice(r.PathValue("sn"))
+	if pair == nil {
+		writeJSON(w, http.StatusOK, 200404, "设备不存在", nil)
+		return
+	}
+	if !pair.Gateway.Online {
+		writeJSON(w, http.StatusOK, 200409, "设备离线", nil)
+		return
+	}
+	task := s.activeTask(pair.Gateway.SN)
+	if task == nil && body.DeviceCommand != "return_home_cancel" {
+		writeJSON(w, http.StatusOK, 200409, "设备当前没有执行中的任务", nil)
+		return
+	}
+	switch body.DeviceCommand {
+	case "return_home", "return_specific_home":
+		finishTask(task, TaskStatusTerminated, now)
+	case "flighttask_pause":
+		if task.pausedAt.IsZero() {
+			task.pausedAt = now
+		}
+	case "flighttask_recovery":
+		if !task.pausedAt.IsZero() {
+			task.pausedTotal += now.Sub(task.pausedAt)
+			task.pausedAt = time.Time{}
+		}
+	}
+	s.refresh(now)
+	writeJSON(w, http.StatusOK, 0, "", nil)
+}
+
+// handleDeviceAck 相机切换、镜头切换、清晰度设置等只需确认的指令
+func (s *Server) handleDeviceAck(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.authorize(w, r, true); !ok {
+		return
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, 200400, "请求体格式错误", nil)
+		return
+	}
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+	if _, device := s.store.findDevice(stringParam(body, "sn")); device == nil {
+		writeJSON(w, http.StatusOK, 200404, "设备不存在", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, 0, "", nil)
+}
+
+// handleDeviceControl 获取/释放设备负载控制权
+func (s *Server) handleDeviceControl(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.authorize(w, r, true); !ok {
+		return
+	}
+	var body struct {
+		SN           string   `json:"sn"`
+		PayloadIndex []string `json:"payload_index"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, 200400, "请求体格式错误", nil)
+		return
+	}
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	if _, device := s.store.findDevice(body.SN); device == nil {
+		writeJSON(w, http.StatusOK, 200404, "设备不存在", nil)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		delete(s.store.control, body.SN)
+	} else {
+		s.store.control[body.SN] = body.PayloadIndex
+	}
+	writeJSON(w, http.StatusOK, 0, "", nil)
+}
+
+// handleDeviceRTK 自定义网络RTK标定
+func (s *Server) handleDeviceRTK(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.authorize(w, r, true); !ok {
+		return
+	}
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+	if _, device := s.store.findDevice(r.PathValue("sn")); device == nil {
+		writeJSON(w, http.StatusOK, 200404, "设备不存在", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, 0, "", nil)
+}
+
+// handleLiveStreamStart 开启直播
+func (s *Server) handleLiveStreamStart(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.authorize(w, r, true); !ok {
+		return
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, 200400, "请求体格式错误", nil)
+		return
+	}
+	sn := stringParam(body, "sn")
+	s.store.mu.RLock()
+	_, device := s.store.findDevice(sn)
+	s.store.mu.RUnlock()
+	if device == nil {
+		writeJSON(w, http.StatusOK, 200404, "设备不存在", nil)
+		return
+	}
+	expire := int64(7200)
+	if value, ok := body["video_expire"].(float64); ok && value > 0 {
+		expire = int64(value)
+	}
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{
+		"channel":      fmt.Sprintf("%s-%s", sn, stringParam(body, "camera_index")),
+		"sn":           sn,
+		"token":        uuid.New().String(),
+		"uid":          50000,
+		"url":          fmt.Sprintf("rtmp://127.0.0.1:1935/live/%s", sn),
+		"url_type":     "rtmp",
+		"expire_ts":    s.opts.Clock().Add(time.Duration(expire) * time.Second).Unix(),
+		"quality_type": stringParam(body, "quality_type"),
+	})
+}
+
+// handleCreateFlightTask 创建飞行任务
+func (s *Server) handleCreateFlightTask(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	var params map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeJSON(w, http.StatusBadRequest, 200400, "请求体格式错误", nil)
+		return
+	}
+	for _, key := range []string{"name", "wayline_uuid", "sn"} {
+		if stringParam(params, key) == "" {
+			writeJSON(w, http.StatusOK, 200400, fmt.Sprintf("缺少参数: %s", key), nil)
+			return
+		}
+	}
+
+	now := s.opts.Clock()
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.refresh(now)
+	pair, _ := s.store.findDevice(stringParam(params, "sn"))
+	if pair == nil {
+		writeJSON(w, http.StatusOK, 200404, "设备不存在", nil)
+		return
+	}
+	if !pair.Gateway.Online {
+		writeJSON(w, http.StatusOK, 200409, "设备离线", nil)
+		return
+	}
+	if _, exists := s.store.waylines[stringParam(params, "wayline_uuid")]; !exists {
+		writeJSON(w, http.StatusOK, 200404, "航线不存在", nil)
+		return
+	}
+	if s.activeTask(pair.Gateway.SN) != nil {
+		writeJSON(w, http.StatusOK, 200409, "设备正在执行其他任务", nil)
+		return
+	}
+	params["sn"] = pair.Gateway.SN
+	task := s.store.newTask(projectUUID, params, now)
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{"task_uuid": task.UUID})
+}
+
+// handleFlightTaskList 获取飞行任务列表
+func (s *Server) handleFlightTaskList(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.refresh(s.opts.Clock())
+	list := make([]*FlightTask, 0)
+	for _, id := range s.store.taskOrder {
+		task := s.store.tasks[id]
+		if task.ProjectUUID != projectUUID {
+			continue
+		}
+		if sn := query.Get("sn"); sn != "" && task.SN != sn {
+			continue
+		}
+		if name := query.Get("name"); name != "" && !strings.Contains(task.Name, name) {
+			continue
+		}
+		if status := query.Get("status"); status != "" && task.Status != status {
+			continue
+		}
+		if taskType := query.Get("task_type"); taskType != "" && task.TaskType != taskType {
+			continue
+		}
+		list = append(list, task)
+	}
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{"list": list})
+}
+
+// lookupTask 查找任务，不存在时输出错误
+func (s *Server) lookupTask(w http.ResponseWriter, r *http.Request, projectUUID string) *FlightTask {
+	task, exists := s.store.tasks[r.PathValue("uuid")]
+	if !exists || task.ProjectUUID != projectUUID {
+		writeJSON(w, http.StatusOK, 200404, "任务不存在", nil)
+		return nil
+	}
+	return task
+}
+
+// handleFlightTaskInfo 获取飞行任务信息
+func (s *Server) handleFlightTaskInfo(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.refresh(s.opts.Clock())
+	if task := s.lookupTask(w, r, projectUUID); task != nil {
+		writeJSON(w, http.StatusOK, 0, "", task)
+	}
+}
+
+// handleFlightTaskStatus 更新飞行任务状态（挂起/恢复）
+func (s *Server) handleFlightTaskStatus(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, 200400, "请求体格式错误", nil)
+		return
+	}
+	now := s.opts.Clock()
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.refresh(now)
+	task := s.lookupTask(w, r, projectUUID)
+	if task == nil {
+		return
+	}
+	switch body.Status {
+	case TaskStatusSuspended:
+		if task.Status != TaskStatusWaiting {
+			writeJSON(w, http.StatusOK, 200409, "只有待执行的任务可以挂起", nil)
+			return
+		}
+		task.finalStatus = TaskStatusSuspended
+	case "restored":
+		if task.finalStatus != TaskStatusSuspended {
+			writeJSON(w, http.StatusOK, 200409, "任务未挂起", nil)
+			return
+		}
+		task.finalStatus = ""
+		task.createdAt = now
+	default:
+		writeJSON(w, http.StatusOK, 200400, fmt.Sprintf("不支持的状态: %s", body.Status), nil)
+		return
+	}
+	s.refresh(now)
+	writeJSON(w, http.StatusOK, 0, "", nil)
+}
+
+// handleFlightTaskMedia 获取飞行任务产生的媒体资源
+func (s *Server) handleFlightTaskMedia(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.refresh(s.opts.Clock())
+	task := s.lookupTask(w, r, projectUUID)
+	if task == nil {
+		return
+	}
+	media := s.taskMedia(task)
+	list := make([]map[string]interface{}, 0, len(media))
+	for _, m := range media {
+		list = append(list, map[string]interface{}{
+			"uuid":        m.UUID,
+			"name":        m.Name,
+			"file_type":   "image",
+			"size":        len(m.Content),
+			"fingerprint": m.fingerprint(),
+			"url":         fmt.Sprintf("http://%s/__mock/media/%s/%s", r.Host, task.UUID, m.Name),
+			"latitude":    m.Latitude,
+			"longitude":   m.Longitude,
+			"height":      m.Height,
+			"created_at":  m.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{"list": list})
+}
+
+// handleFlightTaskTrack 获取飞行任务轨迹信息
+func (s *Server) handleFlightTaskTrack(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.refresh(s.opts.Clock())
+	task := s.lookupTask(w, r, projectUUID)
+	if task == nil {
+		return
+	}
+	pair, _ := s.store.findDevice(task.SN)
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{
+		"task_uuid": task.UUID,
+		"points":    trackPoints(task, pair.Gateway, s.opts.TaskDuration/60),
+	})
+}
+
+// handleFinishUpload 航线上传完成通知
+func (s *Server) handleFinishUpload(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	var body struct {
+		Name      string `json:"name"`
+		ObjectKey string `json:"object_key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, 200400, "请求体格式错误", nil)
+		return
+	}
+	if body.Name == "" || body.ObjectKey == "" {
+		writeJSON(w, http.StatusOK, 200400, "name 与 object_key 不能为空", nil)
+		return
+	}
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	droneModelKey := "0-91-0"
+	// 航线文件已上传到模拟对象存储时，按文件内容登记机型
+	if data, exists := s.store.objects[body.ObjectKey]; exists {
+		if modelKey, err := parseWaylineModel(data); err == nil {
+			droneModelKey = modelKey
+		}
+	}
+	wayline := &Wayline{
+		UUID:             uuid.New().String(),
+		Name:             body.Name,
+		DroneModelKey:    droneModelKey,
+		PayloadModelKeys: []string{"1-81-0"},
+		TemplateTypes:    []int{0},
+		ObjectKey:        body.ObjectKey,
+		UpdateTime:       s.opts.Clock().UnixMilli(),
+		ProjectUUID:      projectUUID,
+	}
+	s.store.waylines[wayline.UUID] = wayline
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{"id": wayline.UUID})
+}
+
+// handleWaylineList 获取项目下航线列表
+func (s *Server) handleWaylineList(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+	list := make([]*Wayline, 0, len(s.store.waylines))
+	for _, wayline := range s.store.waylines {
+		if wayline.ProjectUUID == projectUUID {
+			list = append(list, wayline)
+		}
+	}
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{"list": list})
+}
+
+// handleWaylineInfo 获取航线详情
+func (s *Server) handleWaylineInfo(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+	wayline, exists := s.store.waylines[r.PathValue("uuid")]
+	if !exists || wayline.ProjectUUID != projectUUID {
+		writeJSON(w, http.StatusOK, 200404, "航线不存在", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{
+		"id":                 wayline.UUID,
+		"name":               wayline.Name,
+		"drone_model_key":    wayline.DroneModelKey,
+		"payload_model_keys": wayline.PayloadModelKeys,
+		"template_types":     wayline.TemplateTypes,
+		"update_time":        wayline.UpdateTime,
+		"object_key":         wayline.ObjectKey,
+		"download_url":       fmt.Sprintf("http://%s/__mock/objects/%s", r.Host, wayline.ObjectKey),
+	})
+}
+
+// handleCreateModel 模型重建
+func (s *Server) handleCreateModel(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	var params map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeJSON(w, http.StatusBadRequest, 200400, "请求体格式错误", nil)
+		return
+	}
+	now := s.opts.Clock()
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	model := &Model{
+		ID:          s.store.nextModel,
+		Name:        stringParam(params, "name"),
+		Status:      "processing",
+		Params:      params,
+		CreatedAt:   now.UnixMilli(),
+		ProjectUUID: projectUUID,
+		createdAt:   now,
+	}
+	s.store.nextModel++
+	s.store.models[model.ID] = model
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{"model_id": model.ID})
+}
+
+// handleModelList 获取项目下模型列表
+func (s *Server) handleModelList(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.refresh(s.opts.Clock())
+	list := make([]*Model, 0, len(s.store.models))
+	for id := int64(10001); id < s.store.nextModel; id++ {
+		if model, exists := s.store.models[id]; exists && model.ProjectUUID == projectUUID {
+			list = append(list, model)
+		}
+	}
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{"list": list})
+}
+
+// handleModelInfo 获取模型详情
+func (s *Server) handleModelInfo(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := s.authorize(w, r, true)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, 200400, "模型ID格式错误", nil)
+		return
+	}
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.refresh(s.opts.Clock())
+	model, exists := s.store.models[id]
+	if !exists || model.ProjectUUID != projectUUID {
+		writeJSON(w, http.StatusOK, 200404, "模型不存在", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, 0, "", model)
+}
+
+// handleMockFaults 查询/添加/清空故障注入规则
+func (s *Server) handleMockFaults(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var faults []Fault
+		if err := json.NewDecoder(r.Body).Decode(&faults); err != nil {
+			writeJSON(w, http.StatusBadRequest, 400, "故障规则格式错误，应为JSON数组", nil)
+			return
+		}
+		for _, fault := range faults {
+			s.AddFault(fault)
+		}
+	case http.MethodDelete:
+		s.ClearFaults()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, 0, "", map[string]interface{}{"list": s.faults})
+}
+
+// handleMockDeviceOnline 设置机场上线/下线
+func (s *Server) handleMockDeviceOnline(w http.ResponseWriter, r *http.Request) {
+	online := strings.HasSuffix(r.URL.Path, "/online")
+	if !s.SetDeviceOnline(r.PathValue("sn"), online) {
+		writeJSON(w, http.StatusNotFound, 404, "设备不存在", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, 0, "", nil)
+}
+
+// handleMockHms 注入HMS告警
+func (s *Server) handleMockHms(w http.ResponseWriter, r *http.Request) {
+	var alarm HmsAlarm
+	if err := json.NewDecoder(r.Body).Decode(&alarm); err != nil || alarm.DeviceSN == "" {
+		writeJSON(w, http.StatusBadRequest, 400, "告警格式错误", nil)
+		return
+	}
+	if alarm.HmsID == "" {
+		alarm.HmsID = uuid.New().String()
+	}
+	s.AddHmsAlarm(alarm)
+	writeJSON(w, http.StatusOK, 0, "", alarm)
+}
+
+// handleMockReset 重置模拟服务状态
+func (s *Server) handleMockReset(w http.ResponseWriter, r *http.Request) {
+	s.Reset()
+	writeJSON(w, http.StatusOK, 0, "", nil)
+}
+
+// handleMockMedia 下载模拟媒体文件，支持 Range 续传
+func (s *Server) handleMockMedia(w http.ResponseWriter, r *http.Request) {
+	s.store.mu.Lock()
+	var content []byte
+	if task, exists := s.store.tasks[r.PathValue("task")]; exists {
+		for _, m := range s.taskMedia(task) {
+			if m.Name == r.PathValue("name") {
+				content = m.Content
+				break
+			}
+		}
+	}
+	s.store.mu.Unlock()
+	if content == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	http.ServeContent(w, r, r.PathValue("name"), time.Time{}, bytes.NewReader(content))
+}
+
+// handleMockObject 读写模拟对象存储（航线文件等）
+func (s *Server) handleMockObject(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	if r.Method == http.MethodPut {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, 400, "读取对象失败", nil)
+			return
+		}
+		s.store.mu.Lock()
+		s.store.objects[key] = data
+		s.store.mu.Unlock()
+		writeJSON(w, http.StatusOK, 0, "", nil)
+		return
+	}
+	s.store.mu.RLock()
+	data, exists := s.store.objects[key]
+	s.store.mu.RUnlock()
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, path.Base(key), time.Time{}, bytes.NewReader(data))
+}
+
+// pagination 解析分页参数
+func pagination(r *http.Request) (int, int) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	return page, pageSize
+}
+
+// pageOf 按分页参数截取列表
+func pageOf[T any](items []T, page, pageSize int) map[string]interface{} {
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if start > len(items) {
+		start = len(items)
+	}
+	if end > len(items) {
+		end = len(items)
+	}
+	return map[string]interface{}{
+		"list": items[start:end],
+		"pagination": map[string]int{
+			"page":      page,
+			"page_size": pageSize,
+			"total":     len(items),
+		},
+	}
+}
@@ -0,0 +1,229 @@
+package fh2mock
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// groundAltitude 模拟机场所在位置的海拔（米）
+const groundAltitude = 50.0
+
+// mockMedia 模拟任务照片
+type mockMedia struct {
+	UUID      string
+	Name      string
+	Latitude  float64
+	Longitude float64
+	Height    float64
+	CreatedAt int64
+	Content   []byte
+}
+
+// taskMedia 任务已产生的照片（每10%进度一张），内容为带 EXIF GPS 与大疆 XMP 云台信息的 JPEG；调用方持有 store 锁
+func (s *Server) taskMedia(task *FlightTask) []mockMedia {
+	pair, _ := s.store.findDevice(task.SN)
+	if pair == nil {
+		return nil
+	}
+	model := "M3TD"
+	if pair.Drone != nil && pair.Drone.DeviceModel.Name != "" {
+		model = pair.Drone.DeviceModel.Name
+	}
+	count := task.Progress / 10
+	list := make([]mockMedia, 0, count)
+	for i := 0; i < count; i++ {
+		ratio := float64(i+1) / 10
+		lat, lng, height := circlePosition(pair.Gateway, ratio)
+		name := fmt.Sprintf("DJI_%s_%04d_V.jpeg", time.UnixMilli(task.RunAt).Format("20060102150405"), i+1)
+		createdAt := task.RunAt + int64(i+1)*s.opts.TaskDuration.Milliseconds()/10
+		list = append(list, mockMedia{
+			UUID:      uuid.NewSHA1(uuid.NameSpaceURL, []byte(task.UUID+name)).String(),
+			Name:      name,
+			Latitude:  lat,
+			Longitude: lng,
+			Height:    height,
+			CreatedAt: createdAt,
+			Content:   mockPhoto(task.UUID+"/"+name, model, lat, lng, height, headingAt(ratio), time.UnixMilli(createdAt)),
+		})
+	}
+	return list
+}
+
+// fingerprint 照片内容的MD5
+func (m mockMedia) fingerprint() string {
+	sum := md5.Sum(m.Content)
+	return hex.EncodeToString(sum[:])
+}
+
+// headingAt 环形航迹上的机头朝向（度，正北为0，顺时针为正，范围 -180~180）
+func headingAt(ratio float64) float64 {
+	heading := math.Mod(ratio*360, 360)
+	if heading > 180 {
+		heading -= 360
+	}
+	return heading
+}
+
+// mockPhoto 生成照片：小尺寸渐变图像，SOI 之后依次插入 EXIF 与 XMP 两个 APP1 段
+func mockPhoto(seed, model string, lat, lng, height, yaw float64, takenAt time.Time) []byte {
+	sum := md5.Sum([]byte(seed))
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.RGBA{R: sum[0] + uint8(x*2), G: sum[1] + uint8(y*3), B: sum[2], A: 0xFF})
+		}
+	}
+	var encoded bytes.Buffer
+	_ = jpeg.Encode(&encoded, img, &jpeg.Options{Quality: 80})
+
+	xmp := fmt.Sprintf(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">`+
+		`<rdf:Description rdf:about="" xmlns:drone-dji="http://www.dji.com/drone-dji/1.0/"`+
+		` drone-dji:GpsLatitude="%.8f" drone-dji:GpsLongitude="%.8f"`+
+		` drone-dji:AbsoluteAltitude="%+.3f" drone-dji:RelativeAltitude="%+.3f"`+
+		` drone-dji:GimbalRollDegree="+0.00" drone-dji:GimbalYawDegree="%+.2f" drone-dji:GimbalPitchDegree="-90.00"`+
+		` drone-dji:FlightRollDegree="+0.00" drone-dji:FlightYawDegree="%+.2f" drone-dji:FlightPitchDegree="+0.00"/>`+
+		`</rdf:RDF></x:xmpmeta>`,
+		lat, lng, groundAltitude+height, height, yaw, yaw)
+
+	var out bytes.Buffer
+	out.Write([]byte{0xFF, 0xD8})
+	writeSegment(&out, 0xE1, append([]byte("Exif\x00\x00"), exifTIFF(model, lat, lng, groundAltitude+height, takenAt)...))
+	writeSegment(&out, 0xE1, append([]byte("http://ns.adobe.com/xap/1.0/\x00"), xmp...))
+	out.Write(encoded.Bytes()[2:])
+	return out.Bytes()
+}
+
+func writeSegment(out *bytes.Buffer, marker byte, payload []byte) {
+	out.Write([]byte{0xFF, marker})
+	_ = binary.Write(out, binary.BigEndian, uint16(len(payload)+2))
+	out.Write(payload)
+}
+
+// TIFF 字段类型
+const (
+	tiffByte     = 1
+	tiffASCII    = 2
+	tiffLong     = 4
+	tiffRational = 5
+)
+
+// tiffField IFD 条目，data 为按小端序编码的值
+type tiffField struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	data  []byte
+}
+
+func asciiField(tag uint16, value string) tiffField {
+	return tiffField{tag: tag, typ: tiffASCII, count: uint32(len(value) + 1), data: append([]byte(value), 0)}
+}
+
+func longField(tag uint16, value uint32) tiffField {
+	return tiffField{tag: tag, typ: tiffLong, count: 1, data: binary.LittleEndian.AppendUint32(nil, value)}
+}
+
+// rationalField 按给定分母编码有理数
+func rationalField(tag uint16, denominator uint32, values ...float64) tiffField {
+	var data []byte
+	for _, v := range values {
+		data = binary.LittleEndian.AppendUint32(data, uint32(math.Round(v*float64(denominator))))
+		data = binary.LittleEndian.AppendUint32(data, denominator)
+	}
+	return tiffField{tag: tag, typ: tiffRational, count: uint32(len(values)), data: data}
+}
+
+// ifdSize IFD 及其外部数据占用的字节数
+func ifdSize(fields []tiffField) uint32 {
+	size := uint32(2 + 12*len(fields) + 4)
+	for _, f := range fields {
+		if len(f.data) > 4 {
+			size += uint32(len(f.data)+1) &^ 1
+		}
+	}
+	return size
+}
+
+// writeIFD 在 offset 处写入 IFD，超过4字节的值紧随其后
+func writeIFD(out *bytes.Buffer, offset uint32, fields []tiffField) {
+	extra := offset + uint32(2+12*len(fields)+4)
+	var data []byte
+	_ = binary.Write(out, binary.LittleEndian, uint16(len(fields)))
+	for _, f := range fields {
+		_ = binary.Write(out, binary.LittleEndian, f.tag)
+		_ = binary.Write(out, binary.LittleEndian, f.typ)
+		_ = binary.Write(out, binary.LittleEndian, f.count)
+		if len(f.data) <= 4 {
+			var value [4]byte
+			copy(value[:], f.data)
+			out.Write(value[:])
+			continue
+		}
+		_ = binary.Write(out, binary.LittleEndian, extra+uint32(len(data)))
+		data = append(data, f.data...)
+		if len(data)%2 == 1 {
+			data = append(data, 0)
+		}
+	}
+	_ = binary.Write(out, binary.LittleEndian, uint32(0))
+	out.Write(data)
+}
+
+// exifTIFF 生成包含厂商、型号、拍摄时间与GPS位置的 TIFF 结构
+func exifTIFF(model string, lat, lng, altitude float64, takenAt time.Time) []byte {
+	takenAt = takenAt.In(time.FixedZone("", 8*3600))
+	latRef, lngRef := "N", "E"
+	if lat < 0 {
+		latRef, lat = "S", -lat
+	}
+	if lng < 0 {
+		lngRef, lng = "W", -lng
+	}
+	dms := func(v float64) []float64 {
+		deg := math.Floor(v)
+		min := math.Floor((v - deg) * 60)
+		return []float64{deg, min, (v - deg - min/60) * 3600}
+	}
+
+	exif := []tiffField{
+		asciiField(0x9003, takenAt.Format("2006:01:02 15:04:05")), // DateTimeOriginal
+		asciiField(0x9011, takenAt.Format("-07:00")),              // OffsetTimeOriginal
+	}
+	gps := []tiffField{
+		{tag: 0x0000, typ: tiffByte, count: 4, data: []byte{2, 3, 0, 0}}, // GPSVersionID
+		asciiField(0x0001, latRef),
+		rationalField(0x0002, 10000, dms(lat)...),
+		asciiField(0x0003, lngRef),
+		rationalField(0x0004, 10000, dms(lng)...),
+		{tag: 0x0005, typ: tiffByte, count: 1, data: []byte{0}}, // 海平面以上
+		rationalField(0x0006, 1000, altitude),
+	}
+	ifd0 := []tiffField{
+		asciiField(0x010F, "DJI"),
+		asciiField(0x0110, model),
+		longField(0x8769, 0), // ExifIFD，偏移在下方填写
+		longField(0x8825, 0), // GPS IFD
+	}
+	exifOffset := 8 + ifdSize(ifd0)
+	gpsOffset := exifOffset + ifdSize(exif)
+	ifd0[2] = longField(0x8769, exifOffset)
+	ifd0[3] = longField(0x8825, gpsOffset)
+
+	var out bytes.Buffer
+	out.Write([]byte{'I', 'I', 42, 0})
+	_ = binary.Write(&out, binary.LittleEndian, uint32(8))
+	writeIFD(&out, 8, ifd0)
+	writeIFD(&out, exifOffset, exif)
+	writeIFD(&out, gpsOffset, gps)
+	return out.Bytes()
+}
@@ -0,0 +1,303 @@
+package fh2mock
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/objectstore"
+	"github.com/google/uuid"
+)
+
+// 模拟对象存储（S3 兼容，路径风格寻址）：/s3/{bucket}/{key}
+// 不在 /__mock/ 下，故障注入规则同样作用于对象存储请求
+const (
+	s3Prefix      = "/s3"
+	s3Bucket      = "fh2-mock"
+	s3Region      = "cn-shenzhen"
+	s3MaxPartSize = 64 << 20
+)
+
+// s3Credentials 模拟存储凭证，GetProjectStsToken 下发
+var s3Credentials = objectstore.Credentials{
+	AccessKeyID:     "mock-access-key",
+	AccessKeySecret: "mock-secret-key",
+	SecurityToken:   "mock-security-token",
+}
+
+// multipartUpload 进行中的分片上传
+type multipartUpload struct {
+	key       string
+	initiated time.Time
+	parts     map[int][]byte
+}
+
+// s3Part 分片信息（ListParts 响应）
+type s3Part struct {
+	PartNumber   int    `xml:"PartNumber"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+	LastModified string `xml:"LastModified,omitempty"`
+}
+
+// s3Upload 分片上传信息（ListMultipartUploads 响应）
+type s3Upload struct {
+	Key       string `xml:"Key"`
+	UploadID  string `xml:"UploadId"`
+	Initiated string `xml:"Initiated"`
+}
+
+// handleS3 对象存储请求分发：按方法与 uploads、uploadId、partNumber 查询参数区分 S3 操作
+func (s *Server) handleS3(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("bucket") != s3Bucket {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket", "存储桶不存在")
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, s3MaxPartSize+1))
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "IncompleteBody", "读取请求体失败")
+		return
+	}
+	if len(body) > s3MaxPartSize {
+		writeS3Error(w, http.StatusBadRequest, "EntityTooLarge", "请求体超过大小上限")
+		return
+	}
+	if code, message := s.verifyS3Request(r, body); code != "" {
+		writeS3Error(w, http.StatusForbidden, code, message)
+		return
+	}
+
+	key := r.PathValue("key")
+	query := r.URL.Query()
+	_, uploads := query["uploads"]
+	uploadID := query.Get("uploadId")
+	switch {
+	case key == "" && r.Method == http.MethodGet && uploads:
+		s.s3ListUploads(w, query.Get("prefix"))
+	case key == "":
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented", "不支持的存储桶操作")
+	case r.Method == http.MethodPost && uploads:
+		s.s3CreateUpload(w, key)
+	case r.Method == http.MethodPut && uploadID != "":
+		s.s3UploadPart(w, key, uploadID, query.Get("partNumber"), body)
+	case r.Method == http.MethodPost && uploadID != "":
+		s.s3CompleteUpload(w, key, uploadID, body)
+	case r.Method == http.MethodDelete && uploadID != "":
+		s.s3AbortUpload(w, key, uploadID)
+	case r.Method == http.MethodGet && uploadID != "":
+		s.s3ListParts(w, key, uploadID)
+	case r.Method == http.MethodPut:
+		s.store.mu.Lock()
+		s.store.objects[key] = body
+		s.store.mu.Unlock()
+		w.Header().Set("ETag", etagOf(body))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		s.store.mu.RLock()
+		data, exists := s.store.objects[key]
+		s.store.mu.RUnlock()
+		if !exists {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey", "对象不存在")
+			return
+		}
+		w.Header().Set("ETag", etagOf(data))
+		http.ServeContent(w, r, path.Base(key), time.Time{}, bytes.NewReader(data))
+	case r.Method == http.MethodDelete:
+		s.store.mu.Lock()
+		delete(s.store.objects, key)
+		s.store.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "不支持的请求方法")
+	}
+}
+
+// verifyS3Request 校验 V4 签名、安全令牌、请求体哈希与 Content-MD5，返回 S3 错误码
+func (s *Server) verifyS3Request(r *http.Request, body []byte) (string, string) {
+	if err := objectstore.VerifySignature(r, s3Credentials, s3Region); err != nil {
+		return "SignatureDoesNotMatch", err.Error()
+	}
+	if hash := r.Header.Get("X-Amz-Content-Sha256"); hash != "UNSIGNED-PAYLOAD" {
+		sum := sha256.Sum256(body)
+		if hash != hex.EncodeToString(sum[:]) {
+			return "XAmzContentSHA256Mismatch", "请求体哈希不匹配"
+		}
+	}
+	if expected := r.Header.Get("Content-MD5"); expected != "" {
+		sum := md5.Sum(body)
+		if expected != base64.StdEncoding.EncodeToString(sum[:]) {
+			return "BadDigest", "Content-MD5 不匹配"
+		}
+	}
+	return "", ""
+}
+
+func (s *Server) s3CreateUpload(w http.ResponseWriter, key string) {
+	upload := &multipartUpload{key: key, initiated: s.opts.Clock(), parts: make(map[int][]byte)}
+	uploadID := strings.ReplaceAll(uuid.New().String(), "-", "")
+	s.store.mu.Lock()
+	s.store.uploads[uploadID] = upload
+	s.store.mu.Unlock()
+	writeXML(w, struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Bucket   string   `xml:"Bucket"`
+		Key      string   `xml:"Key"`
+		UploadID string   `xml:"UploadId"`
+	}{Bucket: s3Bucket, Key: key, UploadID: uploadID})
+}
+
+func (s *Server) s3UploadPart(w http.ResponseWriter, key, uploadID, partNumber string, body []byte) {
+	number, err := strconv.Atoi(partNumber)
+	if err != nil || number < 1 || number > 10000 {
+		writeS3Error(w, http.StatusBadRequest, "InvalidArgument", "partNumber 应为 1-10000")
+		return
+	}
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	upload := s.store.uploads[uploadID]
+	if upload == nil || upload.key != key {
+		writeS3Error(w, http.StatusNotFound, "NoSuchUpload", "分片上传不存在")
+		return
+	}
+	upload.parts[number] = body
+	w.Header().Set("ETag", etagOf(body))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) s3CompleteUpload(w http.ResponseWriter, key, uploadID string, body []byte) {
+	var request struct {
+		Parts []s3Part `xml:"Part"`
+	}
+	if err := xml.Unmarshal(body, &request); err != nil || len(request.Parts) == 0 {
+		writeS3Error(w, http.StatusBadRequest, "MalformedXML", "合并请求格式错误")
+		return
+	}
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	upload := s.store.uploads[uploadID]
+	if upload == nil || upload.key != key {
+		writeS3Error(w, http.StatusNotFound, "NoSuchUpload", "分片上传不存在")
+		return
+	}
+	var object []byte
+	digests := md5.New()
+	for i, p := range request.Parts {
+		data, exists := upload.parts[p.PartNumber]
+		if !exists || strings.Trim(p.ETag, `"`) != strings.Trim(etagOf(data), `"`) {
+			writeS3Error(w, http.StatusBadRequest, "InvalidPart", fmt.Sprintf("分片 %d 不存在或 ETag 不匹配", p.PartNumber))
+			return
+		}
+		if i > 0 && p.PartNumber <= request.Parts[i-1].PartNumber {
+			writeS3Error(w, http.StatusBadRequest, "InvalidPartOrder", "分片需按分片号升序")
+			return
+		}
+		if i < len(request.Parts)-1 && len(data) < objectstore.MinPartSize {
+			writeS3Error(w, http.StatusBadRequest, "EntityTooSmall", fmt.Sprintf("分片 %d 小于 5MB", p.PartNumber))
+			return
+		}
+		sum := md5.Sum(data)
+		digests.Write(sum[:])
+		object = append(object, data...)
+	}
+	s.store.objects[key] = object
+	delete(s.store.uploads, uploadID)
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Bucket  string   `xml:"Bucket"`
+		Key     string   `xml:"Key"`
+		ETag    string   `xml:"ETag"`
+	}{Bucket: s3Bucket, Key: key, ETag: fmt.Sprintf(`"%x-%d"`, digests.Sum(nil), len(request.Parts))})
+}
+
+func (s *Server) s3AbortUpload(w http.ResponseWriter, key, uploadID string) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	if upload := s.store.uploads[uploadID]; upload == nil || upload.key != key {
+		writeS3Error(w, http.StatusNotFound, "NoSuchUpload", "分片上传不存在")
+		return
+	}
+	delete(s.store.uploads, uploadID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) s3ListParts(w http.ResponseWriter, key, uploadID string) {
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+	upload := s.store.uploads[uploadID]
+	if upload == nil || upload.key != key {
+		writeS3Error(w, http.StatusNotFound, "NoSuchUpload", "分片上传不存在")
+		return
+	}
+	parts := make([]s3Part, 0, len(upload.parts))
+	for number, data := range upload.parts {
+		parts = append(parts, s3Part{PartNumber: number, ETag: etagOf(data), Size: len(data)})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	writeXML(w, struct {
+		XMLName     xml.Name `xml:"ListPartsResult"`
+		Bucket      string   `xml:"Bucket"`
+		Key         string   `xml:"Key"`
+		UploadID    string   `xml:"UploadId"`
+		IsTruncated bool     `xml:"IsTruncated"`
+		Parts       []s3Part `xml:"Part"`
+	}{Bucket: s3Bucket, Key: key, UploadID: uploadID, Parts: parts})
+}
+
+func (s *Server) s3ListUploads(w http.ResponseWriter, prefix string) {
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+	uploads := make([]s3Upload, 0, len(s.store.uploads))
+	for uploadID, upload := range s.store.uploads {
+		if strings.HasPrefix(upload.key, prefix) {
+			uploads = append(uploads, s3Upload{Key: upload.key, UploadID: uploadID, Initiated: upload.initiated.UTC().Format(time.RFC3339Nano)})
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		if uploads[i].Key != uploads[j].Key {
+			return uploads[i].Key < uploads[j].Key
+		}
+		return uploads[i].Initiated < uploads[j].Initiated
+	})
+	writeXML(w, struct {
+		XMLName     xml.Name   `xml:"ListMultipartUploadsResult"`
+		Bucket      string     `xml:"Bucket"`
+		Prefix      string     `xml:"Prefix"`
+		IsTruncated bool       `xml:"IsTruncated"`
+		Uploads     []s3Upload `xml:"Upload"`
+	}{Bucket: s3Bucket, Prefix: prefix, Uploads: uploads})
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(v)
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(struct {
+		XMLName   xml.Name `xml:"Error"`
+		Code      string   `xml:"Code"`
+		Message   string   `xml:"Message"`
+		RequestID string   `xml:"RequestId"`
+	}{Code: code, Message: message, RequestID: uuid.New().String()})
+}
+
+func etagOf(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
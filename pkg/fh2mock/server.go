@@ -0,0 +1,289 @@
+// Package fh2mock 司空2 OpenAPI 模拟服务
+// 提供有状态的内存实现，覆盖 plugin/plugins/fh2.go 使用的全部接口，既可在进程内配合 httptest 使用，
+// 也可通过 cmd/fh2mock 独立运行，供前端与调度团队在不接触真实飞行器的情况下开发联调。
+package fh2mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options 模拟服务配置
+type Options struct {
+	UserToken          string        // 期望的 X-User-Token，为空时只校验非空
+	OrgUUID            string        // 组织UUID
+	ProjectUUID        string        // 预置项目UUID
+	ProjectName        string        // 预置项目名称
+	DeviceCount        int           // 预置机场数量（每个机场带一架飞行器）
+	BaseLatitude       float64       // 第一个机场纬度
+	BaseLongitude      float64       // 第一个机场经度
+	TaskStartDelay     time.Duration // 任务创建到开始执行的时长
+	TaskDuration       time.Duration // 任务执行时长
+	ModelDuration      time.Duration // 模型重建时长
+	DeviceFlapInterval time.Duration // 机场上下线切换间隔，0表示保持在线
+	Clock              func() time.Time
+}
+
+// DefaultOptions 默认配置
+func DefaultOptions() Options {
+	return Options{
+		OrgUUID:        "a887f4d3-0186-458c-90a0-c1d50e8f38f7",
+		ProjectUUID:    "c33595a4-3996-481d-9d81-459d435ade84",
+		ProjectName:    "模拟项目",
+		DeviceCount:    2,
+		BaseLatitude:   22.5431,
+		BaseLongitude:  113.9344,
+		TaskStartDelay: 5 * time.Second,
+		TaskDuration:   2 * time.Minute,
+		ModelDuration:  3 * time.Minute,
+	}
+}
+
+// Fault 可编排的故障注入规则
+type Fault struct {
+	Method     string `json:"method"`      // 请求方法，为空匹配全部
+	Path       string `json:"path"`        // 路径，支持 path.Match 通配符，例如 /openapi/v0.1/device/*/state
+	StatusCode int    `json:"status_code"` // HTTP状态码，为0时返回200
+	Code       int    `json:"code"`        // 业务错误码
+	Message    string `json:"message"`     // 业务错误信息
+	DelayMs    int    `json:"delay_ms"`    // 响应延迟（毫秒）
+	Times      int    `json:"times"`       // 生效次数，0表示一直生效
+	Drop       bool   `json:"drop"`        // 直接断开连接
+}
+
+// Server 司空2 OpenAPI 模拟服务
+type Server struct {
+	opts   Options
+	store  *store
+	mux    *http.ServeMux
+	faults []*Fault
+	mu     sync.Mutex
+}
+
+// NewServer 创建模拟服务
+func NewServer(opts Options) *Server {
+	defaults := DefaultOptions()
+	if opts.OrgUUID == "" {
+		opts.OrgUUID = defaults.OrgUUID
+	}
+	if opts.ProjectUUID == "" {
+		opts.ProjectUUID = defaults.ProjectUUID
+	}
+	if opts.ProjectName == "" {
+		opts.ProjectName = defaults.ProjectName
+	}
+	if opts.DeviceCount <= 0 {
+		opts.DeviceCount = defaults.DeviceCount
+	}
+	if opts.BaseLatitude == 0 && opts.BaseLongitude == 0 {
+		opts.BaseLatitude, opts.BaseLongitude = defaults.BaseLatitude, defaults.BaseLongitude
+	}
+	if opts.TaskStartDelay <= 0 {
+		opts.TaskStartDelay = defaults.TaskStartDelay
+	}
+	if opts.TaskDuration <= 0 {
+		opts.TaskDuration = defaults.TaskDuration
+	}
+	if opts.ModelDuration <= 0 {
+		opts.ModelDuration = defaults.ModelDuration
+	}
+	if opts.Clock == nil {
+		opts.Clock = time.Now
+	}
+
+	s := &Server{
+		opts:  opts,
+		store: newStore(opts, opts.Clock()),
+		mux:   http.NewServeMux(),
+	}
+	s.routes()
+	return s
+}
+
+// NewTestServer 创建并启动进程内模拟服务，返回的 httptest.Server 需由调用方关闭
+func NewTestServer(opts Options) (*Server, *httptest.Server) {
+	s := NewServer(opts)
+	return s, httptest.NewServer(s)
+}
+
+// Options 返回生效的配置
+func (s *Server) Options() Options {
+	return s.opts
+}
+
+// ServeHTTP 实现 http.Handler：先执行故障注入，再做鉴权与路由
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, "/__mock/") {
+		if handled := s.applyFault(w, r); handled {
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// AddFault 添加故障注入规则
+func (s *Server) AddFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults 清空故障注入规则
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// SetDeviceOnline 设置机场在线状态
+func (s *Server) SetDeviceOnline(sn string, online bool) bool {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	_, device := s.store.findDevice(sn)
+	if device == nil {
+		return false
+	}
+	device.Online = online
+	device.lastFlipTime = s.opts.Clock()
+	return true
+}
+
+// AddHmsAlarm 为设备添加HMS告警
+func (s *Server) AddHmsAlarm(alarm HmsAlarm) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	if alarm.CreateTime == 0 {
+		alarm.CreateTime = s.opts.Clock().UnixMilli()
+	}
+	s.store.hms[alarm.DeviceSN] = append(s.store.hms[alarm.DeviceSN], &alarm)
+}
+
+// ClearHmsAlarms 清除设备的HMS告警
+func (s *Server) ClearHmsAlarms(sn string) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	delete(s.store.hms, sn)
+}
+
+// Reset 重置全部状态与故障规则
+func (s *Server) Reset() {
+	s.ClearFaults()
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	fresh := newStore(s.opts, s.opts.Clock())
+	s.store.projects = fresh.projects
+	s.store.devices = fresh.devices
+	s.store.hms = fresh.hms
+	s.store.waylines = fresh.waylines
+	s.store.tasks = fresh.tasks
+	s.store.taskOrder = fresh.taskOrder
+	s.store.models = fresh.models
+	s.store.nextModel = fresh.nextModel
+	s.store.control = fresh.control
+	s.store.objects = fresh.objects
+	s.store.uploads = fresh.uploads
+}
+
+// applyFault 匹配并执行故障注入规则，返回是否已处理该请求
+func (s *Server) applyFault(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	var matched *Fault
+	for i, fault := range s.faults {
+		if fault.Method != "" && !strings.EqualFold(fault.Method, r.Method) {
+			continue
+		}
+		if fault.Path != "" {
+			if ok, _ := path.Match(fault.Path, r.URL.Path); !ok && fault.Path != r.URL.Path {
+				continue
+			}
+		}
+		copied := *fault
+		matched = &copied
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		break
+	}
+	s.mu.Unlock()
+
+	if matched == nil {
+		return false
+	}
+	if matched.DelayMs > 0 {
+		select {
+		case <-time.After(time.Duration(matched.DelayMs) * time.Millisecond):
+		case <-r.Context().Done():
+			return true
+		}
+	}
+	if matched.Drop {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+				return true
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+	// 只有延迟、没有错误码时继续走正常处理
+	if matched.StatusCode == 0 && matched.Code == 0 {
+		return false
+	}
+	status := matched.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, matched.Code, matched.Message, nil)
+	return true
+}
+
+// authorize 校验 X-User-Token 与 X-Project-Uuid 请求头
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, requireProject bool) (string, bool) {
+	token := r.Header.Get("X-User-Token")
+	if token == "" || (s.opts.UserToken != "" && token != s.opts.UserToken) {
+		writeJSON(w, http.StatusUnauthorized, 200401, "X-User-Token 无效", nil)
+		return "", false
+	}
+	if !requireProject {
+		return "", true
+	}
+	projectUUID := r.Header.Get("X-Project-Uuid")
+	if projectUUID == "" {
+		writeJSON(w, http.StatusBadRequest, 200400, "缺少 X-Project-Uuid 请求头", nil)
+		return "", false
+	}
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+	for _, project := range s.store.projects {
+		if project.UUID == projectUUID {
+			return projectUUID, true
+		}
+	}
+	writeJSON(w, http.StatusForbidden, 200403, "项目不存在或无权限", nil)
+	return "", false
+}
+
+// writeJSON 按司空2统一响应格式输出
+func writeJSON(w http.ResponseWriter, status int, code int, message string, data interface{}) {
+	if message == "" {
+		message = "OK"
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    code,
+		"message": message,
+		"data":    data,
+	})
+}
@@ -0,0 +1,220 @@
+package fh2mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testDock    = "7CTXN4A00B0001H"
+	testWayline = "6d88fbe5-a399-485a-86ba-7bbdbb99edec"
+)
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newClockServer 创建使用手动时钟的模拟服务
+func newClockServer(t *testing.T, opts Options) (*Server, *httptest.Server, *fakeClock) {
+	t.Helper()
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)}
+	opts.Clock = clock.Now
+	s, ts := NewTestServer(opts)
+	t.Cleanup(ts.Close)
+	return s, ts, clock
+}
+
+// response 司空2统一响应
+type response struct {
+	Status  int
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func call(t *testing.T, ts *httptest.Server, method, path string, body interface{}) response {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, ts.URL+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-User-Token", "token")
+	req.Header.Set("X-Project-Uuid", DefaultOptions().ProjectUUID)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s 失败: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	result := response{Status: resp.StatusCode}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("%s %s 响应解析失败: %v", method, path, err)
+	}
+	return result
+}
+
+func taskStatus(t *testing.T, ts *httptest.Server, uuid string) FlightTask {
+	t.Helper()
+	resp := call(t, ts, http.MethodGet, "/openapi/v0.1/flight-task/"+uuid, nil)
+	var task FlightTask
+	if err := json.Unmarshal(resp.Data, &task); err != nil || resp.Code != 0 {
+		t.Fatalf("查询任务失败: %+v, %v", resp, err)
+	}
+	return task
+}
+
+func gatewayOnline(t *testing.T, ts *httptest.Server) bool {
+	t.Helper()
+	resp := call(t, ts, http.MethodGet, "/openapi/v0.1/device/"+testDock+"/state", nil)
+	var data struct {
+		DeviceState struct {
+			Online bool `json:"online"`
+		} `json:"device_state"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	return data.DeviceState.Online
+}
+
+// TestTaskProgression 任务按时钟从待执行推进到执行中、成功，挂起的任务停止推进
+func TestTaskProgression(t *testing.T) {
+	_, ts, clock := newClockServer(t, Options{TaskStartDelay: 10 * time.Second, TaskDuration: time.Minute})
+
+	resp := call(t, ts, http.MethodPost, "/openapi/v0.1/flight-task", map[string]interface{}{"name": "巡检", "wayline_uuid": testWayline, "sn": testDock, "task_type": "immediate"})
+	var created struct {
+		TaskUUID string `json:"task_uuid"`
+	}
+	if err := json.Unmarshal(resp.Data, &created); err != nil || created.TaskUUID == "" {
+		t.Fatalf("创建任务失败: %+v", resp)
+	}
+
+	steps := []struct {
+		advance  time.Duration
+		status   string
+		progress int
+	}{
+		{0, TaskStatusWaiting, 0},
+		{10 * time.Second, TaskStatusExecuting, 0},
+		{30 * time.Second, TaskStatusExecuting, 50},
+		{30 * time.Second, TaskStatusSuccess, 100},
+	}
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		task := taskStatus(t, ts, created.TaskUUID)
+		if task.Status != step.status || task.Progress != step.progress {
+			t.Errorf("推进 %s 后状态 %s/%d，应为 %s/%d", step.advance, task.Status, task.Progress, step.status, step.progress)
+		}
+	}
+	if task := taskStatus(t, ts, created.TaskUUID); task.CompletedAt == 0 {
+		t.Error("成功的任务应记录完成时间")
+	}
+
+	resp = call(t, ts, http.MethodPost, "/openapi/v0.1/flight-task", map[string]interface{}{"name": "挂起", "wayline_uuid": testWayline, "sn": testDock})
+	if err := json.Unmarshal(resp.Data, &created); err != nil {
+		t.Fatal(err)
+	}
+	if resp = call(t, ts, http.MethodPut, "/openapi/v0.1/flight-task/"+created.TaskUUID+"/status", map[string]string{"status": TaskStatusSuspended}); resp.Code != 0 {
+		t.Fatalf("挂起任务失败: %+v", resp)
+	}
+	clock.Advance(time.Hour)
+	if task := taskStatus(t, ts, created.TaskUUID); task.Status != TaskStatusSuspended {
+		t.Errorf("挂起的任务状态 %s，应保持 %s", task.Status, TaskStatusSuspended)
+	}
+}
+
+// TestDeviceFlap 配置切换间隔时机场按时钟上下线，离线机场拒绝创建任务
+func TestDeviceFlap(t *testing.T) {
+	_, ts, clock := newClockServer(t, Options{DeviceFlapInterval: time.Minute})
+
+	if !gatewayOnline(t, ts) {
+		t.Fatal("机场初始应在线")
+	}
+	clock.Advance(time.Minute)
+	if gatewayOnline(t, ts) {
+		t.Fatal("经过切换间隔后机场应离线")
+	}
+	resp := call(t, ts, http.MethodPost, "/openapi/v0.1/flight-task", map[string]interface{}{"name": "离线", "wayline_uuid": testWayline, "sn": testDock})
+	if resp.Code != 200409 {
+		t.Errorf("离线机场创建任务返回 %d，应为 200409", resp.Code)
+	}
+	clock.Advance(30 * time.Second)
+	if gatewayOnline(t, ts) {
+		t.Error("未到切换间隔时机场应保持离线")
+	}
+	clock.Advance(30 * time.Second)
+	if !gatewayOnline(t, ts) {
+		t.Error("再经过切换间隔后机场应重新上线")
+	}
+}
+
+// TestFaultInjection 故障规则按方法与通配路径匹配，Times 用尽后自动移除，管理接口不受影响
+func TestFaultInjection(t *testing.T) {
+	s, ts, _ := newClockServer(t, Options{})
+	statePath := "/openapi/v0.1/device/" + testDock + "/state"
+
+	s.AddFault(Fault{Method: http.MethodGet, Path: "/openapi/v0.1/device/*/state", StatusCode: http.StatusServiceUnavailable, Code: 200503, Message: "服务繁忙", Times: 2})
+	for i := 0; i < 2; i++ {
+		if resp := call(t, ts, http.MethodGet, statePath, nil); resp.Status != http.StatusServiceUnavailable || resp.Code != 200503 {
+			t.Errorf("第 %d 次请求应命中故障: %+v", i+1, resp)
+		}
+	}
+	if resp := call(t, ts, http.MethodGet, statePath, nil); resp.Code != 0 {
+		t.Errorf("故障次数用尽后应恢复正常: %+v", resp)
+	}
+
+	s.AddFault(Fault{Method: http.MethodPost, Code: 200500})
+	if resp := call(t, ts, http.MethodGet, statePath, nil); resp.Code != 0 {
+		t.Errorf("方法不匹配的故障不应生效: %+v", resp)
+	}
+	if resp := call(t, ts, http.MethodPost, "/__mock/reset", nil); resp.Status != http.StatusOK {
+		t.Errorf("管理接口不应受故障注入影响: %+v", resp)
+	}
+	if resp := call(t, ts, http.MethodPost, "/openapi/v0.1/flight-task", map[string]interface{}{"name": "a", "wayline_uuid": testWayline, "sn": testDock}); resp.Code != 0 {
+		t.Errorf("重置后故障规则应被清空: %+v", resp)
+	}
+
+	s.AddFault(Fault{Path: statePath, Drop: true, Times: 1})
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+statePath, nil)
+	req.Header.Set("X-User-Token", "token")
+	// 新连接上的请求不会被自动重试
+	fresh := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	if resp, err := fresh.Do(req); err == nil {
+		resp.Body.Close()
+		t.Error("Drop 故障应直接断开连接")
+	}
+
+	start := time.Now()
+	s.AddFault(Fault{Path: statePath, DelayMs: 50, Times: 1})
+	if resp := call(t, ts, http.MethodGet, statePath, nil); resp.Code != 0 || time.Since(start) < 50*time.Millisecond {
+		t.Errorf("只有延迟的故障应在延迟后正常响应: %+v", resp)
+	}
+}
@@ -0,0 +1,355 @@
+package fh2mock
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+	"gitee.com/jamespi/drone_dispatch/pkg/wayline"
+	"github.com/google/uuid"
+)
+
+// 飞行任务状态（与司空2 OpenAPI 保持一致）
+const (
+	TaskStatusWaiting    = "waiting"    // 待执行
+	TaskStatusExecuting  = "executing"  // 执行中
+	TaskStatusPaused     = "paused"     // 暂停
+	TaskStatusSuspended  = "suspended"  // 挂起
+	TaskStatusTerminated = "terminated" // 终止（返航）
+	TaskStatusSuccess    = "success"    // 执行成功
+	TaskStatusFailed     = "failed"     // 执行失败
+)
+
+// Project 项目
+type Project struct {
+	UUID         string `json:"uuid"`
+	Name         string `json:"name"`
+	Introduction string `json:"introduction"`
+	OrgUUID      string `json:"org_uuid"`
+	CreatedAt    int64  `json:"created_at"`
+	UpdatedAt    int64  `json:"updated_at"`
+}
+
+// Camera 相机/负载
+type Camera struct {
+	CameraIndex    string   `json:"camera_index"`
+	CameraName     string   `json:"camera_name"`
+	CameraPosition string   `json:"camera_position,omitempty"`
+	AvailableLens  []string `json:"available_lens_list,omitempty"`
+}
+
+// DeviceModel 设备型号
+type DeviceModel struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// DeviceInfo 机场或飞行器
+type DeviceInfo struct {
+	SN           string      `json:"sn"`
+	Callsign     string      `json:"callsign"`
+	DeviceModel  DeviceModel `json:"device_model"`
+	Online       bool        `json:"device_online_status"`
+	CameraList   []Camera    `json:"camera_list"`
+	ProjectUUID  string      `json:"-"`
+	Latitude     float64     `json:"-"`
+	Longitude    float64     `json:"-"`
+	Battery      int         `json:"-"`
+	lastFlipTime time.Time
+}
+
+// DevicePair 机场与其停放的飞行器
+type DevicePair struct {
+	Gateway *DeviceInfo `json:"gateway"`
+	Drone   *DeviceInfo `json:"drone"`
+}
+
+// HmsAlarm 设备健康告警
+type HmsAlarm struct {
+	HmsID      string `json:"hms_id"`
+	DeviceSN   string `json:"device_sn"`
+	Level      int    `json:"level"`
+	Module     int    `json:"module"`
+	Code       string `json:"code"`
+	InTheSky   int    `json:"in_the_sky"`
+	Imminent   int    `json:"imminent"`
+	CreateTime int64  `json:"create_time"`
+}
+
+// Wayline 航线
+type Wayline struct {
+	UUID             string   `json:"id"`
+	Name             string   `json:"name"`
+	DroneModelKey    string   `json:"drone_model_key"`
+	PayloadModelKeys []string `json:"payload_model_keys"`
+	TemplateTypes    []int    `json:"template_types"`
+	ObjectKey        string   `json:"object_key"`
+	UpdateTime       int64    `json:"update_time"`
+	ProjectUUID      string   `json:"-"`
+}
+
+// FlightTask 飞行任务
+type FlightTask struct {
+	UUID        string                 `json:"uuid"`
+	Name        string                 `json:"name"`
+	SN          string                 `json:"sn"`
+	WaylineUUID string                 `json:"wayline_uuid"`
+	TaskType    string                 `json:"task_type"`
+	Status      string                 `json:"status"`
+	Progress    int                    `json:"progress"`
+	BeginAt     int64                  `json:"begin_at"`
+	RunAt       int64                  `json:"run_at"`
+	CompletedAt int64                  `json:"completed_at"`
+	Params      map[string]interface{} `json:"params"`
+	ProjectUUID string                 `json:"-"`
+
+	createdAt   time.Time
+	pausedAt    time.Time     // 暂停时刻，零值表示未暂停
+	pausedTotal time.Duration // 累计暂停时长
+	finalStatus string        // 人为终止/挂起后的固定状态
+	finishedAt  time.Time
+}
+
+// Model 三维重建模型
+type Model struct {
+	ID          int64                  `json:"id"`
+	Name        string                 `json:"name"`
+	Status      string                 `json:"status"`
+	Progress    int                    `json:"progress"`
+	Params      map[string]interface{} `json:"params"`
+	CreatedAt   int64                  `json:"created_at"`
+	ProjectUUID string                 `json:"-"`
+	createdAt   time.Time
+}
+
+// store 模拟服务内存状态
+type store struct {
+	mu        sync.RWMutex
+	projects  []*Project
+	devices   map[string]*DevicePair // key: 机场SN
+	hms       map[string][]*HmsAlarm // key: 设备SN
+	waylines  map[string]*Wayline
+	tasks     map[string]*FlightTask
+	taskOrder []string
+	models    map[int64]*Model
+	nextModel int64
+	control   map[string][]string         // 设备控制权 key: SN
+	objects   map[string][]byte           // 模拟对象存储 key: object_key
+	uploads   map[string]*multipartUpload // 进行中的分片上传 key: UploadId
+}
+
+// newStore 创建带有示例数据的内存状态
+func newStore(opts Options, now time.Time) *store {
+	s := &store{
+		devices:   make(map[string]*DevicePair),
+		hms:       make(map[string][]*HmsAlarm),
+		waylines:  make(map[string]*Wayline),
+		tasks:     make(map[string]*FlightTask),
+		models:    make(map[int64]*Model),
+		nextModel: 10001,
+		control:   make(map[string][]string),
+		objects:   make(map[string][]byte),
+		uploads:   make(map[string]*multipartUpload),
+	}
+
+	s.projects = append(s.projects, &Project{
+		UUID:         opts.ProjectUUID,
+		Name:         opts.ProjectName,
+		Introduction: "模拟项目",
+		OrgUUID:      opts.OrgUUID,
+		CreatedAt:    now.Add(-30 * 24 * time.Hour).UnixMilli(),
+		UpdatedAt:    now.UnixMilli(),
+	})
+
+	for i := 0; i < opts.DeviceCount; i++ {
+		dockSN := fmt.Sprintf("7CTXN4A00B0%03dH", i+1)
+		droneSN := fmt.Sprintf("1581F6Q8D2421%03dP", i+1)
+		lat := opts.BaseLatitude + float64(i)*0.01
+		lng := opts.BaseLongitude + float64(i)*0.01
+		s.devices[dockSN] = &DevicePair{
+			Gateway: &DeviceInfo{
+				SN:          dockSN,
+				Callsign:    fmt.Sprintf("机场%d", i+1),
+				DeviceModel: DeviceModel{Key: "3-2-0", Name: "DJI Dock 2"},
+				Online:      true,
+				CameraList: []Camera{
+					{CameraIndex: "165-0-7", CameraName: "舱外相机", CameraPosition: "outdoor"},
+					{CameraIndex: "176-0-0", CameraName: "舱内相机", CameraPosition: "indoor"},
+				},
+				ProjectUUID:  opts.ProjectUUID,
+				Latitude:     lat,
+				Longitude:    lng,
+				lastFlipTime: now,
+			},
+			Drone: &DeviceInfo{
+				SN:          droneSN,
+				Callsign:    fmt.Sprintf("飞行器%d", i+1),
+				DeviceModel: DeviceModel{Key: "0-91-0", Name: "Matrice 3D"},
+				Online:      false,
+				CameraList: []Camera{
+					{CameraIndex: "81-0-0", CameraName: "M3D Camera", AvailableLens: []string{"wide", "zoom", "ir"}},
+				},
+				ProjectUUID:  opts.ProjectUUID,
+				Latitude:     lat,
+				Longitude:    lng,
+				Battery:      100,
+				lastFlipTime: now,
+			},
+		}
+	}
+
+	waylineUUID := "6d88fbe5-a399-485a-86ba-7bbdbb99edec"
+	example := exampleWayline(geo.Point{Lat: opts.BaseLatitude, Lng: opts.BaseLongitude})
+	s.waylines[waylineUUID] = &Wayline{
+		UUID:             waylineUUID,
+		Name:             "示例航线",
+		DroneModelKey:    "0-91-0",
+		PayloadModelKeys: []string{"1-81-0"},
+		TemplateTypes:    []int{0},
+		ObjectKey:        "wayline/" + waylineUUID + ".kmz",
+		UpdateTime:       now.UnixMilli(),
+		ProjectUUID:      opts.ProjectUUID,
+	}
+	if data, err := example.KMZ(); err == nil {
+		s.objects[s.waylines[waylineUUID].ObjectKey] = data
+	}
+	return s
+}
+
+// exampleWayline 示例航线：以第一个机场为中心、边长约100米的正方形，每个航点拍照
+func exampleWayline(center geo.Point) *wayline.Wayline {
+	w := wayline.New(wayline.DroneM3D, wayline.PayloadM3D)
+	w.Author = "fh2mock"
+	for i, bearing := range []float64{315, 45, 135, 225} {
+		corner := geo.Destination(center, bearing, 50*math.Sqrt2)
+		if i == 0 {
+			w.AddWaypoint(corner, wayline.GimbalRotate(0, -90, 0), wayline.TakePhoto(0))
+			continue
+		}
+		w.AddWaypoint(corner, wayline.TakePhoto(0))
+	}
+	return w
+}
+
+// parseWaylineModel 解析航线文件，返回飞行器型号键
+func parseWaylineModel(data []byte) (string, error) {
+	w, err := wayline.Parse(data)
+	if err != nil {
+		return "", err
+	}
+	return w.Mission.Drone.ModelKey(), nil
+}
+
+// findDevice 通过机场或飞行器SN查找设备
+func (s *store) findDevice(sn string) (*DevicePair, *DeviceInfo) {
+	for _, pair := range s.devices {
+		if pair.Gateway.SN == sn {
+			return pair, pair.Gateway
+		}
+		if pair.Drone != nil && pair.Drone.SN == sn {
+			return pair, pair.Drone
+		}
+	}
+	return nil, nil
+}
+
+// newTask 创建飞行任务
+func (s *store) newTask(projectUUID string, params map[string]interface{}, now time.Time) *FlightTask {
+	task := &FlightTask{
+		UUID:        uuid.New().String(),
+		Name:        stringParam(params, "name"),
+		SN:          stringParam(params, "sn"),
+		WaylineUUID: stringParam(params, "wayline_uuid"),
+		TaskType:    stringParam(params, "task_type"),
+		Status:      TaskStatusWaiting,
+		BeginAt:     now.UnixMilli(),
+		Params:      params,
+		ProjectUUID: projectUUID,
+		createdAt:   now,
+	}
+	s.tasks[task.UUID] = task
+	s.taskOrder = append(s.taskOrder, task.UUID)
+	return task
+}
+
+// advanceTask 根据时间推进任务状态
+func advanceTask(task *FlightTask, now time.Time, startDelay, duration time.Duration) {
+	if task.finalStatus != "" {
+		task.Status = task.finalStatus
+		return
+	}
+	reference := now
+	if !task.pausedAt.IsZero() {
+		reference = task.pausedAt
+	}
+	elapsed := reference.Sub(task.createdAt) - task.pausedTotal
+	switch {
+	case elapsed < startDelay:
+		task.Status = TaskStatusWaiting
+		task.Progress = 0
+	case elapsed < startDelay+duration:
+		task.Status = TaskStatusExecuting
+		if !task.pausedAt.IsZero() {
+			task.Status = TaskStatusPaused
+		}
+		task.RunAt = task.createdAt.Add(startDelay).UnixMilli()
+		task.Progress = int(float64(elapsed-startDelay) / float64(duration) * 100)
+	default:
+		task.Status = TaskStatusSuccess
+		task.Progress = 100
+		task.RunAt = task.createdAt.Add(startDelay).UnixMilli()
+		task.CompletedAt = task.createdAt.Add(startDelay + duration + task.pausedTotal).UnixMilli()
+	}
+}
+
+// finishTask 人为结束任务（返航、挂起等）
+func finishTask(task *FlightTask, status string, now time.Time) {
+	if task.finalStatus != "" || task.Status == TaskStatusSuccess {
+		return
+	}
+	task.finalStatus = status
+	task.Status = status
+	task.finishedAt = now
+	task.CompletedAt = now.UnixMilli()
+}
+
+// trackPoints 生成任务轨迹：以机场为圆心的环形航迹，按进度截取
+func trackPoints(task *FlightTask, dock *DeviceInfo, interval time.Duration) []map[string]interface{} {
+	total := 60
+	count := total * task.Progress / 100
+	points := make([]map[string]interface{}, 0, count)
+	start := task.RunAt
+	for i := 0; i < count; i++ {
+		lat, lng, height := circlePosition(dock, float64(i)/float64(total))
+		points = append(points, map[string]interface{}{
+			"timestamp": start + int64(i)*interval.Milliseconds(),
+			"latitude":  lat,
+			"longitude": lng,
+			"height":    height,
+		})
+	}
+	return points
+}
+
+// circlePosition 计算环形航迹上的位置，ratio 为 0~1 的飞行进度
+func circlePosition(dock *DeviceInfo, ratio float64) (float64, float64, float64) {
+	const radius = 0.002 // 约200米
+	angle := ratio * 2 * math.Pi
+	lat := dock.Latitude + radius*math.Sin(angle)
+	lng := dock.Longitude + radius*(1-math.Cos(angle))
+	height := 120.0
+	if ratio < 0.05 {
+		height = 120.0 * ratio / 0.05
+	}
+	return lat, lng, height
+}
+
+// stringParam 从请求参数中读取字符串
+func stringParam(params map[string]interface{}, key string) string {
+	if value, ok := params[key].(string); ok {
+		return value
+	}
+	return ""
+}
@@ -0,0 +1,179 @@
+// Package geo 地理计算工具
+// 包含坐标校验、WGS-84/GCJ-02/BD-09 坐标系转换、距离与方位角计算、多边形包含与缓冲。
+// 飞行器与机场上报 WGS-84 坐标，高德地图使用 GCJ-02，百度地图使用 BD-09。
+package geo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// CoordSystem 坐标系
+type CoordSystem string
+
+const (
+	WGS84 CoordSystem = "wgs84" // GPS原始坐标，设备上报使用
+	GCJ02 CoordSystem = "gcj02" // 国测局坐标（火星坐标），高德、腾讯地图使用
+	BD09  CoordSystem = "bd09"  // 百度坐标
+)
+
+// ParseCoordSystem 解析坐标系名称，不区分大小写，支持 wgs84、gcj02、bd09 及常见写法（如 WGS-84、amap、baidu）
+func ParseCoordSystem(name string) (CoordSystem, error) {
+	switch strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "-", "")) {
+	case "", "wgs84", "gps":
+		return WGS84, nil
+	case "gcj02", "amap", "gaode", "tencent":
+		return GCJ02, nil
+	case "bd09", "bd09ll", "baidu":
+		return BD09, nil
+	}
+	return "", fmt.Errorf("不支持的坐标系: %s", name)
+}
+
+// Point 经纬度点位（度）
+type Point struct {
+	Lat float64 `json:"latitude"`
+	Lng float64 `json:"longitude"`
+}
+
+// Validate 校验经纬度范围
+func (p Point) Validate() error {
+	return ValidateLatLng(p.Lat, p.Lng)
+}
+
+// ValidateLatLng 校验经纬度范围，NaN、无穷与越界均视为无效
+func ValidateLatLng(lat, lng float64) error {
+	if math.IsNaN(lat) || math.IsInf(lat, 0) || lat < -90 || lat > 90 {
+		return fmt.Errorf("纬度无效: %v", lat)
+	}
+	if math.IsNaN(lng) || math.IsInf(lng, 0) || lng < -180 || lng > 180 {
+		return fmt.Errorf("经度无效: %v", lng)
+	}
+	return nil
+}
+
+// OutOfChina 是否在中国范围外，范围外 GCJ-02 与 WGS-84 相同，不做偏移
+func OutOfChina(p Point) bool {
+	return p.Lng < 72.004 || p.Lng > 137.8347 || p.Lat < 0.8293 || p.Lat > 55.8271
+}
+
+// Convert 在坐标系之间转换点位
+func Convert(p Point, from, to CoordSystem) (Point, error) {
+	if err := p.Validate(); err != nil {
+		return Point{}, err
+	}
+	if from == to {
+		return p, nil
+	}
+	// 统一经 GCJ-02 中转
+	var gcj Point
+	switch from {
+	case WGS84:
+		gcj = WGS84ToGCJ02(p)
+	case GCJ02:
+		gcj = p
+	case BD09:
+		gcj = BD09ToGCJ02(p)
+	default:
+		return Point{}, fmt.Errorf("不支持的坐标系: %s", from)
+	}
+	switch to {
+	case WGS84:
+		return GCJ02ToWGS84(gcj), nil
+	case GCJ02:
+		return gcj, nil
+	case BD09:
+		return GCJ02ToBD09(gcj), nil
+	}
+	return Point{}, fmt.Errorf("不支持的坐标系: %s", to)
+}
+
+// 克拉索夫斯基椭球参数
+const (
+	krasovskyA  = 6378245.0
+	krasovskyEE = 0.00669342162296594323
+	bdFactor    = math.Pi * 3000.0 / 180.0
+)
+
+// WGS84ToGCJ02 WGS-84 转 GCJ-02
+func WGS84ToGCJ02(p Point) Point {
+	if OutOfChina(p) {
+		return p
+	}
+	dLat, dLng := gcjOffset(p)
+	return Point{Lat: p.Lat + dLat, Lng: p.Lng + dLng}
+}
+
+// GCJ02ToWGS84 GCJ-02 转 WGS-84，迭代求逆，精度优于 1e-7 度（约1厘米）
+func GCJ02ToWGS84(p Point) Point {
+	if OutOfChina(p) {
+		return p
+	}
+	wgs := p
+	for i := 0; i < 10; i++ {
+		gcj := WGS84ToGCJ02(wgs)
+		dLat, dLng := gcj.Lat-p.Lat, gcj.Lng-p.Lng
+		wgs.Lat -= dLat
+		wgs.Lng -= dLng
+		if math.Abs(dLat) < 1e-9 && math.Abs(dLng) < 1e-9 {
+			break
+		}
+	}
+	return wgs
+}
+
+// GCJ02ToBD09 GCJ-02 转 BD-09
+func GCJ02ToBD09(p Point) Point {
+	z := math.Sqrt(p.Lng*p.Lng+p.Lat*p.Lat) + 0.00002*math.Sin(p.Lat*bdFactor)
+	theta := math.Atan2(p.Lat, p.Lng) + 0.000003*math.Cos(p.Lng*bdFactor)
+	return Point{Lat: z*math.Sin(theta) + 0.006, Lng: z*math.Cos(theta) + 0.0065}
+}
+
+// BD09ToGCJ02 BD-09 转 GCJ-02
+func BD09ToGCJ02(p Point) Point {
+	x, y := p.Lng-0.0065, p.Lat-0.006
+	z := math.Sqrt(x*x+y*y) - 0.00002*math.Sin(y*bdFactor)
+	theta := math.Atan2(y, x) - 0.000003*math.Cos(x*bdFactor)
+	return Point{Lat: z * math.Sin(theta), Lng: z * math.Cos(theta)}
+}
+
+// gcjOffset 计算 GCJ-02 相对 WGS-84 的偏移量（度）
+func gcjOffset(p Point) (float64, float64) {
+	x, y := p.Lng-105.0, p.Lat-35.0
+	dLat := -100.0 + 2.0*x + 3.0*y + 0.2*y*y + 0.1*x*y + 0.2*math.Sqrt(math.Abs(x))
+	dLat += (20.0*math.Sin(6.0*x*math.Pi) + 20.0*math.Sin(2.0*x*math.Pi)) * 2.0 / 3.0
+	dLat += (20.0*math.Sin(y*math.Pi) + 40.0*math.Sin(y/3.0*math.Pi)) * 2.0 / 3.0
+	dLat += (160.0*math.Sin(y/12.0*math.Pi) + 320*math.Sin(y*math.Pi/30.0)) * 2.0 / 3.0
+	dLng := 300.0 + x + 2.0*y + 0.1*x*x + 0.1*x*y + 0.1*math.Sqrt(math.Abs(x))
+	dLng += (20.0*math.Sin(6.0*x*math.Pi) + 20.0*math.Sin(2.0*x*math.Pi)) * 2.0 / 3.0
+	dLng += (20.0*math.Sin(x*math.Pi) + 40.0*math.Sin(x/3.0*math.Pi)) * 2.0 / 3.0
+	dLng += (150.0*math.Sin(x/12.0*math.Pi) + 300.0*math.Sin(x/30.0*math.Pi)) * 2.0 / 3.0
+
+	radLat := p.Lat / 180.0 * math.Pi
+	magic := math.Sin(radLat)
+	magic = 1 - krasovskyEE*magic*magic
+	sqrtMagic := math.Sqrt(magic)
+	dLat = (dLat * 180.0) / ((krasovskyA * (1 - krasovskyEE)) / (magic * sqrtMagic) * math.Pi)
+	dLng = (dLng * 180.0) / (krasovskyA / sqrtMagic * math.Cos(radLat) * math.Pi)
+	return dLat, dLng
+}
+
+type contextKey string
+
+// coordSystemKey 输出坐标系上下文键
+const coordSystemKey contextKey = "coord_system"
+
+// WithCoordSystem 在上下文中指定接口输出的坐标系，轨迹、OSD等带坐标的响应会转换到该坐标系
+func WithCoordSystem(ctx context.Context, cs CoordSystem) context.Context {
+	return context.WithValue(ctx, coordSystemKey, cs)
+}
+
+// CoordSystemFrom 读取上下文中的输出坐标系，未指定时为 WGS-84
+func CoordSystemFrom(ctx context.Context) CoordSystem {
+	if cs, ok := ctx.Value(coordSystemKey).(CoordSystem); ok && cs != "" {
+		return cs
+	}
+	return WGS84
+}
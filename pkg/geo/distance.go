@@ -0,0 +1,47 @@
+package geo
+
+import "math"
+
+// EarthRadius 地球平均半径（米）
+const EarthRadius = 6371008.8
+
+// Haversine 计算两点间大圆距离（米）
+func Haversine(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLng := radians(b.Lng - a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Bearing 计算从 a 到 b 的初始方位角（度），正北为0，顺时针 0~360
+func Bearing(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLng := radians(b.Lng - a.Lng)
+	y := math.Sin(dLng) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLng)
+	return math.Mod(degrees(math.Atan2(y, x))+360, 360)
+}
+
+// Destination 从起点沿方位角（度）前进指定距离（米）后的点位
+func Destination(p Point, bearing, distance float64) Point {
+	lat1, lng1 := radians(p.Lat), radians(p.Lng)
+	brng := radians(bearing)
+	d := distance / EarthRadius
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(brng))
+	lng2 := lng1 + math.Atan2(math.Sin(brng)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+	return Point{Lat: degrees(lat2), Lng: math.Mod(degrees(lng2)+540, 360) - 180}
+}
+
+// PathLength 折线总长度（米）
+func PathLength(path []Point) float64 {
+	total := 0.0
+	for i := 1; i < len(path); i++ {
+		total += Haversine(path[i-1], path[i])
+	}
+	return total
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
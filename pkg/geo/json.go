@@ -0,0 +1,158 @@
+package geo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// coordKeys 识别为经纬度的字段名组合（纬度键, 经度键）
+var coordKeys = [][2]string{
+	{"latitude", "longitude"},
+	{"lat", "lng"},
+	{"lat", "lon"},
+	{"target_latitude", "target_longitude"},
+}
+
+// ConvertJSON 转换 JSON 中全部经纬度字段的坐标系，用于设备上报的轨迹、OSD等响应
+// 任意层级对象中同时包含上述纬度、经度数值字段的都会被转换，其余内容原样保留；超出范围的坐标不转换
+func ConvertJSON(data []byte, from, to CoordSystem) ([]byte, error) {
+	if from == to || len(bytes.TrimSpace(data)) == 0 {
+		return data, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // 避免大整数（如毫秒时间戳）损失精度
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %w", err)
+	}
+	if err := convertValue(doc, from, to); err != nil {
+		return nil, err
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("序列化JSON失败: %w", err)
+	}
+	return out, nil
+}
+
+// convertValue 递归转换对象与数组中的经纬度
+func convertValue(v interface{}, from, to CoordSystem) error {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		// 全部字段组合都按原始值计算后再统一写回；同一字段出现在多个组合中（如同时有 lng 与 lon）时只转换一次
+		converted := make(map[string]float64)
+		for _, keys := range coordKeys {
+			lat, okLat := number(node[keys[0]])
+			lng, okLng := number(node[keys[1]])
+			// 0,0 通常表示设备尚未定位，超出范围的坐标为无效上报，均保持原样
+			if !okLat || !okLng || (lat == 0 && lng == 0) {
+				continue
+			}
+			if (Point{Lat: lat, Lng: lng}).Validate() != nil {
+				continue
+			}
+			p, err := Convert(Point{Lat: lat, Lng: lng}, from, to)
+			if err != nil {
+				return err
+			}
+			for i, value := range []float64{p.Lat, p.Lng} {
+				if _, ok := converted[keys[i]]; !ok {
+					converted[keys[i]] = value
+				}
+			}
+		}
+		for key, value := range converted {
+			node[key] = value
+		}
+		for _, child := range node {
+			if err := convertValue(child, from, to); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, child := range node {
+			if err := convertValue(child, from, to); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// number 读取数值字段
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// ExtractPaths 提取 JSON 中的坐标，用于航线、任务请求等结构未知的响应
+// 元素为经纬度对象的数组视为一条折线（保持数组顺序），其余单独出现的经纬度对象各自作为单点返回
+func ExtractPaths(data []byte) ([][]Point, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %w", err)
+	}
+	var paths [][]Point
+	collectPaths(doc, &paths)
+	return paths, nil
+}
+
+// collectPaths 递归收集折线与单点
+func collectPaths(v interface{}, paths *[][]Point) {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		if p, ok := pointOf(node); ok {
+			*paths = append(*paths, []Point{p})
+		}
+		for _, child := range node {
+			collectPaths(child, paths)
+		}
+	case []interface{}:
+		var path []Point
+		for _, child := range node {
+			if obj, ok := child.(map[string]interface{}); ok {
+				if p, ok := pointOf(obj); ok {
+					path = append(path, p)
+					// 点位对象内可能嵌套其他坐标（如动作目标点），继续查找
+					for _, grandchild := range obj {
+						collectPaths(grandchild, paths)
+					}
+					continue
+				}
+			}
+			collectPaths(child, paths)
+		}
+		if len(path) > 0 {
+			*paths = append(*paths, path)
+		}
+	}
+}
+
+// pointOf 读取对象中的经纬度，未定位（0,0）或超出范围的忽略
+func pointOf(node map[string]interface{}) (Point, bool) {
+	for _, keys := range coordKeys {
+		lat, okLat := number(node[keys[0]])
+		lng, okLng := number(node[keys[1]])
+		if !okLat || !okLng || (lat == 0 && lng == 0) {
+			continue
+		}
+		p := Point{Lat: lat, Lng: lng}
+		if p.Validate() != nil {
+			continue
+		}
+		return p, true
+	}
+	return Point{}, false
+}
@@ -0,0 +1,99 @@
+package geo
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestConvertJSONSkipsInvalidPoints(t *testing.T) {
+	data := []byte(`{"sn":"SN1","timestamp":1750000000123,"host":{"latitude":22.5,"longitude":114.05},` +
+		`"home":{"latitude":0,"longitude":0},"target":{"lat":123.4,"lng":114.05}}`)
+	out, err := ConvertJSON(data, WGS84, GCJ02)
+	if err != nil {
+		t.Fatalf("存在无效坐标时不应失败: %v", err)
+	}
+	var doc struct {
+		Timestamp int64                      `json:"timestamp"`
+		Host      struct{ Latitude float64 } `json:"host"`
+		Home      struct{ Latitude float64 } `json:"home"`
+		Target    struct{ Lat, Lng float64 } `json:"target"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Host.Latitude == 22.5 {
+		t.Error("有效坐标应被转换")
+	}
+	if doc.Home.Latitude != 0 || doc.Target.Lat != 123.4 || doc.Target.Lng != 114.05 {
+		t.Errorf("未定位与超出范围的坐标应保持原样: %s", out)
+	}
+	if doc.Timestamp != 1750000000123 {
+		t.Errorf("时间戳精度丢失: %d", doc.Timestamp)
+	}
+}
+
+// TestConvertJSONRoundTrip WGS-84 转 GCJ-02 再转回后与原坐标一致，嵌套对象与数组中的坐标均被转换
+func TestConvertJSONRoundTrip(t *testing.T) {
+	data := []byte(`{"host":{"latitude":22.5431,"longitude":113.9344},"track":[{"lat":22.5,"lng":114.05},{"lat":22.51,"lng":114.06}]}`)
+	gcj, err := ConvertJSON(data, WGS84, GCJ02)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := ConvertJSON(gcj, GCJ02, WGS84)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type doc struct {
+		Host  struct{ Latitude, Longitude float64 } `json:"host"`
+		Track []struct{ Lat, Lng float64 }          `json:"track"`
+	}
+	var want, mid, got doc
+	for _, item := range []struct {
+		raw []byte
+		v   *doc
+	}{{data, &want}, {gcj, &mid}, {back, &got}} {
+		if err := json.Unmarshal(item.raw, item.v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if math.Abs(mid.Host.Latitude-want.Host.Latitude) < 1e-4 || math.Abs(mid.Track[1].Lng-want.Track[1].Lng) < 1e-4 {
+		t.Fatalf("GCJ-02 坐标应有偏移: %s", gcj)
+	}
+	const tolerance = 1e-6
+	if math.Abs(got.Host.Latitude-want.Host.Latitude) > tolerance || math.Abs(got.Host.Longitude-want.Host.Longitude) > tolerance {
+		t.Errorf("往返转换后 host 为 %+v，应为 %+v", got.Host, want.Host)
+	}
+	for i := range want.Track {
+		if math.Abs(got.Track[i].Lat-want.Track[i].Lat) > tolerance || math.Abs(got.Track[i].Lng-want.Track[i].Lng) > tolerance {
+			t.Errorf("往返转换后 track[%d] 为 %+v，应为 %+v", i, got.Track[i], want.Track[i])
+		}
+	}
+}
+
+// TestConvertJSONMixedKeys 同一对象同时包含 lng 与 lon 时纬度只转换一次，不同字段组合各自转换
+func TestConvertJSONMixedKeys(t *testing.T) {
+	data := []byte(`{"lat":22.5,"lng":114.05,"lon":114.05,"target_latitude":22.6,"target_longitude":114.1}`)
+	out, err := ConvertJSON(data, WGS84, GCJ02)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Lat             float64 `json:"lat"`
+		Lng             float64 `json:"lng"`
+		Lon             float64 `json:"lon"`
+		TargetLatitude  float64 `json:"target_latitude"`
+		TargetLongitude float64 `json:"target_longitude"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	want := WGS84ToGCJ02(Point{Lat: 22.5, Lng: 114.05})
+	if doc.Lat != want.Lat || doc.Lng != want.Lng || doc.Lon != want.Lng {
+		t.Errorf("lat/lng/lon 转换为 %v/%v/%v，应为 %v/%v/%v", doc.Lat, doc.Lng, doc.Lon, want.Lat, want.Lng, want.Lng)
+	}
+	target := WGS84ToGCJ02(Point{Lat: 22.6, Lng: 114.1})
+	if doc.TargetLatitude != target.Lat || doc.TargetLongitude != target.Lng {
+		t.Errorf("target 坐标转换为 %v/%v，应为 %v/%v", doc.TargetLatitude, doc.TargetLongitude, target.Lat, target.Lng)
+	}
+}
@@ -0,0 +1,231 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Polygon 多边形外环，首尾点可以相同也可以不同
+// 计算基于以多边形中心为原点的局部平面投影，适用于机场净空区、作业区等数公里范围的区域
+type Polygon []Point
+
+// Validate 校验多边形：至少3个不同顶点，且坐标有效
+func (pg Polygon) Validate() error {
+	ring := pg.ring()
+	if len(ring) < 3 {
+		return fmt.Errorf("多边形至少需要3个顶点，当前为 %d", len(ring))
+	}
+	for i, p := range ring {
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("第 %d 个顶点%w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Contains 点是否在多边形内（射线法，边界上的点视为在内）
+func (pg Polygon) Contains(p Point) bool {
+	ring := pg.ring()
+	if len(ring) < 3 {
+		return false
+	}
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[j], ring[i]
+		if onSegment(p, a, b) {
+			return true
+		}
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) {
+			lng := (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat) + a.Lng
+			if p.Lng < lng {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// IntersectsPath 折线是否进入多边形：任一点在多边形内，或任一线段与多边形边相交
+func (pg Polygon) IntersectsPath(path []Point) bool {
+	ring := pg.ring()
+	if len(ring) < 3 {
+		return false
+	}
+	for _, p := range path {
+		if pg.Contains(p) {
+			return true
+		}
+	}
+	for i := 1; i < len(path); i++ {
+		for j, k := 0, len(ring)-1; j < len(ring); k, j = j, j+1 {
+			if segmentsIntersect(path[i-1], path[i], ring[k], ring[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DistanceTo 点到多边形边界的最短距离（米），点在多边形内时返回0
+func (pg Polygon) DistanceTo(p Point) float64 {
+	ring := pg.ring()
+	if len(ring) == 0 {
+		return math.Inf(1)
+	}
+	if pg.Contains(p) {
+		return 0
+	}
+	proj := newProjection(p)
+	x, y := 0.0, 0.0
+	best := math.Inf(1)
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		ax, ay := proj.forward(ring[j])
+		bx, by := proj.forward(ring[i])
+		best = math.Min(best, pointSegmentDistance(x, y, ax, ay, bx, by))
+	}
+	return best
+}
+
+// Centroid 顶点的平均位置
+func (pg Polygon) Centroid() Point {
+	ring := pg.ring()
+	var c Point
+	for _, p := range ring {
+		c.Lat += p.Lat
+		c.Lng += p.Lng
+	}
+	if n := float64(len(ring)); n > 0 {
+		c.Lat /= n
+		c.Lng /= n
+	}
+	return c
+}
+
+// Buffer 将多边形向外扩展指定距离（米），用于在禁飞区外留出安全距离；负数表示向内收缩
+// 顶点按斜接方式偏移，锐角处斜接过长时改为两个倒角点
+func (pg Polygon) Buffer(meters float64) Polygon {
+	ring := pg.ring()
+	if len(ring) < 3 || meters == 0 {
+		return append(Polygon(nil), ring...)
+	}
+	proj := newProjection(pg.Centroid())
+	xs := make([]float64, len(ring))
+	ys := make([]float64, len(ring))
+	area := 0.0
+	for i, p := range ring {
+		xs[i], ys[i] = proj.forward(p)
+	}
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		area += xs[i]*ys[j] - xs[j]*ys[i]
+	}
+	// 逆时针时外法线在边的右侧
+	sign := 1.0
+	if area < 0 {
+		sign = -1.0
+	}
+	d := meters * sign
+
+	const miterLimit = 4.0
+	var out Polygon
+	n := len(ring)
+	for i := 0; i < n; i++ {
+		prev, next := (i+n-1)%n, (i+1)%n
+		n1x, n1y := edgeNormal(xs[prev], ys[prev], xs[i], ys[i])
+		n2x, n2y := edgeNormal(xs[i], ys[i], xs[next], ys[next])
+		mx, my := n1x+n2x, n1y+n2y
+		dot := mx*n1x + my*n1y
+		if dot <= 1e-9 || 1/dot*2 > miterLimit {
+			// 倒角：分别沿两条边的法线偏移
+			out = append(out, proj.inverse(xs[i]+n1x*d, ys[i]+n1y*d), proj.inverse(xs[i]+n2x*d, ys[i]+n2y*d))
+			continue
+		}
+		scale := d / dot
+		out = append(out, proj.inverse(xs[i]+mx*scale, ys[i]+my*scale))
+	}
+	return out
+}
+
+// Circle 生成以 center 为圆心、radius 米为半径的近似圆形多边形（例如机场周边净空区）
+func Circle(center Point, radius float64, segments int) Polygon {
+	if segments < 8 {
+		segments = 8
+	}
+	circle := make(Polygon, segments)
+	for i := 0; i < segments; i++ {
+		circle[i] = Destination(center, float64(i)*360/float64(segments), radius)
+	}
+	return circle
+}
+
+// ring 去掉重复的闭合点
+func (pg Polygon) ring() Polygon {
+	if len(pg) > 1 && pg[0] == pg[len(pg)-1] {
+		return pg[:len(pg)-1]
+	}
+	return pg
+}
+
+// projection 以参考点为原点的等距圆柱投影（米）
+type projection struct {
+	origin Point
+	kx, ky float64
+}
+
+func newProjection(origin Point) projection {
+	ky := EarthRadius * math.Pi / 180
+	return projection{origin: origin, kx: ky * math.Cos(radians(origin.Lat)), ky: ky}
+}
+
+func (p projection) forward(pt Point) (float64, float64) {
+	return (pt.Lng - p.origin.Lng) * p.kx, (pt.Lat - p.origin.Lat) * p.ky
+}
+
+func (p projection) inverse(x, y float64) Point {
+	return Point{Lat: p.origin.Lat + y/p.ky, Lng: p.origin.Lng + x/p.kx}
+}
+
+// edgeNormal 边 a->b 右侧的单位法线
+func edgeNormal(ax, ay, bx, by float64) (float64, float64) {
+	dx, dy := bx-ax, by-ay
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return 0, 0
+	}
+	return dy / length, -dx / length
+}
+
+// pointSegmentDistance 平面上点到线段的距离
+func pointSegmentDistance(px, py, ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	lengthSq := dx*dx + dy*dy
+	t := 0.0
+	if lengthSq > 0 {
+		t = math.Max(0, math.Min(1, ((px-ax)*dx+(py-ay)*dy)/lengthSq))
+	}
+	return math.Hypot(px-(ax+t*dx), py-(ay+t*dy))
+}
+
+// cross 向量 ab 与 ac 的叉积（经纬度平面）
+func cross(a, b, c Point) float64 {
+	return (b.Lng-a.Lng)*(c.Lat-a.Lat) - (b.Lat-a.Lat)*(c.Lng-a.Lng)
+}
+
+// onSegment 点是否在线段上
+func onSegment(p, a, b Point) bool {
+	if math.Abs(cross(a, b, p)) > 1e-12 {
+		return false
+	}
+	return p.Lng >= math.Min(a.Lng, b.Lng) && p.Lng <= math.Max(a.Lng, b.Lng) &&
+		p.Lat >= math.Min(a.Lat, b.Lat) && p.Lat <= math.Max(a.Lat, b.Lat)
+}
+
+// segmentsIntersect 线段 p1p2 与 q1q2 是否相交（含端点接触）
+func segmentsIntersect(p1, p2, q1, q2 Point) bool {
+	d1, d2 := cross(q1, q2, p1), cross(q1, q2, p2)
+	d3, d4 := cross(p1, p2, q1), cross(p1, p2, q2)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return onSegment(p1, q1, q2) || onSegment(p2, q1, q2) || onSegment(q1, p1, p2) || onSegment(q2, p1, p2)
+}
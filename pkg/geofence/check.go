@@ -0,0 +1,134 @@
+package geofence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+var (
+	// ErrNoFlyZone 航线或目标点进入禁飞区，任务被拒绝
+	ErrNoFlyZone = errors.New("进入禁飞区")
+	// ErrOverrideRequired 航线或目标点进入限飞区，需要人工确认（WithOverride）后才能下发
+	ErrOverrideRequired = errors.New("进入限飞区，需要人工确认")
+)
+
+// Violation 单个围栏的违规信息
+type Violation struct {
+	ZoneID   string      `json:"zone_id"`
+	ZoneName string      `json:"zone_name"`
+	Kind     Kind        `json:"kind"`
+	Points   []geo.Point `json:"points,omitempty"` // 落在围栏内的航点或目标点
+	Crossing bool        `json:"crossing"`         // 航点均在围栏外，但航段穿越围栏
+}
+
+// ViolationError 校验未通过，可通过 errors.Is 判断 ErrNoFlyZone 或 ErrOverrideRequired
+type ViolationError struct {
+	Violations []Violation
+}
+
+func (e *ViolationError) Error() string {
+	names := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		names[i] = fmt.Sprintf("%s(%s)", v.ZoneName, v.Kind)
+	}
+	return fmt.Sprintf("电子围栏校验未通过: %v: %s", e.cause(), strings.Join(names, ", "))
+}
+
+// Is 禁飞区违规匹配 ErrNoFlyZone，仅限飞区违规匹配 ErrOverrideRequired
+func (e *ViolationError) Is(target error) bool {
+	return target == e.cause()
+}
+
+// cause 违规中存在禁飞区时为 ErrNoFlyZone，否则为 ErrOverrideRequired
+func (e *ViolationError) cause() error {
+	for _, v := range e.Violations {
+		if v.Kind == KindNoFly {
+			return ErrNoFlyZone
+		}
+	}
+	return ErrOverrideRequired
+}
+
+// overrideKey 人工确认上下文键
+type overrideKey struct{}
+
+// Override 限飞区人工确认
+type Override struct {
+	Operator string // 操作人，接口层为租户与用户令牌摘要（见 tenant.TenantInfo.Operator）
+	Reason   string // 确认原因
+}
+
+// WithOverride 在上下文中记录人工确认（操作人与原因），限飞区违规将放行并记录日志；禁飞区不可放行
+// 调用方负责校验操作人具备 tenant.PermGeofenceOverride 权限
+func WithOverride(ctx context.Context, operator, reason string) context.Context {
+	return context.WithValue(ctx, overrideKey{}, Override{Operator: operator, Reason: reason})
+}
+
+// OverrideFrom 读取上下文中的人工确认
+func OverrideFrom(ctx context.Context) (Override, bool) {
+	override, ok := ctx.Value(overrideKey{}).(Override)
+	return override, ok
+}
+
+// Checker 围栏校验器
+type Checker struct {
+	store *Store
+}
+
+// NewChecker 创建围栏校验器
+func NewChecker(store *Store) *Checker {
+	return &Checker{store: store}
+}
+
+// HasZones 指定范围内是否存在围栏，没有围栏时调用方可跳过获取航线几何
+func (c *Checker) HasZones(scope Scope) bool {
+	return len(c.store.Zones(scope)) > 0
+}
+
+// Check 校验折线（航线或单个目标点）是否进入围栏，返回全部违规
+func (c *Checker) Check(scope Scope, paths ...[]geo.Point) []Violation {
+	var violations []Violation
+	for _, zone := range c.store.Zones(scope) {
+		fence := zone.Fence()
+		violation := Violation{ZoneID: zone.ID, ZoneName: zone.displayName(), Kind: zone.Kind}
+		for _, path := range paths {
+			inside := false
+			for _, p := range path {
+				if fence.Contains(p) {
+					violation.Points = append(violation.Points, p)
+					inside = true
+				}
+			}
+			if !inside && fence.IntersectsPath(path) {
+				violation.Crossing = true
+			}
+		}
+		if len(violation.Points) > 0 || violation.Crossing {
+			violations = append(violations, violation)
+		}
+	}
+	return violations
+}
+
+// Enforce 下发任务前校验：进入禁飞区返回 ErrNoFlyZone；仅进入限飞区时，上下文带人工确认则放行，否则返回 ErrOverrideRequired
+func (c *Checker) Enforce(ctx context.Context, scope Scope, paths ...[]geo.Point) error {
+	violations := c.Check(scope, paths...)
+	if len(violations) == 0 {
+		return nil
+	}
+	err := &ViolationError{Violations: violations}
+	if errors.Is(err, ErrNoFlyZone) {
+		return err
+	}
+	override, ok := OverrideFrom(ctx)
+	if !ok {
+		return err
+	}
+	log.Printf("电子围栏人工确认放行: 租户 %d 项目 %s, %v, 操作人: %s, 原因: %s", scope.TenantID, scope.ProjectUUID, err, override.Operator, override.Reason)
+	return nil
+}
@@ -0,0 +1,144 @@
+package geofence
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+// square 纬度 22.50~22.51、经度 114.00~114.01 的正方形区域（边长约1公里）
+var square = geo.Polygon{
+	{Lat: 22.50, Lng: 114.00},
+	{Lat: 22.50, Lng: 114.01},
+	{Lat: 22.51, Lng: 114.01},
+	{Lat: 22.51, Lng: 114.00},
+}
+
+// TestZoneFence 围栏按安全距离外扩后判断点落入与航段穿越
+func TestZoneFence(t *testing.T) {
+	zone := Zone{Name: "作业区", Kind: KindNoFly, Polygon: square, Buffer: 100}
+	fence := zone.Fence()
+
+	points := []struct {
+		name   string
+		p      geo.Point
+		inside bool
+	}{
+		{"中心", geo.Point{Lat: 22.505, Lng: 114.005}, true},
+		{"顶点", geo.Point{Lat: 22.50, Lng: 114.00}, true},
+		{"安全距离内", geo.Point{Lat: 22.5105, Lng: 114.005}, true},
+		{"安全距离外", geo.Point{Lat: 22.515, Lng: 114.005}, false},
+	}
+	for _, tt := range points {
+		if got := fence.Contains(tt.p); got != tt.inside {
+			t.Errorf("%s: Contains = %v，应为 %v", tt.name, got, tt.inside)
+		}
+	}
+	if square.Contains(geo.Point{Lat: 22.5105, Lng: 114.005}) {
+		t.Error("未外扩的多边形不应包含安全距离内的点")
+	}
+
+	paths := []struct {
+		name       string
+		path       []geo.Point
+		intersects bool
+	}{
+		{"端点均在外的穿越航段", []geo.Point{{Lat: 22.505, Lng: 113.99}, {Lat: 22.505, Lng: 114.02}}, true},
+		{"航点落入", []geo.Point{{Lat: 22.52, Lng: 114.005}, {Lat: 22.505, Lng: 114.005}}, true},
+		{"平行绕行", []geo.Point{{Lat: 22.52, Lng: 113.99}, {Lat: 22.52, Lng: 114.02}}, false},
+	}
+	for _, tt := range paths {
+		if got := fence.IntersectsPath(tt.path); got != tt.intersects {
+			t.Errorf("%s: IntersectsPath = %v，应为 %v", tt.name, got, tt.intersects)
+		}
+	}
+}
+
+// TestEnforce 禁飞区一律拒绝，限飞区人工确认后放行并记录操作人，围栏只对所属范围生效
+func TestEnforce(t *testing.T) {
+	store := NewStore()
+	checker := NewChecker(store)
+	restricted := geo.Polygon{
+		{Lat: 22.60, Lng: 114.00},
+		{Lat: 22.60, Lng: 114.01},
+		{Lat: 22.61, Lng: 114.01},
+		{Lat: 22.61, Lng: 114.00},
+	}
+	for _, zone := range []Zone{
+		{ID: "airport", Name: "机场净空区", Kind: KindNoFly, Polygon: square},
+		{ID: "park", Name: "公园", Kind: KindRestricted, Scope: Scope{TenantID: 1}, Polygon: restricted},
+	} {
+		if _, err := store.Add(zone); err != nil {
+			t.Fatal(err)
+		}
+	}
+	override := WithOverride(context.Background(), "租户 1 令牌 abc", "已报备")
+	inNoFly := []geo.Point{{Lat: 22.505, Lng: 114.005}}
+	inRestricted := []geo.Point{{Lat: 22.605, Lng: 114.005}}
+	crossRestricted := []geo.Point{{Lat: 22.605, Lng: 113.99}, {Lat: 22.605, Lng: 114.02}}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		scope   Scope
+		path    []geo.Point
+		wantErr error
+	}{
+		{"围栏外", context.Background(), Scope{TenantID: 1}, []geo.Point{{Lat: 22.7, Lng: 114.2}}, nil},
+		{"全局禁飞区", context.Background(), Scope{TenantID: 2}, inNoFly, ErrNoFlyZone},
+		{"禁飞区不可确认放行", override, Scope{TenantID: 1}, inNoFly, ErrNoFlyZone},
+		{"限飞区未确认", context.Background(), Scope{TenantID: 1}, inRestricted, ErrOverrideRequired},
+		{"航段穿越限飞区", context.Background(), Scope{TenantID: 1}, crossRestricted, ErrOverrideRequired},
+		{"限飞区确认放行", override, Scope{TenantID: 1}, inRestricted, nil},
+		{"其他租户的限飞区", context.Background(), Scope{TenantID: 2}, inRestricted, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checker.Enforce(tt.ctx, tt.scope, tt.path)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("应放行，实际 %v", err)
+				}
+				return
+			}
+			var violation *ViolationError
+			if !errors.Is(err, tt.wantErr) || !errors.As(err, &violation) || len(violation.Violations) != 1 {
+				t.Fatalf("错误 %v，应为 %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	err := checker.Enforce(context.Background(), Scope{TenantID: 1}, crossRestricted)
+	var violation *ViolationError
+	if errors.As(err, &violation) && (!violation.Violations[0].Crossing || len(violation.Violations[0].Points) != 0) {
+		t.Errorf("端点均在围栏外的航段应标记为穿越: %+v", violation.Violations[0])
+	}
+}
+
+// TestEnforceOverrideLog 确认放行时日志记录操作人与原因
+func TestEnforceOverrideLog(t *testing.T) {
+	store := NewStore()
+	if _, err := store.Add(Zone{Name: "公园", Kind: KindRestricted, Polygon: square}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	ctx := WithOverride(context.Background(), "租户 1 令牌 1cebed5980a8", "已向空管报备")
+	if err := NewChecker(store).Enforce(ctx, Scope{TenantID: 1}, []geo.Point{{Lat: 22.505, Lng: 114.005}}); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "操作人: 租户 1 令牌 1cebed5980a8") || !strings.Contains(out, "原因: 已向空管报备") {
+		t.Errorf("放行日志缺少操作人或原因: %s", out)
+	}
+	if got, ok := OverrideFrom(ctx); !ok || got.Operator != "租户 1 令牌 1cebed5980a8" || got.Reason != "已向空管报备" {
+		t.Errorf("OverrideFrom = %+v, %v", got, ok)
+	}
+}
@@ -0,0 +1,46 @@
+package geofence
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+)
+
+var (
+	configMu      sync.Mutex
+	configSources = make(map[string]bool) // 由配置文件导入的围栏来源
+)
+
+// ApplyConfig 按配置文件 Geofences 段导入围栏文件到全局存储
+// 已导入的文件重新导入（整体替换），从配置中移除的文件对应围栏被删除；单个文件失败不影响其他文件
+func ApplyConfig(files []config.GeofenceFile) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	var errs []error
+	declared := make(map[string]bool)
+	for _, file := range files {
+		if file.File == "" {
+			errs = append(errs, fmt.Errorf("电子围栏配置缺少 file"))
+			continue
+		}
+		declared[file.File] = true
+		zones, err := defaultStore.ImportFile(Scope{TenantID: file.TenantID, ProjectUUID: file.ProjectUUID}, file.File)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		configSources[file.File] = true
+		log.Printf("已导入电子围栏文件 %s: %d 个区域", file.File, len(zones))
+	}
+	for source := range configSources {
+		if !declared[source] {
+			defaultStore.RemoveSource(source)
+			delete(configSources, source)
+		}
+	}
+	return errors.Join(errs...)
+}
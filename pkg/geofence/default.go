@@ -0,0 +1,59 @@
+package geofence
+
+import (
+	"context"
+	"log"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+// 全局围栏存储、校验器与位置监控，插件与接口层共用
+var (
+	defaultStore   = NewStore()
+	defaultChecker = NewChecker(defaultStore)
+	defaultMonitor = NewMonitor(defaultStore)
+)
+
+func init() {
+	defaultMonitor.OnAlert(func(alert Alert) {
+		switch alert.Type {
+		case AlertBreach:
+			log.Printf("电子围栏告警: 设备 %s 进入%s %s, 位置 %.6f,%.6f", alert.DeviceSN, kindName(alert.Kind), alert.ZoneName, alert.Position.Lat, alert.Position.Lng)
+		case AlertCleared:
+			log.Printf("电子围栏告警解除: 设备 %s 离开 %s", alert.DeviceSN, alert.ZoneName)
+		}
+	})
+}
+
+// DefaultStore 全局围栏存储
+func DefaultStore() *Store {
+	return defaultStore
+}
+
+// HasZones 全局存储中指定范围是否存在围栏
+func HasZones(scope Scope) bool {
+	return defaultChecker.HasZones(scope)
+}
+
+// Enforce 使用全局存储校验航线与目标点
+func Enforce(ctx context.Context, scope Scope, paths ...[]geo.Point) error {
+	return defaultChecker.Enforce(ctx, scope, paths...)
+}
+
+// Observe 向全局位置监控上报设备位置
+func Observe(scope Scope, sn string, p geo.Point) []Alert {
+	return defaultMonitor.Observe(scope, sn, p)
+}
+
+// OnAlert 注册全局位置监控的告警回调
+func OnAlert(handler func(Alert)) {
+	defaultMonitor.OnAlert(handler)
+}
+
+// kindName 围栏类型中文名称
+func kindName(kind Kind) string {
+	if kind == KindRestricted {
+		return "限飞区"
+	}
+	return "禁飞区"
+}
@@ -0,0 +1,173 @@
+package geofence
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+// geoJSON GeoJSON 对象（FeatureCollection、Feature 或几何对象）
+type geoJSON struct {
+	Type        string                 `json:"type"`
+	Features    []geoJSON              `json:"features"`
+	Geometry    *geoJSON               `json:"geometry"`
+	Geometries  []geoJSON              `json:"geometries"`
+	Properties  map[string]interface{} `json:"properties"`
+	Coordinates json.RawMessage        `json:"coordinates"`
+	ID          interface{}            `json:"id"`
+}
+
+// ParseGeoJSON 解析 GeoJSON 为围栏
+// 支持 FeatureCollection、Feature、GeometryCollection、Polygon、MultiPolygon，以及带 radius（米）属性的 Point（按圆形处理）。
+// Feature 属性：name 名称、kind 类型（no_fly/restricted，默认 no_fly）、buffer 安全距离（米）、id 编号。
+// 多边形只使用外环，内环（洞）忽略，判定结果偏保守
+func ParseGeoJSON(data []byte) ([]Zone, error) {
+	var doc geoJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析GeoJSON失败: %w", err)
+	}
+	var zones []Zone
+	if err := collectZones(&doc, nil, &zones); err != nil {
+		return nil, err
+	}
+	if len(zones) == 0 {
+		return nil, fmt.Errorf("GeoJSON 中没有可用的多边形")
+	}
+	return zones, nil
+}
+
+// collectZones 递归解析对象，properties 为所属 Feature 的属性
+func collectZones(obj *geoJSON, properties map[string]interface{}, zones *[]Zone) error {
+	switch obj.Type {
+	case "FeatureCollection":
+		for i := range obj.Features {
+			if err := collectZones(&obj.Features[i], nil, zones); err != nil {
+				return fmt.Errorf("第 %d 个要素: %w", i+1, err)
+			}
+		}
+		return nil
+	case "Feature":
+		if obj.Geometry == nil {
+			return nil
+		}
+		props := obj.Properties
+		if props == nil {
+			props = map[string]interface{}{}
+		}
+		if _, ok := props["id"]; !ok && obj.ID != nil {
+			props["id"] = obj.ID
+		}
+		return collectZones(obj.Geometry, props, zones)
+	case "GeometryCollection":
+		for i := range obj.Geometries {
+			if err := collectZones(&obj.Geometries[i], properties, zones); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var polygons []geo.Polygon
+	switch obj.Type {
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &rings); err != nil {
+			return fmt.Errorf("Polygon 坐标格式错误: %w", err)
+		}
+		polygon, err := outerRing(rings)
+		if err != nil {
+			return err
+		}
+		polygons = append(polygons, polygon)
+	case "MultiPolygon":
+		var multi [][][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &multi); err != nil {
+			return fmt.Errorf("MultiPolygon 坐标格式错误: %w", err)
+		}
+		for _, rings := range multi {
+			polygon, err := outerRing(rings)
+			if err != nil {
+				return err
+			}
+			polygons = append(polygons, polygon)
+		}
+	case "Point":
+		radius, ok := floatProperty(properties, "radius")
+		if !ok || radius <= 0 {
+			// 普通点位（如标注）不构成围栏
+			return nil
+		}
+		var position []float64
+		if err := json.Unmarshal(obj.Coordinates, &position); err != nil || len(position) < 2 {
+			return fmt.Errorf("Point 坐标格式错误")
+		}
+		polygons = append(polygons, geo.Circle(geo.Point{Lat: position[1], Lng: position[0]}, radius, 64))
+	default:
+		// LineString 等其他几何类型不构成围栏
+		return nil
+	}
+
+	kind, err := ParseKind(stringProperty(properties, "kind"))
+	if err != nil {
+		return err
+	}
+	buffer, _ := floatProperty(properties, "buffer")
+	baseID := stringProperty(properties, "id")
+	for i, polygon := range polygons {
+		zone := Zone{
+			ID:      baseID,
+			Name:    stringProperty(properties, "name"),
+			Kind:    kind,
+			Polygon: polygon,
+			Buffer:  buffer,
+		}
+		if baseID != "" && len(polygons) > 1 {
+			zone.ID = fmt.Sprintf("%s-%d", baseID, i+1)
+		}
+		if err := zone.Validate(); err != nil {
+			return err
+		}
+		*zones = append(*zones, zone)
+	}
+	return nil
+}
+
+// outerRing 取多边形外环，GeoJSON 坐标顺序为 [经度, 纬度]
+func outerRing(rings [][][]float64) (geo.Polygon, error) {
+	if len(rings) == 0 {
+		return nil, fmt.Errorf("多边形缺少坐标")
+	}
+	polygon := make(geo.Polygon, 0, len(rings[0]))
+	for _, position := range rings[0] {
+		if len(position) < 2 {
+			return nil, fmt.Errorf("坐标至少需要经度与纬度")
+		}
+		polygon = append(polygon, geo.Point{Lat: position[1], Lng: position[0]})
+	}
+	return polygon, nil
+}
+
+// stringProperty 读取字符串属性，数字属性转换为字符串
+func stringProperty(properties map[string]interface{}, key string) string {
+	switch v := properties[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// floatProperty 读取数值属性，支持数字字符串
+func floatProperty(properties map[string]interface{}, key string) (float64, bool) {
+	switch v := properties[key].(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
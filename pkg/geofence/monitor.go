@@ -0,0 +1,104 @@
+package geofence
+
+import (
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+// AlertType 告警类型
+type AlertType string
+
+const (
+	AlertBreach  AlertType = "breach"  // 飞行器进入围栏
+	AlertCleared AlertType = "cleared" // 飞行器离开围栏
+)
+
+// Alert 围栏告警
+type Alert struct {
+	Type     AlertType `json:"type"`
+	DeviceSN string    `json:"device_sn"`
+	Scope    Scope     `json:"scope"`
+	ZoneID   string    `json:"zone_id"`
+	ZoneName string    `json:"zone_name"`
+	Kind     Kind      `json:"kind"`
+	Position geo.Point `json:"position"`
+	At       time.Time `json:"at"`
+}
+
+// Monitor 实时位置监控，飞行器进入或离开围栏时各触发一次告警
+type Monitor struct {
+	store *Store
+
+	mu       sync.Mutex
+	inside   map[string]map[string]bool // 设备序列号 -> 当前所在围栏
+	handlers []func(Alert)
+}
+
+// NewMonitor 创建位置监控
+func NewMonitor(store *Store) *Monitor {
+	return &Monitor{store: store, inside: make(map[string]map[string]bool)}
+}
+
+// OnAlert 注册告警回调，回调在 Observe 调用方的协程中同步执行
+func (m *Monitor) OnAlert(handler func(Alert)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, handler)
+}
+
+// Observe 上报设备位置，返回本次产生的告警；未定位（0,0）的位置忽略
+func (m *Monitor) Observe(scope Scope, sn string, p geo.Point) []Alert {
+	if (p.Lat == 0 && p.Lng == 0) || p.Validate() != nil {
+		return nil
+	}
+	now := time.Now()
+	current := make(map[string]bool)
+	zones := make(map[string]Zone)
+	for _, zone := range m.store.Zones(scope) {
+		zones[zone.ID] = zone
+		if zone.Fence().Contains(p) {
+			current[zone.ID] = true
+		}
+	}
+
+	m.mu.Lock()
+	previous := m.inside[sn]
+	m.inside[sn] = current
+	handlers := append([]func(Alert){}, m.handlers...)
+	m.mu.Unlock()
+
+	var alerts []Alert
+	newAlert := func(t AlertType, zoneID string) Alert {
+		zone := zones[zoneID]
+		alert := Alert{Type: t, DeviceSN: sn, Scope: scope, ZoneID: zoneID, ZoneName: zone.Name, Kind: zone.Kind, Position: p, At: now}
+		if alert.ZoneName == "" {
+			alert.ZoneName = zoneID
+		}
+		return alert
+	}
+	for id := range current {
+		if !previous[id] {
+			alerts = append(alerts, newAlert(AlertBreach, id))
+		}
+	}
+	for id := range previous {
+		if !current[id] {
+			alerts = append(alerts, newAlert(AlertCleared, id))
+		}
+	}
+	for _, alert := range alerts {
+		for _, handler := range handlers {
+			handler(alert)
+		}
+	}
+	return alerts
+}
+
+// Forget 清除设备的围栏状态（设备离线或任务结束时调用），下次进入围栏会重新告警
+func (m *Monitor) Forget(sn string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.inside, sn)
+}
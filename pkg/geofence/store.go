@@ -0,0 +1,143 @@
+package geofence
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Store 围栏存储（内存），并发安全
+type Store struct {
+	mu    sync.RWMutex
+	zones map[string]*Zone
+}
+
+// NewStore 创建围栏存储
+func NewStore() *Store {
+	return &Store{zones: make(map[string]*Zone)}
+}
+
+// Add 添加或更新围栏，ID 为空时自动生成，返回保存后的围栏
+func (s *Store) Add(zone Zone) (Zone, error) {
+	kind, err := ParseKind(string(zone.Kind))
+	if err != nil {
+		return Zone{}, err
+	}
+	zone.Kind = kind
+	if err := zone.Validate(); err != nil {
+		return Zone{}, err
+	}
+	if zone.ID == "" {
+		zone.ID = uuid.New().String()
+	}
+	zone.fence = nil
+	zone.Fence()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.zones[zone.ID] = &zone
+	return zone, nil
+}
+
+// Remove 删除围栏，返回是否存在
+func (s *Store) Remove(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.zones[id]
+	delete(s.zones, id)
+	return exists
+}
+
+// RemoveSource 删除指定来源导入的全部围栏，返回删除数量
+func (s *Store) RemoveSource(source string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.removeSource(source)
+}
+
+// removeSource 删除指定来源的围栏，调用方需持有写锁
+func (s *Store) removeSource(source string) int {
+	removed := 0
+	for id, zone := range s.zones {
+		if zone.Source == source {
+			delete(s.zones, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Get 获取围栏
+func (s *Store) Get(id string) (Zone, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	zone, exists := s.zones[id]
+	if !exists {
+		return Zone{}, false
+	}
+	return *zone, true
+}
+
+// Zones 返回适用于指定范围的围栏：全局围栏、租户级围栏以及该项目的围栏，按名称排序
+func (s *Store) Zones(scope Scope) []Zone {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var zones []Zone
+	for _, zone := range s.zones {
+		if zone.Scope.covers(scope) {
+			zones = append(zones, *zone)
+		}
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		if zones[i].Name != zones[j].Name {
+			return zones[i].Name < zones[j].Name
+		}
+		return zones[i].ID < zones[j].ID
+	})
+	return zones
+}
+
+// ImportGeoJSON 导入 GeoJSON 中的围栏到指定范围
+// source 标识导入来源，同一来源再次导入时先删除上次导入的围栏，便于配置文件修改后重新加载
+func (s *Store) ImportGeoJSON(scope Scope, source string, data []byte) ([]Zone, error) {
+	zones, err := ParseGeoJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	for i := range zones {
+		zones[i].Scope = scope
+		zones[i].Source = source
+		if zones[i].ID == "" {
+			zones[i].ID = uuid.New().String()
+		}
+		zones[i].Fence()
+	}
+
+	// 删除与写入在同一把锁内完成，重新导入期间不会出现围栏缺失
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if source != "" {
+		s.removeSource(source)
+	}
+	for i := range zones {
+		zone := zones[i]
+		s.zones[zone.ID] = &zone
+	}
+	return zones, nil
+}
+
+// ImportFile 从 GeoJSON 文件导入围栏，来源为文件路径
+func (s *Store) ImportFile(scope Scope, path string) ([]Zone, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取围栏文件失败: %w", err)
+	}
+	zones, err := s.ImportGeoJSON(scope, path, data)
+	if err != nil {
+		return nil, fmt.Errorf("导入围栏文件 %s 失败: %w", path, err)
+	}
+	return zones, nil
+}
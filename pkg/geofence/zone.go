@@ -0,0 +1,98 @@
+// Package geofence 电子围栏
+// 按租户、项目保存禁飞区与限飞区多边形（支持 GeoJSON 导入），在下发飞行任务前校验航线与目标点，
+// 并根据实时 OSD 位置检测飞行器闯入围栏并告警。坐标均为 WGS-84。
+package geofence
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+)
+
+// Kind 围栏类型
+type Kind string
+
+const (
+	KindNoFly      Kind = "no_fly"     // 禁飞区（如机场净空区），进入的任务一律拒绝
+	KindRestricted Kind = "restricted" // 限飞区（客户自定义），需人工确认后放行
+)
+
+// ParseKind 解析围栏类型，为空时视为禁飞区
+func ParseKind(name string) (Kind, error) {
+	switch Kind(strings.ToLower(strings.TrimSpace(name))) {
+	case "", KindNoFly, "nofly", "no-fly":
+		return KindNoFly, nil
+	case KindRestricted:
+		return KindRestricted, nil
+	}
+	return "", fmt.Errorf("不支持的围栏类型: %s", name)
+}
+
+// Scope 围栏适用范围
+type Scope struct {
+	TenantID    int64  `json:"tenant_id"`    // 0 表示全部租户（如全局机场禁飞区）
+	ProjectUUID string `json:"project_uuid"` // 为空表示租户下全部项目
+}
+
+// ScopeFromContext 从上下文的租户信息获取范围，无租户信息时只匹配全局围栏
+func ScopeFromContext(ctx context.Context) Scope {
+	info, err := tenant.GetTenantFromContext(ctx)
+	if err != nil {
+		return Scope{}
+	}
+	return Scope{TenantID: info.TenantId, ProjectUUID: info.ProjectUUID}
+}
+
+// covers 围栏范围是否覆盖查询范围
+func (s Scope) covers(query Scope) bool {
+	if s.TenantID != 0 && s.TenantID != query.TenantID {
+		return false
+	}
+	return s.ProjectUUID == "" || s.ProjectUUID == query.ProjectUUID
+}
+
+// Zone 围栏区域
+type Zone struct {
+	ID      string      `json:"id"`
+	Name    string      `json:"name"`
+	Kind    Kind        `json:"kind"`
+	Scope   Scope       `json:"scope"`
+	Source  string      `json:"source,omitempty"` // 导入来源（如 GeoJSON 文件），同一来源重新导入时整体替换
+	Polygon geo.Polygon `json:"polygon"`
+	Buffer  float64     `json:"buffer"` // 外扩安全距离（米）
+
+	fence geo.Polygon // 外扩后的实际判定区域
+}
+
+// Validate 校验围栏参数
+func (z *Zone) Validate() error {
+	if _, err := ParseKind(string(z.Kind)); err != nil {
+		return err
+	}
+	if z.Buffer < 0 {
+		return fmt.Errorf("围栏 %s 安全距离不能为负数: %v", z.Name, z.Buffer)
+	}
+	if err := z.Polygon.Validate(); err != nil {
+		return fmt.Errorf("围栏 %s 多边形无效: %w", z.Name, err)
+	}
+	return nil
+}
+
+// Fence 判定使用的区域：多边形按安全距离外扩后的结果
+func (z *Zone) Fence() geo.Polygon {
+	if z.fence == nil {
+		z.fence = z.Polygon.Buffer(z.Buffer)
+	}
+	return z.fence
+}
+
+// displayName 用于提示信息的名称
+func (z *Zone) displayName() string {
+	if z.Name != "" {
+		return z.Name
+	}
+	return z.ID
+}
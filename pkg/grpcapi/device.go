@@ -0,0 +1,290 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	dispatchpb "gitee.com/jamespi/drone_dispatch/pkg/grpcapi/proto"
+	"gitee.com/jamespi/drone_dispatch/pkg/hms"
+	"gitee.com/jamespi/drone_dispatch/pkg/telemetry"
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+	"gitee.com/jamespi/drone_dispatch/plugin"
+	"gitee.com/jamespi/drone_dispatch/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// streamBuffer 每个推送流缓存的事件数，客户端消费过慢时丢弃新事件
+const streamBuffer = 256
+
+// deviceService 实现 DeviceService
+type deviceService struct {
+	dispatchpb.UnimplementedDeviceServiceServer
+	server *Server
+}
+
+// selectFor 选出为设备提供能力T的插件实例，同时确认设备属于当前租户
+func selectFor[T interface{}](ctx context.Context, sn string) (T, error) {
+	if err := validator.GetValidator().ValidateDeviceSN(sn); err != nil {
+		var zero T
+		return zero, status.Errorf(codes.InvalidArgument, "设备序列号无效: %v", err)
+	}
+	capable, err := plugin.SelectForDevice[T](ctx, sn)
+	if err != nil {
+		var zero T
+		return zero, status.Error(codes.NotFound, err.Error())
+	}
+	return capable.Impl, nil
+}
+
+// requireSN 推送流至少指定一台设备，避免订阅到其他租户的设备
+func requireSN(list []string) error {
+	if len(list) == 0 {
+		return status.Error(codes.InvalidArgument, "至少指定一个设备序列号")
+	}
+	return nil
+}
+
+// ListDevices 项目下的设备列表
+func (d *deviceService) ListDevices(ctx context.Context, _ *dispatchpb.ListDevicesRequest) (*dispatchpb.JsonResponse, error) {
+	fh2, err := d.server.fh2(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResponse(fh2.GetDeviceList(ctx))
+}
+
+// GetDeviceState 设备遥测状态
+func (d *deviceService) GetDeviceState(ctx context.Context, req *dispatchpb.DeviceRequest) (*dispatchpb.JsonResponse, error) {
+	source, err := selectFor[service.TelemetrySource](ctx, req.GetSn())
+	if err != nil {
+		return nil, err
+	}
+	return jsonResponse(source.GetDeviceState(ctx, req.GetSn()))
+}
+
+// GetDeviceHms 设备当前的HMS告警，逐台设备向管理它的插件查询
+func (d *deviceService) GetDeviceHms(ctx context.Context, req *dispatchpb.GetDeviceHmsRequest) (*dispatchpb.GetDeviceHmsResponse, error) {
+	if err := requireSN(req.GetSn()); err != nil {
+		return nil, err
+	}
+	lang, err := hms.ParseLang(req.GetLang())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp := &dispatchpb.GetDeviceHmsResponse{}
+	for _, sn := range req.GetSn() {
+		provider, err := selectFor[service.HmsProvider](ctx, sn)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := provider.GetDeviceHms(ctx, sn)
+		if err != nil {
+			return nil, err
+		}
+		alarms, err := hms.ParseList([]byte(raw))
+		if err != nil {
+			return nil, err
+		}
+		for _, alarm := range alarms {
+			resp.Alarms = append(resp.Alarms, hmsAlarm(hms.DefaultCatalog().Decode(alarm, lang)))
+		}
+	}
+	return resp, nil
+}
+
+// SendCommand 实时控制指令
+func (d *deviceService) SendCommand(ctx context.Context, req *dispatchpb.SendCommandRequest) (*dispatchpb.JsonResponse, error) {
+	controller, err := selectFor[service.DeviceController](ctx, req.GetSn())
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]string{"device_command": req.GetCommand()})
+	if err != nil {
+		return nil, err
+	}
+	return jsonResponse(controller.UpdateDeviceCommand(ctx, req.GetSn(), strings.NewReader(string(body))))
+}
+
+// StreamOsd 飞行器OSD推送：主动上报的插件订阅全局OSD，其余插件在流的生命周期内按间隔轮询
+func (d *deviceService) StreamOsd(req *dispatchpb.StreamOsdRequest, stream grpc.ServerStreamingServer[dispatchpb.OsdEvent]) error {
+	ctx := stream.Context()
+	if err := requireSN(req.GetSn()); err != nil {
+		return err
+	}
+	events := make(chan telemetry.OSDEvent, streamBuffer)
+	deliver := func(event telemetry.OSDEvent) {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+	wanted := make(map[string]bool)
+	polled := make(map[service.TelemetrySource][]string)
+	for _, sn := range req.GetSn() {
+		source, err := selectFor[service.TelemetrySource](ctx, sn)
+		if err != nil {
+			return err
+		}
+		wanted[sn] = true
+		if !telemetry.IsPusher(source) {
+			polled[source] = append(polled[source], sn)
+		}
+	}
+	unsubscribe := telemetry.OnOSD(func(event telemetry.OSDEvent) {
+		if wanted[event.DeviceSN] {
+			deliver(event)
+		}
+	})
+	defer unsubscribe()
+	interval := time.Duration(req.GetIntervalMs()) * time.Millisecond
+	for source, devices := range polled {
+		poller := telemetry.NewOSDPoller(source, devices, interval)
+		poller.Start(ctx, deliver)
+		defer poller.Stop()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-events:
+			if err := stream.Send(osdEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamHmsEvents HMS告警触发与消除推送：先推送设备当前的告警，之后推送变化
+// 轮询型插件（如司空2）在流的生命周期内定时查询告警，与其他订阅同一设备的流及 WebSocket 推送共用轮询，由全局去重器产生事件
+func (d *deviceService) StreamHmsEvents(req *dispatchpb.StreamHmsEventsRequest, stream grpc.ServerStreamingServer[dispatchpb.HmsEvent]) error {
+	ctx := stream.Context()
+	if err := requireSN(req.GetSn()); err != nil {
+		return err
+	}
+	var lang hms.Lang
+	if req.GetLang() != "" {
+		parsed, err := hms.ParseLang(req.GetLang())
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		lang = parsed
+	}
+	wanted := make(map[string]bool)
+	polled := make(map[string]service.HmsProvider)
+	for _, sn := range req.GetSn() {
+		provider, err := selectFor[service.HmsProvider](ctx, sn)
+		if err != nil {
+			return err
+		}
+		wanted[sn] = true
+		if !telemetry.IsPusher(provider) {
+			polled[sn] = provider
+		}
+	}
+	events := make(chan hms.Event, streamBuffer)
+	unsubscribe := hms.OnEvent(func(event hms.Event) {
+		if !wanted[event.DeviceSN] {
+			return
+		}
+		select {
+		case events <- event:
+		default:
+		}
+	})
+	defer unsubscribe()
+	for _, sn := range req.GetSn() {
+		for _, alarm := range hms.DefaultTracker().Active(sn) {
+			if err := stream.Send(hmsEvent(hms.Event{Type: hms.EventRaise, DeviceSN: sn, Alarm: alarm, At: time.Now()}, lang)); err != nil {
+				return err
+			}
+		}
+	}
+	for sn, provider := range polled {
+		defer hms.Watch(ctx, provider, sn, d.server.options().PollInterval)()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-events:
+			if err := stream.Send(hmsEvent(event, lang)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// jsonResponse 适配器返回值转换为 JsonResponse，司空2响应只保留 data 部分
+func jsonResponse(resp string, err error) (*dispatchpb.JsonResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &dispatchpb.JsonResponse{Data: string(unwrapData(resp))}, nil
+}
+
+// unwrapData 取出司空2响应 {"code":0,"data":...} 中的 data，其他JSON原样返回
+func unwrapData(resp string) json.RawMessage {
+	trimmed := strings.TrimSpace(resp)
+	var upstream struct {
+		Code *int            `json:"code"`
+		Data json.RawMessage `json:"data"`
+	}
+	if strings.HasPrefix(trimmed, "{") && json.Unmarshal([]byte(trimmed), &upstream) == nil && upstream.Code != nil {
+		if upstream.Data == nil {
+			return json.RawMessage("null")
+		}
+		return upstream.Data
+	}
+	return json.RawMessage(trimmed)
+}
+
+func osdEvent(event telemetry.OSDEvent) *dispatchpb.OsdEvent {
+	return &dispatchpb.OsdEvent{
+		Sn:          event.DeviceSN,
+		Latitude:    event.OSD.Latitude,
+		Longitude:   event.OSD.Longitude,
+		Altitude:    event.OSD.Altitude,
+		Battery:     int32(event.OSD.Battery),
+		Speed:       event.OSD.Speed,
+		FlightState: int32(event.OSD.FlightState),
+		Timestamp:   event.At.UnixMilli(),
+	}
+}
+
+// hmsEvent 转换告警事件，lang 非空时按该语言重新解码
+func hmsEvent(event hms.Event, lang hms.Lang) *dispatchpb.HmsEvent {
+	decoded := event.Alarm
+	if lang != "" {
+		decoded = hms.DefaultCatalog().Decode(decoded.Alarm, lang)
+	}
+	return &dispatchpb.HmsEvent{
+		Type:      string(event.Type),
+		Sn:        event.DeviceSN,
+		Alarm:     hmsAlarm(decoded),
+		Timestamp: event.At.UnixMilli(),
+	}
+}
+
+func hmsAlarm(d hms.Decoded) *dispatchpb.HmsAlarm {
+	return &dispatchpb.HmsAlarm{
+		HmsId:      d.HmsID,
+		DeviceSn:   d.DeviceSN,
+		Level:      int32(d.Level),
+		Module:     int32(d.Module),
+		Code:       d.Code,
+		InTheSky:   d.InTheSky == 1,
+		Imminent:   d.Imminent == 1,
+		CreateTime: d.CreateTime,
+		LevelText:  d.LevelText,
+		ModuleText: d.ModuleText,
+		Message:    d.Message,
+		Action:     d.Action,
+		Known:      d.Known,
+	}
+}
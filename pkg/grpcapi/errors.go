@@ -0,0 +1,81 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geofence"
+	"gitee.com/jamespi/drone_dispatch/pkg/preflight"
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+	"gitee.com/jamespi/drone_dispatch/pkg/weather"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// 前置条件错误详情中的违规类型
+const (
+	PreconditionNoFlyZone        = "GEOFENCE_NO_FLY"
+	PreconditionOverrideRequired = "GEOFENCE_OVERRIDE_REQUIRED"
+	PreconditionPreflight        = "PREFLIGHT"
+	PreconditionWeather          = "WEATHER"
+)
+
+// toStatus 将业务错误映射为 gRPC 状态，校验与前置条件错误附带结构化详情
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var fieldErrs validator.ValidationErrors
+	var violation *geofence.ViolationError
+	switch {
+	case errors.As(err, &fieldErrs):
+		detail := &errdetails.BadRequest{}
+		for _, fe := range fieldErrs {
+			detail.FieldViolations = append(detail.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: fe.Field, Description: fe.Message})
+		}
+		return withDetails(codes.InvalidArgument, err, detail)
+	case errors.As(err, &violation):
+		kind := PreconditionNoFlyZone
+		if errors.Is(err, geofence.ErrOverrideRequired) {
+			kind = PreconditionOverrideRequired
+		}
+		detail := &errdetails.PreconditionFailure{}
+		for _, v := range violation.Violations {
+			detail.Violations = append(detail.Violations, &errdetails.PreconditionFailure_Violation{
+				Type:        kind,
+				Subject:     v.ZoneID,
+				Description: fmt.Sprintf("%s(%s)", v.ZoneName, v.Kind),
+			})
+		}
+		return withDetails(codes.FailedPrecondition, err, detail)
+	case errors.Is(err, preflight.ErrPreflightFailed):
+		return withDetails(codes.FailedPrecondition, err, &errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{Type: PreconditionPreflight, Description: err.Error()}},
+		})
+	case errors.Is(err, weather.ErrUnsafe):
+		return withDetails(codes.FailedPrecondition, err, &errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{Type: PreconditionWeather, Description: err.Error()}},
+		})
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	// 司空2、机场等上游返回的错误
+	return status.Error(codes.Unknown, err.Error())
+}
+
+// withDetails 构造附带详情的状态，附加失败时返回不带详情的状态
+func withDetails(code codes.Code, err error, detail protoadapt.MessageV1) error {
+	st := status.New(code, err.Error())
+	if withDetail, e := st.WithDetails(detail); e == nil {
+		return withDetail.Err()
+	}
+	return st.Err()
+}
@@ -0,0 +1,609 @@
+// 调度服务 gRPC 接口：设备、飞行任务与控制指令，以及 OSD、HMS告警、任务状态的服务端推送
+// 租户身份通过 metadata 传递：x-tenant-id、x-user-token、x-project-uuid，可选 x-org-id、x-request-id
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: dispatch.proto
+
+package dispatchpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	DeviceService_ListDevices_FullMethodName     = "/dronedispatch.api.v1.DeviceService/ListDevices"
+	DeviceService_GetDeviceState_FullMethodName  = "/dronedispatch.api.v1.DeviceService/GetDeviceState"
+	DeviceService_GetDeviceHms_FullMethodName    = "/dronedispatch.api.v1.DeviceService/GetDeviceHms"
+	DeviceService_SendCommand_FullMethodName     = "/dronedispatch.api.v1.DeviceService/SendCommand"
+	DeviceService_StreamOsd_FullMethodName       = "/dronedispatch.api.v1.DeviceService/StreamOsd"
+	DeviceService_StreamHmsEvents_FullMethodName = "/dronedispatch.api.v1.DeviceService/StreamHmsEvents"
+)
+
+// DeviceServiceClient is the client API for DeviceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DeviceService 设备查询、控制指令与遥测推送
+type DeviceServiceClient interface {
+	// ListDevices 项目下的设备列表（司空2）
+	ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*JsonResponse, error)
+	// GetDeviceState 设备遥测状态，由管理该设备的插件提供
+	GetDeviceState(ctx context.Context, in *DeviceRequest, opts ...grpc.CallOption) (*JsonResponse, error)
+	// GetDeviceHms 设备当前的HMS告警（已解码）
+	GetDeviceHms(ctx context.Context, in *GetDeviceHmsRequest, opts ...grpc.CallOption) (*GetDeviceHmsResponse, error)
+	// SendCommand 实时控制指令，例如 return_home、flighttask_pause
+	SendCommand(ctx context.Context, in *SendCommandRequest, opts ...grpc.CallOption) (*JsonResponse, error)
+	// StreamOsd 飞行器OSD推送，主动上报的插件即时推送，其余插件按 interval_ms 轮询
+	StreamOsd(ctx context.Context, in *StreamOsdRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OsdEvent], error)
+	// StreamHmsEvents HMS告警触发与消除推送
+	StreamHmsEvents(ctx context.Context, in *StreamHmsEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HmsEvent], error)
+}
+
+type deviceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDeviceServiceClient(cc grpc.ClientConnInterface) DeviceServiceClient {
+	return &deviceServiceClient{cc}
+}
+
+func (c *deviceServiceClient) ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, DeviceService_ListDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) GetDeviceState(ctx context.Context, in *DeviceRequest, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, DeviceService_GetDeviceState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) GetDeviceHms(ctx context.Context, in *GetDeviceHmsRequest, opts ...grpc.CallOption) (*GetDeviceHmsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDeviceHmsResponse)
+	err := c.cc.Invoke(ctx, DeviceService_GetDeviceHms_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) SendCommand(ctx context.Context, in *SendCommandRequest, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, DeviceService_SendCommand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) StreamOsd(ctx context.Context, in *StreamOsdRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OsdEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DeviceService_ServiceDesc.Streams[0], DeviceService_StreamOsd_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamOsdRequest, OsdEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DeviceService_StreamOsdClient = grpc.ServerStreamingClient[OsdEvent]
+
+func (c *deviceServiceClient) StreamHmsEvents(ctx context.Context, in *StreamHmsEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HmsEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DeviceService_ServiceDesc.Streams[1], DeviceService_StreamHmsEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamHmsEventsRequest, HmsEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DeviceService_StreamHmsEventsClient = grpc.ServerStreamingClient[HmsEvent]
+
+// DeviceServiceServer is the server API for DeviceService service.
+// All implementations must embed UnimplementedDeviceServiceServer
+// for forward compatibility.
+//
+// DeviceService 设备查询、控制指令与遥测推送
+type DeviceServiceServer interface {
+	// ListDevices 项目下的设备列表（司空2）
+	ListDevices(context.Context, *ListDevicesRequest) (*JsonResponse, error)
+	// GetDeviceState 设备遥测状态，由管理该设备的插件提供
+	GetDeviceState(context.Context, *DeviceRequest) (*JsonResponse, error)
+	// GetDeviceHms 设备当前的HMS告警（已解码）
+	GetDeviceHms(context.Context, *GetDeviceHmsRequest) (*GetDeviceHmsResponse, error)
+	// SendCommand 实时控制指令，例如 return_home、flighttask_pause
+	SendCommand(context.Context, *SendCommandRequest) (*JsonResponse, error)
+	// StreamOsd 飞行器OSD推送，主动上报的插件即时推送，其余插件按 interval_ms 轮询
+	StreamOsd(*StreamOsdRequest, grpc.ServerStreamingServer[OsdEvent]) error
+	// StreamHmsEvents HMS告警触发与消除推送
+	StreamHmsEvents(*StreamHmsEventsRequest, grpc.ServerStreamingServer[HmsEvent]) error
+	mustEmbedUnimplementedDeviceServiceServer()
+}
+
+// UnimplementedDeviceServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDeviceServiceServer struct{}
+
+func (UnimplementedDeviceServiceServer) ListDevices(context.Context, *ListDevicesRequest) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDevices not implemented")
+}
+func (UnimplementedDeviceServiceServer) GetDeviceState(context.Context, *DeviceRequest) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeviceState not implemented")
+}
+func (UnimplementedDeviceServiceServer) GetDeviceHms(context.Context, *GetDeviceHmsRequest) (*GetDeviceHmsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeviceHms not implemented")
+}
+func (UnimplementedDeviceServiceServer) SendCommand(context.Context, *SendCommandRequest) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendCommand not implemented")
+}
+func (UnimplementedDeviceServiceServer) StreamOsd(*StreamOsdRequest, grpc.ServerStreamingServer[OsdEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamOsd not implemented")
+}
+func (UnimplementedDeviceServiceServer) StreamHmsEvents(*StreamHmsEventsRequest, grpc.ServerStreamingServer[HmsEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamHmsEvents not implemented")
+}
+func (UnimplementedDeviceServiceServer) mustEmbedUnimplementedDeviceServiceServer() {}
+func (UnimplementedDeviceServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeDeviceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DeviceServiceServer will
+// result in compilation errors.
+type UnsafeDeviceServiceServer interface {
+	mustEmbedUnimplementedDeviceServiceServer()
+}
+
+func RegisterDeviceServiceServer(s grpc.ServiceRegistrar, srv DeviceServiceServer) {
+	// If the following call pancis, it indicates UnimplementedDeviceServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DeviceService_ServiceDesc, srv)
+}
+
+func _DeviceService_ListDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).ListDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_ListDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).ListDevices(ctx, req.(*ListDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_GetDeviceState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).GetDeviceState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_GetDeviceState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).GetDeviceState(ctx, req.(*DeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_GetDeviceHms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeviceHmsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).GetDeviceHms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_GetDeviceHms_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).GetDeviceHms(ctx, req.(*GetDeviceHmsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_SendCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).SendCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_SendCommand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).SendCommand(ctx, req.(*SendCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_StreamOsd_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamOsdRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DeviceServiceServer).StreamOsd(m, &grpc.GenericServerStream[StreamOsdRequest, OsdEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DeviceService_StreamOsdServer = grpc.ServerStreamingServer[OsdEvent]
+
+func _DeviceService_StreamHmsEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamHmsEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DeviceServiceServer).StreamHmsEvents(m, &grpc.GenericServerStream[StreamHmsEventsRequest, HmsEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DeviceService_StreamHmsEventsServer = grpc.ServerStreamingServer[HmsEvent]
+
+// DeviceService_ServiceDesc is the grpc.ServiceDesc for DeviceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DeviceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dronedispatch.api.v1.DeviceService",
+	HandlerType: (*DeviceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDevices",
+			Handler:    _DeviceService_ListDevices_Handler,
+		},
+		{
+			MethodName: "GetDeviceState",
+			Handler:    _DeviceService_GetDeviceState_Handler,
+		},
+		{
+			MethodName: "GetDeviceHms",
+			Handler:    _DeviceService_GetDeviceHms_Handler,
+		},
+		{
+			MethodName: "SendCommand",
+			Handler:    _DeviceService_SendCommand_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamOsd",
+			Handler:       _DeviceService_StreamOsd_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamHmsEvents",
+			Handler:       _DeviceService_StreamHmsEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "dispatch.proto",
+}
+
+const (
+	FlightTaskService_CreateFlightTask_FullMethodName       = "/dronedispatch.api.v1.FlightTaskService/CreateFlightTask"
+	FlightTaskService_ListFlightTasks_FullMethodName        = "/dronedispatch.api.v1.FlightTaskService/ListFlightTasks"
+	FlightTaskService_GetFlightTask_FullMethodName          = "/dronedispatch.api.v1.FlightTaskService/GetFlightTask"
+	FlightTaskService_UpdateFlightTaskStatus_FullMethodName = "/dronedispatch.api.v1.FlightTaskService/UpdateFlightTaskStatus"
+	FlightTaskService_StreamTaskStatus_FullMethodName       = "/dronedispatch.api.v1.FlightTaskService/StreamTaskStatus"
+)
+
+// FlightTaskServiceClient is the client API for FlightTaskService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// FlightTaskService 飞行任务
+type FlightTaskServiceClient interface {
+	// CreateFlightTask 创建飞行任务，电子围栏、飞前检查与天气门限未通过时返回 FAILED_PRECONDITION
+	CreateFlightTask(ctx context.Context, in *CreateFlightTaskRequest, opts ...grpc.CallOption) (*CreateFlightTaskResponse, error)
+	// ListFlightTasks 飞行任务列表（司空2）
+	ListFlightTasks(ctx context.Context, in *ListFlightTasksRequest, opts ...grpc.CallOption) (*JsonResponse, error)
+	// GetFlightTask 飞行任务详情（司空2）
+	GetFlightTask(ctx context.Context, in *FlightTaskRequest, opts ...grpc.CallOption) (*JsonResponse, error)
+	// UpdateFlightTaskStatus 任务挂起与恢复（司空2）
+	UpdateFlightTaskStatus(ctx context.Context, in *UpdateFlightTaskStatusRequest, opts ...grpc.CallOption) (*JsonResponse, error)
+	// StreamTaskStatus 飞行任务状态变化推送
+	StreamTaskStatus(ctx context.Context, in *StreamTaskStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskStatusEvent], error)
+}
+
+type flightTaskServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFlightTaskServiceClient(cc grpc.ClientConnInterface) FlightTaskServiceClient {
+	return &flightTaskServiceClient{cc}
+}
+
+func (c *flightTaskServiceClient) CreateFlightTask(ctx context.Context, in *CreateFlightTaskRequest, opts ...grpc.CallOption) (*CreateFlightTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateFlightTaskResponse)
+	err := c.cc.Invoke(ctx, FlightTaskService_CreateFlightTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flightTaskServiceClient) ListFlightTasks(ctx context.Context, in *ListFlightTasksRequest, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, FlightTaskService_ListFlightTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flightTaskServiceClient) GetFlightTask(ctx context.Context, in *FlightTaskRequest, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, FlightTaskService_GetFlightTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flightTaskServiceClient) UpdateFlightTaskStatus(ctx context.Context, in *UpdateFlightTaskStatusRequest, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, FlightTaskService_UpdateFlightTaskStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flightTaskServiceClient) StreamTaskStatus(ctx context.Context, in *StreamTaskStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskStatusEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FlightTaskService_ServiceDesc.Streams[0], FlightTaskService_StreamTaskStatus_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamTaskStatusRequest, TaskStatusEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FlightTaskService_StreamTaskStatusClient = grpc.ServerStreamingClient[TaskStatusEvent]
+
+// FlightTaskServiceServer is the server API for FlightTaskService service.
+// All implementations must embed UnimplementedFlightTaskServiceServer
+// for forward compatibility.
+//
+// FlightTaskService 飞行任务
+type FlightTaskServiceServer interface {
+	// CreateFlightTask 创建飞行任务，电子围栏、飞前检查与天气门限未通过时返回 FAILED_PRECONDITION
+	CreateFlightTask(context.Context, *CreateFlightTaskRequest) (*CreateFlightTaskResponse, error)
+	// ListFlightTasks 飞行任务列表（司空2）
+	ListFlightTasks(context.Context, *ListFlightTasksRequest) (*JsonResponse, error)
+	// GetFlightTask 飞行任务详情（司空2）
+	GetFlightTask(context.Context, *FlightTaskRequest) (*JsonResponse, error)
+	// UpdateFlightTaskStatus 任务挂起与恢复（司空2）
+	UpdateFlightTaskStatus(context.Context, *UpdateFlightTaskStatusRequest) (*JsonResponse, error)
+	// StreamTaskStatus 飞行任务状态变化推送
+	StreamTaskStatus(*StreamTaskStatusRequest, grpc.ServerStreamingServer[TaskStatusEvent]) error
+	mustEmbedUnimplementedFlightTaskServiceServer()
+}
+
+// UnimplementedFlightTaskServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFlightTaskServiceServer struct{}
+
+func (UnimplementedFlightTaskServiceServer) CreateFlightTask(context.Context, *CreateFlightTaskRequest) (*CreateFlightTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFlightTask not implemented")
+}
+func (UnimplementedFlightTaskServiceServer) ListFlightTasks(context.Context, *ListFlightTasksRequest) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFlightTasks not implemented")
+}
+func (UnimplementedFlightTaskServiceServer) GetFlightTask(context.Context, *FlightTaskRequest) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFlightTask not implemented")
+}
+func (UnimplementedFlightTaskServiceServer) UpdateFlightTaskStatus(context.Context, *UpdateFlightTaskStatusRequest) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateFlightTaskStatus not implemented")
+}
+func (UnimplementedFlightTaskServiceServer) StreamTaskStatus(*StreamTaskStatusRequest, grpc.ServerStreamingServer[TaskStatusEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTaskStatus not implemented")
+}
+func (UnimplementedFlightTaskServiceServer) mustEmbedUnimplementedFlightTaskServiceServer() {}
+func (UnimplementedFlightTaskServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeFlightTaskServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FlightTaskServiceServer will
+// result in compilation errors.
+type UnsafeFlightTaskServiceServer interface {
+	mustEmbedUnimplementedFlightTaskServiceServer()
+}
+
+func RegisterFlightTaskServiceServer(s grpc.ServiceRegistrar, srv FlightTaskServiceServer) {
+	// If the following call pancis, it indicates UnimplementedFlightTaskServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&FlightTaskService_ServiceDesc, srv)
+}
+
+func _FlightTaskService_CreateFlightTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFlightTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlightTaskServiceServer).CreateFlightTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlightTaskService_CreateFlightTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlightTaskServiceServer).CreateFlightTask(ctx, req.(*CreateFlightTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FlightTaskService_ListFlightTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFlightTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlightTaskServiceServer).ListFlightTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlightTaskService_ListFlightTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlightTaskServiceServer).ListFlightTasks(ctx, req.(*ListFlightTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FlightTaskService_GetFlightTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FlightTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlightTaskServiceServer).GetFlightTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlightTaskService_GetFlightTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlightTaskServiceServer).GetFlightTask(ctx, req.(*FlightTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FlightTaskService_UpdateFlightTaskStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateFlightTaskStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlightTaskServiceServer).UpdateFlightTaskStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlightTaskService_UpdateFlightTaskStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlightTaskServiceServer).UpdateFlightTaskStatus(ctx, req.(*UpdateFlightTaskStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FlightTaskService_StreamTaskStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTaskStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FlightTaskServiceServer).StreamTaskStatus(m, &grpc.GenericServerStream[StreamTaskStatusRequest, TaskStatusEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FlightTaskService_StreamTaskStatusServer = grpc.ServerStreamingServer[TaskStatusEvent]
+
+// FlightTaskService_ServiceDesc is the grpc.ServiceDesc for FlightTaskService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FlightTaskService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dronedispatch.api.v1.FlightTaskService",
+	HandlerType: (*FlightTaskServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateFlightTask",
+			Handler:    _FlightTaskService_CreateFlightTask_Handler,
+		},
+		{
+			MethodName: "ListFlightTasks",
+			Handler:    _FlightTaskService_ListFlightTasks_Handler,
+		},
+		{
+			MethodName: "GetFlightTask",
+			Handler:    _FlightTaskService_GetFlightTask_Handler,
+		},
+		{
+			MethodName: "UpdateFlightTaskStatus",
+			Handler:    _FlightTaskService_UpdateFlightTaskStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTaskStatus",
+			Handler:       _FlightTaskService_StreamTaskStatus_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "dispatch.proto",
+}
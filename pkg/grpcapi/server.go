@@ -0,0 +1,259 @@
+// Package grpcapi 以 gRPC 对外提供设备、飞行任务与控制指令接口，并以服务端流推送 OSD、HMS告警与任务状态
+// 租户身份取自 metadata（与 REST 请求头同名的小写键）中登记的用户令牌，权限由服务端按租户授予；协议定义见 proto/dispatch.proto。
+// 服务不绑定监听方式，测试时可通过 bufconn 在进程内调用。
+package grpcapi
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	dispatchpb "gitee.com/jamespi/drone_dispatch/pkg/grpcapi/proto"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/plugin"
+	"gitee.com/jamespi/drone_dispatch/service"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadata 键，与 REST 请求头同名（gRPC metadata 键为小写）
+var (
+	MetadataTenantID    = strings.ToLower(tenant.HeaderTenantID)
+	MetadataUserToken   = strings.ToLower(tenant.HeaderUserToken)
+	MetadataProjectUUID = strings.ToLower(tenant.HeaderProjectUUID)
+	MetadataOrgID       = strings.ToLower(tenant.HeaderOrgID)
+	MetadataRequestID   = strings.ToLower(tenant.HeaderRequestID)
+)
+
+// methodPermissions 各方法要求的权限
+var methodPermissions = map[string]string{
+	dispatchpb.DeviceService_ListDevices_FullMethodName:                tenant.PermFH2Read,
+	dispatchpb.DeviceService_GetDeviceState_FullMethodName:             tenant.PermFH2Read,
+	dispatchpb.DeviceService_GetDeviceHms_FullMethodName:               tenant.PermFH2Read,
+	dispatchpb.DeviceService_SendCommand_FullMethodName:                tenant.PermFH2Write,
+	dispatchpb.DeviceService_StreamOsd_FullMethodName:                  tenant.PermFH2Read,
+	dispatchpb.DeviceService_StreamHmsEvents_FullMethodName:            tenant.PermFH2Read,
+	dispatchpb.FlightTaskService_CreateFlightTask_FullMethodName:       tenant.PermFH2Write,
+	dispatchpb.FlightTaskService_ListFlightTasks_FullMethodName:        tenant.PermFH2Read,
+	dispatchpb.FlightTaskService_GetFlightTask_FullMethodName:          tenant.PermFH2Read,
+	dispatchpb.FlightTaskService_UpdateFlightTaskStatus_FullMethodName: tenant.PermFH2Write,
+	dispatchpb.FlightTaskService_StreamTaskStatus_FullMethodName:       tenant.PermFH2Read,
+}
+
+// Options 服务选项
+type Options struct {
+	// DefaultPermissions 未单独配置的租户具备的权限
+	DefaultPermissions []string
+	// Grants 按租户ID授予的权限，覆盖 DefaultPermissions
+	Grants map[int64][]string
+	// Tokens 登记的用户令牌，调用身份取自令牌所属的租户
+	Tokens tenant.Tokens
+	// FH2 获取司空2适配器，为nil时从插件注册中心获取
+	FH2 func(ctx context.Context) (service.FH2DroneAdapter, error)
+	// PollInterval 轮询型插件（如司空2）推送 HMS告警的查询间隔，默认30秒
+	PollInterval time.Duration
+	// Logger 访问日志，为nil时使用标准库 log
+	Logger *log.Logger
+}
+
+// OptionsFromConfig 按配置文件 Server 段生成服务选项，与 REST 服务共用租户权限
+func OptionsFromConfig(cfg *config.Server) Options {
+	opts := Options{Grants: make(map[int64][]string), Tokens: make(tenant.Tokens)}
+	if cfg == nil {
+		return opts
+	}
+	opts.DefaultPermissions = cfg.DefaultPermissions
+	for _, grant := range cfg.Tenants {
+		if len(grant.Permissions) > 0 {
+			opts.Grants[grant.TenantID] = grant.Permissions
+		}
+		for _, digest := range grant.TokenSHA256 {
+			opts.Tokens[strings.ToLower(strings.TrimSpace(digest))] = grant.TenantID
+		}
+	}
+	return opts
+}
+
+// Server gRPC 服务
+type Server struct {
+	mu   sync.RWMutex
+	opts Options
+}
+
+// NewServer 创建 gRPC 服务
+func NewServer(opts Options) *Server {
+	return &Server{opts: opts}
+}
+
+// SetOptions 替换服务选项（配置重新加载时更新权限）
+func (s *Server) SetOptions(opts Options) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opts = opts
+}
+
+func (s *Server) options() Options {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.opts
+}
+
+// Register 在 gRPC 服务上注册设备与飞行任务服务，调用方需同时使用 ServerOptions 中的拦截器
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	dispatchpb.RegisterDeviceServiceServer(registrar, &deviceService{server: s})
+	dispatchpb.RegisterFlightTaskServiceServer(registrar, &flightTaskService{server: s})
+}
+
+// ServerOptions 租户鉴权、请求ID、panic 恢复与访问日志拦截器
+func (s *Server) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	}
+}
+
+// NewGRPCServer 创建已注册全部服务与拦截器的 gRPC 服务
+func (s *Server) NewGRPCServer(extra ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(append(s.ServerOptions(), extra...)...)
+	s.Register(server)
+	return server
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	start := time.Now()
+	ctx, requestID, err := s.authorize(ctx, info.FullMethod)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataRequestID, requestID))
+	defer func() {
+		if v := recover(); v != nil {
+			s.logf("调用 %s panic: %v\n%s", info.FullMethod, v, debug.Stack())
+			err = status.Error(codes.Internal, "服务内部错误")
+		}
+		s.logf("%s %s %s request_id=%s", info.FullMethod, status.Code(err), time.Since(start).Round(time.Millisecond), requestID)
+	}()
+	if err != nil {
+		return nil, err
+	}
+	resp, err = handler(ctx, req)
+	return resp, toStatus(err)
+}
+
+func (s *Server) streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	start := time.Now()
+	ctx, requestID, err := s.authorize(stream.Context(), info.FullMethod)
+	_ = stream.SetHeader(metadata.Pairs(MetadataRequestID, requestID))
+	defer func() {
+		if v := recover(); v != nil {
+			s.logf("调用 %s panic: %v\n%s", info.FullMethod, v, debug.Stack())
+			err = status.Error(codes.Internal, "服务内部错误")
+		}
+		s.logf("%s %s %s request_id=%s", info.FullMethod, status.Code(err), time.Since(start).Round(time.Millisecond), requestID)
+	}()
+	if err != nil {
+		return err
+	}
+	return toStatus(handler(srv, &contextStream{ServerStream: stream, ctx: ctx}))
+}
+
+// authorize 校验 metadata 中的用户令牌、以令牌所属的租户作为身份并检查权限，写入上下文，返回请求ID
+func (s *Server) authorize(ctx context.Context, fullMethod string) (context.Context, string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	requestID := strings.TrimSpace(first(md, MetadataRequestID))
+	if requestID == "" || len(requestID) > 128 {
+		requestID = uuid.New().String()
+	}
+	ctx = tenant.WithRequestID(ctx, requestID)
+
+	opts := s.options()
+	info, err := TenantFromMetadata(md, opts.Tokens)
+	if err != nil {
+		return ctx, requestID, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if perms, ok := opts.Grants[info.TenantId]; ok {
+		info.GrantPermissions(perms...)
+	} else {
+		info.GrantPermissions(opts.DefaultPermissions...)
+	}
+	perm, ok := methodPermissions[fullMethod]
+	if !ok {
+		return ctx, requestID, status.Errorf(codes.Unimplemented, "未知方法 %s", fullMethod)
+	}
+	if !info.HasPermission(perm) {
+		return ctx, requestID, status.Errorf(codes.PermissionDenied, "租户 %d 缺少权限 %s", info.TenantId, perm)
+	}
+	return tenant.WithTenant(ctx, info), requestID, nil
+}
+
+// TenantFromMetadata 校验 gRPC metadata 中的用户令牌并确定租户，规则与 REST 请求头一致（见 tenant.Tokens.Verify）
+func TenantFromMetadata(md metadata.MD, tokens tenant.Tokens) (*tenant.TenantInfo, error) {
+	header := make(http.Header)
+	for _, key := range []string{tenant.HeaderTenantID, tenant.HeaderUserToken, tenant.HeaderProjectUUID, tenant.HeaderOrgID} {
+		if value := first(md, strings.ToLower(key)); value != "" {
+			header.Set(key, value)
+		}
+	}
+	return tokens.Verify(header)
+}
+
+// WithTenantMetadata 客户端调用时在上下文中附加租户 metadata
+func WithTenantMetadata(ctx context.Context, info *tenant.TenantInfo) context.Context {
+	pairs := []string{
+		MetadataTenantID, strconv.FormatInt(info.TenantId, 10),
+		MetadataUserToken, info.UserToken,
+	}
+	if info.ProjectUUID != "" {
+		pairs = append(pairs, MetadataProjectUUID, info.ProjectUUID)
+	}
+	if info.OrgID != "" {
+		pairs = append(pairs, MetadataOrgID, info.OrgID)
+	}
+	if requestID := tenant.GetRequestIDFromContext(ctx); requestID != "" {
+		pairs = append(pairs, MetadataRequestID, requestID)
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if logger := s.options().Logger; logger != nil {
+		logger.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// fh2 获取司空2适配器
+func (s *Server) fh2(ctx context.Context) (service.FH2DroneAdapter, error) {
+	if get := s.options().FH2; get != nil {
+		return get(ctx)
+	}
+	adapter, ok := plugin.GetWithContext[service.FH2DroneAdapter](ctx, plugin.FH2Plugin)
+	if !ok {
+		return nil, status.Errorf(codes.Unavailable, "插件 %s 未启用", plugin.FH2Plugin)
+	}
+	return adapter, nil
+}
+
+// contextStream 以鉴权后的上下文替换流的上下文
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
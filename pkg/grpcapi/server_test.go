@@ -0,0 +1,259 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geofence"
+	dispatchpb "gitee.com/jamespi/drone_dispatch/pkg/grpcapi/proto"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/plugin"
+	"gitee.com/jamespi/drone_dispatch/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	testDock       = "7CTXN4A00B0001H"
+	testToken      = "tenant-1-token"
+	testReadToken  = "tenant-2-token"
+	testWriteToken = "tenant-3-token"
+)
+
+// fakeDock 测试插件：只为租户1管理 testDock，记录各次调用所在的租户
+type fakeDock struct {
+	mu        sync.Mutex
+	tenants   []int64
+	overrides []geofence.Override
+}
+
+func (f *fakeDock) record(ctx context.Context) {
+	info, err := tenant.GetTenantFromContext(ctx)
+	if err != nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tenants = append(f.tenants, info.TenantId)
+}
+
+func (f *fakeDock) seen() []int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int64(nil), f.tenants...)
+}
+
+func (f *fakeDock) HasDevice(ctx context.Context, deviceSn string) (bool, error) {
+	info, err := tenant.GetTenantFromContext(ctx)
+	return err == nil && info.TenantId == 1 && deviceSn == testDock, nil
+}
+
+func (f *fakeDock) GetDeviceState(ctx context.Context, deviceSn string) (string, error) {
+	f.record(ctx)
+	return fmt.Sprintf(`{"sn":%q,"osd":{"latitude":22.5431,"longitude":113.9344,"height":50,"battery":{"capacity_percent":88}}}`, deviceSn), nil
+}
+
+func (f *fakeDock) GetDeviceHms(ctx context.Context, deviceSnList string) (string, error) {
+	f.record(ctx)
+	return fmt.Sprintf(`{"list":[{"device_sn":%q,"level":2,"module":3,"code":"0x16100083","device_type":"0-91-0"}]}`, deviceSnList), nil
+}
+
+func (f *fakeDock) UpdateDeviceCommand(ctx context.Context, deviceSn string, payLoad io.Reader) (string, error) {
+	f.record(ctx)
+	body, err := io.ReadAll(payLoad)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`{"code":0,"data":%s}`, body), nil
+}
+
+func (f *fakeDock) CreateFlightTask(ctx context.Context, payLoad io.Reader) (string, error) {
+	f.record(ctx)
+	if override, ok := geofence.OverrideFrom(ctx); ok {
+		f.mu.Lock()
+		f.overrides = append(f.overrides, override)
+		f.mu.Unlock()
+	}
+	return `{"code":0,"data":{"task_uuid":"6d88fbe5-a399-485a-86ba-7bbdbb99edec"}}`, nil
+}
+
+// newTestClient 通过 bufconn 在进程内启动服务，租户1具备全部权限，租户2只能读取，租户3可写但不能确认放行限飞区
+func newTestClient(t *testing.T) (*fakeDock, *grpc.ClientConn) {
+	t.Helper()
+	const pluginType plugin.PluginType = "test_grpcapi_dock"
+	dock := &fakeDock{}
+	plugin.RegisterPluginWithScope(pluginType, reflect.TypeOf((*service.DeviceLocator)(nil)).Elem(), plugin.ScopeSingleton, func() interface{} { return dock })
+	t.Cleanup(func() { plugin.Unload(pluginType) })
+	if err := plugin.Enable(pluginType); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(Options{
+		DefaultPermissions: []string{tenant.PermFH2Read},
+		Grants:             map[int64][]string{1: {"*"}, 3: {tenant.PermFH2Write}},
+		Tokens:             tenant.Tokens{tenant.TokenDigest(testToken): 1, tenant.TokenDigest(testReadToken): 2, tenant.TokenDigest(testWriteToken): 3},
+		FH2: func(ctx context.Context) (service.FH2DroneAdapter, error) {
+			return nil, status.Error(codes.Unavailable, "测试中未提供司空2适配器")
+		},
+		PollInterval: 20 * time.Millisecond,
+		Logger:       log.New(io.Discard, "", 0),
+	})
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := server.NewGRPCServer()
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return dock, conn
+}
+
+func tenantContext(t *testing.T, tenantID int64, token string) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return WithTenantMetadata(ctx, tenant.NewTenantInfo(tenantID, token, ""))
+}
+
+func requireCode(t *testing.T, name string, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Errorf("%s: 状态码 %s，应为 %s（%v）", name, got, want, err)
+	}
+}
+
+// TestAuthorize 租户取自登记的令牌，冒用租户ID、未登记的令牌与缺少权限的调用被拒绝
+func TestAuthorize(t *testing.T) {
+	dock, conn := newTestClient(t)
+	devices := dispatchpb.NewDeviceServiceClient(conn)
+	req := &dispatchpb.DeviceRequest{Sn: testDock}
+
+	_, err := devices.GetDeviceState(tenantContext(t, 1, testToken), req)
+	requireCode(t, "租户1", err, codes.OK)
+	if seen := dock.seen(); len(seen) != 1 || seen[0] != 1 {
+		t.Errorf("插件收到的租户为 %v，应为 [1]", seen)
+	}
+
+	_, err = devices.GetDeviceState(tenantContext(t, 1, testReadToken), req)
+	requireCode(t, "以租户2的令牌冒用租户1", err, codes.Unauthenticated)
+	_, err = devices.GetDeviceState(tenantContext(t, 1, "unknown"), req)
+	requireCode(t, "未登记的令牌", err, codes.Unauthenticated)
+	_, err = devices.GetDeviceState(context.Background(), req)
+	requireCode(t, "缺少 metadata", err, codes.Unauthenticated)
+
+	_, err = devices.SendCommand(tenantContext(t, 2, testReadToken), &dispatchpb.SendCommandRequest{Sn: testDock, Command: "return_home"})
+	requireCode(t, "租户2缺少写权限", err, codes.PermissionDenied)
+	_, err = devices.GetDeviceState(tenantContext(t, 2, testReadToken), req)
+	requireCode(t, "其他租户的设备", err, codes.NotFound)
+	if len(dock.seen()) != 1 {
+		t.Errorf("被拒绝的调用不应到达插件: %v", dock.seen())
+	}
+}
+
+// TestOverridePermission 携带限飞区确认原因需具备 geofence:override 权限，插件收到的确认包含操作人与原因
+func TestOverridePermission(t *testing.T) {
+	dock, conn := newTestClient(t)
+	tasks := dispatchpb.NewFlightTaskServiceClient(conn)
+	task := &dispatchpb.FlightTask{Name: "测试", WaylineUuid: "6d88fbe5-a399-485a-86ba-7bbdbb99edec", Sn: testDock, TaskType: "immediate"}
+
+	_, err := tasks.CreateFlightTask(tenantContext(t, 3, testWriteToken), &dispatchpb.CreateFlightTaskRequest{Task: task, OverrideReason: "已报备"})
+	requireCode(t, "租户3缺少 geofence:override", err, codes.PermissionDenied)
+	if len(dock.seen()) != 0 {
+		t.Fatalf("被拒绝的确认不应到达插件: %v", dock.seen())
+	}
+
+	_, err = tasks.CreateFlightTask(tenantContext(t, 1, testToken), &dispatchpb.CreateFlightTaskRequest{Task: task, OverrideReason: "已报备"})
+	requireCode(t, "租户1确认放行", err, codes.OK)
+	want := geofence.Override{Operator: "租户 1 令牌 " + tenant.TokenDigest(testToken)[:12], Reason: "已报备"}
+	dock.mu.Lock()
+	overrides := append([]geofence.Override(nil), dock.overrides...)
+	dock.mu.Unlock()
+	if len(overrides) != 1 || overrides[0] != want {
+		t.Errorf("插件收到的确认为 %+v，应为 %+v", overrides, want)
+	}
+}
+
+// TestUnary 一元调用按设备选择插件并转换响应
+func TestUnary(t *testing.T) {
+	_, conn := newTestClient(t)
+	ctx := tenantContext(t, 1, testToken)
+	devices := dispatchpb.NewDeviceServiceClient(conn)
+	tasks := dispatchpb.NewFlightTaskServiceClient(conn)
+
+	state, err := devices.GetDeviceState(ctx, &dispatchpb.DeviceRequest{Sn: testDock})
+	if err != nil || !strings.Contains(state.GetData(), "22.5431") {
+		t.Errorf("GetDeviceState = %v, %v", state, err)
+	}
+	alarms, err := devices.GetDeviceHms(ctx, &dispatchpb.GetDeviceHmsRequest{Sn: []string{testDock}})
+	if err != nil || len(alarms.GetAlarms()) != 1 || alarms.GetAlarms()[0].GetCode() != "0x16100083" {
+		t.Errorf("GetDeviceHms = %v, %v", alarms, err)
+	}
+	command, err := devices.SendCommand(ctx, &dispatchpb.SendCommandRequest{Sn: testDock, Command: "return_home"})
+	if err != nil || command.GetData() != `{"device_command":"return_home"}` {
+		t.Errorf("SendCommand = %v, %v，响应应只保留 data", command, err)
+	}
+	created, err := tasks.CreateFlightTask(ctx, &dispatchpb.CreateFlightTaskRequest{Task: &dispatchpb.FlightTask{Name: "测试", WaylineUuid: "6d88fbe5-a399-485a-86ba-7bbdbb99edec", Sn: testDock, TaskType: "immediate"}})
+	if err != nil || created.GetTaskUuid() == "" {
+		t.Errorf("CreateFlightTask = %v, %v", created, err)
+	}
+
+	_, err = devices.GetDeviceState(ctx, &dispatchpb.DeviceRequest{Sn: "bad sn"})
+	requireCode(t, "设备序列号无效", err, codes.InvalidArgument)
+	_, err = devices.GetDeviceHms(ctx, &dispatchpb.GetDeviceHmsRequest{})
+	requireCode(t, "未指定设备", err, codes.InvalidArgument)
+	_, err = devices.ListDevices(ctx, &dispatchpb.ListDevicesRequest{})
+	requireCode(t, "司空2不可用", err, codes.Unavailable)
+}
+
+// TestStreams 轮询型插件的 OSD 与 HMS 告警推送
+func TestStreams(t *testing.T) {
+	_, conn := newTestClient(t)
+	ctx := tenantContext(t, 1, testToken)
+	devices := dispatchpb.NewDeviceServiceClient(conn)
+
+	osd, err := devices.StreamOsd(ctx, &dispatchpb.StreamOsdRequest{Sn: []string{testDock}, IntervalMs: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := osd.Recv()
+	if err != nil {
+		t.Fatalf("接收OSD失败: %v", err)
+	}
+	if event.GetSn() != testDock || event.GetBattery() != 88 {
+		t.Errorf("OSD事件 = %v", event)
+	}
+
+	alarms, err := devices.StreamHmsEvents(ctx, &dispatchpb.StreamHmsEventsRequest{Sn: []string{testDock}, Lang: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alarm, err := alarms.Recv()
+	if err != nil {
+		t.Fatalf("接收HMS告警失败: %v", err)
+	}
+	if alarm.GetType() != "raise" || alarm.GetSn() != testDock || alarm.GetAlarm().GetCode() != "0x16100083" {
+		t.Errorf("HMS事件 = %v", alarm)
+	}
+
+	other, err := devices.StreamOsd(tenantContext(t, 2, testReadToken), &dispatchpb.StreamOsdRequest{Sn: []string{testDock}})
+	if err == nil {
+		_, err = other.Recv()
+	}
+	requireCode(t, "订阅其他租户的设备", err, codes.NotFound)
+}
@@ -0,0 +1,207 @@
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geofence"
+	dispatchpb "gitee.com/jamespi/drone_dispatch/pkg/grpcapi/proto"
+	"gitee.com/jamespi/drone_dispatch/pkg/telemetry"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+	"gitee.com/jamespi/drone_dispatch/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flightTaskService 实现 FlightTaskService
+type flightTaskService struct {
+	dispatchpb.UnimplementedFlightTaskServiceServer
+	server *Server
+}
+
+// CreateFlightTask 创建飞行任务，按任务的机场序列号选择插件
+func (f *flightTaskService) CreateFlightTask(ctx context.Context, req *dispatchpb.CreateFlightTaskRequest) (*dispatchpb.CreateFlightTaskResponse, error) {
+	task := req.GetTask()
+	if task == nil {
+		return nil, status.Error(codes.InvalidArgument, "缺少任务参数 task")
+	}
+	body, err := flightTaskBody(task)
+	if err != nil {
+		return nil, err
+	}
+	if reason := strings.TrimSpace(req.GetOverrideReason()); reason != "" {
+		info, err := tenant.GetTenantFromContext(ctx)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if !info.HasPermission(tenant.PermGeofenceOverride) {
+			return nil, status.Errorf(codes.PermissionDenied, "租户 %d 缺少权限 %s，不能确认放行限飞区", info.TenantId, tenant.PermGeofenceOverride)
+		}
+		ctx = geofence.WithOverride(ctx, info.Operator(), reason)
+	}
+	creator, err := selectFor[service.TaskCreator](ctx, task.GetSn())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := creator.CreateFlightTask(ctx, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	data := unwrapData(resp)
+	var created struct {
+		TaskUUID string `json:"task_uuid"`
+	}
+	_ = json.Unmarshal(data, &created)
+	return &dispatchpb.CreateFlightTaskResponse{TaskUuid: created.TaskUUID, Data: string(data)}, nil
+}
+
+// flightTaskBody 任务参数转换为司空2创建飞行任务请求体，零值字段省略
+func flightTaskBody(task *dispatchpb.FlightTask) ([]byte, error) {
+	body := map[string]interface{}{
+		"name":         task.GetName(),
+		"wayline_uuid": task.GetWaylineUuid(),
+		"sn":           task.GetSn(),
+		"rth_altitude": task.GetRthAltitude(),
+		"task_type":    task.GetTaskType(),
+	}
+	optional := map[string]string{
+		"rth_mode":                        task.GetRthMode(),
+		"wayline_precision_type":          task.GetWaylinePrecisionType(),
+		"out_of_control_action_in_flight": task.GetOutOfControlActionInFlight(),
+		"resumable_status":                task.GetResumableStatus(),
+		"time_zone":                       task.GetTimeZone(),
+		"repeat_type":                     task.GetRepeatType(),
+	}
+	for key, value := range optional {
+		if value != "" {
+			body[key] = value
+		}
+	}
+	numbers := map[string]int64{
+		"begin_at":                       task.GetBeginAt(),
+		"end_at":                         task.GetEndAt(),
+		"min_battery_capacity":           int64(task.GetMinBatteryCapacity()),
+		"min_storage_capacity":           int64(task.GetMinStorageCapacity()),
+		"wayline_execute_interval_hours": int64(task.GetWaylineExecuteIntervalHours()),
+	}
+	for key, value := range numbers {
+		if value != 0 {
+			body[key] = value
+		}
+	}
+	if option := task.GetRepeatOption(); option != "" {
+		if !json.Valid([]byte(option)) {
+			return nil, status.Error(codes.InvalidArgument, "repeat_option 不是合法的JSON")
+		}
+		body["repeat_option"] = json.RawMessage(option)
+	}
+	return json.Marshal(body)
+}
+
+// ListFlightTasks 飞行任务列表
+func (f *flightTaskService) ListFlightTasks(ctx context.Context, req *dispatchpb.ListFlightTasksRequest) (*dispatchpb.JsonResponse, error) {
+	fh2, err := f.server.fh2(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResponse(fh2.GetFlightTask(ctx, req.GetSn(), req.GetName(), int(req.GetBeginAt()), int(req.GetEndAt()), req.GetTaskType(), req.GetStatus()))
+}
+
+// GetFlightTask 飞行任务详情
+func (f *flightTaskService) GetFlightTask(ctx context.Context, req *dispatchpb.FlightTaskRequest) (*dispatchpb.JsonResponse, error) {
+	if err := validateTaskUUID(req.GetTaskUuid()); err != nil {
+		return nil, err
+	}
+	fh2, err := f.server.fh2(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResponse(fh2.GetFlightTaskInfo(ctx, req.GetTaskUuid()))
+}
+
+// UpdateFlightTaskStatus 任务挂起与恢复
+func (f *flightTaskService) UpdateFlightTaskStatus(ctx context.Context, req *dispatchpb.UpdateFlightTaskStatusRequest) (*dispatchpb.JsonResponse, error) {
+	if err := validateTaskUUID(req.GetTaskUuid()); err != nil {
+		return nil, err
+	}
+	if req.GetStatus() == "" {
+		return nil, status.Error(codes.InvalidArgument, "缺少目标状态 status")
+	}
+	fh2, err := f.server.fh2(ctx)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]string{"status": req.GetStatus()})
+	if err != nil {
+		return nil, err
+	}
+	return jsonResponse(fh2.UpdateFlightTaskStatus(ctx, req.GetTaskUuid(), bytes.NewReader(body)))
+}
+
+// StreamTaskStatus 飞行任务状态变化推送，按设备（任务所属机场）与可选的任务UUID过滤
+func (f *flightTaskService) StreamTaskStatus(req *dispatchpb.StreamTaskStatusRequest, stream grpc.ServerStreamingServer[dispatchpb.TaskStatusEvent]) error {
+	ctx := stream.Context()
+	if err := requireSN(req.GetSn()); err != nil {
+		return err
+	}
+	devices := make(map[string]bool)
+	for _, sn := range req.GetSn() {
+		if _, err := selectFor[service.DeviceLocator](ctx, sn); err != nil {
+			return err
+		}
+		devices[sn] = true
+	}
+	tasks := make(map[string]bool)
+	for _, id := range req.GetTaskUuid() {
+		if err := validateTaskUUID(id); err != nil {
+			return err
+		}
+		tasks[id] = true
+	}
+	events := make(chan telemetry.TaskEvent, streamBuffer)
+	unsubscribe := telemetry.OnTaskStatus(func(event telemetry.TaskEvent) {
+		if !devices[event.DeviceSN] || (len(tasks) > 0 && !tasks[event.TaskUUID]) {
+			return
+		}
+		select {
+		case events <- event:
+		default:
+		}
+	})
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-events:
+			if err := stream.Send(taskStatusEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func validateTaskUUID(id string) error {
+	if err := validator.GetValidator().ValidateUUID(id); err != nil {
+		return status.Errorf(codes.InvalidArgument, "任务UUID无效: %v", err)
+	}
+	return nil
+}
+
+func taskStatusEvent(event telemetry.TaskEvent) *dispatchpb.TaskStatusEvent {
+	return &dispatchpb.TaskStatusEvent{
+		TaskUuid:   event.TaskUUID,
+		Sn:         event.DeviceSN,
+		Name:       event.Name,
+		TaskType:   event.TaskType,
+		Status:     event.Status,
+		PrevStatus: event.PrevStatus,
+		Progress:   int32(event.Progress),
+		Timestamp:  event.At.UnixMilli(),
+	}
+}
@@ -0,0 +1,136 @@
+// Package hms 设备健康管理系统（HMS）告警解码
+// 将设备上报的原始告警码解码为严重程度、模块、中英文告警信息与处理建议，并在多次轮询间对告警去重，
+// 按设备产生告警触发与消除事件。
+package hms
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Lang 告警文案语言
+type Lang string
+
+const (
+	LangZH Lang = "zh"
+	LangEN Lang = "en"
+)
+
+// ParseLang 解析语言，为空时为中文
+func ParseLang(name string) (Lang, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "zh", "zh-cn", "zh_cn", "cn":
+		return LangZH, nil
+	case "en", "en-us", "en_us":
+		return LangEN, nil
+	}
+	return "", fmt.Errorf("不支持的语言: %s", name)
+}
+
+// Entry 告警码文案，格式兼容大疆发布的 hms.json（仅 zh、en 字段），处理建议为可选扩展
+type Entry struct {
+	ZH       string `json:"zh"`
+	EN       string `json:"en"`
+	ActionZH string `json:"action_zh,omitempty"`
+	ActionEN string `json:"action_en,omitempty"`
+}
+
+// text 按语言选择文案，缺少该语言时回退到另一种语言
+func (e Entry) text(lang Lang) (message, action string) {
+	if lang == LangEN {
+		return firstNonEmpty(e.EN, e.ZH), firstNonEmpty(e.ActionEN, e.ActionZH)
+	}
+	return firstNonEmpty(e.ZH, e.EN), firstNonEmpty(e.ActionZH, e.ActionEN)
+}
+
+//go:embed catalog.json
+var builtinCatalog []byte
+
+// Catalog 告警码目录，键为 hms.json 中的文案键，例如 fpv_tip_0x16100083、dock_tip_0x1b010001_in_the_sky
+type Catalog struct {
+	mu      sync.RWMutex
+	entries map[string]Entry
+}
+
+// NewCatalog 创建包含内置常见告警码的目录
+// 内置目录只收录机场2作业中的常见告警，完整目录可通过 LoadFile 加载大疆发布的 hms.json
+func NewCatalog() *Catalog {
+	c := &Catalog{entries: make(map[string]Entry)}
+	if err := c.Load(builtinCatalog); err != nil {
+		panic(fmt.Sprintf("内置HMS目录无效: %v", err))
+	}
+	return c
+}
+
+// Load 合并 JSON 格式的告警码目录，同名键覆盖已有文案；只覆盖非空字段，便于在官方目录之上补充处理建议
+func (c *Catalog) Load(data []byte) error {
+	var entries map[string]Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("解析HMS目录失败: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range entries {
+		key = strings.ToLower(key)
+		existing := c.entries[key]
+		existing.ZH = firstNonEmpty(entry.ZH, existing.ZH)
+		existing.EN = firstNonEmpty(entry.EN, existing.EN)
+		existing.ActionZH = firstNonEmpty(entry.ActionZH, existing.ActionZH)
+		existing.ActionEN = firstNonEmpty(entry.ActionEN, existing.ActionEN)
+		c.entries[key] = existing
+	}
+	return nil
+}
+
+// LoadFile 从文件合并告警码目录
+func (c *Catalog) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取HMS目录失败: %w", err)
+	}
+	return c.Load(data)
+}
+
+// Len 目录中的文案数量
+func (c *Catalog) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// lookup 查找告警文案：飞行中产生的告警优先使用 _in_the_sky 文案，机场与飞行器前缀按设备类型决定先后
+func (c *Catalog) lookup(alarm Alarm) (Entry, bool) {
+	code := strings.ToLower(alarm.Code)
+	prefixes := []string{"fpv_tip_", "dock_tip_"}
+	if alarm.isDock() {
+		prefixes = []string{"dock_tip_", "fpv_tip_"}
+	}
+	var keys []string
+	for _, prefix := range prefixes {
+		if alarm.InTheSky == 1 {
+			keys = append(keys, prefix+code+"_in_the_sky")
+		}
+		keys = append(keys, prefix+code)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, key := range keys {
+		if entry, ok := c.entries[key]; ok {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package hms
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/cloudapi"
+)
+
+// Alarm 设备上报的原始告警，兼容司空2 OpenAPI 告警列表与上云API hms 事件
+type Alarm struct {
+	HmsID      string           `json:"hms_id,omitempty"`
+	DeviceSN   string           `json:"device_sn"`
+	Level      int              `json:"level"`
+	Module     int              `json:"module"`
+	Code       string           `json:"code"`
+	InTheSky   int              `json:"in_the_sky"`
+	Imminent   int              `json:"imminent"`
+	DeviceType string           `json:"device_type,omitempty"`
+	Args       cloudapi.HmsArgs `json:"args"`
+	CreateTime int64            `json:"create_time,omitempty"`
+}
+
+// isDock 告警是否由机场产生（设备类型以 3- 开头）
+func (a Alarm) isDock() bool {
+	return strings.HasPrefix(a.DeviceType, "3-")
+}
+
+// key 去重键：同一设备、告警码、飞行状态与部件视为同一条告警
+func (a Alarm) key() string {
+	return fmt.Sprintf("%s|%s|%d|%d|%d", a.DeviceSN, strings.ToLower(a.Code), a.InTheSky, a.Args.ComponentIndex, a.Args.SensorIndex)
+}
+
+// FromCloudAPI 转换上云API hms 事件中的告警，sn 为产生告警的设备序列号
+func FromCloudAPI(sn string, item cloudapi.HmsItem) Alarm {
+	return Alarm{
+		DeviceSN:   sn,
+		Level:      item.Level,
+		Module:     item.Module,
+		Code:       item.Code,
+		InTheSky:   item.InTheSky,
+		Imminent:   item.Imminent,
+		DeviceType: item.DeviceType,
+		Args:       item.Args,
+	}
+}
+
+// ParseList 解析告警列表响应，支持 {"data":{"list":[...]}} 与 {"list":[...]}
+func ParseList(data []byte) ([]Alarm, error) {
+	var body struct {
+		Data *struct {
+			List []Alarm `json:"list"`
+		} `json:"data"`
+		List []Alarm `json:"list"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("解析HMS告警失败: %w", err)
+	}
+	if body.Data != nil && body.Data.List != nil {
+		return body.Data.List, nil
+	}
+	return body.List, nil
+}
+
+// Severity 严重程度
+type Severity int
+
+const (
+	SeverityNotice  Severity = 0 // 通知
+	SeverityCaution Severity = 1 // 提醒
+	SeverityWarning Severity = 2 // 警告
+)
+
+// Text 严重程度名称
+func (s Severity) Text(lang Lang) string {
+	names := map[Severity][2]string{
+		SeverityNotice:  {"通知", "Notice"},
+		SeverityCaution: {"提醒", "Caution"},
+		SeverityWarning: {"警告", "Warning"},
+	}
+	name, ok := names[s]
+	if !ok {
+		return strconv.Itoa(int(s))
+	}
+	if lang == LangEN {
+		return name[1]
+	}
+	return name[0]
+}
+
+// moduleNames 告警模块名称
+var moduleNames = map[int][2]string{
+	0: {"飞行任务", "Flight task"},
+	1: {"设备管理", "Device management"},
+	2: {"媒体", "Media"},
+	3: {"健康管理", "HMS"},
+}
+
+// Decoded 解码后的告警
+type Decoded struct {
+	Alarm
+	Severity   Severity `json:"severity"`
+	LevelText  string   `json:"level_text"`
+	ModuleText string   `json:"module_text"`
+	Message    string   `json:"message"`
+	Action     string   `json:"action,omitempty"` // 处理建议
+	Known      bool     `json:"known"`            // 目录中是否收录该告警码
+}
+
+// Decode 按语言解码告警，目录未收录的告警码给出通用文案
+func (c *Catalog) Decode(alarm Alarm, lang Lang) Decoded {
+	d := Decoded{
+		Alarm:     alarm,
+		Severity:  Severity(alarm.Level),
+		LevelText: Severity(alarm.Level).Text(lang),
+	}
+	if name, ok := moduleNames[alarm.Module]; ok {
+		d.ModuleText = name[0]
+		if lang == LangEN {
+			d.ModuleText = name[1]
+		}
+	} else {
+		d.ModuleText = strconv.Itoa(alarm.Module)
+	}
+
+	entry, ok := c.lookup(alarm)
+	if !ok {
+		if lang == LangEN {
+			d.Message = fmt.Sprintf("Unknown HMS alarm %s", alarm.Code)
+		} else {
+			d.Message = fmt.Sprintf("未知HMS告警 %s", alarm.Code)
+		}
+		return d
+	}
+	d.Known = true
+	message, action := entry.text(lang)
+	d.Message = fillArgs(message, alarm)
+	d.Action = fillArgs(action, alarm)
+	return d
+}
+
+// fillArgs 填充文案中的占位符，部件与传感器序号从1开始显示
+func fillArgs(text string, alarm Alarm) string {
+	if !strings.Contains(text, "%") {
+		return text
+	}
+	return strings.NewReplacer(
+		"%component_index", strconv.Itoa(alarm.Args.ComponentIndex+1),
+		"%index", strconv.Itoa(alarm.Args.SensorIndex+1),
+		"%alarmid", alarm.Code,
+	).Replace(text)
+}
@@ -0,0 +1,79 @@
+package hms
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+)
+
+// ErrCatalogRequired 未配置完整告警码目录，内置目录只收录常见告警
+var ErrCatalogRequired = errors.New("未配置 Hms.catalog_file 完整告警码目录")
+
+// 全局告警目录与去重器，插件上报的告警统一经此产生事件
+var (
+	defaultCatalog = NewCatalog()
+	defaultTracker = NewTracker(defaultCatalog, LangZH)
+	defaultLang    atomic.Value // Lang
+)
+
+func init() {
+	defaultLang.Store(LangZH)
+	defaultTracker.OnEvent(func(e Event) {
+		switch e.Type {
+		case EventRaise:
+			log.Printf("HMS告警: 设备 %s [%s] %s %s", e.DeviceSN, e.Alarm.LevelText, e.Alarm.Code, e.Alarm.Message)
+		case EventClear:
+			log.Printf("HMS告警消除: 设备 %s %s %s", e.DeviceSN, e.Alarm.Code, e.Alarm.Message)
+		}
+	})
+}
+
+// DefaultCatalog 全局告警目录
+func DefaultCatalog() *Catalog {
+	return defaultCatalog
+}
+
+// DefaultTracker 全局告警去重器
+func DefaultTracker() *Tracker {
+	return defaultTracker
+}
+
+// Decode 使用全局目录与配置的语言解码告警
+func Decode(alarm Alarm) Decoded {
+	return defaultCatalog.Decode(alarm, defaultLang.Load().(Lang))
+}
+
+// Observe 向全局去重器上报设备当前的全部告警
+func Observe(devices []string, alarms []Alarm) []Event {
+	return defaultTracker.Update(devices, alarms)
+}
+
+// OnEvent 注册全局告警事件回调，返回取消注册函数
+func OnEvent(handler func(Event)) (unsubscribe func()) {
+	return defaultTracker.OnEvent(handler)
+}
+
+// ApplyConfig 按配置文件 Hms 段设置告警文案语言并加载完整目录（大疆发布的 hms.json）
+// 未配置 catalog_file 时仍设置语言，返回包装 ErrCatalogRequired 的错误，未收录的告警只能显示告警码
+func ApplyConfig(cfg *config.Hms) error {
+	if cfg == nil {
+		cfg = &config.Hms{}
+	}
+	lang, err := ParseLang(cfg.Language)
+	if err != nil {
+		return err
+	}
+	defaultLang.Store(lang)
+	defaultTracker.SetLang(lang)
+	if cfg.CatalogFile == "" {
+		return fmt.Errorf("%w，只能解码内置的 %d 条常见告警", ErrCatalogRequired, defaultCatalog.Len())
+	}
+	if err := defaultCatalog.LoadFile(cfg.CatalogFile); err != nil {
+		return err
+	}
+	log.Printf("已加载HMS目录 %s，共 %d 条文案", cfg.CatalogFile, defaultCatalog.Len())
+	return nil
+}
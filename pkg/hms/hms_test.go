@@ -0,0 +1,121 @@
+package hms
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/cloudapi"
+)
+
+// summary 事件摘要：类型 设备 告警码
+func summary(events []Event) []string {
+	var list []string
+	for _, e := range events {
+		list = append(list, string(e.Type)+" "+e.DeviceSN+" "+e.Alarm.Code)
+	}
+	return list
+}
+
+// TestTrackerDedupe 重复上报的告警不产生事件，新出现与消失的告警分别触发与消除（按设备排序），未查询的设备保持不变
+func TestTrackerDedupe(t *testing.T) {
+	tracker := NewTracker(NewCatalog(), LangZH)
+	var received []Event
+	unsubscribe := tracker.OnEvent(func(e Event) { received = append(received, e) })
+
+	imu := Alarm{DeviceSN: "DRONE", Level: 1, Code: "0x16100083"}
+	cover := Alarm{DeviceSN: "DOCK", Level: 2, Code: "0x1b010001", DeviceType: "3-2-0"}
+	battery := Alarm{DeviceSN: "DRONE", Level: 2, Code: "0x16100001", Args: cloudapi.HmsArgs{ComponentIndex: 1}}
+	devices := []string{"DOCK", "DRONE"}
+
+	steps := []struct {
+		name    string
+		devices []string
+		alarms  []Alarm
+		want    []string
+	}{
+		{"首次上报", devices, []Alarm{imu, cover}, []string{"raise DOCK 0x1b010001", "raise DRONE 0x16100083"}},
+		{"重复上报", devices, []Alarm{cover, imu}, nil},
+		{"告警码大小写不同视为同一条", devices, []Alarm{cover, {DeviceSN: "DRONE", Level: 1, Code: "0X16100083"}}, nil},
+		{"新增与消除", devices, []Alarm{imu, battery}, []string{"clear DOCK 0x1b010001", "raise DRONE 0x16100001"}},
+		{"只查询机场时飞行器告警不变", []string{"DOCK"}, nil, nil},
+		{"未指定设备的告警归属第一台设备", []string{"DOCK"}, []Alarm{{Level: 2, Code: "0x1b010001"}}, []string{"raise DOCK 0x1b010001"}},
+		{"全部消除", devices, nil, []string{"clear DOCK 0x1b010001", "clear DRONE 0x16100001", "clear DRONE 0x16100083"}},
+	}
+	for _, step := range steps {
+		if got := summary(tracker.Update(step.devices, step.alarms)); !reflect.DeepEqual(got, step.want) {
+			t.Fatalf("%s: 事件 %v，应为 %v", step.name, got, step.want)
+		}
+	}
+	if len(received) != 8 {
+		t.Errorf("回调收到 %d 个事件，应为 8", len(received))
+	}
+
+	tracker.Update(devices, []Alarm{imu, battery})
+	if active := tracker.Active("DRONE"); len(active) != 2 || active[0].Code != battery.Code || active[0].Message != "2号电池通信异常" {
+		t.Errorf("有效告警应按严重程度排序并填充部件序号: %+v", active)
+	}
+	tracker.Forget("DRONE")
+	if events := tracker.Update([]string{"DRONE"}, nil); len(events) != 0 || len(tracker.Active("DRONE")) != 0 {
+		t.Errorf("Forget 后不应产生消除事件: %v", summary(events))
+	}
+
+	unsubscribe()
+	tracker.Update(devices, []Alarm{cover})
+	if len(received) != 10 {
+		t.Errorf("取消注册后不应再收到事件，共收到 %d 个", len(received))
+	}
+}
+
+// TestCatalogMerge 目录文件合并到内置目录：同名键只覆盖非空字段，新键追加，查找按飞行状态与设备类型选择文案
+func TestCatalogMerge(t *testing.T) {
+	catalog := NewCatalog()
+	builtin := catalog.Len()
+
+	file := filepath.Join(t.TempDir(), "hms.json")
+	data := `{
+		"FPV_TIP_0x16100083": {"zh": "", "en": "IMU calibration needed", "action_zh": "联系运维校准"},
+		"dock_tip_0x1b990001": {"zh": "机场自定义告警", "en": "Custom dock alarm", "action_zh": "检查机场"}
+	}`
+	if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := catalog.LoadFile(file); err != nil {
+		t.Fatal(err)
+	}
+	if catalog.Len() != builtin+1 {
+		t.Errorf("目录 %d 条，应为内置 %d 条加新增 1 条", catalog.Len(), builtin)
+	}
+
+	tests := []struct {
+		name        string
+		alarm       Alarm
+		lang        Lang
+		wantMessage string
+		wantAction  string
+		wantKnown   bool
+	}{
+		{"空字段保留内置文案", Alarm{Code: "0x16100083"}, LangZH, "飞行器IMU需要校准", "联系运维校准", true},
+		{"覆盖英文文案", Alarm{Code: "0x16100083"}, LangEN, "IMU calibration needed", "Recalibrate the IMU on level open ground before the next mission", true},
+		{"飞行中优先使用 in_the_sky 文案", Alarm{Code: "0x16100083", InTheSky: 1}, LangZH, "飞行器IMU异常，请谨慎飞行", "建议立即返航，落地后校准IMU", true},
+		{"新增机场告警", Alarm{Code: "0x1B990001", DeviceType: "3-2-0"}, LangZH, "机场自定义告警", "检查机场", true},
+		{"缺少英文处理建议时回退中文", Alarm{Code: "0x1b990001", DeviceType: "3-2-0"}, LangEN, "Custom dock alarm", "检查机场", true},
+		{"未收录的告警码", Alarm{Code: "0x12345678"}, LangZH, "未知HMS告警 0x12345678", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := catalog.Decode(tt.alarm, tt.lang)
+			if d.Message != tt.wantMessage || d.Action != tt.wantAction || d.Known != tt.wantKnown {
+				t.Errorf("解码为 %q / %q / %v，应为 %q / %q / %v", d.Message, d.Action, d.Known, tt.wantMessage, tt.wantAction, tt.wantKnown)
+			}
+		})
+	}
+
+	if err := catalog.Load([]byte(`[]`)); err == nil {
+		t.Error("格式错误的目录应返回错误")
+	}
+	if NewCatalog().Len() != builtin {
+		t.Error("加载文件不应影响其他目录实例")
+	}
+}
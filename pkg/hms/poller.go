@@ -0,0 +1,88 @@
+package hms
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// Poller 定时通过 GetDeviceHms 查询设备告警并交给 Tracker 去重
+// ctx 需携带调用适配器所需的租户信息（如司空2）
+type Poller struct {
+	provider service.HmsProvider
+	tracker  *Tracker
+	devices  []string
+	interval time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPoller 创建告警轮询器，interval 小于等于0时为30秒
+func NewPoller(provider service.HmsProvider, tracker *Tracker, devices []string, interval time.Duration) *Poller {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &Poller{provider: provider, tracker: tracker, devices: devices, interval: interval}
+}
+
+// PollOnce 查询一次告警，返回产生的事件
+func (p *Poller) PollOnce(ctx context.Context) ([]Event, error) {
+	if len(p.devices) == 0 {
+		return nil, nil
+	}
+	resp, err := p.provider.GetDeviceHms(ctx, strings.Join(p.devices, ","))
+	if err != nil {
+		return nil, fmt.Errorf("查询HMS告警失败: %w", err)
+	}
+	alarms, err := ParseList([]byte(resp))
+	if err != nil {
+		return nil, err
+	}
+	return p.tracker.Update(p.devices, alarms), nil
+}
+
+// Start 启动轮询，重复调用无效
+func (p *Poller) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		return
+	}
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			// 查询失败时保留上次的告警状态，避免网络抖动产生误消除
+			if _, err := p.PollOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("HMS告警轮询失败: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}(p.done)
+}
+
+// Stop 停止轮询并等待当前查询结束
+func (p *Poller) Stop() {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel, p.done = nil, nil
+	p.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,168 @@
+package hms
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// EventType 告警事件类型
+type EventType string
+
+const (
+	EventRaise EventType = "raise" // 新出现的告警
+	EventClear EventType = "clear" // 告警消除
+)
+
+// Event 告警事件
+type Event struct {
+	Type     EventType `json:"type"`
+	DeviceSN string    `json:"device_sn"`
+	Alarm    Decoded   `json:"alarm"`
+	At       time.Time `json:"at"`
+}
+
+// activeAlarm 当前有效的告警
+type activeAlarm struct {
+	alarm   Alarm
+	raised  time.Time
+	lastHit time.Time
+}
+
+// Tracker 告警去重：每次轮询或事件上报设备当前的全部告警，只有新出现与消失的告警产生事件
+type Tracker struct {
+	catalog *Catalog
+	lang    Lang
+
+	mu       sync.Mutex
+	active   map[string]map[string]*activeAlarm // 设备序列号 -> 去重键 -> 告警
+	handlers []eventHandler
+	nextID   int
+}
+
+// eventHandler 已注册的事件回调
+type eventHandler struct {
+	id int
+	fn func(Event)
+}
+
+// NewTracker 创建告警去重器，事件中的告警按 lang 解码
+func NewTracker(catalog *Catalog, lang Lang) *Tracker {
+	return &Tracker{catalog: catalog, lang: lang, active: make(map[string]map[string]*activeAlarm)}
+}
+
+// SetLang 设置事件中告警文案的语言
+func (t *Tracker) SetLang(lang Lang) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lang = lang
+}
+
+// OnEvent 注册事件回调，返回取消注册函数；回调在 Update 调用方的协程中同步执行
+func (t *Tracker) OnEvent(handler func(Event)) (unsubscribe func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id := t.nextID
+	t.nextID++
+	t.handlers = append(t.handlers, eventHandler{id: id, fn: handler})
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		for i, h := range t.handlers {
+			if h.id == id {
+				t.handlers = append(t.handlers[:i:i], t.handlers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Update 上报设备当前的全部告警，devices 为本次查询的设备（其中未出现在 alarms 中的告警视为已消除），返回产生的事件
+// 告警的 DeviceSN 为空时归属 devices 中的第一台设备
+func (t *Tracker) Update(devices []string, alarms []Alarm) []Event {
+	now := time.Now()
+	current := make(map[string]map[string]Alarm)
+	for _, sn := range devices {
+		current[sn] = make(map[string]Alarm)
+	}
+	for _, alarm := range alarms {
+		if alarm.DeviceSN == "" && len(devices) > 0 {
+			alarm.DeviceSN = devices[0]
+		}
+		if current[alarm.DeviceSN] == nil {
+			current[alarm.DeviceSN] = make(map[string]Alarm)
+		}
+		current[alarm.DeviceSN][alarm.key()] = alarm
+	}
+
+	t.mu.Lock()
+	lang := t.lang
+	var events []Event
+	for sn, alarmsNow := range current {
+		previous := t.active[sn]
+		next := make(map[string]*activeAlarm, len(alarmsNow))
+		for key, alarm := range alarmsNow {
+			if prev, ok := previous[key]; ok {
+				prev.alarm, prev.lastHit = alarm, now
+				next[key] = prev
+				continue
+			}
+			next[key] = &activeAlarm{alarm: alarm, raised: now, lastHit: now}
+			events = append(events, Event{Type: EventRaise, DeviceSN: sn, Alarm: t.catalog.Decode(alarm, lang), At: now})
+		}
+		for key, prev := range previous {
+			if _, ok := alarmsNow[key]; !ok {
+				events = append(events, Event{Type: EventClear, DeviceSN: sn, Alarm: t.catalog.Decode(prev.alarm, lang), At: now})
+			}
+		}
+		if len(next) == 0 {
+			delete(t.active, sn)
+		} else {
+			t.active[sn] = next
+		}
+	}
+	handlers := append([]eventHandler{}, t.handlers...)
+	t.mu.Unlock()
+
+	// 事件顺序固定：按设备、类型（先触发后消除）、告警码
+	sort.Slice(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if a.DeviceSN != b.DeviceSN {
+			return a.DeviceSN < b.DeviceSN
+		}
+		if a.Type != b.Type {
+			return a.Type == EventRaise
+		}
+		return a.Alarm.Code < b.Alarm.Code
+	})
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler.fn(event)
+		}
+	}
+	return events
+}
+
+// Active 设备当前有效的告警（已解码），按严重程度从高到低排序
+func (t *Tracker) Active(sn string) []Decoded {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var list []Decoded
+	for _, active := range t.active[sn] {
+		list = append(list, t.catalog.Decode(active.alarm, t.lang))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Severity != list[j].Severity {
+			return list[i].Severity > list[j].Severity
+		}
+		return list[i].Code < list[j].Code
+	})
+	return list
+}
+
+// Forget 清除设备的告警状态（设备解绑或插件停用时调用），不产生消除事件
+func (t *Tracker) Forget(sn string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.active, sn)
+}
@@ -0,0 +1,63 @@
+package hms
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// watchKey 共享轮询按租户、项目与设备区分，不同租户使用各自的凭据查询
+type watchKey struct {
+	tenantID int64
+	project  string
+	sn       string
+}
+
+// watch 设备的共享轮询，最后一个引用释放时停止
+type watch struct {
+	refs   int
+	poller *Poller
+}
+
+var (
+	watchMu sync.Mutex
+	watches = make(map[watchKey]*watch)
+)
+
+// Watch 引用设备的告警轮询，gRPC 推送流、WebSocket 推送中心等订阅方对同一设备共用一个轮询，返回释放函数
+// 轮询按首个订阅方的 interval 执行，结果经全局去重器产生事件；ctx 需携带租户信息，轮询不随 ctx 取消
+func Watch(ctx context.Context, provider service.HmsProvider, sn string, interval time.Duration) (release func()) {
+	key := watchKey{sn: sn}
+	if info, err := tenant.GetTenantFromContext(ctx); err == nil {
+		key.tenantID, key.project = info.TenantId, info.ProjectUUID
+	}
+
+	watchMu.Lock()
+	w, ok := watches[key]
+	if !ok {
+		w = &watch{poller: NewPoller(provider, defaultTracker, []string{sn}, interval)}
+		w.poller.Start(context.WithoutCancel(ctx))
+		watches[key] = w
+	}
+	w.refs++
+	watchMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			watchMu.Lock()
+			w.refs--
+			last := w.refs == 0 && watches[key] == w
+			if last {
+				delete(watches, key)
+			}
+			watchMu.Unlock()
+			if last {
+				w.poller.Stop()
+			}
+		})
+	}
+}
@@ -0,0 +1,445 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// RecorderMode 录制回放模式
+type RecorderMode int
+
+const (
+	ModeRecord RecorderMode = iota // 录制模式：请求真实服务，并把请求/响应写入磁带文件
+	ModeReplay                     // 回放模式：只从磁带返回响应，不访问网络
+	ModeAuto                       // 自动模式：磁带文件存在则回放，否则录制
+)
+
+// RedactedValue 脱敏后的占位值
+const RedactedValue = "[REDACTED]"
+
+// 默认需要脱敏的请求头、查询参数与JSON请求体/响应体字段
+var (
+	defaultScrubHeaders     = []string{"X-User-Token", "Authorization", "Cookie", "Set-Cookie", "X-Amz-Security-Token"}
+	defaultScrubQueryParams = []string{"appKey", "appLicense", "access_token", "token", "X-Amz-Security-Token", "X-Amz-Signature"}
+	// STS 临时凭证、司空2用户令牌、直播鉴权Token等
+	defaultScrubBodyFields = []string{"access_key_secret", "security_token", "x_user_token", "user_token", "access_token", "token", "password"}
+	// 不写入磁带的请求头（每次请求都会变化，对回放无意义）
+	volatileHeaders = []string{"X-Request-Id", "User-Agent", "Date"}
+)
+
+// BodyEncodingBase64 二进制请求体/响应体（如 KMZ、图片）在磁带中以 base64 保存
+const BodyEncodingBase64 = "base64"
+
+// RecordedRequest 磁带中的请求
+type RecordedRequest struct {
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Body         string            `json:"body,omitempty"`
+	BodyEncoding string            `json:"body_encoding,omitempty"` // 为空表示原文，base64 表示二进制内容
+}
+
+// RecordedResponse 磁带中的响应
+type RecordedResponse struct {
+	StatusCode   int               `json:"status_code"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Body         string            `json:"body"`
+	BodyEncoding string            `json:"body_encoding,omitempty"`
+}
+
+// Interaction 一次请求/响应交互
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// Cassette 磁带文件内容
+type Cassette struct {
+	Version      int            `json:"version"`
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Recorder 录制回放传输层，实现 http.RoundTripper
+// 录制模式下把经过的请求/响应脱敏后保存到磁带文件，回放模式下按请求匹配磁带中的响应，
+// 用于在无网络、无真实司空2组织的情况下对适配器做回归测试。
+type Recorder struct {
+	path             string
+	mode             RecorderMode
+	realTransport    http.RoundTripper
+	cassette         *Cassette
+	used             map[int]bool // 回放模式下已消费的交互下标
+	scrubHeaders     []string
+	scrubQueryParams []string
+	scrubBodyFields  []string
+	scrubber         func(*Interaction) // 自定义脱敏处理（例如响应体中的密钥）
+	mu               sync.Mutex
+}
+
+// NewRecorder 创建录制回放传输层
+// realTransport 为录制模式下实际发送请求的传输层，为nil时使用 NewSecureTransport()
+func NewRecorder(cassettePath string, mode RecorderMode, realTransport http.RoundTripper) (*Recorder, error) {
+	if cassettePath == "" {
+		return nil, fmt.Errorf("磁带文件路径不能为空")
+	}
+	if realTransport == nil {
+		realTransport = NewSecureTransport()
+	}
+	r := &Recorder{
+		path:             cassettePath,
+		mode:             mode,
+		realTransport:    realTransport,
+		cassette:         &Cassette{Version: 1},
+		used:             make(map[int]bool),
+		scrubHeaders:     append([]string{}, defaultScrubHeaders...),
+		scrubQueryParams: append([]string{}, defaultScrubQueryParams...),
+		scrubBodyFields:  append([]string{}, defaultScrubBodyFields...),
+	}
+
+	if r.mode == ModeAuto {
+		// 只有磁带文件不存在时才录制，权限等其他错误直接返回，避免误访问真实服务并覆盖磁带
+		_, err := os.Stat(cassettePath)
+		switch {
+		case err == nil:
+			r.mode = ModeReplay
+		case os.IsNotExist(err):
+			r.mode = ModeRecord
+		default:
+			return nil, fmt.Errorf("读取磁带文件失败: %w", err)
+		}
+	}
+
+	if r.mode == ModeReplay {
+		cassette, err := LoadCassette(cassettePath)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = cassette
+	}
+	return r, nil
+}
+
+// NewRecordingClient 创建挂载录制回放传输层的安全HTTP客户端
+func NewRecordingClient(cassettePath string, mode RecorderMode) (*SecureHTTPClient, *Recorder, error) {
+	recorder, err := NewRecorder(cassettePath, mode, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewSecureHTTPClientWithTransport(recorder), recorder, nil
+}
+
+// LoadCassette 读取磁带文件
+func LoadCassette(cassettePath string) (*Cassette, error) {
+	data, err := os.ReadFile(cassettePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取磁带文件失败: %w", err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("解析磁带文件失败: %w", err)
+	}
+	return &cassette, nil
+}
+
+// Mode 返回当前生效的模式（ModeAuto 会被解析为录制或回放）
+func (r *Recorder) Mode() RecorderMode {
+	return r.mode
+}
+
+// AddScrubHeaders 追加需要脱敏的请求头/响应头
+func (r *Recorder) AddScrubHeaders(headers ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scrubHeaders = append(r.scrubHeaders, headers...)
+}
+
+// AddScrubQueryParams 追加需要脱敏的URL查询参数
+func (r *Recorder) AddScrubQueryParams(params ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scrubQueryParams = append(r.scrubQueryParams, params...)
+}
+
+// AddScrubBodyFields 追加需要脱敏的JSON请求体/响应体字段（任意层级，忽略大小写）
+func (r *Recorder) AddScrubBodyFields(fields ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scrubBodyFields = append(r.scrubBodyFields, fields...)
+}
+
+// SetScrubber 设置自定义脱敏函数，在交互写入磁带前调用
+func (r *Recorder) SetScrubber(scrubber func(*Interaction)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scrubber = scrubber
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readAndRestoreBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("读取请求体失败: %w", err)
+	}
+	recorded := r.recordRequest(req, reqBody)
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+	return r.record(req, recorded)
+}
+
+// Stop 结束录制，录制模式下把磁带写入文件
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mode != ModeRecord {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化磁带失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("创建磁带目录失败: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0o644); err != nil {
+		return fmt.Errorf("写入磁带文件失败: %w", err)
+	}
+	return nil
+}
+
+// record 发送真实请求并记录交互
+func (r *Recorder) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	resp, err := r.realTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取响应体失败: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	interaction := &Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    r.flattenHeaders(resp.Header),
+		},
+	}
+	interaction.Response.Body, interaction.Response.BodyEncoding = r.encodeBody(respBody)
+	if r.scrubber != nil {
+		r.scrubber(interaction)
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	return resp, nil
+}
+
+// replay 从磁带中查找匹配的交互并返回响应
+// 相同请求按录制顺序依次返回，全部消费后重复返回最后一次响应，保证回放结果确定
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	matched := -1
+	for i, interaction := range r.cassette.Interactions {
+		if !matchRequest(interaction.Request, recorded) {
+			continue
+		}
+		matched = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if matched < 0 {
+		return nil, fmt.Errorf("磁带中没有匹配的请求: %s %s", recorded.Method, recorded.URL)
+	}
+	r.used[matched] = true
+
+	stored := r.cassette.Interactions[matched].Response
+	body, err := decodeBody(stored.Body, stored.BodyEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("磁带响应体解码失败: %w", err)
+	}
+	header := make(http.Header, len(stored.Headers))
+	for key, value := range stored.Headers {
+		header.Set(key, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", stored.StatusCode, http.StatusText(stored.StatusCode)),
+		StatusCode:    stored.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// recordRequest 生成脱敏后的请求记录
+func (r *Recorder) recordRequest(req *http.Request, body []byte) RecordedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recorded := RecordedRequest{
+		Method:  req.Method,
+		URL:     r.scrubURL(req.URL),
+		Headers: r.flattenHeaders(req.Header),
+	}
+	recorded.Body, recorded.BodyEncoding = r.encodeBody(body)
+	return recorded
+}
+
+// encodeBody 生成写入磁带的请求体/响应体：二进制内容以 base64 保存，JSON 内容对敏感字段脱敏
+func (r *Recorder) encodeBody(body []byte) (string, string) {
+	if !utf8.Valid(body) {
+		return base64.StdEncoding.EncodeToString(body), BodyEncodingBase64
+	}
+	return r.scrubBody(body), ""
+}
+
+// decodeBody 还原磁带中的请求体/响应体
+func decodeBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case BodyEncodingBase64:
+		return base64.StdEncoding.DecodeString(body)
+	default:
+		return nil, fmt.Errorf("不支持的编码: %s", encoding)
+	}
+}
+
+// scrubBody 对JSON请求体/响应体中的敏感字段脱敏，非JSON或没有敏感字段时原样返回
+func (r *Recorder) scrubBody(body []byte) string {
+	if len(body) == 0 || len(r.scrubBodyFields) == 0 {
+		return string(body)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if decoder.Decode(&value) != nil {
+		return string(body)
+	}
+	if !r.scrubValue(value) {
+		return string(body)
+	}
+	scrubbed, err := json.Marshal(value)
+	if err != nil {
+		return string(body)
+	}
+	return string(scrubbed)
+}
+
+// scrubValue 递归替换敏感字段的值，返回是否有字段被脱敏
+func (r *Recorder) scrubValue(value interface{}) bool {
+	scrubbed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if containsFold(r.scrubBodyFields, key) && item != nil {
+				if _, isString := item.(string); isString {
+					v[key] = RedactedValue
+					scrubbed = true
+					continue
+				}
+			}
+			if r.scrubValue(item) {
+				scrubbed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if r.scrubValue(item) {
+				scrubbed = true
+			}
+		}
+	}
+	return scrubbed
+}
+
+// flattenHeaders 展开请求头并做脱敏，忽略易变请求头
+func (r *Recorder) flattenHeaders(header http.Header) map[string]string {
+	result := make(map[string]string, len(header))
+	for key, values := range header {
+		if containsFold(volatileHeaders, key) {
+			continue
+		}
+		value := strings.Join(values, ",")
+		if containsFold(r.scrubHeaders, key) {
+			value = RedactedValue
+		}
+		result[http.CanonicalHeaderKey(key)] = value
+	}
+	return result
+}
+
+// scrubURL 对URL中的敏感查询参数脱敏
+func (r *Recorder) scrubURL(u *url.URL) string {
+	scrubbed := *u
+	query := scrubbed.Query()
+	for key := range query {
+		if containsFold(r.scrubQueryParams, key) {
+			query.Set(key, RedactedValue)
+		}
+	}
+	scrubbed.RawQuery = query.Encode()
+	return scrubbed.String()
+}
+
+// matchRequest 按方法、URL和请求体匹配请求，JSON请求体按语义比较
+func matchRequest(stored, actual RecordedRequest) bool {
+	if stored.Method != actual.Method || stored.URL != actual.URL {
+		return false
+	}
+	if stored.Body == actual.Body {
+		return true
+	}
+	if stored.BodyEncoding != "" || actual.BodyEncoding != "" {
+		return false
+	}
+	var storedJSON, actualJSON interface{}
+	if json.Unmarshal([]byte(stored.Body), &storedJSON) != nil || json.Unmarshal([]byte(actual.Body), &actualJSON) != nil {
+		return false
+	}
+	storedNorm, _ := json.Marshal(storedJSON)
+	actualNorm, _ := json.Marshal(actualJSON)
+	return bytes.Equal(storedNorm, actualNorm)
+}
+
+// readAndRestoreBody 读取请求体并重新放回请求中，保证后续传输层仍可读取
+func readAndRestoreBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// containsFold 忽略大小写判断切片中是否包含指定字符串
+func containsFold(list []string, target string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, target) {
+			return true
+		}
+	}
+	return false
+}
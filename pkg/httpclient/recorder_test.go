@@ -0,0 +1,168 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// stsServer 返回包含STS临时凭证的响应，并回显请求序号
+func stsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"code":0,"data":{"call":`+strconv.Itoa(calls)+`,"credentials":{"access_key_id":"AKID","access_key_secret":"s3cr3t","security_token":"sts-token"}}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func doGet(t *testing.T, client *SecureHTTPClient, url string, body string) string {
+	t.Helper()
+	var reader io.Reader
+	method := http.MethodGet
+	if body != "" {
+		method, reader = http.MethodPost, strings.NewReader(body)
+	}
+	resp, err := client.DoRequest(context.Background(), method, url, reader, map[string]string{"X-User-Token": "user-token"})
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("读取响应失败: %v", err)
+	}
+	return string(data)
+}
+
+func TestRecorderScrubsSecrets(t *testing.T) {
+	server := stsServer(t)
+	path := filepath.Join(t.TempDir(), "sts.json")
+	recorder, err := NewRecorder(path, ModeRecord, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewSecureHTTPClientWithTransport(recorder)
+	live := doGet(t, client, server.URL+"/sts?token=query-token", `{"name":"a","password":"p@ss"}`)
+	if !strings.Contains(live, "s3cr3t") {
+		t.Fatalf("录制模式应返回真实响应: %s", live)
+	}
+	if err := recorder.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"s3cr3t", "sts-token", "user-token", "query-token", "p@ss"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("磁带中不应包含 %q:\n%s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), "AKID") {
+		t.Errorf("非敏感字段不应被脱敏:\n%s", data)
+	}
+
+	// 回放时请求体按脱敏后的内容匹配
+	replayer, err := NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := doGet(t, NewSecureHTTPClientWithTransport(replayer), server.URL+"/sts?token=other", `{"password":"other","name":"a"}`)
+	if !strings.Contains(replayed, RedactedValue) || strings.Contains(replayed, "s3cr3t") {
+		t.Errorf("回放响应应为脱敏后的内容: %s", replayed)
+	}
+}
+
+func TestRecorderReplayOrder(t *testing.T) {
+	server := stsServer(t)
+	path := filepath.Join(t.TempDir(), "order.json")
+	recorder, err := NewRecorder(path, ModeRecord, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewSecureHTTPClientWithTransport(recorder)
+	doGet(t, client, server.URL+"/sts", "")
+	doGet(t, client, server.URL+"/sts", "")
+	if err := recorder.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = NewSecureHTTPClientWithTransport(replayer)
+	for i, want := range []string{`"call":1`, `"call":2`, `"call":2`} {
+		if got := doGet(t, client, server.URL+"/sts", ""); !strings.Contains(got, want) {
+			t.Errorf("第 %d 次回放应包含 %s，实际 %s", i+1, want, got)
+		}
+	}
+	if _, err := client.DoRequest(context.Background(), http.MethodGet, server.URL+"/missing", nil, nil); err == nil {
+		t.Error("磁带中没有的请求应返回错误")
+	}
+}
+
+func TestRecorderAutoMode(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewRecorder(filepath.Join(dir, "missing.json"), ModeAuto, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Mode() != ModeRecord {
+		t.Errorf("磁带不存在时应录制，实际模式 %d", recorder.Mode())
+	}
+
+	existing := filepath.Join(dir, "existing.json")
+	if err := os.WriteFile(existing, []byte(`{"version":1,"interactions":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	recorder, err = NewRecorder(existing, ModeAuto, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Mode() != ModeReplay {
+		t.Errorf("磁带存在时应回放，实际模式 %d", recorder.Mode())
+	}
+
+	// 路径不可访问（父路径是普通文件）时不能退回录制模式
+	if _, err := NewRecorder(filepath.Join(existing, "cassette.json"), ModeAuto, nil); err == nil {
+		t.Error("磁带路径不可访问时应返回错误")
+	}
+}
+
+func TestRecorderBinaryBody(t *testing.T) {
+	payload := []byte{'P', 'K', 0x03, 0x04, 0xff, 0xfe, 0x00}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(append(body, 0xff))
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "binary.json")
+	recorder, err := NewRecorder(path, ModeRecord, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := doGet(t, NewSecureHTTPClientWithTransport(recorder), server.URL+"/object", string(payload))
+	if err := recorder.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := doGet(t, NewSecureHTTPClientWithTransport(replayer), server.URL+"/object", string(payload)); got != want {
+		t.Errorf("二进制响应回放不一致: %x != %x", got, want)
+	}
+}
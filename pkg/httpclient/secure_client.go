@@ -18,19 +18,30 @@ type SecureHTTPClient struct {
 
 // NewSecureHTTPClient 创建请求客户端
 func NewSecureHTTPClient() *SecureHTTPClient {
+	return NewSecureHTTPClientWithTransport(NewSecureTransport())
+}
+
+// NewSecureHTTPClientWithTransport 使用指定的传输层创建请求客户端
+// 用于注入录制回放传输层(Recorder)等自定义RoundTripper，URL与请求头校验逻辑保持不变
+func NewSecureHTTPClientWithTransport(transport http.RoundTripper) *SecureHTTPClient {
 	return &SecureHTTPClient{
 		client: &http.Client{
-			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					MinVersion: tls.VersionTLS12,
-					// InsecureSkipVerify: true, // 根据需要启用，但不推荐在生产环境中使用
-				},
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 10,
-				IdleConnTimeout:     90 * time.Second,
-			},
+			Timeout:   30 * time.Second,
+			Transport: transport,
+		},
+	}
+}
+
+// NewSecureTransport 创建默认的安全传输层(TLS 1.2+、连接池)
+func NewSecureTransport() *http.Transport {
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			// InsecureSkipVerify: true, // 根据需要启用，但不推荐在生产环境中使用
 		},
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
 	}
 }
 
@@ -65,6 +76,18 @@ func (shc *SecureHTTPClient) DoRequest(ctx context.Context, method, requestURL s
 	return resp, nil
 }
 
+// Do 执行调用方构造的请求，只校验URL；用于需要自行设置并签名请求头的场景（如对象存储）
+func (shc *SecureHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if err := shc.validateURL(req.URL.String()); err != nil {
+		return nil, fmt.Errorf("URL验证失败: %w", err)
+	}
+	resp, err := shc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("执行HTTP请求失败: %w", err)
+	}
+	return resp, nil
+}
+
 // validateURL 验证URL的安全性
 func (shc *SecureHTTPClient) validateURL(requestURL string) error {
 	parsedURL, err := url.Parse(requestURL)
@@ -0,0 +1,61 @@
+package livestream
+
+import (
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+)
+
+var (
+	defaultMu      sync.RWMutex
+	defaultManager = NewManager(Options{})
+)
+
+// Default 全局直播会话管理器
+func Default() *Manager {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultManager
+}
+
+// ApplyConfig 按配置文件 LiveStream 段替换全局管理器，server 提供租户的服务凭据；进行中的会话由新管理器接管
+func ApplyConfig(cfg *config.LiveStream, server *config.Server) {
+	next := NewManagerFromConfig(cfg, serviceTokens(server))
+	defaultMu.Lock()
+	prev := defaultManager
+	defaultManager = next
+	defaultMu.Unlock()
+	next.adopt(prev)
+	prev.Close()
+}
+
+// NewManagerFromConfig 按配置创建管理器，cfg 为nil时使用默认值
+func NewManagerFromConfig(cfg *config.LiveStream, credentials tenant.ServiceTokens) *Manager {
+	if cfg == nil {
+		return NewManager(Options{Credentials: credentials})
+	}
+	return NewManager(Options{
+		VideoExpire:   time.Duration(cfg.VideoExpire) * time.Second,
+		RefreshBefore: time.Duration(cfg.RefreshBefore) * time.Second,
+		ViewerTimeout: time.Duration(cfg.ViewerTimeout) * time.Second,
+		IdleTimeout:   time.Duration(cfg.IdleTimeout) * time.Second,
+		Templates:     URLTemplates{HLS: cfg.HlsURL, WebRTC: cfg.WebRTCURL},
+		Credentials:   credentials,
+	})
+}
+
+// serviceTokens 配置文件 Server 段登记的租户服务凭据
+func serviceTokens(server *config.Server) tenant.ServiceTokens {
+	tokens := make(tenant.ServiceTokens)
+	if server == nil {
+		return tokens
+	}
+	for _, grant := range server.Tenants {
+		if grant.ServiceToken != "" {
+			tokens[grant.TenantID] = grant.ServiceToken
+		}
+	}
+	return tokens
+}
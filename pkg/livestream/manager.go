@@ -0,0 +1,366 @@
+package livestream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/service"
+	"github.com/google/uuid"
+)
+
+// ErrNotFound 直播会话不存在或观众已离开
+var ErrNotFound = errors.New("直播会话不存在")
+
+// Request 观看直播的请求
+type Request struct {
+	SN          string `json:"sn"`
+	CameraIndex string `json:"camera_index"`           // 相机索引，见设备列表中的 camera_list
+	QualityType string `json:"quality_type,omitempty"` // 只在开启新直播时生效
+}
+
+// Session 直播会话
+type Session struct {
+	ID          string    `json:"id"`
+	TenantID    int64     `json:"tenant_id"`
+	ProjectUUID string    `json:"project_uuid,omitempty"`
+	SN          string    `json:"sn"`
+	CameraIndex string    `json:"camera_index"`
+	QualityType string    `json:"quality_type,omitempty"`
+	Playback    Playback  `json:"playback"`
+	Viewers     int       `json:"viewers"`
+	StartedAt   time.Time `json:"started_at"`
+	RefreshedAt time.Time `json:"refreshed_at"`         // 最近一次开启或刷新Token的时间
+	IdleSince   time.Time `json:"idle_since,omitempty"` // 最后一位观众离开的时间，仍有观众时为零值
+}
+
+// Ticket 观众加入直播的凭据，心跳与离开时携带 ViewerID
+type Ticket struct {
+	ViewerID string  `json:"viewer_id"`
+	Session  Session `json:"session"`
+}
+
+// Options 会话管理选项，零值使用默认值
+type Options struct {
+	VideoExpire   time.Duration // 开启直播时申请的推流Token有效期，默认2小时
+	RefreshBefore time.Duration // 仍有观众时提前刷新Token的时长，默认5分钟
+	ViewerTimeout time.Duration // 观众超过该时长未发送心跳视为离开，默认60秒
+	IdleTimeout   time.Duration // 最后一位观众离开后保留会话的时长，默认5分钟
+	Templates     URLTemplates
+	// Credentials 租户的服务凭据，后台刷新Token与停止直播时以此调用，不沿用开启直播的首位观众的用户令牌；未配置的租户不刷新也不主动停止
+	Credentials tenant.ServiceTokens
+}
+
+// session 会话内部状态
+type session struct {
+	Session
+	key        string
+	streamer   service.LiveStreamer
+	tenant     *tenant.TenantInfo   // 会话所属租户与项目，不含用户令牌
+	viewers    map[string]time.Time // 观众ID -> 最近一次心跳
+	ready      chan struct{}        // 首次开启完成后关闭
+	err        error                // 首次开启失败的错误
+	refreshing bool
+}
+
+// Manager 直播会话管理器
+type Manager struct {
+	opts     Options
+	mu       sync.Mutex
+	sessions map[string]*session // 会话ID或 key -> 会话，开启中的会话只有 key
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewManager 创建会话管理器并启动后台巡检
+func NewManager(opts Options) *Manager {
+	if opts.VideoExpire <= 0 {
+		opts.VideoExpire = 2 * time.Hour
+	}
+	if opts.RefreshBefore <= 0 {
+		opts.RefreshBefore = 5 * time.Minute
+	}
+	if opts.ViewerTimeout <= 0 {
+		opts.ViewerTimeout = time.Minute
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = 5 * time.Minute
+	}
+	m := &Manager{opts: opts, sessions: make(map[string]*session), stop: make(chan struct{})}
+	go m.loop()
+	return m
+}
+
+// Templates 播放地址模板
+func (m *Manager) Templates() URLTemplates {
+	return m.opts.Templates
+}
+
+// Join 观看设备相机的直播：已有会话时加入，否则通过 streamer 开启直播
+func (m *Manager) Join(ctx context.Context, streamer service.LiveStreamer, req Request) (*Ticket, error) {
+	req.SN, req.CameraIndex = strings.TrimSpace(req.SN), strings.TrimSpace(req.CameraIndex)
+	if req.SN == "" || req.CameraIndex == "" {
+		return nil, fmt.Errorf("设备序列号与相机索引不能为空")
+	}
+	info, err := tenant.GetTenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%d/%s/%s/%s", info.TenantId, info.ProjectUUID, req.SN, req.CameraIndex)
+
+	m.mu.Lock()
+	s, exists := m.sessions[key]
+	if !exists {
+		owner := tenant.NewTenantInfo(info.TenantId, "", info.ProjectUUID)
+		owner.OrgID = info.OrgID
+		s = &session{
+			Session:  Session{ID: uuid.New().String(), TenantID: info.TenantId, ProjectUUID: info.ProjectUUID, SN: req.SN, CameraIndex: req.CameraIndex, QualityType: req.QualityType},
+			key:      key,
+			streamer: streamer,
+			tenant:   owner,
+			viewers:  make(map[string]time.Time),
+			ready:    make(chan struct{}),
+		}
+		m.sessions[key] = s
+	}
+	m.mu.Unlock()
+
+	if !exists {
+		playback, err := m.start(ctx, s)
+		m.mu.Lock()
+		if err != nil {
+			s.err = err
+			delete(m.sessions, key)
+		} else {
+			now := time.Now()
+			s.Playback, s.StartedAt, s.RefreshedAt, s.IdleSince = playback, now, now, now
+			m.sessions[s.ID] = s
+		}
+		m.mu.Unlock()
+		close(s.ready)
+	}
+	select {
+	case <-s.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	m.mu.Lock()
+	if m.sessions[s.ID] != s {
+		// 等待期间会话因无人观看被停止，重新开启
+		m.mu.Unlock()
+		return m.Join(ctx, streamer, req)
+	}
+	defer m.mu.Unlock()
+	viewerID := uuid.New().String()
+	s.viewers[viewerID] = time.Now()
+	s.IdleSince = time.Time{}
+	return &Ticket{ViewerID: viewerID, Session: s.snapshot()}, nil
+}
+
+// Heartbeat 观众保活，返回会话的最新状态（Token刷新后播放信息随之更新）
+func (m *Manager) Heartbeat(sessionID, viewerID string) (Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.sessions[sessionID]
+	if s == nil {
+		return Session{}, fmt.Errorf("%w: %s", ErrNotFound, sessionID)
+	}
+	if _, ok := s.viewers[viewerID]; !ok {
+		return Session{}, fmt.Errorf("%w: 观众 %s 已离开", ErrNotFound, viewerID)
+	}
+	s.viewers[viewerID] = time.Now()
+	return s.snapshot(), nil
+}
+
+// Leave 观众离开，最后一位观众离开后会话保留 IdleTimeout
+func (m *Manager) Leave(sessionID, viewerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.sessions[sessionID]
+	if s == nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, sessionID)
+	}
+	if _, ok := s.viewers[viewerID]; !ok {
+		return fmt.Errorf("%w: 观众 %s 已离开", ErrNotFound, viewerID)
+	}
+	delete(s.viewers, viewerID)
+	if len(s.viewers) == 0 {
+		s.IdleSince = time.Now()
+	}
+	return nil
+}
+
+// Get 按ID查找会话
+func (m *Manager) Get(sessionID string) (Session, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.sessions[sessionID]
+	if s == nil {
+		return Session{}, false
+	}
+	return s.snapshot(), true
+}
+
+// List 租户的全部会话，按开启时间排序；tenantID 为0时返回全部
+func (m *Manager) List(tenantID int64) []Session {
+	m.mu.Lock()
+	list := make([]Session, 0)
+	for id, s := range m.sessions {
+		if id == s.ID && (tenantID == 0 || s.TenantID == tenantID) {
+			list = append(list, s.snapshot())
+		}
+	}
+	m.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].StartedAt.Before(list[j].StartedAt) })
+	return list
+}
+
+// Close 停止后台巡检，不停止正在进行的直播
+func (m *Manager) Close() {
+	m.stopOnce.Do(func() { close(m.stop) })
+}
+
+// adopt 接管另一个管理器的会话
+func (m *Manager) adopt(prev *Manager) {
+	if prev == nil || prev == m {
+		return
+	}
+	prev.mu.Lock()
+	sessions := prev.sessions
+	prev.sessions = make(map[string]*session)
+	prev.mu.Unlock()
+	m.mu.Lock()
+	for id, s := range sessions {
+		m.sessions[id] = s
+	}
+	m.mu.Unlock()
+}
+
+func (s *session) snapshot() Session {
+	snapshot := s.Session
+	snapshot.Viewers = len(s.viewers)
+	return snapshot
+}
+
+// start 开启直播（已开启时厂商返回新的鉴权信息）
+func (m *Manager) start(ctx context.Context, s *session) (Playback, error) {
+	body := map[string]interface{}{
+		"sn":           s.SN,
+		"camera_index": s.CameraIndex,
+		"video_expire": int(m.opts.VideoExpire / time.Second),
+	}
+	if s.QualityType != "" {
+		body["quality_type"] = s.QualityType
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return Playback{}, err
+	}
+	resp, err := s.streamer.LiveStreamStart(ctx, bytes.NewReader(payload))
+	if err != nil {
+		return Playback{}, err
+	}
+	return ParsePlayback(resp, m.opts.Templates)
+}
+
+// loop 定期清理超时的观众、刷新即将到期的Token并停止无人观看的直播
+func (m *Manager) loop() {
+	ticker := time.NewTicker(m.opts.ViewerTimeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.sweep(time.Now())
+		}
+	}
+}
+
+func (m *Manager) sweep(now time.Time) {
+	var refresh, stop []*session
+	m.mu.Lock()
+	for id, s := range m.sessions {
+		if id != s.ID {
+			continue
+		}
+		for viewerID, seen := range s.viewers {
+			if now.Sub(seen) > m.opts.ViewerTimeout {
+				delete(s.viewers, viewerID)
+			}
+		}
+		if len(s.viewers) == 0 && s.IdleSince.IsZero() {
+			s.IdleSince = now
+		}
+		expired := !s.Playback.ExpireAt.IsZero() && !now.Before(s.Playback.ExpireAt)
+		switch {
+		case len(s.viewers) == 0 && now.Sub(s.IdleSince) >= m.opts.IdleTimeout, expired:
+			delete(m.sessions, s.ID)
+			delete(m.sessions, s.key)
+			stop = append(stop, s)
+		case len(s.viewers) > 0 && !s.refreshing && !s.Playback.ExpireAt.IsZero() && now.After(s.Playback.ExpireAt.Add(-m.opts.RefreshBefore)):
+			s.refreshing = true
+			refresh = append(refresh, s)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, s := range refresh {
+		go m.refresh(s)
+	}
+	for _, s := range stop {
+		if stopper, ok := s.streamer.(service.LiveStreamStopper); ok {
+			go func(s *session) {
+				ctx, cancel, err := m.serviceContext(s)
+				if err != nil {
+					log.Printf("停止设备 %s 相机 %s 的直播失败: %v", s.SN, s.CameraIndex, err)
+					return
+				}
+				defer cancel()
+				if err := stopper.LiveStreamStop(ctx, s.SN, s.CameraIndex); err != nil {
+					log.Printf("停止设备 %s 相机 %s 的直播失败: %v", s.SN, s.CameraIndex, err)
+				}
+			}(s)
+		}
+	}
+}
+
+// refresh 重新开启直播以获取新的推流Token，失败时下次巡检重试
+func (m *Manager) refresh(s *session) {
+	var playback Playback
+	ctx, cancel, err := m.serviceContext(s)
+	if err == nil {
+		defer cancel()
+		playback, err = m.start(ctx, s)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.refreshing = false
+	if err != nil {
+		log.Printf("刷新设备 %s 相机 %s 的直播Token失败: %v", s.SN, s.CameraIndex, err)
+		return
+	}
+	s.Playback, s.RefreshedAt = playback, time.Now()
+}
+
+// serviceContext 以会话所属租户的服务凭据构造后台调用的上下文，超时1分钟
+func (m *Manager) serviceContext(s *session) (context.Context, context.CancelFunc, error) {
+	info, err := m.opts.Credentials.Service(s.tenant)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithTimeout(tenant.WithTenant(context.Background(), info), time.Minute)
+	return ctx, cancel, nil
+}
@@ -0,0 +1,190 @@
+package livestream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+)
+
+// fakeStreamer 记录开启与停止直播使用的令牌，每次开启返回新的推流Token，30分钟后到期
+type fakeStreamer struct {
+	mu     sync.Mutex
+	starts []string
+	stops  []string
+}
+
+func tokenOf(ctx context.Context) string {
+	info, err := tenant.GetTenantFromContext(ctx)
+	if err != nil {
+		return ""
+	}
+	return info.UserToken
+}
+
+func (f *fakeStreamer) LiveStreamStart(ctx context.Context, payLoad io.Reader) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.starts = append(f.starts, tokenOf(ctx))
+	expire := time.Now().Add(30 * time.Minute).Unix()
+	return fmt.Sprintf(`{"code":0,"data":{"url":"rtmp://live.example.com/live/SN1","url_type":"rtmp","token":"push-%d","expire_ts":%d}}`, len(f.starts), expire), nil
+}
+
+func (f *fakeStreamer) LiveStreamStop(ctx context.Context, deviceSn, cameraIndex string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stops = append(f.stops, tokenOf(ctx))
+	return nil
+}
+
+func (f *fakeStreamer) calls() ([]string, []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.starts...), append([]string(nil), f.stops...)
+}
+
+// eventually 在超时前轮询直到 done 返回 true
+func eventually(t *testing.T, name string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待%s超时", name)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// newTestManager 观众超时设为1小时，后台巡检不介入，由测试调用 sweep
+func newTestManager(t *testing.T, credentials tenant.ServiceTokens) *Manager {
+	t.Helper()
+	m := NewManager(Options{ViewerTimeout: time.Hour, IdleTimeout: 5 * time.Minute, RefreshBefore: 5 * time.Minute, Credentials: credentials})
+	t.Cleanup(m.Close)
+	return m
+}
+
+func viewer(token string) context.Context {
+	return tenant.WithTenant(context.Background(), tenant.NewTenantInfo(1, token, "project"))
+}
+
+// TestSharedSession 同一设备相机的两位观众共用一路直播，离开后会话保留到空闲超时，之后以服务凭据停止推流
+func TestSharedSession(t *testing.T) {
+	m := newTestManager(t, tenant.ServiceTokens{1: "service-1"})
+	streamer := &fakeStreamer{}
+	req := Request{SN: "SN1", CameraIndex: "81-0-0"}
+
+	first, err := m.Join(viewer("user-a"), streamer, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := m.Join(viewer("user-b"), streamer, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Session.ID != second.Session.ID || second.Session.Viewers != 2 || first.ViewerID == second.ViewerID {
+		t.Errorf("两位观众应加入同一会话: %+v / %+v", first, second)
+	}
+	if starts, _ := streamer.calls(); len(starts) != 1 || starts[0] != "user-a" {
+		t.Errorf("只应由首位观众开启一次直播: %v", starts)
+	}
+	if other, err := m.Join(viewer("user-c"), streamer, Request{SN: "SN1", CameraIndex: "39-0-7"}); err != nil || other.Session.ID == first.Session.ID {
+		t.Errorf("其他相机应开启新的会话: %+v, %v", other, err)
+	}
+
+	id := first.Session.ID
+	if err := m.Leave(id, first.ViewerID); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := m.Heartbeat(id, second.ViewerID); s.Viewers != 1 || !s.IdleSince.IsZero() {
+		t.Errorf("仍有观众时不应空闲: %+v", s)
+	}
+	if err := m.Leave(id, second.ViewerID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Heartbeat(id, second.ViewerID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("离开的观众心跳应返回 ErrNotFound: %v", err)
+	}
+
+	now := time.Now()
+	m.sweep(now.Add(4 * time.Minute))
+	if _, ok := m.Get(id); !ok {
+		t.Fatal("空闲超时前会话应保留")
+	}
+	m.sweep(now.Add(6 * time.Minute))
+	if _, ok := m.Get(id); ok {
+		t.Error("空闲超时后会话应结束")
+	}
+	eventually(t, "停止推流", func() bool {
+		_, stops := streamer.calls()
+		return len(stops) == 1
+	})
+	if _, stops := streamer.calls(); stops[0] != "service-1" {
+		t.Errorf("停止推流应使用服务凭据，实际 %s", stops[0])
+	}
+
+	// 会话结束后再次观看重新开启直播
+	again, err := m.Join(viewer("user-b"), streamer, req)
+	if err != nil || again.Session.ID == id {
+		t.Errorf("会话结束后应开启新的会话: %+v, %v", again, err)
+	}
+}
+
+// TestRefreshBeforeExpiry 仍有观众时在Token到期前以服务凭据重新开启，心跳返回新的播放信息；未配置服务凭据的租户不刷新
+func TestRefreshBeforeExpiry(t *testing.T) {
+	m := newTestManager(t, tenant.ServiceTokens{1: "service-1"})
+	streamer := &fakeStreamer{}
+	ticket, err := m.Join(viewer("user-a"), streamer, Request{SN: "SN1", CameraIndex: "81-0-0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.Session.Playback.Token != "push-1" || ticket.Session.Playback.ExpireAt.IsZero() {
+		t.Fatalf("播放信息 %+v", ticket.Session.Playback)
+	}
+
+	m.sweep(time.Now().Add(20 * time.Minute))
+	if starts, _ := streamer.calls(); len(starts) != 1 {
+		t.Errorf("距到期超过 refresh_before 时不应刷新: %v", starts)
+	}
+
+	m.sweep(time.Now().Add(26 * time.Minute))
+	eventually(t, "刷新Token", func() bool {
+		s, err := m.Heartbeat(ticket.Session.ID, ticket.ViewerID)
+		return err == nil && s.Playback.Token == "push-2"
+	})
+	if starts, _ := streamer.calls(); len(starts) != 2 || starts[1] != "service-1" {
+		t.Errorf("刷新应使用服务凭据而不是首位观众的令牌: %v", starts)
+	}
+	if s, _ := m.Get(ticket.Session.ID); !s.RefreshedAt.After(s.StartedAt) {
+		t.Errorf("刷新后应更新 RefreshedAt: %+v", s)
+	}
+
+	// 未配置服务凭据：不刷新，Token到期后会话结束
+	unconfigured := newTestManager(t, nil)
+	other := &fakeStreamer{}
+	ticket, err = unconfigured.Join(viewer("user-a"), other, Request{SN: "SN1", CameraIndex: "81-0-0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unconfigured.sweep(time.Now().Add(26 * time.Minute))
+	eventually(t, "刷新结束", func() bool {
+		unconfigured.mu.Lock()
+		defer unconfigured.mu.Unlock()
+		return !unconfigured.sessions[ticket.Session.ID].refreshing
+	})
+	if starts, _ := other.calls(); len(starts) != 1 {
+		t.Errorf("未配置服务凭据时不应以用户令牌刷新: %v", starts)
+	}
+	unconfigured.sweep(time.Now().Add(31 * time.Minute))
+	if _, ok := unconfigured.Get(ticket.Session.ID); ok {
+		t.Error("Token到期后会话应结束")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, stops := other.calls(); len(stops) != 0 {
+		t.Errorf("未配置服务凭据时不应以用户令牌停止推流: %v", stops)
+	}
+}
@@ -0,0 +1,150 @@
+// Package livestream 直播会话管理
+// 同一设备相机的多位观众共用一路直播，观众以心跳保活；仍有观众时在推流Token到期前重新开启以刷新鉴权信息，
+// 最后一位观众离开一段时间后停止（司空2在无人拉流5分钟后自行停止推流）。播放信息与厂商无关，按模板由RTMP地址推导 HLS、WebRTC 地址。
+package livestream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 播放协议
+const (
+	ProtocolRTMP   = "rtmp"
+	ProtocolWebRTC = "webrtc"
+	ProtocolHLS    = "hls"
+	ProtocolAgora  = "agora" // 声网，需使用 Channel、Token、UID 通过 SDK 拉流
+)
+
+// Playback 与厂商无关的播放信息
+type Playback struct {
+	Protocol  string    `json:"protocol"` // 厂商返回地址的协议
+	URL       string    `json:"url"`      // 厂商返回的地址
+	RtmpURL   string    `json:"rtmp_url,omitempty"`
+	HlsURL    string    `json:"hls_url,omitempty"`
+	WebRTCURL string    `json:"webrtc_url,omitempty"`
+	Token     string    `json:"token,omitempty"`
+	Channel   string    `json:"channel,omitempty"`
+	UID       int64     `json:"uid,omitempty"`
+	ExpireAt  time.Time `json:"expire_at,omitempty"` // 推流Token到期时间，零值表示不过期
+}
+
+// URLTemplates 播放地址模板，{host}、{app}、{stream} 替换为RTMP地址中的主机、应用名与流名，为空时不提供该协议
+type URLTemplates struct {
+	HLS    string
+	WebRTC string
+}
+
+// liveInfo LiveStreamStart 返回的鉴权信息（司空2或机场2直连）
+type liveInfo struct {
+	URL      string          `json:"url"`
+	URLType  string          `json:"url_type"`
+	Token    string          `json:"token"`
+	Channel  string          `json:"channel"`
+	UID      json.RawMessage `json:"uid"`
+	ExpireTs int64           `json:"expire_ts"`
+}
+
+// ParsePlayback 解析 LiveStreamStart 的返回，兼容司空2 {"code":0,"data":{...}} 与机场2直连的 {...}
+func ParsePlayback(resp string, templates URLTemplates) (Playback, error) {
+	var body struct {
+		Data *liveInfo `json:"data"`
+		liveInfo
+	}
+	if err := json.Unmarshal([]byte(resp), &body); err != nil {
+		return Playback{}, fmt.Errorf("解析直播信息失败: %w", err)
+	}
+	info := body.liveInfo
+	if body.Data != nil {
+		info = *body.Data
+	}
+	if info.URL == "" && info.Channel == "" {
+		return Playback{}, fmt.Errorf("直播信息缺少地址")
+	}
+	p := Playback{Protocol: protocolOf(info.URLType, info.URL), URL: info.URL, Token: info.Token, Channel: info.Channel}
+	// uid 可能为数字或字符串
+	if len(info.UID) > 0 {
+		var uid json.Number
+		if json.Unmarshal(info.UID, &uid) == nil {
+			p.UID, _ = uid.Int64()
+		} else {
+			var text string
+			if json.Unmarshal(info.UID, &text) == nil {
+				p.UID, _ = json.Number(text).Int64()
+			}
+		}
+	}
+	if info.ExpireTs > 0 {
+		p.ExpireAt = time.Unix(info.ExpireTs, 0)
+	}
+	templates.fill(&p)
+	return p, nil
+}
+
+// PlaybackFromURL 由推流地址生成播放信息，用于只返回地址的适配器（例如机场2直连的 GetLiveStreamURL）
+func PlaybackFromURL(rawURL string, templates URLTemplates) Playback {
+	p := Playback{Protocol: protocolOf("", rawURL), URL: rawURL}
+	templates.fill(&p)
+	return p
+}
+
+// protocolOf 按厂商返回的 url_type 判断协议，未给出时按地址判断
+func protocolOf(urlType, rawURL string) string {
+	switch strings.ToLower(urlType) {
+	case "rtmp":
+		return ProtocolRTMP
+	case "agora":
+		return ProtocolAgora
+	case "webrtc", "whip", "whep":
+		return ProtocolWebRTC
+	case "hls", "m3u8":
+		return ProtocolHLS
+	case "":
+	default:
+		return strings.ToLower(urlType)
+	}
+	lower := strings.ToLower(rawURL)
+	switch {
+	case strings.HasPrefix(lower, "rtmp://"), strings.HasPrefix(lower, "rtmps://"):
+		return ProtocolRTMP
+	case strings.HasPrefix(lower, "webrtc://"):
+		return ProtocolWebRTC
+	case strings.Contains(lower, ".m3u8"):
+		return ProtocolHLS
+	}
+	return ""
+}
+
+// fill 按协议填写对应地址，RTMP地址再按模板推导 HLS、WebRTC 地址
+func (t URLTemplates) fill(p *Playback) {
+	switch p.Protocol {
+	case ProtocolHLS:
+		p.HlsURL = p.URL
+		return
+	case ProtocolWebRTC:
+		p.WebRTCURL = p.URL
+		return
+	case ProtocolRTMP:
+		p.RtmpURL = p.URL
+	default:
+		return
+	}
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return
+	}
+	app, stream, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+	if stream == "" {
+		return
+	}
+	replacer := strings.NewReplacer("{host}", u.Hostname(), "{app}", app, "{stream}", stream)
+	if t.HLS != "" {
+		p.HlsURL = replacer.Replace(t.HLS)
+	}
+	if t.WebRTC != "" {
+		p.WebRTCURL = replacer.Replace(t.WebRTC)
+	}
+}
@@ -0,0 +1,124 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+	"gitee.com/jamespi/drone_dispatch/pkg/objectstore"
+)
+
+// defaultDir 未配置存储目录时使用的目录
+const defaultDir = "./media"
+
+var (
+	defaultMu       sync.RWMutex
+	defaultPipeline *Pipeline
+)
+
+// Default 全局归档流水线，未配置或未启用媒体归档时为nil
+func Default() *Pipeline {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultPipeline
+}
+
+// TrackTask 由全局归档流水线登记任务，任务结束后归档其媒体；未启用媒体归档时忽略
+func TrackTask(ctx context.Context, source Source, taskUUID string) {
+	if p := Default(); p != nil {
+		p.Track(ctx, source, taskUUID)
+	}
+}
+
+// ApplyConfig 按配置文件 Media 段创建全局归档流水线，已登记的任务由新流水线接管；未配置或未启用时停止自动归档
+func ApplyConfig(cfg *config.Media) error {
+	var next *Pipeline
+	if cfg != nil && (cfg.Enabled == nil || *cfg.Enabled) {
+		p, err := NewPipelineFromConfig(cfg)
+		if err != nil {
+			return err
+		}
+		next = p
+	}
+
+	defaultMu.Lock()
+	prev := defaultPipeline
+	// 索引文件不变时沿用内存索引，使进行中的归档写入的记录对新流水线可见
+	if prev != nil && next != nil && prev.opts.Index.file == next.opts.Index.file {
+		next.opts.Index = prev.opts.Index
+	}
+	defaultPipeline = next
+	defaultMu.Unlock()
+	if prev != nil {
+		prev.Stop()
+		if next != nil {
+			next.adopt(prev)
+		}
+	}
+	if next != nil {
+		next.Start()
+	}
+	return nil
+}
+
+// NewPipelineFromConfig 按配置创建归档流水线（未启动），命令行工具手动归档与检索时使用
+func NewPipelineFromConfig(cfg *config.Media) (*Pipeline, error) {
+	if cfg == nil {
+		cfg = &config.Media{}
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir = defaultDir
+	}
+	workDir := cfg.WorkDir
+	if workDir == "" {
+		workDir = filepath.Join(dir, ".download")
+	}
+	indexFile := cfg.IndexFile
+	if indexFile == "" {
+		indexFile = filepath.Join(dir, "index.jsonl")
+	}
+
+	var backend Backend
+	switch strings.ToLower(cfg.Storage) {
+	case "", "local":
+		backend = NewLocalBackend(dir)
+	case "s3":
+		if cfg.S3 == nil {
+			return nil, fmt.Errorf("媒体存储为 s3 但未配置 Media.s3")
+		}
+		client, err := objectstore.NewClient(&objectstore.STSToken{
+			Endpoint: cfg.S3.Endpoint,
+			Bucket:   cfg.S3.Bucket,
+			Region:   cfg.S3.Region,
+			Provider: cfg.S3.Provider,
+			Credentials: objectstore.Credentials{
+				AccessKeyID:     cfg.S3.AccessKeyID,
+				AccessKeySecret: cfg.S3.AccessKeySecret,
+			},
+		}, httpclient.NewSecureHTTPClient())
+		if err != nil {
+			return nil, fmt.Errorf("媒体存储配置错误: %w", err)
+		}
+		backend = NewS3Backend(client, cfg.S3.Prefix)
+	default:
+		return nil, fmt.Errorf("不支持的媒体存储: %s", cfg.Storage)
+	}
+
+	index, err := OpenIndex(indexFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewPipeline(Options{
+		Backend:     backend,
+		Index:       index,
+		WorkDir:     workDir,
+		Concurrency: cfg.Concurrency,
+		Delay:       time.Duration(cfg.Delay) * time.Second,
+	}), nil
+}
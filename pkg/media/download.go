@@ -0,0 +1,159 @@
+package media
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+)
+
+// ErrChecksum 下载内容与媒体列表中的大小或MD5不一致
+var ErrChecksum = errors.New("媒体文件校验失败")
+
+// Downloader 媒体下载器：下载到工作目录中的 .part 文件，中断后按已下载长度以 Range 请求续传，
+// 完成后校验大小与MD5；没有进展的连续失败达到 Retries 次时放弃，下次下载同一文件继续续传
+type Downloader struct {
+	client  *httpclient.SecureHTTPClient
+	dir     string
+	Retries int
+}
+
+// NewDownloader 创建下载器，dir 为下载工作目录
+func NewDownloader(client *httpclient.SecureHTTPClient, dir string) *Downloader {
+	return &Downloader{client: client, dir: dir, Retries: 3}
+}
+
+// Download 下载文件，返回本地路径与MD5（十六进制）；调用方处理完成后删除该文件
+func (d *Downloader) Download(ctx context.Context, f File) (string, string, error) {
+	link := f.Link()
+	if link == "" {
+		return "", "", fmt.Errorf("%s 没有下载地址", f.Name)
+	}
+	if err := os.MkdirAll(d.dir, 0o755); err != nil {
+		return "", "", fmt.Errorf("创建下载目录失败: %w", err)
+	}
+	path := d.partPath(f)
+
+	failures := 0
+	backoff := time.Second
+	for {
+		before := fileSize(path)
+		err := d.fetch(ctx, link, path, f.Size)
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return "", "", ctx.Err()
+		}
+		if fileSize(path) > before {
+			failures, backoff = 0, time.Second
+		} else {
+			failures++
+			if failures > d.Retries {
+				return "", "", fmt.Errorf("下载 %s 失败: %w", f.Name, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return "", "", ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	digest, size, err := fileMD5(path)
+	if err != nil {
+		return "", "", err
+	}
+	if (f.Size > 0 && size != f.Size) || (f.Fingerprint != "" && !strings.EqualFold(digest, f.Fingerprint)) {
+		os.Remove(path)
+		return "", "", fmt.Errorf("%w: %s 大小 %d/%d，MD5 %s/%s", ErrChecksum, f.Name, size, f.Size, digest, f.Fingerprint)
+	}
+	return path, digest, nil
+}
+
+// partPath 文件在下载工作目录中的 .part 路径，同一文件多次下载使用同一路径以便续传
+func (d *Downloader) partPath(f File) string {
+	sum := sha1.Sum([]byte(f.ID() + "\x00" + f.Name))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:8])+"-"+safeName(f.Name)+".part")
+}
+
+// fetch 从 .part 文件末尾续传；服务端不支持 Range 时从头下载
+func (d *Downloader) fetch(ctx context.Context, link, path string, size int64) error {
+	offset := fileSize(path)
+	if size > 0 && offset == size {
+		return nil
+	}
+	if size > 0 && offset > size {
+		os.Remove(path)
+		offset = 0
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return fmt.Errorf("创建下载请求失败: %w", err)
+	}
+	req.Header.Set("User-Agent", "DroneDispatch/1.0")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// 本地文件已完整或与服务端不一致，交由校验判断
+		return nil
+	default:
+		return fmt.Errorf("下载失败: %s", resp.Status)
+	}
+	out, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return fmt.Errorf("打开下载文件失败: %w", err)
+	}
+	_, copyErr := io.Copy(out, resp.Body)
+	closeErr := out.Close()
+	if copyErr != nil {
+		return fmt.Errorf("下载中断: %w", copyErr)
+	}
+	return closeErr
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+func fileMD5(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("打开下载文件失败: %w", err)
+	}
+	defer file.Close()
+	h := md5.New()
+	n, err := io.Copy(h, file)
+	if err != nil {
+		return "", 0, fmt.Errorf("读取下载文件失败: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
@@ -0,0 +1,136 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+)
+
+// mediaServer 提供媒体文件下载，记录每次请求的 Range 头；rangeSupport 为 false 时忽略 Range 返回完整内容
+type mediaServer struct {
+	content      []byte
+	rangeSupport bool
+
+	mu     sync.Mutex
+	ranges []string
+}
+
+func (s *mediaServer) start(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.ranges = append(s.ranges, r.Header.Get("Range"))
+		s.mu.Unlock()
+		if !s.rangeSupport {
+			r.Header.Del("Range")
+		}
+		http.ServeContent(w, r, "media", time.Time{}, bytes.NewReader(s.content))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *mediaServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ranges...)
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// TestDownloadResume 已有 .part 文件时以 Range 请求续传，服务端不支持 Range 时从头下载，完成后校验MD5
+func TestDownloadResume(t *testing.T) {
+	content := bytes.Repeat([]byte("DJI_20240501102030_0001_W.JPG "), 1000)
+	tests := []struct {
+		name         string
+		rangeSupport bool
+		wantRange    string
+	}{
+		{"续传", true, "bytes=12000-"},
+		{"不支持Range时从头下载", false, "bytes=12000-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			media := &mediaServer{content: content, rangeSupport: tt.rangeSupport}
+			server := media.start(t)
+			d := NewDownloader(httpclient.NewSecureHTTPClient(), t.TempDir())
+			f := File{UUID: "m1", Name: "DJI_0001.JPG", URL: server.URL + "/m1", Size: int64(len(content)), Fingerprint: strings.ToUpper(md5Hex(content))}
+
+			if err := os.MkdirAll(d.dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			// 中断的下载：.part 中已有前 12000 字节，服务端不支持 Range 时被覆盖
+			partial := append([]byte(nil), content[:12000]...)
+			if !tt.rangeSupport {
+				partial = bytes.Repeat([]byte{'x'}, 12000)
+			}
+			if err := os.WriteFile(d.partPath(f), partial, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			path, digest, err := d.Download(context.Background(), f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := media.requests(); len(got) != 1 || got[0] != tt.wantRange {
+				t.Errorf("请求的 Range %v，应为 %s", got, tt.wantRange)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, content) || digest != md5Hex(content) {
+				t.Errorf("下载内容 %d 字节，MD5 %s", len(data), digest)
+			}
+
+			// 已完整下载的 .part 不再请求
+			if _, _, err := d.Download(context.Background(), f); err != nil || len(media.requests()) != 1 {
+				t.Errorf("已完整的文件不应重新请求: %v, %v", media.requests(), err)
+			}
+		})
+	}
+}
+
+// TestDownloadChecksum 大小或MD5与媒体列表不一致时返回 ErrChecksum 并删除 .part 文件，下次从头下载
+func TestDownloadChecksum(t *testing.T) {
+	content := []byte("corrupted media content")
+	server := (&mediaServer{content: content, rangeSupport: true}).start(t)
+	d := NewDownloader(httpclient.NewSecureHTTPClient(), t.TempDir())
+
+	tests := []struct {
+		name string
+		file File
+	}{
+		{"MD5不一致", File{UUID: "md5", Name: "a.jpg", URL: server.URL, Size: int64(len(content)), Fingerprint: md5Hex([]byte("original"))}},
+		{"大小不一致", File{UUID: "size", Name: "b.jpg", URL: server.URL, Size: int64(len(content)) - 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := d.Download(context.Background(), tt.file)
+			if !errors.Is(err, ErrChecksum) {
+				t.Fatalf("应返回 ErrChecksum: %v", err)
+			}
+			if _, err := os.Stat(d.partPath(tt.file)); !os.IsNotExist(err) {
+				t.Error("校验失败后应删除 .part 文件")
+			}
+		})
+	}
+
+	if _, _, err := d.Download(context.Background(), File{Name: "c.jpg"}); err == nil {
+		t.Error("没有下载地址时应返回错误")
+	}
+}
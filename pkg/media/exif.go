@@ -0,0 +1,324 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+// ErrNotJPEG 文件不是 JPEG，无法提取元数据
+var ErrNotJPEG = errors.New("不是JPEG文件")
+
+// JPEG APP1 段标识
+var (
+	exifHeader = []byte("Exif\x00\x00")
+	xmpHeader  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+)
+
+// TIFF 标签
+const (
+	tagMake               = 0x010F
+	tagModel              = 0x0110
+	tagExifIFD            = 0x8769
+	tagGPSIFD             = 0x8825
+	tagDateTimeOriginal   = 0x9003
+	tagOffsetTimeOriginal = 0x9011
+	tagGPSLatitudeRef     = 0x0001
+	tagGPSLatitude        = 0x0002
+	tagGPSLongitudeRef    = 0x0003
+	tagGPSLongitude       = 0x0004
+	tagGPSAltitudeRef     = 0x0005
+	tagGPSAltitude        = 0x0006
+)
+
+// tiffTypeSize TIFF 数据类型的字节数
+var tiffTypeSize = map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 7: 1, 9: 4, 10: 8}
+
+// ReadMetadata 读取 JPEG 的 EXIF 与 XMP 元数据，只读取到图像数据之前
+func ReadMetadata(r io.Reader) (Metadata, error) {
+	var m Metadata
+	br := bufio.NewReader(r)
+	var soi [2]byte
+	if _, err := io.ReadFull(br, soi[:]); err != nil || soi[0] != 0xFF || soi[1] != 0xD8 {
+		return m, ErrNotJPEG
+	}
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return m, fmt.Errorf("读取JPEG失败: %w", err)
+		}
+		if b != 0xFF {
+			return m, fmt.Errorf("JPEG段格式错误")
+		}
+		marker := byte(0xFF)
+		for marker == 0xFF {
+			if marker, err = br.ReadByte(); err != nil {
+				return m, fmt.Errorf("读取JPEG失败: %w", err)
+			}
+		}
+		switch {
+		case marker == 0xDA || marker == 0xD9: // 图像数据开始或文件结束
+			return m, nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			continue
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(br, length[:]); err != nil {
+			return m, fmt.Errorf("读取JPEG失败: %w", err)
+		}
+		size := int(binary.BigEndian.Uint16(length[:])) - 2
+		if size < 0 {
+			return m, fmt.Errorf("JPEG段长度错误")
+		}
+		if marker != 0xE1 {
+			if _, err := br.Discard(size); err != nil {
+				return m, fmt.Errorf("读取JPEG失败: %w", err)
+			}
+			continue
+		}
+		segment := make([]byte, size)
+		if _, err := io.ReadFull(br, segment); err != nil {
+			return m, fmt.Errorf("读取JPEG失败: %w", err)
+		}
+		switch {
+		case bytes.HasPrefix(segment, exifHeader):
+			if err := parseExif(segment[len(exifHeader):], &m); err != nil {
+				return m, err
+			}
+		case bytes.HasPrefix(segment, xmpHeader):
+			parseXMP(string(segment[len(xmpHeader):]), &m)
+		}
+	}
+}
+
+// tiffEntry IFD 条目，value 为已定位的值数据
+type tiffEntry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// ifd 读取偏移处的 IFD
+func (t tiffReader) ifd(offset uint32) (map[uint16]tiffEntry, error) {
+	if uint64(offset)+2 > uint64(len(t.data)) {
+		return nil, fmt.Errorf("EXIF IFD偏移越界")
+	}
+	count := uint32(t.order.Uint16(t.data[offset:]))
+	if uint64(offset)+2+uint64(count)*12 > uint64(len(t.data)) {
+		return nil, fmt.Errorf("EXIF IFD长度越界")
+	}
+	entries := make(map[uint16]tiffEntry, count)
+	for i := uint32(0); i < count; i++ {
+		raw := t.data[offset+2+i*12:]
+		e := tiffEntry{typ: t.order.Uint16(raw[2:]), count: t.order.Uint32(raw[4:])}
+		unit, known := tiffTypeSize[e.typ]
+		if !known {
+			continue
+		}
+		size := uint64(unit) * uint64(e.count)
+		if size <= 4 {
+			e.value = raw[8 : 8+size]
+		} else {
+			start := uint64(t.order.Uint32(raw[8:]))
+			if start+size > uint64(len(t.data)) {
+				continue
+			}
+			e.value = t.data[start : start+size]
+		}
+		entries[t.order.Uint16(raw)] = e
+	}
+	return entries, nil
+}
+
+func (t tiffReader) ascii(e tiffEntry) string {
+	if e.typ != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(e.value), "\x00"))
+}
+
+func (t tiffReader) uint(e tiffEntry) (uint32, bool) {
+	switch {
+	case e.typ == 1 && len(e.value) >= 1:
+		return uint32(e.value[0]), true
+	case e.typ == 3 && len(e.value) >= 2:
+		return uint32(t.order.Uint16(e.value)), true
+	case e.typ == 4 && len(e.value) >= 4:
+		return t.order.Uint32(e.value), true
+	}
+	return 0, false
+}
+
+func (t tiffReader) rationals(e tiffEntry) []float64 {
+	if e.typ != 5 && e.typ != 10 {
+		return nil
+	}
+	values := make([]float64, 0, e.count)
+	for i := 0; i+8 <= len(e.value); i += 8 {
+		num, den := t.order.Uint32(e.value[i:]), t.order.Uint32(e.value[i+4:])
+		if den == 0 {
+			return nil
+		}
+		if e.typ == 10 {
+			values = append(values, float64(int32(num))/float64(int32(den)))
+		} else {
+			values = append(values, float64(num)/float64(den))
+		}
+	}
+	return values
+}
+
+// parseExif 解析 EXIF（TIFF）中的相机型号、拍摄时间与GPS
+func parseExif(data []byte, m *Metadata) error {
+	if len(data) < 8 {
+		return fmt.Errorf("EXIF长度不足")
+	}
+	t := tiffReader{data: data}
+	switch string(data[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return fmt.Errorf("EXIF字节序错误")
+	}
+	ifd0, err := t.ifd(t.order.Uint32(data[4:]))
+	if err != nil {
+		return err
+	}
+	m.Make = t.ascii(ifd0[tagMake])
+	m.Model = t.ascii(ifd0[tagModel])
+
+	if e, ok := ifd0[tagExifIFD]; ok {
+		if offset, ok := t.uint(e); ok {
+			if exif, err := t.ifd(offset); err == nil {
+				m.CapturedAt = exifTime(t.ascii(exif[tagDateTimeOriginal]), t.ascii(exif[tagOffsetTimeOriginal]))
+			}
+		}
+	}
+
+	e, ok := ifd0[tagGPSIFD]
+	if !ok {
+		return nil
+	}
+	offset, ok := t.uint(e)
+	if !ok {
+		return nil
+	}
+	gps, err := t.ifd(offset)
+	if err != nil {
+		return nil
+	}
+	lat, latOK := dms(t.rationals(gps[tagGPSLatitude]), t.ascii(gps[tagGPSLatitudeRef]), "S")
+	lng, lngOK := dms(t.rationals(gps[tagGPSLongitude]), t.ascii(gps[tagGPSLongitudeRef]), "W")
+	if latOK && lngOK && geo.ValidateLatLng(lat, lng) == nil && (lat != 0 || lng != 0) {
+		m.Location = &geo.Point{Lat: lat, Lng: lng}
+	}
+	if alt := t.rationals(gps[tagGPSAltitude]); len(alt) == 1 {
+		value := alt[0]
+		if ref, ok := t.uint(gps[tagGPSAltitudeRef]); ok && ref == 1 {
+			value = -value
+		}
+		m.AbsAltitude = &value
+	}
+	return nil
+}
+
+// dms 度分秒转十进制度，ref 为 negative 时取负
+func dms(values []float64, ref, negative string) (float64, bool) {
+	if len(values) != 3 {
+		return 0, false
+	}
+	deg := values[0] + values[1]/60 + values[2]/3600
+	if strings.EqualFold(ref, negative) {
+		deg = -deg
+	}
+	return deg, !math.IsNaN(deg)
+}
+
+// exifTime 解析 EXIF 时间，无时区偏移时按本地时区
+func exifTime(value, offset string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	if offset != "" {
+		if t, err := time.Parse("2006:01:02 15:04:05-07:00", value+offset); err == nil {
+			return t
+		}
+	}
+	t, err := time.ParseInLocation("2006:01:02 15:04:05", value, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// parseXMP 解析大疆 XMP（drone-dji 命名空间）中的高度、云台与飞行器姿态，XMP 中的坐标优先于 EXIF
+func parseXMP(xmp string, m *Metadata) {
+	fields := []struct {
+		name   string
+		target **float64
+	}{
+		{"AbsoluteAltitude", &m.AbsAltitude},
+		{"RelativeAltitude", &m.RelAltitude},
+		{"GimbalPitchDegree", &m.GimbalPitch},
+		{"GimbalYawDegree", &m.GimbalYaw},
+		{"GimbalRollDegree", &m.GimbalRoll},
+		{"FlightYawDegree", &m.FlightYaw},
+	}
+	for _, f := range fields {
+		if v, ok := xmpFloat(xmp, f.name); ok {
+			*f.target = &v
+		}
+	}
+	lat, latOK := xmpFloat(xmp, "GpsLatitude")
+	lng, lngOK := xmpFloat(xmp, "GpsLongitude")
+	if !lngOK {
+		// 部分机型写作 GpsLongtitude
+		lng, lngOK = xmpFloat(xmp, "GpsLongtitude")
+	}
+	if latOK && lngOK && geo.ValidateLatLng(lat, lng) == nil && (lat != 0 || lng != 0) {
+		m.Location = &geo.Point{Lat: lat, Lng: lng}
+	}
+}
+
+// xmpFloat 读取 drone-dji 属性，兼容属性与元素两种写法
+func xmpFloat(xmp, name string) (float64, bool) {
+	var raw string
+	if i := strings.Index(xmp, "drone-dji:"+name+"=\""); i >= 0 {
+		rest := xmp[i+len("drone-dji:"+name+"=\""):]
+		end := strings.IndexByte(rest, '"')
+		if end < 0 {
+			return 0, false
+		}
+		raw = rest[:end]
+	} else if i := strings.Index(xmp, "<drone-dji:"+name+">"); i >= 0 {
+		rest := xmp[i+len("<drone-dji:"+name+">"):]
+		end := strings.IndexByte(rest, '<')
+		if end < 0 {
+			return 0, false
+		}
+		raw = rest[:end]
+	} else {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,151 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// tiffTag 测试用 IFD 条目，data 为按小端序编码的值
+type tiffTag struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	data  []byte
+}
+
+func asciiTag(tag uint16, s string) tiffTag {
+	return tiffTag{tag, 2, uint32(len(s) + 1), append([]byte(s), 0)}
+}
+
+func longTag(tag uint16, v uint32) tiffTag {
+	return tiffTag{tag, 4, 1, binary.LittleEndian.AppendUint32(nil, v)}
+}
+
+// rationalTag 无符号分数，values 依次为分子、分母
+func rationalTag(tag uint16, values ...uint32) tiffTag {
+	var data []byte
+	for _, v := range values {
+		data = binary.LittleEndian.AppendUint32(data, v)
+	}
+	return tiffTag{tag, 5, uint32(len(values) / 2), data}
+}
+
+// encodeIFD 编码位于 offset 的 IFD，超过4字节的值紧随其后
+func encodeIFD(offset uint32, tags []tiffTag) []byte {
+	extra := offset + 2 + uint32(len(tags))*12 + 4
+	var ifd, values []byte
+	ifd = binary.LittleEndian.AppendUint16(ifd, uint16(len(tags)))
+	for _, tag := range tags {
+		ifd = binary.LittleEndian.AppendUint16(ifd, tag.tag)
+		ifd = binary.LittleEndian.AppendUint16(ifd, tag.typ)
+		ifd = binary.LittleEndian.AppendUint32(ifd, tag.count)
+		if len(tag.data) <= 4 {
+			ifd = append(ifd, tag.data...)
+			ifd = append(ifd, make([]byte, 4-len(tag.data))...)
+			continue
+		}
+		ifd = binary.LittleEndian.AppendUint32(ifd, extra+uint32(len(values)))
+		values = append(values, tag.data...)
+	}
+	ifd = binary.LittleEndian.AppendUint32(ifd, 0)
+	return append(ifd, values...)
+}
+
+// buildExif 小端序 TIFF：IFD0 相机型号，Exif IFD 拍摄时间，GPS IFD 位置与海拔
+func buildExif(exif, gps []tiffTag) []byte {
+	ifd0 := func(exifOffset, gpsOffset uint32) []byte {
+		return encodeIFD(8, []tiffTag{
+			asciiTag(tagMake, "DJI"),
+			asciiTag(tagModel, "M3TD"),
+			longTag(tagExifIFD, exifOffset),
+			longTag(tagGPSIFD, gpsOffset),
+		})
+	}
+	exifOffset := 8 + uint32(len(ifd0(0, 0)))
+	exifIFD := encodeIFD(exifOffset, exif)
+	gpsOffset := exifOffset + uint32(len(exifIFD))
+	data := []byte("II\x2A\x00\x08\x00\x00\x00")
+	data = append(data, ifd0(exifOffset, gpsOffset)...)
+	data = append(data, exifIFD...)
+	return append(data, encodeIFD(gpsOffset, gps)...)
+}
+
+// buildJPEG 按顺序写入 APP1 段，之后是图像数据
+func buildJPEG(segments ...[]byte) []byte {
+	data := []byte{0xFF, 0xD8}
+	// 非 APP1 段应被跳过
+	data = append(data, 0xFF, 0xE0, 0x00, 0x04, 'J', 'F')
+	for _, segment := range segments {
+		data = append(data, 0xFF, 0xE1)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(segment)+2))
+		data = append(data, segment...)
+	}
+	return append(data, 0xFF, 0xDA, 0x00, 0x02, 0xDE, 0xAD, 0xFF, 0xD9)
+}
+
+// app1 带段标识的 APP1 数据
+func app1(header []byte, body []byte) []byte {
+	return append(append([]byte(nil), header...), body...)
+}
+
+func near(got *float64, want float64) bool {
+	return got != nil && math.Abs(*got-want) < 1e-6
+}
+
+// TestReadMetadata 读取 EXIF 中的相机、拍摄时间与GPS，XMP 中的高度与云台姿态，XMP 坐标优先
+func TestReadMetadata(t *testing.T) {
+	exif := app1(exifHeader, buildExif(
+		[]tiffTag{asciiTag(tagDateTimeOriginal, "2024:05:01 10:20:30"), asciiTag(tagOffsetTimeOriginal, "+08:00")},
+		[]tiffTag{
+			asciiTag(tagGPSLatitudeRef, "N"),
+			rationalTag(tagGPSLatitude, 22, 1, 30, 1, 1800, 100),
+			asciiTag(tagGPSLongitudeRef, "W"),
+			rationalTag(tagGPSLongitude, 114, 1, 3, 1, 0, 1),
+			{tagGPSAltitudeRef, 1, 1, []byte{0}},
+			rationalTag(tagGPSAltitude, 12345, 100),
+		},
+	))
+
+	m, err := ReadMetadata(bytes.NewReader(buildJPEG(exif)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Make != "DJI" || m.Model != "M3TD" {
+		t.Errorf("相机 %s %s", m.Make, m.Model)
+	}
+	if want := time.Date(2024, 5, 1, 2, 20, 30, 0, time.UTC); !m.CapturedAt.Equal(want) {
+		t.Errorf("拍摄时间 %s，应为 %s", m.CapturedAt, want)
+	}
+	if m.Location == nil || math.Abs(m.Location.Lat-22.505) > 1e-9 || math.Abs(m.Location.Lng+114.05) > 1e-9 {
+		t.Errorf("EXIF 位置 %+v，西经应为负值", m.Location)
+	}
+	if !near(m.AbsAltitude, 123.45) || m.GimbalPitch != nil {
+		t.Errorf("海拔 %v，云台 %v", m.AbsAltitude, m.GimbalPitch)
+	}
+
+	xmp := app1(xmpHeader, []byte(`<x:xmpmeta><rdf:Description drone-dji:GpsLatitude="22.6" drone-dji:GpsLongtitude="+114.1"
+		drone-dji:AbsoluteAltitude="+130.50" drone-dji:RelativeAltitude="+80.10" drone-dji:GimbalPitchDegree="-90.0">
+		<drone-dji:FlightYawDegree>45.5</drone-dji:FlightYawDegree><drone-dji:GimbalYawDegree>NaN</drone-dji:GimbalYawDegree>
+		</rdf:Description></x:xmpmeta>`))
+	m, err = ReadMetadata(bytes.NewReader(buildJPEG(exif, xmp)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Location == nil || m.Location.Lat != 22.6 || m.Location.Lng != 114.1 {
+		t.Errorf("XMP 坐标应优先: %+v", m.Location)
+	}
+	if !near(m.AbsAltitude, 130.5) || !near(m.RelAltitude, 80.1) || !near(m.GimbalPitch, -90) || !near(m.FlightYaw, 45.5) || m.GimbalYaw != nil {
+		t.Errorf("XMP 元数据 %+v", m)
+	}
+
+	if _, err := ReadMetadata(bytes.NewReader([]byte("\x89PNG\r\n"))); !errors.Is(err, ErrNotJPEG) {
+		t.Errorf("非JPEG文件应返回 ErrNotJPEG: %v", err)
+	}
+	if _, err := ReadMetadata(bytes.NewReader(buildJPEG(app1(exifHeader, []byte("XX\x2A\x00\x08\x00\x00\x00"))))); err == nil {
+		t.Error("EXIF字节序错误应返回错误")
+	}
+}
@@ -0,0 +1,237 @@
+package media
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+// Index 媒体索引，内存检索；指定文件时以 JSON Lines 追加持久化，打开时按顺序回放，同一文件以最后一条为准
+type Index struct {
+	mu      sync.RWMutex
+	records []Record
+	byID    map[string]int // key: 任务UUID/媒体UUID
+	file    string
+}
+
+// NewIndex 创建内存索引
+func NewIndex() *Index {
+	return &Index{byID: make(map[string]int)}
+}
+
+// OpenIndex 打开持久化索引，文件不存在时创建
+func OpenIndex(file string) (*Index, error) {
+	ix := NewIndex()
+	ix.file = file
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return ix, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开媒体索引失败: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("媒体索引第 %d 行格式错误: %w", line, err)
+		}
+		ix.put(r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取媒体索引失败: %w", err)
+	}
+	return ix, nil
+}
+
+func recordID(taskUUID, mediaUUID string) string {
+	return taskUUID + "/" + mediaUUID
+}
+
+func (ix *Index) put(r Record) {
+	id := recordID(r.TaskUUID, r.MediaUUID)
+	if i, exists := ix.byID[id]; exists {
+		ix.records[i] = r
+		return
+	}
+	ix.byID[id] = len(ix.records)
+	ix.records = append(ix.records, r)
+}
+
+// Add 添加或更新记录
+func (ix *Index) Add(r Record) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.file != "" {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("序列化媒体记录失败: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(ix.file), 0o755); err != nil {
+			return fmt.Errorf("写入媒体索引失败: %w", err)
+		}
+		f, err := os.OpenFile(ix.file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("写入媒体索引失败: %w", err)
+		}
+		_, err = f.Write(append(data, '\n'))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("写入媒体索引失败: %w", err)
+		}
+	}
+	ix.put(r)
+	return nil
+}
+
+// Get 按任务与媒体UUID查找记录
+func (ix *Index) Get(taskUUID, mediaUUID string) (Record, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	i, exists := ix.byID[recordID(taskUUID, mediaUUID)]
+	if !exists {
+		return Record{}, false
+	}
+	return ix.records[i], true
+}
+
+// Len 记录数
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.records)
+}
+
+// Bounds 经纬度范围
+type Bounds struct {
+	South float64 `json:"south"`
+	West  float64 `json:"west"`
+	North float64 `json:"north"`
+	East  float64 `json:"east"`
+}
+
+// Contains 点是否在范围内
+func (b Bounds) Contains(p geo.Point) bool {
+	return p.Lat >= b.South && p.Lat <= b.North && p.Lng >= b.West && p.Lng <= b.East
+}
+
+// ParsePoint 解析 "纬度,经度"
+func ParsePoint(value string) (geo.Point, error) {
+	values, err := parseFloats(value, 2)
+	if err != nil {
+		return geo.Point{}, fmt.Errorf("坐标格式应为 纬度,经度: %w", err)
+	}
+	if err := geo.ValidateLatLng(values[0], values[1]); err != nil {
+		return geo.Point{}, err
+	}
+	return geo.Point{Lat: values[0], Lng: values[1]}, nil
+}
+
+// ParseBounds 解析 "南纬,西经,北纬,东经"
+func ParseBounds(value string) (Bounds, error) {
+	values, err := parseFloats(value, 4)
+	if err != nil {
+		return Bounds{}, fmt.Errorf("范围格式应为 南,西,北,东: %w", err)
+	}
+	b := Bounds{South: values[0], West: values[1], North: values[2], East: values[3]}
+	for _, err := range []error{geo.ValidateLatLng(b.South, b.West), geo.ValidateLatLng(b.North, b.East)} {
+		if err != nil {
+			return Bounds{}, err
+		}
+	}
+	if b.South > b.North || b.West > b.East {
+		return Bounds{}, fmt.Errorf("范围无效: 南纬大于北纬或西经大于东经")
+	}
+	return b, nil
+}
+
+func parseFloats(value string, n int) ([]float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("需要 %d 个数值", n)
+	}
+	values := make([]float64, n)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// Query 检索条件，零值字段不参与筛选；位置条件只匹配带坐标的记录
+type Query struct {
+	TenantID    int64
+	ProjectUUID string
+	TaskUUID    string
+	DeviceSN    string
+	FileType    string
+	From        time.Time  // 拍摄时间下限（含）
+	To          time.Time  // 拍摄时间上限（不含）
+	Near        *geo.Point // 中心点（WGS84）
+	Radius      float64    // 与 Near 的最大距离（米）
+	Bounds      *Bounds    // 经纬度范围（WGS84）
+	Limit       int        // 返回数量上限，0 表示不限制
+}
+
+// Search 按拍摄时间升序返回匹配的记录
+func (ix *Index) Search(q Query) []Record {
+	ix.mu.RLock()
+	matched := make([]Record, 0)
+	for _, r := range ix.records {
+		if q.match(r) {
+			matched = append(matched, r)
+		}
+	}
+	ix.mu.RUnlock()
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CapturedAt.Before(matched[j].CapturedAt)
+	})
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	return matched
+}
+
+func (q Query) match(r Record) bool {
+	switch {
+	case q.TenantID != 0 && r.TenantID != q.TenantID,
+		q.ProjectUUID != "" && r.ProjectUUID != q.ProjectUUID,
+		q.TaskUUID != "" && r.TaskUUID != q.TaskUUID,
+		q.DeviceSN != "" && r.DeviceSN != q.DeviceSN,
+		q.FileType != "" && r.FileType != q.FileType,
+		!q.From.IsZero() && r.CapturedAt.Before(q.From),
+		!q.To.IsZero() && !r.CapturedAt.Before(q.To):
+		return false
+	}
+	if q.Near != nil || q.Bounds != nil {
+		if r.Location == nil {
+			return false
+		}
+		if q.Near != nil && geo.Haversine(*q.Near, *r.Location) > q.Radius {
+			return false
+		}
+		if q.Bounds != nil && !q.Bounds.Contains(*r.Location) {
+			return false
+		}
+	}
+	return true
+}
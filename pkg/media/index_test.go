@@ -0,0 +1,125 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+)
+
+// TestIndexPersist 记录以 JSON Lines 追加写入，重新打开时回放，同一文件以最后一条为准
+func TestIndexPersist(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "index", "media.jsonl")
+	ix, err := OpenIndex(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	captured := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	records := []Record{
+		{TenantID: 1, TaskUUID: "t1", MediaUUID: "m2", DeviceSN: "DOCK", FileType: "visable", Size: 1, Metadata: Metadata{CapturedAt: captured.Add(time.Minute)}},
+		{TenantID: 1, TaskUUID: "t1", MediaUUID: "m1", DeviceSN: "DOCK", FileType: "visable", Size: 1, Metadata: Metadata{CapturedAt: captured, Location: &geo.Point{Lat: 22.5, Lng: 114.0}}},
+		{TenantID: 1, TaskUUID: "t1", MediaUUID: "m2", DeviceSN: "DOCK", FileType: "visable", Size: 2, Metadata: Metadata{CapturedAt: captured.Add(time.Minute)}},
+	}
+	for _, r := range records {
+		if err := ix.Add(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Errorf("索引文件 %d 行，每次添加都应追加一行", lines)
+	}
+
+	reopened, err := OpenIndex(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Len() != 2 {
+		t.Errorf("回放后 %d 条记录，应为 2", reopened.Len())
+	}
+	if r, ok := reopened.Get("t1", "m2"); !ok || r.Size != 2 {
+		t.Errorf("同一文件应以最后一条为准: %+v", r)
+	}
+	found := reopened.Search(Query{TaskUUID: "t1"})
+	if len(found) != 2 || found[0].MediaUUID != "m1" {
+		t.Errorf("检索结果应按拍摄时间升序: %+v", found)
+	}
+	if found := reopened.Search(Query{Near: &geo.Point{Lat: 22.5, Lng: 114.0}, Radius: 100}); len(found) != 1 || found[0].MediaUUID != "m1" {
+		t.Errorf("位置检索只匹配带坐标的记录: %+v", found)
+	}
+
+	if err := os.WriteFile(file, append(data, "{bad\n"...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenIndex(file); err == nil || !strings.Contains(err.Error(), "第 4 行") {
+		t.Errorf("格式错误的行应返回行号: %v", err)
+	}
+}
+
+// fakeMediaSource 返回固定媒体列表的来源
+type fakeMediaSource struct {
+	files string
+}
+
+func (f *fakeMediaSource) GetFlightTaskMedia(ctx context.Context, taskUUID string) (string, error) {
+	return f.files, nil
+}
+
+// TestArchive 归档下载、校验并写入存储与索引，照片提取元数据，再次归档跳过未变化的文件
+func TestArchive(t *testing.T) {
+	photo := buildJPEG(app1(xmpHeader, []byte(`<rdf:Description drone-dji:GpsLatitude="22.6" drone-dji:GpsLongitude="114.1" drone-dji:GimbalPitchDegree="-45"/>`)))
+	video := bytes.Repeat([]byte{0x00, 0x01}, 2048)
+	photoServer := (&mediaServer{content: photo, rangeSupport: true}).start(t)
+	videoServer := (&mediaServer{content: video, rangeSupport: true}).start(t)
+	source := &fakeMediaSource{files: fmt.Sprintf(`{"code":0,"data":{"list":[
+		{"uuid":"p1","name":"DJI_0001.JPG","file_type":"visable","size":%d,"download_url":%q,"fingerprint":%q},
+		{"uuid":"v1","name":"../DJI_0002.MP4","file_type":"video","size":%d,"url":%q,"latitude":22.5,"longitude":114.0,"created_at":1714528800000}
+	]}}`, len(photo), photoServer.URL, md5Hex(photo), len(video), videoServer.URL)}
+
+	dir := t.TempDir()
+	ix, err := OpenIndex(filepath.Join(dir, "media.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPipeline(Options{Backend: NewLocalBackend(filepath.Join(dir, "store")), Index: ix, WorkDir: filepath.Join(dir, "work")})
+	ctx := tenant.WithTenant(context.Background(), tenant.NewTenantInfo(1, "token", "project"))
+
+	report, err := p.Archive(ctx, source, Task{UUID: "t1", DeviceSN: "DOCK", Name: "巡检"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 2 || report.Archived != 2 || report.Bytes != int64(len(photo)+len(video)) {
+		t.Errorf("归档结果 %+v", report)
+	}
+
+	r, ok := ix.Get("t1", "p1")
+	if !ok || r.Key != "1/project/t1/DJI_0001.JPG" || r.MD5 != md5Hex(photo) || r.Location == nil || r.Location.Lat != 22.6 || !near(r.GimbalPitch, -45) {
+		t.Errorf("照片记录 %+v", r)
+	}
+	stored, err := os.ReadFile(filepath.Join(dir, "store", "1", "project", "t1", "DJI_0001.JPG"))
+	if err != nil || !bytes.Equal(stored, photo) {
+		t.Errorf("存储中的照片与下载内容不一致: %v", err)
+	}
+	v, ok := ix.Get("t1", "v1")
+	if !ok || v.Key != "1/project/t1/DJI_0002.MP4" || v.Location == nil || v.CapturedAt.UnixMilli() != 1714528800000 {
+		t.Errorf("视频应使用媒体列表中的位置与时间，文件名去除路径: %+v", v)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "work")); len(entries) != 0 {
+		t.Errorf("归档完成后应删除下载文件: %v", entries)
+	}
+
+	report, err = p.Archive(ctx, source, Task{UUID: "t1"})
+	if err != nil || report.Skipped != 2 || report.Archived != 0 {
+		t.Errorf("再次归档应跳过未变化的文件: %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,131 @@
+// Package media 飞行任务媒体归档
+// 任务结束后列出媒体文件，并发下载（断点续传，校验大小与MD5），提取照片 EXIF/XMP 中的位置与云台姿态，
+// 存入本地目录或 S3 兼容存储，并建立可按任务、设备、时间与位置检索的索引。
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+// Source 可获取飞行任务媒体列表（如司空2）
+type Source interface {
+	GetFlightTaskMedia(ctx context.Context, taskUUID string) (string, error)
+}
+
+// File 媒体列表中的文件
+type File struct {
+	UUID        string  `json:"uuid"`
+	Name        string  `json:"name"`
+	FileType    string  `json:"file_type"`
+	Size        int64   `json:"size"`
+	URL         string  `json:"url"`
+	DownloadURL string  `json:"download_url"`
+	Fingerprint string  `json:"fingerprint"` // 文件MD5，未返回时只校验大小
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Height      float64 `json:"height"`
+	CreatedAt   int64   `json:"created_at"` // 毫秒时间戳
+}
+
+// Link 下载地址
+func (f File) Link() string {
+	if f.DownloadURL != "" {
+		return f.DownloadURL
+	}
+	return f.URL
+}
+
+// ID 文件标识，未返回 uuid 时使用文件名
+func (f File) ID() string {
+	if f.UUID != "" {
+		return f.UUID
+	}
+	return f.Name
+}
+
+// ParseFiles 解析 GetFlightTaskMedia 的响应，格式为司空2 {"data":{"list":[...]}}
+func ParseFiles(resp string) ([]File, error) {
+	var body struct {
+		Data struct {
+			List []File `json:"list"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(resp), &body); err != nil {
+		return nil, fmt.Errorf("解析媒体列表失败: %w", err)
+	}
+	return body.Data.List, nil
+}
+
+// Metadata 照片元数据：EXIF 中的拍摄时间、相机与GPS（WGS84），XMP 中的大疆云台与飞行器姿态
+type Metadata struct {
+	CapturedAt  time.Time  `json:"captured_at"`
+	Location    *geo.Point `json:"location,omitempty"`
+	AbsAltitude *float64   `json:"abs_altitude,omitempty"` // 海拔高度（米）
+	RelAltitude *float64   `json:"rel_altitude,omitempty"` // 相对起飞点高度（米）
+	GimbalPitch *float64   `json:"gimbal_pitch,omitempty"` // 云台俯仰角（度）
+	GimbalYaw   *float64   `json:"gimbal_yaw,omitempty"`   // 云台偏航角（度）
+	GimbalRoll  *float64   `json:"gimbal_roll,omitempty"`  // 云台横滚角（度）
+	FlightYaw   *float64   `json:"flight_yaw,omitempty"`   // 飞行器偏航角（度）
+	Make        string     `json:"make,omitempty"`
+	Model       string     `json:"model,omitempty"`
+}
+
+// fillFrom 以媒体列表中的位置、高度与时间补全缺失的元数据（视频等无 EXIF 的文件）
+func (m *Metadata) fillFrom(f File) {
+	if m.CapturedAt.IsZero() && f.CreatedAt > 0 {
+		m.CapturedAt = time.UnixMilli(f.CreatedAt)
+	}
+	if m.Location == nil && (f.Latitude != 0 || f.Longitude != 0) {
+		m.Location = &geo.Point{Lat: f.Latitude, Lng: f.Longitude}
+	}
+	if m.AbsAltitude == nil && f.Height != 0 {
+		height := f.Height
+		m.AbsAltitude = &height
+	}
+}
+
+// Record 索引记录：一个已归档的媒体文件
+type Record struct {
+	TenantID    int64     `json:"tenant_id"`
+	ProjectUUID string    `json:"project_uuid,omitempty"`
+	TaskUUID    string    `json:"task_uuid"`
+	TaskName    string    `json:"task_name,omitempty"`
+	DeviceSN    string    `json:"sn,omitempty"`
+	MediaUUID   string    `json:"media_uuid"`
+	Name        string    `json:"name"`
+	FileType    string    `json:"file_type"`
+	Size        int64     `json:"size"`
+	MD5         string    `json:"md5"`
+	Backend     string    `json:"backend"`
+	Key         string    `json:"key"` // 存储键：租户/项目/任务/文件名
+	ArchivedAt  time.Time `json:"archived_at"`
+	Metadata
+}
+
+// storageKey 文件在存储中的键
+func storageKey(tenantID int64, projectUUID, taskUUID, name string) string {
+	parts := []string{fmt.Sprint(tenantID)}
+	if projectUUID != "" {
+		parts = append(parts, projectUUID)
+	}
+	parts = append(parts, taskUUID, safeName(name))
+	return strings.Join(parts, "/")
+}
+
+// safeName 去除文件名中的路径成分
+func safeName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "" || name == "." || name == ".." {
+		return "unnamed"
+	}
+	return name
+}
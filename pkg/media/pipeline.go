@@ -0,0 +1,322 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+	"gitee.com/jamespi/drone_dispatch/pkg/telemetry"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+)
+
+// ErrArchiving 同一任务已有归档在进行
+var ErrArchiving = errors.New("飞行任务正在归档")
+
+// Options 归档流水线选项
+type Options struct {
+	Backend     Backend
+	Index       *Index
+	Client      *httpclient.SecureHTTPClient // 下载媒体使用的客户端，为nil时新建
+	WorkDir     string                       // 下载工作目录，保存续传的 .part 文件
+	Concurrency int                          // 并发下载数，多个任务共享，默认4
+	Delay       time.Duration                // 任务结束到开始归档的等待时长，留给机场上传媒体
+}
+
+// Task 待归档的任务
+type Task struct {
+	UUID     string `json:"task_uuid"`
+	Name     string `json:"name,omitempty"`
+	DeviceSN string `json:"sn,omitempty"`
+}
+
+// Failure 归档失败的文件
+type Failure struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// Report 归档结果
+type Report struct {
+	TaskUUID string    `json:"task_uuid"`
+	Total    int       `json:"total"`
+	Archived int       `json:"archived"`
+	Skipped  int       `json:"skipped"` // 已归档且内容未变化
+	Bytes    int64     `json:"bytes"`
+	Failed   []Failure `json:"failed,omitempty"`
+}
+
+// tracked 等待结束的任务
+type tracked struct {
+	ctx    context.Context
+	source Source
+}
+
+// Pipeline 媒体归档流水线：登记的任务结束后自动归档，也可直接调用 Archive
+type Pipeline struct {
+	opts       Options
+	downloader *Downloader
+	slots      chan struct{}
+
+	mu          sync.Mutex
+	tracked     map[string]tracked
+	archiving   map[string]bool
+	unsubscribe func()
+	stopped     chan struct{}
+}
+
+// NewPipeline 创建归档流水线，Backend 与 Index 不能为空
+func NewPipeline(opts Options) *Pipeline {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.Client == nil {
+		opts.Client = httpclient.NewSecureHTTPClient()
+	}
+	if opts.WorkDir == "" {
+		opts.WorkDir = os.TempDir()
+	}
+	return &Pipeline{
+		opts:       opts,
+		downloader: NewDownloader(opts.Client, opts.WorkDir),
+		slots:      make(chan struct{}, opts.Concurrency),
+		tracked:    make(map[string]tracked),
+		archiving:  make(map[string]bool),
+		stopped:    make(chan struct{}),
+	}
+}
+
+// Index 媒体索引
+func (p *Pipeline) Index() *Index {
+	return p.opts.Index
+}
+
+// Backend 媒体存储
+func (p *Pipeline) Backend() Backend {
+	return p.opts.Backend
+}
+
+// Start 订阅任务状态，登记的任务结束后归档
+func (p *Pipeline) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.unsubscribe == nil {
+		p.unsubscribe = telemetry.OnTaskStatus(p.onTask)
+	}
+}
+
+// Stop 取消订阅并放弃等待中的归档，进行中的归档继续完成
+func (p *Pipeline) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.unsubscribe != nil {
+		p.unsubscribe()
+		p.unsubscribe = nil
+		close(p.stopped)
+	}
+}
+
+// Track 登记任务，任务结束后归档其媒体；ctx 需携带调用适配器所需的租户信息，其取消不影响归档
+func (p *Pipeline) Track(ctx context.Context, source Source, taskUUID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tracked[taskUUID] = tracked{ctx: context.WithoutCancel(ctx), source: source}
+}
+
+// Tracking 等待结束的任务数
+func (p *Pipeline) Tracking() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.tracked)
+}
+
+// adopt 接管另一流水线登记的任务（配置重新加载时）
+func (p *Pipeline) adopt(old *Pipeline) {
+	old.mu.Lock()
+	defer old.mu.Unlock()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, t := range old.tracked {
+		p.tracked[id] = t
+	}
+}
+
+func (p *Pipeline) onTask(event telemetry.TaskEvent) {
+	if !event.Terminal() {
+		return
+	}
+	p.mu.Lock()
+	t, ok := p.tracked[event.TaskUUID]
+	delete(p.tracked, event.TaskUUID)
+	p.mu.Unlock()
+	if !ok {
+		return
+	}
+	task := Task{UUID: event.TaskUUID, Name: event.Name, DeviceSN: event.DeviceSN}
+	go func() {
+		if p.opts.Delay > 0 {
+			select {
+			case <-time.After(p.opts.Delay):
+			case <-p.stopped:
+				return
+			}
+		}
+		report, err := p.Archive(t.ctx, t.source, task)
+		if err != nil {
+			log.Printf("飞行任务 %s 媒体归档失败: %v", task.UUID, err)
+			return
+		}
+		log.Printf("飞行任务 %s 媒体归档完成: 共 %d 个，新归档 %d 个，跳过 %d 个", task.UUID, report.Total, report.Archived, report.Skipped)
+	}()
+}
+
+// Archive 归档任务媒体：列出媒体文件，并发下载、提取元数据、写入存储与索引；已归档且内容未变化的文件跳过
+// 部分文件失败时返回结果与错误，再次归档只处理未完成的文件，下载从中断处续传
+func (p *Pipeline) Archive(ctx context.Context, source Source, task Task) (*Report, error) {
+	p.mu.Lock()
+	if p.archiving[task.UUID] {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrArchiving, task.UUID)
+	}
+	p.archiving[task.UUID] = true
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.archiving, task.UUID)
+		p.mu.Unlock()
+	}()
+
+	if task.DeviceSN == "" || task.Name == "" {
+		if info, ok := source.(telemetry.TaskInfoSource); ok {
+			if resp, err := info.GetFlightTaskInfo(ctx, task.UUID); err == nil {
+				if event, err := telemetry.ParseTask([]byte(resp)); err == nil {
+					task.DeviceSN, task.Name = firstNonEmpty(task.DeviceSN, event.DeviceSN), firstNonEmpty(task.Name, event.Name)
+				}
+			}
+		}
+	}
+	resp, err := source.GetFlightTaskMedia(ctx, task.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("获取媒体列表失败: %w", err)
+	}
+	files, err := ParseFiles(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	base := Record{TaskUUID: task.UUID, TaskName: task.Name, DeviceSN: task.DeviceSN, Backend: p.opts.Backend.Name()}
+	if info, err := tenant.GetTenantFromContext(ctx); err == nil {
+		base.TenantID, base.ProjectUUID = info.TenantId, info.ProjectUUID
+	}
+
+	report := &Report{TaskUUID: task.UUID, Total: len(files)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, f := range files {
+		if existing, ok := p.opts.Index.Get(task.UUID, f.ID()); ok && unchanged(existing, f) {
+			report.Skipped++
+			continue
+		}
+		wg.Add(1)
+		go func(f File) {
+			defer wg.Done()
+			select {
+			case p.slots <- struct{}{}:
+				defer func() { <-p.slots }()
+			case <-ctx.Done():
+				mu.Lock()
+				report.Failed = append(report.Failed, Failure{Name: f.Name, Error: ctx.Err().Error()})
+				mu.Unlock()
+				return
+			}
+			record, err := p.archiveFile(ctx, base, f)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Failed = append(report.Failed, Failure{Name: f.Name, Error: err.Error()})
+				return
+			}
+			report.Archived++
+			report.Bytes += record.Size
+		}(f)
+	}
+	wg.Wait()
+	if len(report.Failed) > 0 {
+		return report, fmt.Errorf("%d 个媒体文件归档失败，首个错误: %s", len(report.Failed), report.Failed[0].Error)
+	}
+	return report, nil
+}
+
+// archiveFile 下载单个文件、提取元数据、写入存储与索引
+func (p *Pipeline) archiveFile(ctx context.Context, base Record, f File) (*Record, error) {
+	local, digest, err := p.downloader.Download(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(local)
+
+	record := base
+	record.MediaUUID = f.ID()
+	record.Name = f.Name
+	record.FileType = f.FileType
+	record.MD5 = digest
+	record.Size = fileSize(local)
+	record.Key = storageKey(base.TenantID, base.ProjectUUID, base.TaskUUID, f.Name)
+
+	if isJPEG(f) {
+		if file, err := os.Open(local); err == nil {
+			meta, err := ReadMetadata(file)
+			file.Close()
+			if err != nil && !errors.Is(err, ErrNotJPEG) {
+				log.Printf("媒体文件 %s 元数据提取失败: %v", f.Name, err)
+			}
+			record.Metadata = meta
+		}
+	}
+	record.Metadata.fillFrom(f)
+
+	file, err := os.Open(local)
+	if err != nil {
+		return nil, fmt.Errorf("打开下载文件失败: %w", err)
+	}
+	defer file.Close()
+	if err := p.opts.Backend.Put(ctx, record.Key, file); err != nil {
+		return nil, fmt.Errorf("存储 %s 失败: %w", f.Name, err)
+	}
+	record.ArchivedAt = time.Now()
+	if err := p.opts.Index.Add(record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// unchanged 已归档的记录与媒体列表中的文件是否一致：有MD5时比较MD5，否则比较大小
+func unchanged(r Record, f File) bool {
+	if f.Fingerprint != "" {
+		return strings.EqualFold(r.MD5, f.Fingerprint)
+	}
+	return f.Size <= 0 || r.Size == f.Size
+}
+
+func isJPEG(f File) bool {
+	switch strings.ToLower(path.Ext(f.Name)) {
+	case ".jpg", ".jpeg":
+		return true
+	}
+	return false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/objectstore"
+)
+
+// Backend 媒体存储
+type Backend interface {
+	// Name 存储名称，记录在索引中
+	Name() string
+	// Put 写入文件，同一键重复写入时覆盖
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Open 读取文件
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// LocalBackend 本地目录存储
+type LocalBackend struct {
+	root string
+}
+
+// NewLocalBackend 创建本地目录存储
+func NewLocalBackend(root string) *LocalBackend {
+	return &LocalBackend{root: root}
+}
+
+// Name 实现 Backend
+func (b *LocalBackend) Name() string {
+	return "local"
+}
+
+// Put 先写入临时文件再重命名，避免读到写入一半的文件
+func (b *LocalBackend) Put(ctx context.Context, key string, r io.Reader) error {
+	target, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("创建存储目录失败: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("创建文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	return nil
+}
+
+// Open 实现 Backend
+func (b *LocalBackend) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	target, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(target)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %w", err)
+	}
+	return file, nil
+}
+
+// path 存储键对应的本地路径，拒绝越出根目录的键
+func (b *LocalBackend) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("存储键无效: %s", key)
+	}
+	return filepath.Join(b.root, filepath.FromSlash(clean)), nil
+}
+
+// S3Backend S3 兼容存储（阿里云OSS、AWS S3、MinIO），大文件分片上传
+type S3Backend struct {
+	client *objectstore.Client
+	prefix string
+}
+
+// NewS3Backend 创建 S3 兼容存储，prefix 为对象键前缀
+func NewS3Backend(client *objectstore.Client, prefix string) *S3Backend {
+	return &S3Backend{client: client, prefix: strings.Trim(prefix, "/")}
+}
+
+// Name 实现 Backend
+func (b *S3Backend) Name() string {
+	return "s3"
+}
+
+// Put 实现 Backend
+func (b *S3Backend) Put(ctx context.Context, key string, r io.Reader) error {
+	if _, err := objectstore.NewUploader(b.client).Upload(ctx, b.key(key), r); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Open 实现 Backend
+func (b *S3Backend) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return b.client.GetObject(ctx, b.key(key))
+}
+
+func (b *S3Backend) key(key string) string {
+	if b.prefix == "" {
+		return key
+	}
+	return b.prefix + "/" + key
+}
@@ -0,0 +1,377 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+)
+
+// maxErrorBody 错误响应体读取上限
+const maxErrorBody = 64 << 10
+
+// Client S3 兼容对象存储客户端，绑定一个存储桶与一组临时凭证
+type Client struct {
+	http      *httpclient.SecureHTTPClient
+	endpoint  *url.URL
+	bucket    string
+	region    string
+	pathStyle bool
+	creds     Credentials
+	now       func() time.Time
+}
+
+// NewClient 按上传凭证创建客户端；MinIO 与 IP/localhost 端点使用路径风格寻址，其余使用虚拟主机风格
+func NewClient(token *STSToken, client *httpclient.SecureHTTPClient) (*Client, error) {
+	endpoint := token.Endpoint
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("解析存储端点失败: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("存储端点缺少主机名: %s", token.Endpoint)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+
+	region := token.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	if strings.EqualFold(token.Provider, "ali") && !strings.HasPrefix(region, "oss-") {
+		region = "oss-" + region
+	}
+
+	host := u.Hostname()
+	pathStyle := strings.EqualFold(token.Provider, "minio") || host == "localhost" || net.ParseIP(host) != nil || u.Path != ""
+
+	return &Client{
+		http:      client,
+		endpoint:  u,
+		bucket:    token.Bucket,
+		region:    region,
+		pathStyle: pathStyle,
+		creds:     token.Credentials,
+		now:       time.Now,
+	}, nil
+}
+
+// Bucket 存储桶名称
+func (c *Client) Bucket() string {
+	return c.bucket
+}
+
+// objectURL 对象地址，key 为空时为存储桶地址
+func (c *Client) objectURL(key string, query url.Values) *url.URL {
+	u := *c.endpoint
+	p := u.Path
+	if c.pathStyle {
+		p += "/" + c.bucket
+	} else {
+		u.Host = c.bucket + "." + u.Host
+	}
+	p += "/" + key
+	u.Path = p
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	u.RawPath = strings.Join(segments, "/")
+	u.RawQuery = canonicalQuery(query)
+	return &u
+}
+
+// do 签名并执行请求，非 2xx 响应解析为 *Error
+func (c *Client) do(ctx context.Context, method, key string, query url.Values, body []byte, header http.Header) (*http.Response, error) {
+	u := c.objectURL(key, query)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建存储请求失败: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+	if len(body) == 0 {
+		req.Body = http.NoBody
+	}
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("User-Agent", "DroneDispatch/1.0")
+	payloadHash := emptyBodySHA256
+	if len(body) > 0 {
+		payloadHash = hashHex(body)
+	}
+	signV4(req, c.creds, c.region, payloadHash, c.now())
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, parseError(resp)
+	}
+	return resp, nil
+}
+
+// doXML 执行请求并将响应体解析为 XML，out 为 nil 时丢弃响应体
+func (c *Client) doXML(ctx context.Context, method, key string, query url.Values, body []byte, header http.Header, out interface{}) (http.Header, error) {
+	resp, err := c.do(ctx, method, key, query, body, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取存储响应失败: %w", err)
+	}
+	// CompleteMultipartUpload 可能在 200 响应体中返回错误
+	if bytes.Contains(data, []byte("<Error>")) {
+		return nil, decodeError(resp.StatusCode, data)
+	}
+	if out != nil {
+		if err := xml.Unmarshal(data, out); err != nil {
+			return nil, fmt.Errorf("解析存储响应失败: %w", err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// PutObject 简单上传，返回对象 ETag
+func (c *Client) PutObject(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	header := http.Header{}
+	header.Set("Content-MD5", contentMD5(data))
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	respHeader, err := c.doXML(ctx, http.MethodPut, key, nil, data, header, nil)
+	if err != nil {
+		return "", fmt.Errorf("上传对象失败: %w", err)
+	}
+	return trimETag(respHeader.Get("ETag")), nil
+}
+
+// GetObject 下载对象，调用方负责关闭返回的响应体
+func (c *Client) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("下载对象失败: %w", err)
+	}
+	return resp.Body, nil
+}
+
+// CreateMultipartUpload 初始化分片上传，返回 UploadId
+func (c *Client) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if _, err := c.doXML(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, header, &result); err != nil {
+		return "", fmt.Errorf("初始化分片上传失败: %w", err)
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("初始化分片上传失败: 响应中没有 UploadId")
+	}
+	return result.UploadID, nil
+}
+
+// UploadPart 上传分片，partNumber 从 1 开始，返回分片 ETag
+func (c *Client) UploadPart(ctx context.Context, key, uploadID string, partNumber int, data []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}
+	header := http.Header{}
+	header.Set("Content-MD5", contentMD5(data))
+	respHeader, err := c.doXML(ctx, http.MethodPut, key, query, data, header, nil)
+	if err != nil {
+		return "", fmt.Errorf("上传分片 %d 失败: %w", partNumber, err)
+	}
+	return trimETag(respHeader.Get("ETag")), nil
+}
+
+// Part 已上传分片
+type Part struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+	Size       int64  `xml:"Size,omitempty"`
+}
+
+// CompleteMultipartUpload 合并分片，parts 需按分片号升序，返回对象 ETag
+func (c *Client) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []Part) (string, error) {
+	type completePart struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	request := struct {
+		XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+		Parts   []completePart `xml:"Part"`
+	}{}
+	for _, p := range parts {
+		request.Parts = append(request.Parts, completePart{PartNumber: p.PartNumber, ETag: `"` + trimETag(p.ETag) + `"`})
+	}
+	body, err := xml.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("生成合并请求失败: %w", err)
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml")
+	var result struct {
+		ETag string `xml:"ETag"`
+	}
+	if _, err := c.doXML(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, body, header, &result); err != nil {
+		return "", fmt.Errorf("合并分片失败: %w", err)
+	}
+	return trimETag(result.ETag), nil
+}
+
+// AbortMultipartUpload 取消分片上传并清理已上传分片
+func (c *Client) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	if _, err := c.doXML(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, nil, nil); err != nil {
+		return fmt.Errorf("取消分片上传失败: %w", err)
+	}
+	return nil
+}
+
+// ListParts 列出分片上传中已上传的分片（自动翻页）
+func (c *Client) ListParts(ctx context.Context, key, uploadID string) ([]Part, error) {
+	var parts []Part
+	marker := ""
+	for {
+		query := url.Values{"uploadId": {uploadID}}
+		if marker != "" {
+			query.Set("part-number-marker", marker)
+		}
+		var result struct {
+			Parts                []Part `xml:"Part"`
+			IsTruncated          bool   `xml:"IsTruncated"`
+			NextPartNumberMarker string `xml:"NextPartNumberMarker"`
+		}
+		if _, err := c.doXML(ctx, http.MethodGet, key, query, nil, nil, &result); err != nil {
+			return nil, fmt.Errorf("查询已上传分片失败: %w", err)
+		}
+		for _, p := range result.Parts {
+			p.ETag = trimETag(p.ETag)
+			parts = append(parts, p)
+		}
+		if !result.IsTruncated || result.NextPartNumberMarker == "" || result.NextPartNumberMarker == marker {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
+
+// Upload 进行中的分片上传
+type Upload struct {
+	Key       string    `xml:"Key"`
+	UploadID  string    `xml:"UploadId"`
+	Initiated time.Time `xml:"Initiated"`
+}
+
+// ListMultipartUploads 列出前缀下进行中的分片上传（自动翻页）
+func (c *Client) ListMultipartUploads(ctx context.Context, prefix string) ([]Upload, error) {
+	var uploads []Upload
+	keyMarker, uploadIDMarker := "", ""
+	for {
+		query := url.Values{"uploads": {""}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if keyMarker != "" {
+			query.Set("key-marker", keyMarker)
+			query.Set("upload-id-marker", uploadIDMarker)
+		}
+		var result struct {
+			Uploads            []Upload `xml:"Upload"`
+			IsTruncated        bool     `xml:"IsTruncated"`
+			NextKeyMarker      string   `xml:"NextKeyMarker"`
+			NextUploadIDMarker string   `xml:"NextUploadIdMarker"`
+		}
+		if _, err := c.doXML(ctx, http.MethodGet, "", query, nil, nil, &result); err != nil {
+			return nil, fmt.Errorf("查询进行中的分片上传失败: %w", err)
+		}
+		uploads = append(uploads, result.Uploads...)
+		if !result.IsTruncated || (result.NextKeyMarker == keyMarker && result.NextUploadIDMarker == uploadIDMarker) {
+			return uploads, nil
+		}
+		keyMarker, uploadIDMarker = result.NextKeyMarker, result.NextUploadIDMarker
+	}
+}
+
+// Error 对象存储返回的错误
+type Error struct {
+	StatusCode int    `xml:"-"`
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+	RequestID  string `xml:"RequestId"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("对象存储错误[%d %s]", e.StatusCode, e.Code)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.RequestID != "" {
+		msg += " (RequestId: " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// Temporary 是否为可重试的错误：服务端错误、限流与请求超时
+func (e *Error) Temporary() bool {
+	switch e.Code {
+	case "SlowDown", "RequestTimeout", "InternalError", "ServiceUnavailable":
+		return true
+	}
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+func parseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return decodeError(resp.StatusCode, data)
+}
+
+func decodeError(status int, data []byte) error {
+	e := &Error{StatusCode: status}
+	if err := xml.Unmarshal(data, e); err != nil || e.Code == "" {
+		e.Code = http.StatusText(status)
+	}
+	return e
+}
+
+// retryable 网络错误（含单次请求超时）与可重试的存储错误；调用方上下文结束时不再重试
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var storeErr *Error
+	if errors.As(err, &storeErr) {
+		return storeErr.Temporary()
+	}
+	return true
+}
+
+func contentMD5(data []byte) string {
+	sum := md5.Sum(data)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func trimETag(etag string) string {
+	return strings.Trim(etag, `"`)
+}
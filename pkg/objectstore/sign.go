@@ -0,0 +1,174 @@
+package objectstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// V4 签名常量
+const (
+	signAlgorithm   = "AWS4-HMAC-SHA256"
+	signService     = "s3"
+	amzDateFormat   = "20060102T150405Z"
+	amzDayFormat    = "20060102"
+	headerDate      = "X-Amz-Date"
+	headerToken     = "X-Amz-Security-Token"
+	headerSHA256    = "X-Amz-Content-Sha256"
+	emptyBodySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// signedHeaderNames 参与签名的请求头，其余请求头（如 User-Agent）可能被代理改写，不参与签名
+var signedHeaderNames = []string{"content-md5", "content-type", "host", "x-amz-content-sha256", "x-amz-date", "x-amz-security-token"}
+
+// signV4 按 AWS Signature Version 4 为请求签名，payloadHash 为请求体 SHA256 十六进制
+func signV4(req *http.Request, creds Credentials, region, payloadHash string, now time.Time) {
+	now = now.UTC()
+	req.Header.Set(headerDate, now.Format(amzDateFormat))
+	req.Header.Set(headerSHA256, payloadHash)
+	if creds.SecurityToken != "" {
+		req.Header.Set(headerToken, creds.SecurityToken)
+	}
+
+	canonicalHeaders, signedHeaders := canonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(amzDayFormat), region, signService, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		signAlgorithm,
+		now.Format(amzDateFormat),
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.AccessKeySecret), now.Format(amzDayFormat))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, signService)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", signAlgorithm+" Credential="+creds.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// canonicalHeaders 规范化请求头与签名头列表
+func canonicalHeaders(req *http.Request) (string, string) {
+	var lines, names []string
+	for _, name := range signedHeaderNames {
+		var value string
+		if name == "host" {
+			value = req.Host
+			if value == "" {
+				value = req.URL.Host
+			}
+		} else {
+			value = req.Header.Get(name)
+		}
+		if value == "" {
+			continue
+		}
+		lines = append(lines, name+":"+strings.TrimSpace(value)+"\n")
+		names = append(names, name)
+	}
+	return strings.Join(lines, ""), strings.Join(names, ";")
+}
+
+// canonicalPath 规范化路径：逐段 URI 编码，保留斜杠
+func canonicalPath(u *url.URL) string {
+	p := u.EscapedPath()
+	if p == "" {
+		return "/"
+	}
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery 规范化查询串：按键排序，键值均 URI 编码，无值参数保留等号
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode 按 S3 规则编码：除 A-Z a-z 0-9 - _ . ~ 外全部百分号编码
+func uriEncode(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&0xF])
+	}
+	return b.String()
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// VerifySignature 校验请求的 V4 签名与安全令牌，供本地 S3 模拟服务使用；不校验请求体哈希与签名时间
+func VerifySignature(req *http.Request, creds Credentials, region string) error {
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, signAlgorithm+" ") {
+		return fmt.Errorf("缺少V4签名")
+	}
+	if !strings.Contains(auth, "Credential="+creds.AccessKeyID+"/") {
+		return fmt.Errorf("访问密钥不匹配")
+	}
+	if creds.SecurityToken != "" && req.Header.Get(headerToken) != creds.SecurityToken {
+		return fmt.Errorf("安全令牌不匹配")
+	}
+	date, err := time.Parse(amzDateFormat, req.Header.Get(headerDate))
+	if err != nil {
+		return fmt.Errorf("签名时间格式错误: %w", err)
+	}
+	signed := req.Clone(req.Context())
+	signV4(signed, creds, region, req.Header.Get(headerSHA256), date)
+	if !hmac.Equal([]byte(signed.Header.Get("Authorization")), []byte(auth)) {
+		return fmt.Errorf("签名不匹配")
+	}
+	return nil
+}
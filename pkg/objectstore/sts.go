@@ -0,0 +1,68 @@
+// Package objectstore S3 兼容对象存储客户端
+// 司空2项目存储凭证（STS）指向阿里云OSS、AWS S3 或 MinIO，三者均支持 S3 API 与 V4 签名；
+// 本包实现上传所需的子集：简单上传、分片上传，以及按服务端已上传分片续传。
+package objectstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Credentials 临时访问凭证
+type Credentials struct {
+	AccessKeyID     string `json:"access_key_id"`
+	AccessKeySecret string `json:"access_key_secret"`
+	SecurityToken   string `json:"security_token"`
+	Expire          int64  `json:"expire"` // 过期时间（秒级时间戳）
+}
+
+// STSToken 司空2项目存储上传凭证
+type STSToken struct {
+	Endpoint        string      `json:"endpoint"`
+	Bucket          string      `json:"bucket"`
+	Provider        string      `json:"provider"` // ali、aws、minio
+	Region          string      `json:"region"`
+	ObjectKeyPrefix string      `json:"object_key_prefix"`
+	Credentials     Credentials `json:"credentials"`
+}
+
+// ParseSTSToken 解析 GetProjectStsToken 的响应，兼容带 code/data 外层的司空2响应与凭证对象本身
+func ParseSTSToken(resp string) (*STSToken, error) {
+	var envelope struct {
+		Data *STSToken `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(resp), &envelope); err != nil {
+		return nil, fmt.Errorf("解析存储凭证失败: %w", err)
+	}
+	token := envelope.Data
+	if token == nil {
+		token = &STSToken{}
+		if err := json.Unmarshal([]byte(resp), token); err != nil {
+			return nil, fmt.Errorf("解析存储凭证失败: %w", err)
+		}
+	}
+	if token.Endpoint == "" || token.Bucket == "" || token.Credentials.AccessKeyID == "" || token.Credentials.AccessKeySecret == "" {
+		return nil, fmt.Errorf("存储凭证不完整: endpoint、bucket 与访问密钥不能为空")
+	}
+	return token, nil
+}
+
+// Expired 凭证是否已过期或将在一分钟内过期，未返回过期时间时视为有效
+func (t *STSToken) Expired(now time.Time) bool {
+	if t.Credentials.Expire <= 0 {
+		return false
+	}
+	return now.Add(time.Minute).Unix() >= t.Credentials.Expire
+}
+
+// ObjectKey 凭证允许写入的对象键：前缀/文件名
+func (t *STSToken) ObjectKey(name string) string {
+	prefix := strings.Trim(t.ObjectKeyPrefix, "/")
+	if prefix == "" {
+		return name
+	}
+	return path.Join(prefix, name)
+}
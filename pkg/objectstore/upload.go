@@ -0,0 +1,201 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 分片上传参数
+const (
+	MinPartSize     = 5 << 20 // S3 要求除最后一片外的分片不小于 5MB
+	DefaultPartSize = 8 << 20
+	maxParts        = 10000
+	defaultRetries  = 3
+	retryBackoff    = 500 * time.Millisecond
+)
+
+// Uploader 上传器：小于一个分片的内容使用简单上传，其余使用分片上传
+// 分片上传失败时不取消，再次上传同一对象键时查询服务端已上传的分片，内容一致的分片直接跳过
+type Uploader struct {
+	client      *Client
+	PartSize    int64  // 分片大小，小于 MinPartSize 时按 MinPartSize
+	MaxRetries  int    // 单个请求的最大重试次数
+	ContentType string // 对象类型，为空时不设置
+}
+
+// NewUploader 创建上传器
+func NewUploader(client *Client) *Uploader {
+	return &Uploader{
+		client:     client,
+		PartSize:   DefaultPartSize,
+		MaxRetries: defaultRetries,
+	}
+}
+
+// Result 上传结果
+type Result struct {
+	Key          string `json:"key"`
+	ETag         string `json:"etag"`
+	Size         int64  `json:"size"`
+	UploadID     string `json:"upload_id,omitempty"`     // 分片上传的 UploadId，简单上传为空
+	Parts        int    `json:"parts"`                   // 分片数量，简单上传为 1
+	ResumedParts int    `json:"resumed_parts,omitempty"` // 续传时跳过的已上传分片数量
+}
+
+// Upload 将 r 的内容上传到 key
+func (u *Uploader) Upload(ctx context.Context, key string, r io.Reader) (*Result, error) {
+	partSize := u.PartSize
+	if partSize < MinPartSize {
+		partSize = MinPartSize
+	}
+	buf := make([]byte, partSize)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("读取上传内容失败: %w", err)
+	}
+	if int64(n) < partSize {
+		return u.putObject(ctx, key, buf[:n])
+	}
+	return u.multipart(ctx, key, buf, r)
+}
+
+func (u *Uploader) putObject(ctx context.Context, key string, data []byte) (*Result, error) {
+	var etag string
+	err := u.retry(ctx, func() error {
+		var err error
+		etag, err = u.client.PutObject(ctx, key, data, u.ContentType)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Key: key, ETag: etag, Size: int64(len(data)), Parts: 1}, nil
+}
+
+// multipart 分片上传，first 为已读取的第一个分片
+func (u *Uploader) multipart(ctx context.Context, key string, first []byte, r io.Reader) (*Result, error) {
+	result := &Result{Key: key}
+	uploaded, err := u.resume(ctx, key, result)
+	if err != nil {
+		return nil, err
+	}
+	if result.UploadID == "" {
+		err := u.retry(ctx, func() error {
+			var err error
+			result.UploadID, err = u.client.CreateMultipartUpload(ctx, key, u.ContentType)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var parts []Part
+	chunk := first
+	for number := 1; ; number++ {
+		if number > maxParts {
+			return nil, fmt.Errorf("分片数量超过上限 %d，请增大分片大小", maxParts)
+		}
+		part := Part{PartNumber: number, Size: int64(len(chunk))}
+		sum := md5.Sum(chunk)
+		if prev, ok := uploaded[number]; ok && prev.Size == part.Size && strings.EqualFold(prev.ETag, hex.EncodeToString(sum[:])) {
+			part.ETag = prev.ETag
+			result.ResumedParts++
+		} else {
+			err := u.retry(ctx, func() error {
+				var err error
+				part.ETag, err = u.client.UploadPart(ctx, key, result.UploadID, number, chunk)
+				return err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%w（已保留 UploadId %s，重新上传可续传）", err, result.UploadID)
+			}
+		}
+		parts = append(parts, part)
+		result.Size += part.Size
+
+		n, err := io.ReadFull(r, first)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("读取上传内容失败: %w", err)
+		}
+		chunk = first[:n]
+	}
+
+	err = u.retry(ctx, func() error {
+		var err error
+		result.ETag, err = u.client.CompleteMultipartUpload(ctx, key, result.UploadID, parts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Parts = len(parts)
+	return result, nil
+}
+
+// resume 查找同一对象键最近发起且未完成的分片上传，返回其已上传分片
+// 凭证无权列举分片上传（403）或服务端不支持（501）时按新上传处理
+func (u *Uploader) resume(ctx context.Context, key string, result *Result) (map[int]Part, error) {
+	uploads, err := u.client.ListMultipartUploads(ctx, key)
+	if err != nil {
+		if skippable(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var latest *Upload
+	for i := range uploads {
+		if uploads[i].Key == key && (latest == nil || uploads[i].Initiated.After(latest.Initiated)) {
+			latest = &uploads[i]
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	parts, err := u.client.ListParts(ctx, key, latest.UploadID)
+	if err != nil {
+		if skippable(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	uploaded := make(map[int]Part, len(parts))
+	for _, p := range parts {
+		uploaded[p.PartNumber] = p
+	}
+	result.UploadID = latest.UploadID
+	return uploaded, nil
+}
+
+// retry 执行 fn，可重试的错误按指数退避重试
+func (u *Uploader) retry(ctx context.Context, fn func() error) error {
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= u.MaxRetries || !retryable(ctx, err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func skippable(err error) bool {
+	var storeErr *Error
+	return errors.As(err, &storeErr) &&
+		(storeErr.StatusCode == http.StatusForbidden || storeErr.StatusCode == http.StatusNotImplemented || storeErr.Code == "NoSuchUpload")
+}
@@ -0,0 +1,181 @@
+// 测试使用 fh2mock 的模拟对象存储，fh2mock 依赖本包，因此测试位于外部测试包
+package objectstore_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/fh2mock"
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+	"gitee.com/jamespi/drone_dispatch/pkg/objectstore"
+)
+
+// newMockClient 启动模拟服务并按其下发的存储凭证创建客户端
+func newMockClient(t *testing.T) (*fh2mock.Server, *objectstore.Client, *objectstore.STSToken) {
+	t.Helper()
+	mock, server := fh2mock.NewTestServer(fh2mock.DefaultOptions())
+	t.Cleanup(server.Close)
+	httpClient := httpclient.NewSecureHTTPClientWithTransport(http.DefaultTransport)
+	resp, err := httpClient.DoRequest(context.Background(), http.MethodGet, server.URL+"/openapi/v0.1/project/sts-token", nil, map[string]string{
+		"X-User-Token":   "token",
+		"X-Project-Uuid": mock.Options().ProjectUUID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := objectstore.ParseSTSToken(string(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := objectstore.NewClient(token, httpClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mock, client, token
+}
+
+// content 长度为 size 的确定性内容，seed 不同时内容不同
+func content(size int, seed byte) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i*31) + seed
+	}
+	return data
+}
+
+func download(t *testing.T, client *objectstore.Client, key string) []byte {
+	t.Helper()
+	body, err := client.GetObject(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// TestUpload 小于一个分片时简单上传，其余分片上传，下载内容一致
+func TestUpload(t *testing.T) {
+	_, client, token := newMockClient(t)
+	uploader := objectstore.NewUploader(client)
+	uploader.PartSize = objectstore.MinPartSize
+
+	for _, tc := range []struct {
+		name  string
+		size  int
+		parts int
+	}{
+		{"简单上传", 1 << 10, 1},
+		{"分片上传", 2*objectstore.MinPartSize + 1<<20, 3},
+	} {
+		key := token.ObjectKey(tc.name + ".kmz")
+		data := content(tc.size, 1)
+		result, err := uploader.Upload(context.Background(), key, bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if result.Parts != tc.parts || result.Size != int64(tc.size) || result.ETag == "" {
+			t.Errorf("%s: 结果 %+v", tc.name, result)
+		}
+		if got := download(t, client, key); !bytes.Equal(got, data) {
+			t.Errorf("%s: 下载内容不一致（%d 字节，应为 %d）", tc.name, len(got), len(data))
+		}
+	}
+}
+
+// TestUploadResume 分片上传中断后再次上传同一对象键，跳过服务端内容一致的分片
+func TestUploadResume(t *testing.T) {
+	ctx := context.Background()
+	_, client, token := newMockClient(t)
+	key := token.ObjectKey("resume.kmz")
+	data := content(2*objectstore.MinPartSize+1<<20, 2)
+
+	uploadID, err := client.CreateMultipartUpload(ctx, key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for number := 1; number <= 2; number++ {
+		if _, err := client.UploadPart(ctx, key, uploadID, number, data[(number-1)*objectstore.MinPartSize:number*objectstore.MinPartSize]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	uploader := objectstore.NewUploader(client)
+	uploader.PartSize = objectstore.MinPartSize
+	result, err := uploader.Upload(ctx, key, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.UploadID != uploadID || result.ResumedParts != 2 || result.Parts != 3 {
+		t.Errorf("续传结果 %+v，应沿用 UploadId %s 并跳过 2 个分片", result, uploadID)
+	}
+	if got := download(t, client, key); !bytes.Equal(got, data) {
+		t.Error("续传后下载内容不一致")
+	}
+}
+
+// TestUploadETagMismatch 服务端已有分片与本地内容不一致时重新上传，合并时 ETag 不符的分片被拒绝
+func TestUploadETagMismatch(t *testing.T) {
+	ctx := context.Background()
+	_, client, token := newMockClient(t)
+	key := token.ObjectKey("mismatch.kmz")
+	data := content(objectstore.MinPartSize+1<<20, 3)
+
+	uploadID, err := client.CreateMultipartUpload(ctx, key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 大小相同、内容不同的旧分片
+	stale, err := client.UploadPart(ctx, key, uploadID, 1, content(objectstore.MinPartSize, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.CompleteMultipartUpload(ctx, key, uploadID, []objectstore.Part{{PartNumber: 1, ETag: stale + "0"}})
+	var storeErr *objectstore.Error
+	if !errors.As(err, &storeErr) || storeErr.Code != "InvalidPart" {
+		t.Errorf("ETag 不符时应返回 InvalidPart，实际 %v", err)
+	}
+
+	uploader := objectstore.NewUploader(client)
+	uploader.PartSize = objectstore.MinPartSize
+	result, err := uploader.Upload(ctx, key, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.UploadID != uploadID || result.ResumedParts != 0 {
+		t.Errorf("续传结果 %+v，内容不一致的分片不应跳过", result)
+	}
+	if got := download(t, client, key); !bytes.Equal(got, data) {
+		t.Error("重新上传后下载内容不一致")
+	}
+}
+
+// TestUploadRetry 服务端暂时不可用时重试
+func TestUploadRetry(t *testing.T) {
+	mock, client, token := newMockClient(t)
+	key := token.ObjectKey("retry.kmz")
+	mock.AddFault(fh2mock.Fault{Method: http.MethodPut, Path: "/s3/*/" + key, StatusCode: http.StatusServiceUnavailable, Times: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	data := content(1<<10, 5)
+	if _, err := objectstore.NewUploader(client).Upload(ctx, key, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if got := download(t, client, key); !bytes.Equal(got, data) {
+		t.Error("重试后下载内容不一致")
+	}
+}
@@ -0,0 +1,108 @@
+package preflight
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// DefaultAuditLimit 内存中默认保留的报告数量
+const DefaultAuditLimit = 1000
+
+// AuditLog 飞前检查报告存档，内存只保留最近的报告，完整记录可追加写入 JSON Lines 文件
+type AuditLog struct {
+	mu      sync.RWMutex
+	reports map[string]*Report // 报告编号 -> 报告
+	order   []string           // 报告编号，按保存顺序
+	limit   int
+	file    string
+}
+
+// NewAuditLog 创建报告存档，内存保留 DefaultAuditLimit 条报告
+func NewAuditLog() *AuditLog {
+	return &AuditLog{reports: make(map[string]*Report), limit: DefaultAuditLimit}
+}
+
+// SetLimit 设置内存中保留的报告数量，超出时淘汰最早保存的报告；不大于0时使用默认值
+func (a *AuditLog) SetLimit(limit int) {
+	if limit <= 0 {
+		limit = DefaultAuditLimit
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.limit = limit
+	a.evictLocked()
+}
+
+// evictLocked 淘汰超出数量上限的最早报告
+func (a *AuditLog) evictLocked() {
+	for len(a.order) > a.limit {
+		delete(a.reports, a.order[0])
+		a.order = a.order[1:]
+	}
+}
+
+// SetFile 设置存档文件，每条报告追加一行 JSON；为空时只保存在内存
+func (a *AuditLog) SetFile(path string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.file = path
+}
+
+// Record 保存报告，被阻止下发的检查同样需要保存
+func (a *AuditLog) Record(report *Report) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	saved := *report
+	saved.Results = append([]Result(nil), report.Results...)
+	if _, exists := a.reports[report.ID]; !exists {
+		a.order = append(a.order, report.ID)
+	}
+	a.reports[report.ID] = &saved
+	a.evictLocked()
+	if a.file == "" {
+		return nil
+	}
+	line, err := json.Marshal(&saved)
+	if err != nil {
+		return fmt.Errorf("序列化飞前检查报告失败: %w", err)
+	}
+	f, err := os.OpenFile(a.file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("打开飞前检查存档文件失败: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("写入飞前检查存档失败: %w", err)
+	}
+	return nil
+}
+
+// Get 按报告编号获取报告，已被淘汰的报告只能从存档文件查询
+func (a *AuditLog) Get(id string) (*Report, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	report, ok := a.reports[id]
+	if !ok {
+		return nil, false
+	}
+	saved := *report
+	return &saved, true
+}
+
+// ForMission 获取任务关联的报告，按检查时间排序
+func (a *AuditLog) ForMission(missionID string) []*Report {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var reports []*Report
+	for _, report := range a.reports {
+		if report.MissionID == missionID {
+			saved := *report
+			reports = append(reports, &saved)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].CheckedAt.Before(reports[j].CheckedAt) })
+	return reports
+}
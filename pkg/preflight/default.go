@@ -0,0 +1,86 @@
+package preflight
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+)
+
+// 全局检查流水线与报告存档，插件下发任务前使用
+var (
+	defaultPipeline = NewPipeline(DefaultRules(DefaultLimits())...)
+	defaultAudit    = NewAuditLog()
+	enabled         atomic.Bool
+)
+
+func init() {
+	enabled.Store(true)
+}
+
+// Enabled 是否启用飞前检查
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// SetEnabled 启用或停用飞前检查
+func SetEnabled(on bool) {
+	enabled.Store(on)
+}
+
+// DefaultPipeline 全局检查流水线，可通过 AddRule 增加自定义规则
+func DefaultPipeline() *Pipeline {
+	return defaultPipeline
+}
+
+// DefaultAudit 全局报告存档
+func DefaultAudit() *AuditLog {
+	return defaultAudit
+}
+
+// Run 使用全局流水线执行检查，未启用时返回nil
+func Run(ctx context.Context, src Sources, dockSn, droneSn string) *Report {
+	if !Enabled() {
+		return nil
+	}
+	return defaultPipeline.Run(ctx, src, dockSn, droneSn)
+}
+
+// Record 关联任务编号（可为空，表示被阻止的下发）后保存到全局存档，存档失败只记录日志
+func Record(report *Report, missionID string) {
+	if report == nil {
+		return
+	}
+	report.MissionID = missionID
+	if err := defaultAudit.Record(report); err != nil {
+		log.Printf("保存飞前检查报告 %s 失败: %v", report.ID, err)
+	}
+}
+
+// ApplyConfig 按配置文件 Preflight 段更新全局流水线的阈值、停用规则与存档；未配置时恢复默认
+func ApplyConfig(cfg *config.Preflight) {
+	limits := DefaultLimits()
+	if cfg == nil {
+		cfg = &config.Preflight{}
+	}
+	if cfg.MinBattery > 0 {
+		limits.MinBattery = cfg.MinBattery
+	}
+	if cfg.WarnBattery > 0 {
+		limits.WarnBattery = cfg.WarnBattery
+	}
+	if cfg.HmsFailLevel > 0 {
+		limits.HmsFailLevel = cfg.HmsFailLevel
+	}
+	limits.RequireRTK = cfg.RequireRTK
+
+	// 替换同名内置规则，保留通过 AddRule 增加的自定义规则
+	for _, rule := range DefaultRules(limits) {
+		defaultPipeline.AddRule(rule)
+	}
+	defaultPipeline.SetDisabled(cfg.DisabledRules...)
+	defaultAudit.SetFile(cfg.AuditFile)
+	defaultAudit.SetLimit(cfg.AuditLimit)
+	SetEnabled(cfg.Enabled == nil || *cfg.Enabled)
+}
@@ -0,0 +1,201 @@
+// Package preflight 飞前安全检查
+// 下发飞行任务或一键起飞前采集设备状态、HMS告警与天气，按规则逐项给出通过、警告或不通过，
+// 任一规则不通过时阻止下发，检查报告随任务保存以备审计。
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status 检查结果
+type Status string
+
+const (
+	StatusPass Status = "pass" // 通过
+	StatusWarn Status = "warn" // 警告，不阻止下发
+	StatusFail Status = "fail" // 不通过，阻止下发
+)
+
+// severity 结果严重程度，用于汇总
+func (s Status) severity() int {
+	switch s {
+	case StatusWarn:
+		return 1
+	case StatusFail:
+		return 2
+	}
+	return 0
+}
+
+// ErrPreflightFailed 飞前检查未通过
+var ErrPreflightFailed = errors.New("飞前检查未通过")
+
+// Result 单条规则的检查结果
+type Result struct {
+	Rule   string `json:"rule"`
+	Status Status `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// Pass 通过
+func Pass(format string, args ...interface{}) Result {
+	return Result{Status: StatusPass, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Warn 警告
+func Warn(format string, args ...interface{}) Result {
+	return Result{Status: StatusWarn, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Fail 不通过
+func Fail(format string, args ...interface{}) Result {
+	return Result{Status: StatusFail, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Report 飞前检查报告
+type Report struct {
+	ID        string    `json:"id"`
+	MissionID string    `json:"mission_id,omitempty"` // 下发成功后关联的任务编号
+	DockSN    string    `json:"dock_sn"`
+	DroneSN   string    `json:"drone_sn,omitempty"`
+	Status    Status    `json:"status"`
+	Results   []Result  `json:"results"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Err 存在不通过的规则时返回包装 ErrPreflightFailed 的错误；未启用检查（nil）时返回nil
+func (r *Report) Err() error {
+	if r == nil || r.Status != StatusFail {
+		return nil
+	}
+	var reasons []string
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", result.Rule, result.Reason))
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrPreflightFailed, strings.Join(reasons, "; "))
+}
+
+// Checked 报告中是否包含指定规则的结果；未启用检查（nil）或规则被停用时返回 false
+func (r *Report) Checked(rule string) bool {
+	if r == nil {
+		return false
+	}
+	for _, result := range r.Results {
+		if result.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule 飞前检查规则
+type Rule interface {
+	// Name 规则名称，用于配置启停与报告
+	Name() string
+	// Check 根据快照给出检查结果
+	Check(ctx context.Context, snap *Snapshot) Result
+}
+
+// RuleFunc 以函数实现的规则
+type RuleFunc struct {
+	RuleName string
+	Fn       func(ctx context.Context, snap *Snapshot) Result
+}
+
+func (r RuleFunc) Name() string { return r.RuleName }
+
+func (r RuleFunc) Check(ctx context.Context, snap *Snapshot) Result { return r.Fn(ctx, snap) }
+
+// Pipeline 飞前检查流水线，按注册顺序执行规则
+type Pipeline struct {
+	mu       sync.RWMutex
+	rules    []Rule
+	disabled map[string]bool
+}
+
+// NewPipeline 创建检查流水线
+func NewPipeline(rules ...Rule) *Pipeline {
+	return &Pipeline{rules: rules, disabled: make(map[string]bool)}
+}
+
+// AddRule 追加规则，同名规则会被替换
+func (p *Pipeline) AddRule(rule Rule) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, existing := range p.rules {
+		if existing.Name() == rule.Name() {
+			p.rules[i] = rule
+			return
+		}
+	}
+	p.rules = append(p.rules, rule)
+}
+
+// SetDisabled 设置停用的规则，覆盖之前的设置
+func (p *Pipeline) SetDisabled(names ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.disabled = make(map[string]bool)
+	for _, name := range names {
+		p.disabled[name] = true
+	}
+}
+
+// Rules 当前启用的规则名称
+func (p *Pipeline) Rules() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var names []string
+	for _, rule := range p.rules {
+		if !p.disabled[rule.Name()] {
+			names = append(names, rule.Name())
+		}
+	}
+	return names
+}
+
+// Evaluate 对已采集的快照执行全部启用的规则
+func (p *Pipeline) Evaluate(ctx context.Context, snap *Snapshot) *Report {
+	p.mu.RLock()
+	rules := make([]Rule, 0, len(p.rules))
+	for _, rule := range p.rules {
+		if !p.disabled[rule.Name()] {
+			rules = append(rules, rule)
+		}
+	}
+	p.mu.RUnlock()
+
+	report := &Report{
+		ID:        uuid.New().String(),
+		DockSN:    snap.DockSN,
+		DroneSN:   snap.DroneSN,
+		Status:    StatusPass,
+		CheckedAt: time.Now(),
+	}
+	for _, rule := range rules {
+		result := rule.Check(ctx, snap)
+		result.Rule = rule.Name()
+		if result.Status == "" {
+			result.Status = StatusPass
+		}
+		if result.Status.severity() > report.Status.severity() {
+			report.Status = result.Status
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// Run 采集数据并执行检查
+func (p *Pipeline) Run(ctx context.Context, src Sources, dockSn, droneSn string) *Report {
+	return p.Evaluate(ctx, Collect(ctx, src, dockSn, droneSn))
+}
@@ -0,0 +1,153 @@
+package preflight
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/hms"
+)
+
+func intPtr(v int) *int { return &v }
+
+// resultOf 报告中指定规则的结果
+func resultOf(t *testing.T, report *Report, rule string) Result {
+	t.Helper()
+	for _, result := range report.Results {
+		if result.Rule == rule {
+			return result
+		}
+	}
+	t.Fatalf("报告中没有规则 %s 的结果: %+v", rule, report.Results)
+	return Result{}
+}
+
+// TestRules 电量、RTK、HMS 规则按阈值给出通过、警告或不通过
+func TestRules(t *testing.T) {
+	strict := DefaultLimits()
+	strict.RequireRTK = true
+	tests := []struct {
+		name   string
+		limits Limits
+		rule   string
+		snap   Snapshot
+		want   Status
+	}{
+		{"电量充足", DefaultLimits(), RuleBattery, Snapshot{Drone: &DeviceState{Battery: intPtr(90)}}, StatusPass},
+		{"电量偏低", DefaultLimits(), RuleBattery, Snapshot{Drone: &DeviceState{Battery: intPtr(60)}}, StatusWarn},
+		{"电量不足", DefaultLimits(), RuleBattery, Snapshot{Drone: &DeviceState{Battery: intPtr(49)}}, StatusFail},
+		{"使用机场上报的舱内电量", DefaultLimits(), RuleBattery, Snapshot{Dock: &DeviceState{Battery: intPtr(30)}}, StatusFail},
+		{"未获取到电量", DefaultLimits(), RuleBattery, Snapshot{}, StatusWarn},
+		{"RTK已收敛", strict, RuleRTK, Snapshot{Drone: &DeviceState{RtkFixed: intPtr(2)}}, StatusPass},
+		{"RTK未收敛仅警告", DefaultLimits(), RuleRTK, Snapshot{Drone: &DeviceState{RtkFixed: intPtr(1)}}, StatusWarn},
+		{"要求RTK时未收敛不通过", strict, RuleRTK, Snapshot{Drone: &DeviceState{RtkFixed: intPtr(3)}}, StatusFail},
+		{"要求RTK时缺少状态不通过", strict, RuleRTK, Snapshot{}, StatusFail},
+		{"无HMS告警", DefaultLimits(), RuleHms, Snapshot{}, StatusPass},
+		{"HMS提醒", DefaultLimits(), RuleHms, Snapshot{Hms: []hms.Alarm{{DeviceSN: "SN1", Level: 1, Code: "0x16100083"}}}, StatusWarn},
+		{"HMS警告", DefaultLimits(), RuleHms, Snapshot{Hms: []hms.Alarm{{DeviceSN: "SN1", Level: 1, Code: "0x16100083"}, {DeviceSN: "SN1", Level: 2, Code: "0x1b030011"}}}, StatusFail},
+		{"HMS获取失败", DefaultLimits(), RuleHms, Snapshot{HmsErr: errors.New("超时")}, StatusWarn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewPipeline(DefaultRules(tt.limits)...).Evaluate(context.Background(), &tt.snap)
+			if got := resultOf(t, report, tt.rule); got.Status != tt.want {
+				t.Errorf("规则 %s 结果 %s（%s），应为 %s", tt.rule, got.Status, got.Reason, tt.want)
+			}
+		})
+	}
+}
+
+// TestPipeline 报告状态取最严重的结果，停用的规则不执行，自定义规则替换同名规则
+func TestPipeline(t *testing.T) {
+	ctx := context.Background()
+	snap := &Snapshot{DockSN: "DOCK", Drone: &DeviceState{Battery: intPtr(30), RtkFixed: intPtr(1)}}
+	p := NewPipeline(DefaultRules(DefaultLimits())...)
+
+	report := p.Evaluate(ctx, snap)
+	if report.Status != StatusFail || !errors.Is(report.Err(), ErrPreflightFailed) || !strings.Contains(report.Err().Error(), RuleBattery) {
+		t.Fatalf("电量不足时应不通过: %s, %v", report.Status, report.Err())
+	}
+
+	p.SetDisabled(RuleBattery, RuleOnline, RuleMission, RuleCover)
+	report = p.Evaluate(ctx, snap)
+	if report.Checked(RuleBattery) || report.Status != StatusWarn || report.Err() != nil {
+		t.Errorf("停用电量规则后应只剩警告: %s, %+v", report.Status, report.Results)
+	}
+	if got := strings.Join(p.Rules(), ","); got != "rtk,hms,weather" {
+		t.Errorf("启用的规则 %s", got)
+	}
+
+	p.SetDisabled()
+	p.AddRule(RuleFunc{RuleBattery, func(context.Context, *Snapshot) Result { return Pass("已人工确认") }})
+	if got := resultOf(t, p.Evaluate(ctx, snap), RuleBattery); got.Status != StatusPass || got.Reason != "已人工确认" {
+		t.Errorf("同名规则应被替换: %+v", got)
+	}
+	if len(p.Rules()) != len(DefaultRules(DefaultLimits())) {
+		t.Errorf("替换规则不应增加规则数量: %v", p.Rules())
+	}
+
+	var nilReport *Report
+	if nilReport.Err() != nil || nilReport.Checked(RuleWeather) {
+		t.Error("未启用检查（nil 报告）时不应阻止下发")
+	}
+}
+
+// TestAuditLimit 内存只保留最近的报告，完整记录追加写入存档文件
+func TestAuditLimit(t *testing.T) {
+	audit := NewAuditLog()
+	file := filepath.Join(t.TempDir(), "preflight.jsonl")
+	audit.SetFile(file)
+	audit.SetLimit(2)
+
+	for i := 1; i <= 3; i++ {
+		report := &Report{ID: fmt.Sprintf("r%d", i), MissionID: "m1", Status: StatusPass}
+		if err := audit.Record(report); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := audit.Get("r1"); ok {
+		t.Error("超出上限的最早报告应被淘汰")
+	}
+	if _, ok := audit.Get("r3"); !ok {
+		t.Error("最近的报告应保留")
+	}
+	if got := audit.ForMission("m1"); len(got) != 2 {
+		t.Errorf("任务关联报告 %d 条，应为 2", len(got))
+	}
+
+	// 更新已有报告不占用新的名额
+	if err := audit.Record(&Report{ID: "r3", MissionID: "m2", Status: StatusFail}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := audit.Get("r2"); !ok {
+		t.Error("更新已有报告不应淘汰其他报告")
+	}
+	audit.SetLimit(1)
+	if _, ok := audit.Get("r2"); ok {
+		t.Error("调低上限后应立即淘汰")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var ids []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var report Report
+		if err := json.Unmarshal(scanner.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, report.ID)
+	}
+	if got := strings.Join(ids, ","); got != "r1,r2,r3,r3" {
+		t.Errorf("存档文件记录 %s，应包含全部报告", got)
+	}
+}
@@ -0,0 +1,171 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/hms"
+)
+
+// Limits 内置规则的阈值
+type Limits struct {
+	MinBattery   int  // 电量低于该值不通过（%）
+	WarnBattery  int  // 电量低于该值警告（%）
+	RequireRTK   bool // 定位未收敛时不通过，否则仅警告
+	HmsFailLevel int  // HMS告警达到该等级不通过：1提醒 2警告
+}
+
+// DefaultLimits 默认阈值，参考机场2飞行器的作业条件；天气门限由 weather 包统一配置
+func DefaultLimits() Limits {
+	return Limits{
+		MinBattery:   50,
+		WarnBattery:  80,
+		RequireRTK:   false,
+		HmsFailLevel: 2,
+	}
+}
+
+// 内置规则名称
+const (
+	RuleOnline  = "online"
+	RuleMission = "mission"
+	RuleCover   = "cover"
+	RuleBattery = "battery"
+	RuleRTK     = "rtk"
+	RuleHms     = "hms"
+	RuleWeather = "weather"
+)
+
+// DefaultRules 内置规则：设备在线、是否在执行任务、舱盖、电量、RTK、HMS、天气
+func DefaultRules(limits Limits) []Rule {
+	return []Rule{
+		RuleFunc{RuleOnline, checkOnline},
+		RuleFunc{RuleMission, checkMission},
+		RuleFunc{RuleCover, checkCover},
+		RuleFunc{RuleBattery, func(_ context.Context, snap *Snapshot) Result { return checkBattery(snap, limits) }},
+		RuleFunc{RuleRTK, func(_ context.Context, snap *Snapshot) Result { return checkRTK(snap, limits) }},
+		RuleFunc{RuleHms, func(_ context.Context, snap *Snapshot) Result { return checkHms(snap, limits) }},
+		RuleFunc{RuleWeather, checkWeather},
+	}
+}
+
+// checkOnline 机场在线
+func checkOnline(_ context.Context, snap *Snapshot) Result {
+	if snap.Dock == nil {
+		return Fail("无法获取机场 %s 状态: %v", snap.DockSN, snap.DockErr)
+	}
+	if snap.Dock.Online != nil && !*snap.Dock.Online {
+		return Fail("机场 %s 离线", snap.DockSN)
+	}
+	return Pass("机场在线")
+}
+
+// checkMission 机场空闲、飞行器在舱且未在执行任务
+func checkMission(_ context.Context, snap *Snapshot) Result {
+	if snap.Dock == nil {
+		return Fail("无法获取机场状态")
+	}
+	if mode := snap.Dock.ModeCode; mode != nil && *mode != 0 {
+		return Fail("机场非空闲状态（mode_code=%d），可能正在作业或调试", *mode)
+	}
+	if inDock := snap.Dock.DroneInDock; inDock != nil && !*inDock {
+		return Fail("飞行器不在舱内")
+	}
+	// 飞行器在舱内通常关机，离线视为待机
+	if drone := snap.Drone; drone != nil && (drone.Online == nil || *drone.Online) {
+		if mode := drone.ModeCode; mode != nil && *mode != 0 {
+			return Fail("飞行器非待机状态（mode_code=%d），可能正在执行任务", *mode)
+		}
+	}
+	return Pass("机场空闲")
+}
+
+// checkCover 舱盖状态
+func checkCover(_ context.Context, snap *Snapshot) Result {
+	if snap.Dock == nil || snap.Dock.CoverState == nil {
+		return Warn("未获取到舱盖状态")
+	}
+	switch *snap.Dock.CoverState {
+	case 0, 1:
+		return Pass("舱盖正常")
+	case 2:
+		return Warn("舱盖半开")
+	}
+	return Fail("舱盖状态异常（cover_state=%d）", *snap.Dock.CoverState)
+}
+
+// checkBattery 飞行器电量，优先使用飞行器上报，其次机场上报的舱内充电电量
+func checkBattery(snap *Snapshot, limits Limits) Result {
+	var battery *int
+	if snap.Drone != nil {
+		battery = snap.Drone.Battery
+	}
+	if battery == nil && snap.Dock != nil {
+		battery = snap.Dock.Battery
+	}
+	if battery == nil {
+		return Warn("未获取到飞行器电量")
+	}
+	switch {
+	case *battery < limits.MinBattery:
+		return Fail("电量 %d%% 低于 %d%%", *battery, limits.MinBattery)
+	case *battery < limits.WarnBattery:
+		return Warn("电量 %d%% 低于 %d%%", *battery, limits.WarnBattery)
+	}
+	return Pass("电量 %d%%", *battery)
+}
+
+// checkRTK 定位收敛状态
+func checkRTK(snap *Snapshot, limits Limits) Result {
+	notFixed := Warn
+	if limits.RequireRTK {
+		notFixed = Fail
+	}
+	if snap.Drone == nil || snap.Drone.RtkFixed == nil {
+		return notFixed("未获取到RTK定位状态")
+	}
+	if *snap.Drone.RtkFixed != 2 {
+		return notFixed("RTK未收敛（is_fixed=%d）", *snap.Drone.RtkFixed)
+	}
+	return Pass("RTK已收敛")
+}
+
+// checkHms HMS告警，未提供HMS能力时跳过
+func checkHms(snap *Snapshot, limits Limits) Result {
+	if snap.HmsErr != nil {
+		return Warn("获取HMS告警失败: %v", snap.HmsErr)
+	}
+	var fail, warn []string
+	for _, alarm := range snap.Hms {
+		decoded := hms.Decode(alarm)
+		desc := fmt.Sprintf("%s %s(%s)", decoded.Code, decoded.Message, alarm.DeviceSN)
+		switch {
+		case alarm.Level >= limits.HmsFailLevel:
+			fail = append(fail, desc)
+		case alarm.Level > 0:
+			warn = append(warn, desc)
+		}
+	}
+	switch {
+	case len(fail) > 0:
+		return Fail("存在 %d 条HMS告警: %v", len(fail), fail)
+	case len(warn) > 0:
+		return Warn("存在 %d 条HMS提醒: %v", len(warn), warn)
+	}
+	return Pass("无HMS告警")
+}
+
+// checkWeather 任务时段天气，按 weather 包的全局门限评估，未启用天气门限时通过
+func checkWeather(_ context.Context, snap *Snapshot) Result {
+	a := snap.WeatherCheck
+	switch {
+	case snap.WeatherErr != nil:
+		return Fail("%v", snap.WeatherErr)
+	case a == nil:
+		return Pass("未启用天气门限")
+	case len(a.Warnings) > 0:
+		return Warn("%s", strings.Join(a.Warnings, "; "))
+	}
+	return Pass("天气适宜飞行")
+}
@@ -0,0 +1,217 @@
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+	"gitee.com/jamespi/drone_dispatch/pkg/hms"
+	"gitee.com/jamespi/drone_dispatch/pkg/weather"
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// Sources 飞前检查的数据来源，未实现的能力可为nil，对应规则按缺少数据处理
+type Sources struct {
+	Telemetry service.TelemetrySource // 设备物模型（遥测）状态
+	Hms       service.HmsProvider     // 设备HMS告警
+	Weather   weather.WeatherProvider // 任务区域天气，为nil时使用机场状态中的环境数据
+}
+
+// DeviceState 从遥测响应中提取的检查所需字段，兼容司空2设备状态与机场2 OSD 两种格式
+// 指针字段为nil表示响应中没有该项
+type DeviceState struct {
+	SN          string
+	Online      *bool
+	ModeCode    *int
+	CoverState  *int     // 舱盖状态：0关闭 1打开 2半开 3异常
+	DroneInDock *bool    // 飞行器是否在舱
+	Battery     *int     // 飞行器电量，机场为舱内飞行器充电电量
+	RtkFixed    *int     // 定位收敛状态：0未开始 1收敛中 2收敛成功 3收敛失败
+	WindSpeed   *float64 // 风速（米/秒）
+	Rainfall    *int     // 降雨量：0无雨 1小雨 2中雨 3大雨
+	Temperature *float64 // 环境温度（摄氏度）
+	Location    *geo.Point
+}
+
+// Weather 任务区域天气，来自机场环境数据
+type Weather struct {
+	WindSpeed   *float64 // 风速（米/秒）
+	Rainfall    *int     // 降雨量：0无雨 1小雨 2中雨 3大雨
+	Temperature *float64 // 环境温度（摄氏度）
+}
+
+// Snapshot 一次飞前检查使用的全部数据
+type Snapshot struct {
+	DockSN       string
+	DroneSN      string
+	Dock         *DeviceState
+	Drone        *DeviceState
+	Hms          []hms.Alarm
+	Weather      Weather
+	WeatherCheck *weather.Assessment // 按全局天气门限评估的任务时段天气，未启用天气门限时为nil
+	DockErr      error               // 获取机场状态失败的原因
+	DroneErr     error               // 获取飞行器状态失败的原因
+	HmsErr       error               // 获取HMS失败的原因，未提供HMS能力时为nil且 Hms 为空
+	WeatherErr   error               // 天气超出门限，或配置 block_on_unknown 时无法获取天气的原因
+}
+
+// Collect 采集飞前检查数据，单项获取失败记录在快照中由规则判定，不中断采集
+func Collect(ctx context.Context, src Sources, dockSn, droneSn string) *Snapshot {
+	snap := &Snapshot{DockSN: dockSn, DroneSN: droneSn}
+	if src.Telemetry != nil {
+		snap.Dock, snap.DockErr = fetchState(ctx, src.Telemetry, dockSn)
+		if droneSn != "" {
+			snap.Drone, snap.DroneErr = fetchState(ctx, src.Telemetry, droneSn)
+		}
+	} else {
+		snap.DockErr = fmt.Errorf("适配器不支持获取设备状态")
+	}
+	if src.Hms != nil {
+		snList := dockSn
+		if droneSn != "" {
+			snList += "," + droneSn
+		}
+		snap.Hms, snap.HmsErr = fetchHms(ctx, src.Hms, snList)
+	}
+	if snap.Dock != nil {
+		snap.Weather = Weather{WindSpeed: snap.Dock.WindSpeed, Rainfall: snap.Dock.Rainfall, Temperature: snap.Dock.Temperature}
+	}
+	// 机场未上报风速时使用飞行器测得的风速
+	if snap.Weather.WindSpeed == nil && snap.Drone != nil {
+		snap.Weather.WindSpeed = snap.Drone.WindSpeed
+	}
+	provider := src.Weather
+	if provider == nil {
+		w := snap.Weather
+		provider = weather.NewStaticProvider(weather.Conditions{WindSpeed: w.WindSpeed, Rainfall: w.Rainfall, Temperature: w.Temperature})
+	}
+	q := weather.Query{DockSN: dockSn}
+	if snap.Dock != nil && snap.Dock.Location != nil {
+		q.Location = *snap.Dock.Location
+	}
+	snap.WeatherCheck, snap.WeatherErr = weather.Check(ctx, provider, q)
+	return snap
+}
+
+// fetchState 获取并解析设备状态
+func fetchState(ctx context.Context, source service.TelemetrySource, sn string) (*DeviceState, error) {
+	// 规则与天气查询按 WGS-84 计算，不随调用方请求的输出坐标系变化
+	resp, err := source.GetDeviceState(geo.WithCoordSystem(ctx, geo.WGS84), sn)
+	if err != nil {
+		return nil, err
+	}
+	state, err := ParseDeviceState([]byte(resp))
+	if err != nil {
+		return nil, err
+	}
+	if state.SN == "" {
+		state.SN = sn
+	}
+	return state, nil
+}
+
+// ParseDeviceState 解析遥测响应
+// 支持司空2 {"data":{"device_state":{...}}} 与机场2 {"sn":..,"osd":{...},"state":{...}} 两种格式
+func ParseDeviceState(data []byte) (*DeviceState, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("解析设备状态失败: %w", err)
+	}
+	if inner, ok := doc["data"].(map[string]interface{}); ok {
+		doc = inner
+	}
+	if inner, ok := doc["device_state"].(map[string]interface{}); ok {
+		doc = inner
+	}
+	fields := make(map[string]interface{})
+	for _, key := range []string{"state", "osd"} {
+		if inner, ok := doc[key].(map[string]interface{}); ok {
+			for k, v := range inner {
+				fields[k] = v
+			}
+		}
+	}
+	for k, v := range doc {
+		if _, exists := fields[k]; !exists {
+			fields[k] = v
+		}
+	}
+
+	state := &DeviceState{
+		ModeCode:    intField(fields, "mode_code"),
+		CoverState:  intField(fields, "cover_state"),
+		DroneInDock: boolField(fields, "drone_in_dock"),
+		Online:      boolField(fields, "online"),
+		WindSpeed:   floatField(fields, "wind_speed"),
+		Rainfall:    intField(fields, "rainfall"),
+		Temperature: floatField(fields, "environment_temperature"),
+	}
+	state.SN, _ = fields["sn"].(string)
+	if battery, ok := fields["battery"].(map[string]interface{}); ok {
+		state.Battery = intField(battery, "capacity_percent")
+	}
+	if state.Battery == nil {
+		if charge, ok := fields["drone_charge_state"].(map[string]interface{}); ok {
+			state.Battery = intField(charge, "capacity_percent")
+		}
+	}
+	if position, ok := fields["position_state"].(map[string]interface{}); ok {
+		state.RtkFixed = intField(position, "is_fixed")
+	}
+	lat, lng := floatField(fields, "latitude"), floatField(fields, "longitude")
+	if lat != nil && lng != nil && (*lat != 0 || *lng != 0) {
+		state.Location = &geo.Point{Lat: *lat, Lng: *lng}
+	}
+	return state, nil
+}
+
+// fetchHms 获取并解析HMS告警列表
+func fetchHms(ctx context.Context, provider service.HmsProvider, snList string) ([]hms.Alarm, error) {
+	resp, err := provider.GetDeviceHms(ctx, snList)
+	if err != nil {
+		return nil, err
+	}
+	return hms.ParseList([]byte(resp))
+}
+
+func floatField(fields map[string]interface{}, key string) *float64 {
+	n, ok := fields[key].(json.Number)
+	if !ok {
+		return nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+func intField(fields map[string]interface{}, key string) *int {
+	f := floatField(fields, key)
+	if f == nil {
+		return nil
+	}
+	i := int(*f)
+	return &i
+}
+
+// boolField 读取布尔字段，兼容 0/1 数值与 "true"/"false" 字符串
+func boolField(fields map[string]interface{}, key string) *bool {
+	var b bool
+	switch v := fields[key].(type) {
+	case bool:
+		b = v
+	case json.Number:
+		b = v.String() != "0"
+	case string:
+		b = strings.EqualFold(v, "true") || v == "1"
+	default:
+		return nil
+	}
+	return &b
+}
@@ -0,0 +1,125 @@
+package reconstruct
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/plugin"
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+var (
+	defaultMu           sync.RWMutex
+	defaultOrchestrator = NewOrchestrator(Options{})
+)
+
+// Default 全局重建编排器，未配置 Reconstruct 段时使用内存登记表
+func Default() *Orchestrator {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultOrchestrator
+}
+
+// ApplyConfig 按配置文件 Reconstruct 段替换全局编排器，server 提供租户的服务凭据
+// 正在跟踪的模型由新编排器接管，更换登记表文件时未结束的模型迁移到新登记表；之后恢复登记表中其余未结束模型的跟踪
+func ApplyConfig(cfg *config.Reconstruct, server *config.Server) error {
+	next, err := NewOrchestratorFromConfig(cfg, serviceTokens(server))
+	if err != nil {
+		return err
+	}
+	defaultMu.Lock()
+	prev := defaultOrchestrator
+	// 登记表文件不变时沿用内存中的登记表
+	if prev.opts.Registry.file == next.opts.Registry.file {
+		next.opts.Registry = prev.opts.Registry
+	} else if err := migrate(prev.opts.Registry, next.opts.Registry); err != nil {
+		defaultMu.Unlock()
+		next.Stop()
+		return err
+	}
+	defaultOrchestrator = next
+	defaultMu.Unlock()
+	next.adopt(prev)
+	prev.Stop()
+	next.Resume()
+	return nil
+}
+
+// migrate 把未结束的模型迁移到新登记表，新登记表中已有的记录不覆盖，已结束的记录保留在原登记表
+func migrate(from, to *Registry) error {
+	migrated := 0
+	for _, job := range from.List(Filter{}) {
+		if job.Terminal() {
+			continue
+		}
+		if _, exists := to.Get(job.ModelID); exists {
+			continue
+		}
+		if err := to.Put(job); err != nil {
+			return fmt.Errorf("迁移未结束的重建任务失败: %w", err)
+		}
+		migrated++
+	}
+	if migrated > 0 {
+		log.Printf("重建登记表由 %q 更换为 %q，已迁移 %d 个未结束的模型", from.file, to.file, migrated)
+	}
+	return nil
+}
+
+// NewOrchestratorFromConfig 按配置创建编排器，cfg 为nil时使用默认值与内存登记表
+// credentials 为租户的服务凭据，跟踪进度时使用；恢复跟踪时按租户获取司空2插件实例
+func NewOrchestratorFromConfig(cfg *config.Reconstruct, credentials tenant.ServiceTokens) (*Orchestrator, error) {
+	if cfg == nil {
+		return NewOrchestrator(Options{Credentials: credentials, Sources: pluginSource}), nil
+	}
+	file := cfg.RegistryFile
+	if file == "" {
+		file = "./reconstruct.jsonl"
+	}
+	registry, err := OpenRegistry(file)
+	if err != nil {
+		return nil, err
+	}
+	opts := Options{
+		Registry:        registry,
+		PollInterval:    time.Duration(cfg.PollInterval) * time.Second,
+		MaxPollInterval: time.Duration(cfg.MaxPollInterval) * time.Second,
+		Timeout:         time.Duration(cfg.Timeout) * time.Second,
+		MinImages:       cfg.MinImages,
+		Credentials:     credentials,
+		Sources:         pluginSource,
+	}
+	if cfg.NotifyURL != "" {
+		opts.Notifier = NewNotifier(httpclient.NewSecureHTTPClient(), cfg.NotifyURL)
+	}
+	return NewOrchestrator(opts), nil
+}
+
+// serviceTokens 配置文件 Server 段登记的租户服务凭据
+func serviceTokens(server *config.Server) tenant.ServiceTokens {
+	tokens := make(tenant.ServiceTokens)
+	if server == nil {
+		return tokens
+	}
+	for _, grant := range server.Tenants {
+		if grant.ServiceToken != "" {
+			tokens[grant.TenantID] = grant.ServiceToken
+		}
+	}
+	return tokens
+}
+
+// pluginSource 按上下文中的租户获取司空2插件实例
+func pluginSource(ctx context.Context) (Source, error) {
+	adapter, ok := plugin.GetWithContext[service.FH2DroneAdapter](ctx, plugin.FH2Plugin)
+	if !ok {
+		return nil, fmt.Errorf("插件 %s 未启用", plugin.FH2Plugin)
+	}
+	return adapter, nil
+}
@@ -0,0 +1,108 @@
+// Package reconstruct 三维/二维重建任务编排：从已结束的飞行任务选取照片提交司空2模型重建，
+// 退避轮询重建进度，完成或失败时通知，并记录模型与源飞行任务的对应关系
+package reconstruct
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 重建状态，processing 之外与司空2模型状态一致
+const (
+	StatusProcessing = "processing" // 重建中
+	StatusSuccess    = "success"    // 重建成功
+	StatusFailed     = "failed"     // 重建失败或等待超时
+)
+
+// IsTerminal 模型状态是否为终态
+func IsTerminal(status string) bool {
+	switch status {
+	case StatusSuccess, StatusFailed, "error":
+		return true
+	}
+	return false
+}
+
+// SourceTask 模型使用的飞行任务及其照片
+type SourceTask struct {
+	TaskUUID   string   `json:"task_uuid"`
+	Name       string   `json:"name,omitempty"`
+	DeviceSN   string   `json:"sn,omitempty"`
+	Status     string   `json:"status"`
+	MediaUUIDs []string `json:"media_uuids"`
+}
+
+// Job 重建任务，记录模型的来源（血缘）与最新状态
+type Job struct {
+	ModelID     int64        `json:"model_id"`
+	Name        string       `json:"name"`
+	TenantID    int64        `json:"tenant_id"`
+	ProjectUUID string       `json:"project_uuid,omitempty"`
+	Sources     []SourceTask `json:"sources"`
+	MediaCount  int          `json:"media_count"`
+	Status      string       `json:"status"`
+	Progress    int          `json:"progress"`
+	Error       string       `json:"error,omitempty"`
+	SubmittedAt time.Time    `json:"submitted_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	FinishedAt  time.Time    `json:"finished_at,omitempty"`
+}
+
+// Terminal 重建是否已结束
+func (j *Job) Terminal() bool {
+	return IsTerminal(j.Status)
+}
+
+// TaskUUIDs 源飞行任务UUID
+func (j *Job) TaskUUIDs() []string {
+	list := make([]string, len(j.Sources))
+	for i, s := range j.Sources {
+		list[i] = s.TaskUUID
+	}
+	return list
+}
+
+// Event 重建状态变化，Job 为变化后的副本
+type Event struct {
+	Job        Job    `json:"job"`
+	PrevStatus string `json:"prev_status,omitempty"`
+}
+
+// modelInfo 司空2模型详情
+type modelInfo struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Progress int    `json:"progress"`
+}
+
+// parseModel 解析模型详情，格式为司空2 {"code":0,"data":{"id":..,"status":..}}
+func parseModel(resp string) (modelInfo, error) {
+	var body struct {
+		Data *modelInfo `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(resp), &body); err != nil {
+		return modelInfo{}, fmt.Errorf("解析模型详情失败: %w", err)
+	}
+	if body.Data == nil || body.Data.Status == "" {
+		return modelInfo{}, fmt.Errorf("模型详情缺少状态")
+	}
+	return *body.Data, nil
+}
+
+// parseModelID 解析创建模型的响应 {"code":0,"data":{"model_id":..}}
+func parseModelID(resp string) (int64, error) {
+	var body struct {
+		Data struct {
+			ModelID int64 `json:"model_id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(resp), &body); err != nil {
+		return 0, fmt.Errorf("解析模型重建响应失败: %w", err)
+	}
+	if body.Data.ModelID <= 0 {
+		return 0, fmt.Errorf("模型重建响应缺少 model_id")
+	}
+	return body.Data.ModelID, nil
+}
@@ -0,0 +1,101 @@
+package reconstruct
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+)
+
+var (
+	listenersMu sync.RWMutex
+	listeners   = make(map[int]func(Event))
+	nextID      int
+)
+
+// OnEvent 订阅重建状态变化（提交、进度、结束），返回取消订阅函数
+func OnEvent(handler func(Event)) (unsubscribe func()) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+	id := nextID
+	nextID++
+	listeners[id] = handler
+	return func() {
+		listenersMu.Lock()
+		defer listenersMu.Unlock()
+		delete(listeners, id)
+	}
+}
+
+func publish(event Event) {
+	listenersMu.RLock()
+	handlers := make([]func(Event), 0, len(listeners))
+	for _, handler := range listeners {
+		handlers = append(handlers, handler)
+	}
+	listenersMu.RUnlock()
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// Notifier 重建结束时以 POST JSON（Event）通知回调地址，失败按退避重试
+type Notifier struct {
+	client  *httpclient.SecureHTTPClient
+	url     string
+	Retries int
+}
+
+// NewNotifier 创建回调通知
+func NewNotifier(client *httpclient.SecureHTTPClient, url string) *Notifier {
+	return &Notifier{client: client, url: url, Retries: 3}
+}
+
+// Notify 发送通知，返回最后一次失败的错误
+func (n *Notifier) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("序列化通知失败: %w", err)
+	}
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		err = n.send(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if attempt >= n.Retries || ctx.Err() != nil {
+			log.Printf("模型 %d 重建结果通知失败: %v", event.Job.ModelID, err)
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (n *Notifier) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建通知请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "DroneDispatch/1.0")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("回调地址返回 %s", resp.Status)
+	}
+	return nil
+}
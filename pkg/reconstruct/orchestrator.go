@@ -0,0 +1,438 @@
+package reconstruct
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/media"
+	"gitee.com/jamespi/drone_dispatch/pkg/telemetry"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+)
+
+var (
+	// ErrInvalidRequest 重建请求参数错误
+	ErrInvalidRequest = errors.New("重建请求无效")
+	// ErrTaskNotFinished 源飞行任务尚未结束
+	ErrTaskNotFinished = errors.New("飞行任务尚未结束")
+	// ErrNotEnoughMedia 源飞行任务的照片数量不足
+	ErrNotEnoughMedia = errors.New("可用于重建的照片不足")
+	// ErrNotFound 重建任务不存在
+	ErrNotFound = errors.New("重建任务不存在")
+)
+
+// Source 提交与查询模型重建所需的适配器能力，司空2适配器满足该接口
+type Source interface {
+	media.Source
+	telemetry.TaskInfoSource
+	CreateModel(ctx context.Context, payLoad io.Reader) (string, error)
+	GetModelInfo(ctx context.Context, modelId int64) (string, error)
+}
+
+// Request 重建请求
+type Request struct {
+	Name      string                 `json:"name"`
+	TaskUUIDs []string               `json:"task_uuids"`       // 源飞行任务，必须已结束
+	Params    map[string]interface{} `json:"params,omitempty"` // 透传给司空2的其他重建参数，例如重建类型、精度
+}
+
+// Options 编排器选项
+type Options struct {
+	Registry        *Registry
+	Notifier        *Notifier     // 重建结束时的通知，为nil时只发布事件
+	PollInterval    time.Duration // 首次查询进度的间隔，进度变化时恢复为该值，默认15秒
+	MaxPollInterval time.Duration // 进度无变化或查询失败时间隔加倍的上限，默认5分钟
+	Timeout         time.Duration // 提交后等待结果的时长，超时记为失败，默认24小时
+	MinImages       int           // 提交所需的最少照片数，默认3
+	// Credentials 租户的服务凭据，跟踪进度时以此查询，不沿用提交请求的用户令牌；未配置的租户不自动跟踪
+	Credentials tenant.ServiceTokens
+	// Sources 按上下文中的租户获取适配器，Resume 恢复跟踪时使用，为nil时不恢复
+	Sources func(ctx context.Context) (Source, error)
+}
+
+// tracked 正在跟踪进度的模型
+type tracked struct {
+	source Source
+	cancel context.CancelFunc
+}
+
+// Orchestrator 重建编排器
+type Orchestrator struct {
+	opts Options
+
+	updateMu sync.Mutex // 串行化登记表的读改写
+
+	mu       sync.Mutex
+	tracking map[int64]*tracked
+	stopped  bool
+}
+
+// NewOrchestrator 创建编排器，Registry 为nil时使用内存登记表
+func NewOrchestrator(opts Options) *Orchestrator {
+	if opts.Registry == nil {
+		opts.Registry = NewRegistry()
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 15 * time.Second
+	}
+	if opts.MaxPollInterval < opts.PollInterval {
+		opts.MaxPollInterval = max(5*time.Minute, opts.PollInterval)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 24 * time.Hour
+	}
+	if opts.MinImages <= 0 {
+		opts.MinImages = 3
+	}
+	return &Orchestrator{opts: opts, tracking: make(map[int64]*tracked)}
+}
+
+// Registry 重建登记表
+func (o *Orchestrator) Registry() *Registry {
+	return o.opts.Registry
+}
+
+// Submit 选取源飞行任务的全部照片提交模型重建，登记来源并开始跟踪进度
+// ctx 需携带调用适配器所需的租户信息，只用于提交；后续跟踪使用租户的服务凭据
+func (o *Orchestrator) Submit(ctx context.Context, source Source, req Request) (*Job, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, fmt.Errorf("%w: 名称不能为空", ErrInvalidRequest)
+	}
+	taskUUIDs := make([]string, 0, len(req.TaskUUIDs))
+	seen := make(map[string]bool)
+	for _, id := range req.TaskUUIDs {
+		if err := validator.GetValidator().ValidateUUID(id); err != nil {
+			return nil, fmt.Errorf("%w: 飞行任务UUID %v", ErrInvalidRequest, err)
+		}
+		if !seen[id] {
+			seen[id] = true
+			taskUUIDs = append(taskUUIDs, id)
+		}
+	}
+	if len(taskUUIDs) == 0 {
+		return nil, fmt.Errorf("%w: 至少需要一个源飞行任务", ErrInvalidRequest)
+	}
+	info, err := tenant.GetTenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	job := Job{Name: req.Name, TenantID: info.TenantId, ProjectUUID: info.ProjectUUID, Status: StatusProcessing}
+	var files []string
+	for _, id := range taskUUIDs {
+		src, err := collect(ctx, source, id)
+		if err != nil {
+			return nil, err
+		}
+		job.Sources = append(job.Sources, src)
+		files = append(files, src.MediaUUIDs...)
+	}
+	if len(files) < o.opts.MinImages {
+		return nil, fmt.Errorf("%w: 共 %d 张，至少需要 %d 张", ErrNotEnoughMedia, len(files), o.opts.MinImages)
+	}
+	job.MediaCount = len(files)
+
+	payload := make(map[string]interface{}, len(req.Params)+3)
+	for k, v := range req.Params {
+		payload[k] = v
+	}
+	payload["name"] = req.Name
+	payload["task_uuids"] = taskUUIDs
+	payload["file_uuids"] = files
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("序列化重建参数失败: %w", err)
+	}
+	resp, err := source.CreateModel(ctx, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("提交模型重建失败: %w", err)
+	}
+	if job.ModelID, err = parseModelID(resp); err != nil {
+		return nil, err
+	}
+	job.SubmittedAt = time.Now()
+	job.UpdatedAt = job.SubmittedAt
+	if err := o.opts.Registry.Put(job); err != nil {
+		return nil, err
+	}
+	publish(Event{Job: job})
+	o.Track(source, job.ModelID)
+	return &job, nil
+}
+
+// collect 确认飞行任务已结束并选取其照片
+func collect(ctx context.Context, source Source, taskUUID string) (SourceTask, error) {
+	resp, err := source.GetFlightTaskInfo(ctx, taskUUID)
+	if err != nil {
+		return SourceTask{}, fmt.Errorf("获取飞行任务 %s 失败: %w", taskUUID, err)
+	}
+	task, err := telemetry.ParseTask([]byte(resp))
+	if err != nil {
+		return SourceTask{}, err
+	}
+	if !task.Terminal() {
+		return SourceTask{}, fmt.Errorf("%w: %s 当前状态 %s", ErrTaskNotFinished, taskUUID, task.Status)
+	}
+	list, err := source.GetFlightTaskMedia(ctx, taskUUID)
+	if err != nil {
+		return SourceTask{}, fmt.Errorf("获取飞行任务 %s 媒体失败: %w", taskUUID, err)
+	}
+	files, err := media.ParseFiles(list)
+	if err != nil {
+		return SourceTask{}, err
+	}
+	src := SourceTask{TaskUUID: taskUUID, Name: task.Name, DeviceSN: task.DeviceSN, Status: task.Status, MediaUUIDs: make([]string, 0, len(files))}
+	for _, f := range files {
+		if isPhoto(f) {
+			src.MediaUUIDs = append(src.MediaUUIDs, f.ID())
+		}
+	}
+	return src, nil
+}
+
+// isPhoto 照片：文件类型为 image，或未给出类型时按扩展名判断
+func isPhoto(f media.File) bool {
+	if f.FileType != "" {
+		return f.FileType == "image"
+	}
+	switch strings.ToLower(path.Ext(f.Name)) {
+	case ".jpg", ".jpeg", ".tif", ".tiff":
+		return true
+	}
+	return false
+}
+
+// Track 以模型所属租户的服务凭据跟踪重建进度直到结束；已在跟踪或已结束时忽略，租户未配置服务凭据时记录日志后忽略
+func (o *Orchestrator) Track(source Source, modelID int64) {
+	job, ok := o.opts.Registry.Get(modelID)
+	if !ok || job.Terminal() {
+		return
+	}
+	ctx, err := o.serviceContext(job)
+	if err != nil {
+		log.Printf("模型 %d 不自动跟踪重建进度: %v", modelID, err)
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stopped || o.tracking[modelID] != nil {
+		return
+	}
+	trackCtx, cancel := context.WithCancel(ctx)
+	t := &tracked{source: source, cancel: cancel}
+	o.tracking[modelID] = t
+	go o.poll(trackCtx, t, modelID)
+}
+
+// Resume 恢复登记表中未结束模型的跟踪，服务启动或更换登记表后调用；未设置 Sources 时不恢复
+func (o *Orchestrator) Resume() {
+	if o.opts.Sources == nil {
+		return
+	}
+	for _, job := range o.opts.Registry.List(Filter{}) {
+		if job.Terminal() {
+			continue
+		}
+		ctx, err := o.serviceContext(job)
+		if err != nil {
+			log.Printf("模型 %d 不自动跟踪重建进度: %v", job.ModelID, err)
+			continue
+		}
+		source, err := o.opts.Sources(ctx)
+		if err != nil {
+			log.Printf("恢复模型 %d 的重建进度跟踪失败: %v", job.ModelID, err)
+			continue
+		}
+		o.Track(source, job.ModelID)
+	}
+}
+
+// serviceContext 以重建任务所属租户的服务凭据构造查询上下文
+func (o *Orchestrator) serviceContext(job Job) (context.Context, error) {
+	info, err := o.opts.Credentials.Service(tenant.NewTenantInfo(job.TenantID, "", job.ProjectUUID))
+	if err != nil {
+		return nil, err
+	}
+	return tenant.WithTenant(context.Background(), info), nil
+}
+
+// Tracking 正在跟踪的模型数
+func (o *Orchestrator) Tracking() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.tracking)
+}
+
+// Stop 停止全部跟踪，进行中的查询结束后不再更新
+func (o *Orchestrator) Stop() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.stopped = true
+	for _, t := range o.tracking {
+		t.cancel()
+	}
+}
+
+// adopt 接管另一编排器正在跟踪的模型（配置重新加载时）
+func (o *Orchestrator) adopt(old *Orchestrator) {
+	old.mu.Lock()
+	list := make(map[int64]*tracked, len(old.tracking))
+	for id, t := range old.tracking {
+		list[id] = t
+	}
+	old.mu.Unlock()
+	for id, t := range list {
+		o.Track(t.source, id)
+	}
+}
+
+// poll 退避轮询：进度变化时恢复初始间隔，无变化或查询失败时加倍，直到结束或超时
+func (o *Orchestrator) poll(ctx context.Context, t *tracked, modelID int64) {
+	defer func() {
+		o.mu.Lock()
+		if o.tracking[modelID] == t {
+			delete(o.tracking, modelID)
+		}
+		o.mu.Unlock()
+		t.cancel()
+	}()
+	interval := o.opts.PollInterval
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		job, ok := o.opts.Registry.Get(modelID)
+		if !ok || job.Terminal() {
+			return
+		}
+		if time.Since(job.SubmittedAt) > o.opts.Timeout {
+			o.update(modelID, StatusFailed, job.Progress, fmt.Sprintf("等待重建结果超过 %s", o.opts.Timeout))
+			return
+		}
+		next, err := o.refresh(ctx, t.source, modelID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("查询模型 %d 重建进度失败: %v", modelID, err)
+			interval = min(interval*2, o.opts.MaxPollInterval)
+			continue
+		}
+		if next.Terminal() {
+			return
+		}
+		if next.Progress != job.Progress {
+			interval = o.opts.PollInterval
+		} else {
+			interval = min(interval*2, o.opts.MaxPollInterval)
+		}
+	}
+}
+
+// Refresh 以 ctx 中的租户立即查询模型状态并更新登记表；未结束且未在跟踪的模型重新开始跟踪
+func (o *Orchestrator) Refresh(ctx context.Context, source Source, modelID int64) (Job, error) {
+	job, ok := o.opts.Registry.Get(modelID)
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %d", ErrNotFound, modelID)
+	}
+	if job.Terminal() {
+		return job, nil
+	}
+	job, err := o.refresh(ctx, source, modelID)
+	if err != nil {
+		return Job{}, err
+	}
+	o.Track(source, modelID)
+	return job, nil
+}
+
+func (o *Orchestrator) refresh(ctx context.Context, source Source, modelID int64) (Job, error) {
+	resp, err := source.GetModelInfo(ctx, modelID)
+	if err != nil {
+		return Job{}, err
+	}
+	info, err := parseModel(resp)
+	if err != nil {
+		return Job{}, err
+	}
+	return o.update(modelID, info.Status, info.Progress, "")
+}
+
+// update 更新状态与进度，有变化时写入登记表并发布事件，结束时通知
+func (o *Orchestrator) update(modelID int64, status string, progress int, errMsg string) (Job, error) {
+	o.updateMu.Lock()
+	job, ok := o.opts.Registry.Get(modelID)
+	if !ok {
+		o.updateMu.Unlock()
+		return Job{}, fmt.Errorf("%w: %d", ErrNotFound, modelID)
+	}
+	if job.Terminal() || (job.Status == status && job.Progress == progress && errMsg == "") {
+		o.updateMu.Unlock()
+		return job, nil
+	}
+	prev := job.Status
+	job.Status, job.Progress, job.UpdatedAt = status, progress, time.Now()
+	if errMsg != "" {
+		job.Error = errMsg
+	}
+	if job.Terminal() {
+		job.FinishedAt = job.UpdatedAt
+		if job.Status == StatusSuccess {
+			job.Progress = 100
+		}
+	}
+	err := o.opts.Registry.Put(job)
+	o.updateMu.Unlock()
+	if err != nil {
+		return Job{}, err
+	}
+
+	event := Event{Job: job}
+	if prev != job.Status {
+		event.PrevStatus = prev
+	}
+	publish(event)
+	if job.Terminal() && o.opts.Notifier != nil {
+		go o.opts.Notifier.Notify(context.Background(), event)
+	}
+	return job, nil
+}
+
+// Wait 等待模型重建结束，返回最终状态
+func (o *Orchestrator) Wait(ctx context.Context, modelID int64) (Job, error) {
+	done := make(chan Job, 1)
+	unsubscribe := OnEvent(func(e Event) {
+		if e.Job.ModelID == modelID && e.Job.Terminal() {
+			select {
+			case done <- e.Job:
+			default:
+			}
+		}
+	})
+	defer unsubscribe()
+	job, ok := o.opts.Registry.Get(modelID)
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %d", ErrNotFound, modelID)
+	}
+	if job.Terminal() {
+		return job, nil
+	}
+	select {
+	case job := <-done:
+		return job, nil
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+}
@@ -0,0 +1,123 @@
+package reconstruct
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+)
+
+// fakeSource 记录查询模型进度时使用的令牌，首次查询即返回重建成功
+type fakeSource struct {
+	mu     sync.Mutex
+	tokens []string
+}
+
+func (f *fakeSource) GetFlightTaskMedia(ctx context.Context, taskUUID string) (string, error) {
+	return `{"code":0,"data":{"list":[]}}`, nil
+}
+
+func (f *fakeSource) GetFlightTaskInfo(ctx context.Context, taskUUID string) (string, error) {
+	return `{"code":0,"data":{}}`, nil
+}
+
+func (f *fakeSource) CreateModel(ctx context.Context, payLoad io.Reader) (string, error) {
+	return `{"code":0,"data":{"model_id":1}}`, nil
+}
+
+func (f *fakeSource) GetModelInfo(ctx context.Context, modelId int64) (string, error) {
+	info, err := tenant.GetTenantFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens = append(f.tokens, info.UserToken)
+	return fmt.Sprintf(`{"code":0,"data":{"id":%d,"status":"success","progress":100}}`, modelId), nil
+}
+
+func (f *fakeSource) seen() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.tokens...)
+}
+
+func processingJob(modelID, tenantID int64) Job {
+	return Job{ModelID: modelID, Name: "测试", TenantID: tenantID, ProjectUUID: "project", Status: StatusProcessing, SubmittedAt: time.Now()}
+}
+
+// TestResumeWithServiceCredential 恢复跟踪登记表中未结束的模型，以租户的服务凭据查询，未配置服务凭据的租户不跟踪
+func TestResumeWithServiceCredential(t *testing.T) {
+	registry, err := OpenRegistry(filepath.Join(t.TempDir(), "reconstruct.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, job := range []Job{processingJob(7, 1), processingJob(8, 2)} {
+		if err := registry.Put(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	source := &fakeSource{}
+	o := NewOrchestrator(Options{
+		Registry:     registry,
+		PollInterval: 10 * time.Millisecond,
+		Credentials:  tenant.ServiceTokens{1: "service-1"},
+		Sources:      func(ctx context.Context) (Source, error) { return source, nil },
+	})
+	defer o.Stop()
+
+	o.Resume()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	job, err := o.Wait(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != StatusSuccess {
+		t.Errorf("模型 7 状态 %s，应为 success", job.Status)
+	}
+	for _, token := range source.seen() {
+		if token != "service-1" {
+			t.Errorf("查询进度使用了令牌 %q，应为服务凭据", token)
+		}
+	}
+	if job, _ := registry.Get(8); job.Status != StatusProcessing || o.Tracking() != 0 {
+		t.Errorf("未配置服务凭据的租户不应跟踪: 状态 %s，跟踪中 %d", job.Status, o.Tracking())
+	}
+}
+
+// TestApplyConfigMigratesRegistry 更换登记表文件时未结束的模型迁移到新登记表
+func TestApplyConfigMigratesRegistry(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() { ApplyConfig(nil, nil) })
+	if err := ApplyConfig(&config.Reconstruct{RegistryFile: filepath.Join(dir, "a.jsonl")}, nil); err != nil {
+		t.Fatal(err)
+	}
+	finished := processingJob(10, 3)
+	finished.Status = StatusSuccess
+	for _, job := range []Job{processingJob(9, 3), finished} {
+		if err := Default().Registry().Put(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ApplyConfig(&config.Reconstruct{RegistryFile: filepath.Join(dir, "b.jsonl")}, nil); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := OpenRegistry(filepath.Join(dir, "b.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job, ok := reopened.Get(9); !ok || job.Status != StatusProcessing {
+		t.Errorf("未结束的模型应迁移到新登记表: %+v, %v", job, ok)
+	}
+	if _, ok := reopened.Get(10); ok {
+		t.Error("已结束的模型应保留在原登记表")
+	}
+}
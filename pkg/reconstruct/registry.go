@@ -0,0 +1,137 @@
+package reconstruct
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Registry 重建任务登记表；指定文件时以 JSON Lines 追加持久化，打开时按顺序回放，同一模型以最后一条为准
+type Registry struct {
+	mu   sync.RWMutex
+	jobs map[int64]*Job
+	file string
+}
+
+// NewRegistry 创建内存登记表
+func NewRegistry() *Registry {
+	return &Registry{jobs: make(map[int64]*Job)}
+}
+
+// OpenRegistry 打开持久化登记表，文件不存在时创建
+func OpenRegistry(file string) (*Registry, error) {
+	r := NewRegistry()
+	r.file = file
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开重建登记表失败: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), 4<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var job Job
+		if err := json.Unmarshal(scanner.Bytes(), &job); err != nil {
+			return nil, fmt.Errorf("重建登记表第 %d 行格式错误: %w", line, err)
+		}
+		r.jobs[job.ModelID] = &job
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取重建登记表失败: %w", err)
+	}
+	return r, nil
+}
+
+// Put 添加或更新重建任务
+func (r *Registry) Put(job Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file != "" {
+		data, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("序列化重建任务失败: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(r.file), 0o755); err != nil {
+			return fmt.Errorf("写入重建登记表失败: %w", err)
+		}
+		f, err := os.OpenFile(r.file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("写入重建登记表失败: %w", err)
+		}
+		_, err = f.Write(append(data, '\n'))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("写入重建登记表失败: %w", err)
+		}
+	}
+	r.jobs[job.ModelID] = &job
+	return nil
+}
+
+// Get 按模型ID查找，返回副本
+func (r *Registry) Get(modelID int64) (Job, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	job, exists := r.jobs[modelID]
+	if !exists {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Filter 列表筛选条件，零值字段不参与筛选
+type Filter struct {
+	TenantID    int64
+	ProjectUUID string
+	TaskUUID    string // 使用了该飞行任务照片的模型
+	Status      string
+}
+
+// List 按提交时间倒序返回匹配的重建任务
+func (r *Registry) List(f Filter) []Job {
+	r.mu.RLock()
+	list := make([]Job, 0)
+	for _, job := range r.jobs {
+		if f.match(job) {
+			list = append(list, *job)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].SubmittedAt.Equal(list[j].SubmittedAt) {
+			return list[i].SubmittedAt.After(list[j].SubmittedAt)
+		}
+		return list[i].ModelID > list[j].ModelID
+	})
+	return list
+}
+
+func (f Filter) match(job *Job) bool {
+	switch {
+	case f.TenantID != 0 && job.TenantID != f.TenantID,
+		f.ProjectUUID != "" && job.ProjectUUID != f.ProjectUUID,
+		f.Status != "" && job.Status != f.Status:
+		return false
+	}
+	if f.TaskUUID == "" {
+		return true
+	}
+	for _, s := range job.Sources {
+		if s.TaskUUID == f.TaskUUID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geofence"
+	"gitee.com/jamespi/drone_dispatch/pkg/hms"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+	"gitee.com/jamespi/drone_dispatch/plugin"
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+/**  设备级接口：按设备序列号选择具备能力的插件  **/
+
+// selectFor 选出为设备提供能力T的插件实例，没有插件管理该设备时返回 404
+func selectFor[T interface{}](ctx context.Context, sn string) (T, error) {
+	capable, err := plugin.SelectForDevice[T](ctx, sn)
+	if err != nil {
+		var zero T
+		return zero, errorf(http.StatusNotFound, CodeNotFound, "%v", err)
+	}
+	return capable.Impl, nil
+}
+
+// deviceState 设备遥测状态，?coord=gcj02 指定输出坐标系
+func (s *Server) deviceState(r *http.Request) (interface{}, error) {
+	sn, err := pathSN(r)
+	if err != nil {
+		return nil, err
+	}
+	source, err := selectFor[service.TelemetrySource](r.Context(), sn)
+	if err != nil {
+		return nil, err
+	}
+	return source.GetDeviceState(r.Context(), sn)
+}
+
+// deviceHms 单台设备的HMS告警；decode=true 时返回解码后的告警
+func (s *Server) deviceHms(r *http.Request) (interface{}, error) {
+	sn, err := pathSN(r)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := selectFor[service.HmsProvider](r.Context(), sn)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := provider.GetDeviceHms(r.Context(), sn)
+	if err != nil {
+		return nil, err
+	}
+	return decodeHms(r, resp)
+}
+
+// decodeHms 按查询参数 decode、lang 决定返回原始告警还是解码后的告警
+func decodeHms(r *http.Request, resp string) (interface{}, error) {
+	if r.URL.Query().Get("decode") != "true" {
+		return resp, nil
+	}
+	lang, err := hms.ParseLang(r.URL.Query().Get("lang"))
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, CodeInvalidArgument, "%v", err)
+	}
+	alarms, err := hms.ParseList([]byte(resp))
+	if err != nil {
+		return nil, err
+	}
+	list := make([]hms.Decoded, 0, len(alarms))
+	for _, alarm := range alarms {
+		list = append(list, hms.DefaultCatalog().Decode(alarm, lang))
+	}
+	return map[string]interface{}{"list": list}, nil
+}
+
+// deviceCommand 实时控制指令，请求体 {"device_command":"return_home"}
+func (s *Server) deviceCommand(r *http.Request) (interface{}, error) {
+	sn, err := pathSN(r)
+	if err != nil {
+		return nil, err
+	}
+	body, err := jsonBody(r)
+	if err != nil {
+		return nil, err
+	}
+	controller, err := selectFor[service.DeviceController](r.Context(), sn)
+	if err != nil {
+		return nil, err
+	}
+	return controller.UpdateDeviceCommand(r.Context(), sn, body)
+}
+
+// snBody 请求体中的设备序列号，用于选择插件
+type snBody struct {
+	SN string `json:"sn" validate:"required,sn"`
+}
+
+// bodySN 读取请求体中的设备序列号，返回请求体副本
+func bodySN(r *http.Request) (string, *bytes.Reader, error) {
+	body, err := jsonBody(r)
+	if err != nil {
+		return "", nil, err
+	}
+	var target snBody
+	if err := json.NewDecoder(body).Decode(&target); err != nil {
+		return "", nil, errorf(http.StatusBadRequest, CodeInvalidArgument, "解析请求体失败: %v", err)
+	}
+	if err := validator.Validate(&target); err != nil {
+		return "", nil, err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return "", nil, err
+	}
+	return target.SN, body, nil
+}
+
+// startLiveStream 开启直播，按请求体中的 sn 选择插件
+func (s *Server) startLiveStream(r *http.Request) (interface{}, error) {
+	sn, body, err := bodySN(r)
+	if err != nil {
+		return nil, err
+	}
+	streamer, err := selectFor[service.LiveStreamer](r.Context(), sn)
+	if err != nil {
+		return nil, err
+	}
+	return streamer.LiveStreamStart(r.Context(), body)
+}
+
+// HeaderOverrideReason 限飞区人工确认原因，创建飞行任务时携带该请求头视为已确认，租户需具备 geofence:override 权限
+const HeaderOverrideReason = "X-Override-Reason"
+
+// withOverride 请求携带 X-Override-Reason 时在上下文中记录人工确认（操作人与原因），租户缺少 geofence:override 权限时返回 403
+func withOverride(r *http.Request) (context.Context, error) {
+	ctx := r.Context()
+	reason := strings.TrimSpace(r.Header.Get(HeaderOverrideReason))
+	if reason == "" {
+		return ctx, nil
+	}
+	info, err := tenant.GetTenantFromContext(ctx)
+	if err != nil {
+		return nil, errorf(http.StatusUnauthorized, CodeUnauthenticated, "%v", err)
+	}
+	if !info.HasPermission(tenant.PermGeofenceOverride) {
+		return nil, errorf(http.StatusForbidden, CodeForbidden, "租户 %d 缺少权限 %s，不能确认放行限飞区", info.TenantId, tenant.PermGeofenceOverride)
+	}
+	return geofence.WithOverride(ctx, info.Operator(), reason), nil
+}
+
+// createFlightTask 创建飞行任务，按请求体中的 sn 选择插件；电子围栏、飞前检查与天气门限的拒绝原因以结构化错误返回
+func (s *Server) createFlightTask(r *http.Request) (interface{}, error) {
+	sn, body, err := bodySN(r)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := withOverride(r)
+	if err != nil {
+		return nil, err
+	}
+	creator, err := selectFor[service.TaskCreator](ctx, sn)
+	if err != nil {
+		return nil, err
+	}
+	return creator.CreateFlightTask(ctx, body)
+}
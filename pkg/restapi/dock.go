@@ -0,0 +1,222 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/livestream"
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+/**  机场2直连接口  **/
+
+// dock 选出管理该机场或飞行器的机场2直连插件实例
+func (s *Server) dock(ctx context.Context, sn string) (service.DJIDock2DroneAdapter, error) {
+	return selectFor[service.DJIDock2DroneAdapter](ctx, sn)
+}
+
+// dockFromPath 读取路径中的设备序列号并选出机场2直连插件实例
+func (s *Server) dockFromPath(r *http.Request) (service.DJIDock2DroneAdapter, error) {
+	sn, err := pathSN(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.dock(r.Context(), sn)
+}
+
+// dockInfo 飞行器名称、类型与状态
+func (s *Server) dockInfo(r *http.Request) (interface{}, error) {
+	dock, err := s.dockFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+	info := map[string]interface{}{
+		"name": dock.GetDroneName(),
+		"type": dock.GetDroneType(),
+	}
+	if status, err := dock.GetDroneStatus(); err == nil {
+		info["status"] = status
+	} else {
+		info["status_error"] = err.Error()
+	}
+	return info, nil
+}
+
+// dockEvent 最近一次事件通知：takeoff-progress、fly-to-progress、land-progress、drc-status、joystick-invalid
+func (s *Server) dockEvent(r *http.Request) (interface{}, error) {
+	dock, err := s.dockFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+	switch event := r.PathValue("event"); event {
+	case "takeoff-progress":
+		return dock.TakeOffToPointProgress()
+	case "fly-to-progress":
+		return dock.FlyToPointProgress()
+	case "land-progress":
+		return dock.LandToPointProgress()
+	case "drc-status":
+		return dock.DrcStatusNotify()
+	case "joystick-invalid":
+		return dock.JoystickInvalidNotify()
+	default:
+		return nil, errorf(http.StatusNotFound, CodeNotFound, "事件不存在: %s", event)
+	}
+}
+
+// dockLiveStream 图传流地址，开启主相机直播并按模板补充 HLS、WebRTC 播放地址
+func (s *Server) dockLiveStream(r *http.Request) (interface{}, error) {
+	dock, err := s.dockFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+	url, err := dock.GetLiveStreamURL()
+	if err != nil {
+		return nil, err
+	}
+	return livestream.PlaybackFromURL(url, livestream.Default().Templates()), nil
+}
+
+// dockTakeOff 一键起飞，飞前检查与天气门限未通过时返回 412
+func (s *Server) dockTakeOff(r *http.Request) (interface{}, error) {
+	dock, err := s.dockFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+	return dock.TakeOff(r.Context())
+}
+
+// FlyToRequest 指点飞行请求体，坐标为 WGS-84
+type FlyToRequest struct {
+	Latitude  float64 `json:"latitude" validate:"lat"`
+	Longitude float64 `json:"longitude" validate:"lng"`
+	Height    float64 `json:"height" validate:"min=2,max=10000"`
+	MaxSpeed  float64 `json:"max_speed" validate:"omitempty,min=1,max=15"`
+}
+
+// dockFlyTo 指点飞行，进入电子围栏时返回 412 与违规详情，限飞区可携带 X-Override-Reason 确认（需 geofence:override 权限）
+func (s *Server) dockFlyTo(r *http.Request) (interface{}, error) {
+	var req FlyToRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if err := validator.Validate(&req); err != nil {
+		return nil, err
+	}
+	ctx, err := withOverride(r)
+	if err != nil {
+		return nil, err
+	}
+	dock, err := s.dockFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+	return dock.FlyToPoint(ctx, req.Latitude, req.Longitude, req.Height, req.MaxSpeed)
+}
+
+// dockLand 一键降落
+func (s *Server) dockLand(r *http.Request) (interface{}, error) {
+	dock, err := s.dockFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+	return dock.Land(r.Context())
+}
+
+// dockEmergencyStop 飞行器急停
+func (s *Server) dockEmergencyStop(r *http.Request) (interface{}, error) {
+	dock, err := s.dockFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+	return dock.DroneEmergencyStop()
+}
+
+// dockGrabAuthority 争夺飞行控制权
+func (s *Server) dockGrabAuthority(r *http.Request) (interface{}, error) {
+	dock, err := s.dockFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+	return dock.FlightAuthorityGrab(r.Context())
+}
+
+// dockReleaseAuthority 释放飞行控制权
+func (s *Server) dockReleaseAuthority(r *http.Request) (interface{}, error) {
+	dock, err := s.dockFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+	return dock.FlightAuthorityRelease(r.Context())
+}
+
+// StickRequest DRC杆量请求体，杆量范围 364~1684，1024 为中位
+type StickRequest struct {
+	X int `json:"x" validate:"min=364,max=1684"`
+	Y int `json:"y" validate:"min=364,max=1684"`
+}
+
+// dockStick DRC杆量控制
+func (s *Server) dockStick(r *http.Request) (interface{}, error) {
+	var req StickRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if err := validator.Validate(&req); err != nil {
+		return nil, err
+	}
+	dock, err := s.dockFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+	return dock.StickControl(r.Context(), req.X, req.Y)
+}
+
+// CameraRequest 云台相机操作请求体，按 action 取用对应参数
+type CameraRequest struct {
+	Action      string `json:"action" validate:"required,oneof=mode_switch photo_take photo_stop recording_start recording_stop frame_zoom focal_length screen_drag photo_storage recording_storage"`
+	Mode        string `json:"mode,omitempty"`         // mode_switch
+	X           int    `json:"x,omitempty"`            // frame_zoom、screen_drag
+	Y           int    `json:"y,omitempty"`            // frame_zoom、screen_drag
+	FocalLength int    `json:"focal_length,omitempty"` // focal_length
+	IsFollow    bool   `json:"is_follow,omitempty"`    // screen_drag
+	StorageType string `json:"storage_type,omitempty"` // photo_storage、recording_storage
+}
+
+// dockCamera 云台相机操作
+func (s *Server) dockCamera(r *http.Request) (interface{}, error) {
+	var req CameraRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if err := validator.Validate(&req); err != nil {
+		return nil, err
+	}
+	dock, err := s.dockFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+	switch req.Action {
+	case "mode_switch":
+		return dock.CameraModeSwitch(r.Context(), req.Mode)
+	case "photo_take":
+		return dock.CameraPhotoTake(r.Context())
+	case "photo_stop":
+		return dock.CameraPhotoStop(r.Context())
+	case "recording_start":
+		return dock.CameraRecordingStart(r.Context())
+	case "recording_stop":
+		return dock.CameraRecordingStop(r.Context())
+	case "frame_zoom":
+		return dock.CameraFrameZoom(r.Context(), req.X, req.Y)
+	case "focal_length":
+		return dock.CameraFocalLengthSet(r.Context(), req.FocalLength)
+	case "screen_drag":
+		return dock.CameraScreenDrag(r.Context(), req.X, req.Y, req.IsFollow)
+	case "photo_storage":
+		return dock.CameraPhotoStorageSet(r.Context(), req.StorageType)
+	default:
+		return dock.CameraRecordingStorageSet(r.Context(), req.StorageType)
+	}
+}
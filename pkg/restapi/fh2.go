@@ -0,0 +1,294 @@
+package restapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+)
+
+/**  司空2项目级接口  **/
+
+// listProjects 组织下的项目列表
+func (s *Server) listProjects(r *http.Request) (interface{}, error) {
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.GetprojectList(r.Context())
+}
+
+// listDevices 项目下的设备列表
+func (s *Server) listDevices(r *http.Request) (interface{}, error) {
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.GetDeviceList(r.Context())
+}
+
+// projectStsToken 项目的存储上传凭证
+func (s *Server) projectStsToken(r *http.Request) (interface{}, error) {
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.GetProjectStsToken(r.Context())
+}
+
+// listHms 多台设备的HMS告警，?sn=a,b；decode=true 时返回解码后的告警
+func (s *Server) listHms(r *http.Request) (interface{}, error) {
+	snList, err := querySNList(r)
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := fh2.GetDeviceHms(r.Context(), snList)
+	if err != nil {
+		return nil, err
+	}
+	return decodeHms(r, resp)
+}
+
+// deviceRTK 自定义网络RTK标定
+func (s *Server) deviceRTK(r *http.Request) (interface{}, error) {
+	sn, err := pathSN(r)
+	if err != nil {
+		return nil, err
+	}
+	body, err := jsonBody(r)
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.CreateDeviceRTK(r.Context(), sn, body)
+}
+
+// changeCamera 机场相机切换
+func (s *Server) changeCamera(r *http.Request) (interface{}, error) {
+	body, err := jsonBody(r)
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.UpdateDeviceChangeCamera(r.Context(), body)
+}
+
+// changeLens 飞行器镜头切换
+func (s *Server) changeLens(r *http.Request) (interface{}, error) {
+	body, err := jsonBody(r)
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.UpdateDeviceChangeLens(r.Context(), body)
+}
+
+// streamQuality 图传清晰度设置
+func (s *Server) streamQuality(r *http.Request) (interface{}, error) {
+	body, err := jsonBody(r)
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.UpdateDeviceStreamQuality(r.Context(), body)
+}
+
+// grabControl 获取设备控制权
+func (s *Server) grabControl(r *http.Request) (interface{}, error) {
+	body, err := jsonBody(r)
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.GetDeviceControl(r.Context(), body)
+}
+
+// releaseControl 释放设备控制权
+func (s *Server) releaseControl(r *http.Request) (interface{}, error) {
+	body, err := jsonBody(r)
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.DeleteDeviceControl(r.Context(), body)
+}
+
+// listFlightTasks 飞行任务列表，查询参数 sn、name、begin_at、end_at、task_type、status
+func (s *Server) listFlightTasks(r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	beginAt, err := queryInt(r, "begin_at")
+	if err != nil {
+		return nil, err
+	}
+	endAt, err := queryInt(r, "end_at")
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.GetFlightTask(r.Context(), q.Get("sn"), q.Get("name"), beginAt, endAt, q.Get("task_type"), q.Get("status"))
+}
+
+// flightTaskInfo 飞行任务详情
+func (s *Server) flightTaskInfo(r *http.Request) (interface{}, error) {
+	id, err := pathUUID(r)
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.GetFlightTaskInfo(r.Context(), id)
+}
+
+// flightTaskStatus 任务挂起与恢复
+func (s *Server) flightTaskStatus(r *http.Request) (interface{}, error) {
+	id, err := pathUUID(r)
+	if err != nil {
+		return nil, err
+	}
+	body, err := jsonBody(r)
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.UpdateFlightTaskStatus(r.Context(), id, body)
+}
+
+// flightTaskMedia 飞行任务产生的媒体资源
+func (s *Server) flightTaskMedia(r *http.Request) (interface{}, error) {
+	id, err := pathUUID(r)
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.GetFlightTaskMedia(r.Context(), id)
+}
+
+// flightTaskTrack 飞行任务轨迹
+func (s *Server) flightTaskTrack(r *http.Request) (interface{}, error) {
+	id, err := pathUUID(r)
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.GetFlightTaskTrack(r.Context(), id)
+}
+
+// listWaylines 项目下的航线列表
+func (s *Server) listWaylines(r *http.Request) (interface{}, error) {
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.GetWayLine(r.Context())
+}
+
+// waylineInfo 航线详情
+func (s *Server) waylineInfo(r *http.Request) (interface{}, error) {
+	id, err := pathUUID(r)
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.GetWayLineInfo(r.Context(), id)
+}
+
+// FinishUploadRequest 航线上传完成通知请求体
+type FinishUploadRequest struct {
+	ObjectKeyPrefix string `json:"object_key_prefix" validate:"required,max=512"`
+	FileName        string `json:"file_name" validate:"required,max=255"`
+}
+
+// Validate 校验请求参数
+func (r *FinishUploadRequest) Validate() error {
+	return validator.Validate(r)
+}
+
+// finishUpload 航线上传完成通知
+func (s *Server) finishUpload(r *http.Request) (interface{}, error) {
+	var req FinishUploadRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.SetFinishUpload(r.Context(), req.ObjectKeyPrefix, req.FileName)
+}
+
+// createModel 提交模型重建
+func (s *Server) createModel(r *http.Request) (interface{}, error) {
+	body, err := jsonBody(r)
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.CreateModel(r.Context(), body)
+}
+
+// listModels 项目下的模型列表
+func (s *Server) listModels(r *http.Request) (interface{}, error) {
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.GetModelList(r.Context())
+}
+
+// modelInfo 模型详情
+func (s *Server) modelInfo(r *http.Request) (interface{}, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return nil, errorf(http.StatusBadRequest, CodeInvalidArgument, "模型ID无效: %s", r.PathValue("id"))
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return fh2.GetModelInfo(r.Context(), id)
+}
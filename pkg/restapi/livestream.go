@@ -0,0 +1,68 @@
+package restapi
+
+import (
+	"net/http"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/livestream"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+/**  直播会话  **/
+
+// joinLiveSession 观看设备相机的直播，同一相机已在直播时共用，返回观众ID与播放信息
+func (s *Server) joinLiveSession(r *http.Request) (interface{}, error) {
+	var req livestream.Request
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if req.SN == "" || req.CameraIndex == "" {
+		return nil, errorf(http.StatusBadRequest, CodeInvalidArgument, "sn 与 camera_index 不能为空")
+	}
+	streamer, err := selectFor[service.LiveStreamer](r.Context(), req.SN)
+	if err != nil {
+		return nil, err
+	}
+	return livestream.Default().Join(r.Context(), streamer, req)
+}
+
+// listLiveSessions 当前租户的直播会话
+func (s *Server) listLiveSessions(r *http.Request) (interface{}, error) {
+	info, err := tenant.GetTenantFromContext(r.Context())
+	if err != nil {
+		return nil, errorf(http.StatusUnauthorized, CodeUnauthenticated, "%v", err)
+	}
+	return map[string]interface{}{"list": livestream.Default().List(info.TenantId)}, nil
+}
+
+// liveSessionHeartbeat 观众保活，返回会话最新状态；观众应在 viewer_timeout 内定期调用并使用返回的播放信息
+func (s *Server) liveSessionHeartbeat(r *http.Request) (interface{}, error) {
+	id, err := s.liveSessionID(r)
+	if err != nil {
+		return nil, err
+	}
+	return livestream.Default().Heartbeat(id, r.PathValue("viewer"))
+}
+
+// leaveLiveSession 观众离开
+func (s *Server) leaveLiveSession(r *http.Request) (interface{}, error) {
+	id, err := s.liveSessionID(r)
+	if err != nil {
+		return nil, err
+	}
+	return nil, livestream.Default().Leave(id, r.PathValue("viewer"))
+}
+
+// liveSessionID 路径中的会话ID，会话不属于调用租户时视为不存在
+func (s *Server) liveSessionID(r *http.Request) (string, error) {
+	info, err := tenant.GetTenantFromContext(r.Context())
+	if err != nil {
+		return "", errorf(http.StatusUnauthorized, CodeUnauthenticated, "%v", err)
+	}
+	id := r.PathValue("id")
+	session, ok := livestream.Default().Get(id)
+	if !ok || session.TenantID != info.TenantId {
+		return "", errorf(http.StatusNotFound, CodeNotFound, "直播会话不存在: %s", id)
+	}
+	return id, nil
+}
@@ -0,0 +1,150 @@
+package restapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+	"gitee.com/jamespi/drone_dispatch/pkg/media"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+)
+
+/**  媒体归档  **/
+
+// mediaPipeline 全局媒体归档流水线
+func mediaPipeline() (*media.Pipeline, error) {
+	p := media.Default()
+	if p == nil {
+		return nil, errorf(http.StatusServiceUnavailable, CodeUnavailable, "媒体归档未启用")
+	}
+	return p, nil
+}
+
+// archiveFlightTask 立即归档任务媒体，已归档的文件跳过；部分文件失败时错误详情中包含归档结果
+func (s *Server) archiveFlightTask(r *http.Request) (interface{}, error) {
+	id, err := pathUUID(r)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := mediaPipeline()
+	if err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	report, err := pipeline.Archive(r.Context(), fh2, media.Task{UUID: id})
+	if err != nil {
+		if report != nil && !errors.Is(err, media.ErrArchiving) {
+			return nil, &Error{Status: http.StatusBadGateway, Code: CodeUpstream, Message: err.Error(), Details: report}
+		}
+		return nil, err
+	}
+	return report, nil
+}
+
+// searchMedia 检索当前租户已归档的媒体
+// 查询参数：task、sn、type、from、to（RFC3339 或毫秒时间戳）、near（纬度,经度）、radius（米，默认100）、bbox（南,西,北,东）、limit（默认100）
+// near 与 bbox 按 coord 参数指定的坐标系解释，返回的位置同样转换到该坐标系
+func (s *Server) searchMedia(r *http.Request) (interface{}, error) {
+	pipeline, err := mediaPipeline()
+	if err != nil {
+		return nil, err
+	}
+	info, err := tenant.GetTenantFromContext(r.Context())
+	if err != nil {
+		return nil, errorf(http.StatusUnauthorized, CodeUnauthenticated, "%v", err)
+	}
+	query := r.URL.Query()
+	q := media.Query{
+		TenantID:    info.TenantId,
+		ProjectUUID: info.ProjectUUID,
+		TaskUUID:    query.Get("task"),
+		DeviceSN:    query.Get("sn"),
+		FileType:    query.Get("type"),
+		Radius:      100,
+		Limit:       100,
+	}
+	if q.From, err = queryTime(r, "from"); err != nil {
+		return nil, err
+	}
+	if q.To, err = queryTime(r, "to"); err != nil {
+		return nil, err
+	}
+	if raw := query.Get("radius"); raw != "" {
+		radius, err := strconv.ParseFloat(raw, 64)
+		if err != nil || radius <= 0 {
+			return nil, errorf(http.StatusBadRequest, CodeInvalidArgument, "查询参数 radius 无效: %s", raw)
+		}
+		q.Radius = radius
+	}
+	if query.Has("limit") {
+		if q.Limit, err = queryInt(r, "limit"); err != nil {
+			return nil, err
+		}
+	}
+
+	cs := geo.CoordSystemFrom(r.Context())
+	toWGS84 := func(p geo.Point) (geo.Point, error) {
+		converted, err := geo.Convert(p, cs, geo.WGS84)
+		if err != nil {
+			return geo.Point{}, errorf(http.StatusBadRequest, CodeInvalidArgument, "%v", err)
+		}
+		return converted, nil
+	}
+	if raw := query.Get("near"); raw != "" {
+		point, err := media.ParsePoint(raw)
+		if err != nil {
+			return nil, errorf(http.StatusBadRequest, CodeInvalidArgument, "查询参数 near 无效: %v", err)
+		}
+		if point, err = toWGS84(point); err != nil {
+			return nil, err
+		}
+		q.Near = &point
+	}
+	if raw := query.Get("bbox"); raw != "" {
+		bounds, err := media.ParseBounds(raw)
+		if err != nil {
+			return nil, errorf(http.StatusBadRequest, CodeInvalidArgument, "查询参数 bbox 无效: %v", err)
+		}
+		sw, err := toWGS84(geo.Point{Lat: bounds.South, Lng: bounds.West})
+		if err != nil {
+			return nil, err
+		}
+		ne, err := toWGS84(geo.Point{Lat: bounds.North, Lng: bounds.East})
+		if err != nil {
+			return nil, err
+		}
+		q.Bounds = &media.Bounds{South: sw.Lat, West: sw.Lng, North: ne.Lat, East: ne.Lng}
+	}
+
+	encoded, err := json.Marshal(map[string]interface{}{"list": pipeline.Index().Search(q)})
+	if err != nil {
+		return nil, err
+	}
+	converted, err := geo.ConvertJSON(encoded, geo.WGS84, cs)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(converted), nil
+}
+
+// queryTime 读取时间查询参数：RFC3339 或毫秒时间戳，未提供时为零值
+func queryTime(r *http.Request, name string) (time.Time, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if ms, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	at, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, errorf(http.StatusBadRequest, CodeInvalidArgument, "查询参数 %s 不是 RFC3339 时间或毫秒时间戳: %s", name, raw)
+	}
+	return at, nil
+}
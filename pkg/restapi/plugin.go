@@ -0,0 +1,96 @@
+package restapi
+
+import (
+	"net/http"
+
+	"gitee.com/jamespi/drone_dispatch/plugin"
+)
+
+/**  插件管理接口  **/
+
+// pluginView 插件信息的JSON表示
+type pluginView struct {
+	Type         string   `json:"type"`
+	Base         string   `json:"base,omitempty"`
+	Status       string   `json:"status"`
+	Instances    int      `json:"instances"`
+	Error        string   `json:"error,omitempty"`
+	Version      string   `json:"version,omitempty"`
+	Vendor       string   `json:"vendor,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Capabilities []string `json:"capabilities,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// listPlugins 已注册插件及其状态
+func (s *Server) listPlugins(r *http.Request) (interface{}, error) {
+	infos := plugin.PluginsList()
+	list := make([]pluginView, 0, len(infos))
+	for _, info := range infos {
+		view := pluginView{
+			Type:         string(info.PluginType),
+			Base:         string(info.Base),
+			Status:       string(info.Status),
+			Instances:    info.Instances,
+			Version:      info.Meta.Version,
+			Vendor:       info.Meta.Vendor,
+			Description:  info.Meta.Description,
+			Capabilities: info.Meta.Capabilities,
+		}
+		if info.Error != nil {
+			view.Error = info.Error.Error()
+		}
+		for _, dep := range info.Meta.Dependencies {
+			view.Dependencies = append(view.Dependencies, string(dep))
+		}
+		list = append(list, view)
+	}
+	return map[string]interface{}{"list": list}, nil
+}
+
+// pluginFromPath 读取路径中的插件类型，未注册时返回 404
+func pluginFromPath(r *http.Request) (plugin.PluginType, error) {
+	pluginType := plugin.PluginType(r.PathValue("type"))
+	for _, info := range plugin.PluginsList() {
+		if info.PluginType == pluginType {
+			return pluginType, nil
+		}
+	}
+	return "", errorf(http.StatusNotFound, CodeNotFound, "插件 %s 未注册", pluginType)
+}
+
+// pluginState 插件当前状态
+func pluginState(pluginType plugin.PluginType) map[string]string {
+	state := map[string]string{"type": string(pluginType)}
+	for _, info := range plugin.PluginsList() {
+		if info.PluginType == pluginType {
+			state["status"] = string(info.Status)
+			if info.Error != nil {
+				state["error"] = info.Error.Error()
+			}
+		}
+	}
+	return state
+}
+
+// enablePlugin 启用插件（会先检查依赖）
+func (s *Server) enablePlugin(r *http.Request) (interface{}, error) {
+	pluginType, err := pluginFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := plugin.EnableWithContext(r.Context(), pluginType); err != nil {
+		return nil, errorf(http.StatusServiceUnavailable, CodeUnavailable, "%v", err)
+	}
+	return pluginState(pluginType), nil
+}
+
+// disablePlugin 禁用插件，依赖它的插件一并禁用
+func (s *Server) disablePlugin(r *http.Request) (interface{}, error) {
+	pluginType, err := pluginFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+	plugin.Disable(pluginType)
+	return pluginState(pluginType), nil
+}
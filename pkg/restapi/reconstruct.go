@@ -0,0 +1,65 @@
+package restapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/reconstruct"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+)
+
+/**  模型重建编排  **/
+
+// submitReconstruction 选取已结束飞行任务的照片提交模型重建，返回登记的重建任务
+func (s *Server) submitReconstruction(r *http.Request) (interface{}, error) {
+	var req reconstruct.Request
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return reconstruct.Default().Submit(r.Context(), fh2, req)
+}
+
+// listReconstructions 当前租户项目下的重建任务，可按源飞行任务（task）与状态（status）筛选
+func (s *Server) listReconstructions(r *http.Request) (interface{}, error) {
+	info, err := tenant.GetTenantFromContext(r.Context())
+	if err != nil {
+		return nil, errorf(http.StatusUnauthorized, CodeUnauthenticated, "%v", err)
+	}
+	query := r.URL.Query()
+	list := reconstruct.Default().Registry().List(reconstruct.Filter{
+		TenantID:    info.TenantId,
+		ProjectUUID: info.ProjectUUID,
+		TaskUUID:    query.Get("task"),
+		Status:      query.Get("status"),
+	})
+	return map[string]interface{}{"list": list}, nil
+}
+
+// reconstructionInfo 重建任务详情，未结束时查询司空2的最新进度
+func (s *Server) reconstructionInfo(r *http.Request) (interface{}, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return nil, errorf(http.StatusBadRequest, CodeInvalidArgument, "模型ID无效: %s", r.PathValue("id"))
+	}
+	info, err := tenant.GetTenantFromContext(r.Context())
+	if err != nil {
+		return nil, errorf(http.StatusUnauthorized, CodeUnauthenticated, "%v", err)
+	}
+	orchestrator := reconstruct.Default()
+	job, ok := orchestrator.Registry().Get(id)
+	if !ok || job.TenantID != info.TenantId || (info.ProjectUUID != "" && job.ProjectUUID != info.ProjectUUID) {
+		return nil, errorf(http.StatusNotFound, CodeNotFound, "重建任务不存在: %d", id)
+	}
+	if job.Terminal() {
+		return job, nil
+	}
+	fh2, err := s.fh2(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return orchestrator.Refresh(r.Context(), fh2, id)
+}
@@ -0,0 +1,146 @@
+package restapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geofence"
+	"gitee.com/jamespi/drone_dispatch/pkg/livestream"
+	"gitee.com/jamespi/drone_dispatch/pkg/media"
+	"gitee.com/jamespi/drone_dispatch/pkg/preflight"
+	"gitee.com/jamespi/drone_dispatch/pkg/reconstruct"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+	"gitee.com/jamespi/drone_dispatch/pkg/weather"
+)
+
+// 错误码
+const (
+	CodeInvalidArgument = "invalid_argument"    // 请求参数错误
+	CodeUnauthenticated = "unauthenticated"     // 缺少或无效的租户身份
+	CodeForbidden       = "forbidden"           // 缺少权限
+	CodeNotFound        = "not_found"           // 资源或设备不存在
+	CodeConflict        = "conflict"            // 需要人工确认，例如限飞区；或操作正在进行
+	CodePrecondition    = "failed_precondition" // 飞前检查或天气门限未通过、禁飞区、重建源任务未结束
+	CodeUnavailable     = "unavailable"         // 插件未启用或不可用
+	CodeUpstream        = "upstream_error"      // 司空2、机场等上游返回错误
+	CodeInternal        = "internal"            // 服务内部错误
+)
+
+// Error 结构化错误
+type Error struct {
+	Status  int         `json:"-"`
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func errorf(status int, code, format string, args ...interface{}) *Error {
+	return &Error{Status: status, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// envelope 响应体：成功时包含 data，失败时包含 error
+type envelope struct {
+	RequestID string          `json:"request_id"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Error     *Error          `json:"error,omitempty"`
+}
+
+// writeData 写入成功响应
+// 适配器返回的JSON字符串原样作为 data（司空2响应 {"code":0,"data":...} 取其中的 data），其他值按JSON编码
+func writeData(w http.ResponseWriter, r *http.Request, v interface{}) {
+	var data json.RawMessage
+	switch value := v.(type) {
+	case nil:
+		data = json.RawMessage("null")
+	case string:
+		data = rawData(value)
+	case json.RawMessage:
+		data = value
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			writeError(w, r, errorf(http.StatusInternalServerError, CodeInternal, "序列化响应失败: %v", err))
+			return
+		}
+		data = encoded
+	}
+	writeJSON(w, http.StatusOK, envelope{RequestID: tenant.GetRequestIDFromContext(r.Context()), Data: data})
+}
+
+// rawData 将适配器返回的字符串转换为 data
+func rawData(s string) json.RawMessage {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return json.RawMessage("null")
+	}
+	if !json.Valid([]byte(trimmed)) {
+		encoded, _ := json.Marshal(s)
+		return encoded
+	}
+	var upstream struct {
+		Code *int            `json:"code"`
+		Data json.RawMessage `json:"data"`
+	}
+	if strings.HasPrefix(trimmed, "{") && json.Unmarshal([]byte(trimmed), &upstream) == nil && upstream.Code != nil {
+		if upstream.Data == nil {
+			return json.RawMessage("null")
+		}
+		return upstream.Data
+	}
+	return json.RawMessage(trimmed)
+}
+
+// writeError 写入错误响应，按错误类型确定状态码与错误码
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := toError(err)
+	writeJSON(w, apiErr.Status, envelope{RequestID: tenant.GetRequestIDFromContext(r.Context()), Error: apiErr})
+}
+
+// toError 将业务错误映射为结构化错误
+func toError(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	var fieldErrs validator.ValidationErrors
+	var maxBytes *http.MaxBytesError
+	var violation *geofence.ViolationError
+	switch {
+	case errors.As(err, &fieldErrs):
+		return &Error{Status: http.StatusBadRequest, Code: CodeInvalidArgument, Message: err.Error(), Details: fieldErrs}
+	case errors.As(err, &maxBytes):
+		return errorf(http.StatusRequestEntityTooLarge, CodeInvalidArgument, "请求体超过 %d 字节", maxBytes.Limit)
+	case errors.As(err, &violation):
+		status, code := http.StatusPreconditionFailed, CodePrecondition
+		if errors.Is(err, geofence.ErrOverrideRequired) {
+			status, code = http.StatusConflict, CodeConflict
+		}
+		return &Error{Status: status, Code: code, Message: err.Error(), Details: violation.Violations}
+	case errors.Is(err, preflight.ErrPreflightFailed), errors.Is(err, weather.ErrUnsafe):
+		return errorf(http.StatusPreconditionFailed, CodePrecondition, "%v", err)
+	case errors.Is(err, media.ErrArchiving):
+		return errorf(http.StatusConflict, CodeConflict, "%v", err)
+	case errors.Is(err, reconstruct.ErrInvalidRequest):
+		return errorf(http.StatusBadRequest, CodeInvalidArgument, "%v", err)
+	case errors.Is(err, reconstruct.ErrTaskNotFinished), errors.Is(err, reconstruct.ErrNotEnoughMedia):
+		return errorf(http.StatusPreconditionFailed, CodePrecondition, "%v", err)
+	case errors.Is(err, reconstruct.ErrNotFound), errors.Is(err, livestream.ErrNotFound):
+		return errorf(http.StatusNotFound, CodeNotFound, "%v", err)
+	}
+	return errorf(http.StatusBadGateway, CodeUpstream, "%v", err)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,176 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+)
+
+// routes 注册全部路由
+// 项目级接口（项目、设备列表、控制、任务、航线、模型）由司空2提供；设备级接口按设备序列号选择具备该能力的插件，
+// 机场2直连与司空2均可提供；/docks 下为机场2直连独有的上云API操作
+func (s *Server) routes() {
+	v := "/" + APIVersion
+
+	s.mux.HandleFunc("GET "+v+"/health", s.health)
+
+	// 项目与设备
+	s.handle("GET "+v+"/projects", tenant.PermFH2Read, s.listProjects)
+	s.handle("GET "+v+"/devices", tenant.PermFH2Read, s.listDevices)
+	s.handle("GET "+v+"/sts-token", tenant.PermFH2Write, s.projectStsToken)
+	s.handle("GET "+v+"/devices/hms", tenant.PermFH2Read, s.listHms)
+	s.handle("GET "+v+"/devices/{sn}/state", tenant.PermFH2Read, s.deviceState)
+	s.handle("GET "+v+"/devices/{sn}/hms", tenant.PermFH2Read, s.deviceHms)
+	s.handle("POST "+v+"/devices/{sn}/commands", tenant.PermFH2Write, s.deviceCommand)
+	s.handle("POST "+v+"/devices/{sn}/rtk", tenant.PermFH2Write, s.deviceRTK)
+
+	// 控制
+	s.handle("PUT "+v+"/control/camera", tenant.PermFH2Write, s.changeCamera)
+	s.handle("PUT "+v+"/control/lens", tenant.PermFH2Write, s.changeLens)
+	s.handle("PUT "+v+"/control/stream-quality", tenant.PermFH2Write, s.streamQuality)
+	s.handle("POST "+v+"/control/authority", tenant.PermFH2Write, s.grabControl)
+	s.handle("DELETE "+v+"/control/authority", tenant.PermFH2Write, s.releaseControl)
+
+	// 直播
+	s.handle("POST "+v+"/live-streams", tenant.PermFH2Write, s.startLiveStream)
+	s.handle("POST "+v+"/live-sessions", tenant.PermFH2Write, s.joinLiveSession)
+	s.handle("GET "+v+"/live-sessions", tenant.PermFH2Read, s.listLiveSessions)
+	s.handle("POST "+v+"/live-sessions/{id}/viewers/{viewer}/heartbeat", tenant.PermFH2Read, s.liveSessionHeartbeat)
+	s.handle("DELETE "+v+"/live-sessions/{id}/viewers/{viewer}", tenant.PermFH2Read, s.leaveLiveSession)
+
+	// 飞行任务
+	s.handle("POST "+v+"/flight-tasks", tenant.PermFH2Write, s.createFlightTask)
+	s.handle("GET "+v+"/flight-tasks", tenant.PermFH2Read, s.listFlightTasks)
+	s.handle("GET "+v+"/flight-tasks/{uuid}", tenant.PermFH2Read, s.flightTaskInfo)
+	s.handle("PUT "+v+"/flight-tasks/{uuid}/status", tenant.PermFH2Write, s.flightTaskStatus)
+	s.handle("GET "+v+"/flight-tasks/{uuid}/media", tenant.PermFH2Read, s.flightTaskMedia)
+	s.handle("GET "+v+"/flight-tasks/{uuid}/track", tenant.PermFH2Read, s.flightTaskTrack)
+	s.handle("POST "+v+"/flight-tasks/{uuid}/archive", tenant.PermFH2Write, s.archiveFlightTask)
+
+	// 媒体归档
+	s.handle("GET "+v+"/media", tenant.PermFH2Read, s.searchMedia)
+
+	// 航线
+	s.handle("GET "+v+"/waylines", tenant.PermFH2Read, s.listWaylines)
+	s.handle("GET "+v+"/waylines/{uuid}", tenant.PermFH2Read, s.waylineInfo)
+	s.handle("POST "+v+"/waylines/finish-upload", tenant.PermFH2Write, s.finishUpload)
+
+	// 模型
+	s.handle("POST "+v+"/models", tenant.PermFH2Write, s.createModel)
+	s.handle("GET "+v+"/models", tenant.PermFH2Read, s.listModels)
+	s.handle("GET "+v+"/models/{id}", tenant.PermFH2Read, s.modelInfo)
+	s.handle("POST "+v+"/reconstructions", tenant.PermFH2Write, s.submitReconstruction)
+	s.handle("GET "+v+"/reconstructions", tenant.PermFH2Read, s.listReconstructions)
+	s.handle("GET "+v+"/reconstructions/{id}", tenant.PermFH2Read, s.reconstructionInfo)
+
+	// 机场2直连
+	s.handle("GET "+v+"/docks/{sn}", tenant.PermDock2Read, s.dockInfo)
+	s.handle("GET "+v+"/docks/{sn}/events/{event}", tenant.PermDock2Read, s.dockEvent)
+	s.handle("GET "+v+"/docks/{sn}/live-stream", tenant.PermDock2Read, s.dockLiveStream)
+	s.handle("POST "+v+"/docks/{sn}/takeoff", tenant.PermDock2Write, s.dockTakeOff)
+	s.handle("POST "+v+"/docks/{sn}/fly-to", tenant.PermDock2Write, s.dockFlyTo)
+	s.handle("POST "+v+"/docks/{sn}/land", tenant.PermDock2Write, s.dockLand)
+	s.handle("POST "+v+"/docks/{sn}/emergency-stop", tenant.PermDock2Write, s.dockEmergencyStop)
+	s.handle("POST "+v+"/docks/{sn}/authority", tenant.PermDock2Write, s.dockGrabAuthority)
+	s.handle("DELETE "+v+"/docks/{sn}/authority", tenant.PermDock2Write, s.dockReleaseAuthority)
+	s.handle("POST "+v+"/docks/{sn}/stick", tenant.PermDock2Write, s.dockStick)
+	s.handle("POST "+v+"/docks/{sn}/camera", tenant.PermDock2Write, s.dockCamera)
+
+	// 插件
+	s.handle("GET "+v+"/plugins", tenant.PermPluginRead, s.listPlugins)
+	s.handle("POST "+v+"/plugins/{type}/enable", tenant.PermPluginAdmin, s.enablePlugin)
+	s.handle("POST "+v+"/plugins/{type}/disable", tenant.PermPluginAdmin, s.disablePlugin)
+
+	// 未匹配的路由返回结构化错误
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, r, errorf(http.StatusNotFound, CodeNotFound, "接口不存在: %s %s", r.Method, r.URL.Path))
+	})
+}
+
+// health 存活检查，不需要租户身份
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	writeData(w, r, map[string]string{"status": "ok", "version": APIVersion})
+}
+
+/**  请求参数  **/
+
+// pathSN 读取并校验路径中的设备序列号
+func pathSN(r *http.Request) (string, error) {
+	sn := r.PathValue("sn")
+	if err := validator.GetValidator().ValidateDeviceSN(sn); err != nil {
+		return "", errorf(http.StatusBadRequest, CodeInvalidArgument, "设备序列号无效: %v", err)
+	}
+	return sn, nil
+}
+
+// pathUUID 读取并校验路径中的UUID
+func pathUUID(r *http.Request) (string, error) {
+	id := r.PathValue("uuid")
+	if err := validator.GetValidator().ValidateUUID(id); err != nil {
+		return "", errorf(http.StatusBadRequest, CodeInvalidArgument, "UUID无效: %v", err)
+	}
+	return id, nil
+}
+
+// jsonBody 读取请求体并确认为合法JSON，返回可重复读取的副本
+func jsonBody(r *http.Request) (*bytes.Reader, error) {
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, errorf(http.StatusBadRequest, CodeInvalidArgument, "请求体不能为空")
+	}
+	if !json.Valid(raw) {
+		return nil, errorf(http.StatusBadRequest, CodeInvalidArgument, "请求体不是合法的JSON")
+	}
+	return bytes.NewReader(raw), nil
+}
+
+// decodeBody 解析JSON请求体
+func decodeBody(r *http.Request, v interface{}) error {
+	body, err := jsonBody(r)
+	if err != nil {
+		return err
+	}
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return errorf(http.StatusBadRequest, CodeInvalidArgument, "解析请求体失败: %v", err)
+	}
+	return nil
+}
+
+// queryInt 读取整数查询参数，未提供时为0
+func queryInt(r *http.Request, name string) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, errorf(http.StatusBadRequest, CodeInvalidArgument, "查询参数 %s 不是整数: %s", name, raw)
+	}
+	return n, nil
+}
+
+// querySNList 读取逗号分隔的设备序列号列表并逐个校验
+func querySNList(r *http.Request) (string, error) {
+	raw := r.URL.Query().Get("sn")
+	if raw == "" {
+		return "", errorf(http.StatusBadRequest, CodeInvalidArgument, "缺少查询参数 sn")
+	}
+	var list []string
+	for _, sn := range strings.Split(raw, ",") {
+		sn = strings.TrimSpace(sn)
+		if err := validator.GetValidator().ValidateDeviceSN(sn); err != nil {
+			return "", errorf(http.StatusBadRequest, CodeInvalidArgument, "设备序列号无效: %v", err)
+		}
+		list = append(list, sn)
+	}
+	return strings.Join(list, ","), nil
+}
@@ -0,0 +1,189 @@
+// Package restapi 以版本化 REST API 对外提供司空2与机场2适配器的能力
+// 租户身份取自登记的用户令牌（tenant.Tokens），权限由服务端按租户授予；每个请求携带请求ID，错误以结构化JSON返回。
+package restapi
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/plugin"
+	"gitee.com/jamespi/drone_dispatch/service"
+	"github.com/google/uuid"
+)
+
+// APIVersion 路由前缀中的版本号
+const APIVersion = "v1"
+
+// maxBodyBytes 请求体大小上限
+const maxBodyBytes = 1 << 20
+
+// Options 服务选项
+type Options struct {
+	// DefaultPermissions 未单独配置的租户具备的权限
+	DefaultPermissions []string
+	// Grants 按租户ID授予的权限，覆盖 DefaultPermissions
+	Grants map[int64][]string
+	// Tokens 登记的用户令牌，请求身份取自令牌所属的租户
+	Tokens tenant.Tokens
+	// FH2 获取司空2适配器，为nil时从插件注册中心获取
+	FH2 func(ctx context.Context) (service.FH2DroneAdapter, error)
+	// Logger 访问日志，为nil时使用标准库 log
+	Logger *log.Logger
+}
+
+// OptionsFromConfig 按配置文件 Server 段生成服务选项
+func OptionsFromConfig(cfg *config.Server) Options {
+	opts := Options{Grants: make(map[int64][]string), Tokens: make(tenant.Tokens)}
+	if cfg == nil {
+		return opts
+	}
+	opts.DefaultPermissions = cfg.DefaultPermissions
+	for _, grant := range cfg.Tenants {
+		if len(grant.Permissions) > 0 {
+			opts.Grants[grant.TenantID] = grant.Permissions
+		}
+		for _, digest := range grant.TokenSHA256 {
+			opts.Tokens[strings.ToLower(strings.TrimSpace(digest))] = grant.TenantID
+		}
+	}
+	return opts
+}
+
+// Server REST API 服务，实现 http.Handler
+type Server struct {
+	mux  *http.ServeMux
+	mu   sync.RWMutex
+	opts Options
+}
+
+// NewServer 创建 REST API 服务并注册全部路由
+func NewServer(opts Options) *Server {
+	s := &Server{mux: http.NewServeMux(), opts: opts}
+	s.routes()
+	return s
+}
+
+// SetOptions 替换服务选项（配置重新加载时更新权限）
+func (s *Server) SetOptions(opts Options) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opts = opts
+}
+
+func (s *Server) options() Options {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.opts
+}
+
+// ServeHTTP 分配请求ID、恢复 panic 并记录访问日志
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := strings.TrimSpace(r.Header.Get(tenant.HeaderRequestID))
+	if requestID == "" || len(requestID) > 128 {
+		requestID = uuid.New().String()
+	}
+	w.Header().Set(tenant.HeaderRequestID, requestID)
+	r = r.WithContext(tenant.WithRequestID(r.Context(), requestID))
+
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	start := time.Now()
+	defer func() {
+		if v := recover(); v != nil {
+			s.logf("请求 %s %s panic: %v\n%s", r.Method, r.URL.Path, v, debug.Stack())
+			writeError(rec, r, errorf(http.StatusInternalServerError, CodeInternal, "服务内部错误"))
+		}
+		s.logf("%s %s %d %s request_id=%s", r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Millisecond), requestID)
+	}()
+	s.mux.ServeHTTP(rec, r)
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if logger := s.options().Logger; logger != nil {
+		logger.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// handlerFunc 业务处理函数，返回值按 writeData 规则写入响应
+type handlerFunc func(r *http.Request) (interface{}, error)
+
+// handle 注册需要租户身份的路由，perm 为空表示只要求身份有效
+func (s *Server) handle(pattern, perm string, fn handlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		info, err := s.resolveTenant(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if perm != "" && !info.HasPermission(perm) {
+			writeError(w, r, errorf(http.StatusForbidden, CodeForbidden, "租户 %d 缺少权限 %s", info.TenantId, perm))
+			return
+		}
+		ctx := tenant.WithTenant(r.Context(), info)
+		if name := r.URL.Query().Get("coord"); name != "" {
+			cs, err := geo.ParseCoordSystem(name)
+			if err != nil {
+				writeError(w, r, errorf(http.StatusBadRequest, CodeInvalidArgument, "%v", err))
+				return
+			}
+			ctx = geo.WithCoordSystem(ctx, cs)
+		}
+		r = r.WithContext(ctx)
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+		}
+		result, err := fn(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeData(w, r, result)
+	})
+}
+
+// resolveTenant 校验请求头中的用户令牌，以令牌所属的租户作为身份并按配置授予权限
+func (s *Server) resolveTenant(r *http.Request) (*tenant.TenantInfo, error) {
+	opts := s.options()
+	info, err := opts.Tokens.Verify(r.Header)
+	if err != nil {
+		return nil, errorf(http.StatusUnauthorized, CodeUnauthenticated, "%v", err)
+	}
+	if perms, ok := opts.Grants[info.TenantId]; ok {
+		info.GrantPermissions(perms...)
+	} else {
+		info.GrantPermissions(opts.DefaultPermissions...)
+	}
+	return info, nil
+}
+
+// fh2 获取司空2适配器
+func (s *Server) fh2(ctx context.Context) (service.FH2DroneAdapter, error) {
+	if get := s.options().FH2; get != nil {
+		return get(ctx)
+	}
+	adapter, ok := plugin.GetWithContext[service.FH2DroneAdapter](ctx, plugin.FH2Plugin)
+	if !ok {
+		return nil, errorf(http.StatusServiceUnavailable, CodeUnavailable, "插件 %s 未启用", plugin.FH2Plugin)
+	}
+	return adapter, nil
+}
+
+// statusRecorder 记录响应状态码用于访问日志
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,43 @@
+// Package telemetry 汇集各插件上报的飞行器OSD、飞行任务状态与DRC链路状态变化，供 gRPC、WebSocket 等推送通道订阅
+// 主动推送的插件（如机场2直连）收到消息即发布；只能查询的插件（如司空2）由 OSDPoller、TaskWatcher 轮询后发布。
+package telemetry
+
+import "sync"
+
+// listeners 事件订阅者，回调在发布方协程中同步执行，耗时操作请自行异步处理
+type listeners[T interface{}] struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int]func(T)
+}
+
+func newListeners[T interface{}]() *listeners[T] {
+	return &listeners[T]{handlers: make(map[int]func(T))}
+}
+
+// subscribe 注册回调，返回取消订阅函数
+func (l *listeners[T]) subscribe(handler func(T)) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id := l.nextID
+	l.nextID++
+	l.handlers[id] = handler
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.handlers, id)
+	}
+}
+
+// publish 通知全部订阅者
+func (l *listeners[T]) publish(event T) {
+	l.mu.RLock()
+	handlers := make([]func(T), 0, len(l.handlers))
+	for _, handler := range l.handlers {
+		handlers = append(handlers, handler)
+	}
+	l.mu.RUnlock()
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
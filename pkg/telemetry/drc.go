@@ -0,0 +1,26 @@
+package telemetry
+
+import "time"
+
+// DRCEvent DRC（指令飞行）链路状态变化，归属机场
+type DRCEvent struct {
+	DeviceSN string    `json:"sn"`
+	State    int       `json:"drc_state"` // 0未连接 1连接中 2已连接
+	Result   int       `json:"result"`
+	At       time.Time `json:"at"`
+}
+
+var drcListeners = newListeners[DRCEvent]()
+
+// OnDRCStatus 订阅DRC链路状态变化，返回取消订阅函数
+func OnDRCStatus(handler func(DRCEvent)) (unsubscribe func()) {
+	return drcListeners.subscribe(handler)
+}
+
+// PublishDRCStatus 发布DRC链路状态
+func PublishDRCStatus(event DRCEvent) {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+	drcListeners.publish(event)
+}
@@ -0,0 +1,164 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// OSDEvent 飞行器OSD
+type OSDEvent struct {
+	DeviceSN string
+	OSD      service.DroneOSD
+	At       time.Time
+}
+
+// Pusher 主动推送OSD与HMS告警的插件（如机场2直连），订阅方无需再轮询
+type Pusher interface {
+	PushesTelemetry() bool
+}
+
+var osdListeners = newListeners[OSDEvent]()
+
+// OnOSD 订阅飞行器OSD，返回取消订阅函数
+func OnOSD(handler func(OSDEvent)) (unsubscribe func()) {
+	return osdListeners.subscribe(handler)
+}
+
+// PublishOSD 发布飞行器OSD
+func PublishOSD(sn string, osd service.DroneOSD) {
+	osdListeners.publish(OSDEvent{DeviceSN: sn, OSD: osd, At: time.Now()})
+}
+
+// IsPusher 插件是否主动推送OSD与HMS告警
+func IsPusher(source interface{}) bool {
+	pusher, ok := source.(Pusher)
+	return ok && pusher.PushesTelemetry()
+}
+
+// ParseOSD 从遥测响应中提取OSD
+// 支持司空2 {"data":{"device_state":{...}}} 与机场2 {"sn":..,"osd":{...}} 两种格式
+func ParseOSD(data []byte) (service.DroneOSD, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return service.DroneOSD{}, fmt.Errorf("解析设备状态失败: %w", err)
+	}
+	for _, key := range []string{"data", "device_state", "osd"} {
+		if inner, ok := doc[key].(map[string]interface{}); ok {
+			doc = inner
+		}
+	}
+	osd := service.DroneOSD{
+		Latitude:    number(doc["latitude"]),
+		Longitude:   number(doc["longitude"]),
+		Altitude:    number(doc["height"]),
+		Speed:       number(doc["horizontal_speed"]),
+		FlightState: int(number(doc["mode_code"])),
+	}
+	if battery, ok := doc["battery"].(map[string]interface{}); ok {
+		osd.Battery = int(number(battery["capacity_percent"]))
+	}
+	return osd, nil
+}
+
+func number(v interface{}) float64 {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0
+	}
+	f, _ := n.Float64()
+	return f
+}
+
+// OSDPoller 定时通过 GetDeviceState 查询设备OSD，用于不主动推送的插件
+// ctx 需携带调用适配器所需的租户信息（如司空2）
+type OSDPoller struct {
+	source   service.TelemetrySource
+	devices  []string
+	interval time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewOSDPoller 创建OSD轮询器，interval 小于等于0时为2秒
+func NewOSDPoller(source service.TelemetrySource, devices []string, interval time.Duration) *OSDPoller {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	return &OSDPoller{source: source, devices: devices, interval: interval}
+}
+
+// PollOnce 查询一次全部设备的OSD，单台设备查询失败不影响其余设备
+func (p *OSDPoller) PollOnce(ctx context.Context) ([]OSDEvent, error) {
+	var events []OSDEvent
+	var firstErr error
+	for _, sn := range p.devices {
+		resp, err := p.source.GetDeviceState(ctx, sn)
+		if err == nil {
+			var osd service.DroneOSD
+			if osd, err = ParseOSD([]byte(resp)); err == nil {
+				events = append(events, OSDEvent{DeviceSN: sn, OSD: osd, At: time.Now()})
+				continue
+			}
+		}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("查询设备 %s 的OSD失败: %w", sn, err)
+		}
+	}
+	return events, firstErr
+}
+
+// Start 启动轮询，handler 为nil时发布到全局订阅者；重复调用无效
+func (p *OSDPoller) Start(ctx context.Context, handler func(OSDEvent)) {
+	if handler == nil {
+		handler = osdListeners.publish
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		return
+	}
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			events, err := p.PollOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("OSD轮询失败: %v", err)
+			}
+			for _, event := range events {
+				handler(event)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}(p.done)
+}
+
+// Stop 停止轮询并等待当前查询结束
+func (p *OSDPoller) Stop() {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel, p.done = nil, nil
+	p.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,302 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+)
+
+// TaskEvent 飞行任务状态变化
+type TaskEvent struct {
+	TaskUUID   string    `json:"task_uuid"`
+	DeviceSN   string    `json:"sn,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	TaskType   string    `json:"task_type,omitempty"`
+	Status     string    `json:"status"`
+	PrevStatus string    `json:"prev_status,omitempty"`
+	Progress   int       `json:"progress"`
+	At         time.Time `json:"at"`
+}
+
+// Terminal 任务是否已结束
+func (e TaskEvent) Terminal() bool {
+	return IsTerminal(e.Status)
+}
+
+// IsTerminal 任务状态是否为终态：司空2 success、failed、terminated，上云API task_finish、task_failed
+func IsTerminal(status string) bool {
+	switch status {
+	case "success", "failed", "terminated", "task_finish", "task_failed":
+		return true
+	}
+	return false
+}
+
+var (
+	taskListeners = newListeners[TaskEvent]()
+
+	// lastStatus 各任务最近一次发布的状态，用于去重与填充 PrevStatus；任务结束后删除
+	lastStatus   = make(map[string]string)
+	lastStatusMu sync.Mutex
+)
+
+// OnTaskStatus 订阅飞行任务状态变化，返回取消订阅函数
+func OnTaskStatus(handler func(TaskEvent)) (unsubscribe func()) {
+	return taskListeners.subscribe(handler)
+}
+
+// PublishTaskStatus 发布飞行任务状态，状态未变化时不发布，返回是否发布
+func PublishTaskStatus(event TaskEvent) bool {
+	if event.TaskUUID == "" || event.Status == "" {
+		return false
+	}
+	lastStatusMu.Lock()
+	prev, seen := lastStatus[event.TaskUUID]
+	if seen && prev == event.Status {
+		lastStatusMu.Unlock()
+		return false
+	}
+	if event.Terminal() {
+		delete(lastStatus, event.TaskUUID)
+	} else {
+		lastStatus[event.TaskUUID] = event.Status
+	}
+	lastStatusMu.Unlock()
+
+	event.PrevStatus = prev
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+	taskListeners.publish(event)
+	return true
+}
+
+// ParseTask 解析飞行任务详情，格式为司空2 {"data":{"uuid":..,"status":..}}
+func ParseTask(data []byte) (TaskEvent, error) {
+	var resp struct {
+		Data *struct {
+			UUID     string `json:"uuid"`
+			SN       string `json:"sn"`
+			Name     string `json:"name"`
+			TaskType string `json:"task_type"`
+			Status   string `json:"status"`
+			Progress int    `json:"progress"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return TaskEvent{}, fmt.Errorf("解析飞行任务失败: %w", err)
+	}
+	if resp.Data == nil {
+		return TaskEvent{}, fmt.Errorf("解析飞行任务失败: 响应中没有任务数据")
+	}
+	task := resp.Data
+	return TaskEvent{
+		TaskUUID: task.UUID,
+		DeviceSN: task.SN,
+		Name:     task.Name,
+		TaskType: task.TaskType,
+		Status:   task.Status,
+		Progress: task.Progress,
+	}, nil
+}
+
+// TaskInfoSource 可查询飞行任务详情（如司空2）
+type TaskInfoSource interface {
+	GetFlightTaskInfo(ctx context.Context, taskUUID string) (string, error)
+}
+
+// watchedTask 跟踪中的任务
+type watchedTask struct {
+	tenant *tenant.TenantInfo // 任务所属租户与项目，不含用户令牌
+	source TaskInfoSource
+	since  time.Time
+	errors int // 连续查询失败次数
+}
+
+// TaskWatchOptions 任务跟踪选项
+type TaskWatchOptions struct {
+	Interval  time.Duration // 查询间隔，默认10秒，只在创建时生效
+	MaxAge    time.Duration // 单个任务的最长跟踪时长，超过后停止跟踪，默认24小时
+	MaxErrors int           // 连续查询失败的次数上限，达到后停止跟踪，默认30
+	// Credentials 租户的服务凭据，查询任务状态时以此代替发起任务的用户令牌；未配置的租户不跟踪
+	Credentials tenant.ServiceTokens
+}
+
+// withDefaults 填充默认值
+func (o TaskWatchOptions) withDefaults() TaskWatchOptions {
+	if o.Interval <= 0 {
+		o.Interval = 10 * time.Second
+	}
+	if o.MaxAge <= 0 {
+		o.MaxAge = 24 * time.Hour
+	}
+	if o.MaxErrors <= 0 {
+		o.MaxErrors = 30
+	}
+	return o
+}
+
+// TaskWatcher 定时查询跟踪中的飞行任务并发布状态变化，任务结束、超过最长跟踪时长或连续查询失败过多时停止跟踪
+// 有任务时才运行轮询协程，最后一个任务结束后协程退出
+type TaskWatcher struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	opts    TaskWatchOptions
+	tasks   map[string]*watchedTask
+	running bool
+}
+
+// NewTaskWatcher 创建任务跟踪器
+func NewTaskWatcher(opts TaskWatchOptions) *TaskWatcher {
+	opts = opts.withDefaults()
+	return &TaskWatcher{interval: opts.Interval, opts: opts, tasks: make(map[string]*watchedTask)}
+}
+
+// SetOptions 更新最长跟踪时长、失败次数上限与服务凭据，对跟踪中的任务立即生效；查询间隔不变
+func (w *TaskWatcher) SetOptions(opts TaskWatchOptions) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.opts = opts.withDefaults()
+}
+
+// Watch 开始跟踪任务，ctx 需携带任务所属的租户信息；查询使用该租户的服务凭据，不沿用 ctx 中的用户令牌
+func (w *TaskWatcher) Watch(ctx context.Context, source TaskInfoSource, taskUUID string) {
+	info, err := tenant.GetTenantFromContext(ctx)
+	if err != nil {
+		log.Printf("飞行任务 %s 缺少租户信息，不跟踪状态: %v", taskUUID, err)
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.opts.Credentials.Service(info); err != nil {
+		log.Printf("不跟踪飞行任务 %s 状态: %v", taskUUID, err)
+		return
+	}
+	owner := tenant.NewTenantInfo(info.TenantId, "", info.ProjectUUID)
+	owner.OrgID = info.OrgID
+	w.tasks[taskUUID] = &watchedTask{tenant: owner, source: source, since: time.Now()}
+	if !w.running {
+		w.running = true
+		go w.loop()
+	}
+}
+
+// Unwatch 停止跟踪任务
+func (w *TaskWatcher) Unwatch(taskUUID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.tasks, taskUUID)
+}
+
+// Watching 跟踪中的任务数
+func (w *TaskWatcher) Watching() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.tasks)
+}
+
+// PollOnce 查询一次全部跟踪中的任务，返回发布的事件
+func (w *TaskWatcher) PollOnce() []TaskEvent {
+	w.mu.Lock()
+	opts := w.opts
+	tasks := make(map[string]*watchedTask, len(w.tasks))
+	for id, task := range w.tasks {
+		tasks[id] = task
+	}
+	w.mu.Unlock()
+
+	var events []TaskEvent
+	for id, task := range tasks {
+		if time.Since(task.since) > opts.MaxAge {
+			log.Printf("飞行任务 %s 跟踪超过 %s 仍未结束，停止跟踪", id, opts.MaxAge)
+			w.Unwatch(id)
+			continue
+		}
+		event, err := w.query(opts, task, id)
+		if err != nil {
+			w.failed(opts, task, id, err)
+			continue
+		}
+		w.mu.Lock()
+		task.errors = 0
+		w.mu.Unlock()
+		if PublishTaskStatus(event) {
+			events = append(events, event)
+		}
+		if event.Terminal() {
+			w.Unwatch(id)
+		}
+	}
+	return events
+}
+
+// query 以任务所属租户的服务凭据查询任务状态
+func (w *TaskWatcher) query(opts TaskWatchOptions, task *watchedTask, taskUUID string) (TaskEvent, error) {
+	info, err := opts.Credentials.Service(task.tenant)
+	if err != nil {
+		return TaskEvent{}, err
+	}
+	resp, err := task.source.GetFlightTaskInfo(tenant.WithTenant(context.Background(), info), taskUUID)
+	if err != nil {
+		return TaskEvent{}, err
+	}
+	event, err := ParseTask([]byte(resp))
+	if err != nil {
+		return TaskEvent{}, err
+	}
+	if event.TaskUUID == "" {
+		event.TaskUUID = taskUUID
+	}
+	return event, nil
+}
+
+// failed 记录查询失败，连续失败达到上限时停止跟踪
+func (w *TaskWatcher) failed(opts TaskWatchOptions, task *watchedTask, taskUUID string, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	task.errors++
+	if task.errors < opts.MaxErrors {
+		log.Printf("查询飞行任务 %s 状态失败: %v", taskUUID, err)
+		return
+	}
+	log.Printf("查询飞行任务 %s 状态连续失败 %d 次，停止跟踪: %v", taskUUID, task.errors, err)
+	// 查询期间重新登记的任务不受影响
+	if w.tasks[taskUUID] == task {
+		delete(w.tasks, taskUUID)
+	}
+}
+
+func (w *TaskWatcher) loop() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.PollOnce()
+		<-ticker.C
+		w.mu.Lock()
+		if len(w.tasks) == 0 {
+			w.running = false
+			w.mu.Unlock()
+			return
+		}
+		w.mu.Unlock()
+	}
+}
+
+// defaultWatcher 全局任务跟踪器，插件创建任务后登记
+var defaultWatcher = NewTaskWatcher(TaskWatchOptions{})
+
+// DefaultTaskWatcher 全局任务跟踪器
+func DefaultTaskWatcher() *TaskWatcher {
+	return defaultWatcher
+}
+
+// WatchTask 由全局任务跟踪器跟踪任务状态
+func WatchTask(ctx context.Context, source TaskInfoSource, taskUUID string) {
+	defaultWatcher.Watch(ctx, source, taskUUID)
+}
@@ -0,0 +1,114 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+)
+
+// fakeTaskSource 记录查询使用的令牌，按设置返回任务状态或错误
+type fakeTaskSource struct {
+	mu     sync.Mutex
+	status string
+	err    error
+	tokens []string
+}
+
+func (f *fakeTaskSource) GetFlightTaskInfo(ctx context.Context, taskUUID string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if info, err := tenant.GetTenantFromContext(ctx); err == nil {
+		f.tokens = append(f.tokens, info.UserToken)
+	}
+	if f.err != nil {
+		return "", f.err
+	}
+	return fmt.Sprintf(`{"data":{"uuid":%q,"status":%q}}`, taskUUID, f.status), nil
+}
+
+func (f *fakeTaskSource) set(status string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status, f.err = status, err
+}
+
+// newTestWatcher 创建不启动后台轮询的跟踪器，由测试调用 PollOnce
+func newTestWatcher(opts TaskWatchOptions) *TaskWatcher {
+	w := NewTaskWatcher(opts)
+	w.running = true
+	return w
+}
+
+// TestTaskWatcherCredentials 以租户的服务凭据查询任务状态，未配置服务凭据的租户不跟踪
+func TestTaskWatcherCredentials(t *testing.T) {
+	w := newTestWatcher(TaskWatchOptions{Credentials: tenant.ServiceTokens{1: "service-1"}})
+	source := &fakeTaskSource{status: "in_progress"}
+	user := tenant.WithTenant(context.Background(), tenant.NewTenantInfo(1, "user-token", "project"))
+
+	w.Watch(user, source, "task-credentials")
+	w.Watch(tenant.WithTenant(context.Background(), tenant.NewTenantInfo(2, "user-token", "project")), source, "task-other-tenant")
+	w.Watch(context.Background(), source, "task-no-tenant")
+	if w.Watching() != 1 {
+		t.Fatalf("只应跟踪配置了服务凭据的租户的任务，实际跟踪 %d 个", w.Watching())
+	}
+
+	events := w.PollOnce()
+	if len(events) != 1 || events[0].Status != "in_progress" {
+		t.Errorf("事件 %+v", events)
+	}
+	source.set("success", nil)
+	w.PollOnce()
+	if w.Watching() != 0 {
+		t.Error("任务结束后应停止跟踪")
+	}
+	if len(source.tokens) != 2 || source.tokens[0] != "service-1" || source.tokens[1] != "service-1" {
+		t.Errorf("查询使用的令牌 %v，应为服务凭据", source.tokens)
+	}
+}
+
+// TestTaskWatcherLimits 连续查询失败达到上限或超过最长跟踪时长的任务停止跟踪，查询成功后重新计数
+func TestTaskWatcherLimits(t *testing.T) {
+	w := newTestWatcher(TaskWatchOptions{MaxErrors: 3, MaxAge: time.Hour, Credentials: tenant.ServiceTokens{1: "service-1"}})
+	ctx := tenant.WithTenant(context.Background(), tenant.NewTenantInfo(1, "user-token", "project"))
+	source := &fakeTaskSource{}
+
+	w.Watch(ctx, source, "task-errors")
+	source.set("", errors.New("网关超时"))
+	w.PollOnce()
+	w.PollOnce()
+	source.set("in_progress", nil)
+	w.PollOnce()
+	source.set("", errors.New("网关超时"))
+	w.PollOnce()
+	w.PollOnce()
+	if w.Watching() != 1 {
+		t.Fatal("查询成功后应重新计算连续失败次数")
+	}
+	w.PollOnce()
+	if w.Watching() != 0 {
+		t.Error("连续失败达到上限后应停止跟踪")
+	}
+
+	source.set("in_progress", nil)
+	w.Watch(ctx, source, "task-age")
+	w.mu.Lock()
+	w.tasks["task-age"].since = time.Now().Add(-2 * time.Hour)
+	w.mu.Unlock()
+	queried := len(source.tokens)
+	w.PollOnce()
+	if w.Watching() != 0 || len(source.tokens) != queried {
+		t.Error("超过最长跟踪时长的任务应停止跟踪且不再查询")
+	}
+
+	w.Watch(ctx, source, "task-revoked")
+	w.SetOptions(TaskWatchOptions{MaxErrors: 1})
+	w.PollOnce()
+	if w.Watching() != 0 {
+		t.Error("服务凭据被移除后查询失败，达到上限应停止跟踪")
+	}
+}
@@ -0,0 +1,112 @@
+package tenant
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HTTP请求头，与司空2 OpenAPI 的请求头保持一致
+const (
+	HeaderTenantID    = "X-Tenant-Id"
+	HeaderUserToken   = "X-User-Token"
+	HeaderProjectUUID = "X-Project-Uuid"
+	HeaderOrgID       = "X-Org-Id"
+	HeaderRequestID   = "X-Request-Id"
+)
+
+// 服务端授予租户的权限，格式为 平台:操作
+const (
+	PermFH2Read     = "fh2:read"
+	PermFH2Write    = "fh2:write"
+	PermDock2Read   = "dock2:read"
+	PermDock2Write  = "dock2:write"
+	PermPluginRead  = "plugin:read"
+	PermPluginAdmin = "plugin:admin"
+	// PermGeofenceOverride 人工确认放行限飞区，仅有 fh2:write、dock2:write 时携带确认原因会被拒绝
+	PermGeofenceOverride = "geofence:override"
+)
+
+// ErrUnauthenticated 缺少用户令牌、令牌未登记或与声明的租户不符
+var ErrUnauthenticated = errors.New("身份校验失败")
+
+// Tokens 服务端登记的用户令牌，键为令牌的 SHA-256 摘要（小写十六进制，见 TokenDigest），值为令牌所属的租户ID
+type Tokens map[string]int64
+
+// TokenDigest 用户令牌的 SHA-256 摘要，配置文件只保存摘要
+func TokenDigest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify 校验请求头中的用户令牌并以令牌所属的租户作为请求身份
+// 令牌须已登记；请求头中的租户ID可省略，携带时必须与令牌所属的租户一致。权限由服务端按租户授予（见 GrantPermissions）
+func (t Tokens) Verify(h http.Header) (*TenantInfo, error) {
+	token := strings.TrimSpace(h.Get(HeaderUserToken))
+	if token == "" {
+		return nil, fmt.Errorf("%w: 缺少请求头 %s", ErrUnauthenticated, HeaderUserToken)
+	}
+	tenantID, ok := t[TokenDigest(token)]
+	if !ok {
+		return nil, fmt.Errorf("%w: 用户令牌未登记", ErrUnauthenticated)
+	}
+	if rawID := strings.TrimSpace(h.Get(HeaderTenantID)); rawID != "" && rawID != strconv.FormatInt(tenantID, 10) {
+		return nil, fmt.Errorf("%w: 用户令牌不属于租户 %s", ErrUnauthenticated, rawID)
+	}
+	info := NewTenantInfo(tenantID, token, strings.TrimSpace(h.Get(HeaderProjectUUID)))
+	info.OrgID = strings.TrimSpace(h.Get(HeaderOrgID))
+	return info, nil
+}
+
+// ServiceTokens 租户的服务凭据（如司空2组织密钥），键为租户ID
+// 推送轮询、建模跟踪等不随用户请求结束的后台查询使用服务凭据，不沿用发起订阅或提交的用户令牌
+type ServiceTokens map[int64]string
+
+// Service 以租户的服务凭据代替 info 中的用户令牌，保留项目与组织，不携带权限；租户未配置服务凭据时返回错误
+func (s ServiceTokens) Service(info *TenantInfo) (*TenantInfo, error) {
+	token := s[info.TenantId]
+	if token == "" {
+		return nil, fmt.Errorf("租户 %d 未配置服务凭据 service_token", info.TenantId)
+	}
+	service := NewTenantInfo(info.TenantId, token, info.ProjectUUID)
+	service.OrgID = info.OrgID
+	return service, nil
+}
+
+// Operator 操作人标识：租户ID与用户令牌摘要前12位，可对照配置中登记的令牌摘要确定具体用户，不暴露令牌本身
+func (ti *TenantInfo) Operator() string {
+	return fmt.Sprintf("租户 %d 令牌 %s", ti.TenantId, TokenDigest(ti.UserToken)[:12])
+}
+
+// GrantPermissions 授予权限，已有的权限不重复添加
+func (ti *TenantInfo) GrantPermissions(permissions ...string) {
+	for _, p := range permissions {
+		if !ti.hasExact(p) {
+			ti.Permissions = append(ti.Permissions, p)
+		}
+	}
+}
+
+// HasPermission 是否具备权限，支持通配：* 表示全部权限，fh2:* 表示 fh2 下的全部权限
+func (ti *TenantInfo) HasPermission(permission string) bool {
+	if ti.hasExact("*") || ti.hasExact(permission) {
+		return true
+	}
+	if i := strings.Index(permission, ":"); i > 0 {
+		return ti.hasExact(permission[:i] + ":*")
+	}
+	return false
+}
+
+func (ti *TenantInfo) hasExact(permission string) bool {
+	for _, p := range ti.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package tenant
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestTokensVerify(t *testing.T) {
+	tokens := Tokens{TokenDigest("token-1"): 1, TokenDigest("token-2"): 2}
+	header := func(tenantID, token string) http.Header {
+		h := make(http.Header)
+		if tenantID != "" {
+			h.Set(HeaderTenantID, tenantID)
+		}
+		if token != "" {
+			h.Set(HeaderUserToken, token)
+		}
+		h.Set(HeaderProjectUUID, "project")
+		return h
+	}
+
+	for _, tc := range []struct {
+		name     string
+		header   http.Header
+		tenantID int64
+	}{
+		{"租户取自令牌", header("", "token-1"), 1},
+		{"声明的租户与令牌一致", header("2", "token-2"), 2},
+	} {
+		info, err := tokens.Verify(tc.header)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if info.TenantId != tc.tenantID || info.ProjectUUID != "project" {
+			t.Errorf("%s: 租户 %d 项目 %s，应为租户 %d", tc.name, info.TenantId, info.ProjectUUID, tc.tenantID)
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		header http.Header
+	}{
+		{"缺少令牌", header("1", "")},
+		{"令牌未登记", header("1", "unknown")},
+		{"冒用其他租户", header("2", "token-1")},
+	} {
+		if _, err := tokens.Verify(tc.header); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("%s: 应返回 ErrUnauthenticated，实际 %v", tc.name, err)
+		}
+	}
+}
+
+func TestServiceTokens(t *testing.T) {
+	tokens := ServiceTokens{1: "service-1"}
+	user := NewTenantInfo(1, "user-token", "project")
+	user.OrgID = "org"
+	user.GrantPermissions("*")
+
+	info, err := tokens.Service(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.UserToken != "service-1" || info.ProjectUUID != "project" || info.OrgID != "org" || len(info.Permissions) != 0 {
+		t.Errorf("服务凭据身份 = %+v", info)
+	}
+	if _, err := tokens.Service(NewTenantInfo(2, "user-token", "")); err == nil {
+		t.Error("未配置服务凭据的租户应返回错误")
+	}
+}
@@ -3,7 +3,6 @@ package validator
 import (
 	"fmt"
 	"net/url"
-	"regexp"
 )
 
 // InputValidator 输入验证器
@@ -21,11 +20,7 @@ func (iv *InputValidator) ValidateUUID(uuid string) error {
 		return fmt.Errorf("UUID不能为空")
 	}
 	// UUID格式验证
-	uuidRegex := regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
-	if !uuidRegex.MatchString(uuid) {
-		return fmt.Errorf("UUID格式无效: %s", uuid)
-	}
-	return nil
+	return checkUUID(uuid)
 }
 
 // ValidateDeviceSN 验证设备序列号
@@ -33,16 +28,8 @@ func (iv *InputValidator) ValidateDeviceSN(sn string) error {
 	if sn == "" {
 		return fmt.Errorf("设备序列号不能为空")
 	}
-	// 设备序列号长度限制
-	if len(sn) > 35 {
-		return fmt.Errorf("设备序列号长度超限: %d", len(sn))
-	}
-	// 只允许字母、数字和特定符号
-	snRegex := regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
-	if !snRegex.MatchString(sn) {
-		return fmt.Errorf("设备序列号包含非法字符: %s", sn)
-	}
-	return nil
+	// 长度限制，只允许字母、数字和特定符号
+	return checkSN(sn)
 }
 
 // ValidateProjectName 验证项目名称
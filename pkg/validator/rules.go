@@ -0,0 +1,264 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 预编译的正则
+var (
+	uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	snRegex   = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// maxSNLength 设备序列号最大长度
+const maxSNLength = 35
+
+// builtinRules 内置规则
+var builtinRules = map[string]RuleFactory{
+	"uuid":     StringRule(checkUUID),
+	"sn":       StringRule(checkSN),
+	"oneof":    oneofRule,
+	"min":      boundRule(false),
+	"max":      boundRule(true),
+	"lat":      rangeRule(-90, 90, "纬度"),
+	"lng":      rangeRule(-180, 180, "经度"),
+	"cron":     StringRule(checkCron),
+	"timezone": StringRule(checkTimezone),
+}
+
+// StringRule 将字符串校验函数包装为无参数规则，便于注册自定义规则
+//
+//	validator.RegisterRule("camera_index", validator.StringRule(func(s string) error { ... }))
+func StringRule(fn func(s string) error) RuleFactory {
+	return func(param string) (Check, error) {
+		if param != "" {
+			return nil, fmt.Errorf("不接受参数")
+		}
+		return func(value reflect.Value) error {
+			if value.Kind() != reflect.String {
+				return fmt.Errorf("只能用于字符串字段")
+			}
+			return fn(value.String())
+		}, nil
+	}
+}
+
+// checkUUID UUID格式
+func checkUUID(s string) error {
+	if !uuidRegex.MatchString(s) {
+		return fmt.Errorf("UUID格式无效: %s", s)
+	}
+	return nil
+}
+
+// checkSN 设备序列号：字母、数字、下划线与短横线，最长35位
+func checkSN(s string) error {
+	if len(s) > maxSNLength {
+		return fmt.Errorf("设备序列号长度超限: %d", len(s))
+	}
+	if !snRegex.MatchString(s) {
+		return fmt.Errorf("设备序列号包含非法字符: %s", s)
+	}
+	return nil
+}
+
+// checkTimezone IANA时区名称，例如 Asia/Shanghai
+func checkTimezone(s string) error {
+	if s == "" || s == "Local" {
+		return fmt.Errorf("时区无效: %s", s)
+	}
+	if _, err := time.LoadLocation(s); err != nil {
+		return fmt.Errorf("时区无效: %s", s)
+	}
+	return nil
+}
+
+// oneofRule 值必须是空格分隔的候选值之一，适用于字符串与整数
+func oneofRule(param string) (Check, error) {
+	options := strings.Fields(param)
+	if len(options) == 0 {
+		return nil, fmt.Errorf("缺少候选值")
+	}
+	allowed := make(map[string]bool, len(options))
+	for _, option := range options {
+		allowed[option] = true
+	}
+	return func(value reflect.Value) error {
+		var s string
+		switch value.Kind() {
+		case reflect.String:
+			s = value.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			s = strconv.FormatInt(value.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			s = strconv.FormatUint(value.Uint(), 10)
+		default:
+			return fmt.Errorf("oneof 不支持 %s 类型", value.Kind())
+		}
+		if !allowed[s] {
+			return fmt.Errorf("必须是 [%s] 之一，当前为 %s", param, s)
+		}
+		return nil
+	}, nil
+}
+
+// boundRule min/max：数值比较大小，字符串比较字符数，切片与map比较元素个数
+func boundRule(upper bool) RuleFactory {
+	return func(param string) (Check, error) {
+		bound, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil, fmt.Errorf("参数必须是数字: %s", param)
+		}
+		violated := func(n float64) bool {
+			if upper {
+				return n > bound
+			}
+			return n < bound
+		}
+		word := "小于"
+		if upper {
+			word = "大于"
+		}
+		return func(value reflect.Value) error {
+			switch value.Kind() {
+			case reflect.String:
+				if n := utf8.RuneCountInString(value.String()); violated(float64(n)) {
+					return fmt.Errorf("长度不能%s %s，当前为 %d", word, param, n)
+				}
+			case reflect.Slice, reflect.Array, reflect.Map:
+				if n := value.Len(); violated(float64(n)) {
+					return fmt.Errorf("元素个数不能%s %s，当前为 %d", word, param, n)
+				}
+			default:
+				n, ok := numeric(value)
+				if !ok {
+					return fmt.Errorf("不支持 %s 类型", value.Kind())
+				}
+				if violated(n) {
+					return fmt.Errorf("不能%s %s，当前为 %v", word, param, n)
+				}
+			}
+			return nil
+		}, nil
+	}
+}
+
+// rangeRule 数值必须在闭区间内
+func rangeRule(lower, upper float64, label string) RuleFactory {
+	return func(param string) (Check, error) {
+		if param != "" {
+			return nil, fmt.Errorf("不接受参数")
+		}
+		return func(value reflect.Value) error {
+			n, ok := numeric(value)
+			if !ok {
+				return fmt.Errorf("只能用于数值字段")
+			}
+			if n < lower || n > upper {
+				return fmt.Errorf("%s超出范围 [%v, %v]: %v", label, lower, upper, n)
+			}
+			return nil
+		}, nil
+	}
+}
+
+// numeric 读取数值字段
+func numeric(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+	return 0, false
+}
+
+// cronField cron表达式字段的取值范围与别名
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMonths = map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6, "JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}
+	cronDays   = map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+	cronFields = []cronField{
+		{name: "分", min: 0, max: 59},
+		{name: "时", min: 0, max: 23},
+		{name: "日", min: 1, max: 31},
+		{name: "月", min: 1, max: 12, names: cronMonths},
+		{name: "周", min: 0, max: 7, names: cronDays},
+	}
+	cronSecond = cronField{name: "秒", min: 0, max: 59}
+)
+
+// checkCron 标准5段cron表达式（分 时 日 月 周），也接受以秒开头的6段表达式
+func checkCron(s string) error {
+	parts := strings.Fields(s)
+	fields := cronFields
+	switch len(parts) {
+	case 5:
+	case 6:
+		fields = append([]cronField{cronSecond}, cronFields...)
+	default:
+		return fmt.Errorf("cron表达式应为5或6段: %s", s)
+	}
+	for i, part := range parts {
+		if err := checkCronField(part, fields[i]); err != nil {
+			return fmt.Errorf("cron表达式无效: %s（%s）", s, err)
+		}
+	}
+	return nil
+}
+
+// checkCronField 校验cron单个字段：* ? 数值 区间 步长 列表
+func checkCronField(part string, field cronField) error {
+	for _, item := range strings.Split(part, ",") {
+		rangePart, step, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			n, err := strconv.Atoi(step)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("%s字段步长无效: %s", field.name, step)
+			}
+		}
+		if rangePart == "*" || rangePart == "?" {
+			continue
+		}
+		from, to, isRange := strings.Cut(rangePart, "-")
+		low, err := cronValue(from, field)
+		if err != nil {
+			return err
+		}
+		if isRange {
+			high, err := cronValue(to, field)
+			if err != nil {
+				return err
+			}
+			if low > high {
+				return fmt.Errorf("%s字段区间无效: %s", field.name, rangePart)
+			}
+		}
+	}
+	return nil
+}
+
+// cronValue 解析cron字段中的单个值
+func cronValue(s string, field cronField) (int, error) {
+	if n, ok := field.names[strings.ToUpper(s)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < field.min || n > field.max {
+		return 0, fmt.Errorf("%s字段取值应在 %d~%d: %s", field.name, field.min, field.max, s)
+	}
+	return n, nil
+}
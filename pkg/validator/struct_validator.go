@@ -0,0 +1,273 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Check 校验单个字段值，返回nil表示通过，错误信息描述违规原因（不含字段名）
+type Check func(value reflect.Value) error
+
+// RuleFactory 根据规则参数构造校验函数，例如 min=10 的参数为 "10"
+// 结构体类型首次校验时调用一次，参数无效时返回错误
+type RuleFactory func(param string) (Check, error)
+
+// FieldError 单个字段的校验违规
+type FieldError struct {
+	Field   string // 字段路径，例如 points[0].latitude
+	Rule    string // 规则名称
+	Param   string // 规则参数
+	Message string // 违规原因
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationErrors 全部字段的校验违规
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, e := range v {
+		messages[i] = e.Error()
+	}
+	return "参数校验失败: " + strings.Join(messages, "; ")
+}
+
+// StructValidator 基于 validate 标签的结构体校验器
+// 标签形如 `validate:"required,uuid"`，多个规则以逗号分隔，规则参数以等号给出，例如 oneof=gps rtk、min=1
+// 每个结构体类型的规则在首次校验时解析并缓存，之后直接复用
+type StructValidator struct {
+	mu    sync.RWMutex
+	rules map[string]RuleFactory
+	plans map[reflect.Type]*structPlan
+}
+
+// structPlan 预编译的结构体校验计划
+type structPlan struct {
+	fields []fieldPlan
+	err    error // 标签无效
+}
+
+// fieldPlan 预编译的字段校验计划
+type fieldPlan struct {
+	index     int
+	name      string
+	required  bool
+	omitempty bool
+	checks    []namedCheck
+}
+
+// namedCheck 带名称的校验函数
+type namedCheck struct {
+	rule  string
+	param string
+	check Check
+}
+
+// NewStructValidator 创建结构体校验器，内置 required、omitempty、uuid、sn、oneof、min、max、lat、lng、cron、timezone 规则
+func NewStructValidator() *StructValidator {
+	v := &StructValidator{
+		rules: make(map[string]RuleFactory),
+		plans: make(map[reflect.Type]*structPlan),
+	}
+	for name, factory := range builtinRules {
+		v.rules[name] = factory
+	}
+	return v
+}
+
+// RegisterRule 注册自定义规则，同名规则会被覆盖；已缓存的校验计划会被清空
+func (v *StructValidator) RegisterRule(name string, factory RuleFactory) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rules[name] = factory
+	v.plans = make(map[reflect.Type]*structPlan)
+}
+
+// Validate 校验结构体或结构体指针，返回包含全部违规项的 ValidationErrors；标签无效时返回普通错误
+func (v *StructValidator) Validate(s interface{}) error {
+	value := reflect.ValueOf(s)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return fmt.Errorf("校验对象不能为nil")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("只能校验结构体: %s", value.Type())
+	}
+
+	var errs ValidationErrors
+	if err := v.validateStruct(value, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateStruct 按校验计划校验结构体，违规项追加到 errs
+func (v *StructValidator) validateStruct(value reflect.Value, prefix string, errs *ValidationErrors) error {
+	plan := v.plan(value.Type())
+	if plan.err != nil {
+		return plan.err
+	}
+	for _, field := range plan.fields {
+		path := field.name
+		if prefix != "" {
+			path = prefix + "." + field.name
+		}
+		if err := v.validateField(value.Field(field.index), field, path, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateField 校验单个字段，字段为结构体、结构体切片时继续校验其元素
+func (v *StructValidator) validateField(value reflect.Value, field fieldPlan, path string, errs *ValidationErrors) error {
+	if value.IsZero() {
+		if field.required {
+			*errs = append(*errs, FieldError{Field: path, Rule: "required", Message: "不能为空"})
+		}
+		if field.required || field.omitempty || value.Kind() == reflect.Pointer {
+			return nil
+		}
+	}
+	for value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	for _, c := range field.checks {
+		if err := c.check(value); err != nil {
+			*errs = append(*errs, FieldError{Field: path, Rule: c.rule, Param: c.param, Message: err.Error()})
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		return v.validateStruct(value, path, errs)
+	case reflect.Slice, reflect.Array:
+		if elemStruct(value.Type().Elem()) {
+			for i := 0; i < value.Len(); i++ {
+				elem := value.Index(i)
+				for elem.Kind() == reflect.Pointer {
+					if elem.IsNil() {
+						break
+					}
+					elem = elem.Elem()
+				}
+				if elem.Kind() != reflect.Struct {
+					continue
+				}
+				if err := v.validateStruct(elem, fmt.Sprintf("%s[%d]", path, i), errs); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// plan 获取或编译结构体校验计划
+func (v *StructValidator) plan(t reflect.Type) *structPlan {
+	v.mu.RLock()
+	plan, ok := v.plans[t]
+	v.mu.RUnlock()
+	if ok {
+		return plan
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if plan, ok := v.plans[t]; ok {
+		return plan
+	}
+	plan = v.compile(t)
+	v.plans[t] = plan
+	return plan
+}
+
+// compile 解析结构体字段的 validate 标签，调用方需持有写锁
+func (v *StructValidator) compile(t reflect.Type) *structPlan {
+	plan := &structPlan{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+		field := fieldPlan{index: i, name: fieldName(sf)}
+		for _, item := range strings.Split(tag, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			name, param, _ := strings.Cut(item, "=")
+			switch name {
+			case "required":
+				field.required = true
+				continue
+			case "omitempty":
+				field.omitempty = true
+				continue
+			}
+			factory, ok := v.rules[name]
+			if !ok {
+				plan.err = fmt.Errorf("%s.%s 的校验规则 %s 未注册", t, sf.Name, name)
+				return plan
+			}
+			check, err := factory(param)
+			if err != nil {
+				plan.err = fmt.Errorf("%s.%s 的校验规则 %s 无效: %w", t, sf.Name, item, err)
+				return plan
+			}
+			field.checks = append(field.checks, namedCheck{rule: name, param: param, check: check})
+		}
+		// 没有规则的普通字段无需校验，但结构体字段仍需递归
+		if tag == "" && !elemStruct(sf.Type) {
+			continue
+		}
+		plan.fields = append(plan.fields, field)
+	}
+	return plan
+}
+
+// fieldName 字段路径名称，优先使用 json 标签
+func fieldName(sf reflect.StructField) string {
+	if tag := sf.Tag.Get("json"); tag != "" && tag != "-" {
+		if name, _, _ := strings.Cut(tag, ","); name != "" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+// elemStruct 类型（或其指针、切片元素）是否为结构体
+func elemStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// 全局结构体校验器
+var defaultStructValidator = NewStructValidator()
+
+// Validate 使用全局校验器校验结构体
+func Validate(s interface{}) error {
+	return defaultStructValidator.Validate(s)
+}
+
+// RegisterRule 向全局校验器注册自定义规则
+func RegisterRule(name string, factory RuleFactory) {
+	defaultStructValidator.RegisterRule(name, factory)
+}
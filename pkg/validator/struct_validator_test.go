@@ -0,0 +1,152 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type point struct {
+	Latitude  float64 `json:"latitude" validate:"lat"`
+	Longitude float64 `json:"longitude" validate:"lng"`
+}
+
+type routeRequest struct {
+	Name     string   `json:"name" validate:"required,max=5"`
+	SN       string   `json:"sn" validate:"required,sn"`
+	Mode     string   `json:"mode" validate:"omitempty,oneof=gps rtk"`
+	Level    int      `json:"level" validate:"oneof=1 2 3"`
+	Speed    *float64 `json:"speed" validate:"min=1,max=15"`
+	Cron     string   `json:"cron" validate:"omitempty,cron"`
+	Points   []point  `json:"points" validate:"min=2"`
+	Home     *point   `json:"home"`
+	Internal string
+}
+
+// validationErrors 将错误转换为 字段:规则 列表
+func validationErrors(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("应返回 ValidationErrors，实际 %T: %v", err, err)
+	}
+	list := make([]string, len(verrs))
+	for i, e := range verrs {
+		list[i] = e.Field + ":" + e.Rule
+	}
+	return list
+}
+
+func TestValidate(t *testing.T) {
+	speed, tooFast := 10.0, 20.0
+	valid := func() routeRequest {
+		return routeRequest{
+			Name:   "巡检航线",
+			SN:     "7CTXN4A00B0001H",
+			Level:  2,
+			Speed:  &speed,
+			Points: []point{{22.5, 114.0}, {22.6, 114.1}},
+		}
+	}
+
+	cases := []struct {
+		name   string
+		modify func(*routeRequest)
+		want   []string
+	}{
+		{"通过", func(r *routeRequest) {}, nil},
+		{"可选字段为空时跳过", func(r *routeRequest) { r.Mode, r.Cron, r.Speed = "", "", nil }, nil},
+		{"必填", func(r *routeRequest) { r.Name, r.SN = "", "" }, []string{"name:required", "sn:required"}},
+		{"字符数上限", func(r *routeRequest) { r.Name = "一二三四五六" }, []string{"name:max"}},
+		{"序列号", func(r *routeRequest) { r.SN = "bad sn" }, []string{"sn:sn"}},
+		{"候选值", func(r *routeRequest) { r.Mode, r.Level = "beidou", 4 }, []string{"mode:oneof", "level:oneof"}},
+		{"指针数值", func(r *routeRequest) { r.Speed = &tooFast }, []string{"speed:max"}},
+		{"cron", func(r *routeRequest) { r.Cron = "0 25 * * *" }, []string{"cron:cron"}},
+		{"元素个数", func(r *routeRequest) { r.Points = r.Points[:1] }, []string{"points:min"}},
+		{"切片元素", func(r *routeRequest) { r.Points[1].Latitude = 91 }, []string{"points[1].latitude:lat"}},
+		{"嵌套指针", func(r *routeRequest) { r.Home = &point{Latitude: 22, Longitude: 190} }, []string{"home.longitude:lng"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := valid()
+			tc.modify(&req)
+			if got := validationErrors(t, Validate(&req)); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("校验结果 %v，应为 %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateInvalidInput(t *testing.T) {
+	var nilReq *routeRequest
+	if err := Validate(nilReq); err == nil {
+		t.Error("nil 指针应返回错误")
+	}
+	if err := Validate("text"); err == nil {
+		t.Error("非结构体应返回错误")
+	}
+
+	type unknownRule struct {
+		Name string `validate:"camera"`
+	}
+	type badParam struct {
+		Count int `validate:"min=abc"`
+	}
+	for _, s := range []interface{}{unknownRule{}, badParam{}} {
+		err := Validate(s)
+		var verrs ValidationErrors
+		if err == nil || errors.As(err, &verrs) {
+			t.Errorf("%T 的标签无效，应返回普通错误，实际 %v", s, err)
+		}
+	}
+}
+
+func TestRegisterRule(t *testing.T) {
+	type camera struct {
+		Index string `json:"index" validate:"camera"`
+	}
+	v := NewStructValidator()
+	if err := v.Validate(camera{Index: "81-0-0"}); err == nil {
+		t.Fatal("未注册的规则应返回错误")
+	}
+	v.RegisterRule("camera", StringRule(func(s string) error {
+		if strings.Count(s, "-") != 2 {
+			return fmt.Errorf("相机索引格式无效: %s", s)
+		}
+		return nil
+	}))
+	if err := v.Validate(camera{Index: "81-0-0"}); err != nil {
+		t.Fatalf("注册规则后应通过校验: %v", err)
+	}
+	if got := validationErrors(t, v.Validate(camera{Index: "81"})); !reflect.DeepEqual(got, []string{"index:camera"}) {
+		t.Fatalf("校验结果 %v", got)
+	}
+}
+
+func TestCheckTimezoneAndCron(t *testing.T) {
+	for _, tz := range []string{"Asia/Shanghai", "UTC"} {
+		if err := checkTimezone(tz); err != nil {
+			t.Errorf("%s 应有效: %v", tz, err)
+		}
+	}
+	for _, tz := range []string{"", "Local", "Mars/Base"} {
+		if err := checkTimezone(tz); err == nil {
+			t.Errorf("%q 应无效", tz)
+		}
+	}
+	for _, expr := range []string{"*/5 * * * *", "0 0 8 * * MON-FRI", "0 9 1,15 JAN-JUN ?"} {
+		if err := checkCron(expr); err != nil {
+			t.Errorf("%s 应有效: %v", expr, err)
+		}
+	}
+	for _, expr := range []string{"* * *", "60 * * * *", "0 10-8 * * *", "*/0 * * * *"} {
+		if err := checkCron(expr); err == nil {
+			t.Errorf("%s 应无效", expr)
+		}
+	}
+}
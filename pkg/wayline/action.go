@@ -0,0 +1,152 @@
+package wayline
+
+import (
+	"strconv"
+	"time"
+)
+
+// ActionFunc 航点动作类型（actionActuatorFunc）
+type ActionFunc string
+
+const (
+	ActionTakePhoto    ActionFunc = "takePhoto"    // 拍照
+	ActionStartRecord  ActionFunc = "startRecord"  // 开始录像
+	ActionStopRecord   ActionFunc = "stopRecord"   // 结束录像
+	ActionGimbalRotate ActionFunc = "gimbalRotate" // 云台转动
+	ActionHover        ActionFunc = "hover"        // 悬停等待
+	ActionRotateYaw    ActionFunc = "rotateYaw"    // 飞行器偏航
+	ActionZoom         ActionFunc = "zoom"         // 变焦
+	ActionFocus        ActionFunc = "focus"        // 对焦
+)
+
+// Param 动作参数，按文件中的顺序保存以便原样写回
+type Param struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Action 航点动作，参数与 WPML actionActuatorFuncParam 一一对应，未识别的动作类型原样保留
+type Action struct {
+	Func   ActionFunc `json:"func"`
+	Params []Param    `json:"params,omitempty"`
+	Extra  []Element  `json:"extra,omitempty"` // 动作中未建模的元素，按原顺序写回
+}
+
+// Element 未建模的 WPML 元素，解析时保留名称、文本与子元素以便原样写回
+type Element struct {
+	Name     string    `json:"name"` // 本地名，写回时使用 wpml 前缀
+	Value    string    `json:"value,omitempty"`
+	Children []Element `json:"children,omitempty"`
+}
+
+// TriggerType 动作组触发方式（actionTriggerType）
+type TriggerType string
+
+const (
+	TriggerReachPoint       TriggerType = "reachPoint"            // 到达航点时执行
+	TriggerBetweenPoints    TriggerType = "betweenAdjacentPoints" // 航段中执行
+	TriggerMultipleTiming   TriggerType = "multipleTiming"        // 按时间间隔重复执行
+	TriggerMultipleDistance TriggerType = "multipleDistance"      // 按距离间隔重复执行
+)
+
+// ActionGroup 动作组（actionGroup），自所在航点起至 EndIndex 航点按触发方式执行
+// 只在单个航点到点执行的动作记在 Waypoint.Actions，跨航点或等时、等距触发的动作组记在 Waypoint.ActionGroups
+type ActionGroup struct {
+	EndIndex     int         `json:"end_index"`               // 结束航点序号，不小于所在航点序号
+	Mode         string      `json:"mode,omitempty"`          // 执行方式（actionGroupMode），为空时为 sequence
+	Trigger      TriggerType `json:"trigger"`                 // 为空时为 reachPoint
+	TriggerParam string      `json:"trigger_param,omitempty"` // 触发参数（actionTriggerParam）：等时触发的秒数、等距触发的米数
+	Actions      []Action    `json:"actions"`
+	Extra        []Element   `json:"extra,omitempty"` // 动作组中未建模的元素，按原顺序写回
+}
+
+// Param 取参数值
+func (a Action) Param(name string) (string, bool) {
+	for _, p := range a.Params {
+		if p.Name == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// Float 取数值参数，不存在或无法解析时返回 0
+func (a Action) Float(name string) float64 {
+	value, _ := a.Param(name)
+	f, _ := strconv.ParseFloat(value, 64)
+	return f
+}
+
+// TakePhoto 拍照，position 为负载挂载位置
+func TakePhoto(position int) Action {
+	return Action{Func: ActionTakePhoto, Params: []Param{
+		{"payloadPositionIndex", strconv.Itoa(position)},
+		{"fileSuffix", ""},
+		{"useGlobalPayloadLensIndex", "1"},
+	}}
+}
+
+// StartRecord 开始录像
+func StartRecord(position int) Action {
+	return Action{Func: ActionStartRecord, Params: []Param{
+		{"payloadPositionIndex", strconv.Itoa(position)},
+		{"fileSuffix", ""},
+		{"useGlobalPayloadLensIndex", "1"},
+	}}
+}
+
+// StopRecord 结束录像
+func StopRecord(position int) Action {
+	return Action{Func: ActionStopRecord, Params: []Param{
+		{"payloadPositionIndex", strconv.Itoa(position)},
+	}}
+}
+
+// GimbalRotate 云台转到绝对角度，pitch 俯仰角（度，向下为负），yaw 偏航角（度，以正北为基准）
+func GimbalRotate(position int, pitch, yaw float64) Action {
+	return Action{Func: ActionGimbalRotate, Params: []Param{
+		{"gimbalHeadingYawBase", "north"},
+		{"gimbalRotateMode", "absoluteAngle"},
+		{"gimbalPitchRotateEnable", "1"},
+		{"gimbalPitchRotateAngle", formatFloat(pitch)},
+		{"gimbalRollRotateEnable", "0"},
+		{"gimbalRollRotateAngle", "0"},
+		{"gimbalYawRotateEnable", "1"},
+		{"gimbalYawRotateAngle", formatFloat(yaw)},
+		{"gimbalRotateTimeEnable", "0"},
+		{"gimbalRotateTime", "0"},
+		{"payloadPositionIndex", strconv.Itoa(position)},
+	}}
+}
+
+// Hover 悬停等待
+func Hover(d time.Duration) Action {
+	return Action{Func: ActionHover, Params: []Param{
+		{"hoverTime", formatFloat(d.Seconds())},
+	}}
+}
+
+// RotateYaw 飞行器转到指定偏航角（度，-180~180），顺时针或逆时针
+func RotateYaw(heading float64, clockwise bool) Action {
+	mode := "counterClockwise"
+	if clockwise {
+		mode = "clockwise"
+	}
+	return Action{Func: ActionRotateYaw, Params: []Param{
+		{"aircraftHeading", formatFloat(heading)},
+		{"aircraftPathMode", mode},
+	}}
+}
+
+// Zoom 变焦到指定等效焦距（毫米）
+func Zoom(position int, focalLength float64) Action {
+	return Action{Func: ActionZoom, Params: []Param{
+		{"focalLength", formatFloat(focalLength)},
+		{"isUseFocalFactor", "0"},
+		{"payloadPositionIndex", strconv.Itoa(position)},
+	}}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
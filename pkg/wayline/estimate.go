@@ -0,0 +1,125 @@
+package wayline
+
+import (
+	"math"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+// Estimate 航线航程、时长与电量估算
+type Estimate struct {
+	Waypoints      int           `json:"waypoints"`
+	Photos         int           `json:"photos"`          // 拍照动作数
+	Distance       float64       `json:"distance"`        // 总航程（米），含高度变化
+	Duration       time.Duration `json:"duration"`        // 总时长，含动作执行与航点起停
+	BatteryPercent float64       `json:"battery_percent"` // 预计消耗电量百分比
+}
+
+// Estimate 按机型限制估算航线，home 为起飞机场位置，为 nil 时只计算首航点到末航点
+// 高度变化仅在相对起飞点高度模式下计入起飞爬升与返航下降
+func (w *Wayline) Estimate(limits Limits, home *geo.Point) Estimate {
+	est := Estimate{Waypoints: len(w.Waypoints)}
+	if len(w.Waypoints) == 0 {
+		return est
+	}
+	relative := w.HeightMode == HeightRelative || w.HeightMode == ""
+	transit := capSpeed(w.Mission.TransitionalSpeed, limits)
+	var seconds float64
+	add := func(horizontal, vertical, speed float64) {
+		est.Distance += math.Hypot(horizontal, vertical)
+		seconds += segmentSeconds(horizontal, vertical, speed, limits)
+	}
+
+	first, last := w.Waypoints[0], w.Waypoints[len(w.Waypoints)-1]
+	if home != nil {
+		climb := 0.0
+		if relative {
+			climb = w.Mission.TakeOffSecurityHeight
+		}
+		add(0, climb, transit)
+		vertical := 0.0
+		if relative {
+			vertical = first.Height - climb
+		}
+		add(geo.Haversine(*home, first.Point), vertical, transit)
+	}
+	for i, wp := range w.Waypoints {
+		for _, action := range wp.Actions {
+			seconds += actionDuration(action).Seconds()
+			if action.Func == ActionTakePhoto {
+				est.Photos++
+			}
+		}
+		if i == 0 {
+			continue
+		}
+		prev := w.Waypoints[i-1]
+		speed := capSpeed(w.speedOf(prev), limits)
+		add(geo.Haversine(prev.Point, wp.Point), wp.Height-prev.Height, speed)
+		// 到点停的航点需要减速与重新加速
+		if prev.TurnMode.stops() && limits.Acceleration > 0 {
+			seconds += speed / limits.Acceleration
+		}
+	}
+	switch w.Mission.FinishAction {
+	case FinishGoHome:
+		if home != nil {
+			vertical := 0.0
+			if relative {
+				vertical = -last.Height
+			}
+			add(geo.Haversine(last.Point, *home), vertical, transit)
+		}
+	case FinishGotoFirstWaypoint:
+		add(geo.Haversine(last.Point, first.Point), first.Height-last.Height, transit)
+	}
+
+	est.Duration = time.Duration(seconds * float64(time.Second)).Round(time.Second)
+	if limits.Endurance > 0 {
+		est.BatteryPercent = math.Round(seconds/limits.Endurance.Seconds()*1000) / 10
+	}
+	return est
+}
+
+// segmentSeconds 航段飞行时长，水平与垂直同时进行，取较慢者
+func segmentSeconds(horizontal, vertical, speed float64, limits Limits) float64 {
+	t := 0.0
+	if speed > 0 {
+		t = horizontal / speed
+	}
+	rate := limits.MaxAscent
+	if vertical < 0 {
+		vertical, rate = -vertical, limits.MaxDescent
+	}
+	if rate > 0 {
+		t = math.Max(t, vertical/rate)
+	}
+	return t
+}
+
+// capSpeed 速度不超过机型上限
+func capSpeed(speed float64, limits Limits) float64 {
+	if limits.MaxSpeed > 0 && speed > limits.MaxSpeed {
+		return limits.MaxSpeed
+	}
+	return speed
+}
+
+// actionDuration 动作执行时长估算
+func actionDuration(a Action) time.Duration {
+	switch a.Func {
+	case ActionHover:
+		return time.Duration(a.Float("hoverTime") * float64(time.Second))
+	case ActionGimbalRotate:
+		if value, _ := a.Param("gimbalRotateTimeEnable"); value == "1" {
+			return time.Duration(a.Float("gimbalRotateTime") * float64(time.Second))
+		}
+		return 2 * time.Second
+	case ActionRotateYaw:
+		return 3 * time.Second
+	case ActionStartRecord, ActionStopRecord:
+		return 500 * time.Millisecond
+	}
+	return time.Second
+}
@@ -0,0 +1,155 @@
+package wayline
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+)
+
+// KMZ 内的文件
+const (
+	templateFile = "wpmz/template.kml"
+	waylinesFile = "wpmz/waylines.wpml"
+)
+
+// maxFileSize 航线文件大小上限
+const maxFileSize = 32 << 20
+
+// Parse 解析航线文件：KMZ 压缩包，或单独的 template.kml / waylines.wpml 内容
+func Parse(data []byte) (*Wayline, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '<' {
+		file, err := parseKML(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		if file.executable() {
+			return toWayline(nil, file)
+		}
+		return toWayline(file, nil)
+	}
+	return ReadKMZ(bytes.NewReader(data), int64(len(data)))
+}
+
+// ReadKMZ 读取 KMZ 压缩包，按文件名匹配 template.kml 与 waylines.wpml（不要求位于 wpmz 目录）
+func ReadKMZ(r io.ReaderAt, size int64) (*Wayline, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("读取KMZ失败: %w", err)
+	}
+	var template, waylines *kmlFile
+	for _, f := range archive.File {
+		var target **kmlFile
+		switch strings.ToLower(path.Base(f.Name)) {
+		case "template.kml":
+			target = &template
+		case "waylines.wpml":
+			target = &waylines
+		default:
+			continue
+		}
+		data, err := readZipFile(f)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parseKML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		*target = file
+	}
+	if template == nil && waylines == nil {
+		return nil, fmt.Errorf("KMZ中没有 template.kml 或 waylines.wpml")
+	}
+	return toWayline(template, waylines)
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > maxFileSize {
+		return nil, fmt.Errorf("%s 超过大小上限", f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("打开 %s 失败: %w", f.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxFileSize))
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", f.Name, err)
+	}
+	return data, nil
+}
+
+// WriteKMZ 生成 KMZ：wpmz/template.kml 与 wpmz/waylines.wpml
+func (w *Wayline) WriteKMZ(out io.Writer) error {
+	archive := zip.NewWriter(out)
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{templateFile, w.encodeTemplate()},
+		{waylinesFile, w.encodeWaylines()},
+	}
+	for _, f := range files {
+		fw, err := archive.Create(f.name)
+		if err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", f.name, err)
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", f.name, err)
+		}
+	}
+	return archive.Close()
+}
+
+// KMZ 生成 KMZ 内容
+func (w *Wayline) KMZ() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := w.WriteKMZ(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Download 按 GetWayLineInfo 的响应下载并解析航线文件，下载地址为预签名地址，不携带租户请求头
+func Download(ctx context.Context, client *httpclient.SecureHTTPClient, info string) (*Wayline, error) {
+	var resp struct {
+		Data struct {
+			DownloadURL string `json:"download_url"`
+			URL         string `json:"url"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(info), &resp); err != nil {
+		return nil, fmt.Errorf("解析航线详情失败: %w", err)
+	}
+	downloadURL := resp.Data.DownloadURL
+	if downloadURL == "" {
+		downloadURL = resp.Data.URL
+	}
+	if downloadURL == "" {
+		return nil, fmt.Errorf("航线详情中没有下载地址")
+	}
+	httpResp, err := client.DoRequest(ctx, http.MethodGet, downloadURL, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("下载航线文件失败: %w", err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载航线文件失败: %s", httpResp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, maxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("下载航线文件失败: %w", err)
+	}
+	if len(data) > maxFileSize {
+		return nil, fmt.Errorf("航线文件超过大小上限")
+	}
+	return Parse(data)
+}
@@ -0,0 +1,77 @@
+package wayline
+
+import "time"
+
+// Limits 机型飞行限制，用于航线校验与电量估算
+type Limits struct {
+	Model          string        `json:"model"`
+	MaxSpeed       float64       `json:"max_speed"`        // 最大水平速度（米/秒）
+	MaxAscent      float64       `json:"max_ascent"`       // 最大上升速度（米/秒）
+	MaxDescent     float64       `json:"max_descent"`      // 最大下降速度（米/秒）
+	Acceleration   float64       `json:"acceleration"`     // 航点起停的水平加速度（米/秒²）
+	MinHeight      float64       `json:"min_height"`       // 相对起飞点的最低航点高度（米）
+	MaxHeight      float64       `json:"max_height"`       // 相对起飞点的最高航点高度（米）
+	MinTakeOff     float64       `json:"min_take_off"`     // 最低安全起飞高度（米）
+	MaxTakeOff     float64       `json:"max_take_off"`     // 最高安全起飞高度（米）
+	MinSpacing     float64       `json:"min_spacing"`      // 相邻航点最小间距（米）
+	MaxWaypoints   int           `json:"max_waypoints"`    // 航点数量上限
+	GimbalPitchMin float64       `json:"gimbal_pitch_min"` // 云台俯仰角下限（度）
+	GimbalPitchMax float64       `json:"gimbal_pitch_max"` // 云台俯仰角上限（度）
+	Endurance      time.Duration `json:"endurance"`        // 满电悬停/巡航时长
+	ReservePercent float64       `json:"reserve_percent"`  // 航线结束时需保留的电量百分比
+}
+
+// defaultLimits 未登记机型使用的保守限制
+var defaultLimits = Limits{
+	Model:          "default",
+	MaxSpeed:       15,
+	MaxAscent:      5,
+	MaxDescent:     4,
+	Acceleration:   2,
+	MinHeight:      2,
+	MaxHeight:      1500,
+	MinTakeOff:     8,
+	MaxTakeOff:     1500,
+	MinSpacing:     0.5,
+	MaxWaypoints:   65535,
+	GimbalPitchMin: -90,
+	GimbalPitchMax: 35,
+	Endurance:      35 * time.Minute,
+	ReservePercent: 25,
+}
+
+// droneLimits 按设备型号键登记的机型限制，续航取厂商标称值的八成
+var droneLimits = map[string]Limits{
+	DroneM30.ModelKey():  withModel("M30", 23, 6, 5, 41),
+	DroneM30T.ModelKey(): withModel("M30T", 23, 6, 5, 41),
+	DroneM3E.ModelKey():  withModel("M3E", 21, 6, 6, 45),
+	DroneM3T.ModelKey():  withModel("M3T", 21, 6, 6, 45),
+	DroneM350.ModelKey(): withModel("M350 RTK", 23, 6, 5, 55),
+	DroneM3D.ModelKey():  withModel("M3D", 21, 6, 6, 50),
+	DroneM3TD.ModelKey(): withModel("M3TD", 21, 6, 6, 50),
+	DroneM4D.ModelKey():  withModel("M4D", 21, 10, 8, 54),
+	DroneM4TD.ModelKey(): withModel("M4TD", 21, 10, 8, 54),
+}
+
+func withModel(model string, maxSpeed, ascent, descent float64, enduranceMinutes int) Limits {
+	limits := defaultLimits
+	limits.Model = model
+	limits.MaxSpeed = maxSpeed
+	limits.MaxAscent = ascent
+	limits.MaxDescent = descent
+	limits.Endurance = time.Duration(enduranceMinutes) * time.Minute * 8 / 10
+	return limits
+}
+
+// LimitsFor 按设备型号键（如 0-91-0）取机型限制，未登记的机型返回保守的默认限制
+func LimitsFor(modelKey string) Limits {
+	if limits, ok := droneLimits[modelKey]; ok {
+		return limits
+	}
+	return defaultLimits
+}
+
+// Limits 航线所用机型的限制
+func (w *Wayline) Limits() Limits {
+	return LimitsFor(w.Mission.Drone.ModelKey())
+}
@@ -0,0 +1,151 @@
+package wayline
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+// ErrInvalidWayline 航线超出机型限制或内容不完整
+var ErrInvalidWayline = errors.New("航线校验未通过")
+
+// Issue 单项校验问题，Waypoint 为航点序号，航线级问题为 -1
+type Issue struct {
+	Waypoint int    `json:"waypoint"`
+	Field    string `json:"field"`
+	Reason   string `json:"reason"`
+}
+
+func (i Issue) String() string {
+	if i.Waypoint < 0 {
+		return fmt.Sprintf("%s: %s", i.Field, i.Reason)
+	}
+	return fmt.Sprintf("航点%d %s: %s", i.Waypoint, i.Field, i.Reason)
+}
+
+// ValidationError 校验未通过，可通过 errors.Is 判断 ErrInvalidWayline
+type ValidationError struct {
+	Issues   []Issue  `json:"issues"`
+	Estimate Estimate `json:"estimate"`
+}
+
+func (e *ValidationError) Error() string {
+	reasons := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		reasons[i] = issue.String()
+	}
+	return fmt.Sprintf("%v: %s", ErrInvalidWayline, strings.Join(reasons, "; "))
+}
+
+// Is 匹配 ErrInvalidWayline
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidWayline
+}
+
+// Validate 按机型限制校验航线，home 为起飞机场位置（可为 nil），用于估算电量
+// 高度限制仅在相对起飞点高度模式下校验；预计耗电超过 100-ReservePercent 时不通过
+func (w *Wayline) Validate(limits Limits, home *geo.Point) error {
+	var issues []Issue
+	fail := func(index int, field, format string, args ...interface{}) {
+		issues = append(issues, Issue{Waypoint: index, Field: field, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	if len(w.Waypoints) < 2 {
+		fail(-1, "waypoints", "至少需要2个航点，当前 %d 个", len(w.Waypoints))
+	}
+	if limits.MaxWaypoints > 0 && len(w.Waypoints) > limits.MaxWaypoints {
+		fail(-1, "waypoints", "航点数量 %d 超过上限 %d", len(w.Waypoints), limits.MaxWaypoints)
+	}
+	switch w.HeightMode {
+	case HeightRelative, HeightEGM96, HeightAGL:
+	default:
+		fail(-1, "height_mode", "不支持的高度模式 %q", w.HeightMode)
+	}
+	if w.Speed <= 0 || w.Speed > limits.MaxSpeed {
+		fail(-1, "speed", "航线速度 %.1f 超出范围 (0, %.1f]", w.Speed, limits.MaxSpeed)
+	}
+	if w.Mission.TransitionalSpeed <= 0 || w.Mission.TransitionalSpeed > limits.MaxSpeed {
+		fail(-1, "transitional_speed", "飞向首航点速度 %.1f 超出范围 (0, %.1f]", w.Mission.TransitionalSpeed, limits.MaxSpeed)
+	}
+	if h := w.Mission.TakeOffSecurityHeight; h < limits.MinTakeOff || h > limits.MaxTakeOff {
+		fail(-1, "take_off_security_height", "安全起飞高度 %.1f 超出范围 [%.1f, %.1f]", h, limits.MinTakeOff, limits.MaxTakeOff)
+	}
+	switch w.Mission.FinishAction {
+	case FinishGoHome, FinishNoAction, FinishAutoLand, FinishGotoFirstWaypoint:
+	default:
+		fail(-1, "finish_action", "不支持的结束动作 %q", w.Mission.FinishAction)
+	}
+
+	relative := w.HeightMode == HeightRelative
+	for i, wp := range w.Waypoints {
+		if err := wp.Point.Validate(); err != nil {
+			fail(i, "point", "%v", err)
+		}
+		if relative && (wp.Height < limits.MinHeight || wp.Height > limits.MaxHeight) {
+			fail(i, "height", "高度 %.1f 超出范围 [%.1f, %.1f]", wp.Height, limits.MinHeight, limits.MaxHeight)
+		}
+		if wp.Speed < 0 || wp.Speed > limits.MaxSpeed {
+			fail(i, "speed", "速度 %.1f 超出范围 [0, %.1f]", wp.Speed, limits.MaxSpeed)
+		}
+		if i > 0 && limits.MinSpacing > 0 {
+			prev := w.Waypoints[i-1]
+			spacing := math.Hypot(geo.Haversine(prev.Point, wp.Point), wp.Height-prev.Height)
+			if spacing < limits.MinSpacing {
+				fail(i, "point", "与上一航点间距 %.2f 米小于 %.1f 米", spacing, limits.MinSpacing)
+			}
+		}
+		for j, action := range wp.Actions {
+			if reason := validateAction(action, limits); reason != "" {
+				fail(i, fmt.Sprintf("actions[%d].%s", j, action.Func), "%s", reason)
+			}
+		}
+		for j, group := range wp.ActionGroups {
+			if group.EndIndex < i || group.EndIndex >= len(w.Waypoints) {
+				fail(i, fmt.Sprintf("action_groups[%d]", j), "结束航点 %d 超出范围 [%d, %d]", group.EndIndex, i, len(w.Waypoints)-1)
+			}
+			for k, action := range group.Actions {
+				if reason := validateAction(action, limits); reason != "" {
+					fail(i, fmt.Sprintf("action_groups[%d].actions[%d].%s", j, k, action.Func), "%s", reason)
+				}
+			}
+		}
+	}
+
+	est := w.Estimate(limits, home)
+	if budget := 100 - limits.ReservePercent; est.BatteryPercent > budget {
+		fail(-1, "battery", "预计耗电 %.1f%% 超过可用电量 %.0f%%（保留 %.0f%%）", est.BatteryPercent, budget, limits.ReservePercent)
+	}
+	if len(issues) > 0 {
+		return &ValidationError{Issues: issues, Estimate: est}
+	}
+	return nil
+}
+
+// validateAction 校验动作参数，返回不通过的原因
+func validateAction(a Action, limits Limits) string {
+	switch a.Func {
+	case ActionGimbalRotate:
+		if pitch := a.Float("gimbalPitchRotateAngle"); pitch < limits.GimbalPitchMin || pitch > limits.GimbalPitchMax {
+			return fmt.Sprintf("云台俯仰角 %.1f 超出范围 [%.0f, %.0f]", pitch, limits.GimbalPitchMin, limits.GimbalPitchMax)
+		}
+		if yaw := a.Float("gimbalYawRotateAngle"); yaw < -180 || yaw > 180 {
+			return fmt.Sprintf("云台偏航角 %.1f 超出范围 [-180, 180]", yaw)
+		}
+	case ActionHover:
+		if t := a.Float("hoverTime"); t <= 0 {
+			return fmt.Sprintf("悬停时长 %.1f 秒无效", t)
+		}
+	case ActionRotateYaw:
+		if heading := a.Float("aircraftHeading"); heading < -180 || heading > 180 {
+			return fmt.Sprintf("偏航角 %.1f 超出范围 [-180, 180]", heading)
+		}
+	case ActionZoom:
+		if focal := a.Float("focalLength"); focal <= 0 {
+			return fmt.Sprintf("焦距 %.1f 无效", focal)
+		}
+	}
+	return ""
+}
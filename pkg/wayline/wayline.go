@@ -0,0 +1,191 @@
+// Package wayline 大疆航线文件（KMZ/WPML）的构建、解析与校验
+// KMZ 为 zip 压缩包，wpmz/template.kml 保存航点模板，wpmz/waylines.wpml 保存飞行器执行的航线；
+// 本包以航点航线为模型，可编程构建航点与动作（拍照、云台转动、悬停等），按机型限制校验，
+// 并估算航程、时长与电量消耗；通过 GetWayLineInfo 下载的航线文件可解析后重新生成。
+package wayline
+
+import (
+	"fmt"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+// HeightMode 航点高度参考
+type HeightMode string
+
+const (
+	HeightRelative HeightMode = "relativeToStartPoint" // 相对起飞点高度
+	HeightEGM96    HeightMode = "EGM96"                // 海拔高度
+	HeightAGL      HeightMode = "aboveGroundLevel"     // 相对地面高度（仿地）
+)
+
+// FinishAction 航线结束动作
+type FinishAction string
+
+const (
+	FinishGoHome            FinishAction = "goHome"            // 返航
+	FinishNoAction          FinishAction = "noAction"          // 悬停
+	FinishAutoLand          FinishAction = "autoLand"          // 原地降落
+	FinishGotoFirstWaypoint FinishAction = "gotoFirstWaypoint" // 飞回第一个航点
+)
+
+// RCLostAction 遥控信号丢失后的动作
+type RCLostAction string
+
+const (
+	RCLostGoBack  RCLostAction = "goBack"  // 返航
+	RCLostLanding RCLostAction = "landing" // 降落
+	RCLostHover   RCLostAction = "hover"   // 悬停
+)
+
+// HeadingMode 航点间飞行器偏航角模式
+type HeadingMode string
+
+const (
+	HeadingFollowWayline HeadingMode = "followWayline"    // 沿航线方向
+	HeadingManually      HeadingMode = "manually"         // 手动控制
+	HeadingFixed         HeadingMode = "fixed"            // 锁定当前偏航角
+	HeadingSmooth        HeadingMode = "smoothTransition" // 按航点设置的偏航角平滑过渡
+)
+
+// TurnMode 航点转弯模式
+type TurnMode string
+
+const (
+	TurnStop           TurnMode = "toPointAndStopWithDiscontinuityCurvature" // 直线飞行，飞行器到点停
+	TurnCoordinated    TurnMode = "coordinateTurn"                           // 协调转弯，不过点，提前转弯
+	TurnPassContinuous TurnMode = "toPointAndPassWithContinuityCurvature"    // 曲线飞行，飞行器过点不停
+	TurnStopCurvature  TurnMode = "toPointAndStopWithContinuityCurvature"    // 曲线飞行，飞行器到点停
+)
+
+// stops 转弯模式是否在航点停下
+func (m TurnMode) stops() bool {
+	return m == "" || m == TurnStop || m == TurnStopCurvature
+}
+
+// Drone 飞行器型号，对应设备型号键 0-Type-SubType
+type Drone struct {
+	Type    int `json:"type"`
+	SubType int `json:"sub_type"`
+}
+
+// ModelKey 设备型号键，如 0-91-0
+func (d Drone) ModelKey() string {
+	return fmt.Sprintf("0-%d-%d", d.Type, d.SubType)
+}
+
+// Payload 负载型号，对应设备型号键 1-Type-SubType
+type Payload struct {
+	Type     int `json:"type"`
+	SubType  int `json:"sub_type"`
+	Position int `json:"position"` // 挂载位置
+}
+
+// ModelKey 设备型号键，如 1-81-0
+func (p Payload) ModelKey() string {
+	return fmt.Sprintf("1-%d-%d", p.Type, p.SubType)
+}
+
+// 常用机型
+var (
+	DroneM30    = Drone{Type: 67, SubType: 0}
+	DroneM30T   = Drone{Type: 67, SubType: 1}
+	DroneM3E    = Drone{Type: 77, SubType: 0}
+	DroneM3T    = Drone{Type: 77, SubType: 1}
+	DroneM350   = Drone{Type: 89, SubType: 0}
+	DroneM3D    = Drone{Type: 91, SubType: 0}
+	DroneM3TD   = Drone{Type: 91, SubType: 1}
+	DroneM4D    = Drone{Type: 100, SubType: 0}
+	DroneM4TD   = Drone{Type: 100, SubType: 1}
+	PayloadM3D  = Payload{Type: 80, SubType: 0}
+	PayloadM3TD = Payload{Type: 81, SubType: 0}
+)
+
+// Mission 航线全局设置（missionConfig）
+type Mission struct {
+	FlyToWaylineMode      string       `json:"fly_to_wayline_mode"`      // 飞向首航点模式：safely、pointToPoint
+	FinishAction          FinishAction `json:"finish_action"`            // 航线结束动作
+	ExitOnRCLost          string       `json:"exit_on_rc_lost"`          // 失控是否继续执行航线：goContinue、executeLostAction
+	RCLostAction          RCLostAction `json:"rc_lost_action"`           // 失控动作
+	TakeOffSecurityHeight float64      `json:"take_off_security_height"` // 安全起飞高度（米）
+	TransitionalSpeed     float64      `json:"transitional_speed"`       // 飞向首航点速度（米/秒）
+	Drone                 Drone        `json:"drone"`
+	Payload               Payload      `json:"payload"`
+}
+
+// Waypoint 航点
+type Waypoint struct {
+	Point        geo.Point     `json:"point"`
+	Height       float64       `json:"height"`                  // 高度（米），参考 Wayline.HeightMode
+	Speed        float64       `json:"speed,omitempty"`         // 飞向下一航点的速度（米/秒），0 表示使用航线速度
+	HeadingMode  HeadingMode   `json:"heading_mode,omitempty"`  // 为空时使用沿航线方向
+	HeadingAngle float64       `json:"heading_angle,omitempty"` // 偏航角（度），HeadingSmooth 时生效
+	TurnMode     TurnMode      `json:"turn_mode,omitempty"`     // 为空时到点停
+	Actions      []Action      `json:"actions,omitempty"`       // 到达航点后依次执行的动作
+	ActionGroups []ActionGroup `json:"action_groups,omitempty"` // 自该航点开始的其他动作组，如等时、等距拍照
+}
+
+// Wayline 航点航线
+type Wayline struct {
+	Author     string     `json:"author,omitempty"`
+	CreateTime time.Time  `json:"create_time"`
+	UpdateTime time.Time  `json:"update_time"`
+	Mission    Mission    `json:"mission"`
+	HeightMode HeightMode `json:"height_mode"`
+	Height     float64    `json:"height"` // 全局航线高度（米），新增航点的默认高度
+	Speed      float64    `json:"speed"`  // 全局航线速度（米/秒）
+	Waypoints  []Waypoint `json:"waypoints"`
+}
+
+// New 创建航线：相对起飞点高度，结束后返航，失控返航
+func New(drone Drone, payload Payload) *Wayline {
+	now := time.Now()
+	return &Wayline{
+		CreateTime: now,
+		UpdateTime: now,
+		Mission: Mission{
+			FlyToWaylineMode:      "safely",
+			FinishAction:          FinishGoHome,
+			ExitOnRCLost:          "executeLostAction",
+			RCLostAction:          RCLostGoBack,
+			TakeOffSecurityHeight: 20,
+			TransitionalSpeed:     10,
+			Drone:                 drone,
+			Payload:               payload,
+		},
+		HeightMode: HeightRelative,
+		Height:     100,
+		Speed:      10,
+	}
+}
+
+// AddWaypoint 按全局高度追加航点，返回新航点在 Waypoints 中的序号以便继续设置
+func (w *Wayline) AddWaypoint(p geo.Point, actions ...Action) int {
+	return w.AddWaypointAt(p, w.Height, actions...)
+}
+
+// AddWaypointAt 按指定高度追加航点，返回新航点的序号
+// 不返回指针：继续追加航点时 Waypoints 可能扩容，之前取得的指针不再指向航线中的航点
+func (w *Wayline) AddWaypointAt(p geo.Point, height float64, actions ...Action) int {
+	w.Waypoints = append(w.Waypoints, Waypoint{Point: p, Height: height, Actions: actions})
+	w.UpdateTime = time.Now()
+	return len(w.Waypoints) - 1
+}
+
+// Path 航点经纬度，用于电子围栏校验
+func (w *Wayline) Path() []geo.Point {
+	path := make([]geo.Point, len(w.Waypoints))
+	for i, wp := range w.Waypoints {
+		path[i] = wp.Point
+	}
+	return path
+}
+
+// speedOf 航点飞向下一航点的速度
+func (w *Wayline) speedOf(wp Waypoint) float64 {
+	if wp.Speed > 0 {
+		return wp.Speed
+	}
+	return w.Speed
+}
@@ -0,0 +1,580 @@
+package wayline
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+// 命名空间
+const (
+	kmlNamespace  = "http://www.opengis.net/kml/2.2"
+	wpmlNamespace = "http://www.dji.com/wpmz/1.0.2"
+)
+
+// xmlWriter 逐行输出带 wpml 前缀的元素，encoding/xml 无法按指定前缀输出命名空间
+type xmlWriter struct {
+	buf   bytes.Buffer
+	depth int
+}
+
+func (x *xmlWriter) indent() {
+	x.buf.WriteString(strings.Repeat("  ", x.depth))
+}
+
+func (x *xmlWriter) open(name string) {
+	x.indent()
+	fmt.Fprintf(&x.buf, "<%s>\n", name)
+	x.depth++
+}
+
+func (x *xmlWriter) close(name string) {
+	x.depth--
+	x.indent()
+	fmt.Fprintf(&x.buf, "</%s>\n", name)
+}
+
+func (x *xmlWriter) leaf(name, value string) {
+	x.indent()
+	fmt.Fprintf(&x.buf, "<%s>", name)
+	xml.EscapeText(&x.buf, []byte(value))
+	fmt.Fprintf(&x.buf, "</%s>\n", name)
+}
+
+func (x *xmlWriter) float(name string, value float64) {
+	x.leaf(name, formatFloat(value))
+}
+
+func (x *xmlWriter) int(name string, value int) {
+	x.leaf(name, strconv.Itoa(value))
+}
+
+// begin 输出文件头与 kml、Document 元素
+func (x *xmlWriter) begin() {
+	x.buf.WriteString(xml.Header)
+	fmt.Fprintf(&x.buf, "<kml xmlns=%q xmlns:wpml=%q>\n", kmlNamespace, wpmlNamespace)
+	x.depth = 1
+	x.open("Document")
+}
+
+func (x *xmlWriter) end() []byte {
+	x.close("Document")
+	x.buf.WriteString("</kml>\n")
+	return x.buf.Bytes()
+}
+
+// encodeTemplate 生成 template.kml
+func (w *Wayline) encodeTemplate() []byte {
+	x := &xmlWriter{}
+	x.begin()
+	x.leaf("wpml:author", w.Author)
+	x.leaf("wpml:createTime", strconv.FormatInt(w.CreateTime.UnixMilli(), 10))
+	x.leaf("wpml:updateTime", strconv.FormatInt(w.UpdateTime.UnixMilli(), 10))
+	w.encodeMission(x)
+	x.open("Folder")
+	x.leaf("wpml:templateType", "waypoint")
+	x.int("wpml:templateId", 0)
+	x.open("wpml:waylineCoordinateSysParam")
+	x.leaf("wpml:coordinateMode", "WGS84")
+	x.leaf("wpml:heightMode", string(w.heightMode()))
+	x.close("wpml:waylineCoordinateSysParam")
+	x.float("wpml:autoFlightSpeed", w.Speed)
+	x.float("wpml:globalHeight", w.Height)
+	x.int("wpml:caliFlightEnable", 0)
+	x.leaf("wpml:gimbalPitchMode", "usePointSetting")
+	x.open("wpml:globalWaypointHeadingParam")
+	x.leaf("wpml:waypointHeadingMode", string(HeadingFollowWayline))
+	x.float("wpml:waypointHeadingAngle", 0)
+	x.leaf("wpml:waypointPoiPoint", "0.000000,0.000000,0.000000")
+	x.leaf("wpml:waypointHeadingPathMode", "followBadArc")
+	x.close("wpml:globalWaypointHeadingParam")
+	x.leaf("wpml:globalWaypointTurnMode", string(TurnStop))
+	x.int("wpml:globalUseStraightLine", 1)
+	group := 0
+	for i, wp := range w.Waypoints {
+		x.open("Placemark")
+		encodePoint(x, wp.Point)
+		x.int("wpml:index", i)
+		x.float("wpml:ellipsoidHeight", wp.Height)
+		x.float("wpml:height", wp.Height)
+		x.int("wpml:useGlobalHeight", 0)
+		x.int("wpml:useGlobalSpeed", boolInt(wp.Speed <= 0))
+		x.float("wpml:waypointSpeed", w.speedOf(wp))
+		x.int("wpml:useGlobalHeadingParam", 0)
+		encodeHeading(x, wp)
+		x.int("wpml:useGlobalTurnParam", 0)
+		encodeTurn(x, wp)
+		x.int("wpml:useStraightLine", 1)
+		group = encodeActions(x, i, wp, group)
+		x.close("Placemark")
+	}
+	x.close("Folder")
+	return x.end()
+}
+
+// encodeWaylines 生成 waylines.wpml，航程与时长按机型限制估算
+// 海拔高度模式下执行高度未做大地水准面改正，建议上传后由司空2按模板重新生成执行文件
+func (w *Wayline) encodeWaylines() []byte {
+	est := w.Estimate(w.Limits(), nil)
+	x := &xmlWriter{}
+	x.begin()
+	w.encodeMission(x)
+	x.open("Folder")
+	x.int("wpml:templateId", 0)
+	x.leaf("wpml:executeHeightMode", executeHeightMode(w.heightMode()))
+	x.int("wpml:waylineId", 0)
+	x.float("wpml:distance", roundTo(est.Distance, 1))
+	x.float("wpml:duration", est.Duration.Seconds())
+	x.float("wpml:autoFlightSpeed", w.Speed)
+	group := 0
+	for i, wp := range w.Waypoints {
+		x.open("Placemark")
+		encodePoint(x, wp.Point)
+		x.int("wpml:index", i)
+		x.float("wpml:executeHeight", wp.Height)
+		x.float("wpml:waypointSpeed", w.speedOf(wp))
+		encodeHeading(x, wp)
+		encodeTurn(x, wp)
+		x.int("wpml:useStraightLine", 1)
+		group = encodeActions(x, i, wp, group)
+		x.close("Placemark")
+	}
+	x.close("Folder")
+	return x.end()
+}
+
+func (w *Wayline) encodeMission(x *xmlWriter) {
+	m := w.Mission
+	x.open("wpml:missionConfig")
+	x.leaf("wpml:flyToWaylineMode", m.FlyToWaylineMode)
+	x.leaf("wpml:finishAction", string(m.FinishAction))
+	x.leaf("wpml:exitOnRCLost", m.ExitOnRCLost)
+	x.leaf("wpml:executeRCLostAction", string(m.RCLostAction))
+	x.float("wpml:takeOffSecurityHeight", m.TakeOffSecurityHeight)
+	x.float("wpml:globalTransitionalSpeed", m.TransitionalSpeed)
+	x.open("wpml:droneInfo")
+	x.int("wpml:droneEnumValue", m.Drone.Type)
+	x.int("wpml:droneSubEnumValue", m.Drone.SubType)
+	x.close("wpml:droneInfo")
+	x.open("wpml:payloadInfo")
+	x.int("wpml:payloadEnumValue", m.Payload.Type)
+	x.int("wpml:payloadSubEnumValue", m.Payload.SubType)
+	x.int("wpml:payloadPositionIndex", m.Payload.Position)
+	x.close("wpml:payloadInfo")
+	x.close("wpml:missionConfig")
+}
+
+func encodePoint(x *xmlWriter, p geo.Point) {
+	x.open("Point")
+	x.leaf("coordinates", formatFloat(p.Lng)+","+formatFloat(p.Lat))
+	x.close("Point")
+}
+
+func encodeHeading(x *xmlWriter, wp Waypoint) {
+	mode := wp.HeadingMode
+	if mode == "" {
+		mode = HeadingFollowWayline
+	}
+	x.open("wpml:waypointHeadingParam")
+	x.leaf("wpml:waypointHeadingMode", string(mode))
+	x.float("wpml:waypointHeadingAngle", wp.HeadingAngle)
+	x.leaf("wpml:waypointPoiPoint", "0.000000,0.000000,0.000000")
+	x.int("wpml:waypointHeadingAngleEnable", boolInt(mode == HeadingSmooth))
+	x.leaf("wpml:waypointHeadingPathMode", "followBadArc")
+	x.close("wpml:waypointHeadingParam")
+}
+
+func encodeTurn(x *xmlWriter, wp Waypoint) {
+	mode := wp.TurnMode
+	if mode == "" {
+		mode = TurnStop
+	}
+	x.open("wpml:waypointTurnParam")
+	x.leaf("wpml:waypointTurnMode", string(mode))
+	x.float("wpml:waypointTurnDampingDist", 0)
+	x.close("wpml:waypointTurnParam")
+}
+
+// encodeActions 输出航点的到点动作组与自该航点开始的其他动作组，返回下一个动作组编号
+func encodeActions(x *xmlWriter, index int, wp Waypoint, group int) int {
+	if len(wp.Actions) > 0 {
+		group = encodeActionGroup(x, index, ActionGroup{EndIndex: index, Actions: wp.Actions}, group)
+	}
+	for _, g := range wp.ActionGroups {
+		group = encodeActionGroup(x, index, g, group)
+	}
+	return group
+}
+
+func encodeActionGroup(x *xmlWriter, index int, g ActionGroup, group int) int {
+	mode, trigger := g.Mode, g.Trigger
+	if mode == "" {
+		mode = "sequence"
+	}
+	if trigger == "" {
+		trigger = TriggerReachPoint
+	}
+	x.open("wpml:actionGroup")
+	x.int("wpml:actionGroupId", group)
+	x.int("wpml:actionGroupStartIndex", index)
+	x.int("wpml:actionGroupEndIndex", g.EndIndex)
+	x.leaf("wpml:actionGroupMode", mode)
+	x.open("wpml:actionTrigger")
+	x.leaf("wpml:actionTriggerType", string(trigger))
+	if g.TriggerParam != "" {
+		x.leaf("wpml:actionTriggerParam", g.TriggerParam)
+	}
+	x.close("wpml:actionTrigger")
+	for i, action := range g.Actions {
+		x.open("wpml:action")
+		x.int("wpml:actionId", i)
+		x.leaf("wpml:actionActuatorFunc", string(action.Func))
+		x.open("wpml:actionActuatorFuncParam")
+		for _, p := range action.Params {
+			x.leaf("wpml:"+p.Name, p.Value)
+		}
+		x.close("wpml:actionActuatorFuncParam")
+		encodeElements(x, action.Extra)
+		x.close("wpml:action")
+	}
+	encodeElements(x, g.Extra)
+	x.close("wpml:actionGroup")
+	return group + 1
+}
+
+// encodeElements 原样输出解析时保留的未建模元素
+func encodeElements(x *xmlWriter, elements []Element) {
+	for _, e := range elements {
+		if len(e.Children) == 0 {
+			x.leaf("wpml:"+e.Name, e.Value)
+			continue
+		}
+		x.open("wpml:" + e.Name)
+		encodeElements(x, e.Children)
+		x.close("wpml:" + e.Name)
+	}
+}
+
+func (w *Wayline) heightMode() HeightMode {
+	if w.HeightMode == "" {
+		return HeightRelative
+	}
+	return w.HeightMode
+}
+
+// executeHeightMode 模板高度模式对应的执行高度模式
+func executeHeightMode(mode HeightMode) string {
+	switch mode {
+	case HeightEGM96:
+		return "WGS84"
+	case HeightAGL:
+		return "realTimeFollowSurface"
+	}
+	return string(HeightRelative)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func roundTo(f float64, digits int) float64 {
+	v, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'f', digits, 64), 64)
+	return v
+}
+
+/**  解析  **/
+
+// 解析结构只按元素本地名匹配，kml 与 wpml 命名空间的各版本均可解析
+
+type kmlFile struct {
+	Document struct {
+		Author     string      `xml:"author"`
+		CreateTime int64       `xml:"createTime"`
+		UpdateTime int64       `xml:"updateTime"`
+		Mission    kmlMission  `xml:"missionConfig"`
+		Folders    []kmlFolder `xml:"Folder"`
+	} `xml:"Document"`
+}
+
+type kmlMission struct {
+	FlyToWaylineMode      string  `xml:"flyToWaylineMode"`
+	FinishAction          string  `xml:"finishAction"`
+	ExitOnRCLost          string  `xml:"exitOnRCLost"`
+	RCLostAction          string  `xml:"executeRCLostAction"`
+	TakeOffSecurityHeight float64 `xml:"takeOffSecurityHeight"`
+	TransitionalSpeed     float64 `xml:"globalTransitionalSpeed"`
+	DroneType             int     `xml:"droneInfo>droneEnumValue"`
+	DroneSubType          int     `xml:"droneInfo>droneSubEnumValue"`
+	PayloadType           int     `xml:"payloadInfo>payloadEnumValue"`
+	PayloadSubType        int     `xml:"payloadInfo>payloadSubEnumValue"`
+	PayloadPosition       int     `xml:"payloadInfo>payloadPositionIndex"`
+}
+
+type kmlFolder struct {
+	TemplateType      string         `xml:"templateType"`
+	HeightMode        string         `xml:"waylineCoordinateSysParam>heightMode"`
+	ExecuteHeightMode string         `xml:"executeHeightMode"`
+	AutoFlightSpeed   float64        `xml:"autoFlightSpeed"`
+	GlobalHeight      float64        `xml:"globalHeight"`
+	GlobalHeadingMode string         `xml:"globalWaypointHeadingParam>waypointHeadingMode"`
+	GlobalTurnMode    string         `xml:"globalWaypointTurnMode"`
+	Placemarks        []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Coordinates           string           `xml:"Point>coordinates"`
+	Index                 int              `xml:"index"`
+	Height                *float64         `xml:"height"`
+	ExecuteHeight         *float64         `xml:"executeHeight"`
+	UseGlobalHeight       int              `xml:"useGlobalHeight"`
+	UseGlobalSpeed        int              `xml:"useGlobalSpeed"`
+	WaypointSpeed         float64          `xml:"waypointSpeed"`
+	UseGlobalHeadingParam int              `xml:"useGlobalHeadingParam"`
+	HeadingMode           string           `xml:"waypointHeadingParam>waypointHeadingMode"`
+	HeadingAngle          float64          `xml:"waypointHeadingParam>waypointHeadingAngle"`
+	UseGlobalTurnParam    int              `xml:"useGlobalTurnParam"`
+	TurnMode              string           `xml:"waypointTurnParam>waypointTurnMode"`
+	ActionGroups          []kmlActionGroup `xml:"actionGroup"`
+}
+
+// kmlActionGroup 动作组，编号写回时重新生成，其余未建模的元素收集在 Extra
+type kmlActionGroup struct {
+	ID           int          `xml:"actionGroupId"`
+	StartIndex   int          `xml:"actionGroupStartIndex"`
+	EndIndex     *int         `xml:"actionGroupEndIndex"`
+	Mode         string       `xml:"actionGroupMode"`
+	TriggerType  string       `xml:"actionTrigger>actionTriggerType"`
+	TriggerParam string       `xml:"actionTrigger>actionTriggerParam"`
+	Actions      []kmlAction  `xml:"action"`
+	Extra        []kmlElement `xml:",any"`
+}
+
+type kmlAction struct {
+	ID     int    `xml:"actionId"`
+	Func   string `xml:"actionActuatorFunc"`
+	Params struct {
+		Items []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"actionActuatorFuncParam"`
+	Extra []kmlElement `xml:",any"`
+}
+
+// kmlElement 未建模的元素
+type kmlElement struct {
+	XMLName  xml.Name
+	Value    string       `xml:",chardata"`
+	Children []kmlElement `xml:",any"`
+}
+
+func toElements(items []kmlElement) []Element {
+	var elements []Element
+	for _, item := range items {
+		e := Element{Name: item.XMLName.Local, Children: toElements(item.Children)}
+		if len(e.Children) == 0 {
+			e.Value = strings.TrimSpace(item.Value)
+		}
+		elements = append(elements, e)
+	}
+	return elements
+}
+
+// plain 只在起始航点到点顺序执行、没有未建模元素的动作组，归入航点的 Actions
+func (g kmlActionGroup) plain() bool {
+	return (g.EndIndex == nil || *g.EndIndex == g.StartIndex) &&
+		(g.Mode == "" || g.Mode == "sequence") &&
+		(g.TriggerType == "" || g.TriggerType == string(TriggerReachPoint)) &&
+		g.TriggerParam == "" && len(g.Extra) == 0
+}
+
+// parseKML 解析 template.kml 或 waylines.wpml
+func parseKML(data []byte) (*kmlFile, error) {
+	var file kmlFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析航线文件失败: %w", err)
+	}
+	return &file, nil
+}
+
+// toWayline 由模板与执行文件组装航线：全局设置优先取模板，航点优先取模板中的航点航线，
+// 模板为建图、面状等非航点类型时取执行文件中的航点与执行高度；动作组归入起始航点，
+// 到点顺序执行的动作并入 Actions，跨航点、等时或等距触发以及带有未建模元素的动作组保留在 ActionGroups
+func toWayline(template, waylines *kmlFile) (*Wayline, error) {
+	doc := waylines
+	if template != nil {
+		doc = template
+	}
+	m := doc.Document.Mission
+	if m.FinishAction == "" && waylines != nil {
+		m = waylines.Document.Mission
+	}
+	w := &Wayline{
+		Author:     doc.Document.Author,
+		CreateTime: unixMilli(doc.Document.CreateTime),
+		UpdateTime: unixMilli(doc.Document.UpdateTime),
+		Mission: Mission{
+			FlyToWaylineMode:      m.FlyToWaylineMode,
+			FinishAction:          FinishAction(m.FinishAction),
+			ExitOnRCLost:          m.ExitOnRCLost,
+			RCLostAction:          RCLostAction(m.RCLostAction),
+			TakeOffSecurityHeight: m.TakeOffSecurityHeight,
+			TransitionalSpeed:     m.TransitionalSpeed,
+			Drone:                 Drone{Type: m.DroneType, SubType: m.DroneSubType},
+			Payload:               Payload{Type: m.PayloadType, SubType: m.PayloadSubType, Position: m.PayloadPosition},
+		},
+		HeightMode: HeightRelative,
+	}
+
+	folder := waypointFolder(template)
+	fromTemplate := folder != nil
+	if folder == nil {
+		folder = waypointFolder(waylines)
+	}
+	if folder == nil {
+		return nil, fmt.Errorf("航线文件不包含航点")
+	}
+	if !fromTemplate {
+		w.HeightMode = templateHeightMode(folder.ExecuteHeightMode)
+	} else if folder.HeightMode != "" {
+		w.HeightMode = HeightMode(folder.HeightMode)
+	}
+	w.Speed = folder.AutoFlightSpeed
+	w.Height = folder.GlobalHeight
+
+	for _, pm := range folder.Placemarks {
+		point, err := parseCoordinates(pm.Coordinates)
+		if err != nil {
+			return nil, fmt.Errorf("航点%d: %w", pm.Index, err)
+		}
+		wp := Waypoint{Point: point, HeadingMode: HeadingMode(pm.HeadingMode), HeadingAngle: pm.HeadingAngle, TurnMode: TurnMode(pm.TurnMode)}
+		switch {
+		case fromTemplate && pm.UseGlobalHeight == 1:
+			wp.Height = folder.GlobalHeight
+		case pm.Height != nil && fromTemplate:
+			wp.Height = *pm.Height
+		case pm.ExecuteHeight != nil:
+			wp.Height = *pm.ExecuteHeight
+		case pm.Height != nil:
+			wp.Height = *pm.Height
+		}
+		if !(fromTemplate && pm.UseGlobalSpeed == 1) && pm.WaypointSpeed != w.Speed {
+			wp.Speed = pm.WaypointSpeed
+		}
+		if fromTemplate && pm.UseGlobalHeadingParam == 1 {
+			wp.HeadingMode = HeadingMode(folder.GlobalHeadingMode)
+		}
+		if fromTemplate && pm.UseGlobalTurnParam == 1 {
+			wp.TurnMode = TurnMode(folder.GlobalTurnMode)
+		}
+		// 默认值保持为空，与构建的航线一致
+		if wp.HeadingMode == HeadingFollowWayline {
+			wp.HeadingMode = ""
+		}
+		if wp.TurnMode == TurnStop {
+			wp.TurnMode = ""
+		}
+		w.Waypoints = append(w.Waypoints, wp)
+	}
+	for _, pm := range folder.Placemarks {
+		for _, group := range pm.ActionGroups {
+			if group.StartIndex < 0 || group.StartIndex >= len(w.Waypoints) {
+				continue
+			}
+			wp := &w.Waypoints[group.StartIndex]
+			var actions []Action
+			for _, a := range group.Actions {
+				action := Action{Func: ActionFunc(a.Func), Extra: toElements(a.Extra)}
+				for _, item := range a.Params.Items {
+					action.Params = append(action.Params, Param{Name: item.XMLName.Local, Value: strings.TrimSpace(item.Value)})
+				}
+				actions = append(actions, action)
+			}
+			if group.plain() {
+				wp.Actions = append(wp.Actions, actions...)
+				continue
+			}
+			g := ActionGroup{EndIndex: group.StartIndex, Mode: group.Mode, Trigger: TriggerType(group.TriggerType), TriggerParam: strings.TrimSpace(group.TriggerParam), Actions: actions, Extra: toElements(group.Extra)}
+			if group.EndIndex != nil {
+				g.EndIndex = *group.EndIndex
+			}
+			wp.ActionGroups = append(wp.ActionGroups, g)
+		}
+	}
+	if w.Height == 0 && len(w.Waypoints) > 0 {
+		w.Height = w.Waypoints[0].Height
+	}
+	return w, nil
+}
+
+// waypointFolder 第一个包含航点的 Folder，模板仅接受航点类型
+func waypointFolder(file *kmlFile) *kmlFolder {
+	if file == nil {
+		return nil
+	}
+	for i := range file.Document.Folders {
+		folder := &file.Document.Folders[i]
+		if folder.TemplateType != "" && folder.TemplateType != "waypoint" {
+			continue
+		}
+		if len(folder.Placemarks) > 0 {
+			return folder
+		}
+	}
+	return nil
+}
+
+// executable 是否为执行文件 waylines.wpml
+func (f *kmlFile) executable() bool {
+	for _, folder := range f.Document.Folders {
+		if folder.ExecuteHeightMode != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// unixMilli 毫秒时间戳，0 表示未记录
+func unixMilli(ms int64) time.Time {
+	if ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// templateHeightMode 执行高度模式对应的模板高度模式
+func templateHeightMode(mode string) HeightMode {
+	switch mode {
+	case "WGS84":
+		return HeightEGM96
+	case "realTimeFollowSurface":
+		return HeightAGL
+	}
+	return HeightRelative
+}
+
+// parseCoordinates 解析 "经度,纬度[,高度]"
+func parseCoordinates(s string) (geo.Point, error) {
+	parts := strings.Split(strings.TrimSpace(s), ",")
+	if len(parts) < 2 {
+		return geo.Point{}, fmt.Errorf("坐标格式错误: %q", s)
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return geo.Point{}, fmt.Errorf("经度格式错误: %q", parts[0])
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return geo.Point{}, fmt.Errorf("纬度格式错误: %q", parts[1])
+	}
+	p := geo.Point{Lat: lat, Lng: lng}
+	return p, p.Validate()
+}
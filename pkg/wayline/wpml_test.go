@@ -0,0 +1,154 @@
+package wayline
+
+import (
+	"reflect"
+	"testing"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+// roundTripTemplate 含到点、跨航点等时与等距触发以及未建模元素的动作组
+const roundTripTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:wpml="http://www.dji.com/wpmz/1.0.6">
+  <Document>
+    <wpml:missionConfig>
+      <wpml:flyToWaylineMode>safely</wpml:flyToWaylineMode>
+      <wpml:finishAction>goHome</wpml:finishAction>
+      <wpml:exitOnRCLost>executeLostAction</wpml:exitOnRCLost>
+      <wpml:executeRCLostAction>goBack</wpml:executeRCLostAction>
+      <wpml:takeOffSecurityHeight>20</wpml:takeOffSecurityHeight>
+      <wpml:globalTransitionalSpeed>10</wpml:globalTransitionalSpeed>
+      <wpml:droneInfo><wpml:droneEnumValue>91</wpml:droneEnumValue><wpml:droneSubEnumValue>0</wpml:droneSubEnumValue></wpml:droneInfo>
+      <wpml:payloadInfo><wpml:payloadEnumValue>80</wpml:payloadEnumValue><wpml:payloadSubEnumValue>0</wpml:payloadSubEnumValue><wpml:payloadPositionIndex>0</wpml:payloadPositionIndex></wpml:payloadInfo>
+    </wpml:missionConfig>
+    <Folder>
+      <wpml:templateType>waypoint</wpml:templateType>
+      <wpml:waylineCoordinateSysParam><wpml:heightMode>relativeToStartPoint</wpml:heightMode></wpml:waylineCoordinateSysParam>
+      <wpml:autoFlightSpeed>10</wpml:autoFlightSpeed>
+      <wpml:globalHeight>100</wpml:globalHeight>
+      <Placemark>
+        <Point><coordinates>113.9344,22.5431</coordinates></Point>
+        <wpml:index>0</wpml:index>
+        <wpml:height>100</wpml:height>
+        <wpml:actionGroup>
+          <wpml:actionGroupId>0</wpml:actionGroupId>
+          <wpml:actionGroupStartIndex>0</wpml:actionGroupStartIndex>
+          <wpml:actionGroupEndIndex>0</wpml:actionGroupEndIndex>
+          <wpml:actionGroupMode>sequence</wpml:actionGroupMode>
+          <wpml:actionTrigger><wpml:actionTriggerType>reachPoint</wpml:actionTriggerType></wpml:actionTrigger>
+          <wpml:action>
+            <wpml:actionId>0</wpml:actionId>
+            <wpml:actionActuatorFunc>gimbalRotate</wpml:actionActuatorFunc>
+            <wpml:actionActuatorFuncParam><wpml:gimbalPitchRotateAngle>-90</wpml:gimbalPitchRotateAngle></wpml:actionActuatorFuncParam>
+            <wpml:actionFutureFlag>1</wpml:actionFutureFlag>
+          </wpml:action>
+        </wpml:actionGroup>
+        <wpml:actionGroup>
+          <wpml:actionGroupId>1</wpml:actionGroupId>
+          <wpml:actionGroupStartIndex>0</wpml:actionGroupStartIndex>
+          <wpml:actionGroupEndIndex>2</wpml:actionGroupEndIndex>
+          <wpml:actionGroupMode>sequence</wpml:actionGroupMode>
+          <wpml:actionTrigger>
+            <wpml:actionTriggerType>multipleTiming</wpml:actionTriggerType>
+            <wpml:actionTriggerParam>3</wpml:actionTriggerParam>
+          </wpml:actionTrigger>
+          <wpml:action>
+            <wpml:actionId>0</wpml:actionId>
+            <wpml:actionActuatorFunc>takePhoto</wpml:actionActuatorFunc>
+            <wpml:actionActuatorFuncParam><wpml:payloadPositionIndex>0</wpml:payloadPositionIndex></wpml:actionActuatorFuncParam>
+          </wpml:action>
+        </wpml:actionGroup>
+      </Placemark>
+      <Placemark>
+        <Point><coordinates>113.9354,22.5441</coordinates></Point>
+        <wpml:index>1</wpml:index>
+        <wpml:height>100</wpml:height>
+        <wpml:actionGroup>
+          <wpml:actionGroupId>2</wpml:actionGroupId>
+          <wpml:actionGroupStartIndex>1</wpml:actionGroupStartIndex>
+          <wpml:actionGroupEndIndex>1</wpml:actionGroupEndIndex>
+          <wpml:actionGroupMode>sequence</wpml:actionGroupMode>
+          <wpml:actionTrigger><wpml:actionTriggerType>reachPoint</wpml:actionTriggerType></wpml:actionTrigger>
+          <wpml:action>
+            <wpml:actionId>0</wpml:actionId>
+            <wpml:actionActuatorFunc>takePhoto</wpml:actionActuatorFunc>
+            <wpml:actionActuatorFuncParam><wpml:payloadPositionIndex>0</wpml:payloadPositionIndex></wpml:actionActuatorFuncParam>
+          </wpml:action>
+          <wpml:actionGroupFuture><wpml:value>7</wpml:value></wpml:actionGroupFuture>
+        </wpml:actionGroup>
+      </Placemark>
+      <Placemark>
+        <Point><coordinates>113.9364,22.5431</coordinates></Point>
+        <wpml:index>2</wpml:index>
+        <wpml:height>100</wpml:height>
+        <wpml:actionGroup>
+          <wpml:actionGroupId>3</wpml:actionGroupId>
+          <wpml:actionGroupStartIndex>2</wpml:actionGroupStartIndex>
+          <wpml:actionGroupEndIndex>2</wpml:actionGroupEndIndex>
+          <wpml:actionGroupMode>sequence</wpml:actionGroupMode>
+          <wpml:actionTrigger>
+            <wpml:actionTriggerType>multipleDistance</wpml:actionTriggerType>
+            <wpml:actionTriggerParam>10</wpml:actionTriggerParam>
+          </wpml:actionTrigger>
+          <wpml:action>
+            <wpml:actionId>0</wpml:actionId>
+            <wpml:actionActuatorFunc>takePhoto</wpml:actionActuatorFunc>
+            <wpml:actionActuatorFuncParam><wpml:payloadPositionIndex>0</wpml:payloadPositionIndex></wpml:actionActuatorFuncParam>
+          </wpml:action>
+        </wpml:actionGroup>
+      </Placemark>
+    </Folder>
+  </Document>
+</kml>`
+
+// TestActionGroupRoundTrip 解析、写回再解析后动作组的结束航点、触发方式与未建模元素保持不变
+func TestActionGroupRoundTrip(t *testing.T) {
+	parsed, err := Parse([]byte(roundTripTemplate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := parsed.Waypoints[0]
+	if len(first.Actions) != 1 || !reflect.DeepEqual(first.Actions[0].Extra, []Element{{Name: "actionFutureFlag", Value: "1"}}) {
+		t.Errorf("航点0的到点动作 = %+v", first.Actions)
+	}
+	if len(first.ActionGroups) != 1 {
+		t.Fatalf("航点0的动作组 = %+v", first.ActionGroups)
+	}
+	if g := first.ActionGroups[0]; g.EndIndex != 2 || g.Trigger != TriggerMultipleTiming || g.TriggerParam != "3" || len(g.Actions) != 1 {
+		t.Errorf("等时动作组 = %+v", g)
+	}
+	if groups := parsed.Waypoints[1].ActionGroups; len(groups) != 1 || !reflect.DeepEqual(groups[0].Extra, []Element{{Name: "actionGroupFuture", Children: []Element{{Name: "value", Value: "7"}}}}) {
+		t.Errorf("航点1的动作组 = %+v", groups)
+	}
+	if groups := parsed.Waypoints[2].ActionGroups; len(groups) != 1 || groups[0].Trigger != TriggerMultipleDistance || groups[0].TriggerParam != "10" {
+		t.Errorf("航点2的动作组 = %+v", groups)
+	}
+
+	kmz, err := parsed.KMZ()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reparsed, err := Parse(kmz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range parsed.Waypoints {
+		want, got := parsed.Waypoints[i], reparsed.Waypoints[i]
+		if !reflect.DeepEqual(want.Actions, got.Actions) || !reflect.DeepEqual(want.ActionGroups, got.ActionGroups) {
+			t.Errorf("航点%d写回后不一致:\n%+v\n%+v", i, want, got)
+		}
+	}
+}
+
+// TestAddWaypointIndex 追加航点返回序号，继续追加后仍可按序号修改航点
+func TestAddWaypointIndex(t *testing.T) {
+	w := New(DroneM3D, PayloadM3D)
+	first := w.AddWaypoint(geo.Point{Lat: 22.5431, Lng: 113.9344})
+	for i := 0; i < 8; i++ {
+		w.AddWaypointAt(geo.Point{Lat: 22.5431 + float64(i+1)*0.001, Lng: 113.9344}, 120)
+	}
+	w.Waypoints[first].Speed = 5
+	if first != 0 || w.Waypoints[0].Speed != 5 {
+		t.Errorf("航点序号 %d，速度 %.1f", first, w.Waypoints[0].Speed)
+	}
+}
@@ -0,0 +1,309 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+)
+
+// DefaultAmapURL 高德Web服务API地址
+const DefaultAmapURL = "https://restapi.amap.com"
+
+// amapZone 高德天气的时间均为北京时间
+var amapZone = time.FixedZone("CST", 8*3600)
+
+// beaufortMax 蒲福风级对应的风速上限（米/秒），高德天气只提供风力等级
+var beaufortMax = []float64{0.2, 1.5, 3.3, 5.4, 7.9, 10.7, 13.8, 17.1, 20.7, 24.4, 28.4, 32.6, 36.9}
+
+// AmapProvider 高德天气：按任务区域逆地理编码得到区县编码，当前时段使用实况天气，之后的时段使用4天预报（白天 08:00~20:00、夜间 20:00~次日08:00）
+type AmapProvider struct {
+	key     string
+	baseURL string
+	client  *httpclient.SecureHTTPClient
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	adcodes map[string]string        // 位置（保留两位小数）-> 区县编码
+	cache   map[string]amapCacheItem // 区县编码|extensions -> 响应
+}
+
+type amapCacheItem struct {
+	body []byte
+	at   time.Time
+}
+
+// NewAmapProvider 创建高德天气来源，key 为配置文件中的 AmapKey，baseURL 为空时使用 DefaultAmapURL
+// 天气响应缓存10分钟，避免批量下发时重复调用
+func NewAmapProvider(key, baseURL string, client *httpclient.SecureHTTPClient) *AmapProvider {
+	if baseURL == "" {
+		baseURL = DefaultAmapURL
+	}
+	return &AmapProvider{
+		key:     key,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  client,
+		ttl:     10 * time.Minute,
+		adcodes: make(map[string]string),
+		cache:   make(map[string]amapCacheItem),
+	}
+}
+
+// Name 来源名称
+func (a *AmapProvider) Name() string {
+	return "amap"
+}
+
+// Forecast 查询任务区域在执行时段内的天气
+func (a *AmapProvider) Forecast(ctx context.Context, q Query) ([]Conditions, error) {
+	if a.key == "" {
+		return nil, fmt.Errorf("未配置高德Key: %w", ErrNoData)
+	}
+	if err := q.Location.Validate(); err != nil {
+		return nil, fmt.Errorf("任务区域无效: %w", err)
+	}
+	if geo.OutOfChina(q.Location) {
+		return nil, fmt.Errorf("高德天气不支持中国境外区域: %w", ErrNoData)
+	}
+	adcode, err := a.adcode(ctx, q.Location)
+	if err != nil {
+		return nil, err
+	}
+	start, end := q.window()
+
+	var list []Conditions
+	// 时段在1小时内开始时使用实况天气
+	if time.Until(start) <= time.Hour {
+		live, err := a.live(ctx, adcode)
+		if err != nil {
+			return nil, err
+		}
+		liveEnd := start.Add(time.Hour)
+		if end.Before(liveEnd) {
+			liveEnd = end
+		}
+		live.Start, live.End = start, liveEnd
+		list = append(list, live)
+	}
+	casts, err := a.forecast(ctx, adcode)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range casts {
+		if c.End.After(start) && !c.Start.After(end) {
+			list = append(list, c)
+		}
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("高德天气预报不覆盖 %s~%s: %w", start.Format(time.DateTime), end.Format(time.DateTime), ErrNoData)
+	}
+	return list, nil
+}
+
+// adcode 逆地理编码获取区县编码，高德使用 GCJ-02 坐标
+func (a *AmapProvider) adcode(ctx context.Context, p geo.Point) (string, error) {
+	key := fmt.Sprintf("%.2f,%.2f", p.Lng, p.Lat)
+	a.mu.Lock()
+	code, ok := a.adcodes[key]
+	a.mu.Unlock()
+	if ok {
+		return code, nil
+	}
+	gcj := geo.WGS84ToGCJ02(p)
+	var body struct {
+		Regeocode struct {
+			AddressComponent struct {
+				Adcode json.RawMessage `json:"adcode"`
+			} `json:"addressComponent"`
+		} `json:"regeocode"`
+	}
+	params := url.Values{"location": {fmt.Sprintf("%.6f,%.6f", gcj.Lng, gcj.Lat)}}
+	if err := a.get(ctx, "/v3/geocode/regeo", params, &body); err != nil {
+		return "", err
+	}
+	// 海域等无行政区划的位置返回空数组
+	if err := json.Unmarshal(body.Regeocode.AddressComponent.Adcode, &code); err != nil || code == "" {
+		return "", fmt.Errorf("位置 %s 没有对应的行政区划: %w", key, ErrNoData)
+	}
+	a.mu.Lock()
+	a.adcodes[key] = code
+	a.mu.Unlock()
+	return code, nil
+}
+
+// live 实况天气
+func (a *AmapProvider) live(ctx context.Context, adcode string) (Conditions, error) {
+	var body struct {
+		Lives []struct {
+			Weather     string `json:"weather"`
+			Temperature string `json:"temperature"`
+			WindPower   string `json:"windpower"`
+		} `json:"lives"`
+	}
+	if err := a.weather(ctx, adcode, "base", &body); err != nil {
+		return Conditions{}, err
+	}
+	if len(body.Lives) == 0 {
+		return Conditions{}, fmt.Errorf("高德未返回区县 %s 的实况天气: %w", adcode, ErrNoData)
+	}
+	live := body.Lives[0]
+	return Conditions{
+		WindSpeed:   windSpeedOf(live.WindPower),
+		Rainfall:    rainfallOf(live.Weather),
+		Temperature: parseFloat(live.Temperature),
+		Description: live.Weather,
+		Source:      a.Name(),
+	}, nil
+}
+
+// forecast 未来4天的白天、夜间预报
+func (a *AmapProvider) forecast(ctx context.Context, adcode string) ([]Conditions, error) {
+	var body struct {
+		Forecasts []struct {
+			Casts []struct {
+				Date         string `json:"date"`
+				DayWeather   string `json:"dayweather"`
+				NightWeather string `json:"nightweather"`
+				DayTemp      string `json:"daytemp"`
+				NightTemp    string `json:"nighttemp"`
+				DayPower     string `json:"daypower"`
+				NightPower   string `json:"nightpower"`
+			} `json:"casts"`
+		} `json:"forecasts"`
+	}
+	if err := a.weather(ctx, adcode, "all", &body); err != nil {
+		return nil, err
+	}
+	var list []Conditions
+	for _, f := range body.Forecasts {
+		for _, cast := range f.Casts {
+			day, err := time.ParseInLocation(time.DateOnly, cast.Date, amapZone)
+			if err != nil {
+				continue
+			}
+			list = append(list,
+				Conditions{
+					Start: day.Add(8 * time.Hour), End: day.Add(20 * time.Hour),
+					WindSpeed: windSpeedOf(cast.DayPower), Rainfall: rainfallOf(cast.DayWeather),
+					Temperature: parseFloat(cast.DayTemp), Description: cast.DayWeather, Source: a.Name(),
+				},
+				Conditions{
+					Start: day.Add(20 * time.Hour), End: day.Add(32 * time.Hour),
+					WindSpeed: windSpeedOf(cast.NightPower), Rainfall: rainfallOf(cast.NightWeather),
+					Temperature: parseFloat(cast.NightTemp), Description: cast.NightWeather, Source: a.Name(),
+				})
+		}
+	}
+	// 当天凌晨 00:00~08:00 按当天夜间预报处理
+	if len(list) > 0 {
+		night := list[1]
+		night.Start, night.End = list[0].Start.Add(-8*time.Hour), list[0].Start
+		list = append([]Conditions{night}, list...)
+	}
+	return list, nil
+}
+
+// weather 查询天气，带缓存
+func (a *AmapProvider) weather(ctx context.Context, adcode, extensions string, out interface{}) error {
+	cacheKey := adcode + "|" + extensions
+	a.mu.Lock()
+	item, ok := a.cache[cacheKey]
+	a.mu.Unlock()
+	if ok && time.Since(item.at) < a.ttl {
+		return json.Unmarshal(item.body, out)
+	}
+	var raw json.RawMessage
+	params := url.Values{"city": {adcode}, "extensions": {extensions}}
+	if err := a.get(ctx, "/v3/weather/weatherInfo", params, &raw); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.cache[cacheKey] = amapCacheItem{body: raw, at: time.Now()}
+	a.mu.Unlock()
+	return json.Unmarshal(raw, out)
+}
+
+// get 调用高德Web服务API，status 不为1时返回 info 中的错误原因
+func (a *AmapProvider) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	params.Set("key", a.key)
+	params.Set("output", "JSON")
+	resp, err := a.client.DoRequest(ctx, http.MethodGet, a.baseURL+path+"?"+params.Encode(), nil, nil)
+	if err != nil {
+		return fmt.Errorf("请求高德API失败: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取高德API响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("高德API返回HTTP %d", resp.StatusCode)
+	}
+	var status struct {
+		Status string `json:"status"`
+		Info   string `json:"info"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return fmt.Errorf("解析高德API响应失败: %w", err)
+	}
+	if status.Status != "1" {
+		return fmt.Errorf("高德API错误: %s", status.Info)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("解析高德API响应失败: %w", err)
+	}
+	return nil
+}
+
+var digits = regexp.MustCompile(`\d+`)
+
+// windSpeedOf 风力等级转换为风速上限，例如 "≤3"、"4"、"4-5"，取最大等级
+func windSpeedOf(power string) *float64 {
+	level := -1
+	for _, s := range digits.FindAllString(power, -1) {
+		if n, err := strconv.Atoi(s); err == nil && n > level {
+			level = n
+		}
+	}
+	if level < 0 {
+		return nil
+	}
+	if level >= len(beaufortMax) {
+		level = len(beaufortMax) - 1
+	}
+	return floatPtr(beaufortMax[level])
+}
+
+// rainfallOf 天气现象转换为降雨等级：0无雨 1小雨 2中雨 3大雨，降雪按同等级降雨处理
+func rainfallOf(weather string) *int {
+	if weather == "" {
+		return nil
+	}
+	switch {
+	case strings.Contains(weather, "暴"), strings.Contains(weather, "大雨"), strings.Contains(weather, "大雪"), strings.Contains(weather, "冰雹"):
+		return intPtr(3)
+	case strings.Contains(weather, "中雨"), strings.Contains(weather, "中雪"), strings.Contains(weather, "雷阵雨"):
+		return intPtr(2)
+	case strings.Contains(weather, "雨"), strings.Contains(weather, "雪"):
+		return intPtr(1)
+	}
+	return intPtr(0)
+}
+
+func parseFloat(s string) *float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
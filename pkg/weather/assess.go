@@ -0,0 +1,93 @@
+package weather
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits 天气门限，MaxRainfall 为0时不限制降雨
+type Limits struct {
+	MaxWindSpeed  float64 // 风速超过该值不适宜飞行（米/秒）
+	WarnWindSpeed float64 // 风速超过该值警告（米/秒）
+	MaxRainfall   int     // 降雨量达到该等级不适宜飞行：1小雨 2中雨 3大雨
+	MinTemp       float64 // 气温低于该值不适宜飞行（摄氏度）
+	MaxTemp       float64 // 气温高于该值不适宜飞行（摄氏度）
+}
+
+// DefaultLimits 默认门限，与飞前检查的默认天气阈值一致
+func DefaultLimits() Limits {
+	return Limits{MaxWindSpeed: 12, WarnWindSpeed: 8, MaxRainfall: 2, MinTemp: -20, MaxTemp: 50}
+}
+
+// Assessment 天气评估结果
+type Assessment struct {
+	Provider   string       `json:"provider"`
+	Conditions []Conditions `json:"conditions"`
+	Violations []string     `json:"violations,omitempty"` // 超出门限的原因
+	Warnings   []string     `json:"warnings,omitempty"`
+}
+
+// Safe 是否没有超出门限
+func (a *Assessment) Safe() bool {
+	return a == nil || len(a.Violations) == 0
+}
+
+// Err 超出门限时返回包装 ErrUnsafe 的错误，nil 安全
+func (a *Assessment) Err() error {
+	if a.Safe() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnsafe, strings.Join(a.Violations, "; "))
+}
+
+// Assess 按门限逐时段评估天气，没有任何数据时给出警告
+func Assess(list []Conditions, limits Limits) *Assessment {
+	a := &Assessment{Conditions: list}
+	hasData := false
+	for _, c := range list {
+		if c.empty() {
+			continue
+		}
+		hasData = true
+		period := c.period()
+		if c.WindSpeed != nil {
+			switch {
+			case *c.WindSpeed > limits.MaxWindSpeed:
+				a.Violations = append(a.Violations, fmt.Sprintf("%s风速 %.1fm/s 超过 %.1fm/s", period, *c.WindSpeed, limits.MaxWindSpeed))
+			case limits.WarnWindSpeed > 0 && *c.WindSpeed > limits.WarnWindSpeed:
+				a.Warnings = append(a.Warnings, fmt.Sprintf("%s风速 %.1fm/s 超过 %.1fm/s", period, *c.WindSpeed, limits.WarnWindSpeed))
+			}
+		}
+		if c.Rainfall != nil {
+			switch {
+			case limits.MaxRainfall > 0 && *c.Rainfall >= limits.MaxRainfall:
+				a.Violations = append(a.Violations, fmt.Sprintf("%s降雨等级 %d 达到 %d%s", period, *c.Rainfall, limits.MaxRainfall, c.describe()))
+			case *c.Rainfall > 0:
+				a.Warnings = append(a.Warnings, fmt.Sprintf("%s降雨等级 %d%s", period, *c.Rainfall, c.describe()))
+			}
+		}
+		if c.Temperature != nil && (*c.Temperature < limits.MinTemp || *c.Temperature > limits.MaxTemp) {
+			a.Violations = append(a.Violations, fmt.Sprintf("%s气温 %.1f℃ 超出 %.0f~%.0f℃", period, *c.Temperature, limits.MinTemp, limits.MaxTemp))
+		}
+	}
+	if !hasData {
+		a.Warnings = append(a.Warnings, "未获取到天气数据")
+	}
+	return a
+}
+
+// period 时段描述，用于评估原因前缀；单时段的当前天气不加前缀
+func (c Conditions) period() string {
+	if c.Start.IsZero() || !c.End.After(c.Start) {
+		return ""
+	}
+	return fmt.Sprintf("%s~%s ", c.Start.Format("01-02 15:04"), c.End.Format("01-02 15:04"))
+}
+
+// describe 天气现象后缀
+func (c Conditions) describe() string {
+	if c.Description == "" {
+		return ""
+	}
+	return "（" + c.Description + "）"
+}
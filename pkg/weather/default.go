@@ -0,0 +1,171 @@
+package weather
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// gate 全局天气门限设置，由配置文件 Weather 段决定
+type gate struct {
+	enabled        bool
+	provider       WeatherProvider // 天气预报来源，nil 表示只使用机场数据
+	useDock        bool
+	dockHorizon    time.Duration
+	missionWindow  time.Duration
+	blockOnUnknown bool
+	limits         Limits
+}
+
+var (
+	gateMu      sync.RWMutex
+	defaultGate = gate{
+		enabled:       true,
+		useDock:       true,
+		dockHorizon:   time.Hour,
+		missionWindow: time.Hour,
+		limits:        DefaultLimits(),
+	}
+)
+
+// Enabled 是否启用下发前天气门限
+func Enabled() bool {
+	gateMu.RLock()
+	defer gateMu.RUnlock()
+	return defaultGate.enabled
+}
+
+// SetProvider 替换全局天气预报来源，测试时可传入 NewStaticProvider
+func SetProvider(p WeatherProvider) {
+	gateMu.Lock()
+	defer gateMu.Unlock()
+	defaultGate.provider = p
+}
+
+// SetLimits 替换全局天气门限
+func SetLimits(limits Limits) {
+	gateMu.Lock()
+	defer gateMu.Unlock()
+	defaultGate.limits = limits
+}
+
+// CurrentLimits 当前全局天气门限
+func CurrentLimits() Limits {
+	gateMu.RLock()
+	defer gateMu.RUnlock()
+	return defaultGate.limits
+}
+
+// MissionWindow 未指定结束时间的任务查询天气的时长
+func MissionWindow() time.Duration {
+	gateMu.RLock()
+	defer gateMu.RUnlock()
+	return defaultGate.missionWindow
+}
+
+// ForDock 机场执行任务使用的天气来源：近期时段优先使用机场上报的环境数据，之后回退到全局天气预报来源
+// source 为机场所属适配器，可为nil
+func ForDock(source service.TelemetrySource) WeatherProvider {
+	gateMu.RLock()
+	defer gateMu.RUnlock()
+	var dock WeatherProvider
+	if defaultGate.useDock && source != nil {
+		dock = NewDockProvider(source, defaultGate.dockHorizon)
+	}
+	return Fallback(dock, defaultGate.provider)
+}
+
+// Check 按全局门限评估任务区域在执行时段内的天气，超出门限时返回包装 ErrUnsafe 的错误
+// 未启用时返回 nil, nil；获取不到天气时默认放行并记录日志，配置 block_on_unknown 后阻止下发
+func Check(ctx context.Context, provider WeatherProvider, q Query) (*Assessment, error) {
+	gateMu.RLock()
+	g := defaultGate
+	gateMu.RUnlock()
+	if !g.enabled || provider == nil {
+		return nil, nil
+	}
+	if q.End.IsZero() {
+		start, _ := q.window()
+		q.Start, q.End = start, start.Add(g.missionWindow)
+	}
+	list, err := provider.Forecast(ctx, q)
+	if err != nil {
+		a := &Assessment{Provider: provider.Name(), Warnings: []string{"获取天气失败: " + err.Error()}}
+		if g.blockOnUnknown {
+			a.Violations = append(a.Violations, "无法获取任务区域天气")
+			return a, a.Err()
+		}
+		log.Printf("机场 %s 获取天气失败，跳过天气门限: %v", q.DockSN, err)
+		return a, nil
+	}
+	a := Assess(list, g.limits)
+	a.Provider = provider.Name()
+	if a.Safe() && len(a.Warnings) > 0 {
+		log.Printf("机场 %s 天气提醒: %s", q.DockSN, strings.Join(a.Warnings, "; "))
+	}
+	return a, a.Err()
+}
+
+// ApplyConfig 按配置文件 Weather 段更新全局天气来源与门限，高德来源使用配置文件中的 AmapKey；未配置时恢复默认
+func ApplyConfig(cfg *config.Weather) {
+	if cfg == nil {
+		cfg = &config.Weather{}
+	}
+	g := gate{
+		enabled:        cfg.Enabled == nil || *cfg.Enabled,
+		useDock:        cfg.UseDock == nil || *cfg.UseDock,
+		dockHorizon:    time.Hour,
+		missionWindow:  time.Hour,
+		blockOnUnknown: cfg.BlockOnUnknown,
+		limits:         DefaultLimits(),
+	}
+	if cfg.DockHorizon > 0 {
+		g.dockHorizon = time.Duration(cfg.DockHorizon) * time.Minute
+	}
+	if cfg.MissionMinutes > 0 {
+		g.missionWindow = time.Duration(cfg.MissionMinutes) * time.Minute
+	}
+	if cfg.MaxWindSpeed > 0 {
+		g.limits.MaxWindSpeed = cfg.MaxWindSpeed
+	}
+	if cfg.WarnWindSpeed > 0 {
+		g.limits.WarnWindSpeed = cfg.WarnWindSpeed
+	}
+	if cfg.MaxRainfall > 0 {
+		g.limits.MaxRainfall = cfg.MaxRainfall
+	}
+	if cfg.MinTemperature != nil {
+		g.limits.MinTemp = *cfg.MinTemperature
+	}
+	if cfg.MaxTemperature != nil {
+		g.limits.MaxTemp = *cfg.MaxTemperature
+	}
+
+	switch strings.ToLower(cfg.Provider) {
+	case "", "amap":
+		if config.AmapKeySettings() != "" {
+			g.provider = NewAmapProvider(config.AmapKeySettings(), cfg.AmapURL, httpclient.NewSecureHTTPClient())
+		} else if cfg.Provider != "" {
+			log.Printf("天气来源为 amap 但未配置 AmapKey，只使用机场环境数据")
+		}
+	case "static":
+		static := config.WeatherStatic{}
+		if cfg.Static != nil {
+			static = *cfg.Static
+		}
+		g.provider = NewStaticProvider(NewStaticConditions(static.WindSpeed, static.Rainfall, static.Temperature))
+	case "none":
+	default:
+		log.Printf("不支持的天气来源 %s，只使用机场环境数据", cfg.Provider)
+	}
+
+	gateMu.Lock()
+	defaultGate = g
+	gateMu.Unlock()
+}
@@ -0,0 +1,85 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// DockProvider 机场上报的环境数据（风速、降雨、环境温度）
+// 机场数据只反映当前天气，查询时段开始时间超出 horizon 时返回 ErrNoData，由回退链使用天气预报
+type DockProvider struct {
+	source  service.TelemetrySource
+	horizon time.Duration
+}
+
+// NewDockProvider 创建机场天气来源，source 为机场所属适配器（机场2直连或司空2），horizon 小于等于0时为1小时
+func NewDockProvider(source service.TelemetrySource, horizon time.Duration) *DockProvider {
+	if horizon <= 0 {
+		horizon = time.Hour
+	}
+	return &DockProvider{source: source, horizon: horizon}
+}
+
+// Name 来源名称
+func (d *DockProvider) Name() string {
+	return "dock"
+}
+
+// Forecast 以机场当前环境数据作为整个时段的天气
+func (d *DockProvider) Forecast(ctx context.Context, q Query) ([]Conditions, error) {
+	if q.DockSN == "" {
+		return nil, fmt.Errorf("未指定机场: %w", ErrNoData)
+	}
+	start, end := q.window()
+	if start.Sub(time.Now()) > d.horizon {
+		return nil, fmt.Errorf("机场环境数据不能预测 %s 之后的天气: %w", d.horizon, ErrNoData)
+	}
+	// 位置按 WGS-84 读取，不随调用方请求的输出坐标系变化
+	resp, err := d.source.GetDeviceState(geo.WithCoordSystem(ctx, geo.WGS84), q.DockSN)
+	if err != nil {
+		return nil, fmt.Errorf("获取机场 %s 状态失败: %w", q.DockSN, err)
+	}
+	c, err := parseDockEnvironment([]byte(resp))
+	if err != nil {
+		return nil, err
+	}
+	if c.empty() {
+		return nil, fmt.Errorf("机场 %s 未上报环境数据: %w", q.DockSN, ErrNoData)
+	}
+	c.Start, c.End, c.Source = start, end, d.Name()
+	return []Conditions{c}, nil
+}
+
+// parseDockEnvironment 解析机场状态中的环境数据
+// 支持司空2 {"data":{"device_state":{...}}} 与机场2 {"osd":{...}} 两种格式
+func parseDockEnvironment(data []byte) (Conditions, error) {
+	var body struct {
+		Data *struct {
+			DeviceState *environment `json:"device_state"`
+		} `json:"data"`
+		Osd *environment `json:"osd"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return Conditions{}, fmt.Errorf("解析机场环境数据失败: %w", err)
+	}
+	env := body.Osd
+	if body.Data != nil && body.Data.DeviceState != nil {
+		env = body.Data.DeviceState
+	}
+	if env == nil {
+		return Conditions{}, nil
+	}
+	return Conditions{WindSpeed: env.WindSpeed, Rainfall: env.Rainfall, Temperature: env.Temperature}, nil
+}
+
+// environment 机场OSD中的环境数据字段
+type environment struct {
+	WindSpeed   *float64 `json:"wind_speed"`
+	Rainfall    *int     `json:"rainfall"`
+	Temperature *float64 `json:"environment_temperature"`
+}
@@ -0,0 +1,55 @@
+package weather
+
+import (
+	"context"
+	"sync"
+)
+
+// StaticProvider 固定天气来源，用于测试与无外部天气服务的环境
+type StaticProvider struct {
+	mu         sync.RWMutex
+	conditions Conditions
+	err        error
+}
+
+// NewStaticProvider 创建固定天气来源，任意区域与时段都返回 conditions
+func NewStaticProvider(conditions Conditions) *StaticProvider {
+	return &StaticProvider{conditions: conditions}
+}
+
+// NewStaticConditions 按风速、降雨等级与气温构造天气
+func NewStaticConditions(windSpeed float64, rainfall int, temperature float64) Conditions {
+	return Conditions{WindSpeed: floatPtr(windSpeed), Rainfall: intPtr(rainfall), Temperature: floatPtr(temperature)}
+}
+
+// Set 替换返回的天气
+func (s *StaticProvider) Set(conditions Conditions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conditions = conditions
+}
+
+// SetError 设置查询返回的错误，nil 恢复正常，用于模拟天气服务故障
+func (s *StaticProvider) SetError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// Name 来源名称
+func (s *StaticProvider) Name() string {
+	return "static"
+}
+
+// Forecast 返回覆盖整个查询时段的固定天气
+func (s *StaticProvider) Forecast(ctx context.Context, q Query) ([]Conditions, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	c := s.conditions
+	c.Start, c.End = q.window()
+	c.Source = s.Name()
+	return []Conditions{c}, nil
+}
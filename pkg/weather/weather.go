@@ -0,0 +1,110 @@
+// Package weather 任务区域天气查询与下发前的天气门限
+// 天气来源可插拔：高德天气、机场上报的环境数据与测试使用的固定天气，多个来源可按顺序回退。
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+)
+
+var (
+	// ErrNoData 来源无法提供查询区域或时段的天气，回退链据此尝试下一个来源
+	ErrNoData = errors.New("无天气数据")
+	// ErrUnsafe 天气超出飞行门限
+	ErrUnsafe = errors.New("天气不适宜飞行")
+)
+
+// Query 天气查询条件：任务区域与执行时段
+type Query struct {
+	Location geo.Point // 任务区域（WGS-84），通常为机场位置或航线中心
+	DockSN   string    // 执行任务的机场，机场上报来源使用
+	Start    time.Time // 时段开始，零值表示当前
+	End      time.Time // 时段结束，零值表示与开始相同
+}
+
+// window 返回规范化后的时段
+func (q Query) window() (time.Time, time.Time) {
+	start, end := q.Start, q.End
+	if start.IsZero() {
+		start = time.Now()
+	}
+	if end.Before(start) {
+		end = start
+	}
+	return start, end
+}
+
+// Conditions 一个时段内的天气，指针字段为nil表示来源不提供该项
+type Conditions struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	WindSpeed   *float64  `json:"wind_speed,omitempty"`  // 风速（米/秒）
+	Rainfall    *int      `json:"rainfall,omitempty"`    // 降雨量：0无雨 1小雨 2中雨 3大雨
+	Temperature *float64  `json:"temperature,omitempty"` // 气温（摄氏度）
+	Description string    `json:"description,omitempty"` // 天气现象，例如 小雨
+	Source      string    `json:"source"`
+}
+
+// empty 是否不含任何天气数据
+func (c Conditions) empty() bool {
+	return c.WindSpeed == nil && c.Rainfall == nil && c.Temperature == nil
+}
+
+// WeatherProvider 天气来源
+type WeatherProvider interface {
+	// Name 来源名称，用于日志与评估结果
+	Name() string
+	// Forecast 查询任务区域在执行时段内的天气，按时段返回；无法提供时返回包装 ErrNoData 的错误
+	Forecast(ctx context.Context, q Query) ([]Conditions, error)
+}
+
+// Fallback 按顺序回退的天气来源：前一个来源返回 ErrNoData 或查询失败时使用下一个
+// 例如机场上报数据只能反映当前天气，预约时段较远时回退到高德天气预报
+func Fallback(providers ...WeatherProvider) WeatherProvider {
+	var list []WeatherProvider
+	for _, p := range providers {
+		if p != nil {
+			list = append(list, p)
+		}
+	}
+	return fallback(list)
+}
+
+type fallback []WeatherProvider
+
+// Name 各来源名称以 > 连接
+func (f fallback) Name() string {
+	names := make([]string, len(f))
+	for i, p := range f {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, ">")
+}
+
+// Forecast 返回第一个有数据的来源的结果，全部失败时合并错误
+func (f fallback) Forecast(ctx context.Context, q Query) ([]Conditions, error) {
+	var errs []error
+	for _, p := range f {
+		list, err := p.Forecast(ctx, q)
+		if err == nil && len(list) > 0 {
+			return list, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("%s: %w", p.Name(), ErrNoData)
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("未配置天气来源: %w", ErrNoData)
+	}
+	return nil, errors.Join(errs...)
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func intPtr(v int) *int { return &v }
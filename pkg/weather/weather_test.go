@@ -0,0 +1,274 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+)
+
+// shenzhen 深圳（WGS-84）
+var shenzhen = geo.Point{Lat: 22.54, Lng: 114.05}
+
+// TestAssess 风速、降雨、气温按门限给出警告或不适宜飞行
+func TestAssess(t *testing.T) {
+	limits := DefaultLimits()
+	tests := []struct {
+		name           string
+		conditions     []Conditions
+		wantViolations int
+		wantWarnings   int
+	}{
+		{"天气良好", []Conditions{NewStaticConditions(3, 0, 25)}, 0, 0},
+		{"风速超过警告值", []Conditions{NewStaticConditions(9, 0, 25)}, 0, 1},
+		{"风速超过上限", []Conditions{NewStaticConditions(12.5, 0, 25)}, 1, 0},
+		{"小雨仅警告", []Conditions{NewStaticConditions(3, 1, 25)}, 0, 1},
+		{"降雨达到等级", []Conditions{NewStaticConditions(3, 2, 25)}, 1, 0},
+		{"低温", []Conditions{NewStaticConditions(3, 0, -25)}, 1, 0},
+		{"高温", []Conditions{NewStaticConditions(3, 0, 51)}, 1, 0},
+		{"多个时段分别评估", []Conditions{NewStaticConditions(3, 0, 25), NewStaticConditions(13, 3, 25)}, 2, 0},
+		{"来源不提供的项不评估", []Conditions{{Rainfall: intPtr(0)}}, 0, 0},
+		{"没有天气数据", []Conditions{{Description: "晴"}}, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Assess(tt.conditions, limits)
+			if len(a.Violations) != tt.wantViolations || len(a.Warnings) != tt.wantWarnings {
+				t.Fatalf("超限 %v，警告 %v", a.Violations, a.Warnings)
+			}
+			if err := a.Err(); (err != nil) != (tt.wantViolations > 0) || (err != nil && !errors.Is(err, ErrUnsafe)) {
+				t.Errorf("Err = %v", err)
+			}
+		})
+	}
+
+	noRainLimit := limits
+	noRainLimit.MaxRainfall = 0
+	if a := Assess([]Conditions{NewStaticConditions(3, 3, 25)}, noRainLimit); !a.Safe() || len(a.Warnings) != 1 {
+		t.Errorf("MaxRainfall 为0时降雨只警告: %+v", a)
+	}
+	var nilAssessment *Assessment
+	if !nilAssessment.Safe() || nilAssessment.Err() != nil {
+		t.Error("nil 评估结果应视为安全")
+	}
+}
+
+// fakeTelemetry 返回固定机场状态的遥测来源
+type fakeTelemetry struct {
+	state string
+	err   error
+}
+
+func (f *fakeTelemetry) GetDeviceState(ctx context.Context, deviceSn string) (string, error) {
+	return f.state, f.err
+}
+
+// TestDockProvider 解析机场2与司空2两种格式的环境数据，超出适用时段或没有数据时回退到下一个来源
+func TestDockProvider(t *testing.T) {
+	ctx := context.Background()
+	q := Query{Location: shenzhen, DockSN: "DOCK"}
+	tests := []struct {
+		name  string
+		state string
+		want  float64
+	}{
+		{"机场2", `{"osd":{"wind_speed":6.5,"rainfall":0,"environment_temperature":28}}`, 6.5},
+		{"司空2", `{"code":0,"data":{"device_state":{"wind_speed":4.2,"rainfall":1,"environment_temperature":27}}}`, 4.2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := NewDockProvider(&fakeTelemetry{state: tt.state}, 0).Forecast(ctx, q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list) != 1 || list[0].WindSpeed == nil || *list[0].WindSpeed != tt.want || list[0].Temperature == nil || list[0].Source != "dock" {
+				t.Errorf("环境数据 %+v", list)
+			}
+		})
+	}
+
+	dock := NewDockProvider(&fakeTelemetry{state: `{"osd":{"wind_speed":6.5}}`}, 30*time.Minute)
+	later := q
+	later.Start = time.Now().Add(2 * time.Hour)
+	if _, err := dock.Forecast(ctx, later); !errors.Is(err, ErrNoData) {
+		t.Errorf("超出适用时段应返回 ErrNoData: %v", err)
+	}
+	if _, err := dock.Forecast(ctx, Query{Location: shenzhen}); !errors.Is(err, ErrNoData) {
+		t.Errorf("未指定机场应返回 ErrNoData: %v", err)
+	}
+	if _, err := NewDockProvider(&fakeTelemetry{state: `{"osd":{"mode_code":0}}`}, 0).Forecast(ctx, q); !errors.Is(err, ErrNoData) {
+		t.Errorf("未上报环境数据应返回 ErrNoData: %v", err)
+	}
+
+	static := NewStaticProvider(NewStaticConditions(2, 0, 20))
+	chain := Fallback(dock, nil, static)
+	if chain.Name() != "dock>static" {
+		t.Errorf("回退链名称 %s", chain.Name())
+	}
+	list, err := chain.Forecast(ctx, later)
+	if err != nil || len(list) != 1 || list[0].Source != "static" {
+		t.Errorf("机场数据不适用时应回退到下一个来源: %+v, %v", list, err)
+	}
+	static.SetError(errors.New("服务不可用"))
+	if _, err := chain.Forecast(ctx, later); !errors.Is(err, ErrNoData) || !strings.Contains(err.Error(), "服务不可用") {
+		t.Errorf("全部来源失败时应合并错误: %v", err)
+	}
+}
+
+// amapServer 模拟高德逆地理编码与天气查询接口，统计请求次数
+func amapServer(t *testing.T, regeo, base, all string) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Query().Get("key") != "test-key" {
+			fmt.Fprint(w, `{"status":"0","info":"INVALID_USER_KEY"}`)
+			return
+		}
+		switch {
+		case r.URL.Path == "/v3/geocode/regeo":
+			fmt.Fprint(w, regeo)
+		case r.URL.Path == "/v3/weather/weatherInfo" && r.URL.Query().Get("extensions") == "base":
+			fmt.Fprint(w, base)
+		case r.URL.Path == "/v3/weather/weatherInfo" && r.URL.Query().Get("extensions") == "all":
+			fmt.Fprint(w, all)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// TestAmapProvider 当前时段使用实况天气，之后的时段按白天、夜间预报，风力等级与天气现象转换为风速与降雨等级
+func TestAmapProvider(t *testing.T) {
+	ctx := context.Background()
+	today := time.Now().In(amapZone).Truncate(time.Hour)
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, amapZone)
+	tomorrow := today.AddDate(0, 0, 1)
+	all := fmt.Sprintf(`{"status":"1","forecasts":[{"casts":[
+		{"date":"%s","dayweather":"晴","nightweather":"阴","daytemp":"30","nighttemp":"24","daypower":"≤3","nightpower":"≤3"},
+		{"date":"%s","dayweather":"中雨","nightweather":"大雨","daytemp":"27","nighttemp":"22","daypower":"4-5","nightpower":"6"}
+	]}]}`, today.Format(time.DateOnly), tomorrow.Format(time.DateOnly))
+	base := `{"status":"1","lives":[{"weather":"小雨","temperature":"26","windpower":"≤3"}]}`
+	server, requests := amapServer(t, `{"status":"1","regeocode":{"addressComponent":{"adcode":"440305"}}}`, base, all)
+	amap := NewAmapProvider("test-key", server.URL+"/", httpclient.NewSecureHTTPClient())
+
+	list, err := amap.Forecast(ctx, Query{Location: shenzhen, End: time.Now().Add(30 * time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := list[0]
+	if live.Description != "小雨" || *live.WindSpeed != 5.4 || *live.Rainfall != 1 || *live.Temperature != 26 {
+		t.Errorf("实况天气 %+v", live)
+	}
+
+	tests := []struct {
+		name        string
+		start       time.Time
+		description string
+		wind        float64
+		rainfall    int
+	}{
+		{"次日白天", tomorrow.Add(10 * time.Hour), "中雨", 10.7, 2},
+		{"次日夜间", tomorrow.Add(22 * time.Hour), "大雨", 13.8, 3},
+		{"后日凌晨按次日夜间预报", tomorrow.Add(29 * time.Hour), "大雨", 13.8, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := amap.Forecast(ctx, Query{Location: shenzhen, Start: tt.start, End: tt.start.Add(time.Hour)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list) != 1 || list[0].Description != tt.description || *list[0].WindSpeed != tt.wind || *list[0].Rainfall != tt.rainfall {
+				t.Errorf("预报 %+v", list)
+			}
+		})
+	}
+
+	list, err = amap.Forecast(ctx, Query{Location: shenzhen, Start: today.Add(2 * time.Hour), End: today.Add(3 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if early := list[len(list)-1]; !early.Start.Equal(today) || early.Description != "阴" {
+		t.Errorf("当天凌晨应使用当天夜间预报: %+v", early)
+	}
+
+	if _, err := amap.Forecast(ctx, Query{Location: shenzhen, Start: tomorrow.AddDate(0, 0, 5)}); !errors.Is(err, ErrNoData) {
+		t.Errorf("预报不覆盖的时段应返回 ErrNoData: %v", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 3 {
+		t.Errorf("逆地理编码与天气响应应被缓存，实际请求 %d 次", got)
+	}
+}
+
+// TestAmapProviderErrors 接口返回错误状态、没有行政区划、境外区域与未配置Key
+func TestAmapProviderErrors(t *testing.T) {
+	ctx := context.Background()
+	q := Query{Location: shenzhen}
+	ok := `{"status":"1","lives":[],"forecasts":[]}`
+
+	server, _ := amapServer(t, `{"status":"1","regeocode":{"addressComponent":{"adcode":"440305"}}}`, ok, ok)
+	if _, err := NewAmapProvider("bad-key", server.URL, httpclient.NewSecureHTTPClient()).Forecast(ctx, q); err == nil || !strings.Contains(err.Error(), "INVALID_USER_KEY") {
+		t.Errorf("status 不为1时应返回 info: %v", err)
+	}
+
+	sea, requests := amapServer(t, `{"status":"1","regeocode":{"addressComponent":{"adcode":[]}}}`, ok, ok)
+	amap := NewAmapProvider("test-key", sea.URL, httpclient.NewSecureHTTPClient())
+	if _, err := amap.Forecast(ctx, q); !errors.Is(err, ErrNoData) {
+		t.Errorf("没有行政区划时应返回 ErrNoData: %v", err)
+	}
+	before := atomic.LoadInt32(requests)
+	if _, err := amap.Forecast(ctx, Query{Location: geo.Point{Lat: 48.85, Lng: 2.35}}); !errors.Is(err, ErrNoData) || atomic.LoadInt32(requests) != before {
+		t.Errorf("境外区域应直接返回 ErrNoData: %v", err)
+	}
+	if _, err := NewAmapProvider("", sea.URL, httpclient.NewSecureHTTPClient()).Forecast(ctx, q); !errors.Is(err, ErrNoData) {
+		t.Errorf("未配置Key时应返回 ErrNoData: %v", err)
+	}
+}
+
+// TestCheckBlockOnUnknown 配置门限生效，获取不到天气时默认放行，block_on_unknown 时阻止下发
+func TestCheckBlockOnUnknown(t *testing.T) {
+	t.Cleanup(func() { ApplyConfig(&config.Weather{Provider: "none"}) })
+	ctx := context.Background()
+	q := Query{Location: shenzhen, DockSN: "DOCK"}
+
+	ApplyConfig(&config.Weather{Provider: "static", Static: &config.WeatherStatic{WindSpeed: 11, Temperature: 20}, MaxWindSpeed: 10})
+	if got := CurrentLimits(); got.MaxWindSpeed != 10 || got.WarnWindSpeed != DefaultLimits().WarnWindSpeed {
+		t.Errorf("门限 %+v", got)
+	}
+	gateMu.RLock()
+	provider := defaultGate.provider
+	gateMu.RUnlock()
+	a, err := Check(ctx, provider, q)
+	if !errors.Is(err, ErrUnsafe) || a.Provider != "static" {
+		t.Errorf("风速超过配置的上限应阻止下发: %+v, %v", a, err)
+	}
+
+	failing := NewStaticProvider(NewStaticConditions(2, 0, 20))
+	failing.SetError(fmt.Errorf("高德API错误: %w", ErrNoData))
+	a, err = Check(ctx, failing, q)
+	if err != nil || !a.Safe() || len(a.Warnings) != 1 {
+		t.Errorf("默认获取不到天气时放行并警告: %+v, %v", a, err)
+	}
+
+	ApplyConfig(&config.Weather{Provider: "none", BlockOnUnknown: true})
+	if a, err = Check(ctx, failing, q); !errors.Is(err, ErrUnsafe) || a.Safe() {
+		t.Errorf("block_on_unknown 时获取不到天气应阻止下发: %+v, %v", a, err)
+	}
+
+	disabled := false
+	ApplyConfig(&config.Weather{Enabled: &disabled, BlockOnUnknown: true, Provider: "none"})
+	if a, err = Check(ctx, failing, q); a != nil || err != nil {
+		t.Errorf("未启用时不检查: %+v, %v", a, err)
+	}
+}
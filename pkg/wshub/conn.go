@@ -0,0 +1,385 @@
+package wshub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/hms"
+	"gitee.com/jamespi/drone_dispatch/pkg/telemetry"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+	"gitee.com/jamespi/drone_dispatch/plugin"
+	"gitee.com/jamespi/drone_dispatch/service"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// writeWait 单条消息的写超时
+	writeWait = 10 * time.Second
+	// maxRequestBytes 客户端消息大小上限
+	maxRequestBytes = 64 << 10
+)
+
+// conn 一个 WebSocket 连接
+type conn struct {
+	hub     *Hub
+	ws      *websocket.Conn
+	info    *tenant.TenantInfo
+	session string
+	send    chan *Message
+
+	closing     chan struct{}
+	closeOnce   sync.Once
+	closeCode   int
+	closeReason string
+
+	// 以下字段由 hub.mu 保护
+	topics   map[string]bool
+	devices  map[string]bool
+	tasks    map[string]bool
+	lang     hms.Lang
+	releases []func()
+}
+
+func newConn(h *Hub, ws *websocket.Conn, info *tenant.TenantInfo, opts Options) *conn {
+	return &conn{
+		hub:     h,
+		ws:      ws,
+		info:    info,
+		session: uuid.New().String(),
+		send:    make(chan *Message, opts.SendBuffer),
+		closing: make(chan struct{}),
+	}
+}
+
+// run 处理连接直至断开，返回断开原因
+func (c *conn) run(ctx context.Context, opts Options) string {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.writeLoop(ctx, opts.HeartbeatInterval)
+	}()
+	err := c.readLoop(ctx, opts.HeartbeatInterval)
+	cancel()
+	<-done
+	c.ws.Close()
+
+	select {
+	case <-c.closing:
+		return c.closeReason
+	default:
+	}
+	if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+		return "客户端关闭"
+	}
+	return err.Error()
+}
+
+// readLoop 读取客户端消息，超过两个心跳间隔没有任何消息或 pong 时断开
+func (c *conn) readLoop(ctx context.Context, heartbeat time.Duration) error {
+	c.ws.SetReadLimit(maxRequestBytes)
+	extend := func() { _ = c.ws.SetReadDeadline(time.Now().Add(2 * heartbeat)) }
+	extend()
+	c.ws.SetPongHandler(func(string) error {
+		extend()
+		return nil
+	})
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		extend()
+		var req Request
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.enqueue(c.errorMessage("", CodeInvalidArgument, fmt.Sprintf("消息不是合法的JSON: %v", err)))
+			continue
+		}
+		c.handle(ctx, req)
+	}
+}
+
+// writeLoop 发送消息与心跳，是唯一写连接的协程
+func (c *conn) writeLoop(ctx context.Context, heartbeat time.Duration) {
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.closing:
+			deadline := time.Now().Add(writeWait)
+			_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(c.closeCode, c.closeReason), deadline)
+			c.ws.Close()
+			return
+		case msg := <-c.send:
+			if err := c.write(msg); err != nil {
+				c.ws.Close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				c.ws.Close()
+				return
+			}
+			// 浏览器无法感知 ping 帧，另发应用层心跳便于客户端检测断线并记录最新序号
+			if err := c.write(&Message{Type: TypeHeartbeat, Seq: c.hub.Seq()}); err != nil {
+				c.ws.Close()
+				return
+			}
+		}
+	}
+}
+
+func (c *conn) write(msg *Message) error {
+	_ = c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.ws.WriteJSON(msg)
+}
+
+// enqueue 放入发送队列，队列已满说明客户端消费过慢，断开连接由客户端重连续传
+func (c *conn) enqueue(msg *Message) {
+	select {
+	case c.send <- msg:
+	default:
+		c.close(websocket.CloseTryAgainLater, "消费过慢")
+	}
+}
+
+// close 通知写协程发送关闭帧并断开连接，可重复调用
+func (c *conn) close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode, c.closeReason = code, reason
+		close(c.closing)
+	})
+}
+
+// handle 处理一条客户端消息
+func (c *conn) handle(ctx context.Context, req Request) {
+	switch req.Type {
+	case TypeSubscribe:
+		if err := c.subscribe(ctx, req); err != nil {
+			var reqErr *requestError
+			if errors.As(err, &reqErr) {
+				c.enqueue(c.errorMessage(req.ID, reqErr.code, reqErr.message))
+			} else {
+				c.enqueue(c.errorMessage(req.ID, CodeInvalidArgument, err.Error()))
+			}
+		}
+	case TypeUnsubscribe:
+		c.unsubscribe(req)
+	case TypePing:
+		c.enqueue(&Message{Type: TypePong, ID: req.ID, Seq: c.hub.Seq()})
+	default:
+		c.enqueue(c.errorMessage(req.ID, CodeInvalidArgument, fmt.Sprintf("未知的消息类型: %s", req.Type)))
+	}
+}
+
+// requestError 带错误码的请求错误
+type requestError struct {
+	code    string
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+func invalid(format string, args ...interface{}) error {
+	return &requestError{code: CodeInvalidArgument, message: fmt.Sprintf(format, args...)}
+}
+
+// subscribe 校验并替换订阅，确认设备属于当前租户，为不主动推送的插件启动轮询，按需补发历史事件
+func (c *conn) subscribe(ctx context.Context, req Request) error {
+	topicSet := make(map[string]bool)
+	for _, topic := range req.Topics {
+		if !knownTopic(topic) {
+			return invalid("未知的主题: %s", topic)
+		}
+		topicSet[topic] = true
+	}
+	if len(topicSet) == 0 {
+		for _, topic := range topics {
+			topicSet[topic] = true
+		}
+	}
+	if len(req.SN) == 0 {
+		return invalid("至少指定一个设备序列号")
+	}
+	taskSet := make(map[string]bool)
+	for _, id := range req.TaskUUID {
+		if err := validator.GetValidator().ValidateUUID(id); err != nil {
+			return invalid("任务UUID无效: %v", err)
+		}
+		taskSet[id] = true
+	}
+	var lang hms.Lang
+	if req.Lang != "" {
+		parsed, err := hms.ParseLang(req.Lang)
+		if err != nil {
+			return invalid("%v", err)
+		}
+		lang = parsed
+	}
+
+	deviceSet := make(map[string]bool)
+	var releases []func()
+	releaseAll := func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+	for _, sn := range req.SN {
+		if deviceSet[sn] {
+			continue
+		}
+		started, err := c.startPolling(ctx, sn, topicSet)
+		releases = append(releases, started...)
+		if err != nil {
+			releaseAll()
+			return err
+		}
+		deviceSet[sn] = true
+	}
+
+	h := c.hub
+	h.mu.Lock()
+	previous := c.releases
+	c.topics, c.devices, c.tasks, c.lang, c.releases = topicSet, deviceSet, taskSet, lang, releases
+	ack := &Message{Type: TypeAck, ID: req.ID, Seq: h.seq}
+	var replay []event
+	if req.Since > 0 {
+		replay, ack.Reset = c.replay(req)
+	}
+	ack.Replayed = len(replay)
+	c.enqueue(ack)
+	for _, e := range replay {
+		c.enqueue(c.eventMessage(e))
+	}
+	h.mu.Unlock()
+
+	for _, release := range previous {
+		release()
+	}
+	return nil
+}
+
+// replay 取出需要补发的事件，调用方持有 hub.mu；纪元或序号不匹配、历史已被覆盖或补发量超出发送队列时返回 reset
+func (c *conn) replay(req Request) (events []event, reset bool) {
+	h := c.hub
+	if (req.Epoch != "" && req.Epoch != h.epoch) || req.Since > h.seq {
+		return nil, true
+	}
+	history, ok := h.history.since(req.Since)
+	if !ok {
+		return nil, true
+	}
+	for _, e := range history {
+		if c.wants(e) {
+			events = append(events, e)
+		}
+	}
+	// 预留 ack 的位置
+	if len(events) >= cap(c.send)-len(c.send) {
+		return nil, true
+	}
+	return events, false
+}
+
+// startPolling 确认设备属于当前租户，并为订阅主题中需要轮询的插件启动共享轮询
+func (c *conn) startPolling(ctx context.Context, sn string, topicSet map[string]bool) ([]func(), error) {
+	if err := validator.GetValidator().ValidateDeviceSN(sn); err != nil {
+		return nil, invalid("设备序列号无效: %v", err)
+	}
+	locator, err := plugin.SelectForDevice[service.DeviceLocator](ctx, sn)
+	if err != nil {
+		return nil, &requestError{code: CodeNotFound, message: err.Error()}
+	}
+	locator.Release()
+	var releases []func()
+	if topicSet[TopicOSD] {
+		source, err := plugin.SelectForDevice[service.TelemetrySource](ctx, sn)
+		if err != nil {
+			return releases, &requestError{code: CodeNotFound, message: err.Error()}
+		}
+		if !telemetry.IsPusher(source.Impl) {
+			pollCtx, err := c.serviceContext()
+			if err != nil {
+				return releases, err
+			}
+			releases = append(releases, c.hub.pollOSD(pollCtx, source.Impl, sn))
+		}
+	}
+	if topicSet[TopicHms] {
+		provider, err := plugin.SelectForDevice[service.HmsProvider](ctx, sn)
+		if err != nil {
+			return releases, &requestError{code: CodeNotFound, message: err.Error()}
+		}
+		if !telemetry.IsPusher(provider.Impl) {
+			pollCtx, err := c.serviceContext()
+			if err != nil {
+				return releases, err
+			}
+			releases = append(releases, c.hub.pollHms(pollCtx, provider.Impl, sn))
+		}
+	}
+	return releases, nil
+}
+
+// serviceContext 共享轮询的查询上下文：以连接租户的服务凭据代替用户令牌，轮询可能比发起订阅的连接存活更久
+func (c *conn) serviceContext() (context.Context, error) {
+	info, err := c.hub.options().ServiceTokens.Service(c.info)
+	if err != nil {
+		return nil, &requestError{code: CodePrecondition, message: err.Error()}
+	}
+	return tenant.WithTenant(context.Background(), info), nil
+}
+
+// unsubscribe 取消全部订阅
+func (c *conn) unsubscribe(req Request) {
+	h := c.hub
+	h.mu.Lock()
+	previous := c.releases
+	c.topics, c.devices, c.tasks, c.releases = nil, nil, nil, nil
+	c.enqueue(&Message{Type: TypeAck, ID: req.ID, Seq: h.seq})
+	h.mu.Unlock()
+	for _, release := range previous {
+		release()
+	}
+}
+
+// wants 事件是否符合连接的订阅，调用方持有 hub.mu
+func (c *conn) wants(e event) bool {
+	if !c.topics[e.topic] || !c.devices[e.sn] {
+		return false
+	}
+	return e.topic != TopicTask || len(c.tasks) == 0 || c.tasks[e.task]
+}
+
+// eventMessage 生成事件消息，HMS告警按连接指定的语言重新解码
+func (c *conn) eventMessage(e event) *Message {
+	data := e.data
+	if alarm, ok := data.(hms.Event); ok && c.lang != "" {
+		alarm.Alarm = hms.DefaultCatalog().Decode(alarm.Alarm.Alarm, c.lang)
+		data = alarm
+	}
+	return &Message{Type: TypeEvent, Seq: e.seq, Topic: e.topic, SN: e.sn, At: e.at.UnixMilli(), Data: data}
+}
+
+func (c *conn) errorMessage(id, code, message string) *Message {
+	return &Message{Type: TypeError, ID: id, Seq: c.hub.Seq(), Error: &ErrorBody{Code: code, Message: message}}
+}
+
+func knownTopic(topic string) bool {
+	for _, t := range topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
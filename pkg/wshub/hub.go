@@ -0,0 +1,366 @@
+// Package wshub 为浏览器看板提供 WebSocket 推送：客户端以租户身份连接后订阅设备的OSD、HMS告警、飞行任务进度与DRC链路状态，
+// 服务端按连接的订阅过滤推送。事件带有递增序号，断线重连后可按序号续传；发送队列积压的连接视为消费过慢并断开。
+// 浏览器无法为 WebSocket 设置请求头，用户令牌经 Sec-WebSocket-Protocol 传递（见 Subprotocol），不放在查询参数中以免写入访问日志；
+// 租户ID、项目与组织可通过查询参数 tenant_id、project_uuid、org_id 声明。
+package wshub
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/hms"
+	"gitee.com/jamespi/drone_dispatch/pkg/telemetry"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// DefaultPath 未配置推送路径时使用
+const DefaultPath = "/v1/ws"
+
+// 查询参数，与请求头 X-Tenant-Id、X-Project-Uuid、X-Org-Id 对应
+const (
+	QueryTenantID    = "tenant_id"
+	QueryProjectUUID = "project_uuid"
+	QueryOrgID       = "org_id"
+)
+
+// 浏览器连接时声明的子协议：new WebSocket(url, [Subprotocol, TokenProtocolPrefix + base64url(token)])
+// 服务端只回应 Subprotocol；不便设置子协议的客户端也可使用请求头 X-User-Token
+const (
+	Subprotocol         = "dispatch.v1"
+	TokenProtocolPrefix = "token."
+)
+
+// Options 推送选项
+type Options struct {
+	// DefaultPermissions 未单独配置的租户具备的权限
+	DefaultPermissions []string
+	// Grants 按租户ID授予的权限，覆盖 DefaultPermissions
+	Grants map[int64][]string
+	// Tokens 登记的用户令牌摘要与所属租户，未登记的令牌无法连接
+	Tokens tenant.Tokens
+	// ServiceTokens 租户的服务凭据，轮询型插件的设备查询使用，不沿用发起订阅的用户令牌
+	ServiceTokens tenant.ServiceTokens
+	// AllowedOrigins 允许跨域连接的来源，* 表示全部，为空时只允许同源
+	AllowedOrigins []string
+	// HeartbeatInterval 心跳间隔，默认25秒；连续两个间隔未收到客户端消息或 pong 时断开
+	HeartbeatInterval time.Duration
+	// SendBuffer 每个连接待发送的消息数上限，默认256
+	SendBuffer int
+	// ReplayBuffer 保留最近的事件数，默认4096，只在创建时生效
+	ReplayBuffer int
+	// OSDInterval 轮询型插件（如司空2）的OSD查询间隔，默认2秒
+	OSDInterval time.Duration
+	// HmsInterval 轮询型插件的HMS告警查询间隔，默认30秒
+	HmsInterval time.Duration
+	// Logger 连接日志，为nil时使用标准库 log
+	Logger *log.Logger
+}
+
+// OptionsFromConfig 按配置文件 Server 与 Websocket 段生成推送选项，与 REST 服务共用租户权限
+func OptionsFromConfig(server *config.Server, ws *config.Websocket) Options {
+	opts := Options{Grants: make(map[int64][]string), Tokens: make(tenant.Tokens), ServiceTokens: make(tenant.ServiceTokens)}
+	if server != nil {
+		opts.DefaultPermissions = server.DefaultPermissions
+		for _, grant := range server.Tenants {
+			if len(grant.Permissions) > 0 {
+				opts.Grants[grant.TenantID] = grant.Permissions
+			}
+			for _, digest := range grant.TokenSHA256 {
+				opts.Tokens[strings.ToLower(strings.TrimSpace(digest))] = grant.TenantID
+			}
+			if grant.ServiceToken != "" {
+				opts.ServiceTokens[grant.TenantID] = grant.ServiceToken
+			}
+		}
+	}
+	if ws != nil {
+		opts.AllowedOrigins = ws.AllowedOrigins
+		opts.HeartbeatInterval = time.Duration(ws.HeartbeatInterval) * time.Second
+		opts.SendBuffer = ws.SendBuffer
+		opts.ReplayBuffer = ws.ReplayBuffer
+		opts.OSDInterval = time.Duration(ws.OsdInterval) * time.Second
+		opts.HmsInterval = time.Duration(ws.HmsInterval) * time.Second
+	}
+	return opts
+}
+
+// withDefaults 填充默认值
+func (o Options) withDefaults() Options {
+	if o.HeartbeatInterval <= 0 {
+		o.HeartbeatInterval = 25 * time.Second
+	}
+	if o.SendBuffer <= 0 {
+		o.SendBuffer = 256
+	}
+	if o.ReplayBuffer <= 0 {
+		o.ReplayBuffer = 4096
+	}
+	if o.OSDInterval <= 0 {
+		o.OSDInterval = 2 * time.Second
+	}
+	if o.HmsInterval <= 0 {
+		o.HmsInterval = 30 * time.Second
+	}
+	return o
+}
+
+// Hub WebSocket 推送中心，实现 http.Handler
+type Hub struct {
+	optsMu sync.RWMutex
+	opts   Options
+
+	// mu 保护序号、历史事件、连接集合以及各连接的订阅
+	mu      sync.Mutex
+	epoch   string
+	seq     uint64
+	history *ring
+	conns   map[*conn]struct{}
+	closed  bool
+
+	pollMu  sync.Mutex
+	pollers map[pollKey]*sharedPoller
+
+	unsubscribe []func()
+}
+
+// NewHub 创建推送中心并订阅 telemetry 与 HMS 事件
+func NewHub(opts Options) *Hub {
+	opts = opts.withDefaults()
+	h := &Hub{
+		opts:    opts,
+		epoch:   uuid.New().String(),
+		history: newRing(opts.ReplayBuffer),
+		conns:   make(map[*conn]struct{}),
+		pollers: make(map[pollKey]*sharedPoller),
+	}
+	h.unsubscribe = []func(){
+		telemetry.OnOSD(h.onOSD),
+		hms.OnEvent(h.onHms),
+		telemetry.OnTaskStatus(h.onTask),
+		telemetry.OnDRCStatus(h.onDRC),
+	}
+	return h
+}
+
+// SetOptions 替换推送选项（配置重新加载时更新权限），已建立的连接不受影响
+func (h *Hub) SetOptions(opts Options) {
+	h.optsMu.Lock()
+	defer h.optsMu.Unlock()
+	h.opts = opts.withDefaults()
+}
+
+func (h *Hub) options() Options {
+	h.optsMu.RLock()
+	defer h.optsMu.RUnlock()
+	return h.opts
+}
+
+// Epoch 推送中心的纪元，重启后变化，续传时用于确认序号仍然有效
+func (h *Hub) Epoch() string {
+	return h.epoch
+}
+
+// Seq 最新事件序号
+func (h *Hub) Seq() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.seq
+}
+
+// Close 取消事件订阅、断开全部连接并停止轮询
+func (h *Hub) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	for c := range h.conns {
+		c.close(websocket.CloseGoingAway, "服务关闭")
+	}
+	h.mu.Unlock()
+
+	for _, unsubscribe := range h.unsubscribe {
+		unsubscribe()
+	}
+	h.pollMu.Lock()
+	pollers := h.pollers
+	h.pollers = make(map[pollKey]*sharedPoller)
+	h.pollMu.Unlock()
+	for _, p := range pollers {
+		p.stop()
+	}
+}
+
+// ServeHTTP 校验租户身份后升级为 WebSocket 连接，连接结束前不返回
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	opts := h.options()
+	info, status, err := authenticate(r, opts)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	upgrader := websocket.Upgrader{CheckOrigin: checkOrigin(opts.AllowedOrigins), Subprotocols: []string{Subprotocol}}
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade 已写入错误响应
+		return
+	}
+
+	c := newConn(h, ws, info, opts)
+	ctx := tenant.WithRequestID(tenant.WithTenant(context.WithoutCancel(r.Context()), info), c.session)
+	if !h.register(c, opts) {
+		ws.Close()
+		return
+	}
+	h.logf("WebSocket 连接建立: 租户 %d 会话 %s 来自 %s", info.TenantId, c.session, r.RemoteAddr)
+	reason := c.run(ctx, opts)
+	h.unregister(c)
+	h.logf("WebSocket 连接断开: 租户 %d 会话 %s %s", info.TenantId, c.session, reason)
+}
+
+// authenticate 校验用户令牌并以令牌所属的租户作为连接身份，按配置授予权限，订阅设备数据需要 fh2:read
+// 令牌取自子协议 token.<base64url>，其次为请求头 X-User-Token；查询参数中的令牌不被接受
+func authenticate(r *http.Request, opts Options) (*tenant.TenantInfo, int, error) {
+	query := r.URL.Query()
+	if query.Has("token") {
+		return nil, http.StatusBadRequest, fmt.Errorf("用户令牌不能通过查询参数传递，请使用子协议 %s<base64url> 或请求头 %s", TokenProtocolPrefix, tenant.HeaderUserToken)
+	}
+	header := r.Header.Clone()
+	for param, key := range map[string]string{
+		QueryTenantID:    tenant.HeaderTenantID,
+		QueryProjectUUID: tenant.HeaderProjectUUID,
+		QueryOrgID:       tenant.HeaderOrgID,
+	} {
+		if value := query.Get(param); value != "" {
+			header.Set(key, value)
+		}
+	}
+	for _, protocol := range websocket.Subprotocols(r) {
+		encoded, ok := strings.CutPrefix(protocol, TokenProtocolPrefix)
+		if !ok {
+			continue
+		}
+		token, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+		if err != nil {
+			return nil, http.StatusUnauthorized, fmt.Errorf("%w: 子协议中的用户令牌不是 base64url 编码", tenant.ErrUnauthenticated)
+		}
+		header.Set(tenant.HeaderUserToken, string(token))
+		break
+	}
+	info, err := opts.Tokens.Verify(header)
+	if err != nil {
+		return nil, http.StatusUnauthorized, err
+	}
+	if perms, ok := opts.Grants[info.TenantId]; ok {
+		info.GrantPermissions(perms...)
+	} else {
+		info.GrantPermissions(opts.DefaultPermissions...)
+	}
+	if !info.HasPermission(tenant.PermFH2Read) {
+		return nil, http.StatusForbidden, fmt.Errorf("租户 %d 缺少权限 %s", info.TenantId, tenant.PermFH2Read)
+	}
+	return info, http.StatusOK, nil
+}
+
+// checkOrigin 跨域来源检查，未配置时使用 websocket 默认的同源检查
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	if len(allowed) == 0 {
+		return nil
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, a := range allowed {
+			if a == "*" || strings.EqualFold(a, origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// register 登记连接并发送 welcome，推送中心已关闭时返回 false
+func (h *Hub) register(c *conn, opts Options) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.conns[c] = struct{}{}
+	c.enqueue(&Message{
+		Type:        TypeWelcome,
+		Seq:         h.seq,
+		Session:     c.session,
+		Epoch:       h.epoch,
+		HeartbeatMs: opts.HeartbeatInterval.Milliseconds(),
+	})
+	return true
+}
+
+// unregister 移除连接并释放其轮询
+func (h *Hub) unregister(c *conn) {
+	h.mu.Lock()
+	delete(h.conns, c)
+	releases := c.releases
+	c.releases = nil
+	h.mu.Unlock()
+	for _, release := range releases {
+		release()
+	}
+}
+
+// publish 为事件编号、记录历史并推送给订阅了该设备与主题的连接
+func (h *Hub) publish(topic, sn, task string, at time.Time, data interface{}) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.seq++
+	e := event{seq: h.seq, topic: topic, sn: sn, task: task, at: at, data: data}
+	h.history.push(e)
+	for c := range h.conns {
+		if c.wants(e) {
+			c.enqueue(c.eventMessage(e))
+		}
+	}
+}
+
+func (h *Hub) onOSD(e telemetry.OSDEvent) {
+	h.publish(TopicOSD, e.DeviceSN, "", e.At, e.OSD)
+}
+
+func (h *Hub) onHms(e hms.Event) {
+	h.publish(TopicHms, e.DeviceSN, "", e.At, e)
+}
+
+func (h *Hub) onTask(e telemetry.TaskEvent) {
+	h.publish(TopicTask, e.DeviceSN, e.TaskUUID, e.At, e)
+}
+
+func (h *Hub) onDRC(e telemetry.DRCEvent) {
+	h.publish(TopicDRC, e.DeviceSN, "", e.At, e)
+}
+
+func (h *Hub) logf(format string, args ...interface{}) {
+	if logger := h.options().Logger; logger != nil {
+		logger.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
@@ -0,0 +1,52 @@
+package wshub
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+)
+
+// TestAuthenticate 令牌经子协议或请求头传递并须已登记，查询参数中的令牌被拒绝
+func TestAuthenticate(t *testing.T) {
+	opts := Options{
+		DefaultPermissions: []string{tenant.PermFH2Read},
+		Tokens:             tenant.Tokens{tenant.TokenDigest("token-1"): 1, tenant.TokenDigest("token-2"): 2},
+	}
+	request := func(query, protocol, header string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, DefaultPath+"?project_uuid=project"+query, nil)
+		if protocol != "" {
+			r.Header.Set("Sec-WebSocket-Protocol", Subprotocol+", "+protocol)
+		}
+		if header != "" {
+			r.Header.Set(tenant.HeaderUserToken, header)
+		}
+		return r
+	}
+	encoded := TokenProtocolPrefix + base64.RawURLEncoding.EncodeToString([]byte("token-1"))
+
+	for _, tc := range []struct {
+		name   string
+		r      *http.Request
+		status int
+	}{
+		{"子协议", request("", encoded, ""), http.StatusOK},
+		{"请求头", request("&tenant_id=2", "", "token-2"), http.StatusOK},
+		{"查询参数中的令牌", request("&token=token-1", "", ""), http.StatusBadRequest},
+		{"缺少令牌", request("", "", ""), http.StatusUnauthorized},
+		{"未登记的令牌", request("", "", "unknown"), http.StatusUnauthorized},
+		{"冒用其他租户", request("&tenant_id=2", encoded, ""), http.StatusUnauthorized},
+		{"子协议编码错误", request("", TokenProtocolPrefix+"!!", ""), http.StatusUnauthorized},
+	} {
+		info, status, err := authenticate(tc.r, opts)
+		if status != tc.status {
+			t.Errorf("%s: 状态 %d，应为 %d（%v）", tc.name, status, tc.status, err)
+			continue
+		}
+		if status == http.StatusOK && (info.ProjectUUID != "project" || !info.HasPermission(tenant.PermFH2Read)) {
+			t.Errorf("%s: 身份 %+v", tc.name, info)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package wshub
+
+import "time"
+
+// 推送主题
+const (
+	TopicOSD  = "osd"  // 飞行器OSD
+	TopicHms  = "hms"  // HMS告警触发与消除
+	TopicTask = "task" // 飞行任务状态变化（司空2任务与机场2一键起飞）
+	TopicDRC  = "drc"  // DRC链路状态
+)
+
+// topics 全部主题，订阅时未指定主题表示全部
+var topics = []string{TopicOSD, TopicHms, TopicTask, TopicDRC}
+
+// 客户端消息类型
+const (
+	TypeSubscribe   = "subscribe"   // 设置订阅（替换当前订阅），可携带 since 续传
+	TypeUnsubscribe = "unsubscribe" // 取消全部订阅
+	TypePing        = "ping"        // 应用层心跳，服务端回复 pong
+)
+
+// 服务端消息类型
+const (
+	TypeWelcome   = "welcome"   // 连接建立，携带会话、纪元与当前序号
+	TypeAck       = "ack"       // 订阅或取消订阅成功
+	TypeEvent     = "event"     // 推送事件
+	TypeHeartbeat = "heartbeat" // 定时心跳，携带当前序号
+	TypePong      = "pong"      // 回复 ping
+	TypeError     = "error"     // 请求处理失败
+)
+
+// 错误码，与 REST 接口一致
+const (
+	CodeInvalidArgument = "invalid_argument"    // 请求参数错误
+	CodeNotFound        = "not_found"           // 设备不存在或不属于当前租户
+	CodePrecondition    = "failed_precondition" // 轮询型插件的设备需要租户配置服务凭据
+)
+
+// Request 客户端消息
+type Request struct {
+	Type     string   `json:"type"`
+	ID       string   `json:"id,omitempty"`        // 请求标识，原样返回于 ack、pong 与 error
+	Topics   []string `json:"topics,omitempty"`    // 订阅的主题，为空表示全部
+	SN       []string `json:"sn,omitempty"`        // 订阅的设备序列号，至少一个
+	TaskUUID []string `json:"task_uuid,omitempty"` // 只推送这些任务的状态，为空表示设备的全部任务
+	Lang     string   `json:"lang,omitempty"`      // HMS告警文案语言：zh、en，为空时使用服务端配置
+	Epoch    string   `json:"epoch,omitempty"`     // 断线前 welcome 消息中的纪元，与当前不一致时无法续传
+	Since    uint64   `json:"since,omitempty"`     // 续传：补发序号大于该值的事件，0 表示不补发
+}
+
+// Message 服务端消息
+type Message struct {
+	Type        string      `json:"type"`
+	ID          string      `json:"id,omitempty"`
+	Seq         uint64      `json:"seq"` // 事件序号；其他消息为当前最新序号
+	Topic       string      `json:"topic,omitempty"`
+	SN          string      `json:"sn,omitempty"`
+	At          int64       `json:"at,omitempty"` // 事件时间（毫秒）
+	Data        interface{} `json:"data,omitempty"`
+	Session     string      `json:"session,omitempty"`
+	Epoch       string      `json:"epoch,omitempty"`
+	HeartbeatMs int64       `json:"heartbeat_ms,omitempty"`
+	Replayed    int         `json:"replayed,omitempty"` // 续传补发的事件数
+	Reset       bool        `json:"reset,omitempty"`    // 无法续传，客户端应通过 REST 接口重新获取当前状态
+	Error       *ErrorBody  `json:"error,omitempty"`
+}
+
+// ErrorBody 错误详情
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// event 已编号的事件
+type event struct {
+	seq   uint64
+	topic string
+	sn    string
+	task  string
+	at    time.Time
+	data  interface{}
+}
+
+// ring 最近事件的环形缓冲，用于续传
+type ring struct {
+	items []event
+	start int
+	size  int
+}
+
+func newRing(capacity int) *ring {
+	return &ring{items: make([]event, capacity)}
+}
+
+// push 追加事件，缓冲已满时覆盖最早的事件
+func (r *ring) push(e event) {
+	if len(r.items) == 0 {
+		return
+	}
+	if r.size < len(r.items) {
+		r.items[(r.start+r.size)%len(r.items)] = e
+		r.size++
+		return
+	}
+	r.items[r.start] = e
+	r.start = (r.start + 1) % len(r.items)
+}
+
+// since 返回序号大于 seq 的事件，缓冲中已缺少 seq 之后的事件时返回 false
+func (r *ring) since(seq uint64) ([]event, bool) {
+	if r.size == 0 {
+		return nil, false
+	}
+	if oldest := r.items[r.start].seq; seq+1 < oldest {
+		return nil, false
+	}
+	var events []event
+	for i := 0; i < r.size; i++ {
+		if e := r.items[(r.start+i)%len(r.items)]; e.seq > seq {
+			events = append(events, e)
+		}
+	}
+	return events, true
+}
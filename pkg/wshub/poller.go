@@ -0,0 +1,73 @@
+package wshub
+
+import (
+	"context"
+	"sync"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/hms"
+	"gitee.com/jamespi/drone_dispatch/pkg/telemetry"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// pollKey 轮询按主题、租户、项目与设备共享，不同租户使用各自的服务凭据查询
+type pollKey struct {
+	topic    string
+	tenantID int64
+	project  string
+	sn       string
+}
+
+// sharedPoller 多个连接订阅同一设备时共用的轮询，最后一个订阅释放时停止
+type sharedPoller struct {
+	refs int
+	stop func()
+}
+
+// acquire 引用设备的轮询，不存在时调用 start 启动，返回释放函数
+func (h *Hub) acquire(key pollKey, start func() (stop func())) (release func()) {
+	h.pollMu.Lock()
+	p, ok := h.pollers[key]
+	if !ok {
+		p = &sharedPoller{stop: start()}
+		h.pollers[key] = p
+	}
+	p.refs++
+	h.pollMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			h.pollMu.Lock()
+			p.refs--
+			last := p.refs == 0 && h.pollers[key] == p
+			if last {
+				delete(h.pollers, key)
+			}
+			h.pollMu.Unlock()
+			if last {
+				p.stop()
+			}
+		})
+	}
+}
+
+// pollOSD 轮询不主动推送的插件的OSD，结果直接进入推送中心，不发布到全局以免其他推送通道重复收到
+// ctx 需携带租户的服务凭据（见 conn.serviceContext），轮询不随连接取消
+func (h *Hub) pollOSD(ctx context.Context, source service.TelemetrySource, sn string) (release func()) {
+	key := pollKey{topic: TopicOSD, sn: sn}
+	if info, err := tenant.GetTenantFromContext(ctx); err == nil {
+		key.tenantID, key.project = info.TenantId, info.ProjectUUID
+	}
+	return h.acquire(key, func() func() {
+		poller := telemetry.NewOSDPoller(source, []string{sn}, h.options().OSDInterval)
+		poller.Start(context.WithoutCancel(ctx), h.onOSD)
+		return poller.Stop
+	})
+}
+
+// pollHms 轮询不主动推送的插件的HMS告警，与 gRPC 推送流共用设备的轮询，经全局去重器产生触发与消除事件
+// ctx 需携带租户的服务凭据
+func (h *Hub) pollHms(ctx context.Context, provider service.HmsProvider, sn string) (release func()) {
+	return hms.Watch(ctx, provider, sn, h.options().HmsInterval)
+}
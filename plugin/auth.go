@@ -41,7 +41,7 @@ func (tm *TokenManager) SetAccessToken(token string, refreshToken string, expire
 // 获取令牌
 func (tm *TokenManager) GetAccessToken() string {
 	tm.mutex.RLock()
-	url := fmt.Sprintf("%s?appId=%s&appKey=%s", config.DjiSettings["url"], config.DjiSettings["appId"], config.DjiSettings["appKey"])
+	url := fmt.Sprintf("%s?appId=%s&appKey=%s", config.DjiSettings()["url"], config.DjiSettings()["appId"], config.DjiSettings()["appKey"])
 
 	resp, err := http.Get(url)
 	if err != nil {
@@ -69,7 +69,7 @@ func (tm *TokenManager) RefreshAccessToken() (string, error) {
 		}
 		tm.accessToken = newToken
 		tm.refreshToken = newToken
-		tm.expiresAt = time.Now().Add(time.Duration(config.TokenExpiresInSettings) * time.Second)
+		tm.expiresAt = time.Now().Add(time.Duration(config.TokenExpiresInSettings()) * time.Second)
 	}
 	return tm.accessToken, nil
 
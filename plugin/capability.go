@@ -0,0 +1,168 @@
+// 插件能力发现
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// CapablePlugin 具备某种能力的插件实例
+type CapablePlugin[T interface{}] struct {
+	PluginType PluginType
+	Status     service.PluginStatus
+	Meta       PluginMeta
+	Impl       T
+	transient  bool // 瞬时作用域的实例，由调用方通过 Release 停止
+}
+
+// Release 停止并关闭瞬时作用域的实例，共享实例（单例、租户级）不受影响
+// 调用方使用完 FindCapable 系列返回的实例后调用
+func (c CapablePlugin[T]) Release() {
+	if c.transient {
+		stopInstance(c.Impl)
+	}
+}
+
+// releaseAll 释放列表中的瞬时实例
+func releaseAll[T interface{}](list []CapablePlugin[T]) {
+	for _, capable := range list {
+		capable.Release()
+	}
+}
+
+// FindCapable 查找全部已启用且实现了能力接口T的插件
+// 按健康状态（健康、降级、失败）、优先级、插件类型排序；开启故障切换时跳过失败状态的插件
+// 瞬时作用域的插件先按工厂创建的实例类型判断能力，不具备能力的实例不会 Init、Start；返回的瞬时实例需调用 Release
+// 例如 plugin.FindCapable[service.LiveStreamer](ctx)
+func FindCapable[T interface{}](ctx context.Context) []CapablePlugin[T] {
+	var list []CapablePlugin[T]
+	for _, candidate := range enabledCandidates() {
+		for _, iface := range candidate.ifaces {
+			var impl interface{}
+			var err error
+			if iface.scope == ScopeTransient {
+				impl = iface.factory()
+				if _, ok := impl.(T); !ok {
+					closeInstance(impl)
+					continue
+				}
+				impl, err = registry.initInstance(ctx, candidate.pluginType, impl)
+			} else {
+				impl, err = registry.instance(ctx, candidate.pluginType, iface.ifaceType, iface.scope, iface.factory)
+			}
+			if err != nil {
+				log.Printf("获取插件 %s 实例失败: %v", candidate.pluginType, err)
+				continue
+			}
+			if typed, ok := impl.(T); ok {
+				list = append(list, CapablePlugin[T]{PluginType: candidate.pluginType, Status: candidate.status, Meta: candidate.meta, Impl: typed, transient: iface.scope == ScopeTransient})
+				break
+			}
+		}
+	}
+	return list
+}
+
+// FindCapableForDevice 查找能对指定设备提供能力T的已启用插件
+// 插件实例需同时实现 service.DeviceLocator 并确认管理该设备；无法判断的插件不会返回，其瞬时实例随即释放
+func FindCapableForDevice[T interface{}](ctx context.Context, deviceSn string) ([]CapablePlugin[T], error) {
+	var list []CapablePlugin[T]
+	var errs []error
+	for _, capable := range FindCapable[T](ctx) {
+		locator, ok := interface{}(capable.Impl).(service.DeviceLocator)
+		if !ok {
+			capable.Release()
+			continue
+		}
+		has, err := locator.HasDevice(ctx, deviceSn)
+		if err != nil {
+			capable.Release()
+			errs = append(errs, fmt.Errorf("插件 %s 查询设备 %s 失败: %w", capable.PluginType, deviceSn, err))
+			continue
+		}
+		if has {
+			list = append(list, capable)
+		} else {
+			capable.Release()
+		}
+	}
+	// 只要找到可用插件就不返回错误，个别插件查询失败仅影响候选范围
+	if len(list) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return list, nil
+}
+
+// SelectForDevice 选出为指定设备提供能力T的最优插件，未选中的瞬时实例随即释放
+// 例如机场2直连插件失败时，自动切换到同一设备的司空2插件
+func SelectForDevice[T interface{}](ctx context.Context, deviceSn string) (CapablePlugin[T], error) {
+	list, err := FindCapableForDevice[T](ctx, deviceSn)
+	if err != nil {
+		return CapablePlugin[T]{}, err
+	}
+	if len(list) == 0 {
+		var capability T
+		return CapablePlugin[T]{}, fmt.Errorf("没有可用插件为设备 %s 提供 %s 能力", deviceSn, reflect.TypeOf(&capability).Elem())
+	}
+	releaseAll(list[1:])
+	return list[0], nil
+}
+
+// capabilityCandidate 能力查询的候选插件快照
+type capabilityCandidate struct {
+	pluginType PluginType
+	status     service.PluginStatus
+	meta       PluginMeta
+	ifaces     []capabilityIface
+}
+
+// capabilityIface 候选插件注册的接口
+type capabilityIface struct {
+	ifaceType reflect.Type
+	scope     InstanceScope
+	factory   func() interface{}
+}
+
+// enabledCandidates 获取已启用插件的快照，避免在创建实例时持有锁
+func enabledCandidates() []capabilityCandidate {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	var candidates []capabilityCandidate
+	for pluginType, ifaceMap := range registry.PluginFactory {
+		status := registry.Status[pluginType]
+		if !isRunning(status) || (status == service.PluginFailed && !registry.noFailover) {
+			continue
+		}
+		candidate := capabilityCandidate{pluginType: pluginType, status: status, meta: registry.Metas[pluginType]}
+		for ifaceType, factory := range ifaceMap {
+			candidate.ifaces = append(candidate.ifaces, capabilityIface{
+				ifaceType: ifaceType,
+				scope:     registry.Scopes[pluginType][ifaceType],
+				factory:   factory,
+			})
+		}
+		sort.Slice(candidate.ifaces, func(i, j int) bool {
+			return candidate.ifaces[i].ifaceType.String() < candidate.ifaces[j].ifaceType.String()
+		})
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if rankA, rankB := healthRank(a.status), healthRank(b.status); rankA != rankB {
+			return rankA < rankB
+		}
+		if a.meta.Priority != b.meta.Priority {
+			return a.meta.Priority > b.meta.Priority
+		}
+		return a.pluginType < b.pluginType
+	})
+	return candidates
+}
@@ -0,0 +1,46 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// TestFindCapableTransient 瞬时实例按类型探测能力，未返回的实例会被停止
+func TestFindCapableTransient(t *testing.T) {
+	const pluginType PluginType = "test_find_capable_transient"
+	c := registerCounter(t, pluginType, ScopeTransient)
+	if err := Enable(pluginType); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// 不具备能力的实例不初始化
+	for _, capable := range FindCapable[service.LiveStreamer](ctx) {
+		if capable.PluginType == pluginType {
+			t.Fatal("测试插件不应具备直播能力")
+		}
+	}
+	if c.inits.Load() != 0 {
+		t.Fatalf("不具备能力的瞬时实例不应初始化，Init 调用 %d 次", c.inits.Load())
+	}
+
+	// 未实现 DeviceLocator 的实例无法按设备筛选，查询后随即释放
+	if _, err := SelectForDevice[counterAPI](ctx, "7CTXN4A00B0001H"); err == nil {
+		t.Fatal("无法判断设备归属的插件不应被选中")
+	}
+	found := false
+	for _, capable := range FindCapable[counterAPI](ctx) {
+		if capable.PluginType == pluginType {
+			found = true
+		}
+		capable.Release()
+	}
+	if !found {
+		t.Fatal("FindCapable 未返回测试插件")
+	}
+	if starts, stops := c.starts.Load(), c.stops.Load(); starts != 2 || stops != starts {
+		t.Fatalf("启动 %d 个瞬时实例，停止 %d 个", starts, stops)
+	}
+}
@@ -0,0 +1,161 @@
+// 插件元信息与依赖管理
+
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PluginMeta 插件元信息
+type PluginMeta struct {
+	Version      string       // 插件版本
+	Vendor       string       // 厂商，例如 DJI
+	Description  string       // 插件描述
+	Dependencies []PluginType // 依赖的插件（例如MQTT连接、令牌服务、ClickHouse），启动时先于本插件启用
+	Capabilities []string     // 插件提供的能力，例如 flight_task、live_stream
+	Path         string       // 进程外插件的可执行文件路径，内置插件为空
+	Priority     int          // 能力查询优先级，健康状态相同时数值大的排在前面（例如直连优先于云端转发）
+}
+
+// RegisterPluginMeta 登记插件元信息，可在 RegisterPlugin 之前或之后调用
+func RegisterPluginMeta(pluginType PluginType, meta PluginMeta) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.Metas[pluginType] = meta
+}
+
+// GetPluginMeta 获取插件元信息
+func GetPluginMeta(pluginType PluginType) (PluginMeta, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	meta, ok := registry.Metas[pluginType]
+	return meta, ok
+}
+
+// ResolveStartupOrder 解析启动顺序：依赖在前，被依赖方在后
+// 会自动带上所选插件的传递依赖；依赖未注册或存在循环依赖时返回错误
+func ResolveStartupOrder(selected []PluginType) ([]PluginType, error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return registry.resolveOrder(selected)
+}
+
+// Shutdown 按启用顺序的逆序禁用全部已启用插件（被依赖方先停，依赖后停）
+func Shutdown() {
+	registry.mu.RLock()
+	order := make([]PluginType, len(registry.enableOrder))
+	copy(order, registry.enableOrder)
+	registry.mu.RUnlock()
+
+	for i := len(order) - 1; i >= 0; i-- {
+		Disable(order[i])
+	}
+}
+
+// resolveOrder 基于深度优先遍历的拓扑排序，调用方需持有读锁
+func (r *Registry) resolveOrder(selected []PluginType) ([]PluginType, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[PluginType]int)
+	var order []PluginType
+	var path []PluginType
+
+	var visit func(pluginType PluginType) error
+	visit = func(pluginType PluginType) error {
+		switch state[pluginType] {
+		case visited:
+			return nil
+		case visiting:
+			// 截取从首次进入该插件开始的路径，组成环
+			start := 0
+			for i, p := range path {
+				if p == pluginType {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]PluginType{}, path[start:]...), pluginType)
+			return fmt.Errorf("插件依赖存在循环: %s", joinPluginTypes(cycle, " -> "))
+		}
+		if _, ok := r.PluginFactory[pluginType]; !ok {
+			if len(path) > 0 {
+				return fmt.Errorf("插件 %s 依赖的插件 %s 未注册", path[len(path)-1], pluginType)
+			}
+			return fmt.Errorf("插件 %s 未注册", pluginType)
+		}
+
+		state[pluginType] = visiting
+		path = append(path, pluginType)
+		for _, dep := range r.Metas[pluginType].Dependencies {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[pluginType] = visited
+		order = append(order, pluginType)
+		return nil
+	}
+
+	for _, pluginType := range selected {
+		if err := visit(pluginType); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// checkDependencies 检查插件的直接依赖是否均已注册并启用，调用方需持有读锁
+func (r *Registry) checkDependencies(pluginType PluginType) error {
+	for _, dep := range r.Metas[pluginType].Dependencies {
+		if _, ok := r.PluginFactory[dep]; !ok {
+			return fmt.Errorf("插件 %s 依赖的插件 %s 未注册", pluginType, dep)
+		}
+		if !isRunning(r.Status[dep]) {
+			return fmt.Errorf("插件 %s 依赖的插件 %s 未启用", pluginType, dep)
+		}
+	}
+	return nil
+}
+
+// enabledDependents 查找直接依赖该插件且已启用的插件，调用方需持有读锁
+func (r *Registry) enabledDependents(pluginType PluginType) []PluginType {
+	var dependents []PluginType
+	for other, meta := range r.Metas {
+		if !isRunning(r.Status[other]) {
+			continue
+		}
+		for _, dep := range meta.Dependencies {
+			if dep == pluginType {
+				dependents = append(dependents, other)
+				break
+			}
+		}
+	}
+	sort.Slice(dependents, func(i, j int) bool { return dependents[i] < dependents[j] })
+	return dependents
+}
+
+// removeEnableOrder 从启用顺序中移除插件，调用方需持有写锁
+func (r *Registry) removeEnableOrder(pluginType PluginType) {
+	for i, p := range r.enableOrder {
+		if p == pluginType {
+			r.enableOrder = append(r.enableOrder[:i], r.enableOrder[i+1:]...)
+			return
+		}
+	}
+}
+
+// joinPluginTypes 拼接插件类型
+func joinPluginTypes(types []PluginType, sep string) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+	return strings.Join(names, sep)
+}
@@ -0,0 +1,144 @@
+package plugin
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// lifecycleLog 按调用顺序记录各插件的 Start、Stop
+type lifecycleLog struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *lifecycleLog) add(entry string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+func (l *lifecycleLog) take() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := l.entries
+	l.entries = nil
+	return entries
+}
+
+// orderedPlugin 将 Start、Stop 记录到 lifecycleLog 的测试插件
+type orderedPlugin struct {
+	name PluginType
+	log  *lifecycleLog
+}
+
+func (p *orderedPlugin) Count() int64 { return 0 }
+
+func (p *orderedPlugin) Start(ctx context.Context) error {
+	p.log.add("start " + string(p.name))
+	return nil
+}
+
+func (p *orderedPlugin) Stop(ctx context.Context) error {
+	p.log.add("stop " + string(p.name))
+	return nil
+}
+
+// registerOrdered 注册插件并登记依赖
+func registerOrdered(t *testing.T, log *lifecycleLog, pluginType PluginType, deps ...PluginType) {
+	t.Helper()
+	RegisterPlugin(pluginType, reflect.TypeOf((*counterAPI)(nil)).Elem(), func() interface{} {
+		return &orderedPlugin{name: pluginType, log: log}
+	})
+	RegisterPluginMeta(pluginType, PluginMeta{Dependencies: deps})
+	t.Cleanup(func() { Unload(pluginType) })
+}
+
+// TestResolveStartupOrder 依赖排在被依赖方之前并自动带上传递依赖，循环依赖与缺失的依赖返回错误
+func TestResolveStartupOrder(t *testing.T) {
+	log := &lifecycleLog{}
+	registerOrdered(t, log, "test_dep_mqtt")
+	registerOrdered(t, log, "test_dep_token")
+	registerOrdered(t, log, "test_dep_dock", "test_dep_mqtt", "test_dep_token")
+	registerOrdered(t, log, "test_dep_live", "test_dep_dock")
+	registerOrdered(t, log, "test_dep_cycle_a", "test_dep_cycle_b")
+	registerOrdered(t, log, "test_dep_cycle_b", "test_dep_cycle_c")
+	registerOrdered(t, log, "test_dep_cycle_c", "test_dep_cycle_a")
+	registerOrdered(t, log, "test_dep_orphan", "test_dep_missing")
+
+	tests := []struct {
+		name     string
+		selected []PluginType
+		want     []PluginType
+		wantErr  string
+	}{
+		{name: "传递依赖", selected: []PluginType{"test_dep_live"}, want: []PluginType{"test_dep_mqtt", "test_dep_token", "test_dep_dock", "test_dep_live"}},
+		{name: "重复选择只出现一次", selected: []PluginType{"test_dep_dock", "test_dep_mqtt", "test_dep_live"}, want: []PluginType{"test_dep_mqtt", "test_dep_token", "test_dep_dock", "test_dep_live"}},
+		{name: "循环依赖", selected: []PluginType{"test_dep_cycle_a"}, wantErr: "test_dep_cycle_a -> test_dep_cycle_b -> test_dep_cycle_c -> test_dep_cycle_a"},
+		{name: "依赖未注册", selected: []PluginType{"test_dep_orphan"}, wantErr: "插件 test_dep_orphan 依赖的插件 test_dep_missing 未注册"},
+		{name: "插件未注册", selected: []PluginType{"test_dep_unknown"}, wantErr: "插件 test_dep_unknown 未注册"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := ResolveStartupOrder(tt.selected)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("错误 %v，应包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(order, tt.want) {
+				t.Errorf("启动顺序 %v，应为 %v", order, tt.want)
+			}
+		})
+	}
+}
+
+// TestDependencyLifecycle 批量启用按依赖顺序启动，依赖未启用时拒绝启用，禁用依赖时先禁用依赖方，Shutdown 逆序停止
+func TestDependencyLifecycle(t *testing.T) {
+	log := &lifecycleLog{}
+	registerOrdered(t, log, "test_life_mqtt")
+	registerOrdered(t, log, "test_life_dock", "test_life_mqtt")
+	registerOrdered(t, log, "test_life_live", "test_life_dock")
+
+	if err := Enable("test_life_dock"); err == nil || !strings.Contains(err.Error(), "未启用") {
+		t.Fatalf("依赖未启用时 Enable 返回 %v", err)
+	}
+	if entries := log.take(); len(entries) != 0 {
+		t.Fatalf("被拒绝的插件不应启动: %v", entries)
+	}
+
+	if err := LoadEnableList([]string{"test_life_live"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"start test_life_mqtt", "start test_life_dock", "start test_life_live"}
+	if entries := log.take(); !reflect.DeepEqual(entries, want) {
+		t.Fatalf("启动顺序 %v，应为 %v", entries, want)
+	}
+
+	Disable("test_life_mqtt")
+	want = []string{"stop test_life_live", "stop test_life_dock", "stop test_life_mqtt"}
+	if entries := log.take(); !reflect.DeepEqual(entries, want) {
+		t.Fatalf("禁用依赖时停止顺序 %v，应为 %v", entries, want)
+	}
+
+	if err := LoadEnableList([]string{"test_life_live"}); err != nil {
+		t.Fatal(err)
+	}
+	log.take()
+	Shutdown()
+	want = []string{"stop test_life_live", "stop test_life_dock", "stop test_life_mqtt"}
+	if entries := log.take(); !reflect.DeepEqual(entries, want) {
+		t.Fatalf("Shutdown 停止顺序 %v，应为 %v", entries, want)
+	}
+	for _, info := range PluginsList() {
+		if strings.HasPrefix(string(info.PluginType), "test_life_") && isRunning(info.Status) {
+			t.Errorf("Shutdown 后插件 %s 仍为 %s", info.PluginType, info.Status)
+		}
+	}
+}
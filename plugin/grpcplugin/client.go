@@ -0,0 +1,424 @@
+package grpcplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	pluginpb "gitee.com/jamespi/drone_dispatch/plugin/grpcplugin/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Options 插件进程启动参数
+type Options struct {
+	Path           string        // 插件可执行文件路径
+	Args           []string      // 启动参数
+	Env            []string      // 额外环境变量，格式 KEY=VALUE
+	StartTimeout   time.Duration // 启动与握手超时，默认10秒
+	StopTimeout    time.Duration // 等待插件进程退出的超时，超时后强制结束，默认5秒
+	MaxRestarts    int           // 崩溃后自动重启的次数上限，默认5次，小于0表示不重启
+	RestartBackoff time.Duration // 首次重启间隔，之后指数退避至30秒，默认1秒
+}
+
+// 默认参数
+const (
+	defaultStartTimeout   = 10 * time.Second
+	defaultStopTimeout    = 5 * time.Second
+	defaultMaxRestarts    = 5
+	defaultRestartBackoff = time.Second
+	maxRestartBackoff     = 30 * time.Second
+)
+
+// Client 插件进程客户端，负责启动、握手、调用、崩溃重启与停止
+// 启动与握手在锁外进行，握手期间的调用立即返回"插件进程未启动"，不会被阻塞
+type Client struct {
+	opts Options
+
+	mu       sync.Mutex
+	proc     *process      // 当前运行的插件进程
+	starting chan struct{} // 启动或重启进行中，完成后关闭
+	info     *pluginpb.HandshakeResponse
+	cfg      map[string]string // 最近一次下发的配置，重启后重新下发
+	running  bool              // 期望状态：是否应当运行
+	restarts int               // 自上次 Start 以来的重启次数
+	lastErr  error             // 最近一次进程异常
+}
+
+// process 已完成握手的插件进程
+type process struct {
+	cmd     *exec.Cmd
+	exited  chan struct{} // 进程退出时关闭
+	sockDir string
+	conn    *grpc.ClientConn
+	rpc     pluginpb.PluginClient
+	info    *pluginpb.HandshakeResponse
+}
+
+// kill 强制结束进程并清理连接与套接字目录
+func (p *process) kill() {
+	p.conn.Close()
+	_ = p.cmd.Process.Kill()
+	<-p.exited
+	os.RemoveAll(p.sockDir)
+}
+
+// NewClient 创建插件进程客户端
+func NewClient(opts Options) *Client {
+	if opts.StartTimeout <= 0 {
+		opts.StartTimeout = defaultStartTimeout
+	}
+	if opts.StopTimeout <= 0 {
+		opts.StopTimeout = defaultStopTimeout
+	}
+	if opts.MaxRestarts == 0 {
+		opts.MaxRestarts = defaultMaxRestarts
+	}
+	if opts.RestartBackoff <= 0 {
+		opts.RestartBackoff = defaultRestartBackoff
+	}
+	return &Client{opts: opts}
+}
+
+// Info 最近一次握手返回的插件信息
+func (c *Client) Info() *pluginpb.HandshakeResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.info
+}
+
+// Start 启动插件进程并完成握手，已运行时直接返回握手信息；其他调用正在启动时等待其完成
+func (c *Client) Start(ctx context.Context) (*pluginpb.HandshakeResponse, error) {
+	for {
+		c.mu.Lock()
+		c.running = true
+		if c.proc != nil {
+			info := c.info
+			c.mu.Unlock()
+			return info, nil
+		}
+		if starting := c.starting; starting != nil {
+			c.mu.Unlock()
+			select {
+			case <-starting:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		c.restarts = 0
+		c.starting = make(chan struct{})
+		c.mu.Unlock()
+
+		proc, err := c.launch(ctx)
+
+		c.mu.Lock()
+		close(c.starting)
+		c.starting = nil
+		if err == nil {
+			err = c.installLocked(proc)
+		}
+		if err != nil {
+			c.running = false
+			c.mu.Unlock()
+			return nil, err
+		}
+		info := c.info
+		c.mu.Unlock()
+		return info, nil
+	}
+}
+
+// installLocked 登记握手完成的进程；启动期间已被 Stop 或进程已退出时结束进程，调用方需持有锁
+func (c *Client) installLocked(proc *process) error {
+	if !c.running {
+		go proc.kill()
+		return errors.New("插件在启动过程中被停止")
+	}
+	select {
+	case <-proc.exited:
+		proc.conn.Close()
+		os.RemoveAll(proc.sockDir)
+		return errors.New("插件进程已退出")
+	default:
+	}
+	c.proc, c.info = proc, proc.info
+	c.lastErr = nil
+	return nil
+}
+
+// Init 启动插件进程（如未运行）并下发配置
+func (c *Client) Init(ctx context.Context, cfg map[string]string) error {
+	if _, err := c.Start(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.cfg = cfg
+	c.mu.Unlock()
+
+	rpc, err := c.client()
+	if err != nil {
+		return err
+	}
+	if _, err := rpc.Init(ctx, &pluginpb.InitRequest{Config: cfg}); err != nil {
+		return fmt.Errorf("插件初始化失败: %s", status.Convert(err).Message())
+	}
+	return nil
+}
+
+// HealthCheck 检查插件进程是否存活且健康
+func (c *Client) HealthCheck(ctx context.Context) error {
+	rpc, err := c.client()
+	if err != nil {
+		return err
+	}
+	if _, err := rpc.HealthCheck(ctx, &pluginpb.HealthCheckRequest{}); err != nil {
+		return fmt.Errorf("插件健康检查失败: %s", status.Convert(err).Message())
+	}
+	return nil
+}
+
+// Invoke 调用插件能力方法，租户信息与请求ID从上下文读取
+func (c *Client) Invoke(ctx context.Context, method string, args map[string]string, payload []byte) (string, error) {
+	rpc, err := c.client()
+	if err != nil {
+		return "", err
+	}
+	req := &pluginpb.InvokeRequest{
+		Method:    method,
+		RequestId: tenant.GetRequestIDFromContext(ctx),
+		Args:      args,
+		Payload:   payload,
+	}
+	if tenantInfo, err := tenant.GetTenantFromContext(ctx); err == nil {
+		req.Tenant = &pluginpb.Tenant{
+			TenantId:    tenantInfo.TenantId,
+			UserToken:   tenantInfo.UserToken,
+			OrgId:       tenantInfo.OrgID,
+			ProjectUuid: tenantInfo.ProjectUUID,
+		}
+	}
+	resp, err := rpc.Invoke(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("插件调用 %s 失败: %s", method, status.Convert(err).Message())
+	}
+	return resp.Result, nil
+}
+
+// Stop 通知插件进程退出，超时后强制结束；停止后不再自动重启
+// 启动过程中调用时，启动完成后进程随即结束
+func (c *Client) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	c.running = false
+	proc := c.proc
+	c.mu.Unlock()
+	if proc == nil {
+		return nil
+	}
+
+	stopCtx, cancel := context.WithTimeout(ctx, c.opts.StopTimeout)
+	defer cancel()
+	// 进程收到 Shutdown 后会断开连接，这里忽略返回错误
+	_, _ = proc.rpc.Shutdown(stopCtx, &pluginpb.ShutdownRequest{})
+	select {
+	case <-proc.exited:
+		return nil
+	case <-stopCtx.Done():
+		_ = proc.cmd.Process.Kill()
+		<-proc.exited
+		return fmt.Errorf("插件进程未在 %s 内退出，已强制结束", c.opts.StopTimeout)
+	}
+}
+
+// client 获取当前可用的 gRPC 客户端
+func (c *Client) client() (pluginpb.PluginClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.proc == nil {
+		if c.lastErr != nil {
+			return nil, fmt.Errorf("插件进程不可用: %w", c.lastErr)
+		}
+		return nil, errors.New("插件进程未启动")
+	}
+	return c.proc.rpc, nil
+}
+
+// launch 启动插件进程、建立连接并握手，不持有锁
+func (c *Client) launch(ctx context.Context) (*process, error) {
+	sockDir, err := os.MkdirTemp("", "drone-plugin-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建插件套接字目录失败: %w", err)
+	}
+	sockPath := filepath.Join(sockDir, "plugin.sock")
+
+	name := filepath.Base(c.opts.Path)
+	cmd := exec.Command(c.opts.Path, c.opts.Args...)
+	cmd.Env = append(os.Environ(), c.opts.Env...)
+	cmd.Env = append(cmd.Env,
+		MagicCookieKey+"="+MagicCookieValue,
+		SocketEnvKey+"="+sockPath,
+		fmt.Sprintf("%s=%d", VersionEnvKey, hostProtocolVersion),
+	)
+	cmd.Stdout = &logWriter{prefix: name}
+	cmd.Stderr = &logWriter{prefix: name}
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(sockDir)
+		return nil, fmt.Errorf("启动插件进程 %s 失败: %w", c.opts.Path, err)
+	}
+
+	proc := &process{cmd: cmd, exited: make(chan struct{}), sockDir: sockDir}
+	go func() {
+		waitErr := cmd.Wait()
+		close(proc.exited)
+		c.handleExit(proc, waitErr)
+	}()
+
+	conn, err := grpc.NewClient("unix://"+sockPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		_ = cmd.Process.Kill()
+		<-proc.exited
+		os.RemoveAll(sockDir)
+		return nil, fmt.Errorf("连接插件进程失败: %w", err)
+	}
+	proc.conn, proc.rpc = conn, pluginpb.NewPluginClient(conn)
+
+	info, err := handshake(ctx, proc.rpc, proc.exited, c.opts.StartTimeout)
+	if err == nil && info.ProtocolVersion != hostProtocolVersion {
+		err = fmt.Errorf("插件协议版本不匹配: 宿主 %d, 插件 %d", hostProtocolVersion, info.ProtocolVersion)
+	}
+	if err != nil {
+		proc.kill()
+		return nil, fmt.Errorf("插件 %s 握手失败: %w", name, err)
+	}
+	proc.info = info
+	return proc, nil
+}
+
+// handshake 轮询握手直到插件进程开始监听、进程退出或超时
+func handshake(ctx context.Context, rpc pluginpb.PluginClient, exited <-chan struct{}, timeout time.Duration) (*pluginpb.HandshakeResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		attemptCtx, attemptCancel := context.WithTimeout(ctx, time.Second)
+		info, err := rpc.Handshake(attemptCtx, &pluginpb.HandshakeRequest{ProtocolVersion: hostProtocolVersion})
+		attemptCancel()
+		if err == nil {
+			return info, nil
+		}
+		select {
+		case <-exited:
+			return nil, errors.New("插件进程已退出")
+		case <-ctx.Done():
+			return nil, fmt.Errorf("等待插件响应超时: %w", err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// handleExit 插件进程退出回调：清理连接，非预期退出时按退避策略重启
+func (c *Client) handleExit(proc *process, waitErr error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.proc != proc {
+		// 未登记的进程（握手失败或启动期间被停止），由启动流程清理
+		return
+	}
+	proc.conn.Close()
+	os.RemoveAll(proc.sockDir)
+	c.proc = nil
+	if !c.running {
+		return
+	}
+
+	c.lastErr = fmt.Errorf("插件进程异常退出: %v", waitErr)
+	log.Printf("插件 %s 进程异常退出: %v", filepath.Base(c.opts.Path), waitErr)
+	go c.restart()
+}
+
+// restart 崩溃后重启插件进程并重新下发配置，启动与握手在锁外进行
+func (c *Client) restart() {
+	backoff := c.opts.RestartBackoff
+	for {
+		c.mu.Lock()
+		if !c.running || c.proc != nil || c.starting != nil {
+			c.mu.Unlock()
+			return
+		}
+		if c.opts.MaxRestarts < 0 || c.restarts >= c.opts.MaxRestarts {
+			c.lastErr = fmt.Errorf("插件进程重启次数超过上限(%d): %w", c.opts.MaxRestarts, c.lastErr)
+			log.Printf("插件 %s 不再重启: %v", filepath.Base(c.opts.Path), c.lastErr)
+			c.mu.Unlock()
+			return
+		}
+		c.restarts++
+		c.mu.Unlock()
+
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxRestartBackoff)
+
+		c.mu.Lock()
+		if !c.running || c.proc != nil || c.starting != nil {
+			c.mu.Unlock()
+			return
+		}
+		c.starting = make(chan struct{})
+		cfg := c.cfg
+		c.mu.Unlock()
+
+		proc, err := c.launch(context.Background())
+		if err == nil && cfg != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), c.opts.StartTimeout)
+			_, err = proc.rpc.Init(ctx, &pluginpb.InitRequest{Config: cfg})
+			cancel()
+			if err != nil {
+				proc.kill()
+				err = fmt.Errorf("插件重新初始化失败: %s", status.Convert(err).Message())
+			}
+		}
+
+		c.mu.Lock()
+		close(c.starting)
+		c.starting = nil
+		if err == nil {
+			err = c.installLocked(proc)
+		}
+		if err == nil {
+			c.mu.Unlock()
+			log.Printf("插件 %s 已重启", filepath.Base(c.opts.Path))
+			return
+		}
+		running := c.running
+		if running {
+			c.lastErr = err
+		}
+		c.mu.Unlock()
+		if !running {
+			return
+		}
+		log.Printf("插件 %s 重启失败: %v", filepath.Base(c.opts.Path), err)
+	}
+}
+
+// logWriter 将插件进程输出转发到宿主日志
+type logWriter struct {
+	prefix string
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		log.Printf("[plugin %s] %s", w.prefix, line)
+	}
+	return len(p), nil
+}
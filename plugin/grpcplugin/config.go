@@ -0,0 +1,108 @@
+package grpcplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/plugin"
+)
+
+// loaded 配置文件声明并已注册的进程外插件
+type loaded struct {
+	pluginType plugin.PluginType
+	opts       Options
+	client     *Client
+}
+
+var (
+	loadMu sync.Mutex
+	loads  = make(map[string]*loaded) // key: 可执行文件路径
+)
+
+// ApplyConfig 加载 Plugins 段中配置了 path 的进程外插件，再交由 plugin.ApplyConfig 启用全部插件，启动与配置重新加载时调用
+//   - 新声明或 args、env 有变化的插件启动进程完成握手并注册，插件类型取自握手结果；type 非空时须与之一致
+//   - 上次声明、本次未声明的进程外插件被卸载，插件进程随之停止
+//
+// 加载失败的插件不影响其余插件，全部错误合并后返回
+func ApplyConfig(ctx context.Context, decls []config.PluginConfig) error {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	var errs []error
+	resolved := make([]config.PluginConfig, 0, len(decls))
+	declared := make(map[string]bool)
+	for _, decl := range decls {
+		if decl.Path == "" {
+			resolved = append(resolved, decl)
+			continue
+		}
+		if declared[decl.Path] {
+			errs = append(errs, fmt.Errorf("进程外插件 %s 重复声明", decl.Path))
+			continue
+		}
+		declared[decl.Path] = true
+		pluginType, err := load(ctx, decl)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		decl.Name, decl.Type, decl.Path = "", string(pluginType), ""
+		resolved = append(resolved, decl)
+	}
+	for path, entry := range loads {
+		if !declared[path] {
+			log.Printf("进程外插件 %s 不再声明，卸载插件 %s", path, entry.pluginType)
+			unload(path)
+		}
+	}
+
+	if err := plugin.ApplyConfig(ctx, resolved); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// load 注册声明的进程外插件，已按相同参数注册时直接返回插件类型，参数变化时先卸载再重新注册，调用方需持有 loadMu
+func load(ctx context.Context, decl config.PluginConfig) (plugin.PluginType, error) {
+	opts := Options{Path: decl.Path, Args: decl.Args, Env: decl.Env}
+	if entry, ok := loads[decl.Path]; ok {
+		if reflect.DeepEqual(entry.opts, opts) {
+			return entry.pluginType, checkDeclared(decl, entry.pluginType)
+		}
+		log.Printf("进程外插件 %s 启动参数已变化，重新注册", decl.Path)
+		unload(decl.Path)
+	}
+
+	pluginType, client, err := register(ctx, opts)
+	if err != nil {
+		return "", fmt.Errorf("加载进程外插件 %s 失败: %w", decl.Path, err)
+	}
+	loads[decl.Path] = &loaded{pluginType: pluginType, opts: opts, client: client}
+	return pluginType, checkDeclared(decl, pluginType)
+}
+
+// checkDeclared 配置中的 type、name 须与插件握手声明的类型一致，进程外插件不支持命名实例
+func checkDeclared(decl config.PluginConfig, pluginType plugin.PluginType) error {
+	for _, declared := range []string{decl.Type, decl.Name} {
+		if declared != "" && plugin.PluginType(declared) != pluginType {
+			return fmt.Errorf("进程外插件 %s 声明的类型为 %s，与配置的 %s 不一致", decl.Path, pluginType, declared)
+		}
+	}
+	return nil
+}
+
+// unload 卸载进程外插件并停止插件进程，调用方需持有 loadMu
+func unload(path string) {
+	entry := loads[path]
+	delete(loads, path)
+	plugin.Unload(entry.pluginType)
+	// 插件未启用时没有实例负责停止进程，这里兜底
+	if err := entry.client.Stop(context.Background()); err != nil {
+		log.Printf("停止插件进程 %s 失败: %v", path, err)
+	}
+}
@@ -0,0 +1,203 @@
+package grpcplugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+	"gitee.com/jamespi/drone_dispatch/plugin"
+)
+
+// Register 启动插件进程完成版本握手，按插件声明的能力注册到插件中心
+// 握手后插件进程即停止，启用插件时（Init）再启动；禁用或卸载插件时停止进程
+// 进程生命周期由单独注册的 processOwner 实例负责，各能力代理不实现 Init、Stop，避免同一进程被重复启动与停止
+func Register(ctx context.Context, opts Options) (plugin.PluginType, error) {
+	pluginType, _, err := register(ctx, opts)
+	return pluginType, err
+}
+
+// register 注册进程外插件，同时返回持有插件进程的客户端
+func register(ctx context.Context, opts Options) (plugin.PluginType, *Client, error) {
+	client := NewClient(opts)
+	info, err := client.Start(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := client.Stop(ctx); err != nil {
+		log.Printf("停止插件进程失败: %v", err)
+	}
+	if info.PluginType == "" {
+		return "", nil, fmt.Errorf("插件 %s 未声明插件类型", opts.Path)
+	}
+
+	pluginType := plugin.PluginType(info.PluginType)
+	for _, existing := range plugin.PluginsList() {
+		if existing.PluginType == pluginType && existing.Meta.Path != opts.Path {
+			return "", nil, fmt.Errorf("插件 %s 声明的类型 %s 已被其他插件注册", opts.Path, pluginType)
+		}
+	}
+	capabilities := make(map[string]bool, len(info.Capabilities))
+	for _, capability := range info.Capabilities {
+		capabilities[capability] = true
+	}
+
+	core := &remote{client: client, locator: capabilities[CapabilityDeviceLocator]}
+	registered := 0
+	for capability, ifaceType := range capabilityIfaces {
+		if !capabilities[capability] {
+			continue
+		}
+		build := proxyBuilder(capability)
+		plugin.RegisterPlugin(pluginType, ifaceType, func() interface{} { return build(core) })
+		registered++
+	}
+	if registered == 0 {
+		return "", nil, fmt.Errorf("插件 %s 未声明可用能力: %v", pluginType, info.Capabilities)
+	}
+	plugin.RegisterPlugin(pluginType, processOwnerType, func() interface{} { return &lifecycle{client: client} })
+
+	dependencies := make([]plugin.PluginType, len(info.Dependencies))
+	for i, dep := range info.Dependencies {
+		dependencies[i] = plugin.PluginType(dep)
+	}
+	sortedCapabilities := append([]string{}, info.Capabilities...)
+	sort.Strings(sortedCapabilities)
+	plugin.RegisterPluginMeta(pluginType, plugin.PluginMeta{
+		Version:      info.Version,
+		Vendor:       info.Vendor,
+		Description:  info.Description,
+		Dependencies: dependencies,
+		Capabilities: sortedCapabilities,
+		Path:         opts.Path,
+	})
+	return pluginType, client, nil
+}
+
+// processOwner 插件进程生命周期的持有者，每个进程插件只注册一个
+type processOwner interface {
+	processOwner()
+}
+
+var processOwnerType = reflect.TypeOf((*processOwner)(nil)).Elem()
+
+// lifecycle 负责启动、停止插件进程与健康检查
+type lifecycle struct {
+	client *Client
+}
+
+func (l *lifecycle) processOwner() {}
+
+// Init 启动插件进程并下发配置
+func (l *lifecycle) Init(ctx context.Context, cfg map[string]string) error {
+	return l.client.Init(ctx, cfg)
+}
+
+// Stop 停止插件进程
+func (l *lifecycle) Stop(ctx context.Context) error {
+	return l.client.Stop(ctx)
+}
+
+// HealthCheck 插件健康检查
+func (l *lifecycle) HealthCheck(ctx context.Context) error {
+	return l.client.HealthCheck(ctx)
+}
+
+// remote 进程外插件代理的公共部分，实现设备定位与调用，同一插件的全部能力代理共用
+type remote struct {
+	client  *Client
+	locator bool // 插件是否声明了 device_locator 能力
+}
+
+// HasDevice 设备是否由该插件管理，未声明 device_locator 能力的插件一律返回false
+func (r *remote) HasDevice(ctx context.Context, deviceSn string) (bool, error) {
+	if !r.locator {
+		return false, nil
+	}
+	result, err := r.client.Invoke(ctx, MethodHasDevice, map[string]string{ArgDeviceSn: deviceSn}, nil)
+	if err != nil {
+		return false, err
+	}
+	has, err := strconv.ParseBool(result)
+	if err != nil {
+		return false, fmt.Errorf("解析插件返回值失败: %w", err)
+	}
+	return has, nil
+}
+
+// invokePayload 读取请求体后调用插件
+func (r *remote) invokePayload(ctx context.Context, method string, args map[string]string, payLoad io.Reader) (string, error) {
+	var payload []byte
+	if payLoad != nil {
+		var err error
+		if payload, err = io.ReadAll(payLoad); err != nil {
+			return "", fmt.Errorf("读取请求体失败: %w", err)
+		}
+	}
+	return r.client.Invoke(ctx, method, args, payload)
+}
+
+// taskCreatorProxy 代理 service.TaskCreator
+type taskCreatorProxy struct{ *remote }
+
+func (p taskCreatorProxy) CreateFlightTask(ctx context.Context, payLoad io.Reader) (string, error) {
+	return p.invokePayload(ctx, MethodCreateFlightTask, nil, payLoad)
+}
+
+// liveStreamerProxy 代理 service.LiveStreamer
+type liveStreamerProxy struct{ *remote }
+
+func (p liveStreamerProxy) LiveStreamStart(ctx context.Context, payLoad io.Reader) (string, error) {
+	return p.invokePayload(ctx, MethodLiveStreamStart, nil, payLoad)
+}
+
+// deviceControllerProxy 代理 service.DeviceController
+type deviceControllerProxy struct{ *remote }
+
+func (p deviceControllerProxy) UpdateDeviceCommand(ctx context.Context, deviceSn string, payLoad io.Reader) (string, error) {
+	return p.invokePayload(ctx, MethodUpdateDeviceCommand, map[string]string{ArgDeviceSn: deviceSn}, payLoad)
+}
+
+// telemetrySourceProxy 代理 service.TelemetrySource
+type telemetrySourceProxy struct{ *remote }
+
+func (p telemetrySourceProxy) GetDeviceState(ctx context.Context, deviceSn string) (string, error) {
+	resp, err := p.client.Invoke(ctx, MethodGetDeviceState, map[string]string{ArgDeviceSn: deviceSn}, nil)
+	if err != nil {
+		return "", err
+	}
+	// 插件按 WGS-84 返回，由宿主统一转换为调用方需要的坐标系
+	converted, err := geo.ConvertJSON([]byte(resp), geo.WGS84, geo.CoordSystemFrom(ctx))
+	if err != nil {
+		return "", fmt.Errorf("坐标系转换失败: %w", err)
+	}
+	return string(converted), nil
+}
+
+// mediaProviderProxy 代理 service.MediaProvider
+type mediaProviderProxy struct{ *remote }
+
+func (p mediaProviderProxy) GetFlightTaskMedia(ctx context.Context, taskUUID string) (string, error) {
+	return p.client.Invoke(ctx, MethodGetFlightTaskMedia, map[string]string{ArgTaskUUID: taskUUID}, nil)
+}
+
+// proxyBuilder 按能力返回代理构造函数
+func proxyBuilder(capability string) func(*remote) interface{} {
+	switch capability {
+	case CapabilityTaskCreator:
+		return func(r *remote) interface{} { return taskCreatorProxy{r} }
+	case CapabilityLiveStreamer:
+		return func(r *remote) interface{} { return liveStreamerProxy{r} }
+	case CapabilityDeviceController:
+		return func(r *remote) interface{} { return deviceControllerProxy{r} }
+	case CapabilityTelemetrySource:
+		return func(r *remote) interface{} { return telemetrySourceProxy{r} }
+	case CapabilityMediaProvider:
+		return func(r *remote) interface{} { return mediaProviderProxy{r} }
+	}
+	return func(r *remote) interface{} { return r }
+}
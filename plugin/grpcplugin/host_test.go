@@ -0,0 +1,160 @@
+package grpcplugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/plugin"
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// samplePlugin 编译好的 cmd/sampleplugin 可执行文件
+var samplePlugin string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "sampleplugin-*")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	samplePlugin = filepath.Join(dir, "sampleplugin")
+	build := exec.Command("go", "build", "-o", samplePlugin, "gitee.com/jamespi/drone_dispatch/cmd/sampleplugin")
+	if out, err := build.CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "编译示例插件失败: %v\n%s", err, out)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func findPlugin(pluginType plugin.PluginType) (plugin.PluginInfo, bool) {
+	for _, info := range plugin.PluginsList() {
+		if info.PluginType == pluginType {
+			return info, true
+		}
+	}
+	return plugin.PluginInfo{}, false
+}
+
+// eventually 在超时前轮询直到 done 返回 true
+func eventually(t *testing.T, name string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待%s超时", name)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func running(c *Client) *process {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.proc
+}
+
+// TestApplyConfigHandshake 配置了 path 的插件经握手注册并启用，参数变化时重新注册，移除声明后卸载并停止进程
+func TestApplyConfigHandshake(t *testing.T) {
+	ctx := context.Background()
+	decl := config.PluginConfig{Path: samplePlugin, Settings: map[string]string{"devices": "SN1,SN2"}}
+	if err := ApplyConfig(ctx, []config.PluginConfig{decl}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ApplyConfig(ctx, nil) })
+
+	info, ok := findPlugin("sample")
+	if !ok {
+		t.Fatal("PluginsList 中没有进程外插件 sample")
+	}
+	if info.Status != service.PluginEnabled || info.Meta.Path != samplePlugin || info.Meta.Version != "0.1.0" {
+		t.Errorf("插件信息 = %+v", info)
+	}
+	if strings.Join(info.Meta.Capabilities, ",") != "device_locator,telemetry" {
+		t.Errorf("插件能力 %v，应为 device_locator、telemetry", info.Meta.Capabilities)
+	}
+	telemetry, ok := plugin.Get[service.TelemetrySource]("sample")
+	if !ok {
+		t.Fatal("获取进程外插件的 TelemetrySource 失败")
+	}
+	if state, err := telemetry.GetDeviceState(ctx, "SN2"); err != nil || !strings.Contains(state, `"sn":"SN2"`) {
+		t.Errorf("GetDeviceState = %s, %v", state, err)
+	}
+
+	first := loads[samplePlugin].client
+	decl.Args = []string{"--region", "cn"}
+	if err := ApplyConfig(ctx, []config.PluginConfig{decl}); err != nil {
+		t.Fatal(err)
+	}
+	second := loads[samplePlugin].client
+	if second == first || running(first) != nil || running(second) == nil {
+		t.Error("启动参数变化后应停止原进程并重新注册启动")
+	}
+
+	decl.Type = "xag"
+	if err := ApplyConfig(ctx, []config.PluginConfig{decl}); err == nil || !strings.Contains(err.Error(), "不一致") {
+		t.Errorf("type 与握手类型不一致时返回 %v", err)
+	}
+
+	if err := ApplyConfig(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := findPlugin("sample"); ok {
+		t.Error("移除声明后插件应被卸载")
+	}
+	if running(second) != nil || len(loads) != 0 {
+		t.Error("移除声明后插件进程应停止")
+	}
+}
+
+// TestVersionMismatch 协议版本不一致时插件进程拒绝启动，注册失败且不出现在插件列表中
+func TestVersionMismatch(t *testing.T) {
+	hostProtocolVersion = ProtocolVersion + 1
+	t.Cleanup(func() { hostProtocolVersion = ProtocolVersion })
+
+	_, err := Register(context.Background(), Options{Path: samplePlugin, StartTimeout: 5 * time.Second})
+	if err == nil || !strings.Contains(err.Error(), "握手失败") {
+		t.Fatalf("版本不一致时 Register 返回 %v", err)
+	}
+	if _, ok := findPlugin("sample"); ok {
+		t.Error("握手失败的插件不应注册")
+	}
+}
+
+// TestRestartAfterCrash 插件进程崩溃后按退避重启并重新下发配置，超过重启上限后不再重启
+func TestRestartAfterCrash(t *testing.T) {
+	ctx := context.Background()
+	client := NewClient(Options{Path: samplePlugin, MaxRestarts: 1, RestartBackoff: 10 * time.Millisecond})
+	if err := client.Init(ctx, map[string]string{"devices": "SN1"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Stop(ctx) })
+
+	crashed := running(client)
+	crashed.cmd.Process.Kill()
+	eventually(t, "插件重启", func() bool {
+		proc := running(client)
+		return proc != nil && proc != crashed
+	})
+	if has, err := client.Invoke(ctx, MethodHasDevice, map[string]string{ArgDeviceSn: "SN1"}, nil); err != nil || has != "true" {
+		t.Errorf("重启后应重新下发配置: HasDevice = %s, %v", has, err)
+	}
+
+	running(client).cmd.Process.Kill()
+	eventually(t, "达到重启上限", func() bool {
+		err := client.HealthCheck(ctx)
+		return err != nil && strings.Contains(err.Error(), "重启次数超过上限")
+	})
+	if running(client) != nil {
+		t.Error("超过重启上限后不应再启动插件进程")
+	}
+}
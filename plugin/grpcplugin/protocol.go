@@ -0,0 +1,59 @@
+// Package grpcplugin 进程外插件：宿主启动插件可执行文件，经版本握手后通过本地套接字以 gRPC 调用
+// 厂商无需修改本仓库即可发布适配器（例如 MMC、XAG），插件进程崩溃后自动重启，
+// 并以与内置插件相同的方式出现在 plugin.PluginsList 中
+package grpcplugin
+
+import (
+	"reflect"
+
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// ProtocolVersion 当前插件协议版本，握手时双方必须一致
+const ProtocolVersion uint32 = 1
+
+// hostProtocolVersion 宿主启动插件时声明的协议版本，测试中修改以模拟版本不一致
+var hostProtocolVersion = ProtocolVersion
+
+// 宿主通过环境变量向插件进程传递握手信息
+const (
+	MagicCookieKey   = "DRONE_DISPATCH_PLUGIN"                // 魔术变量，防止插件被直接运行
+	MagicCookieValue = "3f1c9a7e-5d4b-4e2a-9b8c-drone-plugin" // 魔术变量取值
+	SocketEnvKey     = "DRONE_DISPATCH_PLUGIN_SOCKET"         // 插件监听的本地套接字路径
+	VersionEnvKey    = "DRONE_DISPATCH_PLUGIN_PROTOCOL"       // 宿主支持的协议版本
+)
+
+// 插件能力名称，与 service 包的能力接口一一对应
+const (
+	CapabilityTaskCreator      = "flight_task"    // service.TaskCreator
+	CapabilityLiveStreamer     = "live_stream"    // service.LiveStreamer
+	CapabilityDeviceController = "device_control" // service.DeviceController
+	CapabilityTelemetrySource  = "telemetry"      // service.TelemetrySource
+	CapabilityMediaProvider    = "media"          // service.MediaProvider
+	CapabilityDeviceLocator    = "device_locator" // service.DeviceLocator
+)
+
+// 能力方法名
+const (
+	MethodCreateFlightTask    = "CreateFlightTask"
+	MethodLiveStreamStart     = "LiveStreamStart"
+	MethodUpdateDeviceCommand = "UpdateDeviceCommand"
+	MethodGetDeviceState      = "GetDeviceState"
+	MethodGetFlightTaskMedia  = "GetFlightTaskMedia"
+	MethodHasDevice           = "HasDevice"
+)
+
+// 具名参数
+const (
+	ArgDeviceSn = "device_sn"
+	ArgTaskUUID = "task_uuid"
+)
+
+// capabilityIfaces 能力名称对应的接口类型，宿主按握手返回的能力注册接口
+var capabilityIfaces = map[string]reflect.Type{
+	CapabilityTaskCreator:      reflect.TypeOf((*service.TaskCreator)(nil)).Elem(),
+	CapabilityLiveStreamer:     reflect.TypeOf((*service.LiveStreamer)(nil)).Elem(),
+	CapabilityDeviceController: reflect.TypeOf((*service.DeviceController)(nil)).Elem(),
+	CapabilityTelemetrySource:  reflect.TypeOf((*service.TelemetrySource)(nil)).Elem(),
+	CapabilityMediaProvider:    reflect.TypeOf((*service.MediaProvider)(nil)).Elem(),
+}
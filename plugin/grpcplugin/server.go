@@ -0,0 +1,196 @@
+package grpcplugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	pluginpb "gitee.com/jamespi/drone_dispatch/plugin/grpcplugin/proto"
+	"gitee.com/jamespi/drone_dispatch/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Meta 插件侧声明的元信息
+type Meta struct {
+	PluginType   string   // 插件类型，例如 mmc、xag，需全局唯一
+	Version      string   // 插件版本
+	Vendor       string   // 厂商
+	Description  string   // 插件描述
+	Dependencies []string // 依赖的插件类型
+}
+
+// Serve 插件进程入口：在宿主指定的本地套接字上提供 gRPC 服务，直到宿主通知退出
+// impl 实现 service 包中的能力接口（TaskCreator、LiveStreamer 等）即声明对应能力，
+// 可选实现 service.PluginInitializer、service.PluginHealthChecker
+func Serve(meta Meta, impl interface{}) error {
+	if os.Getenv(MagicCookieKey) != MagicCookieValue {
+		return errors.New("本程序是 drone_dispatch 插件，需要由宿主进程启动")
+	}
+	if version := os.Getenv(VersionEnvKey); version != strconv.FormatUint(uint64(ProtocolVersion), 10) {
+		return fmt.Errorf("插件协议版本不匹配: 宿主 %s, 插件 %d", version, ProtocolVersion)
+	}
+	sockPath := os.Getenv(SocketEnvKey)
+	if sockPath == "" {
+		return fmt.Errorf("环境变量 %s 未设置", SocketEnvKey)
+	}
+
+	listener, err := net.Listen("unix", sockPath)
+	if err != nil {
+		return fmt.Errorf("插件监听套接字失败: %w", err)
+	}
+	server := grpc.NewServer()
+	pluginpb.RegisterPluginServer(server, newPluginServer(meta, impl, server))
+	return server.Serve(listener)
+}
+
+// pluginServer 插件侧 gRPC 服务，将调用分发到插件实现
+type pluginServer struct {
+	pluginpb.UnimplementedPluginServer
+	meta     Meta
+	impl     interface{}
+	server   *grpc.Server
+	stopOnce sync.Once
+}
+
+func newPluginServer(meta Meta, impl interface{}, server *grpc.Server) *pluginServer {
+	return &pluginServer{meta: meta, impl: impl, server: server}
+}
+
+// Handshake 返回插件元信息，能力由 impl 实现的接口推导
+func (s *pluginServer) Handshake(ctx context.Context, req *pluginpb.HandshakeRequest) (*pluginpb.HandshakeResponse, error) {
+	return &pluginpb.HandshakeResponse{
+		ProtocolVersion: ProtocolVersion,
+		PluginType:      s.meta.PluginType,
+		Version:         s.meta.Version,
+		Vendor:          s.meta.Vendor,
+		Description:     s.meta.Description,
+		Dependencies:    s.meta.Dependencies,
+		Capabilities:    Capabilities(s.impl),
+	}, nil
+}
+
+// Init 调用插件实现的 Init
+func (s *pluginServer) Init(ctx context.Context, req *pluginpb.InitRequest) (*pluginpb.InitResponse, error) {
+	if initializer, ok := s.impl.(service.PluginInitializer); ok {
+		if err := initializer.Init(ctx, req.Config); err != nil {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+	}
+	return &pluginpb.InitResponse{}, nil
+}
+
+// HealthCheck 调用插件实现的 HealthCheck
+func (s *pluginServer) HealthCheck(ctx context.Context, req *pluginpb.HealthCheckRequest) (*pluginpb.HealthCheckResponse, error) {
+	if checker, ok := s.impl.(service.PluginHealthChecker); ok {
+		if err := checker.HealthCheck(ctx); err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+	}
+	return &pluginpb.HealthCheckResponse{}, nil
+}
+
+// Invoke 按方法名分发到插件实现的能力接口
+func (s *pluginServer) Invoke(ctx context.Context, req *pluginpb.InvokeRequest) (*pluginpb.InvokeResponse, error) {
+	if req.Tenant != nil {
+		tenantInfo := tenant.NewTenantInfo(req.Tenant.TenantId, req.Tenant.UserToken, req.Tenant.ProjectUuid)
+		tenantInfo.OrgID = req.Tenant.OrgId
+		ctx = tenant.WithTenant(ctx, tenantInfo)
+	}
+	if req.RequestId != "" {
+		ctx = tenant.WithRequestID(ctx, req.RequestId)
+	}
+
+	result, err := s.dispatch(ctx, req)
+	if errors.Is(err, errUnsupportedMethod) {
+		return nil, status.Errorf(codes.Unimplemented, "插件不支持方法 %s", req.Method)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+	return &pluginpb.InvokeResponse{Result: result}, nil
+}
+
+// Shutdown 返回后优雅停止服务，Serve 随之返回
+func (s *pluginServer) Shutdown(ctx context.Context, req *pluginpb.ShutdownRequest) (*pluginpb.ShutdownResponse, error) {
+	s.stopOnce.Do(func() {
+		go s.server.GracefulStop()
+	})
+	return &pluginpb.ShutdownResponse{}, nil
+}
+
+// dispatch 调用插件实现的具体方法
+func (s *pluginServer) dispatch(ctx context.Context, req *pluginpb.InvokeRequest) (string, error) {
+	deviceSn := req.Args[ArgDeviceSn]
+	switch req.Method {
+	case MethodCreateFlightTask:
+		if creator, ok := s.impl.(service.TaskCreator); ok {
+			return creator.CreateFlightTask(ctx, readPayload(req.Payload))
+		}
+	case MethodLiveStreamStart:
+		if streamer, ok := s.impl.(service.LiveStreamer); ok {
+			return streamer.LiveStreamStart(ctx, readPayload(req.Payload))
+		}
+	case MethodUpdateDeviceCommand:
+		if controller, ok := s.impl.(service.DeviceController); ok {
+			return controller.UpdateDeviceCommand(ctx, deviceSn, readPayload(req.Payload))
+		}
+	case MethodGetDeviceState:
+		if source, ok := s.impl.(service.TelemetrySource); ok {
+			return source.GetDeviceState(ctx, deviceSn)
+		}
+	case MethodGetFlightTaskMedia:
+		if provider, ok := s.impl.(service.MediaProvider); ok {
+			return provider.GetFlightTaskMedia(ctx, req.Args[ArgTaskUUID])
+		}
+	case MethodHasDevice:
+		if locator, ok := s.impl.(service.DeviceLocator); ok {
+			has, err := locator.HasDevice(ctx, deviceSn)
+			return strconv.FormatBool(has), err
+		}
+	}
+	return "", errUnsupportedMethod
+}
+
+// Capabilities 根据实现的接口推导插件能力
+func Capabilities(impl interface{}) []string {
+	var capabilities []string
+	if _, ok := impl.(service.TaskCreator); ok {
+		capabilities = append(capabilities, CapabilityTaskCreator)
+	}
+	if _, ok := impl.(service.LiveStreamer); ok {
+		capabilities = append(capabilities, CapabilityLiveStreamer)
+	}
+	if _, ok := impl.(service.DeviceController); ok {
+		capabilities = append(capabilities, CapabilityDeviceController)
+	}
+	if _, ok := impl.(service.TelemetrySource); ok {
+		capabilities = append(capabilities, CapabilityTelemetrySource)
+	}
+	if _, ok := impl.(service.MediaProvider); ok {
+		capabilities = append(capabilities, CapabilityMediaProvider)
+	}
+	if _, ok := impl.(service.DeviceLocator); ok {
+		capabilities = append(capabilities, CapabilityDeviceLocator)
+	}
+	return capabilities
+}
+
+// errUnsupportedMethod 插件未实现的方法
+var errUnsupportedMethod = errors.New("插件不支持该方法")
+
+// readPayload 将请求体转为 io.Reader，空请求体返回nil
+func readPayload(payload []byte) io.Reader {
+	if len(payload) == 0 {
+		return nil
+	}
+	return bytes.NewReader(payload)
+}
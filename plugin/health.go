@@ -0,0 +1,218 @@
+// 插件健康监控与故障切换
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// StatusEvent 插件状态变化事件
+type StatusEvent struct {
+	PluginType PluginType
+	From       service.PluginStatus
+	To         service.PluginStatus
+	Err        error // 导致状态变化的错误（健康检查失败原因），恢复或手动操作时为nil
+	Time       time.Time
+}
+
+// statusListeners 状态变化订阅者
+var statusListeners = struct {
+	sync.RWMutex
+	nextID   int
+	handlers map[int]func(StatusEvent)
+}{handlers: make(map[int]func(StatusEvent))}
+
+// OnStatusChange 订阅插件状态变化事件，返回取消订阅函数
+// 回调在触发状态变化的协程中同步执行，耗时操作请自行异步处理
+func OnStatusChange(handler func(StatusEvent)) (unsubscribe func()) {
+	statusListeners.Lock()
+	defer statusListeners.Unlock()
+	id := statusListeners.nextID
+	statusListeners.nextID++
+	statusListeners.handlers[id] = handler
+	return func() {
+		statusListeners.Lock()
+		defer statusListeners.Unlock()
+		delete(statusListeners.handlers, id)
+	}
+}
+
+// publishStatus 通知订阅者，调用方不能持有注册中心锁
+func publishStatus(event *StatusEvent) {
+	if event == nil {
+		return
+	}
+	statusListeners.RLock()
+	handlers := make([]func(StatusEvent), 0, len(statusListeners.handlers))
+	for _, handler := range statusListeners.handlers {
+		handlers = append(handlers, handler)
+	}
+	statusListeners.RUnlock()
+
+	for _, handler := range handlers {
+		handler(*event)
+	}
+}
+
+// setStatusLocked 更新插件状态，状态未变化时返回nil，调用方需持有写锁
+func (r *Registry) setStatusLocked(pluginType PluginType, status service.PluginStatus, err error) *StatusEvent {
+	from := r.Status[pluginType]
+	if from == status {
+		return nil
+	}
+	r.Status[pluginType] = status
+	return &StatusEvent{PluginType: pluginType, From: from, To: status, Err: err, Time: time.Now()}
+}
+
+// SetFailover 设置能力查询是否故障切换：开启后 FindCapable 系列查询跳过失败状态的插件（默认开启）
+func SetFailover(enabled bool) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.noFailover = !enabled
+}
+
+// HealthOptions 健康监控参数
+type HealthOptions struct {
+	Interval         time.Duration // 探测间隔，默认30秒
+	Timeout          time.Duration // 单次探测超时，默认5秒
+	FailureThreshold int           // 连续失败多少次标记为失败，之前为降级，默认3次
+}
+
+// HealthMonitor 周期性探测已启用插件的健康状态，在 enabled、degraded、failed 之间切换
+type HealthMonitor struct {
+	opts     HealthOptions
+	mu       sync.Mutex
+	failures map[PluginType]int // 连续失败次数
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewHealthMonitor 创建健康监控
+func NewHealthMonitor(opts HealthOptions) *HealthMonitor {
+	if opts.Interval <= 0 {
+		opts.Interval = 30 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 3
+	}
+	return &HealthMonitor{opts: opts, failures: make(map[PluginType]int)}
+}
+
+// Start 启动后台探测
+func (m *HealthMonitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.run(ctx, m.done)
+}
+
+// Stop 停止后台探测
+func (m *HealthMonitor) Stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// run 探测循环
+func (m *HealthMonitor) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+	for {
+		m.ProbeOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProbeOnce 立即探测一次全部已启用插件并更新状态
+func (m *HealthMonitor) ProbeOnce(ctx context.Context) {
+	registry.mu.RLock()
+	var targets []PluginType
+	for pluginType, status := range registry.Status {
+		if isRunning(status) {
+			targets = append(targets, pluginType)
+		}
+	}
+	registry.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, pluginType := range targets {
+		wg.Add(1)
+		go func(pluginType PluginType) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+			defer cancel()
+			m.apply(pluginType, HealthCheck(probeCtx, pluginType))
+		}(pluginType)
+	}
+	wg.Wait()
+}
+
+// apply 根据探测结果切换插件状态
+func (m *HealthMonitor) apply(pluginType PluginType, err error) {
+	m.mu.Lock()
+	var status service.PluginStatus
+	switch {
+	case err == nil:
+		delete(m.failures, pluginType)
+		status = service.PluginEnabled
+	case errors.Is(err, service.ErrPluginDegraded):
+		delete(m.failures, pluginType)
+		status = service.PluginDegraded
+	default:
+		m.failures[pluginType]++
+		status = service.PluginDegraded
+		if m.failures[pluginType] >= m.opts.FailureThreshold {
+			status = service.PluginFailed
+		}
+	}
+	m.mu.Unlock()
+
+	registry.mu.Lock()
+	var event *StatusEvent
+	// 探测期间插件可能已被禁用或卸载，此时不再改写状态
+	if isRunning(registry.Status[pluginType]) {
+		event = registry.setStatusLocked(pluginType, status, err)
+	}
+	registry.mu.Unlock()
+
+	if event != nil {
+		log.Printf("插件 %s 状态变化: %s -> %s (%v)", pluginType, event.From, event.To, err)
+		publishStatus(event)
+	}
+}
+
+// healthRank 健康状态排序权重，越小越优先
+func healthRank(status service.PluginStatus) int {
+	switch status {
+	case service.PluginEnabled:
+		return 0
+	case service.PluginDegraded:
+		return 1
+	}
+	return 2
+}
@@ -0,0 +1,166 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// probeState 测试插件健康检查的返回结果，由同一插件的全部实例共享
+type probeState struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (s *probeState) set(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// probedPlugin 健康检查结果可控、管理固定设备的测试插件
+type probedPlugin struct {
+	state    *probeState
+	deviceSn string
+}
+
+func (p *probedPlugin) Count() int64 { return 0 }
+
+func (p *probedPlugin) HealthCheck(ctx context.Context) error {
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+	return p.state.err
+}
+
+func (p *probedPlugin) HasDevice(ctx context.Context, deviceSn string) (bool, error) {
+	return deviceSn == p.deviceSn, nil
+}
+
+// registerProbed 注册并启用测试插件
+func registerProbed(t *testing.T, pluginType PluginType, priority int, deviceSn string) *probeState {
+	t.Helper()
+	state := &probeState{}
+	RegisterPlugin(pluginType, reflect.TypeOf((*counterAPI)(nil)).Elem(), func() interface{} {
+		return &probedPlugin{state: state, deviceSn: deviceSn}
+	})
+	RegisterPluginMeta(pluginType, PluginMeta{Priority: priority})
+	t.Cleanup(func() { Unload(pluginType) })
+	if err := Enable(pluginType); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Get[counterAPI](pluginType); !ok {
+		t.Fatalf("获取插件 %s 失败", pluginType)
+	}
+	return state
+}
+
+// TestHealthTransitions 降级错误直接降级，其他错误连续达到阈值后标记为失败，探测成功即恢复，已禁用的插件不再改写状态
+func TestHealthTransitions(t *testing.T) {
+	const pluginType PluginType = "test_health_transitions"
+	state := registerProbed(t, pluginType, 0, "")
+
+	var mu sync.Mutex
+	var events []string
+	unsubscribe := OnStatusChange(func(e StatusEvent) {
+		if e.PluginType == pluginType {
+			mu.Lock()
+			events = append(events, fmt.Sprintf("%s->%s", e.From, e.To))
+			mu.Unlock()
+		}
+	})
+	defer unsubscribe()
+
+	monitor := NewHealthMonitor(HealthOptions{FailureThreshold: 2})
+	down := errors.New("服务不可达")
+	steps := []struct {
+		name string
+		err  error
+		want service.PluginStatus
+	}{
+		{"健康", nil, service.PluginEnabled},
+		{"部分能力受限", fmt.Errorf("%w: OSD未更新", service.ErrPluginDegraded), service.PluginDegraded},
+		{"恢复", nil, service.PluginEnabled},
+		{"首次失败", down, service.PluginDegraded},
+		{"连续失败达到阈值", down, service.PluginFailed},
+		{"失败后恢复", nil, service.PluginEnabled},
+		{"恢复后重新计数", down, service.PluginDegraded},
+	}
+	for _, step := range steps {
+		state.set(step.err)
+		monitor.ProbeOnce(context.Background())
+		if status := findPlugin(t, pluginType).Status; status != step.want {
+			t.Fatalf("%s: 状态 %s，应为 %s", step.name, status, step.want)
+		}
+	}
+
+	mu.Lock()
+	got := events
+	mu.Unlock()
+	want := []string{"enabled->degraded", "degraded->enabled", "enabled->degraded", "degraded->failed", "failed->enabled", "enabled->degraded"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("状态事件 %v，应为 %v", got, want)
+	}
+
+	Disable(pluginType)
+	monitor.apply(pluginType, down)
+	if status := findPlugin(t, pluginType).Status; status != service.PluginDisabled {
+		t.Errorf("禁用后探测结果不应改写状态，实际 %s", status)
+	}
+}
+
+// TestFailover 同一设备优先选择健康且优先级高的插件，失败的插件被跳过，关闭故障切换后仍参与查询
+func TestFailover(t *testing.T) {
+	const (
+		primary  PluginType = "test_failover_primary"
+		backup   PluginType = "test_failover_backup"
+		deviceSn            = "7CTXN4A00B0009H"
+	)
+	primaryState := registerProbed(t, primary, 10, deviceSn)
+	registerProbed(t, backup, 0, deviceSn)
+	monitor := NewHealthMonitor(HealthOptions{FailureThreshold: 1})
+	ctx := context.Background()
+
+	selected := func() PluginType {
+		t.Helper()
+		capable, err := SelectForDevice[counterAPI](ctx, deviceSn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return capable.PluginType
+	}
+
+	if got := selected(); got != primary {
+		t.Fatalf("健康时应选择优先级高的 %s，实际 %s", primary, got)
+	}
+
+	primaryState.set(fmt.Errorf("%w: MQTT重连中", service.ErrPluginDegraded))
+	monitor.ProbeOnce(ctx)
+	if got := selected(); got != backup {
+		t.Errorf("降级的插件应排在健康插件之后，实际选择 %s", got)
+	}
+
+	primaryState.set(errors.New("MQTT未连接"))
+	monitor.ProbeOnce(ctx)
+	list, err := FindCapableForDevice[counterAPI](ctx, deviceSn)
+	if err != nil || len(list) != 1 || list[0].PluginType != backup {
+		t.Errorf("失败的插件应被跳过: %+v, %v", list, err)
+	}
+
+	SetFailover(false)
+	list, err = FindCapableForDevice[counterAPI](ctx, deviceSn)
+	SetFailover(true)
+	if err != nil || len(list) != 2 || list[1].PluginType != primary || list[1].Status != service.PluginFailed {
+		t.Errorf("关闭故障切换后失败的插件应排在最后参与查询: %+v, %v", list, err)
+	}
+
+	primaryState.set(nil)
+	monitor.ProbeOnce(ctx)
+	if got := selected(); got != primary {
+		t.Errorf("恢复后应切回 %s，实际 %s", primary, got)
+	}
+}
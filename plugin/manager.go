@@ -2,35 +2,159 @@
 package plugin
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"gitee.com/jamespi/drone_dispatch/config"
 	"gitee.com/jamespi/drone_dispatch/service"
+	"log"
 	"reflect"
+	"sort"
 )
 
 type PluginInfo struct {
 	PluginType PluginType
 	Interfaces []reflect.Type
 	Status     service.PluginStatus
+	Scopes     map[reflect.Type]InstanceScope // 各接口的实例作用域
+	Instances  int                            // 已创建的共享实例数
+	Error      error                          // 最近一次启用失败的原因（依赖/Init/Start错误）
+	Meta       PluginMeta                     // 插件元信息
+	Base       PluginType                     // 命名实例的模板插件类型，非命名实例为空
 }
 
-// 批量启用某些插件
-func LoadEnableList(selected []string) {
-	for _, pluginName := range selected {
-		Enable(PluginType(pluginName))
+// 批量启用某些插件，按依赖顺序启用（会自动带上依赖的插件）
+// 启用失败的插件不影响其余插件，未注册或启用失败的插件合并为一个错误返回
+func LoadEnableList(selected []string) error {
+	types := toPluginTypes(selected)
+	errs := checkRegistered(types)
+	order, err := ResolveStartupOrder(types)
+	if err != nil {
+		// 依赖解析失败时仍按原顺序逐个启用，未满足依赖的插件会在 Enable 中被拒绝
+		log.Printf("解析插件启动顺序失败: %v", err)
+		order = types
 	}
+	for _, pluginType := range order {
+		if !isRegistered(pluginType) {
+			continue
+		}
+		if err := Enable(pluginType); err != nil {
+			errs = append(errs, fmt.Errorf("启用插件 %s 失败: %w", pluginType, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-// 批量禁用某些插件
-func LoadDisabledList(selected []string) {
-	for _, pluginName := range selected {
-		Disable(PluginType(pluginName))
+// 批量禁用某些插件，按依赖顺序的逆序禁用，未注册的插件合并为一个错误返回
+func LoadDisabledList(selected []string) error {
+	types := toPluginTypes(selected)
+	order, err := ResolveStartupOrder(types)
+	if err != nil {
+		order = types
 	}
+	for i := len(order) - 1; i >= 0; i-- {
+		if contains(types, order[i]) {
+			Disable(order[i])
+		}
+	}
+	return errors.Join(checkRegistered(types)...)
 }
 
-// 批量卸载某些插件
-func LoadUnloadedList(selected []string) {
-	for _, pluginName := range selected {
-		Unload(PluginType(pluginName))
+// 批量卸载某些插件，未注册的插件合并为一个错误返回
+func LoadUnloadedList(selected []string) error {
+	types := toPluginTypes(selected)
+	errs := checkRegistered(types)
+	for _, pluginType := range types {
+		Unload(pluginType)
 	}
+	return errors.Join(errs...)
+}
+
+// ApplyConfig 按 config.yaml 的 Plugins 段声明启用插件，启动与配置重新加载时调用
+//   - 声明的插件按依赖顺序启用，配置有变化的已启用插件会先禁用再启用，使新配置经 Init 生效
+//   - 上次声明、本次未声明或 enabled: false 的插件会被禁用
+//   - name 与 type 不同时以 type 为模板注册命名实例（见 RegisterInstance）
+//   - SetPluginConfig 设置的运行时配置覆盖 settings 中的同名配置项，不会被重新加载清除
+//   - 配置了 path 的进程外插件需先经 grpcplugin.ApplyConfig 注册，直接传入时报错
+//
+// 未知插件与启用失败的插件不影响其余插件，全部错误合并后返回
+func ApplyConfig(ctx context.Context, decls []config.PluginConfig) error {
+	var errs []error
+	desired := make(map[PluginType]map[string]string)
+	var order, disabled []PluginType
+	for i, decl := range decls {
+		name := PluginType(decl.InstanceName())
+		if decl.Path != "" {
+			errs = append(errs, fmt.Errorf("进程外插件 %s 需通过 grpcplugin.ApplyConfig 加载", decl.Path))
+			continue
+		}
+		if name == "" {
+			errs = append(errs, fmt.Errorf("第 %d 个插件声明缺少 type", i+1))
+			continue
+		}
+		if _, dup := desired[name]; dup || contains(disabled, name) {
+			errs = append(errs, fmt.Errorf("插件 %s 重复声明", name))
+			continue
+		}
+		if decl.Type != "" && PluginType(decl.Type) != name {
+			if err := RegisterInstance(name, PluginType(decl.Type)); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		} else if !isRegistered(name) {
+			errs = append(errs, fmt.Errorf("未知插件: %s", name))
+			continue
+		}
+		if !decl.IsEnabled() {
+			disabled = append(disabled, name)
+			continue
+		}
+		desired[name] = decl.Settings
+		order = append(order, name)
+	}
+
+	registry.mu.Lock()
+	for pluginType := range registry.declared {
+		if _, ok := desired[pluginType]; !ok && !contains(disabled, pluginType) {
+			disabled = append(disabled, pluginType)
+		}
+	}
+	var restart []PluginType
+	for _, pluginType := range order {
+		effective := mergeSettings(desired[pluginType], registry.overrides[pluginType])
+		if isRunning(registry.Status[pluginType]) && !sameSettings(registry.Configs[pluginType], effective) {
+			restart = append(restart, pluginType)
+		}
+		registry.Configs[pluginType] = effective
+	}
+	for _, pluginType := range disabled {
+		if _, ok := registry.declared[pluginType]; ok {
+			registry.Configs[pluginType] = mergeSettings(nil, registry.overrides[pluginType])
+		}
+	}
+	registry.declared = desired
+	registry.mu.Unlock()
+
+	sort.Slice(disabled, func(i, j int) bool { return disabled[i] < disabled[j] })
+	for _, pluginType := range disabled {
+		Disable(pluginType)
+	}
+	for _, pluginType := range restart {
+		log.Printf("插件 %s 配置已变化，重新启用", pluginType)
+		Disable(pluginType)
+	}
+
+	resolved, err := ResolveStartupOrder(order)
+	if err != nil {
+		errs = append(errs, err)
+		resolved = order
+	}
+	for _, pluginType := range resolved {
+		if err := EnableWithContext(ctx, pluginType); err != nil {
+			errs = append(errs, fmt.Errorf("启用插件 %s 失败: %w", pluginType, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // 获取全部注册的插件列表
@@ -41,15 +165,73 @@ func PluginsList() []PluginInfo {
 	var list []PluginInfo
 	for pluginType, ifaceMap := range registry.PluginFactory {
 		var ifaceTypes []reflect.Type
+		scopes := make(map[reflect.Type]InstanceScope, len(ifaceMap))
 		for ifaceType := range ifaceMap {
 			ifaceTypes = append(ifaceTypes, ifaceType)
+			scopes[ifaceType] = registry.Scopes[pluginType][ifaceType]
 		}
 
 		list = append(list, PluginInfo{
 			PluginType: pluginType,
 			Interfaces: ifaceTypes,
 			Status:     registry.Status[pluginType],
+			Scopes:     scopes,
+			Instances:  registry.instanceCount(pluginType),
+			Error:      registry.Errors[pluginType],
+			Meta:       registry.Metas[pluginType],
+			Base:       registry.bases[pluginType],
 		})
 	}
 	return list
 }
+
+// toPluginTypes 插件名称转换为插件类型
+func toPluginTypes(names []string) []PluginType {
+	types := make([]PluginType, len(names))
+	for i, name := range names {
+		types[i] = PluginType(name)
+	}
+	return types
+}
+
+// checkRegistered 检查插件是否已注册，返回未注册插件的错误
+func checkRegistered(types []PluginType) []error {
+	var errs []error
+	for _, pluginType := range types {
+		if !isRegistered(pluginType) {
+			errs = append(errs, fmt.Errorf("未知插件: %s", pluginType))
+		}
+	}
+	return errs
+}
+
+// isRegistered 插件是否已注册
+func isRegistered(pluginType PluginType) bool {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	_, ok := registry.PluginFactory[pluginType]
+	return ok
+}
+
+// sameSettings 比较两份插件配置是否相同（nil 与空配置视为相同）
+func sameSettings(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// contains 判断插件类型是否在列表中
+func contains(types []PluginType, target PluginType) bool {
+	for _, t := range types {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
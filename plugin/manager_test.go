@@ -0,0 +1,52 @@
+package plugin
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+)
+
+// TestApplyConfigKeepsOverrides 重新应用配置时保留 SetPluginConfig 设置的运行时配置
+func TestApplyConfigKeepsOverrides(t *testing.T) {
+	const pluginType PluginType = "test_apply_config_overrides"
+	c := registerCounter(t, pluginType, ScopeSingleton)
+	SetPluginConfig(pluginType, map[string]string{"broker": "tcp://runtime:1883"})
+
+	apply := func(settings map[string]string) {
+		t.Helper()
+		decls := []config.PluginConfig{{Type: string(pluginType), Settings: settings}}
+		if err := ApplyConfig(context.Background(), decls); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantCfg := func(want map[string]string) {
+		t.Helper()
+		if got := c.initCfg.Load().(map[string]string); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Init 配置 %v，应为 %v", got, want)
+		}
+	}
+
+	apply(map[string]string{"broker": "tcp://file:1883", "gateway_sn": "SN1"})
+	wantCfg(map[string]string{"broker": "tcp://runtime:1883", "gateway_sn": "SN1"})
+
+	// 配置文件未变化时不重启，运行时配置保留
+	apply(map[string]string{"broker": "tcp://file:1883", "gateway_sn": "SN1"})
+	if starts := c.starts.Load(); starts != 1 {
+		t.Fatalf("配置未变化时启动 %d 次，应为 1", starts)
+	}
+
+	apply(map[string]string{"broker": "tcp://file:1883", "gateway_sn": "SN2"})
+	if starts := c.starts.Load(); starts != 2 {
+		t.Fatalf("配置变化后启动 %d 次，应为 2", starts)
+	}
+	wantCfg(map[string]string{"broker": "tcp://runtime:1883", "gateway_sn": "SN2"})
+
+	if err := ApplyConfig(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if starts, stops := c.starts.Load(), c.stops.Load(); starts != stops {
+		t.Fatalf("未声明的插件应被禁用：启动 %d 次，停止 %d 次", starts, stops)
+	}
+}
@@ -0,0 +1,1185 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/cloudapi"
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+	"gitee.com/jamespi/drone_dispatch/pkg/geofence"
+	"gitee.com/jamespi/drone_dispatch/pkg/hms"
+	"gitee.com/jamespi/drone_dispatch/pkg/preflight"
+	"gitee.com/jamespi/drone_dispatch/pkg/telemetry"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/pkg/weather"
+	"gitee.com/jamespi/drone_dispatch/plugin"
+	"gitee.com/jamespi/drone_dispatch/service"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+)
+
+// osdStaleAfter OSD超过该时长未更新时健康检查判定为降级
+const osdStaleAfter = 30 * time.Second
+
+// dock2CameraIndex 机场2飞行器（M3D/M3TD）主相机的负载索引，未指定相机时使用
+const dock2CameraIndex = "81-0-0"
+
+// dock2VideoQuality 司空2清晰度对应的上云API video_quality
+var dock2VideoQuality = map[string]int{"": 0, "adaptive": 0, "smooth": 1, "ultra_high_definition": 4}
+
+// Dock2Adapter 大疆机场2适配器，通过上云API(Cloud API) MQTT 直连机场
+type Dock2Adapter struct {
+	// 配置，Init 时确定
+	broker        string
+	username      string
+	password      string
+	clientID      string
+	gatewaySn     string
+	replyTimeout  time.Duration
+	takeoffHeight float64
+	rtmpURL       string         // 推流地址模板，支持 {sn}、{camera_index}、{video_id} 占位符
+	scope         geofence.Scope // 实例绑定的租户与项目，用于访问控制与电子围栏监控
+
+	mu         sync.RWMutex
+	client     mqtt.Client // Start 时连接，Stop 后置空
+	droneSn    string
+	droneType  string
+	dockOsd    *cloudapi.DockOsd
+	droneOsd   *cloudapi.AircraftOsd
+	states     map[string]json.RawMessage // 设备序列号 -> 最近一次 state 上报
+	osdAt      time.Time
+	events     map[string]json.RawMessage // 事件方法 -> 最近一次事件数据
+	hmsAlarms  []hms.Alarm                // 最近一次 hms 事件上报的全部告警
+	pending    map[string]chan *cloudapi.Message
+	drcSeq     int64
+	drcEntered bool
+	live       *dock2Live // 正在推流的直播，同一时间只有一路
+
+	liveMu sync.Mutex // 串行化直播开启与停止
+}
+
+// dock2Live 机场正在推流的直播
+type dock2Live struct {
+	sn          string
+	cameraIndex string
+	videoID     string
+	url         string
+}
+
+// NewDock2Adapter 创建机场2适配器
+func NewDock2Adapter() *Dock2Adapter {
+	return &Dock2Adapter{
+		states:  make(map[string]json.RawMessage),
+		events:  make(map[string]json.RawMessage),
+		pending: make(map[string]chan *cloudapi.Message),
+	}
+}
+
+// Init 读取配置，cfg 未提供的项回退到全局 mqtt 与 drone.dji 配置
+// 支持的键：broker、username、password、client_id、gateway_sn、drone_sn、reply_timeout（秒）、takeoff_height（米）、
+// rtmp_url（直播推流地址模板，未配置时使用全局 RtmpURL）、
+// tenant_id 与 project_uuid（实例绑定的租户项目：只有该租户项目的请求可访问本机场，飞行器位置按其电子围栏监控；
+// 未配置 tenant_id 时任何租户都无法访问，电子围栏只匹配全局围栏）
+func (d *Dock2Adapter) Init(ctx context.Context, cfg map[string]string) error {
+	d.broker = firstNonEmpty(cfg["broker"], mqttBrokerFromSettings())
+	d.username = firstNonEmpty(cfg["username"], config.MqttSettings()["username"])
+	d.password = firstNonEmpty(cfg["password"], config.MqttSettings()["password"])
+	d.gatewaySn = firstNonEmpty(cfg["gateway_sn"], config.DjiSettings()["DockSn"], config.DjiSettings()["GatewaySn"])
+	d.clientID = firstNonEmpty(cfg["client_id"], config.DjiSettings()["ClientId"], "drone_dispatch-"+uuid.New().String()[:8])
+	d.droneSn = cfg["drone_sn"]
+	d.rtmpURL = cfg["rtmp_url"]
+	if d.broker == "" {
+		return fmt.Errorf("未配置MQTT代理地址")
+	}
+	if d.gatewaySn == "" {
+		return fmt.Errorf("未配置机场序列号")
+	}
+
+	d.replyTimeout = 10 * time.Second
+	if v := cfg["reply_timeout"]; v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds <= 0 {
+			return fmt.Errorf("reply_timeout 配置无效: %s", v)
+		}
+		d.replyTimeout = time.Duration(seconds) * time.Second
+	}
+	d.takeoffHeight = 100
+	if v := cfg["takeoff_height"]; v != "" {
+		height, err := strconv.ParseFloat(v, 64)
+		if err != nil || height <= 0 {
+			return fmt.Errorf("takeoff_height 配置无效: %s", v)
+		}
+		d.takeoffHeight = height
+	}
+	d.scope = geofence.Scope{ProjectUUID: cfg["project_uuid"]}
+	if v := cfg["tenant_id"]; v != "" {
+		tenantID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("tenant_id 配置无效: %s", v)
+		}
+		d.scope.TenantID = tenantID
+	}
+	if d.scope.TenantID == 0 {
+		log.Printf("机场 %s 未配置 tenant_id，租户请求无法访问该机场", d.gatewaySn)
+	}
+	if url := firstNonEmpty(d.rtmpURL, config.RtmpURLSettings()); url != "" && !strings.Contains(url, "{sn}") && !strings.Contains(url, "{video_id}") {
+		log.Printf("机场 %s 的直播推流地址不含 {sn} 或 {video_id}，多个机场将推流到同一地址", d.gatewaySn)
+	}
+	return nil
+}
+
+// Start 连接MQTT代理并订阅机场上行主题
+func (d *Dock2Adapter) Start(ctx context.Context) error {
+	opts := mqtt.NewClientOptions().
+		AddBroker(d.broker).
+		SetClientID(d.clientID).
+		SetUsername(d.username).
+		SetPassword(d.password).
+		SetAutoReconnect(true).
+		SetConnectTimeout(d.replyTimeout)
+	// 重连后需要重新订阅；osd、state 只订阅本机场及已知的飞行器，飞行器序列号经拓扑上报获知后再订阅
+	opts.SetOnConnectHandler(func(client mqtt.Client) {
+		d.subscribeDevice(client, d.gatewaySn)
+		d.mu.RLock()
+		droneSn := d.droneSn
+		d.mu.RUnlock()
+		if droneSn != "" {
+			d.subscribeDevice(client, droneSn)
+		}
+		client.Subscribe(cloudapi.ServicesReplyTopic(d.gatewaySn), 1, d.onServicesReply)
+		client.Subscribe(cloudapi.EventsTopic(d.gatewaySn), 1, d.onEvents)
+		client.Subscribe(cloudapi.StatusTopic(d.gatewaySn), 1, d.onStatus)
+		client.Subscribe(cloudapi.DrcUpTopic(d.gatewaySn), 0, d.onDrcUp)
+	})
+
+	client := mqtt.NewClient(opts)
+	token := client.Connect()
+	if !token.WaitTimeout(d.replyTimeout) {
+		return fmt.Errorf("连接MQTT代理超时: %s", d.broker)
+	}
+	if err := token.Error(); err != nil {
+		return fmt.Errorf("连接MQTT代理失败: %w", err)
+	}
+	d.mu.Lock()
+	previous := d.client
+	d.client = client
+	d.mu.Unlock()
+	if previous != nil {
+		previous.Disconnect(250)
+	}
+	return nil
+}
+
+// Stop 断开MQTT连接（同时停止自动重连）并清除告警去重状态
+func (d *Dock2Adapter) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	client := d.client
+	d.client = nil
+	droneSn := d.droneSn
+	d.mu.Unlock()
+	if client != nil {
+		client.Disconnect(250)
+	}
+	hms.DefaultTracker().Forget(d.gatewaySn)
+	if droneSn != "" {
+		hms.DefaultTracker().Forget(droneSn)
+	}
+	return nil
+}
+
+// subscribeDevice 订阅设备的 osd、state 上行主题
+func (d *Dock2Adapter) subscribeDevice(client mqtt.Client, sn string) {
+	client.Subscribe(cloudapi.OsdTopic(sn), 0, d.onOsd)
+	client.Subscribe(cloudapi.StateTopic(sn), 0, d.onState)
+}
+
+// mqttClient 返回已连接的MQTT客户端，未启动、已停止或连接断开时返回错误
+func (d *Dock2Adapter) mqttClient() (mqtt.Client, error) {
+	d.mu.RLock()
+	client := d.client
+	d.mu.RUnlock()
+	if client == nil || !client.IsConnectionOpen() {
+		return nil, fmt.Errorf("MQTT未连接: %s", d.broker)
+	}
+	return client, nil
+}
+
+// HealthCheck 健康检查：MQTT未连接返回错误；机场OSD长时间未更新返回 service.ErrPluginDegraded
+func (d *Dock2Adapter) HealthCheck(ctx context.Context) error {
+	if _, err := d.mqttClient(); err != nil {
+		return err
+	}
+	d.mu.RLock()
+	osdAt := d.osdAt
+	d.mu.RUnlock()
+	if osdAt.IsZero() || time.Since(osdAt) > osdStaleAfter {
+		return fmt.Errorf("%w: 机场 %s 超过 %s 未上报OSD", service.ErrPluginDegraded, d.gatewaySn, osdStaleAfter)
+	}
+	return nil
+}
+
+/**  MQTT消息处理  **/
+
+// onOsd 缓存机场与飞行器OSD，飞行器位置同时上报电子围栏监控
+func (d *Dock2Adapter) onOsd(_ mqtt.Client, message mqtt.Message) {
+	msg, sn, ok := d.parseDeviceMessage(message)
+	if !ok {
+		return
+	}
+	if sn == d.gatewaySn {
+		var osd cloudapi.DockOsd
+		if json.Unmarshal(msg.Data, &osd) == nil {
+			d.mu.Lock()
+			d.dockOsd = &osd
+			d.osdAt = time.Now()
+			d.mu.Unlock()
+		}
+		return
+	}
+	var osd cloudapi.AircraftOsd
+	if json.Unmarshal(msg.Data, &osd) == nil {
+		d.mu.Lock()
+		d.droneOsd = &osd
+		d.mu.Unlock()
+		d.observeFence(sn, osd)
+		telemetry.PublishOSD(sn, droneOSD(osd))
+	}
+}
+
+// droneOSD 飞行器OSD转换为通用OSD
+func droneOSD(osd cloudapi.AircraftOsd) service.DroneOSD {
+	return service.DroneOSD{
+		Latitude:    osd.Latitude,
+		Longitude:   osd.Longitude,
+		Altitude:    osd.Height,
+		Battery:     osd.Battery.CapacityPercent,
+		Speed:       osd.HorizontalSpeed,
+		FlightState: osd.ModeCode,
+	}
+}
+
+// PushesTelemetry 飞行器OSD与HMS告警随MQTT上报发布，订阅方无需轮询
+func (d *Dock2Adapter) PushesTelemetry() bool {
+	return true
+}
+
+// observeFence 飞行器位置上报电子围栏监控，告警回调在锁外执行
+func (d *Dock2Adapter) observeFence(sn string, osd cloudapi.AircraftOsd) {
+	geofence.Observe(d.scope, sn, geo.Point{Lat: osd.Latitude, Lng: osd.Longitude})
+}
+
+// onState 缓存设备状态变化
+func (d *Dock2Adapter) onState(_ mqtt.Client, message mqtt.Message) {
+	msg, sn, ok := d.parseDeviceMessage(message)
+	if !ok {
+		return
+	}
+	d.mu.Lock()
+	d.states[sn] = msg.Data
+	d.mu.Unlock()
+	d.syncLiveStatus(msg.Data)
+}
+
+// syncLiveStatus 机场上报的直播状态中不再包含正在推流的相机时（例如机场重启），清除本地记录
+func (d *Dock2Adapter) syncLiveStatus(data json.RawMessage) {
+	var state struct {
+		LiveStatus *[]struct {
+			VideoID string `json:"video_id"`
+		} `json:"live_status"`
+	}
+	if json.Unmarshal(data, &state) != nil || state.LiveStatus == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.live == nil {
+		return
+	}
+	for _, status := range *state.LiveStatus {
+		if status.VideoID == d.live.videoID {
+			return
+		}
+	}
+	d.live = nil
+}
+
+// parseDeviceMessage 解析 osd/state 消息，过滤非本机场的设备
+func (d *Dock2Adapter) parseDeviceMessage(message mqtt.Message) (*cloudapi.Message, string, bool) {
+	// thing/product/{sn}/osd
+	parts := strings.Split(message.Topic(), "/")
+	if len(parts) < 4 {
+		return nil, "", false
+	}
+	sn := parts[2]
+	d.mu.RLock()
+	mine := sn == d.gatewaySn || (d.droneSn != "" && sn == d.droneSn)
+	d.mu.RUnlock()
+	if !mine {
+		return nil, "", false
+	}
+	msg, err := cloudapi.ParseMessage(message.Payload())
+	if err != nil {
+		return nil, "", false
+	}
+	return msg, sn, true
+}
+
+// onServicesReply 将服务回复交给等待中的调用方
+func (d *Dock2Adapter) onServicesReply(_ mqtt.Client, message mqtt.Message) {
+	msg, err := cloudapi.ParseMessage(message.Payload())
+	if err != nil {
+		return
+	}
+	d.mu.Lock()
+	ch, ok := d.pending[msg.Tid]
+	delete(d.pending, msg.Tid)
+	d.mu.Unlock()
+	if ok {
+		ch <- msg
+	}
+}
+
+// onEvents 缓存事件并按需回复 events_reply，hms 事件同时上报告警去重
+func (d *Dock2Adapter) onEvents(client mqtt.Client, message mqtt.Message) {
+	msg, err := cloudapi.ParseMessage(message.Payload())
+	if err != nil {
+		return
+	}
+	d.mu.Lock()
+	d.events[msg.Method] = msg.Data
+	d.mu.Unlock()
+	switch msg.Method {
+	case cloudapi.EventHms:
+		d.onHms(msg.Data)
+	case cloudapi.EventTakeoffToPointProgress:
+		d.onTakeoffProgress(msg.Data)
+	case cloudapi.EventDrcStatusNotify:
+		d.onDrcStatus(msg.Data)
+	}
+	if msg.NeedReply == 1 {
+		if reply, err := msg.Reply(cloudapi.ResultSuccess, nil); err == nil {
+			client.Publish(cloudapi.EventsReplyTopic(d.gatewaySn), 1, false, reply.Bytes())
+		}
+	}
+}
+
+// onHms 处理 hms 事件：每次上报机场与飞行器当前的全部告警，告警按设备类型归属机场或飞行器
+func (d *Dock2Adapter) onHms(data json.RawMessage) {
+	var event cloudapi.HmsEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return
+	}
+	d.mu.Lock()
+	droneSn := d.droneSn
+	alarms := make([]hms.Alarm, 0, len(event.List))
+	for _, item := range event.List {
+		sn := droneSn
+		if strings.HasPrefix(item.DeviceType, "3-") || droneSn == "" {
+			sn = d.gatewaySn
+		}
+		alarms = append(alarms, hms.FromCloudAPI(sn, item))
+	}
+	d.hmsAlarms = alarms
+	d.mu.Unlock()
+
+	devices := []string{d.gatewaySn}
+	if droneSn != "" {
+		devices = append(devices, droneSn)
+	}
+	hms.Observe(devices, alarms)
+}
+
+// onTakeoffProgress 一键起飞进度以 flight_id 作为任务发布状态变化，与司空2任务一致归属机场
+func (d *Dock2Adapter) onTakeoffProgress(data json.RawMessage) {
+	var progress cloudapi.ProgressEvent
+	if err := json.Unmarshal(data, &progress); err != nil || progress.FlightID == "" {
+		return
+	}
+	telemetry.PublishTaskStatus(telemetry.TaskEvent{
+		TaskUUID: progress.FlightID,
+		DeviceSN: d.gatewaySn,
+		TaskType: "takeoff_to_point",
+		Status:   progress.Status,
+	})
+}
+
+// onDrcStatus 发布DRC链路状态，链路断开后下次杆量控制需重新进入DRC模式
+func (d *Dock2Adapter) onDrcStatus(data json.RawMessage) {
+	var status struct {
+		Result   int `json:"result"`
+		DrcState int `json:"drc_state"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return
+	}
+	if status.DrcState == cloudapi.DrcStateDisconnected {
+		d.mu.Lock()
+		d.drcEntered = false
+		d.mu.Unlock()
+	}
+	telemetry.PublishDRCStatus(telemetry.DRCEvent{DeviceSN: d.gatewaySn, State: status.DrcState, Result: status.Result})
+}
+
+// onStatus 处理设备拓扑上报：记录飞行器序列号与型号，飞行器变化时改为订阅新飞行器的 osd、state，并回复 status_reply
+func (d *Dock2Adapter) onStatus(client mqtt.Client, message mqtt.Message) {
+	msg, err := cloudapi.ParseMessage(message.Payload())
+	if err != nil || msg.Method != cloudapi.StatusMethodUpdateTopo {
+		return
+	}
+	var topo struct {
+		SubDevices []struct {
+			SN      string `json:"sn"`
+			SubType int    `json:"sub_type"`
+		} `json:"sub_devices"`
+	}
+	if json.Unmarshal(msg.Data, &topo) == nil && len(topo.SubDevices) > 0 {
+		d.mu.Lock()
+		previous := d.droneSn
+		d.droneSn = topo.SubDevices[0].SN
+		d.droneType = "Matrice 3D"
+		if topo.SubDevices[0].SubType == 1 {
+			d.droneType = "Matrice 3TD"
+		}
+		d.mu.Unlock()
+		if droneSn := topo.SubDevices[0].SN; droneSn != previous {
+			if previous != "" {
+				client.Unsubscribe(cloudapi.OsdTopic(previous), cloudapi.StateTopic(previous))
+			}
+			d.subscribeDevice(client, droneSn)
+		}
+	}
+	if reply, err := msg.Reply(cloudapi.ResultSuccess, nil); err == nil {
+		client.Publish(cloudapi.StatusReplyTopic(d.gatewaySn), 1, false, reply.Bytes())
+	}
+}
+
+// onDrcUp DRC模式下以 osd_info_push 更新飞行器OSD
+func (d *Dock2Adapter) onDrcUp(_ mqtt.Client, message mqtt.Message) {
+	msg, err := cloudapi.ParseMessage(message.Payload())
+	if err != nil || msg.Method != cloudapi.DrcMethodOsdInfoPush {
+		return
+	}
+	var osd cloudapi.AircraftOsd
+	if json.Unmarshal(msg.Data, &osd) == nil {
+		d.mu.Lock()
+		d.droneOsd = &osd
+		droneSn := d.droneSn
+		d.mu.Unlock()
+		if droneSn != "" {
+			d.observeFence(droneSn, osd)
+		}
+	}
+}
+
+/**  服务调用  **/
+
+// callService 下发服务并等待 services_reply，设备返回非0结果码时返回错误
+func (d *Dock2Adapter) callService(ctx context.Context, method string, data interface{}) (string, error) {
+	client, err := d.mqttClient()
+	if err != nil {
+		return "", err
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	msg, err := cloudapi.NewMessage(d.gatewaySn, method, "", data)
+	if err != nil {
+		return "", err
+	}
+
+	ch := make(chan *cloudapi.Message, 1)
+	d.mu.Lock()
+	d.pending[msg.Tid] = ch
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		delete(d.pending, msg.Tid)
+		d.mu.Unlock()
+	}()
+
+	token := client.Publish(cloudapi.ServicesTopic(d.gatewaySn), 1, false, msg.Bytes())
+	if !token.WaitTimeout(d.replyTimeout) {
+		return "", fmt.Errorf("下发服务 %s 超时", method)
+	}
+	if err := token.Error(); err != nil {
+		return "", fmt.Errorf("下发服务 %s 失败: %w", method, err)
+	}
+
+	timer := time.NewTimer(d.replyTimeout)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-timer.C:
+		return "", fmt.Errorf("等待服务 %s 回复超时", method)
+	case reply := <-ch:
+		var result cloudapi.ServiceReply
+		if err := json.Unmarshal(reply.Data, &result); err != nil {
+			return "", fmt.Errorf("解析服务 %s 回复失败: %w", method, err)
+		}
+		if result.Result != cloudapi.ResultSuccess {
+			return "", fmt.Errorf("服务 %s 执行失败[%d]", method, result.Result)
+		}
+		return string(reply.Data), nil
+	}
+}
+
+// publishDrc 发布DRC下行消息（不等待回复）
+func (d *Dock2Adapter) publishDrc(method string, data interface{}) (string, error) {
+	client, err := d.mqttClient()
+	if err != nil {
+		return "", err
+	}
+	msg, err := cloudapi.NewMessage(d.gatewaySn, method, "", data)
+	if err != nil {
+		return "", err
+	}
+	token := client.Publish(cloudapi.DrcDownTopic(d.gatewaySn), 0, false, msg.Bytes())
+	if !token.WaitTimeout(d.replyTimeout) {
+		return "", fmt.Errorf("下发DRC指令 %s 超时", method)
+	}
+	if err := token.Error(); err != nil {
+		return "", fmt.Errorf("下发DRC指令 %s 失败: %w", method, err)
+	}
+	return string(msg.Data), nil
+}
+
+// ensureDrc 确保已获取飞行控制权并进入DRC模式
+func (d *Dock2Adapter) ensureDrc(ctx context.Context) error {
+	d.mu.RLock()
+	entered := d.drcEntered
+	d.mu.RUnlock()
+	if entered {
+		return nil
+	}
+	if _, err := d.callService(ctx, cloudapi.MethodFlightAuthorityGrab, nil); err != nil {
+		return err
+	}
+	if _, err := d.callService(ctx, cloudapi.MethodDrcModeEnter, nil); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.drcEntered = true
+	d.mu.Unlock()
+	return nil
+}
+
+// lastEvent 返回最近一次事件数据
+func (d *Dock2Adapter) lastEvent(method string) (string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	data, ok := d.events[method]
+	if !ok {
+		return "", fmt.Errorf("尚未收到 %s 事件", method)
+	}
+	return string(data), nil
+}
+
+// aircraftOsd 返回最近一次飞行器OSD
+func (d *Dock2Adapter) aircraftOsd() (cloudapi.AircraftOsd, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.droneOsd == nil {
+		return cloudapi.AircraftOsd{}, fmt.Errorf("尚未收到飞行器OSD")
+	}
+	return *d.droneOsd, nil
+}
+
+/**  能力接口  **/
+
+// GetDeviceState 获取设备遥测状态：最近一次OSD与state合并后的JSON，坐标按上下文指定的坐标系输出
+func (d *Dock2Adapter) GetDeviceState(ctx context.Context, deviceSn string) (string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	var osd interface{}
+	switch {
+	case deviceSn == d.gatewaySn && d.dockOsd != nil:
+		osd = d.dockOsd
+	case deviceSn == d.droneSn && d.droneOsd != nil:
+		osd = d.droneOsd
+	case deviceSn != d.gatewaySn && deviceSn != d.droneSn:
+		return "", fmt.Errorf("设备 %s 不属于机场 %s", deviceSn, d.gatewaySn)
+	default:
+		return "", fmt.Errorf("尚未收到设备 %s 的OSD", deviceSn)
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"sn":    deviceSn,
+		"osd":   osd,
+		"state": d.states[deviceSn],
+	})
+	if err != nil {
+		return "", fmt.Errorf("序列化设备状态失败: %w", err)
+	}
+	return convertCoords(ctx, data)
+}
+
+// GetDeviceHms 获取设备当前的HMS告警，格式与司空2一致 {"list":[...]}，deviceSnList 为逗号分隔的设备序列号
+func (d *Dock2Adapter) GetDeviceHms(ctx context.Context, deviceSnList string) (string, error) {
+	wanted := make(map[string]bool)
+	for _, sn := range strings.Split(deviceSnList, ",") {
+		if sn = strings.TrimSpace(sn); sn != "" {
+			wanted[sn] = true
+		}
+	}
+	d.mu.RLock()
+	list := make([]hms.Alarm, 0, len(d.hmsAlarms))
+	for _, alarm := range d.hmsAlarms {
+		if len(wanted) == 0 || wanted[alarm.DeviceSN] {
+			list = append(list, alarm)
+		}
+	}
+	d.mu.RUnlock()
+	data, err := json.Marshal(map[string]interface{}{"list": list})
+	if err != nil {
+		return "", fmt.Errorf("序列化HMS告警失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// HasDevice 设备是否为本机场或其挂载的飞行器，且请求租户为实例绑定的租户；其他租户的请求一律返回 false
+func (d *Dock2Adapter) HasDevice(ctx context.Context, deviceSn string) (bool, error) {
+	if !d.ownedBy(ctx) {
+		return false, nil
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return deviceSn == d.gatewaySn || (d.droneSn != "" && deviceSn == d.droneSn), nil
+}
+
+// ownedBy 上下文中的租户是否为实例绑定的租户，配置了 project_uuid 时项目也须一致；实例未绑定租户时返回 false
+func (d *Dock2Adapter) ownedBy(ctx context.Context) bool {
+	info, err := tenant.GetTenantFromContext(ctx)
+	if err != nil || d.scope.TenantID == 0 || info.TenantId != d.scope.TenantID {
+		return false
+	}
+	return d.scope.ProjectUUID == "" || info.ProjectUUID == d.scope.ProjectUUID
+}
+
+// UpdateDeviceCommand 实时控制指令下发，兼容司空2的指令格式 {"device_command": "return_home"}
+func (d *Dock2Adapter) UpdateDeviceCommand(ctx context.Context, deviceSn string, payLoad io.Reader) (string, error) {
+	if ok, _ := d.HasDevice(ctx, deviceSn); !ok {
+		return "", fmt.Errorf("设备 %s 不属于机场 %s", deviceSn, d.gatewaySn)
+	}
+	var body DeviceCommandRequest
+	if _, err := decodeValidated(payLoad, &body); err != nil {
+		return "", err
+	}
+	switch body.DeviceCommand {
+	case "return_home", "return_specific_home":
+		return d.callService(ctx, cloudapi.MethodReturnHome, nil)
+	case "return_home_cancel":
+		return d.callService(ctx, cloudapi.MethodReturnHomeCancel, nil)
+	}
+	return "", fmt.Errorf("机场2直连不支持控制指令: %s", body.DeviceCommand)
+}
+
+/**  上云api  **/
+
+// AirportBindStatus 设备是否绑定机场：已收到拓扑上报即视为已绑定
+func (d *Dock2Adapter) AirportBindStatus() (bool, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.droneSn != "", nil
+}
+
+// AirportOrganizationBind 机场是否绑定组织：直连模式下无组织概念，连接成功即视为已绑定
+func (d *Dock2Adapter) AirportOrganizationBind() (bool, error) {
+	_, err := d.mqttClient()
+	return err == nil, nil
+}
+
+// TakeOff 一键起飞，目标点为机场正上方 takeoff_height 米；起飞前执行飞前检查，报告以 flight_id 存档
+func (d *Dock2Adapter) TakeOff(ctx context.Context) (string, error) {
+	d.mu.RLock()
+	dock := d.dockOsd
+	d.mu.RUnlock()
+	if dock == nil {
+		return "", fmt.Errorf("尚未收到机场OSD，无法确定起飞点")
+	}
+	req := &cloudapi.TakeoffToPointRequest{
+		FlightID:        uuid.New().String(),
+		TargetLatitude:  dock.Latitude,
+		TargetLongitude: dock.Longitude,
+		TargetHeight:    d.takeoffHeight,
+		SecurityTakeoff: d.takeoffHeight,
+		RthAltitude:     d.takeoffHeight,
+	}
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
+	if err := geofence.Enforce(ctx, d.scope, []geo.Point{{Lat: req.TargetLatitude, Lng: req.TargetLongitude}}); err != nil {
+		return "", err
+	}
+	d.mu.RLock()
+	droneSn := d.droneSn
+	d.mu.RUnlock()
+	report := preflight.Run(ctx, preflight.Sources{Telemetry: d, Hms: d, Weather: weather.ForDock(d)}, d.gatewaySn, droneSn)
+	if err := report.Err(); err != nil {
+		preflight.Record(report, "")
+		return "", err
+	}
+	// 飞前检查未启用或停用了 weather 规则时单独检查天气门限
+	if !report.Checked(preflight.RuleWeather) {
+		q := weather.Query{DockSN: d.gatewaySn, Location: geo.Point{Lat: dock.Latitude, Lng: dock.Longitude}}
+		if _, err := weather.Check(ctx, weather.ForDock(d), q); err != nil {
+			return "", err
+		}
+	}
+	resp, err := d.callService(ctx, cloudapi.MethodTakeoffToPoint, req)
+	if report != nil {
+		missionID := req.FlightID
+		if err != nil {
+			missionID = ""
+		}
+		preflight.Record(report, missionID)
+	}
+	return resp, err
+}
+
+// TakeOffToPointProgress 一键起飞结果事件通知
+func (d *Dock2Adapter) TakeOffToPointProgress() (string, error) {
+	return d.lastEvent(cloudapi.EventTakeoffToPointProgress)
+}
+
+// FlyToPoint 指点飞行，下发前校验飞行器当前位置到目标点的航段是否进入电子围栏；飞行器位置未知时拒绝下发
+func (d *Dock2Adapter) FlyToPoint(ctx context.Context, latitude, longitude, height, maxSpeed float64) (string, error) {
+	req := &cloudapi.FlyToPointRequest{
+		FlyToID:  uuid.New().String(),
+		MaxSpeed: maxSpeed,
+		Points:   []cloudapi.Point{{Latitude: latitude, Longitude: longitude, Height: height}},
+	}
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
+	osd, err := d.aircraftOsd()
+	if err != nil {
+		return "", fmt.Errorf("无法确定飞行器位置，拒绝指点飞行: %w", err)
+	}
+	path := []geo.Point{{Lat: osd.Latitude, Lng: osd.Longitude}, {Lat: latitude, Lng: longitude}}
+	if err := geofence.Enforce(ctx, d.scope, path); err != nil {
+		return "", err
+	}
+	return d.callService(ctx, cloudapi.MethodFlyToPoint, req)
+}
+
+// FlyToPointProgress 指点飞行结果事件通知
+func (d *Dock2Adapter) FlyToPointProgress() (string, error) {
+	return d.lastEvent(cloudapi.EventFlyToPointProgress)
+}
+
+// Land 一键降落：返航并降落到机场
+func (d *Dock2Adapter) Land(ctx context.Context) (string, error) {
+	return d.callService(ctx, cloudapi.MethodReturnHome, nil)
+}
+
+// LandToPointProgress 一键降落结果事件通知
+func (d *Dock2Adapter) LandToPointProgress() (string, error) {
+	return d.lastEvent(cloudapi.EventReturnHomeInfo)
+}
+
+// DrcStatusNotify DRC链路状态通知
+func (d *Dock2Adapter) DrcStatusNotify() (string, error) {
+	return d.lastEvent(cloudapi.EventDrcStatusNotify)
+}
+
+// JoystickInvalidNotify DRC飞行控制无效原因通知
+func (d *Dock2Adapter) JoystickInvalidNotify() (string, error) {
+	return d.lastEvent(cloudapi.EventJoystickInvalidNotify)
+}
+
+// StickControl DRC杆量控制，stickX 控制横滚、stickY 控制俯仰，为相对中位的偏移量
+func (d *Dock2Adapter) StickControl(ctx context.Context, stickX, stickY int) (string, error) {
+	if err := d.ensureDrc(ctx); err != nil {
+		return "", err
+	}
+	d.mu.Lock()
+	d.drcSeq++
+	seq := d.drcSeq
+	d.mu.Unlock()
+	stick := &cloudapi.StickControl{
+		Roll:     clampStick(cloudapi.StickNeutral + stickX),
+		Pitch:    clampStick(cloudapi.StickNeutral + stickY),
+		Throttle: cloudapi.StickNeutral,
+		Yaw:      cloudapi.StickNeutral,
+		Seq:      seq,
+	}
+	if err := stick.Validate(); err != nil {
+		return "", err
+	}
+	return d.publishDrc(cloudapi.DrcMethodStickControl, stick)
+}
+
+// DroneEmergencyStop DRC飞行器急停
+func (d *Dock2Adapter) DroneEmergencyStop() (string, error) {
+	return d.publishDrc(cloudapi.DrcMethodEmergencyStop, map[string]interface{}{})
+}
+
+// FlightAuthorityGrab 飞行控制权争夺
+func (d *Dock2Adapter) FlightAuthorityGrab(ctx context.Context) (string, error) {
+	return d.callService(ctx, cloudapi.MethodFlightAuthorityGrab, nil)
+}
+
+// FlightAuthorityRelease 飞行控制权释放，同时退出DRC模式
+func (d *Dock2Adapter) FlightAuthorityRelease(ctx context.Context) (string, error) {
+	d.mu.Lock()
+	d.drcEntered = false
+	d.mu.Unlock()
+	return d.callService(ctx, cloudapi.MethodFlightAuthorityRelease, nil)
+}
+
+// CameraModeSwitch 切换相机模式：photo 或 video
+func (d *Dock2Adapter) CameraModeSwitch(ctx context.Context, mode string) (string, error) {
+	modes := map[string]int{"photo": 0, "video": 1}
+	cameraMode, ok := modes[mode]
+	if !ok {
+		return "", fmt.Errorf("不支持的相机模式: %s", mode)
+	}
+	return d.callService(ctx, cloudapi.MethodCameraModeSwitch, map[string]interface{}{"camera_mode": cameraMode})
+}
+
+// CameraPhotoTake 云台挂载开始拍照
+func (d *Dock2Adapter) CameraPhotoTake(ctx context.Context) (string, error) {
+	return d.callService(ctx, cloudapi.MethodCameraPhotoTake, nil)
+}
+
+// CameraPhotoStop 云台挂载停止拍照
+func (d *Dock2Adapter) CameraPhotoStop(ctx context.Context) (string, error) {
+	return d.callService(ctx, cloudapi.MethodCameraPhotoStop, nil)
+}
+
+// CameraRecordingStart 云台挂载开始录像
+func (d *Dock2Adapter) CameraRecordingStart(ctx context.Context) (string, error) {
+	return d.callService(ctx, cloudapi.MethodCameraRecordingStart, nil)
+}
+
+// CameraRecordingStop 云台挂载停止录像
+func (d *Dock2Adapter) CameraRecordingStop(ctx context.Context) (string, error) {
+	return d.callService(ctx, cloudapi.MethodCameraRecordingStop, nil)
+}
+
+// CameraFrameZoom 云台挂载框选变焦，frameX/frameY 为框选中心（画面百分比）
+func (d *Dock2Adapter) CameraFrameZoom(ctx context.Context, frameX, frameY int) (string, error) {
+	return d.callService(ctx, cloudapi.MethodCameraFrameZoom, map[string]interface{}{
+		"x": float64(frameX) / 100,
+		"y": float64(frameY) / 100,
+	})
+}
+
+// CameraFocalLengthSet 云台挂载变焦
+func (d *Dock2Adapter) CameraFocalLengthSet(ctx context.Context, focalLength int) (string, error) {
+	return d.callService(ctx, cloudapi.MethodCameraFocalLengthSet, map[string]interface{}{"zoom_factor": focalLength})
+}
+
+// CameraScreenDrag 画面拖动控制（云台与机身是否一起转动）
+func (d *Dock2Adapter) CameraScreenDrag(ctx context.Context, dragX, dragY int, isFollow bool) (string, error) {
+	return d.callService(ctx, cloudapi.MethodCameraScreenDrag, map[string]interface{}{
+		"locked":      isFollow,
+		"yaw_speed":   dragX,
+		"pitch_speed": dragY,
+	})
+}
+
+// CameraPhotoStorageSet 云台照片存储设置
+func (d *Dock2Adapter) CameraPhotoStorageSet(ctx context.Context, storageType string) (string, error) {
+	return d.callService(ctx, cloudapi.MethodPhotoStorageSet, map[string]interface{}{"photo_storage_settings": []string{storageType}})
+}
+
+// CameraRecordingStorageSet 云台录像存储设置
+func (d *Dock2Adapter) CameraRecordingStorageSet(ctx context.Context, storageType string) (string, error) {
+	return d.callService(ctx, cloudapi.MethodVideoStorageSet, map[string]interface{}{"video_storage_settings": []string{storageType}})
+}
+
+// GetDroneName 获取无人机名称
+func (d *Dock2Adapter) GetDroneName() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.droneSn
+}
+
+// GetDroneType 获取无人机类型
+func (d *Dock2Adapter) GetDroneType() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.droneType
+}
+
+// GetDroneStatus 获取无人机状态（mode_code）
+func (d *Dock2Adapter) GetDroneStatus() (string, error) {
+	osd, err := d.aircraftOsd()
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(osd.ModeCode), nil
+}
+
+// GetDroneLocation 获取无人机位置信息
+func (d *Dock2Adapter) GetDroneLocation() (string, error) {
+	osd, err := d.aircraftOsd()
+	if err != nil {
+		return "", err
+	}
+	data, _ := json.Marshal(cloudapi.Point{Latitude: osd.Latitude, Longitude: osd.Longitude, Height: osd.Height})
+	return string(data), nil
+}
+
+// GetDroneBatteryLevel 获取无人机电池状态
+func (d *Dock2Adapter) GetDroneBatteryLevel() (int, error) {
+	osd, err := d.aircraftOsd()
+	if err != nil {
+		return 0, err
+	}
+	return osd.Battery.CapacityPercent, nil
+}
+
+// GetDroneCameraStatus 获取无人机摄像头状态
+func (d *Dock2Adapter) GetDroneCameraStatus() (string, error) {
+	osd, err := d.aircraftOsd()
+	if err != nil {
+		return "", err
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"camera_mode":       osd.CameraMode,
+		"recording":         osd.Recording,
+		"zoom_factor":       osd.ZoomFactor,
+		"gimbal_pitch":      osd.GimbalPitch,
+		"storage_remaining": osd.StorageRemaining,
+	})
+	return string(data), nil
+}
+
+// GetDroneFlightTime 获取无人机飞行时间（秒）
+func (d *Dock2Adapter) GetDroneFlightTime() (int, error) {
+	osd, err := d.aircraftOsd()
+	if err != nil {
+		return 0, err
+	}
+	return int(osd.TotalFlightTime), nil
+}
+
+// GetDroneMaxAltitude 获取无人机最大飞行高度（米）
+func (d *Dock2Adapter) GetDroneMaxAltitude() (int, error) {
+	return 6000, nil
+}
+
+// GetDroneMaxSpeed 获取无人机最大水平速度（米/秒）
+func (d *Dock2Adapter) GetDroneMaxSpeed() (int, error) {
+	return 15, nil
+}
+
+// GetDronePayloadCapacity 获取无人机最大载重：Matrice 3D 系列不支持外挂载荷
+func (d *Dock2Adapter) GetDronePayloadCapacity() (int, error) {
+	return 0, nil
+}
+
+// GetDroneManufacturer 获取无人机制造商
+func (d *Dock2Adapter) GetDroneManufacturer() (string, error) {
+	return "DJI", nil
+}
+
+// GetDroneModel 获取无人机型号
+func (d *Dock2Adapter) GetDroneModel() (string, error) {
+	if droneType := d.GetDroneType(); droneType != "" {
+		return droneType, nil
+	}
+	return "", fmt.Errorf("尚未收到设备拓扑")
+}
+
+// GetDroneFirmwareVersion 获取无人机固件版本：需订阅 state 中的 firmware_version
+func (d *Dock2Adapter) GetDroneFirmwareVersion() (string, error) {
+	d.mu.RLock()
+	state := d.states[d.droneSn]
+	d.mu.RUnlock()
+	var fields struct {
+		FirmwareVersion string `json:"firmware_version"`
+	}
+	if state == nil || json.Unmarshal(state, &fields) != nil || fields.FirmwareVersion == "" {
+		return "", fmt.Errorf("尚未收到飞行器固件版本")
+	}
+	return fields.FirmwareVersion, nil
+}
+
+// GetDroneLastMaintenanceDate 获取无人机最近保养日期：上云API不提供
+func (d *Dock2Adapter) GetDroneLastMaintenanceDate() (string, error) {
+	return "", fmt.Errorf("机场2直连不支持获取保养记录")
+}
+
+// SupportsMqtt 是否支持MQTT
+func (d *Dock2Adapter) SupportsMqtt() bool {
+	return true
+}
+
+// GetLiveStreamURL 获取图传流地址：开启飞行器主相机直播（已开启时沿用），返回推流地址
+func (d *Dock2Adapter) GetLiveStreamURL() (string, error) {
+	d.mu.RLock()
+	droneSn := d.droneSn
+	d.mu.RUnlock()
+	if droneSn == "" {
+		return "", fmt.Errorf("尚未获取机场 %s 挂载的飞行器序列号", d.gatewaySn)
+	}
+	live, err := d.startLive(context.Background(), LiveStreamStartRequest{SN: droneSn, CameraIndex: dock2CameraIndex})
+	if err != nil {
+		return "", err
+	}
+	return live.url, nil
+}
+
+// LiveStreamStart 开启直播，请求体与司空2相同，机场将相机画面推流到 rtmp_url 模板（未配置时为全局 RtmpURL）展开后的地址；
+// 同一相机已在推流时直接返回，返回格式与司空2一致：{"sn","camera_index","url","url_type":"rtmp","video_id"}
+func (d *Dock2Adapter) LiveStreamStart(ctx context.Context, payLoad io.Reader) (string, error) {
+	var req LiveStreamStartRequest
+	if _, err := decodeValidated(payLoad, &req); err != nil {
+		return "", err
+	}
+	if ok, _ := d.HasDevice(ctx, req.SN); !ok {
+		return "", fmt.Errorf("设备 %s 不属于机场 %s", req.SN, d.gatewaySn)
+	}
+	live, err := d.startLive(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	resp, err := json.Marshal(map[string]string{
+		"sn":           live.sn,
+		"camera_index": live.cameraIndex,
+		"url":          live.url,
+		"url_type":     "rtmp",
+		"video_id":     live.videoID,
+	})
+	return string(resp), err
+}
+
+// startLive 下发 live_start_push，video_id 与推流地址均按请求的设备与相机生成；调用方负责校验设备归属
+func (d *Dock2Adapter) startLive(ctx context.Context, req LiveStreamStartRequest) (*dock2Live, error) {
+	quality, ok := dock2VideoQuality[req.QualityType]
+	if !ok {
+		return nil, fmt.Errorf("不支持的清晰度: %s", req.QualityType)
+	}
+	template := firstNonEmpty(d.rtmpURL, config.RtmpURLSettings())
+	if template == "" {
+		return nil, fmt.Errorf("未配置直播推流地址 rtmp_url 或 RtmpURL")
+	}
+	d.liveMu.Lock()
+	defer d.liveMu.Unlock()
+	d.mu.RLock()
+	live := d.live
+	d.mu.RUnlock()
+	if live != nil && (live.sn != req.SN || live.cameraIndex != req.CameraIndex) {
+		return nil, fmt.Errorf("机场2直连同一时间只支持一路直播，设备 %s 相机 %s 正在推流", live.sn, live.cameraIndex)
+	}
+	if live != nil {
+		return live, nil
+	}
+	videoID := fmt.Sprintf("%s/%s/normal-0", req.SN, req.CameraIndex)
+	url := strings.NewReplacer("{sn}", req.SN, "{camera_index}", req.CameraIndex, "{video_id}", videoID).Replace(template)
+	if _, err := d.callService(ctx, cloudapi.MethodLiveStartPush, map[string]interface{}{
+		"url_type":      1, // RTMP
+		"url":           url,
+		"video_id":      videoID,
+		"video_quality": quality,
+	}); err != nil {
+		return nil, err
+	}
+	live = &dock2Live{sn: req.SN, cameraIndex: req.CameraIndex, videoID: videoID, url: url}
+	d.mu.Lock()
+	d.live = live
+	d.mu.Unlock()
+	return live, nil
+}
+
+// LiveStreamStop 停止直播，相机未在推流时直接返回
+func (d *Dock2Adapter) LiveStreamStop(ctx context.Context, deviceSn, cameraIndex string) error {
+	if ok, _ := d.HasDevice(ctx, deviceSn); !ok {
+		return fmt.Errorf("设备 %s 不属于机场 %s", deviceSn, d.gatewaySn)
+	}
+	d.liveMu.Lock()
+	defer d.liveMu.Unlock()
+	d.mu.RLock()
+	live := d.live
+	d.mu.RUnlock()
+	if live == nil || live.sn != deviceSn || live.cameraIndex != cameraIndex {
+		return nil
+	}
+	if _, err := d.callService(ctx, cloudapi.MethodLiveStopPush, map[string]interface{}{"video_id": live.videoID}); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.live = nil
+	d.mu.Unlock()
+	return nil
+}
+
+// clampStick 杆量限制在 364~1684
+func clampStick(value int) float64 {
+	const minStick, maxStick = 364, 1684
+	if value < minStick {
+		value = minStick
+	}
+	if value > maxStick {
+		value = maxStick
+	}
+	return float64(value)
+}
+
+// mqttBrokerFromSettings 由全局 mqtt 配置拼接代理地址
+func mqttBrokerFromSettings() string {
+	host := config.MqttSettings()["host"]
+	if host == "" {
+		return ""
+	}
+	if strings.Contains(host, "://") {
+		return host
+	}
+	port := config.MqttSettings()["port"]
+	if port == "" {
+		port = "1883"
+	}
+	return fmt.Sprintf("tcp://%s:%s", host, port)
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// 编译期检查机场2适配器实现的接口
+var (
+	_ service.DJIDock2DroneAdapter = (*Dock2Adapter)(nil)
+	_ service.TelemetrySource      = (*Dock2Adapter)(nil)
+	_ service.HmsProvider          = (*Dock2Adapter)(nil)
+	_ service.DeviceController     = (*Dock2Adapter)(nil)
+	_ service.DeviceLocator        = (*Dock2Adapter)(nil)
+	_ service.LiveStreamer         = (*Dock2Adapter)(nil)
+	_ service.LiveStreamStopper    = (*Dock2Adapter)(nil)
+	_ service.PluginInitializer    = (*Dock2Adapter)(nil)
+	_ service.PluginStarter        = (*Dock2Adapter)(nil)
+	_ service.PluginStopper        = (*Dock2Adapter)(nil)
+	_ service.PluginHealthChecker  = (*Dock2Adapter)(nil)
+)
+
+// 实例化 Dock2Adapter 并注册到插件系统（自动注册）
+func init() {
+	// 注册机场2适配器插件（单例：一个实例维持一条MQTT连接）
+	plugin.RegisterPluginWithScope(plugin.DJIDock2Plugin, reflect.TypeOf((*service.DJIDock2DroneAdapter)(nil)).Elem(), plugin.ScopeSingleton, func() interface{} {
+		return NewDock2Adapter()
+	})
+	plugin.RegisterPluginMeta(plugin.DJIDock2Plugin, plugin.PluginMeta{
+		Version:      "0.1.0",
+		Vendor:       "DJI",
+		Description:  "大疆机场2 上云API MQTT 直连适配器",
+		Capabilities: []string{"device_control", "telemetry"},
+		Priority:     10, // 直连优先于司空2云端转发
+	})
+}
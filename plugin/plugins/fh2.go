@@ -1,22 +1,32 @@
 package plugins
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+	"gitee.com/jamespi/drone_dispatch/pkg/geofence"
 	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+	"gitee.com/jamespi/drone_dispatch/pkg/media"
+	"gitee.com/jamespi/drone_dispatch/pkg/objectstore"
+	"gitee.com/jamespi/drone_dispatch/pkg/preflight"
+	"gitee.com/jamespi/drone_dispatch/pkg/telemetry"
 	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
 	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+	"gitee.com/jamespi/drone_dispatch/pkg/wayline"
+	"gitee.com/jamespi/drone_dispatch/pkg/weather"
 	"gitee.com/jamespi/drone_dispatch/plugin"
 	"gitee.com/jamespi/drone_dispatch/service"
 	"github.com/google/uuid"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"reflect"
 	"strings"
-	"sync"
+	"time"
 )
 
 // TenantInfo 租户上下文基础信息
@@ -28,18 +38,23 @@ type TenantInfo struct {
 
 // FH2Adapter 司空2适配器
 // 司空2openapi接口文档地址：https://apifox.com/apidoc/shared/6b4ca90b-233f-48ac-818c-d694acb0663a/api-221842037
+// 适配器本身不保存租户状态，租户信息随每次请求从上下文读取，因此可作为单例在多个租户间共享连接池
 type FH2Adapter struct {
-	tenantInfo   *TenantInfo
 	validator    *validator.InputValidator
 	secureClient *httpclient.SecureHTTPClient
-	mu           sync.RWMutex // 并发线程安全
 }
 
 // NewFH2Adapter 创建一个新的FH2适配器
 func NewFH2Adapter() *FH2Adapter {
+	return NewFH2AdapterWithClient(httpclient.NewSecureHTTPClient())
+}
+
+// NewFH2AdapterWithClient 使用指定的HTTP客户端创建FH2适配器
+// 测试时可传入 httpclient.NewRecordingClient 创建的客户端，在无网络环境下回放磁带
+func NewFH2AdapterWithClient(client *httpclient.SecureHTTPClient) *FH2Adapter {
 	return &FH2Adapter{
-		tenantInfo: &TenantInfo{},
-		validator:  validator.GetValidator(),
+		validator:    validator.GetValidator(),
+		secureClient: client,
 	}
 }
 
@@ -58,22 +73,20 @@ func (F *FH2Adapter) doRequestWithTenant(ctx context.Context, method, url string
 		return nil, fmt.Errorf("获取租户信息失败: %w", err)
 	}
 
-	F.mu.RLock()
-	F.tenantInfo = &TenantInfo{
+	requestTenant := &TenantInfo{
 		XUserToken:  tenantInfo.UserToken,
 		TenantId:    tenantInfo.TenantId,
 		ProjectUUID: tenantInfo.ProjectUUID,
 	}
-	F.mu.RUnlock()
 
 	// 构建header请求头
 	headers := map[string]string{
-		"X-User-Token": F.tenantInfo.XUserToken,
+		"X-User-Token": requestTenant.XUserToken,
 		"X-Request-Id": tenant.GetRequestIDFromContext(ctx),
 		"X-Language":   "zh",
 	}
-	if F.tenantInfo.ProjectUUID != "" {
-		headers["X-Project-Uuid"] = F.tenantInfo.ProjectUUID
+	if requestTenant.ProjectUUID != "" {
+		headers["X-Project-Uuid"] = requestTenant.ProjectUUID
 	}
 	// 如果请求ID为空，生成一个
 	if headers["X-Request-Id"] == "" {
@@ -122,40 +135,128 @@ func (F *FH2Adapter) doRequestWithHeaders(ctx context.Context, method, url strin
 	return bodyBytes, nil
 }
 
+// convertCoords 将设备上报的 WGS-84 坐标转换为上下文指定的坐标系（geo.WithCoordSystem）
+func convertCoords(ctx context.Context, resp []byte) (string, error) {
+	converted, err := geo.ConvertJSON(resp, geo.WGS84, geo.CoordSystemFrom(ctx))
+	if err != nil {
+		return "", fmt.Errorf("坐标系转换失败: %w", err)
+	}
+	return string(converted), nil
+}
+
+// HealthCheck 健康检查：司空2服务是否可达，配置的 xUserToken 是否有效
+// 服务不可达返回错误；可达但令牌无效返回 service.ErrPluginDegraded（各租户仍使用自己的令牌）
+func (F *FH2Adapter) HealthCheck(ctx context.Context) error {
+	host := config.FH2Settings()["host"]
+	if host == "" {
+		return fmt.Errorf("未配置司空2服务地址")
+	}
+	token := config.FH2Settings()["xUserToken"]
+	if token == "" {
+		// 未配置令牌时只检查可达性，任何HTTP响应都视为可达
+		resp, err := F.secureClient.DoRequest(ctx, http.MethodGet, host, nil, nil)
+		if err != nil {
+			return fmt.Errorf("司空2服务不可达: %w", err)
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	url := fmt.Sprintf("%s/openapi/v0.1/project?page=1&page_size=1", host)
+	headers := map[string]string{
+		"X-User-Token": token,
+		"X-Request-Id": uuid.New().String(),
+		"X-Language":   "zh",
+	}
+	resp, err := F.secureClient.DoRequest(ctx, http.MethodGet, url, nil, headers)
+	if err != nil {
+		return fmt.Errorf("司空2服务不可达: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: 司空2返回状态码 %d", service.ErrPluginDegraded, resp.StatusCode)
+	}
+	var apiResp APIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("%w: 解析司空2响应失败: %v", service.ErrPluginDegraded, err)
+	}
+	if apiResp.Code > 0 {
+		return fmt.Errorf("%w: 司空2令牌校验失败[%d]: %s", service.ErrPluginDegraded, apiResp.Code, apiResp.Message)
+	}
+	return nil
+}
+
 // GetprojectList 获取组织下的项目列表
 func (F *FH2Adapter) GetprojectList(ctx context.Context) (string, error) {
-	encodedQ := url.QueryEscape(config.FH2Settings["q"])
+	encodedQ := url.QueryEscape(config.FH2Settings()["q"])
 	if err := F.validator.ValidateQueryParam(encodedQ); err != nil {
 		return "", fmt.Errorf("查询参数验证失败: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/openapi/v0.1/project?page=1&page_size=10&q=%s&prj_authorized_status=project-status-authorized&usage=simple&sort_column=created_at&sort_type=ASC", config.FH2Settings["host"], encodedQ)
+	url := fmt.Sprintf("%s/openapi/v0.1/project?page=1&page_size=10&q=%s&prj_authorized_status=project-status-authorized&usage=simple&sort_column=created_at&sort_type=ASC", config.FH2Settings()["host"], encodedQ)
 	resp, err := F.doRequestWithTenant(ctx, http.MethodGet, url, nil)
 	return string(resp), err
 }
 
 // GetDeviceList 获取项目下的设备列表
 func (F *FH2Adapter) GetDeviceList(ctx context.Context) (string, error) {
-	url := fmt.Sprintf("%s/openapi/v0.1/project/device", config.FH2Settings["host"])
+	url := fmt.Sprintf("%s/openapi/v0.1/project/device", config.FH2Settings()["host"])
 	resp, err := F.doRequestWithTenant(ctx, http.MethodGet, url, nil)
 	return string(resp), err
 }
 
+// HasDevice 判断设备是否属于当前租户项目（匹配网关或飞行器序列号）
+func (F *FH2Adapter) HasDevice(ctx context.Context, deviceSn string) (bool, error) {
+	resp, err := F.GetDeviceList(ctx)
+	if err != nil {
+		return false, err
+	}
+	var result struct {
+		Data struct {
+			List []struct {
+				Gateway *struct {
+					SN string `json:"sn"`
+				} `json:"gateway"`
+				Drone *struct {
+					SN string `json:"sn"`
+				} `json:"drone"`
+			} `json:"list"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(resp), &result); err != nil {
+		return false, fmt.Errorf("解析设备列表失败: %w", err)
+	}
+	for _, pair := range result.Data.List {
+		if (pair.Gateway != nil && pair.Gateway.SN == deviceSn) || (pair.Drone != nil && pair.Drone.SN == deviceSn) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // GetProjectStsToken 获取项目的存储上传凭证
 func (F *FH2Adapter) GetProjectStsToken(ctx context.Context) (string, error) {
-	url := fmt.Sprintf("%s/openapi/v0.1/project/sts-token", config.FH2Settings["host"])
+	url := fmt.Sprintf("%s/openapi/v0.1/project/sts-token", config.FH2Settings()["host"])
 	resp, err := F.doRequestWithTenant(ctx, http.MethodGet, url, nil)
 	return string(resp), err
 }
 
 // GetStsToken 获取设备物模型信息
 func (F *FH2Adapter) GetStsToken(ctx context.Context, deviceSn string) (string, error) {
+	return F.GetDeviceState(ctx, deviceSn)
+}
+
+// GetDeviceState 获取设备物模型（遥测）状态
+func (F *FH2Adapter) GetDeviceState(ctx context.Context, deviceSn string) (string, error) {
 	if err := F.validator.ValidateDeviceSN(deviceSn); err != nil {
 		return "", fmt.Errorf("设备序列号验证失败: %w", err)
 	}
-	url := fmt.Sprintf("%s/openapi/v0.1/device/%s/state", config.FH2Settings["host"], deviceSn)
+	url := fmt.Sprintf("%s/openapi/v0.1/device/%s/state", config.FH2Settings()["host"], deviceSn)
 	resp, err := F.doRequestWithTenant(ctx, http.MethodGet, url, nil)
-	return string(resp), err
+	if err != nil {
+		return "", err
+	}
+	return convertCoords(ctx, resp)
 }
 
 // GetDeviceHms 获取设备HMS信息
@@ -164,7 +265,7 @@ func (F *FH2Adapter) GetDeviceHms(ctx context.Context, deviceSnList string) (str
 	if err := F.validator.ValidateQueryParam(encodedQ); err != nil {
 		return "", fmt.Errorf("查询参数验证失败: %w", err)
 	}
-	url := fmt.Sprintf("%s/openapi/v0.1/device/hms?device_sn_list=%s", config.FH2Settings["host"], encodedQ)
+	url := fmt.Sprintf("%s/openapi/v0.1/device/hms?device_sn_list=%s", config.FH2Settings()["host"], encodedQ)
 	resp, err := F.doRequestWithTenant(ctx, http.MethodGet, url, nil)
 	return string(resp), err
 }
@@ -179,7 +280,11 @@ func (F *FH2Adapter) UpdateDeviceCommand(ctx context.Context, deviceSn string, p
 	if err := F.validator.ValidateDeviceSN(deviceSn); err != nil {
 		return "", fmt.Errorf("设备序列号验证失败: %w", err)
 	}
-	url := fmt.Sprintf("%s/openapi/v0.1/device/%s/command", config.FH2Settings["host"], deviceSn)
+	payLoad, err := decodeValidated(payLoad, &DeviceCommandRequest{})
+	if err != nil {
+		return "", err
+	}
+	url := fmt.Sprintf("%s/openapi/v0.1/device/%s/command", config.FH2Settings()["host"], deviceSn)
 	resp, err := F.doRequestWithTenant(ctx, http.MethodPost, url, payLoad)
 	return string(resp), err
 }
@@ -194,7 +299,7 @@ func (F *FH2Adapter) UpdateDeviceCommand(ctx context.Context, deviceSn string, p
 //					  "camera_position": "indoor",  // 相机位置，indoor表示舱内摄像头，outdoor表示舱外摄像头
 //				   }`
 func (F *FH2Adapter) UpdateDeviceChangeCamera(ctx context.Context, payLoad io.Reader) (string, error) {
-	url := fmt.Sprintf("%s/openapi/v0.1/device/change-camera", config.FH2Settings["host"])
+	url := fmt.Sprintf("%s/openapi/v0.1/device/change-camera", config.FH2Settings()["host"])
 	resp, err := F.doRequestWithTenant(ctx, http.MethodPost, url, payLoad)
 	return string(resp), err
 }
@@ -210,7 +315,7 @@ func (F *FH2Adapter) UpdateDeviceChangeCamera(ctx context.Context, payLoad io.Re
 //					   "lens_type": "zoom"   // 镜头类型，支持 "wide"（广角）、"zoom"（变焦）、"ir"（红外）
 //					}`
 func (F *FH2Adapter) UpdateDeviceChangeLens(ctx context.Context, payLoad io.Reader) (string, error) {
-	url := fmt.Sprintf("%s/openapi/v0.1/device/change-lens", config.FH2Settings["host"])
+	url := fmt.Sprintf("%s/openapi/v0.1/device/change-lens", config.FH2Settings()["host"])
 	resp, err := F.doRequestWithTenant(ctx, http.MethodPost, url, payLoad)
 	return string(resp), err
 }
@@ -224,7 +329,7 @@ func (F *FH2Adapter) UpdateDeviceChangeLens(ctx context.Context, payLoad io.Read
 //				   "payload_index": ["81-0-0"]  // 负载索引，此参数可通过获取设备列表中data.list.drone.camera_list.camera_index传入
 //				}`
 func (F *FH2Adapter) GetDeviceControl(ctx context.Context, payLoad io.Reader) (string, error) {
-	url := fmt.Sprintf("%s/openapi/v0.1/device/control", config.FH2Settings["host"])
+	url := fmt.Sprintf("%s/openapi/v0.1/device/control", config.FH2Settings()["host"])
 	resp, err := F.doRequestWithTenant(ctx, http.MethodPost, url, payLoad)
 	return string(resp), err
 }
@@ -238,7 +343,7 @@ func (F *FH2Adapter) GetDeviceControl(ctx context.Context, payLoad io.Reader) (s
 //				   "payload_index": ["81-0-0"]  // 负载索引，此参数可通过获取设备列表中data.list.drone.camera_list.camera_index传入
 //				}`
 func (F *FH2Adapter) DeleteDeviceControl(ctx context.Context, payLoad io.Reader) (string, error) {
-	url := fmt.Sprintf("%s/openapi/v0.1/device/control", config.FH2Settings["host"])
+	url := fmt.Sprintf("%s/openapi/v0.1/device/control", config.FH2Settings()["host"])
 	resp, err := F.doRequestWithTenant(ctx, http.MethodDelete, url, payLoad)
 	return string(resp), err
 }
@@ -253,7 +358,7 @@ func (F *FH2Adapter) DeleteDeviceControl(ctx context.Context, payLoad io.Reader)
 //				   "quality": "ultra_high_definition"   // 清晰度选项，支持 "adaptive"（自动）、"smooth"（流畅）、"ultra_high_definition"（超清）
 //				}`
 func (F *FH2Adapter) UpdateDeviceStreamQuality(ctx context.Context, payLoad io.Reader) (string, error) {
-	url := fmt.Sprintf("%s/openapi/v0.1/device/stream/quality", config.FH2Settings["host"])
+	url := fmt.Sprintf("%s/openapi/v0.1/device/stream/quality", config.FH2Settings()["host"])
 	resp, err := F.doRequestWithTenant(ctx, http.MethodPut, url, payLoad)
 	return string(resp), err
 }
@@ -271,7 +376,7 @@ func (F *FH2Adapter) CreateDeviceRTK(ctx context.Context, deviceSn string, payLo
 	if err := F.validator.ValidateDeviceSN(deviceSn); err != nil {
 		return "", fmt.Errorf("设备序列号验证失败: %w", err)
 	}
-	url := fmt.Sprintf("%s/openapi/v0.1/device/%s/rtk", config.FH2Settings["host"], deviceSn)
+	url := fmt.Sprintf("%s/openapi/v0.1/device/%s/rtk", config.FH2Settings()["host"], deviceSn)
 	resp, err := F.doRequestWithTenant(ctx, http.MethodPost, url, payLoad)
 	return string(resp), err
 }
@@ -288,7 +393,11 @@ func (F *FH2Adapter) CreateDeviceRTK(ctx context.Context, deviceSn string, payLo
 //				  "quality_type": "adaptive"   // 直播清晰度，adaptive（自动）、smooth（流畅）、ultra_high_definition（超清）
 //				}`
 func (F *FH2Adapter) LiveStreamStart(ctx context.Context, payLoad io.Reader) (string, error) {
-	url := fmt.Sprintf("%s/openapi/v0.1/live-stream/start", config.FH2Settings["host"])
+	payLoad, err := decodeValidated(payLoad, &LiveStreamStartRequest{})
+	if err != nil {
+		return "", err
+	}
+	url := fmt.Sprintf("%s/openapi/v0.1/live-stream/start", config.FH2Settings()["host"])
 	resp, err := F.doRequestWithTenant(ctx, http.MethodPost, url, payLoad)
 	return string(resp), err
 }
@@ -310,11 +419,131 @@ func (F *FH2Adapter) LiveStreamStart(ctx context.Context, payLoad io.Reader) (st
 //			   "min_battery_capacity": 60
 //			}`
 func (F *FH2Adapter) CreateFlightTask(ctx context.Context, payLoad io.Reader) (string, error) {
-	url := fmt.Sprintf("%s/openapi/v0.1/flight-task", config.FH2Settings["host"])
-	resp, err := F.doRequestWithTenant(ctx, http.MethodPost, url, payLoad)
+	req := &FlightTaskRequest{}
+	payLoad, err := decodeValidated(payLoad, req)
+	if err != nil {
+		return "", err
+	}
+	raw, err := io.ReadAll(payLoad)
+	if err != nil {
+		return "", fmt.Errorf("读取请求体失败: %w", err)
+	}
+	if err := F.checkGeofence(ctx, req.WaylineUUID, raw); err != nil {
+		return "", err
+	}
+	// 立即任务下发前做飞前检查（含天气），定时、周期任务执行时的设备状态无法预知，只按执行时段的天气预报检查
+	// 飞前检查未启用或停用了 weather 规则时，立即任务同样经过天气门限
+	var report *preflight.Report
+	if req.TaskType == "immediate" {
+		report = preflight.Run(ctx, preflight.Sources{Telemetry: F, Hms: F, Weather: weather.ForDock(F)}, req.SN, F.droneOf(ctx, req.SN))
+		if err := report.Err(); err != nil {
+			preflight.Record(report, "")
+			return "", err
+		}
+	}
+	if !report.Checked(preflight.RuleWeather) {
+		if err := F.checkWeather(ctx, req); err != nil {
+			return "", err
+		}
+	}
+	url := fmt.Sprintf("%s/openapi/v0.1/flight-task", config.FH2Settings()["host"])
+	resp, err := F.doRequestWithTenant(ctx, http.MethodPost, url, bytes.NewReader(raw))
+	var created struct {
+		Data struct {
+			TaskUUID string `json:"task_uuid"`
+		} `json:"data"`
+	}
+	if err == nil {
+		_ = json.Unmarshal(resp, &created)
+	}
+	if report != nil {
+		preflight.Record(report, created.Data.TaskUUID)
+	}
+	// 任务状态变化由全局任务跟踪器轮询发布，任务结束后归档其媒体
+	if created.Data.TaskUUID != "" {
+		telemetry.WatchTask(ctx, F, created.Data.TaskUUID)
+		media.TrackTask(ctx, F, created.Data.TaskUUID)
+	}
 	return string(resp), err
 }
 
+// droneOf 从项目设备列表中查找机场挂载的飞行器序列号，查找失败时返回空字符串
+func (F *FH2Adapter) droneOf(ctx context.Context, dockSn string) string {
+	resp, err := F.GetDeviceList(ctx)
+	if err != nil {
+		return ""
+	}
+	var list struct {
+		Data struct {
+			List []struct {
+				Gateway struct {
+					SN string `json:"sn"`
+				} `json:"gateway"`
+				Drone *struct {
+					SN string `json:"sn"`
+				} `json:"drone"`
+			} `json:"list"`
+		} `json:"data"`
+	}
+	if json.Unmarshal([]byte(resp), &list) != nil {
+		return ""
+	}
+	for _, pair := range list.Data.List {
+		if pair.Gateway.SN == dockSn && pair.Drone != nil {
+			return pair.Drone.SN
+		}
+	}
+	return ""
+}
+
+// checkWeather 按任务首次执行时段查询机场所在区域的天气预报，超出门限时返回包装 weather.ErrUnsafe 的错误
+// begin_at、end_at 为毫秒时间戳；周期任务只检查首次执行，end_at 为周期结束时间而非单次执行时长
+func (F *FH2Adapter) checkWeather(ctx context.Context, req *FlightTaskRequest) error {
+	if !weather.Enabled() {
+		return nil
+	}
+	q := weather.Query{DockSN: req.SN}
+	if req.BeginAt > 0 {
+		q.Start = time.UnixMilli(req.BeginAt)
+	}
+	if req.TaskType == "timed" && req.EndAt > req.BeginAt {
+		q.End = time.UnixMilli(req.EndAt)
+	}
+	if resp, err := F.GetDeviceState(geo.WithCoordSystem(ctx, geo.WGS84), req.SN); err == nil {
+		if state, err := preflight.ParseDeviceState([]byte(resp)); err == nil && state.Location != nil {
+			q.Location = *state.Location
+		}
+	}
+	_, err := weather.Check(ctx, weather.ForDock(F), q)
+	return err
+}
+
+// checkGeofence 下发任务前校验航线航点与请求中的目标点（WGS-84）是否进入电子围栏
+// 当前租户项目没有围栏时不获取航线；航线文件无法下载、解析或不含航点时拒绝下发；限飞区需通过 geofence.WithOverride 人工确认
+func (F *FH2Adapter) checkGeofence(ctx context.Context, waylineUUID string, payLoad []byte) error {
+	scope := geofence.ScopeFromContext(ctx)
+	if !geofence.HasZones(scope) {
+		return nil
+	}
+	paths, err := geo.ExtractPaths(payLoad)
+	if err != nil {
+		return fmt.Errorf("解析任务目标点失败: %w", err)
+	}
+	info, err := F.GetWayLineInfo(ctx, waylineUUID)
+	if err != nil {
+		return fmt.Errorf("获取航线信息失败，无法校验电子围栏: %w", err)
+	}
+	w, err := wayline.Download(ctx, F.secureClient, info)
+	if err != nil {
+		return fmt.Errorf("获取航线 %s 的航点失败，无法校验电子围栏: %w", waylineUUID, err)
+	}
+	path := w.Path()
+	if len(path) == 0 {
+		return fmt.Errorf("航线 %s 不含航点，无法校验电子围栏", waylineUUID)
+	}
+	return geofence.Enforce(ctx, scope, append(paths, path)...)
+}
+
 // UpdateFlightTaskStatus 更新飞行状态
 //
 //	payLoad := `{
@@ -324,8 +553,12 @@ func (F *FH2Adapter) UpdateFlightTaskStatus(ctx context.Context, taskUUID string
 	if err := F.validator.ValidateUUID(taskUUID); err != nil {
 		return "", fmt.Errorf("UUID验证失败: %w", err)
 	}
-	url := fmt.Sprintf("%s/openapi/v0.1/flight-task/%s/status", config.FH2Settings["host"], taskUUID)
+	url := fmt.Sprintf("%s/openapi/v0.1/flight-task/%s/status", config.FH2Settings()["host"], taskUUID)
 	resp, err := F.doRequestWithTenant(ctx, http.MethodPut, url, payLoad)
+	if err == nil {
+		telemetry.WatchTask(ctx, F, taskUUID)
+		media.TrackTask(ctx, F, taskUUID)
+	}
 	return string(resp), err
 }
 
@@ -334,7 +567,7 @@ func (F *FH2Adapter) GetFlightTaskInfo(ctx context.Context, taskUUID string) (st
 	if err := F.validator.ValidateUUID(taskUUID); err != nil {
 		return "", fmt.Errorf("UUID验证失败: %w", err)
 	}
-	url := fmt.Sprintf("%s/openapi/v0.1/flight-task/%s", config.FH2Settings["host"], taskUUID)
+	url := fmt.Sprintf("%s/openapi/v0.1/flight-task/%s", config.FH2Settings()["host"], taskUUID)
 	resp, err := F.doRequestWithTenant(ctx, http.MethodGet, url, nil)
 	return string(resp), err
 }
@@ -347,7 +580,7 @@ func (F *FH2Adapter) GetFlightTask(ctx context.Context, sn string, name string,
 	if err := F.validator.ValidateProjectName(name); err != nil {
 		return "", fmt.Errorf("任务名称验证失败: %w", err)
 	}
-	url := fmt.Sprintf("%s/openapi/v0.1/flight-task/list?sn=%s&name=%s&begin_at=%d&end_at=%d&task_type=%s&status=%s", config.FH2Settings["host"], sn, name, beginAt, endAt, taskType, status)
+	url := fmt.Sprintf("%s/openapi/v0.1/flight-task/list?sn=%s&name=%s&begin_at=%d&end_at=%d&task_type=%s&status=%s", config.FH2Settings()["host"], sn, name, beginAt, endAt, taskType, status)
 	resp, err := F.doRequestWithTenant(ctx, http.MethodGet, url, nil)
 	return string(resp), err
 }
@@ -357,7 +590,7 @@ func (F *FH2Adapter) GetFlightTaskMedia(ctx context.Context, taskUUID string) (s
 	if err := F.validator.ValidateUUID(taskUUID); err != nil {
 		return "", fmt.Errorf("UUID验证失败: %w", err)
 	}
-	url := fmt.Sprintf("%s/openapi/v0.1/flight-task/%s/media", config.FH2Settings["host"], taskUUID)
+	url := fmt.Sprintf("%s/openapi/v0.1/flight-task/%s/media", config.FH2Settings()["host"], taskUUID)
 	resp, err := F.doRequestWithTenant(ctx, http.MethodGet, url, nil)
 	return string(resp), err
 }
@@ -367,22 +600,59 @@ func (F *FH2Adapter) GetFlightTaskTrack(ctx context.Context, taskUUID string) (s
 	if err := F.validator.ValidateUUID(taskUUID); err != nil {
 		return "", fmt.Errorf("UUID验证失败: %w", err)
 	}
-	url := fmt.Sprintf("%s/openapi/v0.1/flight-task/%s/track", config.FH2Settings["host"], taskUUID)
+	url := fmt.Sprintf("%s/openapi/v0.1/flight-task/%s/track", config.FH2Settings()["host"], taskUUID)
 	resp, err := F.doRequestWithTenant(ctx, http.MethodGet, url, nil)
-	return string(resp), err
+	if err != nil {
+		return "", err
+	}
+	return convertCoords(ctx, resp)
 }
 
 // SetFinishUpload 航线上传完成通知
 func (F *FH2Adapter) SetFinishUpload(ctx context.Context, objectKeyPrefix string, fileName string) (string, error) {
-	url := fmt.Sprintf("%s/openapi/v0.1/wayline/finish-upload", config.FH2Settings["host"])
-	payload := strings.NewReader(fmt.Sprintf(`{"name":"%s","object_key":"%s"}`, fileName, objectKeyPrefix))
-	resp, err := F.doRequestWithTenant(ctx, http.MethodPost, url, payload)
+	url := fmt.Sprintf("%s/openapi/v0.1/wayline/finish-upload", config.FH2Settings()["host"])
+	payload, err := json.Marshal(map[string]string{"name": fileName, "object_key": objectKeyPrefix})
+	if err != nil {
+		return "", fmt.Errorf("生成请求体失败: %w", err)
+	}
+	resp, err := F.doRequestWithTenant(ctx, http.MethodPost, url, bytes.NewReader(payload))
 	return string(resp), err
 }
 
+// UploadWayline 上传航线文件：获取项目存储凭证，将 KMZ 上传（大文件分片、可续传）到凭证指定的存储桶与前缀，再通知司空2上传完成
+// name 为航线名称，对象键为 前缀/name.kmz；同名文件再次上传时续传未完成的分片
+func (F *FH2Adapter) UploadWayline(ctx context.Context, name string, kmz io.Reader) (string, error) {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".kmz")
+	if name == "" || strings.ContainsAny(name, "/\\") {
+		return "", fmt.Errorf("航线名称验证失败: 名称不能为空且不能包含路径分隔符")
+	}
+	tokenResp, err := F.GetProjectStsToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("获取存储上传凭证失败: %w", err)
+	}
+	token, err := objectstore.ParseSTSToken(tokenResp)
+	if err != nil {
+		return "", err
+	}
+	client, err := objectstore.NewClient(token, F.secureClient)
+	if err != nil {
+		return "", err
+	}
+	uploader := objectstore.NewUploader(client)
+	uploader.ContentType = "application/vnd.google-earth.kmz"
+	result, err := uploader.Upload(ctx, token.ObjectKey(name+".kmz"), kmz)
+	if err != nil {
+		return "", fmt.Errorf("上传航线文件失败: %w", err)
+	}
+	if result.ResumedParts > 0 {
+		log.Printf("航线文件 %s 续传完成，跳过已上传分片 %d/%d", result.Key, result.ResumedParts, result.Parts)
+	}
+	return F.SetFinishUpload(ctx, result.Key, name)
+}
+
 // GetWayLine 获取项目下航线列表
 func (F *FH2Adapter) GetWayLine(ctx context.Context) (string, error) {
-	url := fmt.Sprintf("%s/openapi/v0.1/wayline", config.FH2Settings["host"])
+	url := fmt.Sprintf("%s/openapi/v0.1/wayline", config.FH2Settings()["host"])
 	resp, err := F.doRequestWithTenant(ctx, http.MethodGet, url, nil)
 	return string(resp), err
 }
@@ -392,36 +662,55 @@ func (F *FH2Adapter) GetWayLineInfo(ctx context.Context, wayLineUuid string) (st
 	if err := F.validator.ValidateUUID(wayLineUuid); err != nil {
 		return "", fmt.Errorf("UUID验证失败: %w", err)
 	}
-	url := fmt.Sprintf("%s/openapi/v0.1/wayline/%s", config.FH2Settings["host"], wayLineUuid)
+	url := fmt.Sprintf("%s/openapi/v0.1/wayline/%s", config.FH2Settings()["host"], wayLineUuid)
 	resp, err := F.doRequestWithTenant(ctx, http.MethodGet, url, nil)
 	return string(resp), err
 }
 
 // CreateModel 模型重建
 func (F *FH2Adapter) CreateModel(ctx context.Context, payLoad io.Reader) (string, error) {
-	url := fmt.Sprintf("%s/openapi/v0.1/model/create", config.FH2Settings["host"])
+	url := fmt.Sprintf("%s/openapi/v0.1/model/create", config.FH2Settings()["host"])
 	resp, err := F.doRequestWithTenant(ctx, http.MethodPost, url, payLoad)
 	return string(resp), err
 }
 
 // GetModelInfo 获取模型详情
 func (F *FH2Adapter) GetModelInfo(ctx context.Context, modelId int64) (string, error) {
-	url := fmt.Sprintf("%s/openapi/v0.1/model/%s", config.FH2Settings["host"], modelId)
+	url := fmt.Sprintf("%s/openapi/v0.1/model/%d", config.FH2Settings()["host"], modelId)
 	resp, err := F.doRequestWithTenant(ctx, http.MethodGet, url, nil)
 	return string(resp), err
 }
 
 // GetModelList 获取项目下模型列表
 func (F *FH2Adapter) GetModelList(ctx context.Context) (string, error) {
-	url := fmt.Sprintf("%s//openapi/v0.1/model", config.FH2Settings["host"])
+	url := fmt.Sprintf("%s/openapi/v0.1/model", config.FH2Settings()["host"])
 	resp, err := F.doRequestWithTenant(ctx, http.MethodGet, url, nil)
 	return string(resp), err
 }
 
+// 编译期校验 FH2Adapter 提供的能力
+var (
+	_ service.FH2DroneAdapter     = (*FH2Adapter)(nil)
+	_ service.TaskCreator         = (*FH2Adapter)(nil)
+	_ service.LiveStreamer        = (*FH2Adapter)(nil)
+	_ service.DeviceController    = (*FH2Adapter)(nil)
+	_ service.TelemetrySource     = (*FH2Adapter)(nil)
+	_ service.MediaProvider       = (*FH2Adapter)(nil)
+	_ service.DeviceLocator       = (*FH2Adapter)(nil)
+	_ service.HmsProvider         = (*FH2Adapter)(nil)
+	_ service.PluginHealthChecker = (*FH2Adapter)(nil)
+)
+
 // 实例化 FH2Adapter 并注册到插件系统（自动注册）
 func init() {
-	// 注册 FH2 适配器插件
-	plugin.RegisterPlugin(plugin.FH2Plugin, reflect.TypeOf((*service.FH2DroneAdapter)(nil)).Elem(), func() interface{} {
+	// 注册 FH2 适配器插件（单例：多租户共享同一连接池）
+	plugin.RegisterPluginWithScope(plugin.FH2Plugin, reflect.TypeOf((*service.FH2DroneAdapter)(nil)).Elem(), plugin.ScopeSingleton, func() interface{} {
 		return NewFH2Adapter()
 	})
+	plugin.RegisterPluginMeta(plugin.FH2Plugin, plugin.PluginMeta{
+		Version:      "0.1.0",
+		Vendor:       "DJI",
+		Description:  "大疆司空2 OpenAPI 适配器",
+		Capabilities: []string{"project", "device", "flight_task", "live_stream", "media", "telemetry", "wayline", "model"},
+	})
 }
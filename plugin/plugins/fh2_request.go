@@ -0,0 +1,83 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+)
+
+// FlightTaskRequest 创建飞行任务请求体
+type FlightTaskRequest struct {
+	Name                        string      `json:"name" validate:"required,max=35"`
+	WaylineUUID                 string      `json:"wayline_uuid" validate:"required,uuid"`
+	SN                          string      `json:"sn" validate:"required,sn"`
+	RthAltitude                 int         `json:"rth_altitude" validate:"omitempty,min=20,max=1500"`
+	RthMode                     string      `json:"rth_mode" validate:"omitempty,oneof=optimal preset"`
+	WaylinePrecisionType        string      `json:"wayline_precision_type" validate:"omitempty,oneof=gps rtk"`
+	OutOfControlActionInFlight  string      `json:"out_of_control_action_in_flight" validate:"omitempty,oneof=return_home continue_task"`
+	ResumableStatus             string      `json:"resumable_status" validate:"omitempty,oneof=auto manual"`
+	TaskType                    string      `json:"task_type" validate:"required,oneof=immediate timed recurring continuous"`
+	TimeZone                    string      `json:"time_zone" validate:"omitempty,timezone"`
+	RepeatType                  string      `json:"repeat_type" validate:"omitempty,oneof=nonrepeating daily weekly absolute_monthly relative_monthly"`
+	RepeatOption                interface{} `json:"repeat_option,omitempty"`
+	BeginAt                     int64       `json:"begin_at,omitempty" validate:"min=0"`
+	EndAt                       int64       `json:"end_at,omitempty" validate:"min=0"`
+	MinBatteryCapacity          int         `json:"min_battery_capacity" validate:"omitempty,min=0,max=100"`
+	MinStorageCapacity          int         `json:"min_storage_capacity,omitempty" validate:"min=0"`
+	WaylineExecuteIntervalHours int         `json:"wayline_execute_interval_hours,omitempty" validate:"min=0"`
+}
+
+// Validate 校验请求参数
+func (r *FlightTaskRequest) Validate() error {
+	return validator.Validate(r)
+}
+
+// DeviceCommandRequest 实时控制指令请求体
+type DeviceCommandRequest struct {
+	DeviceCommand string `json:"device_command" validate:"required,oneof=return_home return_specific_home return_home_cancel flighttask_pause flighttask_recovery"`
+}
+
+// Validate 校验请求参数
+func (r *DeviceCommandRequest) Validate() error {
+	return validator.Validate(r)
+}
+
+// LiveStreamStartRequest 开启直播请求体
+type LiveStreamStartRequest struct {
+	SN          string `json:"sn" validate:"required,sn"`
+	CameraIndex string `json:"camera_index" validate:"required,max=20"`
+	VideoExpire int    `json:"video_expire" validate:"omitempty,min=1,max=86400"`
+	QualityType string `json:"quality_type" validate:"omitempty,oneof=adaptive smooth ultra_high_definition"`
+}
+
+// Validate 校验请求参数
+func (r *LiveStreamStartRequest) Validate() error {
+	return validator.Validate(r)
+}
+
+// errEmptyBody 请求体为空
+var errEmptyBody = validator.ValidationErrors{{Field: "body", Rule: "required", Message: "请求体不能为空"}}
+
+// decodeValidated 解析并校验请求体，返回可再次读取的原始请求体（保留未声明的字段）；请求体为空时返回校验错误
+func decodeValidated(payLoad io.Reader, req interface{ Validate() error }) (io.Reader, error) {
+	if payLoad == nil {
+		return nil, errEmptyBody
+	}
+	raw, err := io.ReadAll(payLoad)
+	if err != nil {
+		return nil, fmt.Errorf("读取请求体失败: %w", err)
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, errEmptyBody
+	}
+	if err := json.Unmarshal(raw, req); err != nil {
+		return nil, fmt.Errorf("解析请求体失败: %w", err)
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(raw), nil
+}
@@ -0,0 +1,49 @@
+package plugins
+
+import (
+	"errors"
+	"testing"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+)
+
+func TestFlightTaskRequestValidate(t *testing.T) {
+	valid := func() FlightTaskRequest {
+		return FlightTaskRequest{
+			Name:        "巡检",
+			WaylineUUID: "6d88fbe5-a399-485a-86ba-7bbdbb99edec",
+			SN:          "7CTXN4A00B0001H",
+			TaskType:    "immediate",
+		}
+	}
+
+	// 未填写返航高度时由司空2使用默认值
+	req := valid()
+	if err := req.Validate(); err != nil {
+		t.Fatalf("未填写返航高度应通过校验: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		modify func(*FlightTaskRequest)
+		field  string
+		rule   string
+	}{
+		{"返航高度过低", func(r *FlightTaskRequest) { r.RthAltitude = 10 }, "rth_altitude", "min"},
+		{"返航高度过高", func(r *FlightTaskRequest) { r.RthAltitude = 2000 }, "rth_altitude", "max"},
+		{"航线UUID非法", func(r *FlightTaskRequest) { r.WaylineUUID = "abc" }, "wayline_uuid", "uuid"},
+		{"任务类型非法", func(r *FlightTaskRequest) { r.TaskType = "once" }, "task_type", "oneof"},
+		{"时区非法", func(r *FlightTaskRequest) { r.TimeZone = "Mars/Base" }, "time_zone", "timezone"},
+		{"电量阈值越界", func(r *FlightTaskRequest) { r.MinBatteryCapacity = 101 }, "min_battery_capacity", "max"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := valid()
+			tc.modify(&req)
+			var verrs validator.ValidationErrors
+			if err := req.Validate(); !errors.As(err, &verrs) || len(verrs) != 1 || verrs[0].Field != tc.field || verrs[0].Rule != tc.rule {
+				t.Fatalf("应返回 %s 的 %s 校验错误，实际 %v", tc.field, tc.rule, err)
+			}
+		})
+	}
+}
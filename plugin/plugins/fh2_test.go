@@ -0,0 +1,367 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gitee.com/jamespi/drone_dispatch/config"
+	"gitee.com/jamespi/drone_dispatch/pkg/fh2mock"
+	"gitee.com/jamespi/drone_dispatch/pkg/geo"
+	"gitee.com/jamespi/drone_dispatch/pkg/geofence"
+	"gitee.com/jamespi/drone_dispatch/pkg/httpclient"
+	"gitee.com/jamespi/drone_dispatch/pkg/telemetry"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/pkg/validator"
+	"gitee.com/jamespi/drone_dispatch/pkg/wayline"
+)
+
+// record 为 true 时访问进程内 fh2mock 重新录制磁带：go test ./plugin/plugins -run FH2 -args -record
+var record = flag.Bool("record", false, "访问进程内 fh2mock 重新录制 testdata/cassettes 下的磁带")
+
+const (
+	cassetteHost   = "http://fh2.test" // 磁带中的司空2地址，录制时改写到进程内模拟服务
+	cassetteToken  = "cassette-user-token"
+	cassetteDock   = "7CTXN4A00B0001H"
+	cassetteDock2  = "7CTXN4A00B0002H"
+	cassetteCamera = "81-0-0"
+)
+
+var cassetteStart = time.Date(2025, 6, 1, 2, 0, 0, 0, time.UTC)
+
+// hostRewriter 录制时把 cassetteHost 的请求转发到模拟服务，Host 请求头保持不变，使响应中的地址仍指向 cassetteHost
+type hostRewriter struct {
+	host string
+}
+
+func (h hostRewriter) RoundTrip(req *http.Request) (*http.Response, error) {
+	clone := req.Clone(req.Context())
+	clone.URL.Host = h.host
+	return http.DefaultTransport.RoundTrip(clone)
+}
+
+// noRequests 校验失败的调用不应发出任何请求
+type noRequests struct {
+	t *testing.T
+}
+
+func (n noRequests) RoundTrip(req *http.Request) (*http.Response, error) {
+	n.t.Errorf("参数校验失败时不应发出请求: %s %s", req.Method, req.URL)
+	return nil, errors.New("unexpected request")
+}
+
+// cassette 单个磁带场景：录制模式下启动模拟服务，回放模式下只读取磁带
+type cassette struct {
+	adapter *FH2Adapter
+	ctx     context.Context
+	path    string
+	now     atomic.Int64 // 模拟服务时钟（Unix毫秒），录制时由场景推进
+}
+
+// newCassette 创建磁带场景，测试结束时写入（录制模式）磁带并恢复全局配置
+func newCassette(t *testing.T, name string) *cassette {
+	t.Helper()
+	c := &cassette{path: filepath.Join("testdata", "cassettes", name+".json")}
+	c.now.Store(cassetteStart.UnixMilli())
+
+	mode, transport := httpclient.ModeReplay, http.RoundTripper(nil)
+	if *record {
+		opts := fh2mock.DefaultOptions()
+		opts.UserToken = cassetteToken
+		opts.Clock = func() time.Time { return time.UnixMilli(c.now.Load()) }
+		_, server := fh2mock.NewTestServer(opts)
+		t.Cleanup(server.Close)
+		target, err := url.Parse(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mode, transport = httpclient.ModeRecord, hostRewriter{host: target.Host}
+	}
+	recorder, err := httpclient.NewRecorder(c.path, mode, transport)
+	if err != nil {
+		t.Fatalf("加载磁带失败（首次运行请加 -args -record 录制）: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := recorder.Stop(); err != nil {
+			t.Errorf("写入磁带失败: %v", err)
+		}
+	})
+
+	prev := config.FH2Settings()
+	config.SetFH2Settings(map[string]string{"host": cassetteHost, "xUserToken": cassetteToken, "q": "模拟"})
+	t.Cleanup(func() {
+		config.SetFH2Settings(map[string]string{"host": prev["host"], "xUserToken": prev["xUserToken"], "q": prev["q"]})
+	})
+
+	c.adapter = NewFH2AdapterWithClient(httpclient.NewSecureHTTPClientWithTransport(recorder))
+	c.ctx = tenant.WithTenant(context.Background(), tenant.NewTenantInfo(1, cassetteToken, fh2mock.DefaultOptions().ProjectUUID))
+	return c
+}
+
+// advance 推进模拟服务时钟，回放模式下无影响
+func (c *cassette) advance(d time.Duration) {
+	c.now.Add(d.Milliseconds())
+}
+
+// requireOK 校验调用成功且业务码为0，返回 data 字段
+func requireOK(t *testing.T, method string, resp string, err error) json.RawMessage {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s 失败: %v", method, err)
+	}
+	var body APIResponse
+	if err := json.Unmarshal([]byte(resp), &body); err != nil {
+		t.Fatalf("%s 响应不是合法JSON: %v\n%s", method, err, resp)
+	}
+	if body.Code != 0 {
+		t.Fatalf("%s 返回业务错误[%d]: %s", method, body.Code, body.Message)
+	}
+	return body.Data
+}
+
+func requireContains(t *testing.T, method, resp string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(resp, w) {
+			t.Errorf("%s 响应应包含 %q:\n%s", method, w, resp)
+		}
+	}
+}
+
+func TestFH2DeviceCassette(t *testing.T) {
+	c := newCassette(t, "fh2_device")
+	a, ctx := c.adapter, c.ctx
+
+	resp, err := a.GetprojectList(ctx)
+	requireOK(t, "GetprojectList", resp, err)
+	requireContains(t, "GetprojectList", resp, fh2mock.DefaultOptions().ProjectUUID)
+
+	resp, err = a.GetDeviceList(ctx)
+	requireOK(t, "GetDeviceList", resp, err)
+	requireContains(t, "GetDeviceList", resp, cassetteDock, cassetteDock2)
+
+	if ok, err := a.HasDevice(ctx, cassetteDock); err != nil || !ok {
+		t.Errorf("HasDevice(%s) = %v, %v，应为 true", cassetteDock, ok, err)
+	}
+	if ok, err := a.HasDevice(ctx, "7CTXN4A00B9999H"); err != nil || ok {
+		t.Errorf("HasDevice(未知设备) = %v, %v，应为 false", ok, err)
+	}
+
+	resp, err = a.GetProjectStsToken(ctx)
+	requireOK(t, "GetProjectStsToken", resp, err)
+	requireContains(t, "GetProjectStsToken", resp, "bucket", "object_key_prefix")
+
+	resp, err = a.GetStsToken(ctx, cassetteDock)
+	requireOK(t, "GetStsToken", resp, err)
+	resp, err = a.GetDeviceState(ctx, cassetteDock)
+	requireOK(t, "GetDeviceState", resp, err)
+	requireContains(t, "GetDeviceState", resp, "latitude", "longitude")
+
+	resp, err = a.GetDeviceHms(ctx, cassetteDock)
+	requireOK(t, "GetDeviceHms", resp, err)
+
+	body := fmt.Sprintf(`{"sn":"%s","camera_index":"%s"`, cassetteDock, cassetteCamera)
+	resp, err = a.UpdateDeviceChangeCamera(ctx, strings.NewReader(body+`,"camera_type":"wide"}`))
+	requireOK(t, "UpdateDeviceChangeCamera", resp, err)
+	resp, err = a.UpdateDeviceChangeLens(ctx, strings.NewReader(body+`,"video_type":"zoom"}`))
+	requireOK(t, "UpdateDeviceChangeLens", resp, err)
+	resp, err = a.UpdateDeviceStreamQuality(ctx, strings.NewReader(body+`,"quality_type":"smooth"}`))
+	requireOK(t, "UpdateDeviceStreamQuality", resp, err)
+
+	control := fmt.Sprintf(`{"sn":"%s","payload_index":["%s"]}`, cassetteDock, cassetteCamera)
+	resp, err = a.GetDeviceControl(ctx, strings.NewReader(control))
+	requireOK(t, "GetDeviceControl", resp, err)
+	resp, err = a.DeleteDeviceControl(ctx, strings.NewReader(control))
+	requireOK(t, "DeleteDeviceControl", resp, err)
+
+	resp, err = a.CreateDeviceRTK(ctx, cassetteDock, strings.NewReader(`{"host":"rtk.example.com","port":8001,"mount_point":"RTCM33","username":"u","password":"p"}`))
+	requireOK(t, "CreateDeviceRTK", resp, err)
+
+	resp, err = a.LiveStreamStart(ctx, strings.NewReader(body+`,"video_expire":3600,"quality_type":"adaptive"}`))
+	requireOK(t, "LiveStreamStart", resp, err)
+	requireContains(t, "LiveStreamStart", resp, "rtmp://", cassetteDock)
+
+	// 回放的磁带中不能包含令牌与存储密钥
+	if !*record {
+		data, err := os.ReadFile(c.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), cassetteToken) || !strings.Contains(string(data), `access_key_secret\":\"`+httpclient.RedactedValue) {
+			t.Errorf("磁带 %s 中包含未脱敏的令牌或存储密钥", c.path)
+		}
+	}
+}
+
+func TestFH2FlightTaskCassette(t *testing.T) {
+	c := newCassette(t, "fh2_flight_task")
+	a, ctx := c.adapter, c.ctx
+
+	resp, err := a.GetWayLine(ctx)
+	requireOK(t, "GetWayLine", resp, err)
+	const waylineUUID = "6d88fbe5-a399-485a-86ba-7bbdbb99edec"
+	requireContains(t, "GetWayLine", resp, waylineUUID)
+	resp, err = a.GetWayLineInfo(ctx, waylineUUID)
+	requireOK(t, "GetWayLineInfo", resp, err)
+	requireContains(t, "GetWayLineInfo", resp, "download_url")
+
+	task := fmt.Sprintf(`{"name":"回归测试","wayline_uuid":"%s","sn":"%s","rth_altitude":80,"rth_mode":"optimal","task_type":"immediate","time_zone":"Asia/Shanghai","min_battery_capacity":60}`, waylineUUID, cassetteDock)
+	resp, err = a.CreateFlightTask(ctx, strings.NewReader(task))
+	data := requireOK(t, "CreateFlightTask", resp, err)
+	var created struct {
+		TaskUUID string `json:"task_uuid"`
+	}
+	if err := json.Unmarshal(data, &created); err != nil || created.TaskUUID == "" {
+		t.Fatalf("CreateFlightTask 未返回任务UUID: %s", resp)
+	}
+	// 测试结束后停止全局任务跟踪器对该任务的后台轮询
+	t.Cleanup(func() { telemetry.DefaultTaskWatcher().Unwatch(created.TaskUUID) })
+
+	resp, err = a.UpdateFlightTaskStatus(ctx, created.TaskUUID, strings.NewReader(`{"status":"suspended"}`))
+	requireOK(t, "UpdateFlightTaskStatus(suspended)", resp, err)
+	resp, err = a.UpdateFlightTaskStatus(ctx, created.TaskUUID, strings.NewReader(`{"status":"restored"}`))
+	requireOK(t, "UpdateFlightTaskStatus(restored)", resp, err)
+
+	c.advance(30 * time.Second)
+	resp, err = a.GetFlightTaskInfo(ctx, created.TaskUUID)
+	requireOK(t, "GetFlightTaskInfo", resp, err)
+	// 全局任务跟踪器在后台查询同一接口，磁带中的顺序不确定，只校验任务UUID
+	requireContains(t, "GetFlightTaskInfo", resp, created.TaskUUID)
+
+	resp, err = a.UpdateDeviceCommand(ctx, cassetteDock, strings.NewReader(`{"device_command":"flighttask_pause"}`))
+	requireOK(t, "UpdateDeviceCommand(pause)", resp, err)
+	resp, err = a.UpdateDeviceCommand(ctx, cassetteDock, strings.NewReader(`{"device_command":"flighttask_recovery"}`))
+	requireOK(t, "UpdateDeviceCommand(recovery)", resp, err)
+
+	resp, err = a.GetFlightTask(ctx, cassetteDock, "回归", 0, 0, "", "")
+	requireOK(t, "GetFlightTask", resp, err)
+	requireContains(t, "GetFlightTask", resp, created.TaskUUID)
+
+	c.advance(5 * time.Minute)
+	resp, err = a.GetFlightTaskTrack(ctx, created.TaskUUID)
+	requireOK(t, "GetFlightTaskTrack", resp, err)
+	resp, err = a.GetFlightTaskMedia(ctx, created.TaskUUID)
+	requireOK(t, "GetFlightTaskMedia", resp, err)
+	requireContains(t, "GetFlightTaskMedia", resp, "__mock/media/"+created.TaskUUID)
+}
+
+// TestFH2GeofenceCassette 按下载的航线文件校验电子围栏，航线无法获取时拒绝下发
+func TestFH2GeofenceCassette(t *testing.T) {
+	c := newCassette(t, "fh2_geofence")
+	a, ctx := c.adapter, c.ctx
+
+	// 禁飞区覆盖示例航线东北角的航点
+	zone, err := geofence.DefaultStore().Add(geofence.Zone{
+		Name:    "回归测试禁飞区",
+		Kind:    geofence.KindNoFly,
+		Scope:   geofence.ScopeFromContext(ctx),
+		Polygon: geo.Polygon{{Lat: 22.5433, Lng: 113.9346}, {Lat: 22.5433, Lng: 113.9352}, {Lat: 22.5438, Lng: 113.9352}, {Lat: 22.5438, Lng: 113.9346}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { geofence.DefaultStore().Remove(zone.ID) })
+
+	const task = `{"name":"围栏测试","wayline_uuid":"%s","sn":"%s","rth_altitude":80,"task_type":"immediate","time_zone":"Asia/Shanghai","min_battery_capacity":60}`
+	_, err = a.CreateFlightTask(ctx, strings.NewReader(fmt.Sprintf(task, "6d88fbe5-a399-485a-86ba-7bbdbb99edec", cassetteDock)))
+	if !errors.Is(err, geofence.ErrNoFlyZone) {
+		t.Errorf("航线进入禁飞区时应拒绝下发，实际: %v", err)
+	}
+	_, err = a.CreateFlightTask(ctx, strings.NewReader(fmt.Sprintf(task, "00000000-0000-4000-8000-000000000000", cassetteDock)))
+	if err == nil || errors.Is(err, geofence.ErrNoFlyZone) {
+		t.Errorf("航线无法获取时应拒绝下发，实际: %v", err)
+	}
+}
+
+func TestFH2WaylineAndModelCassette(t *testing.T) {
+	c := newCassette(t, "fh2_wayline_model")
+	a, ctx := c.adapter, c.ctx
+
+	w := wayline.New(wayline.DroneM3D, wayline.PayloadM3D)
+	w.AddWaypoint(geo.Point{Lat: 22.5431, Lng: 113.9344}, wayline.TakePhoto(0))
+	w.AddWaypoint(geo.Point{Lat: 22.5441, Lng: 113.9354}, wayline.TakePhoto(0))
+	// 固定时间戳，保证每次生成的 KMZ 与磁带中的上传内容一致
+	w.CreateTime, w.UpdateTime = cassetteStart, cassetteStart
+	kmz, err := w.KMZ()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := a.UploadWayline(ctx, "回归测试航线", strings.NewReader(string(kmz)))
+	requireOK(t, "UploadWayline", resp, err)
+	resp, err = a.GetWayLine(ctx)
+	requireOK(t, "GetWayLine", resp, err)
+	requireContains(t, "GetWayLine", resp, "回归测试航线")
+
+	resp, err = a.CreateModel(ctx, strings.NewReader(`{"name":"回归测试模型","model_type":"2d","media_list":[]}`))
+	data := requireOK(t, "CreateModel", resp, err)
+	var created struct {
+		ModelID int64 `json:"model_id"`
+	}
+	if err := json.Unmarshal(data, &created); err != nil || created.ModelID == 0 {
+		t.Fatalf("CreateModel 未返回模型ID: %s", resp)
+	}
+	resp, err = a.GetModelInfo(ctx, created.ModelID)
+	requireOK(t, "GetModelInfo", resp, err)
+	requireContains(t, "GetModelInfo", resp, "回归测试模型")
+	resp, err = a.GetModelList(ctx)
+	requireOK(t, "GetModelList", resp, err)
+}
+
+// TestFH2Validation 参数校验失败时不发出请求
+func TestFH2Validation(t *testing.T) {
+	a := NewFH2AdapterWithClient(httpclient.NewSecureHTTPClientWithTransport(noRequests{t}))
+	ctx := tenant.WithTenant(context.Background(), tenant.NewTenantInfo(1, cassetteToken, fh2mock.DefaultOptions().ProjectUUID))
+
+	cases := []struct {
+		name string
+		call func() (string, error)
+	}{
+		{"设备序列号非法", func() (string, error) { return a.GetDeviceState(ctx, "bad sn") }},
+		{"任务UUID非法", func() (string, error) { return a.GetFlightTaskInfo(ctx, "not-a-uuid") }},
+		{"不支持的控制指令", func() (string, error) {
+			return a.UpdateDeviceCommand(ctx, cassetteDock, strings.NewReader(`{"device_command":"self_destruct"}`))
+		}},
+		{"任务缺少名称", func() (string, error) {
+			return a.CreateFlightTask(ctx, strings.NewReader(`{"wayline_uuid":"6d88fbe5-a399-485a-86ba-7bbdbb99edec","sn":"`+cassetteDock+`","task_type":"immediate","rth_altitude":80}`))
+		}},
+		{"直播清晰度非法", func() (string, error) {
+			return a.LiveStreamStart(ctx, strings.NewReader(`{"sn":"`+cassetteDock+`","camera_index":"81-0-0","quality_type":"8k"}`))
+		}},
+		{"航线名称非法", func() (string, error) { return a.UploadWayline(ctx, "a/b", strings.NewReader("")) }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.call(); err == nil {
+				t.Fatal("应返回校验错误")
+			}
+		})
+	}
+
+	var verrs validator.ValidationErrors
+	_, err := a.UpdateDeviceCommand(ctx, cassetteDock, strings.NewReader(`{}`))
+	if !errors.As(err, &verrs) || len(verrs) != 1 || verrs[0].Field != "device_command" {
+		t.Errorf("缺少必填字段应返回 device_command 的 validator.ValidationErrors，实际 %v", err)
+	}
+
+	// 请求体为空时返回校验错误而不是 panic
+	for name, call := range map[string]func() (string, error){
+		"控制指令": func() (string, error) { return a.UpdateDeviceCommand(ctx, cassetteDock, nil) },
+		"飞行任务": func() (string, error) { return a.CreateFlightTask(ctx, nil) },
+		"开启直播": func() (string, error) { return a.LiveStreamStart(ctx, strings.NewReader(" ")) },
+	} {
+		_, err := call()
+		if !errors.As(err, &verrs) || verrs[0].Field != "body" {
+			t.Errorf("%s请求体为空应返回 body 的 validator.ValidationErrors，实际 %v", name, err)
+		}
+	}
+}
@@ -3,9 +3,15 @@
 package plugin
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
 	"gitee.com/jamespi/drone_dispatch/service"
+	"log"
 	"reflect"
 	"sync"
+	"time"
 )
 
 type PluginType string
@@ -16,72 +22,475 @@ const (
 	DJIPilotPlugin PluginType = "dji_pilot" // DJI Pilot插件
 )
 
+// stopTimeout 禁用/卸载插件时调用 Stop 的超时时间
+const stopTimeout = 10 * time.Second
+
+// InstanceScope 插件实例作用域
+type InstanceScope int
+
+const (
+	ScopeSingleton InstanceScope = iota // 全局单例：首次Get时创建，之后共享
+	ScopeTenant                         // 租户级：按 TenantInfo.TenantId 各自创建并共享
+	ScopeTransient                      // 瞬时：每次Get都创建新实例，由调用方负责关闭
+)
+
+// String 作用域名称
+func (s InstanceScope) String() string {
+	switch s {
+	case ScopeSingleton:
+		return "singleton"
+	case ScopeTenant:
+		return "tenant"
+	case ScopeTransient:
+		return "transient"
+	}
+	return "unknown"
+}
+
+// instanceKey 实例缓存键
+type instanceKey struct {
+	pluginType PluginType
+	ifaceType  reflect.Type
+	tenantId   int64 // 单例作用域固定为0
+}
+
+// instanceEntry 懒加载的插件实例
+type instanceEntry struct {
+	once  sync.Once
+	value interface{}
+	err   error // 创建或初始化失败的原因
+}
+
 type Registry struct {
 	// DroneAdapters is a map of drone types to their respective adapters.
 	mu            sync.RWMutex // 并发安全
 	PluginFactory map[PluginType]map[reflect.Type]func() interface{}
-	Status        map[PluginType]service.PluginStatus // 插件状态
+	Scopes        map[PluginType]map[reflect.Type]InstanceScope // 实例作用域
+	Status        map[PluginType]service.PluginStatus           // 插件状态
+	Configs       map[PluginType]map[string]string              // 插件配置，Init时传入：配置文件声明的配置叠加运行时配置
+	Errors        map[PluginType]error                          // 最近一次启用失败的原因
+	Metas         map[PluginType]PluginMeta                     // 插件元信息
+	instances     map[instanceKey]*instanceEntry                // 已创建的共享实例
+	enableOrder   []PluginType                                  // 启用顺序，停止时逆序执行
+	noFailover    bool                                          // 关闭能力查询的故障切换
+	bases         map[PluginType]PluginType                     // 命名实例 -> 模板插件类型
+	declared      map[PluginType]map[string]string              // 上次 ApplyConfig 声明启用的插件及配置文件中的配置
+	overrides     map[PluginType]map[string]string              // SetPluginConfig 设置的运行时配置，优先于配置文件
 }
 
 // registry 全局单例模式- 使用工厂模式
 var registry = &Registry{
 	PluginFactory: make(map[PluginType]map[reflect.Type]func() interface{}),
+	Scopes:        make(map[PluginType]map[reflect.Type]InstanceScope),
 	Status:        make(map[PluginType]service.PluginStatus),
+	Configs:       make(map[PluginType]map[string]string),
+	Errors:        make(map[PluginType]error),
+	Metas:         make(map[PluginType]PluginMeta),
+	instances:     make(map[instanceKey]*instanceEntry),
+	bases:         make(map[PluginType]PluginType),
+	declared:      make(map[PluginType]map[string]string),
+	overrides:     make(map[PluginType]map[string]string),
 }
 
-// RegisterPlugin 注册插件.
+// RegisterPlugin 注册插件（单例作用域）.
 func RegisterPlugin(pluginType PluginType, ifaceType reflect.Type, Build func() interface{}) {
+	RegisterPluginWithScope(pluginType, ifaceType, ScopeSingleton, Build)
+}
+
+// RegisterPluginWithScope 按指定实例作用域注册插件
+func RegisterPluginWithScope(pluginType PluginType, ifaceType reflect.Type, scope InstanceScope, Build func() interface{}) {
 	registry.mu.Lock()
 	defer registry.mu.Unlock()
 
 	if registry.PluginFactory[pluginType] == nil {
 		registry.PluginFactory[pluginType] = make(map[reflect.Type]func() interface{})
+		registry.Scopes[pluginType] = make(map[reflect.Type]InstanceScope)
 	}
 
 	registry.PluginFactory[pluginType][ifaceType] = Build
-	registry.Status[pluginType] = service.PluginRegistered
+	registry.Scopes[pluginType][ifaceType] = scope
+	if !isRunning(registry.Status[pluginType]) {
+		registry.setStatusLocked(pluginType, service.PluginRegistered, nil)
+	}
+}
+
+// RegisterInstance 以已注册的插件为模板注册一个命名实例，例如多台机场各用一个 dji_dock2 实例
+// 命名实例拥有独立的状态、配置与共享实例，元信息与模板相同；重复注册同一模板的实例不报错
+func RegisterInstance(name, base PluginType) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	ifaceMap, ok := registry.PluginFactory[base]
+	if !ok {
+		return fmt.Errorf("插件 %s 未注册", base)
+	}
+	if _, exists := registry.PluginFactory[name]; exists {
+		if registry.bases[name] == base {
+			return nil
+		}
+		return fmt.Errorf("插件 %s 已注册，不能作为 %s 的实例", name, base)
+	}
+
+	registry.PluginFactory[name] = make(map[reflect.Type]func() interface{}, len(ifaceMap))
+	registry.Scopes[name] = make(map[reflect.Type]InstanceScope, len(ifaceMap))
+	for ifaceType, factory := range ifaceMap {
+		registry.PluginFactory[name][ifaceType] = factory
+		registry.Scopes[name][ifaceType] = registry.Scopes[base][ifaceType]
+	}
+	if meta, ok := registry.Metas[base]; ok {
+		registry.Metas[name] = meta
+	}
+	registry.bases[name] = base
+	registry.setStatusLocked(name, service.PluginRegistered, nil)
+	return nil
+}
+
+// SetPluginConfig 设置插件运行时配置，下次创建实例时通过 Init 传入
+// 运行时配置覆盖配置文件 Plugins 段中的同名配置项，配置重新加载后仍然保留
+func SetPluginConfig(pluginType PluginType, cfg map[string]string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.overrides[pluginType] = cfg
+	registry.Configs[pluginType] = mergeSettings(registry.declared[pluginType], cfg)
+}
+
+// mergeSettings 合并配置文件中的配置与运行时配置，运行时配置优先；均为空时返回 nil
+func mergeSettings(declared, overrides map[string]string) map[string]string {
+	if len(declared) == 0 && len(overrides) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(declared)+len(overrides))
+	for k, v := range declared {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
 }
 
 // Enable 启用插件
-func Enable(pluginType PluginType) {
-	registry.mu.RLock()
-	defer registry.mu.RUnlock()
-	if _, ok := registry.PluginFactory[pluginType]; ok {
-		registry.Status[pluginType] = service.PluginEnabled
+func Enable(pluginType PluginType) error {
+	return EnableWithContext(context.Background(), pluginType)
+}
+
+// EnableWithContext 启用插件
+// 依赖的插件必须已注册并启用；单例作用域的实例会立即创建并依次调用 Init、Start，任一失败则拒绝启用并记录错误
+func EnableWithContext(ctx context.Context, pluginType PluginType) error {
+	registry.mu.Lock()
+	ifaceMap, ok := registry.PluginFactory[pluginType]
+	if !ok {
+		registry.mu.Unlock()
+		return fmt.Errorf("插件 %s 未注册", pluginType)
+	}
+	if isRunning(registry.Status[pluginType]) {
+		registry.mu.Unlock()
+		return nil
+	}
+	if err := registry.checkDependencies(pluginType); err != nil {
+		registry.Errors[pluginType] = err
+		registry.mu.Unlock()
+		return err
+	}
+	factories := make(map[instanceKey]func() interface{})
+	for ifaceType, factory := range ifaceMap {
+		if registry.Scopes[pluginType][ifaceType] == ScopeSingleton {
+			factories[instanceKey{pluginType: pluginType, ifaceType: ifaceType}] = factory
+		}
+	}
+	registry.mu.Unlock()
+
+	for key, factory := range factories {
+		if _, err := registry.sharedInstance(ctx, key, factory, false); err != nil {
+			registry.mu.Lock()
+			registry.Errors[pluginType] = err
+			closing := registry.detachInstances(func(key instanceKey) bool { return key.pluginType == pluginType })
+			registry.mu.Unlock()
+
+			stopInstances(closing)
+			return err
+		}
 	}
+
+	registry.mu.Lock()
+	if _, ok := registry.PluginFactory[pluginType]; !ok {
+		registry.mu.Unlock()
+		return fmt.Errorf("插件 %s 在启用过程中被卸载", pluginType)
+	}
+	event := registry.setStatusLocked(pluginType, service.PluginEnabled, nil)
+	delete(registry.Errors, pluginType)
+	registry.enableOrder = append(registry.enableOrder, pluginType)
+	registry.mu.Unlock()
+
+	publishStatus(event)
+	return nil
 }
 
-// Disable 禁用插件
+// Disable 禁用插件，并停止、关闭已创建的共享实例
+// 依赖该插件的已启用插件会先被禁用
 func Disable(pluginType PluginType) {
+	disableDependents(pluginType)
+
 	registry.mu.Lock()
-	defer registry.mu.Unlock()
+	var event *StatusEvent
 	if _, ok := registry.PluginFactory[pluginType]; ok {
-		registry.Status[pluginType] = service.PluginDisabled
+		event = registry.setStatusLocked(pluginType, service.PluginDisabled, nil)
 	}
+	registry.removeEnableOrder(pluginType)
+	closing := registry.detachInstances(func(key instanceKey) bool { return key.pluginType == pluginType })
+	registry.mu.Unlock()
+
+	stopInstances(closing)
+	publishStatus(event)
 }
 
-// Unload 卸载插件
+// Unload 卸载插件，并停止、关闭已创建的共享实例
+// 依赖该插件的已启用插件会先被禁用
 func Unload(pluginType PluginType) {
+	disableDependents(pluginType)
+
 	registry.mu.Lock()
-	defer registry.mu.Unlock()
+	var event *StatusEvent
 	if _, ok := registry.PluginFactory[pluginType]; ok {
-		registry.Status[pluginType] = service.PluginUnloaded
+		event = registry.setStatusLocked(pluginType, service.PluginUnloaded, nil)
 	}
 	delete(registry.PluginFactory, pluginType)
+	delete(registry.Scopes, pluginType)
+	delete(registry.Errors, pluginType)
+	delete(registry.bases, pluginType)
+	delete(registry.declared, pluginType)
+	registry.removeEnableOrder(pluginType)
+	closing := registry.detachInstances(func(key instanceKey) bool { return key.pluginType == pluginType })
+	registry.mu.Unlock()
+
+	stopInstances(closing)
+	publishStatus(event)
+}
+
+// disableDependents 先禁用依赖该插件的已启用插件
+func disableDependents(pluginType PluginType) {
+	registry.mu.RLock()
+	dependents := registry.enabledDependents(pluginType)
+	registry.mu.RUnlock()
+
+	for _, dependent := range dependents {
+		log.Printf("插件 %s 依赖插件 %s，随之禁用", dependent, pluginType)
+		Disable(dependent)
+	}
+}
+
+// HealthCheck 对插件已创建的共享实例执行健康检查，未实现 HealthCheck 的实例视为健康
+func HealthCheck(ctx context.Context, pluginType PluginType) error {
+	registry.mu.RLock()
+	if !isRunning(registry.Status[pluginType]) {
+		registry.mu.RUnlock()
+		return fmt.Errorf("插件 %s 未启用", pluginType)
+	}
+	var entries []*instanceEntry
+	for key, entry := range registry.instances {
+		if key.pluginType == pluginType {
+			entries = append(entries, entry)
+		}
+	}
+	registry.mu.RUnlock()
+
+	var errs []error
+	for _, entry := range entries {
+		entry.once.Do(func() {})
+		if entry.err != nil {
+			continue
+		}
+		if checker, ok := entry.value.(service.PluginHealthChecker); ok {
+			if err := checker.HealthCheck(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("插件 %s 健康检查失败: %w", pluginType, errors.Join(errs...))
+	}
+	return nil
+}
+
+// ReleaseTenant 关闭并移除指定租户的全部租户级实例（例如租户注销或令牌失效时）
+func ReleaseTenant(tenantId int64) {
+	registry.mu.Lock()
+	closing := registry.detachInstances(func(key instanceKey) bool { return key.tenantId == tenantId && key.tenantId != 0 })
+	registry.mu.Unlock()
+
+	stopInstances(closing)
 }
 
 // Get 获取启用状态下的适配器
-// 判断该插件是否存在并且已返回目标T接口类型的插件实例
+// 判断该插件是否存在、是否已启用（含降级、失败状态），并返回目标T接口类型的插件实例
+// 租户级作用域的插件需要通过 GetWithContext 传入租户上下文
 func Get[T interface{}](pluginType PluginType) (T, bool) {
-	registry.mu.RLock()
-	defer registry.mu.RUnlock()
+	return GetWithContext[T](context.Background(), pluginType)
+}
+
+// GetWithContext 按实例作用域获取插件实例
+// 单例作用域全局共享；租户级作用域按上下文中的 TenantInfo.TenantId 共享；瞬时作用域每次新建
+func GetWithContext[T interface{}](ctx context.Context, pluginType PluginType) (T, bool) {
 	// 泛型参数T的类型实例化
 	var plugin T
 	ifaceType := reflect.TypeOf(&plugin).Elem()
-	if factory, ok := registry.PluginFactory[pluginType][ifaceType]; ok {
-		impl := factory()
-		return impl.(T), true
+
+	registry.mu.RLock()
+	factory, ok := registry.PluginFactory[pluginType][ifaceType]
+	scope := registry.Scopes[pluginType][ifaceType]
+	enabled := isRunning(registry.Status[pluginType])
+	registry.mu.RUnlock()
+	if !ok || !enabled {
+		return plugin, false
+	}
+
+	impl, err := registry.instance(ctx, pluginType, ifaceType, scope, factory)
+	if err != nil {
+		log.Printf("获取插件 %s 实例失败: %v", pluginType, err)
+		return plugin, false
 	}
 
+	if typed, ok := impl.(T); ok {
+		return typed, true
+	}
 	return plugin, false
 }
+
+// instance 按实例作用域获取插件实例
+// 共享实例的生命周期不属于任何一次请求，懒加载时使用后台上下文调用 Init、Start（租户级实例保留租户信息），
+// 避免首个请求的取消或超时传递给实例
+func (r *Registry) instance(ctx context.Context, pluginType PluginType, ifaceType reflect.Type, scope InstanceScope, factory func() interface{}) (interface{}, error) {
+	switch scope {
+	case ScopeTransient:
+		return r.newInstance(ctx, pluginType, factory)
+	case ScopeTenant:
+		tenantInfo, err := tenant.GetTenantFromContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("插件 %s 为租户级作用域，获取租户信息失败: %w", pluginType, err)
+		}
+		key := instanceKey{pluginType: pluginType, ifaceType: ifaceType, tenantId: tenantInfo.TenantId}
+		return r.sharedInstance(tenant.WithTenant(context.Background(), tenantInfo), key, factory, true)
+	}
+	return r.sharedInstance(context.Background(), instanceKey{pluginType: pluginType, ifaceType: ifaceType}, factory, true)
+}
+
+// sharedInstance 获取或懒加载共享实例，创建在锁外执行且只执行一次；创建失败的实例会被移除以便重试
+// lazy 为 true 时（按需获取）只在插件仍处于启用状态时登记新实例，与 Disable 在同一把锁内判断，
+// 避免禁用后才登记的实例无人停止
+func (r *Registry) sharedInstance(ctx context.Context, key instanceKey, factory func() interface{}, lazy bool) (interface{}, error) {
+	r.mu.Lock()
+	entry, ok := r.instances[key]
+	if !ok {
+		if lazy && !isRunning(r.Status[key.pluginType]) {
+			r.mu.Unlock()
+			return nil, fmt.Errorf("插件 %s 未启用", key.pluginType)
+		}
+		entry = &instanceEntry{}
+		r.instances[key] = entry
+	}
+	r.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.value, entry.err = r.newInstance(ctx, key.pluginType, factory)
+	})
+	if entry.err != nil {
+		r.mu.Lock()
+		if r.instances[key] == entry {
+			delete(r.instances, key)
+		}
+		r.mu.Unlock()
+		return nil, entry.err
+	}
+	return entry.value, nil
+}
+
+// newInstance 创建插件实例并按需调用 Init、Start
+func (r *Registry) newInstance(ctx context.Context, pluginType PluginType, factory func() interface{}) (interface{}, error) {
+	return r.initInstance(ctx, pluginType, factory())
+}
+
+// initInstance 对工厂创建的实例按需调用 Init、Start，失败时关闭实例
+func (r *Registry) initInstance(ctx context.Context, pluginType PluginType, impl interface{}) (interface{}, error) {
+	r.mu.RLock()
+	cfg := r.Configs[pluginType]
+	r.mu.RUnlock()
+
+	if initializer, ok := impl.(service.PluginInitializer); ok {
+		if err := initializer.Init(ctx, cfg); err != nil {
+			closeInstance(impl)
+			return nil, fmt.Errorf("插件 %s 初始化失败: %w", pluginType, err)
+		}
+	}
+	if starter, ok := impl.(service.PluginStarter); ok {
+		if err := starter.Start(ctx); err != nil {
+			closeInstance(impl)
+			return nil, fmt.Errorf("插件 %s 启动失败: %w", pluginType, err)
+		}
+	}
+	return impl, nil
+}
+
+// detachInstances 移除满足条件的共享实例并返回，调用方需持有写锁
+func (r *Registry) detachInstances(match func(instanceKey) bool) []*instanceEntry {
+	var detached []*instanceEntry
+	for key, entry := range r.instances {
+		if match(key) {
+			detached = append(detached, entry)
+			delete(r.instances, key)
+		}
+	}
+	return detached
+}
+
+// instanceCount 统计插件已创建的共享实例数，调用方需持有读锁
+func (r *Registry) instanceCount(pluginType PluginType) int {
+	count := 0
+	for key := range r.instances {
+		if key.pluginType == pluginType {
+			count++
+		}
+	}
+	return count
+}
+
+// stopInstances 停止并关闭实例：先调用 Stop，再调用 Close
+func stopInstances(entries []*instanceEntry) {
+	for _, entry := range entries {
+		// 等待正在进行的懒加载完成，避免与创建过程竞争
+		entry.once.Do(func() {})
+		if entry.err != nil {
+			continue
+		}
+		stopInstance(entry.value)
+	}
+}
+
+// stopInstance 停止并关闭单个实例：先调用 Stop，再调用 Close
+func stopInstance(impl interface{}) {
+	if stopper, ok := impl.(service.PluginStopper); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+		if err := stopper.Stop(ctx); err != nil {
+			log.Printf("停止插件实例失败: %v", err)
+		}
+		cancel()
+	}
+	closeInstance(impl)
+}
+
+// closeInstance 关闭实现了 Close 方法的实例
+func closeInstance(impl interface{}) {
+	if closer, ok := impl.(interface{ Close() error }); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("关闭插件实例失败: %v", err)
+		}
+	}
+}
+
+// isRunning 插件是否处于已启用的运行状态（健康、降级或失败）
+func isRunning(status service.PluginStatus) bool {
+	switch status {
+	case service.PluginEnabled, service.PluginDegraded, service.PluginFailed:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gitee.com/jamespi/drone_dispatch/pkg/tenant"
+	"gitee.com/jamespi/drone_dispatch/service"
+)
+
+// lifecycleCounter 记录 Init、Start、Stop 调用次数的测试插件
+type lifecycleCounter struct {
+	inits, starts, stops *atomic.Int64
+	initErr              *atomic.Value
+	initCfg              *atomic.Value // 最近一次 Init 收到的配置
+}
+
+type counterAPI interface {
+	Count() int64
+}
+
+func (c *lifecycleCounter) Count() int64 { return c.starts.Load() }
+
+func (c *lifecycleCounter) Init(ctx context.Context, cfg map[string]string) error {
+	c.inits.Add(1)
+	c.initCfg.Store(cfg)
+	if err := ctx.Err(); err != nil {
+		c.initErr.Store(err)
+	}
+	return nil
+}
+
+func (c *lifecycleCounter) Start(ctx context.Context) error {
+	c.starts.Add(1)
+	return nil
+}
+
+func (c *lifecycleCounter) Stop(ctx context.Context) error {
+	c.stops.Add(1)
+	return nil
+}
+
+func registerCounter(t *testing.T, pluginType PluginType, scope InstanceScope) *lifecycleCounter {
+	t.Helper()
+	c := &lifecycleCounter{inits: new(atomic.Int64), starts: new(atomic.Int64), stops: new(atomic.Int64), initErr: new(atomic.Value), initCfg: new(atomic.Value)}
+	RegisterPluginWithScope(pluginType, reflect.TypeOf((*counterAPI)(nil)).Elem(), scope, func() interface{} {
+		return &lifecycleCounter{inits: c.inits, starts: c.starts, stops: c.stops, initErr: c.initErr, initCfg: c.initCfg}
+	})
+	t.Cleanup(func() { Unload(pluginType) })
+	return c
+}
+
+// TestGetDisableRace 并发获取与禁用时，每个启动过的实例都会被停止
+func TestGetDisableRace(t *testing.T) {
+	const pluginType PluginType = "test_get_disable_race"
+	c := registerCounter(t, pluginType, ScopeTenant)
+
+	for round := 0; round < 50; round++ {
+		if err := Enable(pluginType); err != nil {
+			t.Fatal(err)
+		}
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(tenantId int64) {
+				defer wg.Done()
+				GetWithContext[counterAPI](tenantContext(tenantId), pluginType)
+			}(int64(i + 1))
+		}
+		Disable(pluginType)
+		wg.Wait()
+	}
+	if starts, stops := c.starts.Load(), c.stops.Load(); starts != stops {
+		t.Fatalf("启动 %d 个实例，停止 %d 个，禁用后仍有实例泄漏", starts, stops)
+	}
+}
+
+// TestLazyInitContext 懒加载的共享实例不使用首个请求的上下文初始化
+func TestLazyInitContext(t *testing.T) {
+	const pluginType PluginType = "test_lazy_init_context"
+	c := registerCounter(t, pluginType, ScopeTenant)
+	if err := Enable(pluginType); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(tenantContext(1))
+	cancel()
+	if _, ok := GetWithContext[counterAPI](ctx, pluginType); !ok {
+		t.Fatal("获取插件实例失败")
+	}
+	if err := c.initErr.Load(); err != nil {
+		t.Fatalf("Init 收到已取消的请求上下文: %v", err)
+	}
+	if c.inits.Load() != 1 {
+		t.Fatalf("Init 调用 %d 次，应为 1", c.inits.Load())
+	}
+}
+
+func tenantContext(tenantId int64) context.Context {
+	return tenant.WithTenant(context.Background(), tenant.NewTenantInfo(tenantId, "token", ""))
+}
+
+// faultyPlugin Init 或 Start 按配置返回错误的测试插件
+type faultyPlugin struct {
+	initErr, startErr error
+	stops             *atomic.Int64
+}
+
+func (f *faultyPlugin) Count() int64 { return 0 }
+
+func (f *faultyPlugin) Init(ctx context.Context, cfg map[string]string) error { return f.initErr }
+
+func (f *faultyPlugin) Start(ctx context.Context) error { return f.startErr }
+
+func (f *faultyPlugin) Stop(ctx context.Context) error {
+	f.stops.Add(1)
+	return nil
+}
+
+// findPlugin 在 PluginsList 中查找插件
+func findPlugin(t *testing.T, pluginType PluginType) PluginInfo {
+	t.Helper()
+	for _, info := range PluginsList() {
+		if info.PluginType == pluginType {
+			return info
+		}
+	}
+	t.Fatalf("PluginsList 中没有插件 %s", pluginType)
+	return PluginInfo{}
+}
+
+// TestLifecycleGating 只有启用成功的插件可以获取；Init、Start 失败时启用返回错误，插件保持未启用并在 PluginsList 中报告原因
+func TestLifecycleGating(t *testing.T) {
+	errInit, errStart := errors.New("broker unreachable"), errors.New("subscribe failed")
+	tests := []struct {
+		name       string
+		initErr    error
+		startErr   error
+		enable     bool
+		disable    bool
+		wantErr    error
+		wantGet    bool
+		wantStatus service.PluginStatus
+	}{
+		{name: "已注册未启用", wantStatus: service.PluginRegistered},
+		{name: "启用成功", enable: true, wantGet: true, wantStatus: service.PluginEnabled},
+		{name: "启用后禁用", enable: true, disable: true, wantStatus: service.PluginDisabled},
+		{name: "Init失败", initErr: errInit, enable: true, wantErr: errInit, wantStatus: service.PluginRegistered},
+		{name: "Start失败", startErr: errStart, enable: true, wantErr: errStart, wantStatus: service.PluginRegistered},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pluginType := PluginType(fmt.Sprintf("test_lifecycle_gating_%d", i))
+			stops := new(atomic.Int64)
+			RegisterPlugin(pluginType, reflect.TypeOf((*counterAPI)(nil)).Elem(), func() interface{} {
+				return &faultyPlugin{initErr: tt.initErr, startErr: tt.startErr, stops: stops}
+			})
+			t.Cleanup(func() { Unload(pluginType) })
+
+			if tt.enable {
+				err := Enable(pluginType)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Enable 返回 %v，应为 %v", err, tt.wantErr)
+				}
+			}
+			if tt.disable {
+				Disable(pluginType)
+			}
+			if _, ok := Get[counterAPI](pluginType); ok != tt.wantGet {
+				t.Errorf("Get 返回 %v，应为 %v", ok, tt.wantGet)
+			}
+
+			info := findPlugin(t, pluginType)
+			if info.Status != tt.wantStatus {
+				t.Errorf("状态 %s，应为 %s", info.Status, tt.wantStatus)
+			}
+			if !errors.Is(info.Error, tt.wantErr) {
+				t.Errorf("PluginsList 报告的错误 %v，应为 %v", info.Error, tt.wantErr)
+			}
+			if tt.wantErr != nil && info.Instances != 0 {
+				t.Errorf("启用失败后仍保留 %d 个实例", info.Instances)
+			}
+			if tt.startErr != nil && stops.Load() != 0 {
+				t.Error("Start 失败的实例只应关闭，不应调用 Stop")
+			}
+		})
+	}
+}
+
+// TestEnableErrors Enable 对未注册、依赖缺失的插件返回错误，已启用时重复启用不报错
+func TestEnableErrors(t *testing.T) {
+	if err := Enable("test_enable_unregistered"); err == nil {
+		t.Error("启用未注册的插件应返回错误")
+	}
+
+	const pluginType PluginType = "test_enable_errors"
+	c := registerCounter(t, pluginType, ScopeSingleton)
+	if err := Enable(pluginType); err != nil {
+		t.Fatal(err)
+	}
+	if err := Enable(pluginType); err != nil {
+		t.Errorf("重复启用返回 %v", err)
+	}
+	if inits := c.inits.Load(); inits != 1 {
+		t.Errorf("重复启用不应重新初始化，Init 调用 %d 次", inits)
+	}
+}
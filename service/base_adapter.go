@@ -1,5 +1,10 @@
 package service
 
+import (
+	"context"
+	"errors"
+)
+
 type PluginStatus string
 
 // 定义适配器插件生命周期状态
@@ -8,7 +13,34 @@ const (
 	PluginUnloaded   PluginStatus = "unloaded"   //插件已卸载
 	PluginDisabled   PluginStatus = "disabled"   //插件已禁用
 	PluginEnabled    PluginStatus = "enabled"    //插件已启用
+	PluginDegraded   PluginStatus = "degraded"   //插件已启用，健康检查降级（部分能力受限）
+	PluginFailed     PluginStatus = "failed"     //插件已启用，健康检查连续失败
 )
 
+// ErrPluginDegraded 健康检查返回包装了该错误的结果时，插件直接标记为降级而非计入失败次数
+var ErrPluginDegraded = errors.New("插件降级")
+
 type BaseAdapter interface {
 }
+
+// 以下为可选的插件生命周期接口，插件实例实现哪个，注册中心就在对应阶段调用哪个
+
+// PluginInitializer 插件初始化，启用插件时调用，返回错误则拒绝启用
+type PluginInitializer interface {
+	Init(ctx context.Context, cfg map[string]string) error
+}
+
+// PluginStarter 插件启动，Init成功后调用（例如建立MQTT连接、启动后台协程）
+type PluginStarter interface {
+	Start(ctx context.Context) error
+}
+
+// PluginStopper 插件停止，禁用或卸载插件时调用
+type PluginStopper interface {
+	Stop(ctx context.Context) error
+}
+
+// PluginHealthChecker 插件健康检查，返回包装了 ErrPluginDegraded 的错误表示降级
+type PluginHealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
@@ -0,0 +1,57 @@
+// 定义跨厂商的能力接口
+// 调度逻辑按能力而非插件名称查找适配器，适配器实现哪个接口即具备哪种能力
+
+package service
+
+import (
+	"context"
+	"io"
+)
+
+// TaskCreator 可创建飞行任务
+type TaskCreator interface {
+	// CreateFlightTask 创建飞行任务
+	CreateFlightTask(ctx context.Context, payLoad io.Reader) (string, error)
+}
+
+// LiveStreamer 可开启直播
+type LiveStreamer interface {
+	// LiveStreamStart 开启直播
+	LiveStreamStart(ctx context.Context, payLoad io.Reader) (string, error)
+}
+
+// LiveStreamStopper 可主动停止直播；未实现时由厂商在无人观看后自行停止推流
+type LiveStreamStopper interface {
+	// LiveStreamStop 停止设备指定相机的直播
+	LiveStreamStop(ctx context.Context, deviceSn, cameraIndex string) error
+}
+
+// DeviceController 可下发设备实时控制指令
+type DeviceController interface {
+	// UpdateDeviceCommand 实时控制指令下发
+	UpdateDeviceCommand(ctx context.Context, deviceSn string, payLoad io.Reader) (string, error)
+}
+
+// TelemetrySource 可获取设备遥测状态
+type TelemetrySource interface {
+	// GetDeviceState 获取设备物模型（遥测）状态
+	GetDeviceState(ctx context.Context, deviceSn string) (string, error)
+}
+
+// MediaProvider 可获取飞行任务产生的媒体资源
+type MediaProvider interface {
+	// GetFlightTaskMedia 获取飞行任务产生的媒体资源
+	GetFlightTaskMedia(ctx context.Context, taskUUID string) (string, error)
+}
+
+// DeviceLocator 可判断设备是否由该适配器管理，用于按设备筛选适配器
+type DeviceLocator interface {
+	// HasDevice 设备是否由该适配器管理（网关或飞行器序列号）
+	HasDevice(ctx context.Context, deviceSn string) (bool, error)
+}
+
+// HmsProvider 可获取设备健康管理系统（HMS）告警
+type HmsProvider interface {
+	// GetDeviceHms 获取设备HMS告警，多个序列号以逗号分隔
+	GetDeviceHms(ctx context.Context, deviceSnList string) (string, error)
+}
@@ -2,6 +2,8 @@
 
 package service
 
+import "context"
+
 // 新增OSD数据结构
 type DroneOSD struct {
 	Latitude    float64 `json:"latitude"`
@@ -21,11 +23,15 @@ type DJIDock2DroneAdapter interface {
 	// 设备绑定机场组织
 	AirportOrganizationBind() (bool, error) // 是否绑定机场组织
 	// 一键起飞
-	TakeOff() (string, error)
+	TakeOff(ctx context.Context) (string, error)
 	// 一键起飞结果事件通知
 	TakeOffToPointProgress() (string, error)
+	// 指点飞行（WGS-84），航段进入电子围栏时拒绝下发，限飞区可通过 geofence.WithOverride 确认
+	FlyToPoint(ctx context.Context, latitude, longitude, height, maxSpeed float64) (string, error)
+	// 指点飞行结果事件通知
+	FlyToPointProgress() (string, error)
 	// 一键降落
-	Land() (string, error)
+	Land(ctx context.Context) (string, error)
 	// 一键降落结果事件通知
 	LandToPointProgress() (string, error)
 	// DRC链路状态通知
@@ -33,33 +39,33 @@ type DJIDock2DroneAdapter interface {
 	// DRC飞行控制无效原因通知
 	JoystickInvalidNotify() (string, error)
 	// DRC杆量控制
-	StickControl(stickX, stickY int) (string, error)
+	StickControl(ctx context.Context, stickX, stickY int) (string, error)
 	// DRC飞行器急停
 	DroneEmergencyStop() (string, error)
 	// 飞行控制权争夺
-	FlightAuthorityGrab() (string, error)
+	FlightAuthorityGrab(ctx context.Context) (string, error)
 	// 飞行控制权释放
-	FlightAuthorityRelease() (string, error)
+	FlightAuthorityRelease(ctx context.Context) (string, error)
 	// 切换相机模式
-	CameraModeSwitch(mode string) (string, error)
+	CameraModeSwitch(ctx context.Context, mode string) (string, error)
 	// 云台挂载开始拍照
-	CameraPhotoTake() (string, error)
+	CameraPhotoTake(ctx context.Context) (string, error)
 	// 云台挂载停止拍照
-	CameraPhotoStop() (string, error)
+	CameraPhotoStop(ctx context.Context) (string, error)
 	// 云台挂载开始录像
-	CameraRecordingStart() (string, error)
+	CameraRecordingStart(ctx context.Context) (string, error)
 	// 云台挂载停止录像
-	CameraRecordingStop() (string, error)
+	CameraRecordingStop(ctx context.Context) (string, error)
 	// 云台挂载框选变焦
-	CameraFrameZoom(frameX, frameY int) (string, error)
+	CameraFrameZoom(ctx context.Context, frameX, frameY int) (string, error)
 	// 云台挂载变焦
-	CameraFocalLengthSet(focalLength int) (string, error)
+	CameraFocalLengthSet(ctx context.Context, focalLength int) (string, error)
 	// 画面拖动控制（云台与机身是否一起转动）
-	CameraScreenDrag(dragX, dragY int, isFollow bool) (string, error)
+	CameraScreenDrag(ctx context.Context, dragX, dragY int, isFollow bool) (string, error)
 	// 云台照片存储设置
-	CameraPhotoStorageSet(storageType string) (string, error)
+	CameraPhotoStorageSet(ctx context.Context, storageType string) (string, error)
 	// 云台录像存储设置
-	CameraRecordingStorageSet(storageType string) (string, error)
+	CameraRecordingStorageSet(ctx context.Context, storageType string) (string, error)
 	// 获取无人机名称
 	GetDroneName() string
 	// 获取无人机类型
@@ -51,6 +51,8 @@ type FH2DroneAdapter interface {
 	GetFlightTaskTrack(ctx context.Context, taskUUID string) (string, error)
 	// SetFinishUpload 航线上传完成通知
 	SetFinishUpload(ctx context.Context, objectKeyPrefix string, fileName string) (string, error)
+	// UploadWayline 上传航线文件并通知上传完成
+	UploadWayline(ctx context.Context, name string, kmz io.Reader) (string, error)
 	// GetWayLine 获取项目下航线列表
 	GetWayLine(ctx context.Context) (string, error)
 	// GetWayLineInfo 获取项目下的航线详情